@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -10,14 +9,24 @@ import (
 )
 
 var addTagsCmd = &cobra.Command{
-	Use:   "add-tags",
+	Use:   "add-tags [monitor-id...]",
 	Short: "Add tags to monitors",
-	Long:  `Add tags to a single monitor or multiple monitors matching filters`,
-	RunE:  runAddTags,
+	Long: `Add tags to a single monitor or multiple monitors matching filters.
+
+Several monitor IDs (--monitor-id repeated or comma-separated, or given as
+arguments) are updated as a batch: one confirmation and one summary of the
+updated, unchanged and failed monitors.
+
+Examples:
+  add-tags --monitor-id 12345 --tag team:payments
+  add-tags --monitor-id 12345,67890 --tag team:payments
+  add-tags 12345 67890 --tag team:payments
+  add-tags --services api,worker --env prd --tag team:payments`,
+	RunE: runAddTags,
 }
 
 var (
-	addTagsMonitorID      int
+	addTagsMonitorIDs     []int
 	addTagsService        string
 	addTagsEnv            string
 	addTagsNamespace      string
@@ -26,20 +35,25 @@ var (
 	addTagsStatus         string
 	addTagsFilterServices string
 	addTagsTags           []string
+	addTagsRollbackFile   string
+
+	addTagsServices string
 )
 
 func init() {
 	rootCmd.AddCommand(addTagsCmd)
-	addTagsCmd.Flags().IntVar(&addTagsMonitorID, "monitor-id", 0, "Monitor ID (for single monitor)")
+	addTagsCmd.Flags().IntSliceVar(&addTagsMonitorIDs, "monitor-id", nil, "Monitor ID (comma-separated or repeated for several; monitor IDs can also be given as arguments)")
 	addTagsCmd.Flags().StringVar(&addTagsService, "service", "", "Filter by service (for multiple monitors)")
 	addTagsCmd.Flags().StringVar(&addTagsEnv, "env", "", "Filter by environment (for multiple monitors)")
 	addTagsCmd.Flags().StringVar(&addTagsNamespace, "namespace", "", "Filter by namespace (for multiple monitors)")
 	addTagsCmd.Flags().StringVar(&addTagsFilterTags, "filter-tags", "", "Filter by tags (comma-separated, for multiple monitors)")
 	addTagsCmd.Flags().StringVar(&addTagsQuery, "query", "", "Complex search query (e.g., service:(service1 OR service2))")
-	addTagsCmd.Flags().StringVar(&addTagsStatus, "status", "", "Filter by monitor state (e.g., No Data, Alert, Warn, OK) when updating multiple monitors")
+	addTagsCmd.Flags().StringVar(&addTagsStatus, "status", "", "Filter by monitor state, comma-separated (OK, Alert, Warn, No Data, Unknown, Skipped, Ignored) when updating multiple monitors")
+	addTagsCmd.Flags().StringVar(&addTagsServices, "services", "", "Filter by any of these services (comma-separated, OR-matched; cannot be combined with --service)")
 	addTagsCmd.Flags().StringVar(&addTagsFilterServices, "filter-services", "", "Filter by multiple services (comma-separated, filters locally after query/tags)")
 	addTagsCmd.Flags().StringArrayVar(&addTagsTags, "tag", []string{}, "Tags to add (required, can be used multiple times)")
 	addTagsCmd.MarkFlagRequired("tag")
+	addTagsCmd.Flags().StringVar(&addTagsRollbackFile, "rollback-file", "", "Write the tags of each updated monitor before and after the change to this file (undo with: rollback --file)")
 }
 
 func runAddTags(cmd *cobra.Command, args []string) error {
@@ -47,286 +61,186 @@ func runAddTags(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("at least one --tag is required")
 	}
 
-	// Validate: either monitor-id or filters must be provided
-	if addTagsMonitorID == 0 && addTagsService == "" && addTagsEnv == "" && addTagsNamespace == "" && addTagsFilterTags == "" && addTagsQuery == "" {
-		return fmt.Errorf("either --monitor-id or filter flags (--service, --env, --namespace, --filter-tags, --query) must be provided")
+	monitorIDs, err := monitorIDArgs(addTagsMonitorIDs, args)
+	if err != nil {
+		return err
+	}
+
+	// Validate: either monitor IDs or filters must be provided
+	if len(monitorIDs) == 0 && addTagsService == "" && addTagsServices == "" && addTagsEnv == "" && addTagsNamespace == "" && addTagsFilterTags == "" && addTagsQuery == "" {
+		return fmt.Errorf("either monitor IDs (--monitor-id or arguments) or filter flags (--service, --services, --env, --namespace, --filter-tags, --query) must be provided")
 	}
 
-	// Cannot use both monitor-id and filters
-	if addTagsMonitorID > 0 && (addTagsService != "" || addTagsEnv != "" || addTagsNamespace != "" || addTagsFilterTags != "" || addTagsQuery != "" || addTagsStatus != "") {
-		return fmt.Errorf("cannot use --monitor-id together with filter flags")
+	// Cannot use both monitor IDs and filters
+	if len(monitorIDs) > 0 && (addTagsService != "" || addTagsServices != "" || addTagsEnv != "" || addTagsNamespace != "" || addTagsFilterTags != "" || addTagsQuery != "" || addTagsStatus != "") {
+		return fmt.Errorf("cannot use monitor IDs together with filter flags")
 	}
 
 	// Cannot use --query together with other filter flags
-	if addTagsQuery != "" && (addTagsService != "" || addTagsEnv != "" || addTagsNamespace != "" || addTagsFilterTags != "") {
-		return fmt.Errorf("cannot use --query together with other filter flags (--service, --env, --namespace, --filter-tags)")
+	if addTagsQuery != "" && (addTagsService != "" || addTagsServices != "" || addTagsEnv != "" || addTagsNamespace != "" || addTagsFilterTags != "") {
+		return fmt.Errorf("cannot use --query together with other filter flags (--service, --services, --env, --namespace, --filter-tags)")
+	}
+	if addTagsService != "" && addTagsServices != "" {
+		return fmt.Errorf("cannot use --service together with --services")
+	}
+	if _, err := parseStatusFlag(addTagsStatus); err != nil {
+		return err
 	}
 
-	client, err := datadog.NewClient()
+	client, err := newClient()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		errOut.Printf("❌ Error: %v\n", err)
 		return err
 	}
 
-	if addTagsMonitorID > 0 {
+	if len(monitorIDs) == 1 {
 		// Single monitor
-		updated, err := client.AddTagsToMonitor(addTagsMonitorID, addTagsTags)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "❌ Error adding tags: %v\n", err)
-			return err
-		}
-
-		fmt.Printf("✅ Tags added to monitor %d\n", addTagsMonitorID)
-		fmt.Printf("Monitor: %s\n", updated.Name)
-		fmt.Printf("Tags: %s\n", strings.Join(updated.Tags, ", "))
-	} else if addTagsQuery != "" {
-		// Use query to find monitors
-		fmt.Println("\n🔍 Finding monitors with query:")
-		fmt.Printf("🔎 Query: %s\n", addTagsQuery)
-		if addTagsStatus != "" {
-			fmt.Printf("🚦 Status: %s\n", addTagsStatus)
+		monitorID := monitorIDs[0]
+		var before *datadog.Monitor
+		if addTagsRollbackFile != "" {
+			before, err = client.GetMonitor(monitorID)
+			if err != nil {
+				reportMonitorError("getting monitor", err)
+				return err
+			}
 		}
-		fmt.Println(strings.Repeat("=", 80))
 
-		monitors, err := client.ListMonitors(nil, addTagsQuery)
+		updated, changed, err := client.AddTagsToMonitor(monitorID, addTagsTags)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "❌ Error listing monitors: %v\n", err)
+			reportMonitorError("adding tags", err)
 			return err
 		}
-
-		if addTagsStatus != "" {
-			monitors = filterMonitorsByState(monitors, addTagsStatus)
+		if !changed {
+			out.Printf("ℹ️  Monitor %d already has %s, left unchanged\n", monitorID, strings.Join(addTagsTags, ", "))
+			return nil
 		}
 
-		if addTagsFilterServices != "" {
-			services := strings.Split(addTagsFilterServices, ",")
-			for i := range services {
-				services[i] = strings.TrimSpace(services[i])
-			}
-			monitors = filterMonitorsByServices(monitors, services)
+		out.Printf("✅ Tags added to monitor %d\n", monitorID)
+		out.Printf("Monitor: %s\n", updated.Name)
+		out.Printf("Tags: %s\n", strings.Join(updated.Tags, ", "))
+		if addTagsRollbackFile != "" {
+			return writeTagRollback(addTagsRollbackFile, "add-tags", addTagsTags, []datadog.TagUpdateResult{datadog.NewTagUpdateResult(*before, updated, true, nil)})
 		}
+		return nil
+	}
 
-		if len(monitors) == 0 {
-			fmt.Println("ℹ️  No monitors found matching the specified query/status/filters")
-			return nil
+	// Several monitor IDs are updated as a batch, like the monitors matching
+	// filters; the IDs that can't be fetched are counted in the summary
+	var monitors []datadog.Monitor
+	var fetchFailed []datadog.TagUpdateResult
+	if len(monitorIDs) > 1 {
+		out.Printf("\n🔍 Fetching %d monitor(s) by ID\n", len(monitorIDs))
+		out.Println(strings.Repeat("=", 80))
+		monitors, fetchFailed, err = fetchMonitorsByID(client, monitorIDs)
+		if err != nil {
+			printInterrupted(err, len(monitors)+len(fetchFailed), len(monitorIDs), "monitor(s)")
+			return err
 		}
-
-		fmt.Printf("📊 Found %d monitor(s) matching the query\n\n", len(monitors))
-
-		// Add tags to each monitor
-		var results []map[string]interface{}
-		for _, monitor := range monitors {
-			updated, err := client.AddTagsToMonitor(monitor.ID, addTagsTags)
-			if err != nil {
-				results = append(results, map[string]interface{}{
-					"id":     monitor.ID,
-					"name":   monitor.Name,
-					"status": fmt.Sprintf("failed: %v", err),
-				})
+		if len(monitors) == 0 {
+			if len(fetchFailed) > 0 {
+				printTagUpdateResults(fetchFailed)
 			} else {
-				results = append(results, map[string]interface{}{
-					"id":     updated.ID,
-					"name":   updated.Name,
-					"status": "updated",
-					"tags":   updated.Tags,
-				})
+				out.Println("ℹ️  No monitors left to update")
 			}
+			return nil
 		}
-
-		var successful []map[string]interface{}
-		var failed []map[string]interface{}
-
-		for _, result := range results {
-			if status, ok := result["status"].(string); ok && status == "updated" {
-				successful = append(successful, result)
-			} else {
-				failed = append(failed, result)
+		out.Printf("📊 Found %d of %d monitor(s)\n", len(monitors), len(monitorIDs))
+	} else {
+		selector := monitorSelector{
+			Query:          addTagsQuery,
+			Service:        addTagsService,
+			Services:       splitCommaList(addTagsServices),
+			Env:            addTagsEnv,
+			Namespace:      addTagsNamespace,
+			Tags:           splitCommaList(addTagsFilterTags),
+			Status:         addTagsStatus,
+			FilterServices: addTagsFilterServices,
+			Mutating:       true,
+		}
+
+		if addTagsQuery != "" {
+			out.Println("\n🔍 Finding monitors with query:")
+			out.Printf("🔎 Query: %s\n", addTagsQuery)
+		} else {
+			out.Println("\n🔍 Finding monitors to update with filters:")
+			if addTagsService != "" {
+				out.Printf("📦 Service: %s\n", addTagsService)
 			}
-		}
-
-		fmt.Printf("📊 Results:\n")
-		fmt.Printf("✅ Successfully updated: %d\n", len(successful))
-		fmt.Printf("❌ Failed: %d\n", len(failed))
-
-		if len(successful) > 0 {
-			fmt.Println("\n✅ Successfully updated monitors:")
-			for _, result := range successful {
-				id, _ := result["id"].(int)
-				name, _ := result["name"].(string)
-				var tags []string
-				if tagsInterface, ok := result["tags"].([]interface{}); ok {
-					for _, tag := range tagsInterface {
-						if tagStr, ok := tag.(string); ok {
-							tags = append(tags, tagStr)
-						}
-					}
-				} else if tagsStr, ok := result["tags"].([]string); ok {
-					tags = tagsStr
-				}
-				fmt.Printf("   ✅ ID %d: %s\n", id, name)
-				if len(tags) > 0 {
-					fmt.Printf("      Tags: %s\n", strings.Join(tags, ", "))
-				}
+			if len(selector.Services) > 0 {
+				out.Printf("📦 Services: %s\n", strings.Join(selector.Services, ", "))
 			}
-		}
-
-		if len(failed) > 0 {
-			fmt.Println("\n❌ Failed to update monitors:")
-			for _, result := range failed {
-				id, _ := result["id"].(int)
-				name, _ := result["name"].(string)
-				status, _ := result["status"].(string)
-				fmt.Printf("   ⚠️  ID %d: %s - %s\n", id, name, status)
+			if addTagsEnv != "" {
+				out.Printf("🌍 Environment: %s\n", addTagsEnv)
+			}
+			if addTagsNamespace != "" {
+				out.Printf("🏷️  Namespace: %s\n", addTagsNamespace)
+			}
+			if len(selector.Tags) > 0 {
+				out.Printf("🏷️  Filter Tags: %s\n", strings.Join(selector.Tags, ", "))
 			}
-		}
-	} else {
-		// Multiple monitors
-		fmt.Println("\n🔍 Finding monitors to update with filters:")
-		if addTagsService != "" {
-			fmt.Printf("📦 Service: %s\n", addTagsService)
-		}
-		if addTagsEnv != "" {
-			fmt.Printf("🌍 Environment: %s\n", addTagsEnv)
-		}
-		if addTagsNamespace != "" {
-			fmt.Printf("🏷️  Namespace: %s\n", addTagsNamespace)
 		}
 		if addTagsStatus != "" {
-			fmt.Printf("🚦 Status: %s\n", addTagsStatus)
+			out.Printf("🚦 Status: %s\n", addTagsStatus)
 		}
 		if addTagsFilterServices != "" {
-			fmt.Printf("🔍 Filter Services: %s\n", addTagsFilterServices)
+			out.Printf("🔍 Filter Services: %s\n", addTagsFilterServices)
 		}
+		out.Println(strings.Repeat("=", 80))
 
-		var filterTags []string
-		if addTagsFilterTags != "" {
-			filterTags = strings.Split(addTagsFilterTags, ",")
-			for i := range filterTags {
-				filterTags[i] = strings.TrimSpace(filterTags[i])
-			}
-			if len(filterTags) > 0 {
-				fmt.Printf("🏷️  Filter Tags: %s\n", strings.Join(filterTags, ", "))
-			}
-		}
-		fmt.Println(strings.Repeat("=", 80))
-
-		var results []map[string]interface{}
-		if addTagsStatus == "" && addTagsFilterServices == "" {
-			// Keep existing behavior (more efficient) when status/filter-services filter is not requested
-			results, err = client.AddTagsToMonitors(addTagsService, addTagsEnv, addTagsNamespace, filterTags, addTagsTags)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "❌ Error adding tags: %v\n", err)
-				return err
-			}
-		} else {
-			// When filtering by status or filter-services, we need to list and filter locally
-			// Check if filterTags contains wildcards - if so, use as query instead
-			var monitors []datadog.Monitor
-			var err error
-			if len(filterTags) > 0 && (strings.Contains(filterTags[0], "*") || strings.Contains(filterTags[0], "?")) {
-				// Wildcard pattern - use as query
-				monitors, err = client.ListMonitors(nil, filterTags[0])
-			} else {
-				// Exact tags - use as tag filter
-				monitors, err = client.ListMonitors(filterTags, "")
-			}
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "❌ Error listing monitors: %v\n", err)
-				return err
-			}
-
-			monitors = filterMonitorsByServiceEnvNamespace(monitors, addTagsService, addTagsEnv, addTagsNamespace)
-
-			if addTagsFilterServices != "" {
-				services := strings.Split(addTagsFilterServices, ",")
-				for i := range services {
-					services[i] = strings.TrimSpace(services[i])
-				}
-				monitors = filterMonitorsByServices(monitors, services)
-			}
-
-			if addTagsStatus != "" {
-				monitors = filterMonitorsByState(monitors, addTagsStatus)
-			}
-
-			if len(monitors) == 0 {
-				fmt.Println("ℹ️  No monitors found matching the specified filters")
-				return nil
-			}
-
-			fmt.Printf("📊 Found %d monitor(s) matching the filters\n\n", len(monitors))
-
-			for _, monitor := range monitors {
-				updated, err := client.AddTagsToMonitor(monitor.ID, addTagsTags)
-				if err != nil {
-					results = append(results, map[string]interface{}{
-						"id":     monitor.ID,
-						"name":   monitor.Name,
-						"status": fmt.Sprintf("failed: %v", err),
-					})
-				} else {
-					results = append(results, map[string]interface{}{
-						"id":     updated.ID,
-						"name":   updated.Name,
-						"status": "updated",
-						"tags":   updated.Tags,
-					})
-				}
-			}
+		monitors, err = fetchMonitors(client, selector)
+		if err != nil {
+			errOut.Printf("❌ Error listing monitors: %v\n", err)
+			return err
 		}
 
-		if len(results) == 0 {
-			fmt.Println("ℹ️  No monitors found matching the specified filters")
+		if len(monitors) == 0 {
+			out.Println("ℹ️  No monitors found matching the specified filters")
 			return nil
 		}
 
-		var successful []map[string]interface{}
-		var failed []map[string]interface{}
+		out.Printf("📊 Found %d monitor(s) matching the filters\n", len(monitors))
+	}
 
-		for _, result := range results {
-			if status, ok := result["status"].(string); ok && status == "updated" {
-				successful = append(successful, result)
-			} else {
-				failed = append(failed, result)
-			}
+	// Skip monitors that already have every tag
+	var missing []datadog.Monitor
+	for _, monitor := range monitors {
+		if len(datadog.MissingTags(monitor, addTagsTags)) > 0 {
+			missing = append(missing, monitor)
 		}
+	}
+	if len(missing) == 0 {
+		out.Printf("ℹ️  All %d monitor(s) already have %s\n", len(monitors), strings.Join(addTagsTags, ", "))
+		if len(fetchFailed) > 0 {
+			printTagUpdateResults(fetchFailed)
+		}
+		return nil
+	}
+	if skipped := len(monitors) - len(missing); skipped > 0 {
+		out.Printf("⏭️  %d monitor(s) that already have every tag are left alone\n", skipped)
+	}
+	monitors = missing
 
-		fmt.Printf("\n📊 Results:\n")
-		fmt.Printf("✅ Successfully updated: %d\n", len(successful))
-		fmt.Printf("❌ Failed: %d\n", len(failed))
+	confirmed, err := confirm(len(monitors), "add tags to", monitorSample(monitors))
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+	if !confirmed {
+		out.Println("❌ Update cancelled")
+		return nil
+	}
 
-		if len(successful) > 0 {
-			fmt.Println("\n✅ Successfully updated monitors:")
-			for _, result := range successful {
-				id, _ := result["id"].(int)
-				name, _ := result["name"].(string)
-				var tags []string
-				if tagsInterface, ok := result["tags"].([]interface{}); ok {
-					for _, tag := range tagsInterface {
-						if tagStr, ok := tag.(string); ok {
-							tags = append(tags, tagStr)
-						}
-					}
-				} else if tagsStr, ok := result["tags"].([]string); ok {
-					tags = tagsStr
-				}
-				fmt.Printf("   ✅ ID %d: %s\n", id, name)
-				if len(tags) > 0 {
-					fmt.Printf("      Tags: %s\n", strings.Join(tags, ", "))
-				}
-			}
-		}
+	results, err := updateTagsOnMonitors(monitors, func(monitorID int) (*datadog.Monitor, bool, error) {
+		return client.AddTagsToMonitor(monitorID, addTagsTags)
+	})
+	printInterrupted(err, len(results), len(monitors), "monitor(s)")
+	printTagUpdateResults(append(fetchFailed, results...))
 
-		if len(failed) > 0 {
-			fmt.Println("\n❌ Failed to update monitors:")
-			for _, result := range failed {
-				id, _ := result["id"].(int)
-				name, _ := result["name"].(string)
-				status, _ := result["status"].(string)
-				fmt.Printf("   ⚠️  ID %d: %s - %s\n", id, name, status)
-			}
+	if addTagsRollbackFile != "" {
+		if rollbackErr := writeTagRollback(addTagsRollbackFile, "add-tags", addTagsTags, results); rollbackErr != nil && err == nil {
+			err = rollbackErr
 		}
 	}
 
-	return nil
+	return err
 }
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+func TestTagBatchByIDSkipsProtected(t *testing.T) {
+	for _, tc := range []struct {
+		command string
+		tag     string
+		// changed reports whether the tags of a monitor were updated
+		changed func(tags []string) bool
+	}{
+		{"add-tags", "team:sre", func(tags []string) bool { return slices.Contains(tags, "team:sre") }},
+		{"remove-tags", "owner:alice", func(tags []string) bool { return !slices.Contains(tags, "owner:alice") }},
+	} {
+		t.Run(tc.command, func(t *testing.T) {
+			srv := newTestServer(t)
+			open := srv.AddMonitor(datadog.Monitor{Name: "cpu", Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90", Tags: []string{"owner:alice"}})
+			locked := srv.AddMonitor(datadog.Monitor{Name: "disk", Type: "metric alert", Query: "avg(last_5m):avg:disk{*} > 90", Tags: []string{"owner:alice", "protected:true"}})
+
+			res := runCLI(t, nil, tc.command, "--yes", "--tag", tc.tag, strconv.Itoa(open.ID), strconv.Itoa(locked.ID))
+			if res.Err != nil {
+				t.Fatalf("%s: %v\n%s%s", tc.command, res.Err, res.Stdout, res.Stderr)
+			}
+
+			if m, _ := srv.Monitor(open.ID); !tc.changed(m.Tags) {
+				t.Errorf("monitor %d was not updated: tags %v", open.ID, m.Tags)
+			}
+			if m, _ := srv.Monitor(locked.ID); tc.changed(m.Tags) {
+				t.Errorf("protected monitor %d was updated: tags %v", locked.ID, m.Tags)
+			}
+			srv.AssertRequestCount(t, 1, "PUT", "/monitor/"+strconv.Itoa(open.ID))
+			srv.AssertRequestCount(t, 0, "PUT", "/monitor/"+strconv.Itoa(locked.ID))
+
+			_, section, _ := strings.Cut(res.Stdout, "Protected (skipped)")
+			if !strings.Contains(section, "ID "+strconv.Itoa(locked.ID)+": disk") {
+				t.Errorf("protected monitor not listed as skipped:\n%s", res.Stdout)
+			}
+		})
+	}
+}
+
+func TestTagBatchByIDOnlyProtected(t *testing.T) {
+	srv := newTestServer(t)
+	disk := srv.AddMonitor(datadog.Monitor{Name: "disk", Type: "metric alert", Query: "avg(last_5m):avg:disk{*} > 90", Tags: []string{"protected:true"}})
+	mem := srv.AddMonitor(datadog.Monitor{Name: "mem", Type: "metric alert", Query: "avg(last_5m):avg:mem{*} > 90", Tags: []string{"protected:true"}})
+
+	res := runCLI(t, nil, "add-tags", "--yes", "--tag", "team:sre", "--monitor-id", strconv.Itoa(disk.ID), "--monitor-id", strconv.Itoa(mem.ID))
+	if res.Err != nil {
+		t.Fatalf("add-tags: %v\n%s", res.Err, res.Stderr)
+	}
+	srv.AssertNoMutations(t)
+	if !strings.Contains(res.Stdout, "No monitors left to update") || !strings.Contains(res.Stdout, "Protected (skipped): 2") {
+		t.Errorf("unexpected output:\n%s", res.Stdout)
+	}
+}
+
+func TestTagUpdatesSkipUnchanged(t *testing.T) {
+	for _, tc := range []struct {
+		command string
+		tag     string
+		want    string
+		// otherTags are the tags of a monitor the command changes
+		otherTags []string
+	}{
+		{"add-tags", "team:sre", "already has team:sre, left unchanged", nil},
+		{"remove-tags", "owner:alice", "has none of owner:alice, left unchanged", []string{"owner:alice"}},
+	} {
+		t.Run(tc.command, func(t *testing.T) {
+			srv := newTestServer(t)
+			monitor := srv.AddMonitor(datadog.Monitor{Name: "cpu", Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90", Tags: []string{"team:sre"}})
+
+			// A single monitor
+			res := runCLI(t, nil, tc.command, "--yes", "--tag", tc.tag, strconv.Itoa(monitor.ID))
+			if res.Err != nil {
+				t.Fatalf("%s: %v\n%s", tc.command, res.Err, res.Stderr)
+			}
+			srv.AssertNoMutations(t)
+			if !strings.Contains(res.Stdout, tc.want) {
+				t.Errorf("unexpected output:\n%s", res.Stdout)
+			}
+
+			// A batch updates only the monitor whose tags change
+			srv.ResetRequests()
+			other := srv.AddMonitor(datadog.Monitor{Name: "disk", Type: "metric alert", Query: "avg(last_5m):avg:disk{*} > 90", Tags: tc.otherTags})
+			res = runCLI(t, nil, tc.command, "--yes", "--tag", tc.tag, strconv.Itoa(monitor.ID), strconv.Itoa(other.ID))
+			if res.Err != nil {
+				t.Fatalf("%s: %v\n%s", tc.command, res.Err, res.Stderr)
+			}
+			srv.AssertRequestCount(t, 0, "PUT", "/monitor/"+strconv.Itoa(monitor.ID))
+			srv.AssertRequestCount(t, 1, "PUT", "/monitor/"+strconv.Itoa(other.ID))
+
+			// Nothing to change in the whole batch
+			srv.ResetRequests()
+			res = runCLI(t, nil, tc.command, "--yes", "--tag", tc.tag, strconv.Itoa(monitor.ID), strconv.Itoa(other.ID))
+			if res.Err != nil {
+				t.Fatalf("%s: %v\n%s", tc.command, res.Err, res.Stderr)
+			}
+			srv.AssertNoMutations(t)
+		})
+	}
+}
@@ -0,0 +1,369 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Apply a service spec (monitors, SLOs and downtimes)",
+	Long: `Apply everything described in a service spec file in order:
+monitors from the referenced templates first, then SLOs referencing those
+monitors, then maintenance windows (downtimes).
+
+Everything is upserted, so if a step fails the command prints exactly what was
+applied so far and it is safe to rerun. With --atomic, every monitor is
+validated with the API before anything is written, and a failed write rolls
+back the monitors, SLOs and downtimes this run created or updated.
+
+Example service.yaml:
+  service: myapp
+  env: prd
+  namespace: myapp
+  tags: [team:backend]
+  threshold_scale: 1.5   # optional, multiplies every threshold
+  templates:
+    - file: templates/kubernetes-monitors.json
+      vars: {threshold: "90"}
+    - file: git::https://github.com/org/monitors.git//k8s/pods.json?ref=v1.2.0
+  slos:
+    - name: "{service} availability"
+      monitors: ["CPU usage"]
+      thresholds: [{timeframe: 30d, target: 99.9}]
+  downtimes:
+    - name: "{service} weekly maintenance"
+      scope: ["service:{service}"]
+      start: "2024-01-07T02:00:00Z"
+      end: "2024-01-07T04:00:00Z"
+      recurrence: {type: weeks, period: 1, week_days: [Sun]}`,
+	RunE: runApply,
+}
+
+var (
+	applyFile        string
+	applyAllowAnyEnv bool
+	applyNoSchema    bool
+	applyStrictScope bool
+	applyProtect     bool
+	applyAtomic      bool
+	applyRefresh     bool
+
+	applyNameOverflow  string
+	applyK8sDefaults   bool
+	applyK8sEvalDelay  int
+	applyK8sGroupDelay int
+
+	applyThresholdScale string
+	applyMatchBy        string
+	applyFixNotifyBy    bool
+	applyUppercaseEnv   bool
+	applyPresets        []string
+)
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+	applyCmd.Flags().StringVarP(&applyFile, "file", "f", "", "Path to the service spec file (required)")
+	applyCmd.MarkFlagRequired("file")
+	applyCmd.Flags().BoolVar(&applyNoSchema, "no-schema-validation", false, "Skip validating templates against the monitor template schema")
+	applyCmd.Flags().BoolVar(&applyStrictScope, "strict-scope", false, "Fail when a template query is scoped to another env or service than the spec's")
+	applyCmd.Flags().BoolVar(&applyProtect, "protect-unmanaged", false, "Don't update existing monitors without the managed-by:ddmm tag; report them as conflicts (exit code 4)")
+	applyCmd.Flags().BoolVar(&applyAtomic, "atomic", false, "Validate every monitor with the API before writing any, and roll back this run's changes if a step fails")
+	applyCmd.Flags().BoolVar(&applyRefresh, "refresh-templates", false, "Fetch remote template sources again instead of using the cached copy")
+	applyCmd.Flags().BoolVar(&applyAllowAnyEnv, "allow-any-env", false, "Accept any environment name without validation or warnings")
+	applyCmd.Flags().BoolVar(&applyK8sDefaults, "k8s-defaults", false, "Set evaluation_delay and new_group_delay on metric monitors on kubernetes./container. metrics when the template doesn't")
+	applyCmd.Flags().IntVar(&applyK8sEvalDelay, "k8s-evaluation-delay", 0, "evaluation_delay --k8s-defaults sets, in seconds (default: k8s_defaults in the config file, or 300)")
+	applyCmd.Flags().IntVar(&applyK8sGroupDelay, "k8s-new-group-delay", 0, "new_group_delay --k8s-defaults sets, in seconds (default: k8s_defaults in the config file, or 300)")
+	applyCmd.Flags().StringVar(&applyMatchBy, "match-by", "name", "How upserts find a monitor without the template's identity: name, or query to also match a renamed monitor by its type and query (threshold excluded)")
+	applyCmd.Flags().BoolVar(&applyFixNotifyBy, "fix-notify-by", false, "Drop options.notify_by values the query doesn't group by, with a warning, instead of failing the template")
+	applyCmd.Flags().BoolVar(&applyUppercaseEnv, "uppercase-env-in-name", false, "Render {env} in names uppercased, as before {env:upper}")
+	applyCmd.Flags().MarkDeprecated("uppercase-env-in-name", "use {env:upper} in template names instead; the flag will be removed in the next release")
+	applyCmd.Flags().StringArrayVar(&applyPresets, "preset", []string{}, "Apply the options of this preset under the templates' own options (can be used multiple times, later presets win; see presets list)")
+	applyCmd.Flags().StringVar(&applyThresholdScale, "threshold-scale", "", "Multiply thresholds per environment after rendering, e.g. dev=2.0,hml=1.5 (default: threshold_scale in the spec, then in the config file)")
+	applyCmd.Flags().StringVar(&applyNameOverflow, "name-overflow", "error", "What to do with monitor names over 200 characters: error, truncate-hash or abbreviate (the service)")
+}
+
+// treeSection is one branch of the tree-shaped apply summary
+type treeSection struct {
+	title string
+	items []string
+}
+
+// printTree prints the apply summary as a tree under the given root line
+func printTree(root string, sections []treeSection) {
+	out.Println(root)
+	for i, section := range sections {
+		branch, indent := "├── ", "│   "
+		if i == len(sections)-1 {
+			branch, indent = "└── ", "    "
+		}
+		out.Printf("%s%s (%d)\n", branch, section.title, len(section.items))
+		for j, item := range section.items {
+			leaf := "├── "
+			if j == len(section.items)-1 {
+				leaf = "└── "
+			}
+			out.Printf("%s%s%s\n", indent, leaf, item)
+		}
+	}
+}
+
+func runApply(cmd *cobra.Command, args []string) error {
+	_, err := applyServiceSpec()
+	return err
+}
+
+// applyServiceSpec applies the service spec of --file and returns the results
+// of its monitors, also when a step fails; the daemon command runs it on
+// every sync
+func applyServiceSpec() ([]datadog.ApplyResult, error) {
+	nameOverflow, err := datadog.ParseNameOverflowPolicy(applyNameOverflow)
+	if err != nil {
+		return nil, err
+	}
+	matchByQuery, err := parseMatchBy(applyMatchBy)
+	if err != nil {
+		return nil, err
+	}
+	k8s, err := k8sDefaults(applyK8sDefaults, applyK8sEvalDelay, applyK8sGroupDelay)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := datadog.LoadServiceSpec(applyFile)
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return nil, err
+	}
+
+	for _, field := range []struct {
+		name  string
+		value *string
+	}{{"service", &spec.Service}, {"env", &spec.Env}, {"namespace", &spec.Namespace}} {
+		if *field.value, err = checkIdentityValue("spec "+field.name, *field.value); err != nil {
+			errOut.Printf("❌ Error: %v\n", err)
+			return nil, err
+		}
+	}
+
+	// Remote template sources (https://, git::) are fetched once, up front
+	for i, ref := range spec.Templates {
+		if spec.Templates[i].File, err = fetchTemplateSource(ref.File, applyRefresh); err != nil {
+			return nil, err
+		}
+	}
+
+	spec.Env, err = resolveEnv(spec.Env, applyAllowAnyEnv)
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return nil, err
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return nil, err
+	}
+	scale, err := thresholdScale(applyThresholdScale, spec.Env, spec.ThresholdScale)
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return nil, err
+	}
+	presets, err := presetOptions(applyPresets)
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return nil, err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return nil, err
+	}
+
+	out.Println("\n🚀 Applying service spec for:")
+	out.Printf("📦 Service: %s\n", spec.Service)
+	out.Printf("🌍 Environment: %s\n", spec.Env)
+	out.Printf("🏷️  Namespace: %s\n", spec.Namespace)
+	printThresholdScale(scale)
+	printPresets(applyPresets)
+	out.Println(strings.Repeat("=", 80))
+
+	root := fmt.Sprintf("📦 %s (%s/%s)", spec.Service, spec.Env, spec.Namespace)
+	monitors := treeSection{title: "Monitors"}
+	slos := treeSection{title: "SLOs"}
+	downtimes := treeSection{title: "Downtimes"}
+
+	// refOptions returns the options a template reference is applied with
+	refOptions := func(ref datadog.SpecTemplateRef) datadog.ApplyOptions {
+		return datadog.ApplyOptions{
+			RenderOptions: datadog.RenderOptions{
+				Service:             spec.Service,
+				Env:                 spec.Env,
+				Namespace:           spec.Namespace,
+				AdditionalTags:      append(append([]string{}, spec.Tags...), ref.Tags...),
+				Vars:                ref.Vars,
+				EnvAliases:          cfg.EnvAliases,
+				NameOverflow:        nameOverflow,
+				SingleValuedTagKeys: cfg.SingleValuedTagKeys,
+				UppercaseEnvInName:  applyUppercaseEnv,
+			},
+			Upsert:               true,
+			SkipSchemaValidation: applyNoSchema,
+			StrictScope:          applyStrictScope,
+			ProtectUnmanaged:     applyProtect,
+			MatchByQuery:         matchByQuery,
+			K8sDefaults:          k8s,
+			ThresholdScale:       scale,
+			FixNotifyBy:          applyFixNotifyBy,
+			PresetOptions:        presets,
+		}
+	}
+
+	// With --atomic, render and validate every monitor before the first write
+	var atomic *datadog.AtomicApply
+	var rendered []datadog.RenderedMonitor
+	if applyAtomic {
+		for _, ref := range spec.Templates {
+			monitors, err := datadog.RenderTemplateFile(ref.File, refOptions(ref))
+			if err != nil {
+				errOut.Printf("❌ Error rendering template %s: %v\n", filepath.Base(ref.File), err)
+				return nil, err
+			}
+			rendered = append(rendered, monitors...)
+		}
+		atomic = client.NewAtomicApply(refOptions(datadog.SpecTemplateRef{}))
+		if err := runPreflight(atomic, rendered); err != nil {
+			return nil, err
+		}
+	}
+
+	// fail prints what was applied before the failing step so reruns can be
+	// reasoned about; with --atomic it rolls the run back instead
+	fail := func(step string, err error) error {
+		if atomic != nil {
+			return rollbackAtomic(atomic, fmt.Errorf("applying %s: %w", step, err))
+		}
+		errOut.Printf("\n❌ Error applying %s: %v\n", step, err)
+		out.Println("\n⚠️  Partially applied (rerunning is safe, everything is upserted):")
+		printTree(root, []treeSection{monitors, slos, downtimes})
+		return err
+	}
+
+	// Monitors first: SLOs reference them by ID
+	monitorRefs := make(datadog.MonitorRefs)
+	var applied []datadog.ApplyResult
+	addResults := func(results []datadog.ApplyResult) {
+		applied = append(applied, results...)
+		for _, result := range results {
+			if result.Status == datadog.StatusSkipped {
+				monitors.items = append(monitors.items, fmt.Sprintf("⏭️  Skipped %s (%s)", result.TemplateName, result.SkipReason))
+				continue
+			}
+			if result.Status == datadog.StatusFailed {
+				monitors.items = append(monitors.items, fmt.Sprintf("❌ Failed %s: %v", result.TemplateName, result.Err))
+				continue
+			}
+			if result.Status == datadog.StatusConflict {
+				monitors.items = append(monitors.items, fmt.Sprintf("⛔ Conflict %s: Monitor ID %d is not managed by this tool (created by %s), left unchanged", result.Name, result.ID, formatCreator(result.Creator)))
+				continue
+			}
+			action := "🔄 Updated"
+			switch result.Status {
+			case datadog.StatusCreated:
+				action = "🆕 Created"
+			case datadog.StatusExisting:
+				action = "♻️  Already created"
+			case datadog.StatusUnchanged:
+				action = "✅ Up to date"
+			}
+			monitorRefs.Add(result.TemplateName, result.ID)
+			monitorRefs.Add(result.Name, result.ID)
+			item := fmt.Sprintf("%s %s: Monitor ID %d", action, result.Name, result.ID)
+			if result.RenamedFrom != "" {
+				item += fmt.Sprintf(" (matched by query, renamed from %q)", result.RenamedFrom)
+			}
+			monitors.items = append(monitors.items, item)
+			if result.NameOverflow != "" {
+				logVerbose("%s: %s", result.TemplateName, result.NameOverflow)
+			}
+			if len(result.K8sDefaults) > 0 {
+				monitors.items = append(monitors.items, fmt.Sprintf("⏱️  %s: added %s (Kubernetes defaults)", result.Name, strings.Join(result.K8sDefaults, ", ")))
+			}
+			if len(result.NotifyByDropped) > 0 {
+				monitors.items = append(monitors.items, fmt.Sprintf("⚠️  %s: dropped %s from notify_by (not in the query's group-by)", result.Name, strings.Join(result.NotifyByDropped, ", ")))
+			}
+			for _, r := range result.TagsReplaced {
+				monitors.items = append(monitors.items, fmt.Sprintf("🏷️  %s: replaced tag %s with %s", result.Name, r.From, r.To))
+			}
+			for _, mismatch := range result.ScopeWarnings {
+				monitors.items = append(monitors.items, fmt.Sprintf("⚠️  %s: %s", result.Name, mismatch))
+			}
+		}
+	}
+	if atomic != nil {
+		// Composites last, so the monitors they reference exist
+		for _, r := range datadog.OrderForApply(rendered) {
+			result, err := atomic.ApplyMonitor(r)
+			if err != nil {
+				return applied, fail(fmt.Sprintf("monitor %s", r.Monitor.Name), err)
+			}
+			addResults([]datadog.ApplyResult{result})
+		}
+	} else {
+		for _, ref := range spec.Templates {
+			results, err := client.ApplyTemplateWithOptions(ref.File, refOptions(ref))
+			addResults(results)
+			if err != nil {
+				return applied, fail(fmt.Sprintf("template %s", filepath.Base(ref.File)), err)
+			}
+		}
+	}
+
+	upsertSLO := client.UpsertSLO
+	upsertDowntime := client.UpsertDowntime
+	if atomic != nil {
+		upsertSLO = atomic.ApplySLO
+		upsertDowntime = atomic.ApplyDowntime
+	}
+
+	for _, specSLO := range spec.SLOs {
+		slo, err := spec.BuildSLO(specSLO, monitorRefs)
+		if err != nil {
+			return applied, fail(fmt.Sprintf("SLO %s", specSLO.Name), err)
+		}
+		result, wasCreated, err := upsertSLO(slo)
+		if err != nil {
+			return applied, fail(fmt.Sprintf("SLO %s", slo.Name), err)
+		}
+		action := "🆕 Created"
+		if !wasCreated {
+			action = "🔄 Updated"
+		}
+		slos.items = append(slos.items, fmt.Sprintf("%s %s: SLO ID %s", action, result.Name, result.ID))
+	}
+
+	for _, specDowntime := range spec.Downtimes {
+		downtime := spec.BuildDowntime(specDowntime)
+		result, wasCreated, err := upsertDowntime(downtime)
+		if err != nil {
+			return applied, fail(fmt.Sprintf("downtime %s", specDowntime.Name), err)
+		}
+		action := "🆕 Created"
+		if !wasCreated {
+			action = "🔄 Updated"
+		}
+		downtimes.items = append(downtimes.items, fmt.Sprintf("%s %s: Downtime ID %d", action, specDowntime.Name, result.ID))
+	}
+
+	out.Println("\n✅ Service spec applied:")
+	printTree(root, []treeSection{monitors, slos, downtimes})
+	conflicts := printUnmanagedSummary(applied)
+	if err := printFailedTemplates(applied); conflicts == nil {
+		conflicts = err
+	}
+	return applied, conflicts
+}
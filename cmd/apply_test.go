@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+func TestApplyServiceSpec(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+	spec := writeServiceSpec(t)
+
+	res := runCLI(t, nil, "apply", "-f", spec)
+	if res.Err != nil {
+		t.Fatalf("apply: %v\n%s", res.Err, res.Stderr)
+	}
+	srv.AssertRequestCount(t, 2, "POST", "/monitor")
+	srv.AssertSnapshot(t, "testdata/after-apply.json")
+
+	// Applying the same spec again changes nothing
+	srv.ResetRequests()
+	res = runCLI(t, nil, "apply", "-f", spec)
+	if res.Err != nil {
+		t.Fatalf("second apply: %v\n%s", res.Err, res.Stderr)
+	}
+	srv.AssertNoMutations(t)
+	if strings.Count(res.Stdout, "Up to date") != 2 {
+		t.Errorf("second apply did not report both monitors up to date:\n%s", res.Stdout)
+	}
+}
+
+func TestApplyServiceSpecRestoresDrift(t *testing.T) {
+	srv := newTestServer(t)
+	spec := writeServiceSpec(t)
+	if res := runCLI(t, nil, "apply", "-f", spec); res.Err != nil {
+		t.Fatalf("apply: %v\n%s", res.Err, res.Stderr)
+	}
+
+	// A monitor changed by hand is brought back to the template
+	edited, _ := srv.Monitor(1000)
+	want := edited.Query
+	edited.Query = "sum(last_5m):sum:http.errors{service:checkout,env:prd}.as_count() > 500"
+	srv.AddMonitor(edited)
+
+	res := runCLI(t, nil, "apply", "-f", spec)
+	if res.Err != nil {
+		t.Fatalf("apply: %v\n%s", res.Err, res.Stderr)
+	}
+	srv.AssertRequestCount(t, 1, "PUT", "/monitor/1000")
+	srv.AssertRequestCount(t, 0, "PUT", "/monitor/1001")
+	if m, _ := srv.Monitor(1000); m.Query != want {
+		t.Errorf("query %q, want %q", m.Query, want)
+	}
+}
+
+func TestApplyMatchByQuery(t *testing.T) {
+	srv := newTestServer(t)
+	// Renamed in the UI, with a tuned threshold and no template identity
+	srv.AddMonitor(datadog.Monitor{
+		Name:  "checkout errors (renamed)",
+		Type:  "query alert",
+		Query: "sum(last_5m):sum:http.errors{service:checkout,env:prd}.as_count() > 50",
+		Tags:  []string{"service:checkout", "env:prd", "managed-by:ddmm"},
+	})
+	spec := writeServiceSpec(t)
+
+	res := runCLI(t, nil, "apply", "-f", spec, "--match-by", "query")
+	if res.Err != nil {
+		t.Fatalf("apply: %v\n%s", res.Err, res.Stderr)
+	}
+	srv.AssertRequestCount(t, 1, "PUT", "/monitor/1000")
+	srv.AssertRequestCount(t, 1, "POST", "/monitor")
+	if m, _ := srv.Monitor(1000); m.Name != "Monitor checkout - Error Rate" {
+		t.Errorf("matched monitor has name %q, want the template's", m.Name)
+	}
+	if !strings.Contains(res.Stdout, `renamed from "checkout errors (renamed)"`) {
+		t.Errorf("apply did not report the rename:\n%s", res.Stdout)
+	}
+}
+
+func TestApplyMatchByQueryAmbiguous(t *testing.T) {
+	srv := newTestServer(t)
+	for _, name := range []string{"copy A", "copy B"} {
+		srv.AddMonitor(datadog.Monitor{
+			Name:  name,
+			Type:  "metric alert",
+			Query: "sum(last_5m):sum:http.errors{service:checkout,env:prd}.as_count() > 10",
+		})
+	}
+	spec := writeServiceSpec(t)
+
+	res := runCLI(t, nil, "apply", "-f", spec, "--match-by", "query")
+	if res.Err == nil {
+		t.Fatal("apply with an ambiguous query match succeeded")
+	}
+	if output := res.Stdout + res.Stderr; !strings.Contains(output, "(IDs 1000, 1001)") {
+		t.Errorf("candidate IDs not listed:\n%s", output)
+	}
+	// Only the ambiguous template is left out
+	srv.AssertRequestCount(t, 0, "PUT", "/monitor/1000")
+	srv.AssertRequestCount(t, 0, "PUT", "/monitor/1001")
+	srv.AssertRequestCount(t, 1, "POST", "/monitor")
+}
+
+func TestApplyQueryScopeMismatch(t *testing.T) {
+	srv := newTestServer(t)
+	spec := writeServiceSpec(t)
+	// A template author hardcoded the env instead of using {env}
+	editTemplateFile(t, spec, "env:{env}}.as_count()", "env:dev}.as_count()")
+
+	res := runCLI(t, nil, "apply", "-f", spec)
+	if res.Err != nil {
+		t.Fatalf("apply: %v\n%s", res.Err, res.Stderr)
+	}
+	if !strings.Contains(res.Stdout, "query scope has env:dev but env is prd") {
+		t.Errorf("apply did not warn about the scope:\n%s", res.Stdout)
+	}
+	srv.AssertRequestCount(t, 2, "POST", "/monitor")
+
+	// With --strict-scope nothing is applied from the mismatched template
+	srv = newTestServer(t)
+	res = runCLI(t, nil, "apply", "-f", spec, "--strict-scope")
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "query scope has env:dev but env is prd") {
+		t.Fatalf("apply --strict-scope = %v", res.Err)
+	}
+	srv.AssertRequestCount(t, 0, "POST", "/monitor")
+}
+
+func TestApplyDowntimeMessageChange(t *testing.T) {
+	srv := newTestServer(t)
+	spec := writeServiceSpec(t)
+	appendSpec := func(text string) {
+		t.Helper()
+		f, err := os.OpenFile(spec, os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(text); err != nil {
+			t.Fatal(err)
+		}
+	}
+	appendSpec(`downtimes:
+  - name: "{service} deploys"
+    scope: ["env:{env}"]
+    message: Weekly deploys
+`)
+	if res := runCLI(t, nil, "apply", "-f", spec); res.Err != nil {
+		t.Fatalf("apply: %v\n%s", res.Err, res.Stderr)
+	}
+	created := srv.Downtimes()
+	if len(created) != 1 || created[0].Message != "[checkout deploys] Weekly deploys" {
+		t.Fatalf("downtimes after the first apply: %+v", created)
+	}
+
+	// The downtime is found by its name when its message changes
+	editSpec(t, spec, "message: Weekly deploys", "message: Deploys on Tuesdays")
+	srv.ResetRequests()
+	res := runCLI(t, nil, "apply", "-f", spec)
+	if res.Err != nil {
+		t.Fatalf("apply: %v\n%s", res.Err, res.Stderr)
+	}
+	srv.AssertRequestCount(t, 1, "PUT", "/downtime/"+strconv.Itoa(created[0].ID))
+	srv.AssertRequestCount(t, 0, "POST", "/downtime")
+	downtimes := srv.Downtimes()
+	if len(downtimes) != 1 || downtimes[0].Message != "[checkout deploys] Deploys on Tuesdays" {
+		t.Errorf("downtimes after changing the message: %+v", downtimes)
+	}
+
+	// A downtime whose name only starts the same is another downtime
+	editSpec(t, spec, `name: "{service} deploys"`, `name: "{service} deploys 2"`)
+	srv.ResetRequests()
+	if res := runCLI(t, nil, "apply", "-f", spec); res.Err != nil {
+		t.Fatalf("apply: %v\n%s", res.Err, res.Stderr)
+	}
+	srv.AssertRequestCount(t, 0, "PUT", "/downtime/"+strconv.Itoa(created[0].ID))
+	srv.AssertRequestCount(t, 1, "POST", "/downtime")
+}
+
+// editSpec replaces the first occurrence of from in a spec file
+func editSpec(t *testing.T, spec, from, to string) {
+	t.Helper()
+	data, err := os.ReadFile(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), from) {
+		t.Fatalf("spec has no %q", from)
+	}
+	if err := os.WriteFile(spec, []byte(strings.Replace(string(data), from, to, 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+// runPreflight checks every rendered monitor of an --atomic apply before
+// anything is written, listing all the failures at once
+func runPreflight(atomic *datadog.AtomicApply, rendered []datadog.RenderedMonitor) error {
+	out.Printf("🔎 Pre-flight: validating %d monitor(s) before applying anything...\n", len(rendered))
+	failures, err := atomic.Preflight(rendered)
+	if err != nil {
+		errOut.Printf("❌ Error during pre-flight: %v\n", err)
+		return err
+	}
+	if len(failures) == 0 {
+		out.Println("✅ Pre-flight passed")
+		return nil
+	}
+
+	errOut.Printf("\n❌ Pre-flight failed for %d monitor(s), nothing was applied:\n", len(failures))
+	for _, failure := range failures {
+		errOut.Printf("   ⚠️  %s (%s): %v\n", failure.Name, failure.TemplateName, failure.Err)
+	}
+	return fmt.Errorf("pre-flight failed for %d monitor(s): %w", len(failures), failures[0].Err)
+}
+
+// rollbackAtomic undoes the writes of a failed --atomic apply and prints the
+// rollback report. Changes that could not be undone are listed on stderr so
+// they can be fixed by hand.
+func rollbackAtomic(atomic *datadog.AtomicApply, cause error) error {
+	errOut.Printf("\n❌ Error: %v\n", cause)
+
+	changes := atomic.Rollback()
+	if len(changes) == 0 {
+		out.Println("ℹ️  Nothing was written, nothing to roll back")
+		return cause
+	}
+
+	out.Printf("\n↩️  Rolling back %d change(s):\n", len(changes))
+	var failed []datadog.AtomicChange
+	for _, change := range changes {
+		if change.Err != nil {
+			failed = append(failed, change)
+			out.Printf("   ❌ Could not %s %s %s: %s - %v\n", change.Action(), change.Kind, change.ID, change.Name, change.Err)
+			continue
+		}
+		if change.Created {
+			out.Printf("   🗑️  Deleted %s %s: %s\n", change.Kind, change.ID, change.Name)
+		} else {
+			out.Printf("   ↩️  Restored %s %s: %s\n", change.Kind, change.ID, change.Name)
+		}
+	}
+
+	if len(failed) > 0 {
+		errOut.Printf("\n🚨 ROLLBACK INCOMPLETE: %d of %d change(s) could not be undone and must be fixed by hand:\n", len(failed), len(changes))
+		for _, change := range failed {
+			errOut.Printf("   🚨 %s %s %s: %s - %v\n", change.Action(), change.Kind, change.ID, change.Name, change.Err)
+		}
+		return &datadog.RollbackIncompleteError{Failed: len(failed), Err: cause}
+	}
+
+	out.Printf("✅ Rolled back: none of the changes of this run remain\n")
+	return fmt.Errorf("atomic apply rolled back: %w", cause)
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadogtest"
+)
+
+// addLatencyMonitor adds the monitor the Latency template of writeServiceSpec
+// updates, as it was before the apply
+func addLatencyMonitor(srv *datadogtest.Server) datadog.Monitor {
+	return srv.AddMonitor(datadog.Monitor{
+		Name:    "Monitor checkout - Latency",
+		Type:    "query alert",
+		Query:   "avg(last_5m):avg:http.request.duration{service:checkout,env:prd} > 5",
+		Message: "Latency too high, set by hand",
+		Tags:    []string{datadog.ManagedByTag, "team:payments"},
+		Options: map[string]interface{}{"thresholds": map[string]interface{}{"critical": 5.0}, "notify_no_data": true},
+	})
+}
+
+// appendDowntime adds a downtime to a service spec, applied after the monitors
+func appendDowntime(t *testing.T, spec string) {
+	t.Helper()
+	f, err := os.OpenFile(spec, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString("downtimes:\n  - name: \"{service} deploys\"\n    scope: [\"env:{env}\"]\n    message: Weekly deploys\n"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApplyAtomicPreflight(t *testing.T) {
+	srv := newTestServer(t)
+	spec := writeServiceSpec(t)
+	srv.AddMonitor(datadog.Monitor{Name: "Monitor checkout - Error Rate", Type: "query alert", Query: "sum(last_5m):sum:http.errors{service:checkout} > 1", Creator: &datadog.Creator{Email: "someone@example.com"}})
+	srv.Validate = func(monitor datadog.Monitor) []string {
+		if strings.Contains(monitor.Query, "http.request.duration") {
+			return []string{"unknown metric http.request.duration"}
+		}
+		return nil
+	}
+	srv.ResetRequests()
+
+	res := runCLI(t, nil, "apply", "-f", spec, "--atomic", "--protect-unmanaged")
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "pre-flight failed for 2 monitor(s)") {
+		t.Fatalf("apply --atomic = %v, want a pre-flight failure for both monitors\n%s", res.Err, res.Stderr)
+	}
+	// Every failure is listed, and nothing is written
+	for _, want := range []string{
+		"Pre-flight failed for 2 monitor(s), nothing was applied",
+		"Monitor checkout - Error Rate (Error Rate):",
+		"Monitor checkout - Latency (Latency): monitor \"Monitor checkout - Latency\" is invalid: unknown metric http.request.duration",
+	} {
+		if !strings.Contains(res.Stderr, want) {
+			t.Errorf("stderr has no %q:\n%s", want, res.Stderr)
+		}
+	}
+	srv.AssertNoMutations(t)
+	srv.AssertRequestCount(t, 1, "POST", "/monitor/validate")
+}
+
+func TestApplyAtomicRollback(t *testing.T) {
+	srv := newTestServer(t)
+	spec := writeServiceSpec(t)
+	appendDowntime(t, spec)
+	before := addLatencyMonitor(srv)
+	srv.InjectFault(datadogtest.ServerError("POST", "/downtime", 0))
+	srv.ResetRequests()
+
+	res := runCLI(t, nil, "apply", "-f", spec, "--atomic")
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "atomic apply rolled back: applying downtime {service} deploys") {
+		t.Fatalf("apply --atomic = %v, want a rolled back apply\n%s", res.Err, res.Stderr)
+	}
+	if code := ExitCode(res.Err); code != ExitFailure {
+		t.Errorf("exit code %d, want %d", code, ExitFailure)
+	}
+
+	// The monitor created mid-apply is deleted, the updated one restored
+	created := srv.RequestsTo("POST", "/monitor")
+	if len(created) != 1 {
+		t.Fatalf("%d monitors created, want 1", len(created))
+	}
+	createdID := before.ID + 1
+	srv.AssertRequestCount(t, 1, "DELETE", "/monitor/"+strconv.Itoa(createdID))
+	srv.AssertRequestCount(t, 2, "PUT", "/monitor/"+strconv.Itoa(before.ID))
+	monitors := srv.Monitors()
+	if len(monitors) != 1 {
+		t.Fatalf("monitors after the rollback: %+v", monitors)
+	}
+	after := monitors[0]
+	if after.Query != before.Query || after.Message != before.Message || !reflect.DeepEqual(after.Tags, before.Tags) || !reflect.DeepEqual(after.Options, before.Options) {
+		t.Errorf("monitor after the rollback = %+v, want it restored to %+v", after, before)
+	}
+	if len(srv.Downtimes()) != 0 {
+		t.Errorf("downtimes after the rollback: %+v", srv.Downtimes())
+	}
+
+	for _, want := range []string{
+		"Rolling back 2 change(s):",
+		"Deleted monitor " + strconv.Itoa(createdID) + ": Monitor checkout - Error Rate",
+		"Restored monitor " + strconv.Itoa(before.ID) + ": Monitor checkout - Latency",
+		"Rolled back: none of the changes of this run remain",
+	} {
+		if !strings.Contains(res.Stdout, want) {
+			t.Errorf("stdout has no %q:\n%s", want, res.Stdout)
+		}
+	}
+	// Newest first: the update was made after the create
+	if strings.Index(res.Stdout, "Restored monitor") > strings.Index(res.Stdout, "Deleted monitor") {
+		t.Errorf("the rollback did not undo the newest change first:\n%s", res.Stdout)
+	}
+}
+
+func TestApplyAtomicRollbackIncomplete(t *testing.T) {
+	srv := newTestServer(t)
+	spec := writeServiceSpec(t)
+	appendDowntime(t, spec)
+	before := addLatencyMonitor(srv)
+	srv.InjectFault(datadogtest.ServerError("POST", "/downtime", 0))
+	srv.InjectFault(datadogtest.ServerError("DELETE", "/monitor/*", 0))
+
+	res := runCLI(t, nil, "apply", "-f", spec, "--atomic")
+	if !datadog.IsRollbackIncomplete(res.Err) {
+		t.Fatalf("apply --atomic = %v, want an incomplete rollback\n%s", res.Err, res.Stderr)
+	}
+	if code := ExitCode(res.Err); code != ExitRollback {
+		t.Errorf("exit code %d, want %d", code, ExitRollback)
+	}
+
+	// The monitor left behind is reported; the update is still undone
+	createdID := strconv.Itoa(before.ID + 1)
+	for _, want := range []string{
+		"ROLLBACK INCOMPLETE: 1 of 2 change(s) could not be undone and must be fixed by hand:",
+		"delete monitor " + createdID + ": Monitor checkout - Error Rate",
+	} {
+		if !strings.Contains(res.Stderr, want) {
+			t.Errorf("stderr has no %q:\n%s", want, res.Stderr)
+		}
+	}
+	if !strings.Contains(res.Stdout, "Could not delete monitor "+createdID) {
+		t.Errorf("stdout does not report the failed delete:\n%s", res.Stdout)
+	}
+	if _, ok := srv.Monitor(before.ID + 1); !ok {
+		t.Errorf("monitor %s was deleted, want it left behind", createdID)
+	}
+	if after, _ := srv.Monitor(before.ID); after.Message != before.Message {
+		t.Errorf("monitor %d message %q, want it restored to %q", before.ID, after.Message, before.Message)
+	}
+}
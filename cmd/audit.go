@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/audit"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Local audit log of changes made with this tool, and the Datadog Audit Trail",
+	Long: `Every mutating API call (create, update, delete, mute, ...) is appended to a
+local JSON lines audit log when one is configured with DDMM_AUDIT_LOG or
+audit_log in the config file. Each entry records the time, the OS user, the
+command line (with secrets redacted), the affected monitor, SLO or downtime,
+the previous value of updated or deleted monitors and the outcome.
+
+Writing the audit log is best-effort: a failure to write it prints a warning
+but never fails the command.
+
+audit remote shows the changes the Datadog Audit Trail recorded for a monitor,
+whatever made them.`,
+}
+
+var auditShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the latest audit log entries",
+	Long: `Show the latest entries of the audit log, oldest first.
+
+Examples:
+  audit show
+  audit show --last 50
+  audit show --output json`,
+	RunE: runAuditShow,
+}
+
+var (
+	auditShowLast   int
+	auditShowOutput string
+)
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditShowCmd)
+	auditShowCmd.Flags().IntVar(&auditShowLast, "last", 20, "Number of entries to show (0 for all)")
+	auditShowCmd.Flags().StringVarP(&auditShowOutput, "output", "o", "table", "Output format: table or json")
+}
+
+// newAuditLog returns the audit log of this invocation, warning once on
+// stderr when entries can't be written
+func newAuditLog(path string) *audit.Log {
+	log := audit.NewLog(path, audit.RedactArgs(os.Args))
+	var warnOnce sync.Once
+	log.OnError = func(err error) {
+		warnOnce.Do(func() {
+			errOut.Printf("⚠️  Warning: %v\n", err)
+		})
+	}
+	return log
+}
+
+func runAuditShow(cmd *cobra.Command, args []string) error {
+	if auditShowOutput != "table" && auditShowOutput != "json" {
+		return fmt.Errorf("invalid --output %q (must be table or json)", auditShowOutput)
+	}
+	if auditShowLast < 0 {
+		return fmt.Errorf("--last must not be negative")
+	}
+
+	path, err := auditLogPath()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+	if path == "" {
+		err := fmt.Errorf("no audit log configured: set %s or audit_log in the config file", audit.PathEnv)
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	entries, err := audit.Read(path, auditShowLast)
+	if os.IsNotExist(err) {
+		entries, err = nil, nil
+	}
+	if err != nil {
+		errOut.Printf("❌ Error reading audit log: %v\n", err)
+		return err
+	}
+
+	if auditShowOutput == "json" {
+		if entries == nil {
+			entries = []audit.Entry{}
+		}
+		jsonData, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	if len(entries) == 0 {
+		out.Printf("ℹ️  No entries in audit log %s\n", path)
+		return nil
+	}
+
+	out.Printf("\n📒 Audit log %s (last %d entries):\n", path, len(entries))
+	out.Println(strings.Repeat("=", 80))
+	for _, entry := range entries {
+		icon := "✅"
+		if entry.Outcome != audit.OutcomeSuccess {
+			icon = "❌"
+		}
+		target := entry.Resource
+		if entry.ID != "" {
+			target += " " + entry.ID
+		}
+		if entry.Name != "" {
+			target += fmt.Sprintf(" (%s)", entry.Name)
+		}
+		out.Printf("%s %s  %-8s %-6s %s\n", icon, formatTime(entry.Time), entry.User, entry.Method, target)
+		out.Printf("      $ %s\n", entry.Command)
+		if entry.Error != "" {
+			out.Printf("      %s: %s\n", entry.Outcome, entry.Error)
+		}
+	}
+
+	return nil
+}
+
+var auditRemoteCmd = &cobra.Command{
+	Use:   "remote",
+	Short: "Show the Datadog Audit Trail of a monitor",
+	Long: `Show who changed a monitor and when, from the Datadog Audit Trail (audit
+events API v2): every change since --since, oldest first, with the actor and,
+when the event records the monitor before and after, the changed fields.
+
+Unlike audit show, this covers changes made anywhere (UI, Terraform, other
+tools), but needs Audit Trail enabled for the organization and an application
+key with the audit_logs_read permission. The API v2 feature flag must be on
+(DDMM_API_V2=1 or api_v2: true in the config file).
+
+Examples:
+  audit remote --monitor-id 12345
+  audit remote --monitor-id 12345 --since 7d
+  audit remote --monitor-id 12345 --output json`,
+	RunE: runAuditRemote,
+}
+
+var (
+	auditRemoteMonitorID int
+	auditRemoteSince     string
+	auditRemoteOutput    string
+)
+
+func init() {
+	auditCmd.AddCommand(auditRemoteCmd)
+	auditRemoteCmd.Flags().IntVar(&auditRemoteMonitorID, "monitor-id", 0, "Monitor ID (required)")
+	auditRemoteCmd.Flags().StringVar(&auditRemoteSince, "since", defaultAuditSince, "How far back to list changes (e.g., 12h, 7d, 2w)")
+	auditRemoteCmd.Flags().StringVarP(&auditRemoteOutput, "output", "o", "table", "Output format: table or json")
+	auditRemoteCmd.MarkFlagRequired("monitor-id")
+}
+
+// defaultAuditSince is how far back the Audit Trail of a monitor is listed
+const defaultAuditSince = "30d"
+
+// auditSince parses a --since value for the Audit Trail
+func auditSince(value string) (time.Time, error) {
+	since, err := parseDuration(value)
+	if err != nil || since <= 0 {
+		return time.Time{}, fmt.Errorf("invalid --since %q (e.g., 12h, 7d, 2w)", value)
+	}
+	return time.Now().Add(-since), nil
+}
+
+func runAuditRemote(cmd *cobra.Command, args []string) error {
+	if auditRemoteOutput != "table" && auditRemoteOutput != "json" {
+		return fmt.Errorf("invalid --output %q (must be table or json)", auditRemoteOutput)
+	}
+	since, err := auditSince(auditRemoteSince)
+	if err != nil {
+		return err
+	}
+	if err := requireAPIV2("audit remote"); err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	events, err := client.MonitorAuditEvents(auditRemoteMonitorID, since)
+	if err != nil {
+		errOut.Printf("❌ Error listing audit events: %v\n", err)
+		return err
+	}
+
+	if auditRemoteOutput == "json" {
+		if events == nil {
+			events = []datadog.AuditEvent{}
+		}
+		jsonData, err := json.MarshalIndent(events, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	printAuditEvents(auditRemoteMonitorID, events, auditRemoteSince)
+	return nil
+}
+
+// printAuditEvents prints the Audit Trail events of a monitor, oldest first
+func printAuditEvents(monitorID int, events []datadog.AuditEvent, since string) {
+	if len(events) == 0 {
+		out.Printf("ℹ️  No Audit Trail events for monitor %d in the last %s\n", monitorID, since)
+		return
+	}
+
+	out.Printf("\n🕵️  Audit Trail of monitor %d (last %s, %d event(s)):\n", monitorID, since, len(events))
+	out.Println(strings.Repeat("=", 80))
+	for _, event := range events {
+		actor := event.Actor
+		if actor == "" {
+			actor = "(unknown)"
+		}
+		out.Printf("%s  %-9s %s\n", formatTime(event.Time), event.Action, actor)
+		if len(event.ChangedFields) > 0 {
+			out.Printf("      Changed: %s\n", strings.Join(event.ChangedFields, ", "))
+		} else if event.Message != "" {
+			out.Printf("      %s\n", event.Message)
+		}
+	}
+}
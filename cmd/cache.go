@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect or clear the monitor cache (--cache)",
+	Long: `Inspect or clear the monitor inventory cached by --cache.
+
+With the global --cache flag, read-only commands (list, describe, export,
+graph, teams report, handles report) load every monitor once, store them in
+the cache file and filter them locally on later runs until the inventory is
+older than --cache-ttl (default 5m). Filters the API has to evaluate (--query,
+free-text search, --group-states) always fetch live. Commands that change
+monitors never read the cache and clear it.
+
+Examples:
+  cache status
+  cache clear`,
+}
+
+var cacheStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the cache file, its age and size",
+	RunE:  runCacheStatus,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete the cached monitor inventory",
+	RunE:  runCacheClear,
+}
+
+var (
+	// useCache is --cache: serve read-only commands from the monitor inventory
+	useCache bool
+	// cacheTTL is --cache-ttl: how long the inventory is served before a refetch
+	cacheTTL time.Duration
+)
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheStatusCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+}
+
+// cachedMonitors returns every monitor from the inventory when it is fresh,
+// and otherwise lists them from the API and rewrites the inventory. A corrupt
+// or unwritable cache file is reported and the live list is used.
+func cachedMonitors(client *datadog.Client) ([]datadog.Monitor, error) {
+	path := datadog.DefaultInventoryPath()
+	now := time.Now()
+	inventory, err := datadog.LoadInventory(path)
+	switch {
+	case err == nil && inventory.Fresh(client.InventoryKey(), cacheTTL, now):
+		logVerbose("--cache: %d monitor(s) from %s, fetched %s ago", len(inventory.Monitors), path, formatDuration(inventory.Age(now)))
+		return inventory.Monitors, nil
+	case err == nil:
+		logVerbose("--cache: %s is stale or for other credentials, refreshing", path)
+	case errors.Is(err, os.ErrNotExist):
+		logVerbose("--cache: no monitor cache yet, fetching every monitor")
+	default:
+		errOut.Printf("⚠️  %v; fetching monitors from the API\n", err)
+	}
+
+	monitors, err := client.ListMonitorsWithOptions(datadog.ListMonitorsOptions{})
+	if err != nil {
+		return nil, err
+	}
+	inventory = &datadog.Inventory{Key: client.InventoryKey(), FetchedAt: now, Monitors: monitors}
+	if err := datadog.SaveInventory(path, inventory); err != nil {
+		errOut.Printf("⚠️  Could not write the monitor cache: %v\n", err)
+	}
+	return monitors, nil
+}
+
+func runCacheStatus(cmd *cobra.Command, args []string) error {
+	path := datadog.DefaultInventoryPath()
+
+	out.Printf("📁 Cache file: %s\n", path)
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		out.Println("ℹ️  Empty: no monitors cached (run a read-only command with --cache)")
+		return nil
+	}
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+	inventory, err := datadog.LoadInventory(path)
+	if err != nil {
+		out.Printf("⚠️  %v\n", err)
+		out.Println("💡 It is ignored and rewritten on the next --cache run, or remove it with: datadog-monitor-manager cache clear")
+		return nil
+	}
+
+	now := time.Now()
+	out.Printf("📦 Monitors: %d (%s)\n", len(inventory.Monitors), formatBytes(info.Size()))
+	out.Printf("🕐 Fetched: %s (%s old)\n", formatTime(inventory.FetchedAt), formatDuration(inventory.Age(now)))
+
+	state := "fresh"
+	if inventory.Age(now) >= cacheTTL {
+		state = "stale, refetched on the next --cache run"
+	}
+	out.Printf("⏳ TTL: %s (%s)\n", cacheTTL, state)
+	if client, err := newClient(); err == nil && inventory.Key != client.InventoryKey() {
+		out.Println("⚠️  Cached for other credentials or another site; refetched on the next --cache run")
+	}
+	return nil
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	path := datadog.DefaultInventoryPath()
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		out.Println("ℹ️  The monitor cache is already empty")
+		return nil
+	}
+	if err := datadog.ClearInventory(path); err != nil {
+		errOut.Printf("❌ Error clearing the monitor cache: %v\n", err)
+		return err
+	}
+	out.Printf("✅ Cleared the monitor cache (%s)\n", path)
+	return nil
+}
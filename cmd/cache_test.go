@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+func TestCacheServesReadOnlyCommands(t *testing.T) {
+	srv := newTestServer(t)
+	monitor := srv.AddMonitor(datadog.Monitor{Name: "CPU", Type: "metric alert", Query: "avg(last_5m):avg:cpu{service:api} > 90", Tags: []string{"service:api"}})
+	srv.AddMonitor(datadog.Monitor{Name: "Disk", Type: "metric alert", Query: "avg(last_5m):avg:disk{service:db} > 90", Tags: []string{"service:db"}})
+
+	export := func(args ...string) cliResult {
+		t.Helper()
+		res := runCLI(t, nil, append([]string{"export", "--format", "json", "--cache", "--service", "api"}, args...)...)
+		if res.Err != nil {
+			t.Fatalf("export: %v\n%s", res.Err, res.Stderr)
+		}
+		if !strings.Contains(res.Stdout, `"CPU"`) || strings.Contains(res.Stdout, `"Disk"`) {
+			t.Errorf("export --service api:\n%s", res.Stdout)
+		}
+		return res
+	}
+
+	// The first run fetches every monitor, the second is served from the cache
+	export()
+	srv.AssertRequestCount(t, 1, "GET", "/monitor")
+	srv.ResetRequests()
+	export()
+	srv.AssertRequestCount(t, 0, "GET", "/monitor")
+
+	// An expired cache is fetched again
+	export("--cache-ttl", "1ns")
+	srv.AssertRequestCount(t, 1, "GET", "/monitor")
+
+	// A corrupt cache falls back to the API
+	if err := os.WriteFile(datadog.DefaultInventoryPath(), []byte(`{"monitors": [`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	srv.ResetRequests()
+	res := export()
+	srv.AssertRequestCount(t, 1, "GET", "/monitor")
+	if !strings.Contains(res.Stderr, "corrupt monitor cache") {
+		t.Errorf("no warning about the corrupt cache:\n%s", res.Stderr)
+	}
+
+	// A change through the tool clears the cache, so the next run sees it
+	if res := runCLI(t, nil, "add-tags", "--yes", "--tag", "team:sre", strconv.Itoa(monitor.ID)); res.Err != nil {
+		t.Fatalf("add-tags: %v\n%s", res.Err, res.Stderr)
+	}
+	if _, err := os.Stat(datadog.DefaultInventoryPath()); !os.IsNotExist(err) {
+		t.Errorf("cache not cleared by add-tags: %v", err)
+	}
+	srv.ResetRequests()
+	if res := export(); !strings.Contains(res.Stdout, "team:sre") {
+		t.Errorf("export after add-tags lacks the new tag:\n%s", res.Stdout)
+	}
+	srv.AssertRequestCount(t, 1, "GET", "/monitor")
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+// addCheckpointFlags registers --checkpoint-file and --chunk-size on a bulk
+// delete command
+func addCheckpointFlags(cmd *cobra.Command, file *string, chunkSize *int) {
+	cmd.Flags().StringVar(file, "checkpoint-file", "", "Record the monitors to process and those done in this file; rerunning the same command resumes from it")
+	cmd.Flags().IntVar(chunkSize, "chunk-size", 0, "Process the monitors in chunks of this size, asking to continue between chunks unless --yes (default: all at once)")
+}
+
+// resumeCheckpoint loads the --checkpoint-file of operation; nil for a new
+// run. A checkpoint written for another operation (other filters) is
+// refused: resuming it would act on monitors the command line doesn't select.
+func resumeCheckpoint(path, operation string) (*datadog.Checkpoint, error) {
+	if path == "" {
+		return nil, nil
+	}
+	checkpoint, err := datadog.LoadCheckpoint(path)
+	if err != nil || checkpoint == nil {
+		return nil, err
+	}
+	if checkpoint.Operation != operation {
+		return nil, fmt.Errorf("checkpoint file %s was written by %q, not %q: rerun that command to resume it, or remove the file", path, checkpoint.Operation, operation)
+	}
+	done := len(checkpoint.Targets) - len(checkpoint.Remaining())
+	out.Printf("\n📌 Resuming from %s (started %s): %d of %d monitor(s) done, %d left\n",
+		path, formatTime(checkpoint.Created), done, len(checkpoint.Targets), len(checkpoint.Targets)-done)
+	return checkpoint, nil
+}
+
+// startCheckpoint writes the checkpoint of a new run with its target
+// monitors before the first change; nil without --checkpoint-file
+func startCheckpoint(path, operation string, monitors []datadog.Monitor) (*datadog.Checkpoint, error) {
+	if path == "" {
+		return nil, nil
+	}
+	checkpoint := datadog.NewCheckpoint(path, operation, monitors, time.Now())
+	if err := checkpoint.Save(); err != nil {
+		return nil, fmt.Errorf("failed to write checkpoint file %s: %v", path, err)
+	}
+	return checkpoint, nil
+}
+
+// finishCheckpoint removes the checkpoint file once every target is done, or
+// tells how to resume the rest
+func finishCheckpoint(checkpoint *datadog.Checkpoint, path string) {
+	if checkpoint == nil {
+		return
+	}
+	remaining := len(checkpoint.Remaining())
+	if remaining > 0 {
+		out.Printf("\n📌 %d monitor(s) left; rerun the same command to resume from %s\n", remaining, path)
+		return
+	}
+	if err := os.Remove(path); err != nil {
+		errOut.Printf("⚠️  Failed to remove checkpoint file %s: %v\n", path, err)
+		return
+	}
+	logVerbose("every monitor of the checkpoint is done, removed %s", path)
+}
+
+// forEachChunk calls fn with monitors in chunks of size (a single chunk when
+// size is 0), asking to continue before each chunk after the first one unless
+// --yes. It returns false when the user stopped between chunks.
+func forEachChunk(monitors []datadog.Monitor, size int, action string, fn func(chunk []datadog.Monitor) error) (bool, error) {
+	if size <= 0 {
+		size = len(monitors)
+	}
+	for start := 0; start < len(monitors); start += size {
+		end := start + size
+		if end > len(monitors) {
+			end = len(monitors)
+		}
+		if start > 0 {
+			out.Printf("\n⏸️  %d of %d monitor(s) processed\n", start, len(monitors))
+			confirmed, err := confirm(end-start, action, nil)
+			if err != nil || !confirmed {
+				return false, err
+			}
+		}
+		if err := fn(monitors[start:end]); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+func TestDeleteAllResumesFromCheckpoint(t *testing.T) {
+	srv := newTestServer(t)
+	var monitors []datadog.Monitor
+	for i := 0; i < 6; i++ {
+		monitors = append(monitors, srv.AddMonitor(datadog.Monitor{Name: "old " + strconv.Itoa(i), Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90", Tags: []string{"service:old"}}))
+	}
+	srv.AddMonitor(datadog.Monitor{Name: "kept", Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90", Tags: []string{"service:new"}})
+	file := filepath.Join(t.TempDir(), "checkpoint.json")
+	args := []string{"delete-all", "--yes", "--service", "old", "--checkpoint-file", file}
+
+	// The run stops after two deletes (two list requests and two deletes)
+	res := runCLI(t, nil, append(args, "--request-budget", "4")...)
+	if !datadog.IsLimitExceeded(res.Err) {
+		t.Fatalf("first run = %v, want the budget spent\n%s", res.Err, res.Stdout)
+	}
+	checkpoint, err := datadog.LoadCheckpoint(file)
+	if err != nil || checkpoint == nil {
+		t.Fatalf("checkpoint after the first run: %v, %v", checkpoint, err)
+	}
+	if len(checkpoint.Targets) != 6 || len(checkpoint.Done) != 2 {
+		t.Fatalf("checkpoint with %d targets and %v done, want 6 and 2", len(checkpoint.Targets), checkpoint.Done)
+	}
+
+	// A crash between a delete and its checkpoint save leaves the monitor
+	// pending although it is gone
+	checkpoint.Done = checkpoint.Done[:1]
+	if err := checkpoint.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.ResetRequests()
+	res = runCLI(t, nil, args...)
+	if res.Err != nil {
+		t.Fatalf("resumed run: %v\n%s", res.Err, res.Stderr)
+	}
+	if !strings.Contains(res.Stdout, "Resuming from") || !strings.Contains(res.Stdout, "1 of 6 monitor(s) done") {
+		t.Errorf("resumed run did not report the checkpoint:\n%s", res.Stdout)
+	}
+	// The resumed run deletes the pending monitors once and leaves the done one alone
+	srv.AssertRequestCount(t, 0, "DELETE", "/monitor/"+strconv.Itoa(checkpoint.Done[0]))
+	for _, monitor := range monitors {
+		if monitor.ID != checkpoint.Done[0] {
+			srv.AssertRequestCount(t, 1, "DELETE", "/monitor/"+strconv.Itoa(monitor.ID))
+		}
+	}
+	if !strings.Contains(res.Stdout, "Successfully deleted: 4") || !strings.Contains(res.Stdout, "Already deleted: 1") {
+		t.Errorf("unexpected summary:\n%s", res.Stdout)
+	}
+
+	if got := srv.Monitors(); len(got) != 1 || got[0].Name != "kept" {
+		t.Errorf("monitors left: %+v", got)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("checkpoint file left after the resumed run completed: %v", err)
+	}
+}
+
+func TestDeleteAllRefusesOtherCheckpoint(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddMonitor(datadog.Monitor{Name: "old", Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90", Tags: []string{"service:old"}})
+	file := filepath.Join(t.TempDir(), "checkpoint.json")
+	checkpoint := datadog.NewCheckpoint(file, "delete-all service=other services= env= namespace= tags=", nil, srv.Now())
+	if err := checkpoint.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	res := runCLI(t, nil, "delete-all", "--yes", "--service", "old", "--checkpoint-file", file)
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "was written by") {
+		t.Errorf("delete-all with another command's checkpoint = %v", res.Err)
+	}
+	srv.AssertNoMutations(t)
+}
@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var cleanupCmd = &cobra.Command{
+	Use:   "cleanup",
+	Short: "Remove monitors for resources that no longer exist",
+	Long:  `Remove monitors left behind by resources that no longer exist`,
+}
+
+var cleanupNamespacesCmd = &cobra.Command{
+	Use:   "namespaces",
+	Short: "Delete or mute monitors of namespaces that no longer exist",
+	Long: `Find monitors whose namespace tag is not one of the live namespaces of the
+cluster and delete them (or mute them with --mute) after confirmation.
+
+The live namespaces come from a file (one per line, "-" for stdin) or from
+"kubectl get ns -o name" with --from-kubectl. Monitors without a namespace tag
+are never touched, and monitors modified within --grace-period are skipped.
+
+--checkpoint-file and --chunk-size work as for delete-all: the stale monitors
+are recorded before the first change and each one once handled, so rerunning
+the same command resumes with the monitors left, without reading the live
+namespaces again.
+
+Examples:
+  cleanup namespaces --from-kubectl --env prd
+  kubectl get ns -o name | cleanup namespaces --file - --yes --mute
+  cleanup namespaces --file live-namespaces.txt --grace-period 30d
+  cleanup namespaces --from-kubectl --checkpoint-file prune.json --chunk-size 100`,
+	RunE: runCleanupNamespaces,
+}
+
+var (
+	cleanupNamespacesFile        string
+	cleanupNamespacesFromKubectl bool
+	cleanupNamespacesEnv         string
+	cleanupNamespacesGracePeriod string
+	cleanupNamespacesMute        bool
+
+	cleanupNamespacesCheckpointFile string
+	cleanupNamespacesChunkSize      int
+)
+
+func init() {
+	rootCmd.AddCommand(cleanupCmd)
+	cleanupCmd.AddCommand(cleanupNamespacesCmd)
+	cleanupNamespacesCmd.Flags().StringVarP(&cleanupNamespacesFile, "file", "f", "", "File with the live namespaces, one per line (- for stdin)")
+	cleanupNamespacesCmd.Flags().BoolVar(&cleanupNamespacesFromKubectl, "from-kubectl", false, "Read the live namespaces from 'kubectl get ns -o name' (current context)")
+	cleanupNamespacesCmd.Flags().StringVar(&cleanupNamespacesEnv, "env", "", "Only consider monitors of this environment")
+	cleanupNamespacesCmd.Flags().StringVar(&cleanupNamespacesGracePeriod, "grace-period", "7d", "Skip monitors modified within this period (e.g., 12h, 7d; 0 disables)")
+	cleanupNamespacesCmd.Flags().BoolVar(&cleanupNamespacesMute, "mute", false, "Mute stale monitors instead of deleting them")
+	addCheckpointFlags(cleanupNamespacesCmd, &cleanupNamespacesCheckpointFile, &cleanupNamespacesChunkSize)
+	cleanupNamespacesCmd.MarkFlagsMutuallyExclusive("file", "from-kubectl")
+}
+
+// liveNamespaces reads the live namespaces from --file or kubectl
+func liveNamespaces() ([]string, error) {
+	var input io.Reader
+	switch {
+	case cleanupNamespacesFromKubectl:
+		var stdout, stderr bytes.Buffer
+		kubectl := exec.CommandContext(commandContext(), "kubectl", "get", "ns", "-o", "name")
+		kubectl.Stdout = &stdout
+		kubectl.Stderr = &stderr
+		if err := kubectl.Run(); err != nil {
+			return nil, fmt.Errorf("kubectl get ns failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		input = &stdout
+	case cleanupNamespacesFile == "-":
+		input = os.Stdin
+	case cleanupNamespacesFile != "":
+		file, err := os.Open(cleanupNamespacesFile)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		input = file
+	default:
+		return nil, fmt.Errorf("either --file or --from-kubectl must be provided")
+	}
+
+	namespaces, err := datadog.ParseNamespaceList(input)
+	if err != nil {
+		return nil, err
+	}
+	// An empty list would make every namespaced monitor look stale
+	if len(namespaces) == 0 {
+		return nil, fmt.Errorf("no live namespaces found; refusing to treat every namespace as gone")
+	}
+	return namespaces, nil
+}
+
+func runCleanupNamespaces(cmd *cobra.Command, args []string) error {
+	gracePeriod, err := parseDuration(cleanupNamespacesGracePeriod)
+	if err != nil {
+		return err
+	}
+
+	if cleanupNamespacesChunkSize < 0 {
+		return fmt.Errorf("--chunk-size must be positive")
+	}
+	operation := fmt.Sprintf("cleanup namespaces env=%s grace-period=%s mute=%t", cleanupNamespacesEnv, cleanupNamespacesGracePeriod, cleanupNamespacesMute)
+	checkpoint, err := resumeCheckpoint(cleanupNamespacesCheckpointFile, operation)
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	var client *datadog.Client
+	var stale []datadog.Monitor
+	if checkpoint != nil {
+		stale = checkpoint.Remaining()
+		if len(stale) == 0 {
+			out.Println("ℹ️  Every monitor of the checkpoint is already handled")
+			finishCheckpoint(checkpoint, cleanupNamespacesCheckpointFile)
+			return nil
+		}
+		out.Printf("\n📋 %d monitor(s) left:\n", len(stale))
+		for _, monitor := range stale {
+			out.Printf("   ID %d: %s\n", monitor.ID, monitor.Name)
+		}
+		if client, err = newClient(); err != nil {
+			errOut.Printf("❌ Error: %v\n", err)
+			return err
+		}
+	} else {
+		if client, stale, err = findStaleNamespaceMonitors(gracePeriod); err != nil || len(stale) == 0 {
+			return err
+		}
+	}
+
+	verb, action := "delete", "deleted"
+	if cleanupNamespacesMute {
+		verb, action = "mute", "muted"
+	}
+
+	prompt := verb
+	if !cleanupNamespacesMute {
+		prompt = "permanently delete"
+	}
+	confirmed, err := confirm(len(stale), prompt, nil)
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+	if !confirmed {
+		out.Println("❌ Cleanup cancelled")
+		return nil
+	}
+
+	if checkpoint == nil {
+		checkpoint, err = startCheckpoint(cleanupNamespacesCheckpointFile, operation, stale)
+		if err != nil {
+			errOut.Printf("❌ Error: %v\n", err)
+			return err
+		}
+	}
+
+	cleaned, handled := 0, 0
+	var failures bulkFailures
+	completed, err := forEachChunk(stale, cleanupNamespacesChunkSize, prompt, func(chunk []datadog.Monitor) error {
+		for _, monitor := range chunk {
+			i := handled
+			handled++
+			if failures.stop(i, nil) {
+				return failures.interrupted
+			}
+			var err error
+			if cleanupNamespacesMute {
+				_, err = client.MuteMonitor(monitor.ID, "", time.Time{})
+			} else {
+				err = client.DeleteMonitor(monitor.ID)
+			}
+			if err != nil {
+				if failures.stop(i, err) {
+					return failures.interrupted
+				}
+				failures.add(monitor, err)
+				// A monitor gone meanwhile needs nothing more; other failures
+				// stay pending so a resumed run retries them
+				if !datadog.IsMonitorNotFound(err) {
+					continue
+				}
+			} else {
+				cleaned++
+			}
+			if err := checkpoint.MarkDone(monitor.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil && failures.interrupted == nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		finishCheckpoint(checkpoint, cleanupNamespacesCheckpointFile)
+		return err
+	}
+
+	failures.printInterrupted(len(stale))
+	if !completed {
+		out.Printf("\n⏹️  Stopped after %d of %d monitor(s)\n", handled, len(stale))
+	}
+	out.Printf("\n📊 Results:\n")
+	out.Printf("✅ Successfully %s: %d\n", action, cleaned)
+	failures.printCounts()
+
+	failures.printDetails(verb)
+
+	finishCheckpoint(checkpoint, cleanupNamespacesCheckpointFile)
+	return failures.interrupted
+}
+
+// findStaleNamespaceMonitors reads the live namespaces and finds the monitors
+// of the namespaces gone, previewed by namespace; none when there are none
+func findStaleNamespaceMonitors(gracePeriod time.Duration) (*datadog.Client, []datadog.Monitor, error) {
+	live, err := liveNamespaces()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return nil, nil, err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return nil, nil, err
+	}
+
+	out.Println("\n🔍 Finding monitors of namespaces that no longer exist:")
+	out.Printf("🏷️  Live namespaces: %d\n", len(live))
+	if cleanupNamespacesEnv != "" {
+		out.Printf("🌍 Environment: %s\n", cleanupNamespacesEnv)
+	}
+	if gracePeriod > 0 {
+		out.Printf("⏳ Grace period: %s\n", formatDuration(gracePeriod))
+	}
+	out.Println(strings.Repeat("=", 80))
+
+	monitors, err := fetchMonitors(client, monitorSelector{Env: cleanupNamespacesEnv, Mutating: true})
+	if err != nil {
+		errOut.Printf("❌ Error listing monitors: %v\n", err)
+		return nil, nil, err
+	}
+
+	stale := datadog.StaleNamespaceMonitors(monitors, live, gracePeriod, time.Now())
+	if len(stale) == 0 {
+		out.Printf("✅ No monitors of removed namespaces found (%d monitor(s) checked)\n", len(monitors))
+		return nil, nil, nil
+	}
+
+	// Preview grouped by namespace
+	byNamespace := make(map[string][]datadog.Monitor)
+	var namespaces []string
+	for _, monitor := range stale {
+		namespace, _ := datadog.MonitorNamespace(monitor)
+		if _, ok := byNamespace[namespace]; !ok {
+			namespaces = append(namespaces, namespace)
+		}
+		byNamespace[namespace] = append(byNamespace[namespace], monitor)
+	}
+	sort.Strings(namespaces)
+	out.Printf("\n📋 Found %d monitor(s) in %d namespace(s) that no longer exist:\n", len(stale), len(namespaces))
+	for _, namespace := range namespaces {
+		out.Printf("\n   🏷️  namespace:%s\n", namespace)
+		for _, monitor := range byNamespace[namespace] {
+			out.Printf("      ID %d: %s [%s] (modified: %s)\n", monitor.ID, monitor.Name, monitor.OverallState, formatModified(monitor))
+		}
+	}
+
+	return client, stale, nil
+}
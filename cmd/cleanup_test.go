@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadogtest"
+)
+
+// fakeKubectl puts a kubectl on PATH that prints stdout, or fails with stderr
+// when it is set
+func fakeKubectl(t *testing.T, stdout, stderr string) {
+	t.Helper()
+	dir := t.TempDir()
+	script := "#!/bin/sh\n"
+	if stderr != "" {
+		script += "cat >&2 <<'EOF'\n" + stderr + "\nEOF\nexit 1\n"
+	} else {
+		script += "cat <<'EOF'\n" + stdout + "EOF\n"
+	}
+	if err := os.WriteFile(filepath.Join(dir, "kubectl"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// addNamespaceMonitors adds monitors of the shop, old-shop and gone
+// namespaces, and one without a namespace
+func addNamespaceMonitors(srv *datadogtest.Server) {
+	for _, monitor := range []datadog.Monitor{
+		{Name: "Shop CPU", Tags: []string{"namespace:shop", "env:prd"}},
+		{Name: "Old shop CPU", Tags: []string{"namespace:old-shop", "env:prd"}},
+		{Name: "Gone CPU", Tags: []string{"namespace:gone", "env:stg"}},
+		{Name: "Host CPU", Tags: []string{"env:prd"}},
+	} {
+		monitor.Type = "metric alert"
+		monitor.Query = "avg(last_5m):avg:cpu{*} > 90"
+		srv.AddMonitor(monitor)
+	}
+}
+
+func TestCleanupNamespacesFromKubectl(t *testing.T) {
+	srv := newTestServer(t)
+	addNamespaceMonitors(srv)
+	fakeKubectl(t, "namespace/default\nnamespace/kube-system\nnamespace/shop\n", "")
+
+	res := runCLI(t, nil, "cleanup", "namespaces", "--from-kubectl", "--grace-period", "0", "--yes")
+	if res.Err != nil {
+		t.Fatalf("cleanup namespaces: %v\n%s", res.Err, res.Stderr)
+	}
+	var left []string
+	for _, monitor := range srv.Monitors() {
+		left = append(left, monitor.Name)
+	}
+	if strings.Join(left, ", ") != "Shop CPU, Host CPU" {
+		t.Errorf("monitors left %q, want the live namespace's and the one without a namespace", left)
+	}
+	for _, want := range []string{"Live namespaces: 3", "Found 2 monitor(s) in 2 namespace(s) that no longer exist", "namespace:gone", "namespace:old-shop"} {
+		if !strings.Contains(res.Stdout, want) {
+			t.Errorf("stdout has no %q:\n%s", want, res.Stdout)
+		}
+	}
+}
+
+func TestCleanupNamespacesEnv(t *testing.T) {
+	srv := newTestServer(t)
+	addNamespaceMonitors(srv)
+	fakeKubectl(t, "namespace/shop\n", "")
+
+	res := runCLI(t, nil, "cleanup", "namespaces", "--from-kubectl", "--env", "prd", "--grace-period", "0", "--yes", "--mute")
+	if res.Err != nil {
+		t.Fatalf("cleanup namespaces: %v\n%s", res.Err, res.Stderr)
+	}
+	// Only the prd monitor of the gone namespace is muted, none deleted
+	srv.AssertRequestCount(t, 0, "DELETE", "/monitor/*")
+	srv.AssertRequestCount(t, 1, "POST", "/monitor/1001/mute")
+}
+
+func TestCleanupNamespacesNoneStale(t *testing.T) {
+	srv := newTestServer(t)
+	addNamespaceMonitors(srv)
+	// Every namespace is live, and some have no monitors
+	fakeKubectl(t, "namespace/shop\nnamespace/old-shop\nnamespace/gone\nnamespace/payments\n", "")
+
+	res := runCLI(t, nil, "cleanup", "namespaces", "--from-kubectl", "--grace-period", "0", "--yes")
+	if res.Err != nil {
+		t.Fatalf("cleanup namespaces: %v\n%s", res.Err, res.Stderr)
+	}
+	srv.AssertNoMutations(t)
+	if !strings.Contains(res.Stdout, "No monitors of removed namespaces found (4 monitor(s) checked)") {
+		t.Errorf("stdout:\n%s", res.Stdout)
+	}
+}
+
+func TestCleanupNamespacesUnusableList(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		stdout, stderr string
+		err            string
+	}{
+		{"table output", "NAME      STATUS   AGE\nshop      Active   3d\n", "", `line 1: "NAME      STATUS   AGE" is not a namespace name`},
+		{"no namespaces", "", "", "no live namespaces found"},
+		{"kubectl fails", "", "error: You must be logged in to the server (Unauthorized)", "kubectl get ns failed: exit status 1: error: You must be logged in to the server (Unauthorized)"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := newTestServer(t)
+			addNamespaceMonitors(srv)
+			fakeKubectl(t, tc.stdout, tc.stderr)
+
+			res := runCLI(t, nil, "cleanup", "namespaces", "--from-kubectl", "--grace-period", "0", "--yes")
+			if res.Err == nil || !strings.Contains(res.Err.Error(), tc.err) {
+				t.Errorf("cleanup namespaces = %v, want %q", res.Err, tc.err)
+			}
+			// Nothing is even listed when the live namespaces are unknown
+			if requests := srv.Requests(); len(requests) != 0 {
+				t.Errorf("requests sent: %v", requests)
+			}
+		})
+	}
+}
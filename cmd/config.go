@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/audit"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/config"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/paths"
+)
+
+var (
+	configFile string
+	loadedCfg  *config.Config
+)
+
+// defaultEnvironments are the environments the tool was originally built for;
+// other values are accepted with a warning when no environments are configured
+var defaultEnvironments = []string{"dev", "hml", "prd", "corp"}
+
+// loadConfig loads the config file once per invocation
+func loadConfig() (*config.Config, error) {
+	if loadedCfg != nil {
+		return loadedCfg, nil
+	}
+
+	path := configFile
+	if path == "" {
+		path = config.DefaultPath()
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	loadedCfg = cfg
+	return loadedCfg, nil
+}
+
+// resolveEnv maps env through the configured aliases and validates it against
+// the configured environments. Without configured environments any non-empty
+// env is accepted, with a warning when it isn't one of the defaults.
+func resolveEnv(env string, allowAny bool) (string, error) {
+	if env == "" {
+		return "", fmt.Errorf("environment is required")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return "", err
+	}
+
+	resolved := datadog.ResolveEnvAlias(env, cfg.EnvAliases)
+	if resolved != env {
+		out.Printf("🔁 Environment alias: %s → %s\n", env, resolved)
+	}
+
+	if allowAny {
+		return resolved, nil
+	}
+
+	if len(cfg.Environments) > 0 {
+		for _, valid := range cfg.Environments {
+			if resolved == valid {
+				return resolved, nil
+			}
+		}
+		return "", fmt.Errorf("invalid environment: %s (must be one of: %s)", resolved, strings.Join(cfg.Environments, ", "))
+	}
+
+	for _, known := range defaultEnvironments {
+		if resolved == known {
+			return resolved, nil
+		}
+	}
+	errOut.Printf("⚠️  Warning: environment %q is not one of %s (configure 'environments' in the config file to validate it)\n", resolved, strings.Join(defaultEnvironments, ", "))
+	return resolved, nil
+}
+
+// requireAPIV2 returns an error unless the API v2 feature flag is enabled with
+// DDMM_API_V2 or api_v2 in the config file
+func requireAPIV2(feature string) error {
+	if value := os.Getenv("DDMM_API_V2"); value != "" && value != "0" && value != "false" {
+		return nil
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.APIV2 {
+		return nil
+	}
+	return fmt.Errorf("%s uses the Datadog API v2, which is behind a feature flag: set DDMM_API_V2=1 or api_v2: true in the config file", feature)
+}
+
+// auditLogPath returns the audit log file from DDMM_AUDIT_LOG or audit_log in
+// the config file, with a leading ~ expanded; empty when auditing is off
+func auditLogPath() (string, error) {
+	path := os.Getenv(audit.PathEnv)
+	if path == "" {
+		cfg, err := loadConfig()
+		if err != nil {
+			return "", err
+		}
+		path = cfg.AuditLog
+	}
+	return paths.Expand(path)
+}
+
+// k8sDefaults returns the Kubernetes defaults --k8s-defaults injects: the
+// delays given on the command line, else k8s_defaults in the config file, else
+// datadog.DefaultK8sDelay. nil when --k8s-defaults is not set.
+func k8sDefaults(enabled bool, evaluationDelay, newGroupDelay int) (*datadog.K8sDefaults, error) {
+	if !enabled {
+		if evaluationDelay != 0 || newGroupDelay != 0 {
+			return nil, fmt.Errorf("--k8s-evaluation-delay and --k8s-new-group-delay can only be used together with --k8s-defaults")
+		}
+		return nil, nil
+	}
+	if evaluationDelay < 0 || newGroupDelay < 0 {
+		return nil, fmt.Errorf("--k8s-evaluation-delay and --k8s-new-group-delay must be positive")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	defaults := &datadog.K8sDefaults{EvaluationDelay: datadog.DefaultK8sDelay, NewGroupDelay: datadog.DefaultK8sDelay}
+	for _, delay := range []struct {
+		value   *int
+		flag    int
+		fromCfg int
+	}{
+		{&defaults.EvaluationDelay, evaluationDelay, cfg.K8sDefaults.EvaluationDelay},
+		{&defaults.NewGroupDelay, newGroupDelay, cfg.K8sDefaults.NewGroupDelay},
+	} {
+		switch {
+		case delay.flag > 0:
+			*delay.value = delay.flag
+		case delay.fromCfg > 0:
+			*delay.value = delay.fromCfg
+		}
+	}
+	return defaults, nil
+}
+
+// thresholdScale returns the factor the thresholds of monitors rendered for
+// env are multiplied by: the --threshold-scale value for env, else specScale
+// (a service spec's threshold_scale), else threshold_scale in the config file,
+// else 1 (unscaled). Environment keys go through the configured aliases.
+func thresholdScale(flag, env string, specScale float64) (float64, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return 0, err
+	}
+	if flag != "" {
+		scale, err := datadog.ParseThresholdScale(flag)
+		if err != nil {
+			return 0, fmt.Errorf("--threshold-scale: %w", err)
+		}
+		for key, factor := range scale {
+			if datadog.ResolveEnvAlias(key, cfg.EnvAliases) == env {
+				return factor, nil
+			}
+		}
+	}
+	if specScale > 0 {
+		return specScale, nil
+	}
+	for key, factor := range cfg.ThresholdScale {
+		if datadog.ResolveEnvAlias(key, cfg.EnvAliases) != env {
+			continue
+		}
+		if factor <= 0 {
+			return 0, fmt.Errorf("threshold_scale in the config file: the factor for %s must be positive", key)
+		}
+		return factor, nil
+	}
+	return 1, nil
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+// confirmSampleSize is how many affected items are previewed before prompting
+const confirmSampleSize = 10
+
+var (
+	assumeYes bool
+	verbose   bool
+)
+
+// assumeYesEnabled reports whether confirmations are skipped via --yes or DDMM_ASSUME_YES
+func assumeYesEnabled() bool {
+	if assumeYes {
+		return true
+	}
+	value := strings.ToLower(strings.TrimSpace(os.Getenv("DDMM_ASSUME_YES")))
+	if value == "yes" || value == "y" {
+		return true
+	}
+	enabled, _ := strconv.ParseBool(value)
+	return enabled
+}
+
+// logVerbose prints a message to stderr when --verbose is set
+func logVerbose(format string, args ...interface{}) {
+	if verbose {
+		errOut.Printf("🔧 "+format+"\n", args...)
+	}
+}
+
+// stdinIsTerminal reports whether stdin is an interactive terminal. /dev/null
+// is a character device too, but CI jobs often run with it as stdin.
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	if info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	if devNull, err := os.Stat(os.DevNull); err == nil && os.SameFile(info, devNull) {
+		return false
+	}
+	return true
+}
+
+// confirm asks for confirmation before a mutating operation on count monitors.
+// action describes the operation (e.g., "permanently delete"). A sample of the
+// affected items is printed first unless sample is empty (for commands that
+// already printed their own preview). The prompt is skipped with --yes or
+// DDMM_ASSUME_YES; without them, a non-interactive stdin is refused with an error.
+func confirm(count int, action string, sample []string) (bool, error) {
+	out.Printf("\n⚠️  This will %s %d monitor(s)", action, count)
+	if len(sample) == 0 {
+		out.Println(".")
+	} else {
+		out.Println(":")
+		for i, item := range sample {
+			if i == confirmSampleSize {
+				out.Printf("   ... and %d more\n", len(sample)-confirmSampleSize)
+				break
+			}
+			out.Printf("   %s\n", item)
+		}
+	}
+
+	if assumeYesEnabled() {
+		logVerbose("confirmation: %s %d monitor(s) auto-confirmed (--yes/DDMM_ASSUME_YES)", action, count)
+		return true, nil
+	}
+
+	if !stdinIsTerminal() {
+		logVerbose("confirmation: %s %d monitor(s) refused (stdin is not a terminal)", action, count)
+		return false, fmt.Errorf("confirmation required but stdin is not a terminal (use --yes or DDMM_ASSUME_YES=1)")
+	}
+
+	out.Print("Type 'yes' to confirm: ")
+	answer, err := readAnswer()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	confirmed := strings.TrimSpace(strings.ToLower(answer)) == "yes"
+
+	logVerbose("confirmation: %s %d monitor(s) answered %q (confirmed: %v)", action, count, strings.TrimSpace(answer), confirmed)
+	return confirmed, nil
+}
+
+// stdinReader is shared by the prompts so that input buffered by one prompt
+// isn't lost to the next
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// readAnswer reads a line from stdin. Ctrl-C is handled by the command context,
+// so it stops waiting when the context is cancelled.
+func readAnswer() (string, error) {
+	type line struct {
+		text string
+		err  error
+	}
+	lines := make(chan line, 1)
+	go func() {
+		text, err := stdinReader.ReadString('\n')
+		lines <- line{text, err}
+	}()
+
+	select {
+	case l := <-lines:
+		if l.err != nil && l.text == "" {
+			return "", l.err
+		}
+		return l.text, nil
+	case <-commandContext().Done():
+		out.Println()
+		return "", commandContext().Err()
+	}
+}
+
+// promptValue asks for a value on the terminal until validate accepts it; an
+// empty answer takes def. The caller checks that stdin is a terminal.
+func promptValue(label, def string, validate func(string) error) (string, error) {
+	for {
+		if def != "" {
+			out.Printf("%s [%s]: ", label, def)
+		} else {
+			out.Printf("%s: ", label)
+		}
+		answer, err := readAnswer()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				out.Println()
+				return "", fmt.Errorf("no value entered for %s", label)
+			}
+			return "", err
+		}
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			answer = def
+		}
+		if err := validate(answer); err != nil {
+			out.Printf("   ⚠️  %v\n", err)
+			continue
+		}
+		return answer, nil
+	}
+}
+
+// monitorSample formats monitors as confirmation preview lines
+func monitorSample(monitors []datadog.Monitor) []string {
+	sample := make([]string, len(monitors))
+	for i, monitor := range monitors {
+		sample[i] = fmt.Sprintf("ID %d: %s", monitor.ID, monitor.Name)
+	}
+	return sample
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var createCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create monitors without a template file",
+	Long:  `Create monitors directly from the command line, without a template file`,
+}
+
+var createQuickCmd = &cobra.Command{
+	Use:   "quick",
+	Short: "Create a metric monitor from a metric query",
+	Long: `Create a metric alert from a metric explorer query. The full monitor query,
+a default message and tags (from the query scope) are filled in for you.
+
+Examples:
+  create quick --metric 'avg:kubernetes.cpu.usage.total{service:foo} by {pod}' --above 80 --window last_5m
+  create quick --metric 'sum:trace.http.request.hits{service:foo}.as_count()' --below 10 --warning 20 --no-data-timeframe 10
+  create quick --metric 'avg:kubernetes.cpu.usage.total{service:foo}' --above 80 --create-muted=24h`,
+	RunE: runCreateQuick,
+}
+
+var (
+	createQuickMetric          string
+	createQuickAbove           float64
+	createQuickBelow           float64
+	createQuickWarning         float64
+	createQuickWindow          string
+	createQuickAggregation     string
+	createQuickNoDataTimeframe int
+	createQuickName            string
+	createQuickMessage         string
+	createQuickTags            []string
+	createQuickDryRun          bool
+
+	createQuickCreateMuted string
+)
+
+func init() {
+	rootCmd.AddCommand(createCmd)
+	createCmd.AddCommand(createQuickCmd)
+	createQuickCmd.Flags().StringVar(&createQuickMetric, "metric", "", "Metric query, e.g. 'avg:kubernetes.cpu.usage.total{service:foo} by {pod}' (required)")
+	createQuickCmd.MarkFlagRequired("metric")
+	createQuickCmd.Flags().Float64Var(&createQuickAbove, "above", 0, "Alert when the value is above this threshold")
+	createQuickCmd.Flags().Float64Var(&createQuickBelow, "below", 0, "Alert when the value is below this threshold")
+	createQuickCmd.MarkFlagsMutuallyExclusive("above", "below")
+	createQuickCmd.Flags().Float64Var(&createQuickWarning, "warning", 0, "Warning threshold")
+	createQuickCmd.Flags().StringVar(&createQuickWindow, "window", "last_5m", "Evaluation window (e.g., last_5m, last_1h)")
+	createQuickCmd.Flags().StringVar(&createQuickAggregation, "aggregation", "avg", "Time aggregation (avg, sum, min, max)")
+	createQuickCmd.Flags().IntVar(&createQuickNoDataTimeframe, "no-data-timeframe", 0, "Notify when there is no data for this many minutes")
+	createQuickCmd.Flags().StringVar(&createQuickName, "name", "", "Monitor name (default: '<metric> is above/below <threshold>')")
+	createQuickCmd.Flags().StringVar(&createQuickMessage, "message", "", "Monitor message (default: generated from the metric and threshold)")
+	createQuickCmd.Flags().StringArrayVar(&createQuickTags, "tag", []string{}, "Additional tags (can be used multiple times)")
+	createQuickCmd.Flags().BoolVar(&createQuickDryRun, "dry-run", false, "Print the monitor as JSON instead of creating it")
+	addCreateMutedFlag(createQuickCmd, &createQuickCreateMuted)
+}
+
+func runCreateQuick(cmd *cobra.Command, args []string) error {
+	opts := datadog.QuickMonitorOptions{
+		Name:            createQuickName,
+		Metric:          createQuickMetric,
+		Aggregation:     createQuickAggregation,
+		Window:          createQuickWindow,
+		NoDataTimeframe: createQuickNoDataTimeframe,
+		Message:         createQuickMessage,
+		Tags:            createQuickTags,
+	}
+	if cmd.Flags().Changed("above") {
+		opts.Above = &createQuickAbove
+	}
+	if cmd.Flags().Changed("below") {
+		opts.Below = &createQuickBelow
+	}
+	if cmd.Flags().Changed("warning") {
+		opts.Warning = &createQuickWarning
+	}
+	if opts.Above == nil && opts.Below == nil {
+		return fmt.Errorf("either --above or --below is required")
+	}
+
+	monitor, err := datadog.BuildQuickMonitor(opts)
+	if err != nil {
+		return err
+	}
+	var mutedUntil time.Time
+	if createQuickCreateMuted != "" {
+		if mutedUntil, err = parseCreateMuted(createQuickCreateMuted, time.Now()); err != nil {
+			return err
+		}
+		datadog.MuteOnCreate(monitor, mutedUntil)
+	}
+
+	if createQuickDryRun {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(monitor)
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	created, err := client.CreateMonitor(monitor)
+	if err != nil {
+		errOut.Printf("❌ Error creating monitor: %v\n", err)
+		return err
+	}
+
+	out.Printf("✅ Monitor %d created successfully!\n", created.ID)
+	out.Printf("Name: %s\n", created.Name)
+	out.Printf("Query: %s\n", created.Query)
+	if len(created.Tags) > 0 {
+		out.Printf("Tags: %s\n", strings.Join(created.Tags, ", "))
+	}
+	if createQuickCreateMuted != "" {
+		out.Printf("🔇 Muted %s (lift it early with: datadog-monitor-manager unmute --monitor-id %d --all-scopes)\n", muteEndLabel(mutedUntil), created.ID)
+	}
+	return nil
+}
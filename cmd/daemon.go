@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/metrics"
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Apply a service spec in a loop and expose Prometheus metrics",
+	Long: `Run as a long-lived reconciler instead of a cron job: apply the service spec
+of --file (see: apply) right away and then every --interval, so monitors
+changed by hand are brought back to the spec. The spec and its templates are
+read again on every sync, so a mounted ConfigMap can be updated in place.
+
+An HTTP server on --listen serves:
+  /metrics  Prometheus metrics: syncs run and failed, last sync time and
+            duration, monitors applied by result (created, updated,
+            unchanged, failed, ...), the drift of the last sync (monitors it
+            had to create or update) and the Datadog API calls (requests by
+            endpoint and status, retries, errors, bytes)
+  /healthz  Liveness probe, answers 200 while the daemon runs
+
+--request-budget and --fail-fast apply to each sync: a sync that reaches a
+limit fails, and the next one starts with a full budget. The API call metrics
+keep counting across syncs.
+
+SIGTERM or Ctrl-C stops the daemon: a sync in flight is finished first, so no
+template is left half-applied. A second signal exits right away. --timeout
+stops the daemon the same way.
+
+Examples:
+  daemon -f service.yaml
+  daemon -f service.yaml --interval 10m --listen :9090
+  daemon -f service.yaml --protect-unmanaged --atomic`,
+	RunE: runDaemon,
+}
+
+var (
+	daemonInterval time.Duration
+	daemonListen   string
+)
+
+// minDaemonInterval keeps the daemon from spending the API rate limits
+const minDaemonInterval = 30 * time.Second
+
+// daemonShutdownTimeout is how long the HTTP server gets to finish the
+// requests in flight on shutdown
+const daemonShutdownTimeout = 5 * time.Second
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+	// The apply options are shared with the apply command, which syncs run
+	daemonCmd.Flags().StringVarP(&applyFile, "file", "f", "", "Path to the service spec file (required)")
+	daemonCmd.MarkFlagRequired("file")
+	daemonCmd.Flags().DurationVar(&daemonInterval, "interval", 5*time.Minute, "Time between syncs (minimum 30s)")
+	daemonCmd.Flags().StringVar(&daemonListen, "listen", ":9090", "Address the /metrics and /healthz endpoints are served on")
+	daemonCmd.Flags().BoolVar(&applyProtect, "protect-unmanaged", false, "Don't update existing monitors without the managed-by:ddmm tag; report them as conflicts")
+	daemonCmd.Flags().BoolVar(&applyAtomic, "atomic", false, "Validate every monitor with the API before writing any, and roll back a sync's changes if a step fails")
+	daemonCmd.Flags().BoolVar(&applyStrictScope, "strict-scope", false, "Fail a sync when a template query is scoped to another env or service than the spec's")
+	daemonCmd.Flags().BoolVar(&applyAllowAnyEnv, "allow-any-env", false, "Accept any environment name without validation or warnings")
+	daemonCmd.Flags().BoolVar(&applyRefresh, "refresh-templates", false, "Fetch remote template sources again on every sync instead of using the cached copy")
+	daemonCmd.Flags().StringVar(&applyMatchBy, "match-by", "name", "How upserts find a monitor without the template's identity: name or query (see: apply --help)")
+	daemonCmd.Flags().BoolVar(&applyUppercaseEnv, "uppercase-env-in-name", false, "Render {env} in names uppercased, as before {env:upper}")
+	daemonCmd.Flags().MarkDeprecated("uppercase-env-in-name", "use {env:upper} in template names instead; the flag will be removed in the next release")
+}
+
+func runDaemon(cmd *cobra.Command, args []string) error {
+	if daemonInterval < minDaemonInterval {
+		return fmt.Errorf("--interval must be at least %s", minDaemonInterval)
+	}
+
+	// The API call metrics come from the stats layer, collected with or
+	// without --stats
+	if runStats == nil {
+		runStats = datadog.NewStats()
+	}
+	collector := metrics.NewCollector(runStats)
+
+	listener, err := net.Listen("tcp", daemonListen)
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+	server := &http.Server{Handler: collector.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errOut.Printf("❌ Metrics server stopped: %v\n", err)
+		}
+	}()
+	out.Printf("📡 Serving metrics on http://%s/metrics (liveness: /healthz)\n", listener.Addr())
+	out.Printf("🔁 Applying %s every %s\n", applyFile, formatDuration(daemonInterval))
+
+	// A signal stops the loop, but not the sync in flight: the clients of a
+	// sync get a context the signal doesn't cancel
+	stop := commandContext()
+	runCtx = context.WithoutCancel(stop)
+	defer func() { runCtx = stop }()
+
+	ticker := time.NewTicker(daemonInterval)
+	defer ticker.Stop()
+	daemonLoop(stop, ticker.C, func() { daemonSync(collector) })
+
+	out.Println("\n🛑 Stopping the daemon")
+	ctx, cancel := context.WithTimeout(context.Background(), daemonShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		errOut.Printf("⚠️  Metrics server shutdown: %v\n", err)
+	}
+	return nil
+}
+
+// daemonLoop runs sync right away and then on every tick, until stop is done.
+// A sync in flight when stop is done is finished.
+func daemonLoop(stop context.Context, ticks <-chan time.Time, sync func()) {
+	for stop.Err() == nil {
+		sync()
+		select {
+		case <-stop.Done():
+		case <-ticks:
+		}
+	}
+}
+
+// daemonSync applies the service spec once and records the outcome in the
+// metrics. The --request-budget and --fail-fast limits start over with each
+// sync.
+func daemonSync(collector *metrics.Collector) {
+	started := time.Now()
+	out.Printf("\n🔁 Sync started at %s\n", formatTime(started))
+	if runStats != nil {
+		runStats.ResetLimits()
+	}
+
+	results, err := applyServiceSpec()
+	sync := metrics.Sync{
+		Started:  started,
+		Duration: time.Since(started),
+		Monitors: make(map[datadog.ResultStatus]int),
+		Err:      err,
+	}
+	for _, result := range results {
+		sync.Monitors[result.Status]++
+		switch result.Status {
+		case datadog.StatusCreated, datadog.StatusUpdated, datadog.StatusAdopted:
+			sync.Drift++
+		}
+	}
+	collector.RecordSync(sync)
+
+	if err != nil {
+		errOut.Printf("❌ Sync failed after %s: %v\n", formatMillis(float64(sync.Duration)/float64(time.Millisecond)), err)
+		return
+	}
+	out.Printf("✅ Sync finished in %s: %d monitor(s) created or updated, %d unchanged\n",
+		formatMillis(float64(sync.Duration)/float64(time.Millisecond)), sync.Drift, sync.Monitors[datadog.StatusUnchanged])
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/metrics"
+)
+
+func TestDaemonLoop(t *testing.T) {
+	stop, cancel := context.WithCancel(context.Background())
+	ticks := make(chan time.Time)
+	syncs := make(chan int)
+	n := 0
+	done := make(chan struct{})
+	go func() {
+		daemonLoop(stop, ticks, func() {
+			n++
+			if n == 3 {
+				// Stopped during the third sync
+				cancel()
+			}
+			syncs <- n
+		})
+		close(done)
+	}()
+
+	// The first sync runs right away, the next ones on each tick
+	if got := <-syncs; got != 1 {
+		t.Fatalf("sync %d, want 1", got)
+	}
+	ticks <- time.Now()
+	if got := <-syncs; got != 2 {
+		t.Fatalf("sync %d, want 2", got)
+	}
+
+	// Stopping during a sync lets it finish, and no sync starts after it
+	ticks <- time.Now()
+	if got := <-syncs; got != 3 {
+		t.Fatalf("sync %d, want 3", got)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the loop did not stop")
+	}
+}
+
+func TestDaemonSyncLimitsPerSync(t *testing.T) {
+	srv := newTestServer(t)
+	resetCommandState()
+	applyFile = writeServiceSpec(t)
+
+	// The first sync creates both monitors in 6 requests; the next ones only
+	// read them back, within the same budget
+	runStats = datadog.NewStats()
+	runStats.SetLimits(datadog.Limits{RequestBudget: 6, FailFast: 1})
+	collector := metrics.NewCollector(runStats)
+	stdout, stderr := captureOutput(t, func() {
+		for i := 0; i < 3; i++ {
+			daemonSync(collector)
+		}
+	})
+	if strings.Contains(stderr, "Sync failed") {
+		t.Fatalf("a sync failed:\n%s%s", stdout, stderr)
+	}
+
+	var b strings.Builder
+	collector.Write(&b)
+	exposition := b.String()
+	for _, want := range []string{
+		"ddmm_syncs_total 3\n",
+		"ddmm_sync_failures_total 0\n",
+		`ddmm_sync_monitors_total{result="created"} 2` + "\n",
+		`ddmm_sync_monitors_total{result="unchanged"} 4` + "\n",
+		"ddmm_drift_monitors 0\n",
+	} {
+		if !strings.Contains(exposition, want) {
+			t.Errorf("metrics lack %q:\n%s", strings.TrimSpace(want), exposition)
+		}
+	}
+	// The API call counters keep counting across syncs
+	if summary := runStats.Summary(); summary.Requests != len(srv.Requests()) || summary.Requests <= 6 {
+		t.Errorf("stats count %d request(s), the server got %d", summary.Requests, len(srv.Requests()))
+	}
+}
+
+func TestDaemonSyncOverBudgetFails(t *testing.T) {
+	newTestServer(t)
+	resetCommandState()
+	applyFile = writeServiceSpec(t)
+
+	runStats = datadog.NewStats()
+	runStats.SetLimits(datadog.Limits{RequestBudget: 2})
+	collector := metrics.NewCollector(runStats)
+	captureOutput(t, func() {
+		daemonSync(collector)
+		daemonSync(collector)
+	})
+
+	var b strings.Builder
+	collector.Write(&b)
+	// The first sync spends its budget before creating the monitors; the
+	// second gets a fresh budget, spent the same way
+	for _, want := range []string{"ddmm_syncs_total 2\n", "ddmm_sync_failures_total 2\n"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("metrics lack %q:\n%s", strings.TrimSpace(want), b.String())
+		}
+	}
+}
+
+func TestDaemonCommand(t *testing.T) {
+	srv := newTestServer(t)
+	spec := writeServiceSpec(t)
+
+	res := runCLI(t, nil, "daemon", "-f", spec, "--interval", "10s")
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "--interval must be at least 30s") {
+		t.Errorf("daemon --interval 10s = %v, want an interval error", res.Err)
+	}
+
+	// The daemon stops once the context is done, after the sync in flight
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		defer cancel()
+		deadline := time.Now().Add(5 * time.Second)
+		for len(srv.RequestsTo("POST", "/monitor")) < 2 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+	res = runCLI(t, ctx, "daemon", "-f", spec, "--listen", "127.0.0.1:0")
+	if res.Err != nil {
+		t.Fatalf("daemon: %v\n%s", res.Err, res.Stderr)
+	}
+	for _, want := range []string{"Serving metrics on http://127.0.0.1:", "Sync finished", "2 monitor(s) created or updated", "Stopping the daemon"} {
+		if !strings.Contains(res.Stdout, want) {
+			t.Errorf("output lacks %q:\n%s", want, res.Stdout)
+		}
+	}
+	if got := len(srv.Monitors()); got != 2 {
+		t.Errorf("%d monitor(s), want 2", got)
+	}
+}
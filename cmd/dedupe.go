@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var dedupeCmd = &cobra.Command{
+	Use:   "dedupe",
+	Short: "Detect and merge duplicate monitors",
+	Long: `Find duplicate monitors among the monitors matching filters.
+
+Monitors are grouped by type and query (ignoring whitespace) and, separately,
+by exact name. Each group with more than one monitor is reported as a cluster.
+
+With --fix, the most recently modified monitor of each cluster is kept and the
+others are deleted (or muted with --mute) after confirmation.
+
+Examples:
+  dedupe --service myapp
+  dedupe --env prd --fix
+  dedupe --query "team:payments" --fix --mute`,
+	RunE: runDedupe,
+}
+
+var (
+	dedupeService        string
+	dedupeEnv            string
+	dedupeNamespace      string
+	dedupeFilterTags     string
+	dedupeQuery          string
+	dedupeStatus         string
+	dedupeFilterServices string
+	dedupeFix            bool
+	dedupeMute           bool
+)
+
+func init() {
+	rootCmd.AddCommand(dedupeCmd)
+	dedupeCmd.Flags().StringVar(&dedupeService, "service", "", "Filter by service")
+	dedupeCmd.Flags().StringVar(&dedupeEnv, "env", "", "Filter by environment")
+	dedupeCmd.Flags().StringVar(&dedupeNamespace, "namespace", "", "Filter by namespace")
+	dedupeCmd.Flags().StringVar(&dedupeFilterTags, "filter-tags", "", "Filter by tags (comma-separated)")
+	dedupeCmd.Flags().StringVar(&dedupeQuery, "query", "", "Complex search query (e.g., service:(service1 OR service2))")
+	dedupeCmd.Flags().StringVar(&dedupeStatus, "status", "", "Filter by monitor state, comma-separated (OK, Alert, Warn, No Data, Unknown, Skipped, Ignored)")
+	dedupeCmd.Flags().StringVar(&dedupeFilterServices, "filter-services", "", "Filter by multiple services (comma-separated, filters locally after query/tags)")
+	dedupeCmd.Flags().BoolVar(&dedupeFix, "fix", false, "Keep the most recently modified monitor per cluster and remove the rest")
+	dedupeCmd.Flags().BoolVar(&dedupeMute, "mute", false, "With --fix, mute duplicates instead of deleting them")
+}
+
+// formatModified formats a monitor's modified time for display
+func formatModified(monitor datadog.Monitor) string {
+	if monitor.Modified.IsZero() {
+		return "unknown"
+	}
+	return formatTime(monitor.Modified.Time())
+}
+
+func runDedupe(cmd *cobra.Command, args []string) error {
+	if dedupeMute && !dedupeFix {
+		return fmt.Errorf("--mute can only be used together with --fix")
+	}
+
+	selector := monitorSelector{
+		Query:          dedupeQuery,
+		Service:        dedupeService,
+		Env:            dedupeEnv,
+		Namespace:      dedupeNamespace,
+		Tags:           splitCommaList(dedupeFilterTags),
+		Status:         dedupeStatus,
+		FilterServices: dedupeFilterServices,
+		Mutating:       dedupeFix,
+	}
+	if err := selector.validate(); err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	monitors, err := fetchMonitors(client, selector)
+	if err != nil {
+		errOut.Printf("❌ Error listing monitors: %v\n", err)
+		return err
+	}
+
+	clusters := datadog.FindDuplicateClusters(monitors)
+	out.Printf("🔍 Checked %d monitor(s)\n", len(monitors))
+	if len(clusters) == 0 {
+		out.Println("✅ No duplicate monitors found")
+		return nil
+	}
+
+	out.Printf("\n📊 Found %d duplicate cluster(s):\n", len(clusters))
+	out.Println(strings.Repeat("=", 80))
+	for i, cluster := range clusters {
+		if cluster.Reason == datadog.DuplicateByQuery {
+			out.Printf("\n%d. Same query (%s): %s\n", i+1, cluster.Monitors[0].Type, cluster.Monitors[0].Query)
+		} else {
+			out.Printf("\n%d. Same name: %s\n", i+1, cluster.Key)
+		}
+		for j, monitor := range cluster.Monitors {
+			marker := "  "
+			if j == 0 {
+				marker = "⭐"
+			}
+			out.Printf("   %s ID %d: %s (modified: %s)\n", marker, monitor.ID, monitor.Name, formatModified(monitor))
+		}
+	}
+
+	remove := datadog.DuplicatesToRemove(clusters)
+	verb, action := "delete", "deleted"
+	if dedupeMute {
+		verb, action = "mute", "muted"
+	}
+
+	if !dedupeFix {
+		out.Printf("\nℹ️  %d monitor(s) would be %s (⭐ = kept). Use --fix to apply.\n", len(remove), action)
+		return nil
+	}
+
+	if len(remove) == 0 {
+		out.Println("\nℹ️  Nothing to fix")
+		return nil
+	}
+
+	confirmed, err := confirm(len(remove), verb, nil)
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+	if !confirmed {
+		out.Println("❌ Dedupe cancelled")
+		return nil
+	}
+
+	fixed := 0
+	var failures bulkFailures
+	for i, monitor := range remove {
+		if failures.stop(i, nil) {
+			break
+		}
+		var err error
+		if dedupeMute {
+			_, err = client.MuteMonitor(monitor.ID, "", time.Time{})
+		} else {
+			err = client.DeleteMonitor(monitor.ID)
+		}
+		if err != nil {
+			if failures.stop(i, err) {
+				break
+			}
+			failures.add(monitor, err)
+			continue
+		}
+		fixed++
+	}
+
+	failures.printInterrupted(len(remove))
+	out.Printf("\n📊 Results:\n")
+	out.Printf("✅ Successfully %s: %d\n", action, fixed)
+	failures.printCounts()
+
+	failures.printDetails(verb)
+
+	return failures.interrupted
+}
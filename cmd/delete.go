@@ -1,9 +1,6 @@
 package cmd
 
 import (
-	"fmt"
-	"os"
-
 	"github.com/spf13/cobra"
 	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
 )
@@ -11,7 +8,7 @@ import (
 var deleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Delete a monitor",
-	Long:  `Delete a single monitor by ID`,
+	Long:  `Delete a single monitor by ID (asks for confirmation unless --yes is set)`,
 	RunE:  runDelete,
 }
 
@@ -25,26 +22,44 @@ func init() {
 	deleteCmd.Flags().IntVar(&deleteMonitorID, "monitor-id", 0, "Monitor ID (required)")
 	deleteCmd.MarkFlagRequired("monitor-id")
 	deleteCmd.Flags().BoolVar(&deleteConfirm, "confirm", false, "Confirm deletion")
+	deleteCmd.Flags().MarkDeprecated("confirm", "use --yes instead")
 }
 
 func runDelete(cmd *cobra.Command, args []string) error {
-	if !deleteConfirm {
-		fmt.Fprintf(os.Stderr, "❌ Please use --confirm to confirm deletion\n")
-		return fmt.Errorf("confirmation required")
+	if deleteConfirm {
+		assumeYes = true
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
 	}
 
-	client, err := datadog.NewClient()
+	monitor, err := client.GetMonitor(deleteMonitorID)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		reportMonitorError("getting monitor", err)
 		return err
 	}
 
+	warnCompositeReferences(client, []datadog.Monitor{*monitor})
+
+	confirmed, err := confirm(1, "permanently delete", monitorSample([]datadog.Monitor{*monitor}))
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+	if !confirmed {
+		out.Println("❌ Deletion cancelled")
+		return nil
+	}
+
 	err = client.DeleteMonitor(deleteMonitorID)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Error deleting monitor: %v\n", err)
+		reportMonitorError("deleting monitor", err)
 		return err
 	}
 
-	fmt.Printf("✅ Monitor %d deleted successfully!\n", deleteMonitorID)
+	out.Printf("✅ Monitor %d deleted successfully!\n", deleteMonitorID)
 	return nil
 }
@@ -1,9 +1,7 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -13,8 +11,27 @@ import (
 var deleteAllCmd = &cobra.Command{
 	Use:   "delete-all",
 	Short: "Delete all monitors matching filters",
-	Long:  `Delete all monitors matching the specified filters (interactive confirmation)`,
-	RunE:  runDeleteAll,
+	Long: `Delete all monitors matching the specified filters (asks for confirmation unless --yes is set)
+
+--details shows the type, env/service/namespace tags and query of each monitor
+in the preview. --pick lists the monitors with numbers and lets you exclude
+some of them before the confirmation, which restates how many are left to
+delete. Only the previewed (and picked) monitors are deleted.
+
+--checkpoint-file records the monitors to delete before the first deletion,
+and each monitor deleted right after it is gone (the file is replaced
+atomically, so a crash can't corrupt it). Rerunning the same command with the
+same file resumes: monitors already deleted are skipped and only the rest are
+deleted. The file is removed once every monitor is done. --chunk-size deletes
+in chunks, asking to continue between chunks unless --yes.
+
+Examples:
+  delete-all --service myapp --env hml
+  delete-all --service myapp --env hml --details
+  delete-all --services legacy-a,legacy-b --env hml
+  delete-all --tags team:old --pick
+  delete-all --env hml --checkpoint-file hml-delete.json --chunk-size 50`,
+	RunE: runDeleteAll,
 }
 
 var (
@@ -22,32 +39,56 @@ var (
 	deleteAllEnv       string
 	deleteAllNamespace string
 	deleteAllTags      string
+	deleteAllDetails   bool
+	deleteAllPick      bool
+
+	deleteAllServices string
+
+	deleteAllCheckpointFile string
+	deleteAllChunkSize      int
 )
 
 func init() {
 	rootCmd.AddCommand(deleteAllCmd)
 	deleteAllCmd.Flags().StringVar(&deleteAllService, "service", "", "Filter by service")
+	deleteAllCmd.Flags().StringVar(&deleteAllServices, "services", "", "Filter by any of these services (comma-separated, OR-matched; cannot be combined with --service)")
 	deleteAllCmd.Flags().StringVar(&deleteAllEnv, "env", "", "Filter by environment")
 	deleteAllCmd.Flags().StringVar(&deleteAllNamespace, "namespace", "", "Filter by namespace")
 	deleteAllCmd.Flags().StringVar(&deleteAllTags, "tags", "", "Filter by tags (comma-separated)")
+	deleteAllCmd.Flags().BoolVar(&deleteAllDetails, "details", false, "Show the type, env/service/namespace tags and query of each monitor in the preview")
+	deleteAllCmd.Flags().BoolVar(&deleteAllPick, "pick", false, "Pick the monitors to exclude from deletion before confirming (interactive)")
+	addCheckpointFlags(deleteAllCmd, &deleteAllCheckpointFile, &deleteAllChunkSize)
 }
 
 func runDeleteAll(cmd *cobra.Command, args []string) error {
-	client, err := datadog.NewClient()
+	if deleteAllService != "" && deleteAllServices != "" {
+		return fmt.Errorf("cannot use --service together with --services")
+	}
+	if deleteAllPick && !stdinIsTerminal() {
+		return fmt.Errorf("--pick needs an interactive terminal")
+	}
+	if deleteAllChunkSize < 0 {
+		return fmt.Errorf("--chunk-size must be positive")
+	}
+
+	client, err := newClient()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		errOut.Printf("❌ Error: %v\n", err)
 		return err
 	}
 
-	fmt.Println("\n🔍 Finding monitors to delete with filters:")
+	out.Println("\n🔍 Finding monitors to delete with filters:")
 	if deleteAllService != "" {
-		fmt.Printf("📦 Service: %s\n", deleteAllService)
+		out.Printf("📦 Service: %s\n", deleteAllService)
+	}
+	if deleteAllServices != "" {
+		out.Printf("📦 Services: %s\n", strings.Join(splitCommaList(deleteAllServices), ", "))
 	}
 	if deleteAllEnv != "" {
-		fmt.Printf("🌍 Environment: %s\n", deleteAllEnv)
+		out.Printf("🌍 Environment: %s\n", deleteAllEnv)
 	}
 	if deleteAllNamespace != "" {
-		fmt.Printf("🏷️  Namespace: %s\n", deleteAllNamespace)
+		out.Printf("🏷️  Namespace: %s\n", deleteAllNamespace)
 	}
 
 	var tags []string
@@ -57,138 +98,173 @@ func runDeleteAll(cmd *cobra.Command, args []string) error {
 			tags[i] = strings.TrimSpace(tags[i])
 		}
 		if len(tags) > 0 {
-			fmt.Printf("🏷️  Tags: %s\n", strings.Join(tags, ", "))
+			out.Printf("🏷️  Tags: %s\n", strings.Join(tags, ", "))
 		}
 	}
-	fmt.Println(strings.Repeat("=", 80))
+	out.Println(strings.Repeat("=", 80))
 
-	// Find monitors to delete
-	monitors, err := client.ListMonitors(tags, "")
+	checkpoint, err := resumeCheckpoint(deleteAllCheckpointFile, deleteAllOperation(tags))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Error listing monitors: %v\n", err)
+		errOut.Printf("❌ Error: %v\n", err)
 		return err
 	}
 
-	// Filter monitors by service, env, namespace
 	var filteredMonitors []datadog.Monitor
-	for _, monitor := range monitors {
-		matches := true
-		monitorTags := monitor.Tags
-
-		if deleteAllService != "" {
-			found := false
-			for _, tag := range monitorTags {
-				if tag == fmt.Sprintf("service:%s", deleteAllService) {
-					found = true
-					break
-				}
-			}
-			if !found {
-				matches = false
-			}
-		}
-
-		if deleteAllEnv != "" {
-			found := false
-			for _, tag := range monitorTags {
-				if tag == fmt.Sprintf("env:%s", deleteAllEnv) {
-					found = true
-					break
-				}
-			}
-			if !found {
-				matches = false
-			}
+	if checkpoint != nil {
+		filteredMonitors = checkpoint.Remaining()
+		if len(filteredMonitors) == 0 {
+			out.Println("ℹ️  Every monitor of the checkpoint is already deleted")
+			finishCheckpoint(checkpoint, deleteAllCheckpointFile)
+			return nil
 		}
-
-		if deleteAllNamespace != "" {
-			found := false
-			for _, tag := range monitorTags {
-				if tag == fmt.Sprintf("namespace:%s", deleteAllNamespace) {
-					found = true
-					break
-				}
-			}
-			if !found {
-				matches = false
-			}
+		out.Printf("\n📋 %d monitors left to delete:\n", len(filteredMonitors))
+		for _, monitor := range filteredMonitors {
+			out.Printf("   ID %d: %s\n", monitor.ID, monitor.Name)
 		}
-
-		if matches {
-			filteredMonitors = append(filteredMonitors, monitor)
+	} else {
+		filteredMonitors, err = selectMonitorsToDelete(client, tags)
+		if err != nil || len(filteredMonitors) == 0 {
+			return err
 		}
 	}
 
-	if len(filteredMonitors) == 0 {
-		fmt.Println("ℹ️  No monitors found matching the specified filters")
+	warnCompositeReferences(client, filteredMonitors)
+
+	var sample []string
+	if deleteAllPick {
+		sample = monitorSample(filteredMonitors)
+	}
+	confirmed, err := confirm(len(filteredMonitors), "permanently delete", sample)
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+	if !confirmed {
+		out.Println("❌ Deletion cancelled")
 		return nil
 	}
 
-	// Show monitors that will be deleted
-	fmt.Printf("\n📋 Found %d monitors to delete:\n", len(filteredMonitors))
-	for _, monitor := range filteredMonitors {
-		status := "🟢 Enabled"
-		if monitor.OverallState == "muted" {
-			status = "🔴 Disabled"
+	if checkpoint == nil {
+		checkpoint, err = startCheckpoint(deleteAllCheckpointFile, deleteAllOperation(tags), filteredMonitors)
+		if err != nil {
+			errOut.Printf("❌ Error: %v\n", err)
+			return err
 		}
-		fmt.Printf("   ID %d: %s (%s)\n", monitor.ID, monitor.Name, status)
-	}
-
-	// Interactive confirmation
-	fmt.Printf("\n⚠️  WARNING: This will permanently delete %d monitors!\n", len(filteredMonitors))
-	fmt.Print("Type 'yes' to confirm deletion: ")
-
-	reader := bufio.NewReader(os.Stdin)
-	confirm, _ := reader.ReadString('\n')
-	confirm = strings.TrimSpace(strings.ToLower(confirm))
-
-	if confirm != "yes" {
-		fmt.Println("❌ Deletion cancelled")
-		return nil
 	}
 
-	fmt.Println("\n🗑️  Deleting monitors...")
+	out.Println("\n🗑️  Deleting monitors...")
 
 	// Delete monitors
-	results, err := client.DeleteMonitorsByFilter(deleteAllService, deleteAllEnv, deleteAllNamespace, tags)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Error deleting monitors: %v\n", err)
+	var results []datadog.DeleteResult
+	completed, err := forEachChunk(filteredMonitors, deleteAllChunkSize, "permanently delete", func(chunk []datadog.Monitor) error {
+		chunkResults, err := client.DeleteMonitorsWithCheckpoint(chunk, checkpoint)
+		results = append(results, chunkResults...)
+		return err
+	})
+	if err != nil && !isInterrupted(err) {
+		errOut.Printf("❌ Error deleting monitors: %v\n", err)
+		finishCheckpoint(checkpoint, deleteAllCheckpointFile)
 		return err
 	}
+	printInterrupted(err, len(results), len(filteredMonitors), "monitor(s)")
+	if !completed {
+		out.Printf("\n⏹️  Stopped after %d of %d monitor(s)\n", len(results), len(filteredMonitors))
+	}
 
-	var successfulDeletions []map[string]interface{}
-	var failedDeletions []map[string]interface{}
-
+	var successfulDeletions, notFoundDeletions, failedDeletions []datadog.DeleteResult
 	for _, result := range results {
-		if status, ok := result["status"].(string); ok && status == "deleted" {
+		switch result.Status {
+		case datadog.StatusDeleted:
 			successfulDeletions = append(successfulDeletions, result)
-		} else {
+		case datadog.StatusNotFound:
+			notFoundDeletions = append(notFoundDeletions, result)
+		default:
 			failedDeletions = append(failedDeletions, result)
 		}
 	}
 
-	fmt.Printf("\n📊 Deletion Results:\n")
-	fmt.Printf("✅ Successfully deleted: %d\n", len(successfulDeletions))
-	fmt.Printf("❌ Failed to delete: %d\n", len(failedDeletions))
+	out.Printf("\n📊 Deletion Results:\n")
+	out.Printf("✅ Successfully deleted: %d\n", len(successfulDeletions))
+	if len(notFoundDeletions) > 0 {
+		out.Printf("🔍 Already deleted: %d\n", len(notFoundDeletions))
+	}
+	out.Printf("❌ Failed to delete: %d\n", len(failedDeletions))
 
 	if len(successfulDeletions) > 0 {
-		fmt.Println("\n✅ Successfully deleted monitors:")
-		for _, monitor := range successfulDeletions {
-			id, _ := monitor["id"].(int)
-			name, _ := monitor["name"].(string)
-			fmt.Printf("   🗑️  ID %d: %s\n", id, name)
+		out.Println("\n✅ Successfully deleted monitors:")
+		for _, result := range successfulDeletions {
+			out.Printf("   🗑️  ID %d: %s\n", result.ID, result.Name)
 		}
 	}
 
 	if len(failedDeletions) > 0 {
-		fmt.Println("\n❌ Failed to delete monitors:")
-		for _, monitor := range failedDeletions {
-			id, _ := monitor["id"].(int)
-			name, _ := monitor["name"].(string)
-			status, _ := monitor["status"].(string)
-			fmt.Printf("   ⚠️  ID %d: %s - %s\n", id, name, status)
+		out.Println("\n❌ Failed to delete monitors:")
+		for _, result := range failedDeletions {
+			out.Printf("   ⚠️  ID %d: %s - %v\n", result.ID, result.Name, result.Err)
+		}
+	}
+
+	finishCheckpoint(checkpoint, deleteAllCheckpointFile)
+	return err
+}
+
+// deleteAllOperation identifies a delete-all run by its filters in its
+// checkpoint, so the file is only resumed by the same command
+func deleteAllOperation(tags []string) string {
+	return fmt.Sprintf("delete-all service=%s services=%s env=%s namespace=%s tags=%s",
+		deleteAllService, strings.Join(splitCommaList(deleteAllServices), ","), deleteAllEnv, deleteAllNamespace, strings.Join(tags, ","))
+}
+
+// selectMonitorsToDelete finds the monitors matching the filters, previews
+// them and lets the user pick with --pick; none when nothing is left to delete
+func selectMonitorsToDelete(client *datadog.Client, tags []string) ([]datadog.Monitor, error) {
+	// Find monitors to delete
+	filteredMonitors, err := fetchMonitors(client, monitorSelector{
+		Tags:      tags,
+		Service:   deleteAllService,
+		Services:  splitCommaList(deleteAllServices),
+		Env:       deleteAllEnv,
+		Namespace: deleteAllNamespace,
+		Mutating:  true,
+	})
+	if err != nil {
+		errOut.Printf("❌ Error listing monitors: %v\n", err)
+		return nil, err
+	}
+
+	if len(filteredMonitors) == 0 {
+		out.Println("ℹ️  No monitors found matching the specified filters")
+		return nil, nil
+	}
+
+	// Show monitors that will be deleted
+	out.Printf("\n📋 Found %d monitors to delete:\n", len(filteredMonitors))
+	for _, monitor := range filteredMonitors {
+		status := "🟢 Enabled"
+		if monitor.OverallState == "muted" {
+			status = "🔴 Disabled"
+		}
+		out.Printf("   ID %d: %s (%s)\n", monitor.ID, monitor.Name, status)
+		if deleteAllDetails {
+			printMonitorPreviewDetails(monitor, "      ")
+		}
+	}
+
+	if deleteAllPick {
+		found := len(filteredMonitors)
+		filteredMonitors, err = pickMonitors(filteredMonitors, deleteAllDetails)
+		if err != nil {
+			errOut.Printf("❌ Error: %v\n", err)
+			return nil, err
+		}
+		if len(filteredMonitors) == 0 {
+			out.Println("ℹ️  All monitors were excluded, nothing to delete")
+			return nil, nil
+		}
+		if excluded := found - len(filteredMonitors); excluded > 0 {
+			out.Printf("\n⏭️  Excluded %d monitor(s); %d of %d left to delete\n", excluded, len(filteredMonitors), found)
 		}
 	}
 
-	return nil
+	return filteredMonitors, nil
 }
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
@@ -13,84 +15,482 @@ import (
 var describeCmd = &cobra.Command{
 	Use:   "describe",
 	Short: "Show detailed monitor information",
-	Long:  `Show detailed information about a specific monitor`,
-	RunE:  runDescribe,
+	Long: `Show detailed information about one or more monitors, or compare two.
+
+Instead of --monitor-id, the filter flags (--service, --env, --namespace,
+--filter-tags, --query) select the monitors to describe, e.g. for an
+inventory dump. Each matching monitor is then fetched on its own, with the
+full detail the list endpoint omits, --concurrency at a time, with progress
+on stderr. The monitors are printed sorted by ID so that dumps diff cleanly;
+with --json they are always a JSON array, and --ndjson prints one monitor per
+line instead (for jq or BigQuery).
+
+--compare prints a field-by-field diff of exactly two monitors (name, type,
+query, message, tags added/removed and options), e.g. to see why two
+supposedly identical monitors behave differently.
+
+Examples:
+  describe --monitor-id 12345
+  describe --monitor-id 12345,12346 --monitor-id 12347
+  describe --monitor-id 12345,12346 --compare
+  describe --monitor-id 12345,12346 --compare --json
+  describe --monitor-id 12345 --group-states all
+  describe --monitor-id 12345 --audit --since 7d
+  describe --monitor-id 12345,12346 --format '{{.ID}}: {{.Query}}'
+  describe --env prd --json > prd-monitors.json
+  describe --env prd --ndjson --concurrency 8 | jq -c '{id, options}'
+
+--audit follows the details of each --monitor-id monitor with its changes
+from the Datadog Audit Trail since --since (see audit remote). It needs the API
+v2 feature flag; when the organization doesn't have Audit Trail, the details
+are still printed with a warning.
+
+--format and --format-preset print each monitor with a Go template, as in list.`,
+	RunE: runDescribe,
 }
 
 var (
-	describeMonitorID int
-	describeJSON      bool
+	describeMonitorIDs []int
+	describeJSON       bool
+	describeCompare    bool
+	describeGroups     string
+	describeFormat     string
+	describePreset     string
+
+	describeService     string
+	describeEnv         string
+	describeNamespace   string
+	describeFilterTags  string
+	describeQuery       string
+	describeNDJSON      bool
+	describeConcurrency int
+	describeAudit       bool
+	describeAuditSince  string
 )
 
 func init() {
 	rootCmd.AddCommand(describeCmd)
-	describeCmd.Flags().IntVar(&describeMonitorID, "monitor-id", 0, "Monitor ID (required)")
-	describeCmd.MarkFlagRequired("monitor-id")
+	describeCmd.Flags().IntSliceVar(&describeMonitorIDs, "monitor-id", nil, "Monitor ID (comma-separated or repeated for several; or select monitors with the filter flags)")
 	describeCmd.Flags().BoolVar(&describeJSON, "json", false, "Output in JSON format")
+	describeCmd.Flags().BoolVar(&describeNDJSON, "ndjson", false, "Output one JSON monitor per line")
+	describeCmd.Flags().BoolVar(&describeCompare, "compare", false, "Compare exactly two monitors field by field")
+	describeCmd.Flags().StringVar(&describeFormat, "format", "", "Go template executed per monitor (see list --help)")
+	describeCmd.Flags().StringVar(&describePreset, "format-preset", "", "Built-in output format: "+strings.Join(monitorFormatPresetNames(), ", "))
+	describeCmd.Flags().StringVar(&describeGroups, "group-states", "", "Show per-group states of multi-alert monitors for these states (comma-separated: all, alert, warn, no data)")
+	describeCmd.Flags().StringVar(&describeService, "service", "", "Describe the monitors of this service")
+	describeCmd.Flags().StringVar(&describeEnv, "env", "", "Describe the monitors of this environment")
+	describeCmd.Flags().StringVar(&describeNamespace, "namespace", "", "Describe the monitors of this namespace")
+	describeCmd.Flags().StringVar(&describeFilterTags, "filter-tags", "", "Describe the monitors with these tags (comma-separated)")
+	describeCmd.Flags().StringVar(&describeQuery, "query", "", "Describe the monitors matching this search query (e.g., service:(service1 OR service2))")
+	describeCmd.Flags().IntVar(&describeConcurrency, "concurrency", datadog.DefaultFetchConcurrency, "Monitors fetched at once when selecting with filters")
+	describeCmd.Flags().BoolVar(&describeAudit, "audit", false, "Also show the changes the Datadog Audit Trail recorded for the monitor (API v2)")
+	describeCmd.Flags().StringVar(&describeAuditSince, "since", defaultAuditSince, "With --audit, how far back to list changes (e.g., 12h, 7d, 2w)")
 }
 
 func runDescribe(cmd *cobra.Command, args []string) error {
-	client, err := datadog.NewClient()
+	selector := monitorSelector{
+		Query:     describeQuery,
+		Service:   describeService,
+		Env:       describeEnv,
+		Namespace: describeNamespace,
+		Tags:      splitCommaList(describeFilterTags),
+		Cacheable: true,
+	}
+	if err := selector.validate(); err != nil {
+		return err
+	}
+	batch := selector.hasFilters()
+	switch {
+	case batch && len(describeMonitorIDs) > 0:
+		return fmt.Errorf("--monitor-id cannot be combined with the filter flags")
+	case !batch && len(describeMonitorIDs) == 0:
+		return fmt.Errorf("--monitor-id or a filter (--service, --env, --namespace, --filter-tags or --query) is required")
+	}
+	if describeConcurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	if describeNDJSON && describeJSON {
+		return fmt.Errorf("--ndjson and --json cannot be combined")
+	}
+	if describeCompare && (batch || describeNDJSON) {
+		return fmt.Errorf("--compare needs --monitor-id and cannot be combined with --ndjson")
+	}
+	if describeCompare && len(describeMonitorIDs) != 2 {
+		return fmt.Errorf("--compare needs exactly two monitor IDs (got %d)", len(describeMonitorIDs))
+	}
+	var auditSinceTime time.Time
+	if describeAudit {
+		if batch || describeCompare || describeJSON || describeNDJSON || describeFormat != "" || describePreset != "" {
+			return fmt.Errorf("--audit needs --monitor-id and cannot be combined with --compare, --json, --ndjson or --format")
+		}
+		since, err := auditSince(describeAuditSince)
+		if err != nil {
+			return err
+		}
+		auditSinceTime = since
+		if err := requireAPIV2("describe --audit"); err != nil {
+			errOut.Printf("❌ Error: %v\n", err)
+			return err
+		}
+	} else if cmd.Flags().Changed("since") {
+		return fmt.Errorf("--since only applies with --audit")
+	}
+	groupStates, err := datadog.NormalizeGroupStates(splitCommaList(describeGroups))
+	if err != nil {
+		return err
+	}
+	formatter, err := newMonitorFormatter(describeFormat, describePreset)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
 		return err
 	}
+	if formatter != nil && (describeJSON || describeNDJSON || describeCompare) {
+		return fmt.Errorf("--format and --format-preset cannot be combined with --json, --ndjson or --compare")
+	}
 
-	monitor, err := client.GetMonitor(describeMonitorID)
+	client, err := newClient()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Error getting monitor: %v\n", err)
+		errOut.Printf("❌ Error: %v\n", err)
 		return err
 	}
 
+	var monitors []datadog.Monitor
+	var lastErr error
+	if batch {
+		monitors, lastErr = describeSelectedMonitors(client, selector, groupStates)
+		if lastErr != nil && (len(monitors) == 0 || isInterrupted(lastErr)) {
+			return lastErr
+		}
+	}
+	for _, id := range describeMonitorIDs {
+		monitor, err := client.GetMonitorWithGroupStates(id, groupStates)
+		if err != nil {
+			reportMonitorError("getting monitor", err)
+			if describeCompare || isInterrupted(err) {
+				return err
+			}
+			lastErr = err
+			continue
+		}
+		monitors = append(monitors, *monitor)
+	}
+
+	if describeCompare {
+		return printMonitorComparison(monitors[0], monitors[1])
+	}
+
+	if formatter != nil {
+		formatter.appURL = client.AppURL()
+		if err := formatter.print(os.Stdout, monitors); err != nil {
+			return err
+		}
+		return lastErr
+	}
+
+	if describeNDJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, monitor := range monitors {
+			if err := encoder.Encode(monitor); err != nil {
+				return err
+			}
+		}
+		return lastErr
+	}
+
 	if describeJSON {
-		jsonData, err := json.MarshalIndent(monitor, "", "  ")
+		var output interface{} = monitors
+		if monitors == nil {
+			output = []datadog.Monitor{}
+		}
+		if len(describeMonitorIDs) == 1 {
+			if len(monitors) == 0 {
+				return lastErr
+			}
+			output = monitors[0]
+		}
+		jsonData, err := json.MarshalIndent(output, "", "  ")
 		if err != nil {
 			return err
 		}
 		fmt.Println(string(jsonData))
+		return lastErr
+	}
+
+	for i := range monitors {
+		printMonitorDetails(&monitors[i])
+		if describeAudit {
+			if err := printMonitorAuditTrail(client, monitors[i].ID, auditSinceTime); err != nil {
+				return err
+			}
+		}
+	}
+	return lastErr
+}
+
+// printMonitorAuditTrail prints the Audit Trail of a monitor for --audit. An
+// organization without Audit Trail only gets a warning, as the details were
+// printed already.
+func printMonitorAuditTrail(client *datadog.Client, monitorID int, since time.Time) error {
+	events, err := client.MonitorAuditEvents(monitorID, since)
+	if datadog.IsAuditTrailForbidden(err) {
+		errOut.Printf("⚠️  Warning: no Audit Trail for monitor %d: %v\n", monitorID, err)
 		return nil
 	}
+	if err != nil {
+		errOut.Printf("❌ Error listing audit events: %v\n", err)
+		return err
+	}
+	printAuditEvents(monitorID, events, describeAuditSince)
+	return nil
+}
 
-	// Human-readable format
-	fmt.Println("\n📊 Monitor Details:")
-	fmt.Println(strings.Repeat("=", 80))
-	fmt.Printf("ID: %d\n", monitor.ID)
-	fmt.Printf("Name: %s\n", monitor.Name)
-	fmt.Printf("Type: %s\n", monitor.Type)
-	fmt.Printf("Query: %s\n", monitor.Query)
-	fmt.Printf("Message: %s\n", monitor.Message)
-	fmt.Printf("Overall State: %s\n", monitor.OverallState)
+// describeSelectedMonitors fetches the full detail of the monitors selector
+// matches, describeConcurrency at a time, sorted by ID. Progress goes to
+// stderr: a live counter on a terminal, a line every describeProgressEvery
+// monitors otherwise. Failed monitors are reported and the last error is
+// returned with the monitors fetched.
+func describeSelectedMonitors(client *datadog.Client, selector monitorSelector, groupStates []string) ([]datadog.Monitor, error) {
+	listed, err := fetchMonitors(client, selector)
+	if err != nil {
+		errOut.Printf("❌ Error listing monitors: %v\n", err)
+		return nil, err
+	}
+	ids := make([]int, len(listed))
+	for i, monitor := range listed {
+		ids[i] = monitor.ID
+	}
+	logVerbose("Fetching %d monitor(s), %d at a time", len(ids), describeConcurrency)
+
+	live := stderrIsTerminal()
+	monitors, failures := client.GetMonitors(ids, groupStates, describeConcurrency, func(done, total int) {
+		switch {
+		case live:
+			errOut.Printf("\r⏳ Fetched %d/%d monitor(s)", done, total)
+		case done%describeProgressEvery == 0 && done < total:
+			errOut.Printf("⏳ Fetched %d/%d monitor(s)\n", done, total)
+		}
+	})
+	if live && len(ids) > 0 {
+		errOut.Println()
+	}
+
+	failedIDs := make([]int, 0, len(failures))
+	for id := range failures {
+		failedIDs = append(failedIDs, id)
+	}
+	sort.Ints(failedIDs)
+	var lastErr error
+	for _, id := range failedIDs {
+		lastErr = failures[id]
+		if !isInterrupted(lastErr) {
+			reportMonitorError(fmt.Sprintf("getting monitor %d", id), lastErr)
+		}
+	}
+	if ctxErr := interruption(lastErr); ctxErr != nil {
+		errOut.Printf("⚠️  Interrupted after fetching %d of %d monitor(s)\n", len(monitors), len(ids))
+		return monitors, ctxErr
+	}
+	errOut.Printf("✅ Fetched %d monitor(s)", len(monitors))
+	if len(failures) > 0 {
+		errOut.Printf(", %d failed", len(failures))
+	}
+	errOut.Println()
+	return monitors, lastErr
+}
+
+// describeProgressEvery is how often, in monitors, describe reports progress
+// when stderr is not a terminal
+const describeProgressEvery = 100
+
+// printMonitorDetails prints a monitor in human-readable form
+func printMonitorDetails(monitor *datadog.Monitor) {
+	out.Println("\n📊 Monitor Details:")
+	out.Println(strings.Repeat("=", 80))
+	out.Printf("ID: %d\n", monitor.ID)
+	out.Printf("Name: %s\n", monitor.Name)
+	out.Printf("Type: %s\n", monitor.Type)
+	out.Printf("Query: %s\n", monitor.Query)
+	if monitor.Type == "log alert" {
+		if block, ok := datadog.DecompileLogQuery(monitor.Query, monitor.Options); ok {
+			blockJSON, _ := json.Marshal(block)
+			out.Printf("Log Block: %s\n", string(blockJSON))
+		}
+	}
+	if monitor.Type == "service check" {
+		if block, ok := datadog.DecompileServiceCheckQuery(monitor.Query, monitor.Options); ok {
+			blockJSON, _ := json.Marshal(block)
+			out.Printf("Service Check Block: %s\n", string(blockJSON))
+		}
+	}
+	out.Printf("Message: %s\n", monitor.Message)
+	if escalation := datadog.EscalationMessage(*monitor); escalation != "" {
+		out.Printf("Escalation Message: %s\n", escalation)
+	}
+	out.Printf("Overall State: %s\n", monitor.OverallState)
 
 	status := "🟢 Enabled"
 	if monitor.OverallState == "muted" {
 		status = "🔴 Disabled"
 	}
-	fmt.Printf("Status: %s\n", status)
+	out.Printf("Status: %s\n", status)
+	if disabledAt, ok := datadog.DisabledSince(*monitor); ok && datadog.IsDisabled(*monitor) {
+		out.Printf("Disabled For: %s (since %s)\n", formatDuration(time.Since(disabledAt)), formatTime(disabledAt))
+	}
 
 	if len(monitor.Tags) > 0 {
-		fmt.Printf("Tags: %s\n", strings.Join(monitor.Tags, ", "))
+		out.Printf("Tags: %s\n", strings.Join(monitor.Tags, ", "))
 	}
 
 	if monitor.Options != nil {
 		if thresholds, ok := monitor.Options["thresholds"].(map[string]interface{}); ok {
 			thresholdsJSON, _ := json.Marshal(thresholds)
-			fmt.Printf("Thresholds: %s\n", string(thresholdsJSON))
+			out.Printf("Thresholds: %s\n", string(thresholdsJSON))
 		}
 		if notifyNoData, ok := monitor.Options["notify_no_data"].(bool); ok {
-			fmt.Printf("Notify No Data: %v\n", notifyNoData)
+			out.Printf("Notify No Data: %v\n", notifyNoData)
 		}
 		if notifyAudit, ok := monitor.Options["notify_audit"].(bool); ok {
-			fmt.Printf("Notify Audit: %v\n", notifyAudit)
+			out.Printf("Notify Audit: %v\n", notifyAudit)
+		}
+		if delay, ok := monitor.Options["evaluation_delay"].(float64); ok {
+			out.Printf("Evaluation Delay: %.0f seconds\n", delay)
+		}
+		if delay, ok := monitor.Options["new_group_delay"].(float64); ok {
+			out.Printf("New Group Delay: %.0f seconds\n", delay)
+		}
+		renotify := datadog.RenotifyFromOptions(monitor.Options)
+		if renotify.Interval != nil {
+			out.Printf("Renotify Interval: %d minutes\n", *renotify.Interval)
+		}
+		if renotify.Occurrences != nil {
+			out.Printf("Renotify Occurrences: %d\n", *renotify.Occurrences)
+		}
+		if len(renotify.Statuses) > 0 {
+			out.Printf("Renotify Statuses: %s\n", strings.Join(renotify.Statuses, ", "))
+		}
+		if renotify.NotificationPreset != "" {
+			out.Printf("Notification Preset: %s\n", renotify.NotificationPreset)
+		}
+	}
+
+	if silenced := datadog.SilencedScopes(*monitor); len(silenced) > 0 {
+		out.Println("Silenced:")
+		for _, scope := range silenced {
+			until := muteEndLabel(scope.End)
+			if !scope.End.IsZero() && timeFormat != timeFormatRelative {
+				until += fmt.Sprintf(" (in %s)", formatDuration(time.Until(scope.End)))
+			}
+			out.Printf("  🔇 %s %s\n", scopeLabel(scope.Scope), until)
+		}
+	}
+
+	if monitor.Creator != nil {
+		out.Printf("Creator: %s\n", formatCreator(monitor.Creator))
+	}
+	if !monitor.CreatedAt.IsZero() {
+		out.Printf("Created: %s\n", formatTimestamp(monitor.CreatedAt))
+	}
+	if !monitor.Modified.IsZero() {
+		out.Printf("Modified: %s\n", formatTimestamp(monitor.Modified))
+	}
+	printGroupStates(*monitor)
+
+	out.Println(strings.Repeat("=", 80))
+}
+
+// groupStateIcons mark group states in printGroupStates
+var groupStateIcons = map[string]string{
+	"alert":   "🔴",
+	"warn":    "🟡",
+	"no data": "⚪",
+	"ok":      "🟢",
+}
+
+// printGroupStates prints the per-group states of a monitor fetched with group
+// states, with the time each group last triggered
+func printGroupStates(monitor datadog.Monitor) {
+	groups := datadog.SortedGroups(monitor)
+	if groups == nil {
+		return
+	}
+	out.Printf("Groups (%d):\n", len(groups))
+	for _, group := range groups {
+		icon := groupStateIcons[canonicalMonitorState(group.Status)]
+		if icon == "" {
+			icon = "⚫"
 		}
+		triggered := "never triggered"
+		if !group.LastTriggeredTS.IsZero() {
+			triggered = "last triggered " + formatTimestamp(group.LastTriggeredTS)
+		}
+		out.Printf("  %s %s: %s, %s\n", icon, group.Name, group.Status, triggered)
+	}
+}
+
+// printMonitorComparison prints the differences between two monitors, or with
+// --json both monitors and the structured diff
+func printMonitorComparison(from, to datadog.Monitor) error {
+	diff := datadog.DiffMonitors(from, to)
+
+	if describeJSON {
+		jsonData, err := json.MarshalIndent(struct {
+			Monitors []datadog.Monitor   `json:"monitors"`
+			Diff     datadog.MonitorDiff `json:"diff"`
+		}{[]datadog.Monitor{from, to}, diff}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(jsonData))
+		return nil
 	}
 
-	if monitor.CreatedAt.Int64() > 0 {
-		fmt.Printf("Created: %d\n", monitor.CreatedAt.Int64())
+	out.Printf("\n🔍 Comparing monitors:\n")
+	out.Printf("   - ID %d: %s\n", from.ID, from.Name)
+	out.Printf("   + ID %d: %s\n", to.ID, to.Name)
+	out.Println(strings.Repeat("=", 80))
+
+	if diff.Empty() {
+		out.Println("✅ The monitors are identical (name, type, query, message, priority, tags and options)")
+		return nil
+	}
+
+	for _, change := range diff.Fields {
+		out.Printf("%s:\n", strings.ToUpper(change.Field[:1])+change.Field[1:])
+		out.Printf("  - %v\n", indentLines(fmt.Sprint(change.From), "    "))
+		out.Printf("  + %v\n", indentLines(fmt.Sprint(change.To), "    "))
 	}
-	if monitor.Modified.Int64() > 0 {
-		fmt.Printf("Modified: %d\n", monitor.Modified.Int64())
+	if len(diff.TagsAdded) > 0 || len(diff.TagsRemoved) > 0 {
+		out.Println("Tags:")
+		for _, tag := range diff.TagsRemoved {
+			out.Printf("  - %s\n", tag)
+		}
+		for _, tag := range diff.TagsAdded {
+			out.Printf("  + %s\n", tag)
+		}
+	}
+	if len(diff.Options) > 0 {
+		out.Println("Options:")
+		for _, change := range diff.Options {
+			out.Printf("  %s: %s → %s\n", change.Field, formatOptionValue(change.From), formatOptionValue(change.To))
+		}
 	}
 
-	fmt.Println(strings.Repeat("=", 80))
+	out.Printf("\n📊 %d field(s), %d tag(s) and %d option(s) differ\n", len(diff.Fields), len(diff.TagsAdded)+len(diff.TagsRemoved), len(diff.Options))
 	return nil
 }
+
+// indentLines indents every line of a multi-line value but the first
+func indentLines(value, indent string) string {
+	return strings.ReplaceAll(value, "\n", "\n"+indent)
+}
+
+// formatOptionValue formats an option value as JSON, or "(unset)" when missing
+func formatOptionValue(value interface{}) string {
+	if value == nil {
+		return "(unset)"
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
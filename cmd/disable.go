@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var disableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Disable monitors (mute indefinitely)",
+	Long: `Disable a single monitor or multiple monitors matching filters: they are
+muted with no end time and tagged status:disabled plus disabled_at:<unix time>,
+so disabled monitors can be found with:
+
+  list --tags status:disabled
+
+Use enable to undo.
+
+Examples:
+  disable --monitor-id 12345
+  disable --service myapp --env hml`,
+	RunE: runDisable,
+}
+
+var (
+	disableMonitorID      int
+	disableService        string
+	disableEnv            string
+	disableNamespace      string
+	disableFilterTags     string
+	disableQuery          string
+	disableStatus         string
+	disableFilterServices string
+)
+
+func init() {
+	rootCmd.AddCommand(disableCmd)
+	disableCmd.Flags().IntVar(&disableMonitorID, "monitor-id", 0, "Monitor ID (for single monitor)")
+	disableCmd.Flags().StringVar(&disableService, "service", "", "Filter by service (for multiple monitors)")
+	disableCmd.Flags().StringVar(&disableEnv, "env", "", "Filter by environment (for multiple monitors)")
+	disableCmd.Flags().StringVar(&disableNamespace, "namespace", "", "Filter by namespace (for multiple monitors)")
+	disableCmd.Flags().StringVar(&disableFilterTags, "filter-tags", "", "Filter by tags (comma-separated, for multiple monitors)")
+	disableCmd.Flags().StringVar(&disableQuery, "query", "", "Complex search query (e.g., service:(service1 OR service2))")
+	disableCmd.Flags().StringVar(&disableStatus, "status", "", "Filter by monitor state, comma-separated (OK, Alert, Warn, No Data, Unknown, Skipped, Ignored)")
+	disableCmd.Flags().StringVar(&disableFilterServices, "filter-services", "", "Filter by multiple services (comma-separated, filters locally after query/tags)")
+}
+
+func runDisable(cmd *cobra.Command, args []string) error {
+	selector := monitorSelector{
+		Query:          disableQuery,
+		Service:        disableService,
+		Env:            disableEnv,
+		Namespace:      disableNamespace,
+		Tags:           splitCommaList(disableFilterTags),
+		Status:         disableStatus,
+		FilterServices: disableFilterServices,
+		Mutating:       true,
+	}
+
+	if disableMonitorID == 0 && !selector.hasFilters() {
+		return fmt.Errorf("either --monitor-id or filter flags (--service, --env, --namespace, --filter-tags, --query) must be provided")
+	}
+	if disableMonitorID > 0 && (selector.hasFilters() || disableStatus != "" || disableFilterServices != "") {
+		return fmt.Errorf("cannot use --monitor-id together with filter flags")
+	}
+	if err := selector.validate(); err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	var monitors []datadog.Monitor
+	if disableMonitorID > 0 {
+		monitor, err := client.GetMonitor(disableMonitorID)
+		if err != nil {
+			reportMonitorError("getting monitor", err)
+			return err
+		}
+		monitors = []datadog.Monitor{*monitor}
+	} else {
+		monitors, err = fetchMonitors(client, selector)
+		if err != nil {
+			errOut.Printf("❌ Error listing monitors: %v\n", err)
+			return err
+		}
+	}
+
+	var toDisable []datadog.Monitor
+	for _, monitor := range monitors {
+		if datadog.IsDisabled(monitor) {
+			since := "unknown time"
+			if disabledAt, ok := datadog.DisabledSince(monitor); ok {
+				since = formatDuration(time.Since(disabledAt))
+			}
+			out.Printf("⏭️  ID %d: %s - already disabled for %s\n", monitor.ID, monitor.Name, since)
+			continue
+		}
+		toDisable = append(toDisable, monitor)
+	}
+
+	if len(toDisable) == 0 {
+		out.Printf("ℹ️  No monitors to disable (%d monitor(s) matched)\n", len(monitors))
+		return nil
+	}
+
+	confirmed, err := confirm(len(toDisable), "disable (mute indefinitely)", monitorSample(toDisable))
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+	if !confirmed {
+		out.Println("❌ Disable cancelled")
+		return nil
+	}
+
+	now := time.Now()
+	disabled := 0
+	var failures bulkFailures
+	for i, monitor := range toDisable {
+		if failures.stop(i, nil) {
+			break
+		}
+		if _, err := client.DisableMonitor(monitor.ID, now); err != nil {
+			if failures.stop(i, err) {
+				break
+			}
+			failures.add(monitor, err)
+			continue
+		}
+		disabled++
+		out.Printf("🔴 ID %d: %s disabled\n", monitor.ID, monitor.Name)
+	}
+
+	failures.printInterrupted(len(toDisable))
+	out.Printf("\n📊 Results:\n")
+	out.Printf("✅ Successfully disabled: %d\n", disabled)
+	failures.printCounts()
+
+	failures.printDetails("disable")
+	if failures.interrupted != nil {
+		return failures.interrupted
+	}
+
+	out.Printf("\n💡 Find disabled monitors with: list --tags %s\n", datadog.DisabledTag)
+	return nil
+}
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var editMessageCmd = &cobra.Command{
+	Use:   "edit-message",
+	Short: "Edit the message of monitors in bulk",
+	Long: `Append, prepend or replace text in the message of a single monitor or of
+multiple monitors matching filters.
+
+Append and prepend are idempotent: monitors whose message already contains the
+text are skipped. Regex replacements never touch Datadog {{...}} template
+blocks unless --include-template-blocks is set.
+
+Examples:
+  edit-message --service myapp --append "\n\nEscalation: @pagerduty-secops"
+  edit-message --query "team:payments" --replace "@slack-old=@slack-new"
+  edit-message --env prd --regex "runbook: \S+=runbook: https://wiki/runbooks"`,
+	RunE: runEditMessage,
+}
+
+var (
+	editMessageMonitorID             int
+	editMessageService               string
+	editMessageEnv                   string
+	editMessageNamespace             string
+	editMessageFilterTags            string
+	editMessageQuery                 string
+	editMessageStatus                string
+	editMessageFilterServices        string
+	editMessageAppend                string
+	editMessagePrepend               string
+	editMessageReplace               []string
+	editMessageRegex                 []string
+	editMessageIncludeTemplateBlocks bool
+)
+
+func init() {
+	rootCmd.AddCommand(editMessageCmd)
+	editMessageCmd.Flags().IntVar(&editMessageMonitorID, "monitor-id", 0, "Monitor ID (for single monitor)")
+	editMessageCmd.Flags().StringVar(&editMessageService, "service", "", "Filter by service (for multiple monitors)")
+	editMessageCmd.Flags().StringVar(&editMessageEnv, "env", "", "Filter by environment (for multiple monitors)")
+	editMessageCmd.Flags().StringVar(&editMessageNamespace, "namespace", "", "Filter by namespace (for multiple monitors)")
+	editMessageCmd.Flags().StringVar(&editMessageFilterTags, "filter-tags", "", "Filter by tags (comma-separated, for multiple monitors)")
+	editMessageCmd.Flags().StringVar(&editMessageQuery, "query", "", "Complex search query (e.g., service:(service1 OR service2))")
+	editMessageCmd.Flags().StringVar(&editMessageStatus, "status", "", "Filter by monitor state, comma-separated (OK, Alert, Warn, No Data, Unknown, Skipped, Ignored)")
+	editMessageCmd.Flags().StringVar(&editMessageFilterServices, "filter-services", "", "Filter by multiple services (comma-separated, filters locally after query/tags)")
+	editMessageCmd.Flags().StringVar(&editMessageAppend, "append", "", "Text to append to the message (skipped if already present)")
+	editMessageCmd.Flags().StringVar(&editMessagePrepend, "prepend", "", "Text to prepend to the message (skipped if already present)")
+	editMessageCmd.Flags().StringArrayVar(&editMessageReplace, "replace", []string{}, "Literal replacement old=new (can be used multiple times)")
+	editMessageCmd.Flags().StringArrayVar(&editMessageRegex, "regex", []string{}, "Regex replacement pattern=replacement (can be used multiple times)")
+	editMessageCmd.Flags().BoolVar(&editMessageIncludeTemplateBlocks, "include-template-blocks", false, "Also apply --regex inside {{...}} template blocks")
+}
+
+// messageSnippet returns the part of a message around its first difference with
+// another message, so previews stay short for long messages. It works on runes
+// so a snippet never cuts a multi-byte character in half.
+func messageSnippet(message, other string) string {
+	const context = 40
+
+	runes, otherRunes := []rune(message), []rune(other)
+	start := 0
+	for start < len(runes) && start < len(otherRunes) && runes[start] == otherRunes[start] {
+		start++
+	}
+	end := len(runes)
+	otherEnd := len(otherRunes)
+	for end > start && otherEnd > start && runes[end-1] == otherRunes[otherEnd-1] {
+		end--
+		otherEnd--
+	}
+
+	from := start - context
+	if from < 0 {
+		from = 0
+	}
+	to := end + context
+	if to > len(runes) {
+		to = len(runes)
+	}
+
+	snippet := strings.ReplaceAll(string(runes[from:to]), "\n", "\\n")
+	if from > 0 {
+		snippet = "..." + snippet
+	}
+	if to < len(runes) {
+		snippet += "..."
+	}
+	return snippet
+}
+
+func runEditMessage(cmd *cobra.Command, args []string) error {
+	edit := datadog.MessageEdit{
+		Append:                strings.ReplaceAll(editMessageAppend, `\n`, "\n"),
+		Prepend:               strings.ReplaceAll(editMessagePrepend, `\n`, "\n"),
+		IncludeTemplateBlocks: editMessageIncludeTemplateBlocks,
+	}
+	for _, value := range editMessageReplace {
+		replacement, err := datadog.ParseReplacement(value)
+		if err != nil {
+			return err
+		}
+		edit.Replace = append(edit.Replace, replacement)
+	}
+	for _, value := range editMessageRegex {
+		regexEdit, err := datadog.ParseRegexEdit(value)
+		if err != nil {
+			return err
+		}
+		edit.Regex = append(edit.Regex, regexEdit)
+	}
+	if edit.IsEmpty() {
+		return fmt.Errorf("at least one of --append, --prepend, --replace or --regex is required")
+	}
+
+	selector := monitorSelector{
+		Query:          editMessageQuery,
+		Service:        editMessageService,
+		Env:            editMessageEnv,
+		Namespace:      editMessageNamespace,
+		Tags:           splitCommaList(editMessageFilterTags),
+		Status:         editMessageStatus,
+		FilterServices: editMessageFilterServices,
+		Mutating:       true,
+	}
+
+	if editMessageMonitorID == 0 && !selector.hasFilters() {
+		return fmt.Errorf("either --monitor-id or filter flags (--service, --env, --namespace, --filter-tags, --query) must be provided")
+	}
+	if editMessageMonitorID > 0 && (selector.hasFilters() || editMessageStatus != "" || editMessageFilterServices != "") {
+		return fmt.Errorf("cannot use --monitor-id together with filter flags")
+	}
+	if err := selector.validate(); err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	var monitors []datadog.Monitor
+	if editMessageMonitorID > 0 {
+		monitor, err := client.GetMonitor(editMessageMonitorID)
+		if err != nil {
+			reportMonitorError("getting monitor", err)
+			return err
+		}
+		monitors = []datadog.Monitor{*monitor}
+	} else {
+		monitors, err = fetchMonitors(client, selector)
+		if err != nil {
+			errOut.Printf("❌ Error listing monitors: %v\n", err)
+			return err
+		}
+	}
+
+	// Preview the changes
+	var toUpdate []datadog.Monitor
+	unchanged := 0
+	for _, monitor := range monitors {
+		edited, changed := edit.Apply(monitor.Message)
+		if !changed {
+			unchanged++
+			continue
+		}
+		if len(toUpdate) == 0 {
+			out.Println("\n📝 Message changes:")
+		}
+		toUpdate = append(toUpdate, monitor)
+		out.Printf("\n   ID %d: %s\n", monitor.ID, monitor.Name)
+		out.Printf("      - %s\n", messageSnippet(monitor.Message, edited))
+		out.Printf("      + %s\n", messageSnippet(edited, monitor.Message))
+	}
+
+	if len(toUpdate) == 0 {
+		out.Printf("ℹ️  No messages to change (%d monitor(s) matched, all already up to date)\n", len(monitors))
+		return nil
+	}
+
+	out.Printf("\n📊 %d monitor(s) will be updated, %d already up to date\n", len(toUpdate), unchanged)
+
+	confirmed, err := confirm(len(toUpdate), "update the message of", nil)
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+	if !confirmed {
+		out.Println("❌ Edit cancelled")
+		return nil
+	}
+
+	updated := 0
+	var failures bulkFailures
+	for i, monitor := range toUpdate {
+		if failures.stop(i, nil) {
+			break
+		}
+		_, changed, err := client.EditMonitorMessage(monitor.ID, edit)
+		if err != nil {
+			if failures.stop(i, err) {
+				break
+			}
+			failures.add(monitor, err)
+			continue
+		}
+		if changed {
+			updated++
+		} else {
+			unchanged++
+		}
+	}
+
+	failures.printInterrupted(len(toUpdate))
+	out.Printf("\n📊 Results:\n")
+	out.Printf("✅ Successfully updated: %d\n", updated)
+	out.Printf("⏭️  Unchanged: %d\n", unchanged)
+	failures.printCounts()
+
+	failures.printDetails("update")
+
+	return failures.interrupted
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestMessageSnippet(t *testing.T) {
+	long := strings.Repeat("a", 60)
+	for _, tc := range []struct {
+		name           string
+		message, other string
+		want           string
+	}{
+		{"short", "page @slack-old", "page @slack-new", "page @slack-old"},
+		{"identical", "page @oncall", "page @oncall", "page @oncall"},
+		{"newlines escaped", "Down\n@slack-old", "Down\n@slack-new", `Down\n@slack-old`},
+		{"context cut on both sides", long + "@old" + long, long + "@new" + long, "..." + strings.Repeat("a", 39) + "@old" + strings.Repeat("a", 40) + "..."},
+		{"appended", "Down", "Down @oncall", "Down"},
+		// é and è share their first byte: the difference starts at the rune
+		{"shared leading byte", long + "café" + long, long + "cafè" + long, "..." + strings.Repeat("a", 37) + "café" + strings.Repeat("a", 40) + "..."},
+		{"multi-byte context", strings.Repeat("é", 50) + " @old " + strings.Repeat("ü", 50), strings.Repeat("é", 50) + " @new " + strings.Repeat("ü", 50), "..." + strings.Repeat("é", 38) + " @old " + strings.Repeat("ü", 39) + "..."},
+		{"emoji", "🔥 CPU high on " + long, "🚨 CPU high on " + long, "🔥 CPU high on " + strings.Repeat("a", 27) + "..."},
+	} {
+		got := messageSnippet(tc.message, tc.other)
+		if got != tc.want {
+			t.Errorf("%s: messageSnippet = %q, want %q", tc.name, got, tc.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("%s: messageSnippet = %q, not valid UTF-8", tc.name, got)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var enableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Enable disabled monitors (unmute)",
+	Long: `Enable monitors disabled with the disable command: they are unmuted and the
+status:disabled and disabled_at tags are removed. The preview shows how long
+each monitor was disabled.
+
+Examples:
+  enable --monitor-id 12345
+  enable --filter-tags status:disabled --service myapp`,
+	RunE: runEnable,
+}
+
+var (
+	enableMonitorID      int
+	enableService        string
+	enableEnv            string
+	enableNamespace      string
+	enableFilterTags     string
+	enableQuery          string
+	enableStatus         string
+	enableFilterServices string
+)
+
+func init() {
+	rootCmd.AddCommand(enableCmd)
+	enableCmd.Flags().IntVar(&enableMonitorID, "monitor-id", 0, "Monitor ID (for single monitor)")
+	enableCmd.Flags().StringVar(&enableService, "service", "", "Filter by service (for multiple monitors)")
+	enableCmd.Flags().StringVar(&enableEnv, "env", "", "Filter by environment (for multiple monitors)")
+	enableCmd.Flags().StringVar(&enableNamespace, "namespace", "", "Filter by namespace (for multiple monitors)")
+	enableCmd.Flags().StringVar(&enableFilterTags, "filter-tags", "", "Filter by tags (comma-separated, for multiple monitors)")
+	enableCmd.Flags().StringVar(&enableQuery, "query", "", "Complex search query (e.g., service:(service1 OR service2))")
+	enableCmd.Flags().StringVar(&enableStatus, "status", "", "Filter by monitor state, comma-separated (OK, Alert, Warn, No Data, Unknown, Skipped, Ignored)")
+	enableCmd.Flags().StringVar(&enableFilterServices, "filter-services", "", "Filter by multiple services (comma-separated, filters locally after query/tags)")
+}
+
+func runEnable(cmd *cobra.Command, args []string) error {
+	selector := monitorSelector{
+		Query:          enableQuery,
+		Service:        enableService,
+		Env:            enableEnv,
+		Namespace:      enableNamespace,
+		Tags:           splitCommaList(enableFilterTags),
+		Status:         enableStatus,
+		FilterServices: enableFilterServices,
+		Mutating:       true,
+	}
+
+	if enableMonitorID == 0 && !selector.hasFilters() {
+		return fmt.Errorf("either --monitor-id or filter flags (--service, --env, --namespace, --filter-tags, --query) must be provided")
+	}
+	if enableMonitorID > 0 && (selector.hasFilters() || enableStatus != "" || enableFilterServices != "") {
+		return fmt.Errorf("cannot use --monitor-id together with filter flags")
+	}
+	if err := selector.validate(); err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	var monitors []datadog.Monitor
+	if enableMonitorID > 0 {
+		monitor, err := client.GetMonitor(enableMonitorID)
+		if err != nil {
+			reportMonitorError("getting monitor", err)
+			return err
+		}
+		monitors = []datadog.Monitor{*monitor}
+	} else {
+		monitors, err = fetchMonitors(client, selector)
+		if err != nil {
+			errOut.Printf("❌ Error listing monitors: %v\n", err)
+			return err
+		}
+	}
+
+	var toEnable []datadog.Monitor
+	for _, monitor := range monitors {
+		if !datadog.IsDisabled(monitor) && monitor.OverallState != "muted" {
+			continue
+		}
+		toEnable = append(toEnable, monitor)
+	}
+
+	if len(toEnable) == 0 {
+		out.Printf("ℹ️  No disabled monitors to enable (%d monitor(s) matched)\n", len(monitors))
+		return nil
+	}
+
+	sample := make([]string, len(toEnable))
+	for i, monitor := range toEnable {
+		sample[i] = fmt.Sprintf("ID %d: %s", monitor.ID, monitor.Name)
+		if disabledAt, ok := datadog.DisabledSince(monitor); ok {
+			sample[i] += fmt.Sprintf(" (disabled for %s)", formatDuration(time.Since(disabledAt)))
+		}
+	}
+
+	confirmed, err := confirm(len(toEnable), "enable (unmute)", sample)
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+	if !confirmed {
+		out.Println("❌ Enable cancelled")
+		return nil
+	}
+
+	enabled := 0
+	var failures bulkFailures
+	for i, monitor := range toEnable {
+		if failures.stop(i, nil) {
+			break
+		}
+		if _, err := client.EnableMonitor(monitor.ID); err != nil {
+			if failures.stop(i, err) {
+				break
+			}
+			failures.add(monitor, err)
+			continue
+		}
+		enabled++
+		out.Printf("🟢 ID %d: %s enabled\n", monitor.ID, monitor.Name)
+	}
+
+	failures.printInterrupted(len(toEnable))
+	out.Printf("\n📊 Results:\n")
+	out.Printf("✅ Successfully enabled: %d\n", enabled)
+	failures.printCounts()
+
+	failures.printDetails("enable")
+
+	return failures.interrupted
+}
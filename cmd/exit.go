@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+// Process exit codes
+const (
+	ExitFailure     = 1   // Any other error
+	ExitNotFound    = 3   // A monitor given by ID does not exist
+	ExitConflict    = 4   // Templates matched monitors not managed by the tool (--protect-unmanaged)
+	ExitRollback    = 5   // An --atomic apply failed and some of its changes could not be rolled back
+	ExitPartial     = 6   // --request-budget or --fail-fast stopped the command; the summary printed is partial
+	ExitTimeout     = 124 // --timeout expired; the summary printed is partial
+	ExitInterrupted = 130 // Interrupted (Ctrl-C/SIGTERM); the summary printed is partial
+)
+
+// ExitCode returns the process exit code for an error returned by Execute
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	if datadog.IsRollbackIncomplete(err) {
+		// Takes precedence: changes were left behind, whatever stopped the run
+		return ExitRollback
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ExitTimeout
+	}
+	if errors.Is(err, context.Canceled) {
+		return ExitInterrupted
+	}
+	if datadog.IsLimitExceeded(err) {
+		return ExitPartial
+	}
+	if datadog.IsMonitorNotFound(err) {
+		return ExitNotFound
+	}
+	if datadog.IsUnmanagedConflict(err) {
+		return ExitConflict
+	}
+	return ExitFailure
+}
+
+// reportMonitorError prints the error of an operation on a single monitor,
+// with a concise message when the monitor does not exist
+func reportMonitorError(action string, err error) {
+	if datadog.IsMonitorNotFound(err) {
+		errOut.Printf("❌ %v\n", err)
+		return
+	}
+	errOut.Printf("❌ Error %s: %v\n", action, err)
+}
+
+// bulkFailures collects the per-monitor failures of a bulk operation, keeping
+// monitors that no longer exist apart from real errors
+type bulkFailures struct {
+	notFound []string
+	failed   []string
+
+	// interrupted is set when the loop stopped early on Ctrl-C or --timeout,
+	// after done items
+	interrupted error
+	done        int
+}
+
+// stop reports whether a bulk loop must stop before finishing item i because
+// the command was interrupted or timed out. err is the error of item i, if any.
+func (f *bulkFailures) stop(i int, err error) bool {
+	f.interrupted = interruption(err)
+	f.done = i
+	return f.interrupted != nil
+}
+
+// printInterrupted marks the results summary as partial if the loop was stopped
+func (f *bulkFailures) printInterrupted(total int) {
+	printInterrupted(f.interrupted, f.done, total, "monitor(s)")
+}
+
+// add records the failure of an operation on a monitor
+func (f *bulkFailures) add(monitor datadog.Monitor, err error) {
+	entry := fmt.Sprintf("ID %d: %s", monitor.ID, monitor.Name)
+	recordFailure(err)
+	if datadog.IsMonitorNotFound(err) {
+		f.notFound = append(f.notFound, entry)
+		return
+	}
+	f.failed = append(f.failed, fmt.Sprintf("%s - %v", entry, err))
+}
+
+// printCounts prints the not-found and failed counts of the results summary
+func (f *bulkFailures) printCounts() {
+	if len(f.notFound) > 0 {
+		out.Printf("🔍 Not found (deleted meanwhile): %d\n", len(f.notFound))
+	}
+	out.Printf("❌ Failed: %d\n", len(f.failed))
+}
+
+// printDetails lists the monitors that were not found or failed
+func (f *bulkFailures) printDetails(action string) {
+	if len(f.notFound) > 0 {
+		out.Println("\n🔍 Monitors that no longer exist:")
+		for _, entry := range f.notFound {
+			out.Printf("   ⚠️  %s\n", entry)
+		}
+	}
+	if len(f.failed) > 0 {
+		out.Printf("\n❌ Failed to %s monitors:\n", action)
+		for _, entry := range f.failed {
+			out.Printf("   ⚠️  %s\n", entry)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadogtest"
+)
+
+func TestExitCode(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want int
+	}{
+		{nil, 0},
+		{errors.New("boom"), ExitFailure},
+		{context.Canceled, ExitInterrupted},
+		{fmt.Errorf("deleting: %w", context.DeadlineExceeded), ExitTimeout},
+		{&datadog.LimitError{RequestBudget: 10}, ExitPartial},
+		{fmt.Errorf("failed to apply CPU: %w", &datadog.LimitError{FailFast: 3}), ExitPartial},
+		{&datadog.ErrMonitorNotFound{ID: 1}, ExitNotFound},
+		{&datadog.UnmanagedMonitorError{ID: 1, Name: "CPU"}, ExitConflict},
+	} {
+		if got := ExitCode(tc.err); got != tc.want {
+			t.Errorf("ExitCode(%v) = %d, want %d", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestLimitsStopWithPartialSummary(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		args []string
+		// fail makes the server fail every change
+		fail    bool
+		summary []string
+	}{
+		// Two list requests, then two deletes
+		{"request budget", []string{"delete-all", "--service", "old", "--request-budget", "4"}, false,
+			[]string{"Request budget spent (--request-budget 4) after 2 of 6 monitor(s) - the results below are PARTIAL", "Successfully deleted: 2"}},
+		{"fail fast", []string{"delete-all", "--service", "old", "--fail-fast", "2"}, true,
+			[]string{"Stopped at 2 failure(s) (--fail-fast) after 2 of 6 monitor(s) - the results below are PARTIAL", "Failed to delete: 2"}},
+		{"fail fast per monitor loop", []string{"set-renotify", "--service", "old", "--interval", "30", "--fail-fast", "3"}, true,
+			[]string{"Stopped at 3 failure(s) (--fail-fast) after 3 of 6 monitor(s) - the results below are PARTIAL", "Failed: 3"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := newTestServer(t)
+			var monitors []datadog.Monitor
+			for i := 0; i < 6; i++ {
+				monitors = append(monitors, srv.AddMonitor(datadog.Monitor{Name: "old " + strconv.Itoa(i), Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90", Tags: []string{"service:old"}}))
+			}
+			if tc.fail {
+				srv.InjectFault(datadogtest.ServerError("DELETE", "/monitor/*", 0))
+				srv.InjectFault(datadogtest.ServerError("PUT", "/monitor/*", 0))
+			}
+
+			res := runCLI(t, nil, append([]string{"--yes"}, tc.args...)...)
+			if code := ExitCode(res.Err); code != ExitPartial {
+				t.Fatalf("exit code %d (%v), want %d\n%s", code, res.Err, ExitPartial, res.Stdout)
+			}
+			for _, want := range tc.summary {
+				if !strings.Contains(res.Stdout, want) {
+					t.Errorf("summary lacks %q:\n%s", want, res.Stdout)
+				}
+			}
+			// The last monitors are left alone
+			for _, monitor := range monitors[4:] {
+				for _, method := range []string{"PUT", "DELETE"} {
+					srv.AssertRequestCount(t, 0, method, "/monitor/"+strconv.Itoa(monitor.ID))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export monitors to Terraform, JSON or templates",
+	Long: `Export the selected monitors in another format, e.g. to adopt monitors
+created by this tool into Terraform.
+
+With --format terraform, each monitor becomes a datadog_monitor resource named
+after the monitor (lowercase, non-alphanumeric runs replaced by '_', with a
+numeric suffix for duplicates). Options are mapped to their provider attributes
+(thresholds to the monitor_thresholds block, notify_no_data, renotify_interval,
+...); options the provider has no attribute for are kept as comments.
+
+The HCL goes to stdout or --file. The matching "terraform import" commands go
+to --imports-file, or to stderr so that redirecting stdout gives a clean .tf file.
+
+With --format json, the monitors are written as a JSON array, as returned by
+the API.
+
+With --format template, the monitors are written as a template file that
+recreates them. Log alert and service check queries are decompiled into "log"
+and "service_check" blocks when the blocks can express them; the managed-by,
+template-id and ddmm-fingerprint tags are left out.
+
+Examples:
+  export --service myapp --env prd > monitors.tf
+  export --service myapp --file monitors.tf --imports-file import.sh
+  export --filter-tags team:payments --format json
+  export --service myapp --format template --file templates/myapp.json`,
+	RunE: runExport,
+}
+
+var (
+	exportFormat      string
+	exportFile        string
+	exportImportsFile string
+	exportService     string
+	exportEnv         string
+	exportNamespace   string
+	exportFilterTags  string
+	exportQuery       string
+)
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVar(&exportFormat, "format", "terraform", "Export format: terraform, json or template")
+	exportCmd.Flags().StringVarP(&exportFile, "file", "f", "", "Write the export to this file instead of stdout")
+	exportCmd.Flags().StringVar(&exportImportsFile, "imports-file", "", "Write the terraform import commands to this file instead of stderr")
+	exportCmd.Flags().StringVar(&exportService, "service", "", "Filter by service")
+	exportCmd.Flags().StringVar(&exportEnv, "env", "", "Filter by environment")
+	exportCmd.Flags().StringVar(&exportNamespace, "namespace", "", "Filter by namespace")
+	exportCmd.Flags().StringVar(&exportFilterTags, "filter-tags", "", "Filter by tags (comma-separated)")
+	exportCmd.Flags().StringVar(&exportQuery, "query", "", "Complex search query (e.g., service:(service1 OR service2))")
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	if exportFormat != "terraform" && exportFormat != "json" && exportFormat != "template" {
+		return fmt.Errorf("invalid --format %q (must be terraform, json or template)", exportFormat)
+	}
+	if exportImportsFile != "" && exportFormat != "terraform" {
+		return fmt.Errorf("--imports-file can only be used with --format terraform")
+	}
+
+	selector := monitorSelector{
+		Query:     exportQuery,
+		Service:   exportService,
+		Env:       exportEnv,
+		Namespace: exportNamespace,
+		Tags:      splitCommaList(exportFilterTags),
+		Cacheable: true,
+	}
+	if err := selector.validate(); err != nil {
+		return err
+	}
+	if !selector.hasFilters() {
+		return fmt.Errorf("at least one filter is required (--service, --env, --namespace, --filter-tags or --query)")
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	monitors, err := fetchMonitors(client, selector)
+	if err != nil {
+		errOut.Printf("❌ Error listing monitors: %v\n", err)
+		return err
+	}
+	if len(monitors) == 0 {
+		errOut.Println("ℹ️  No monitors found")
+		return nil
+	}
+
+	var output string
+	var imports []string
+	switch exportFormat {
+	case "json":
+		jsonData, err := json.MarshalIndent(monitors, "", "  ")
+		if err != nil {
+			return err
+		}
+		output = string(jsonData) + "\n"
+	case "template":
+		jsonData, err := json.MarshalIndent(datadog.ExportTemplates(monitors), "", "  ")
+		if err != nil {
+			return err
+		}
+		output = string(jsonData) + "\n"
+	default:
+		export := datadog.ExportTerraform(monitors)
+		output, imports = export.HCL, export.Imports
+	}
+
+	if exportFile == "" {
+		fmt.Print(output)
+	} else {
+		if err := os.WriteFile(exportFile, []byte(output), 0644); err != nil {
+			errOut.Printf("❌ Error writing %s: %v\n", exportFile, err)
+			return err
+		}
+		errOut.Printf("✅ Exported %d monitor(s) to %s\n", len(monitors), exportFile)
+	}
+
+	if exportFormat != "terraform" {
+		return nil
+	}
+	script := strings.Join(imports, "\n") + "\n"
+	if exportImportsFile != "" {
+		if err := os.WriteFile(exportImportsFile, []byte("#!/bin/sh\nset -e\n"+script), 0755); err != nil {
+			errOut.Printf("❌ Error writing %s: %v\n", exportImportsFile, err)
+			return err
+		}
+		errOut.Printf("✅ Wrote %d import command(s) to %s\n", len(imports), exportImportsFile)
+		return nil
+	}
+	errOut.Printf("\n📥 Import the monitors into Terraform state with:\n%s", script)
+	return nil
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+// monitorFormatPreset is a named --format-preset: a Go template executed per
+// monitor, with an optional header printed once before the first monitor
+type monitorFormatPreset struct {
+	Header string
+	Row    string
+}
+
+// monitorFormatPresets are the built-in --format-preset formats
+var monitorFormatPresets = map[string]monitorFormatPreset{
+	"slack": {
+		Row: `• <{{.URL}}|{{slackescape .Name}}> ({{or .OverallState "OK"}})`,
+	},
+	"markdown-table": {
+		Header: "| ID | Name | State | Tags |\n|---|---|---|---|",
+		Row:    `| [{{.ID}}]({{.URL}}) | {{mdescape .Name}} | {{or .OverallState "OK"}} | {{mdescape (join ", " .Tags)}} |`,
+	},
+}
+
+// monitorFormatPresetNames returns the names accepted by --format-preset, sorted
+func monitorFormatPresetNames() []string {
+	names := make([]string, 0, len(monitorFormatPresets))
+	for name := range monitorFormatPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// monitorFormatFuncs are the helper functions of --format templates
+var monitorFormatFuncs = template.FuncMap{
+	// join joins a list: {{join ", " .Tags}} or {{.Tags | join ","}}
+	"join": func(sep string, items []string) string { return strings.Join(items, sep) },
+	// tagvalue returns the value of the first tag with a key: {{tagvalue "env" .Tags}}
+	"tagvalue": func(key string, tags []string) string {
+		for _, tag := range tags {
+			if value, ok := strings.CutPrefix(tag, key+":"); ok {
+				return value
+			}
+		}
+		return ""
+	},
+	// truncate cuts a string to n characters, ending it with "…": {{.Name | truncate 40}}
+	"truncate": func(n int, s string) string {
+		if n <= 0 || utf8.RuneCountInString(s) <= n {
+			return s
+		}
+		return string([]rune(s)[:n-1]) + "…"
+	},
+	// mdescape escapes the characters that break a Markdown table cell
+	"mdescape": markdownCellEscape,
+	// slackescape escapes the characters Slack's mrkdwn gives a meaning to
+	"slackescape": func(s string) string {
+		return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+	},
+}
+
+// markdownCellEscape escapes the characters that break a Markdown table cell
+func markdownCellEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+// monitorFormatData is what a --format template is executed with: the
+// monitor's fields, plus its URL in the Datadog app
+type monitorFormatData struct {
+	datadog.Monitor
+	URL string
+}
+
+// monitorFormatter prints monitors with a --format template or preset
+type monitorFormatter struct {
+	header string
+	row    *template.Template
+	// appURL is the Datadog app URL monitor links are built from, set once
+	// the client exists
+	appURL string
+}
+
+// newMonitorFormatter parses --format or --format-preset; it returns nil when
+// neither is set. Parsing happens before any API call so a broken template
+// fails fast. In --format, the sequences \t and \n stand for a tab and a newline.
+func newMonitorFormatter(format, preset string) (*monitorFormatter, error) {
+	if format != "" && preset != "" {
+		return nil, fmt.Errorf("--format and --format-preset are mutually exclusive")
+	}
+	var header string
+	switch {
+	case preset != "":
+		p, ok := monitorFormatPresets[preset]
+		if !ok {
+			return nil, fmt.Errorf("unknown --format-preset %q (must be one of: %s)", preset, strings.Join(monitorFormatPresetNames(), ", "))
+		}
+		header, format = p.Header, p.Row
+	case format != "":
+		format = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(format)
+	default:
+		return nil, nil
+	}
+
+	row, err := template.New("format").Funcs(monitorFormatFuncs).Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --format template: %w", err)
+	}
+	// Unknown fields only show when the template runs: try it on an empty monitor
+	if err := row.Execute(io.Discard, monitorFormatData{}); err != nil {
+		return nil, fmt.Errorf("invalid --format template: %w", err)
+	}
+	return &monitorFormatter{header: header, row: row}, nil
+}
+
+// print writes the header and one line per monitor
+func (f *monitorFormatter) print(w io.Writer, monitors []datadog.Monitor) error {
+	if f.header != "" {
+		fmt.Fprintln(w, f.header)
+	}
+	var buf bytes.Buffer
+	for _, monitor := range monitors {
+		buf.Reset()
+		data := monitorFormatData{Monitor: monitor}
+		if f.appURL != "" {
+			data.URL = fmt.Sprintf("%s/monitors/%d", f.appURL, monitor.ID)
+		}
+		if err := f.row.Execute(&buf, data); err != nil {
+			return fmt.Errorf("executing --format template for monitor %d: %w", monitor.ID, err)
+		}
+		if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
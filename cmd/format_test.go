@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+// formatMonitors prints monitors with --format or --format-preset, linking
+// to app.datadoghq.com
+func formatMonitors(t *testing.T, format, preset string, monitors ...datadog.Monitor) string {
+	t.Helper()
+	formatter, err := newMonitorFormatter(format, preset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	formatter.appURL = "https://app.datadoghq.com"
+	var buf bytes.Buffer
+	if err := formatter.print(&buf, monitors); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestFormatPresetSlack(t *testing.T) {
+	got := formatMonitors(t, "", "slack",
+		datadog.Monitor{ID: 1, Name: "CPU high", OverallState: "Alert"},
+		// Slack's control characters are escaped; no state reads as OK
+		datadog.Monitor{ID: 2, Name: "p99 > 2s & <api>"},
+	)
+	want := "• <https://app.datadoghq.com/monitors/1|CPU high> (Alert)\n" +
+		"• <https://app.datadoghq.com/monitors/2|p99 &gt; 2s &amp; &lt;api&gt;> (OK)\n"
+	if got != want {
+		t.Errorf("slack preset:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatPresetMarkdownTable(t *testing.T) {
+	got := formatMonitors(t, "", "markdown-table",
+		datadog.Monitor{ID: 1, Name: "CPU high", OverallState: "Warn", Tags: []string{"env:prd", "team:sre"}},
+		// Pipes and newlines would break the table
+		datadog.Monitor{ID: 2, Name: "a|b\nc", Tags: []string{"expr:a|b"}},
+	)
+	want := "| ID | Name | State | Tags |\n" +
+		"|---|---|---|---|\n" +
+		"| [1](https://app.datadoghq.com/monitors/1) | CPU high | Warn | env:prd, team:sre |\n" +
+		`| [2](https://app.datadoghq.com/monitors/2) | a\|b c | OK | expr:a\|b |` + "\n"
+	if got != want {
+		t.Errorf("markdown-table preset:\n%s\nwant:\n%s", got, want)
+	}
+
+	// The header is printed even without monitors
+	if got := formatMonitors(t, "", "markdown-table"); got != "| ID | Name | State | Tags |\n|---|---|---|---|\n" {
+		t.Errorf("markdown-table preset without monitors:\n%s", got)
+	}
+}
+
+func TestFormatTemplate(t *testing.T) {
+	monitor := datadog.Monitor{ID: 7, Name: "Checkout error rate above threshold", OverallState: "OK", Tags: []string{"env:prd", "team:sre", "env:stg"}}
+	for _, tc := range []struct {
+		format string
+		want   string
+	}{
+		{`{{.ID}}\t{{.Name}}\t{{.OverallState}}`, "7\tCheckout error rate above threshold\tOK\n"},
+		{`{{join "," .Tags}}`, "env:prd,team:sre,env:stg\n"},
+		{`{{.Tags | join " "}}`, "env:prd team:sre env:stg\n"},
+		// The first tag of the key
+		{`{{tagvalue "env" .Tags}}/{{tagvalue "owner" .Tags}}`, "prd/\n"},
+		{`{{.Name | truncate 8}}`, "Checkou…\n"},
+		{`{{.Name | truncate 100}}|{{truncate 0 .Name}}`, "Checkout error rate above threshold|Checkout error rate above threshold\n"},
+		// A trailing newline isn't doubled
+		{`{{.ID}}\n`, "7\n"},
+		{`{{.URL}}`, "https://app.datadoghq.com/monitors/7\n"},
+	} {
+		if got := formatMonitors(t, tc.format, "", monitor); got != tc.want {
+			t.Errorf("--format %s = %q, want %q", tc.format, got, tc.want)
+		}
+	}
+}
+
+func TestFormatErrorsBeforeAPICalls(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddMonitor(datadog.Monitor{Name: "cpu", Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90", Tags: []string{"env:prd"}})
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"list", "--format", "{{.Name"}, "invalid --format template"},
+		{[]string{"list", "--format", "{{.Owner}}"}, "invalid --format template"},
+		{[]string{"list", "--format", "{{upper .Name}}"}, "invalid --format template"},
+		{[]string{"describe", "--monitor-id", "1000", "--format", "{{.Nope}}"}, "invalid --format template"},
+		{[]string{"list", "--format-preset", "html"}, `unknown --format-preset "html" (must be one of: markdown-table, slack)`},
+		{[]string{"list", "--format", "{{.ID}}", "--format-preset", "slack"}, "mutually exclusive"},
+	} {
+		res := runCLI(t, nil, tc.args...)
+		if res.Err == nil || !strings.Contains(res.Err.Error(), tc.want) {
+			t.Errorf("%s: %v, want %q", strings.Join(tc.args, " "), res.Err, tc.want)
+		}
+	}
+	if reqs := srv.Requests(); len(reqs) != 0 {
+		t.Errorf("%d request(s) with invalid formats: %v", len(reqs), reqs)
+	}
+
+	res := runCLI(t, nil, "list", "--env", "prd", "--format-preset", "slack")
+	if res.Err != nil {
+		t.Fatalf("list --format-preset slack: %v\n%s", res.Err, res.Stderr)
+	}
+	if !strings.HasPrefix(res.Stdout, "• <") || !strings.HasSuffix(res.Stdout, "/monitors/1000|cpu> (OK)\n") {
+		t.Errorf("list --format-preset slack:\n%s", res.Stdout)
+	}
+}
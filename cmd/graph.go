@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Show which monitors composite monitors depend on",
+	Long: `Print the dependency tree of composite monitors: each composite with the
+monitors its query references indented under it, nested composites expanded
+in turn. Referenced monitors outside the filters are fetched as well; those
+that no longer exist are flagged, and so are reference cycles.
+
+Without filter flags, every monitor is included. --format dot prints the
+graph in Graphviz format instead.
+
+Examples:
+  graph
+  graph --service checkout --env prd
+  graph --format dot | dot -Tsvg > composites.svg`,
+	RunE: runGraph,
+}
+
+var (
+	graphService    string
+	graphEnv        string
+	graphNamespace  string
+	graphFilterTags string
+	graphQuery      string
+	graphFormat     string
+)
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+	graphCmd.Flags().StringVar(&graphService, "service", "", "Filter by service")
+	graphCmd.Flags().StringVar(&graphEnv, "env", "", "Filter by environment")
+	graphCmd.Flags().StringVar(&graphNamespace, "namespace", "", "Filter by namespace")
+	graphCmd.Flags().StringVar(&graphFilterTags, "filter-tags", "", "Filter by tags (comma-separated)")
+	graphCmd.Flags().StringVar(&graphQuery, "query", "", "Complex search query (e.g., service:(service1 OR service2))")
+	graphCmd.Flags().StringVar(&graphFormat, "format", "tree", "Output format: tree or dot (Graphviz)")
+}
+
+func runGraph(cmd *cobra.Command, args []string) error {
+	if graphFormat != "tree" && graphFormat != "dot" {
+		return fmt.Errorf("invalid --format %q (must be tree or dot)", graphFormat)
+	}
+	selector := monitorSelector{
+		Query:     graphQuery,
+		Service:   graphService,
+		Env:       graphEnv,
+		Namespace: graphNamespace,
+		Tags:      splitCommaList(graphFilterTags),
+		Cacheable: true,
+	}
+	if err := selector.validate(); err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	monitors, err := fetchMonitors(client, selector)
+	if err != nil {
+		errOut.Printf("❌ Error listing monitors: %v\n", err)
+		return err
+	}
+
+	// Fetch the referenced monitors the filters left out, level by level
+	graph := datadog.NewDependencyGraph(monitors)
+	notFound := make(map[int]bool)
+	for {
+		var missing []int
+		for _, id := range graph.MissingRefs() {
+			if !notFound[id] {
+				missing = append(missing, id)
+			}
+		}
+		if len(missing) == 0 {
+			break
+		}
+		logVerbose("fetching %d referenced monitor(s) outside the filters", len(missing))
+		fetched, failures := client.GetMonitors(missing, nil, 0, nil)
+		for id, err := range failures {
+			if isInterrupted(err) {
+				return interruption(err)
+			}
+			if !datadog.IsMonitorNotFound(err) {
+				errOut.Printf("❌ Error getting monitor %d: %v\n", id, err)
+				return err
+			}
+			notFound[id] = true
+		}
+		monitors = append(monitors, fetched...)
+		graph = datadog.NewDependencyGraph(monitors)
+	}
+
+	if graphFormat == "dot" {
+		printGraphDot(graph)
+		return nil
+	}
+	printGraphTree(graph)
+	return nil
+}
+
+// printGraphTree prints the composites as trees of the monitors they reference
+func printGraphTree(graph *datadog.DependencyGraph) {
+	roots := graph.Roots()
+	out.Printf("🕸️  Composite dependency graph\n")
+	out.Println(strings.Repeat("=", 80))
+	if len(roots) == 0 {
+		out.Println("ℹ️  No composite monitors found")
+		return
+	}
+
+	for i, id := range roots {
+		if i > 0 {
+			out.Println()
+		}
+		out.Println(graphNodeLabel(graph, id))
+		printGraphChildren(graph, id, "", map[int]bool{id: true})
+	}
+
+	out.Println(strings.Repeat("=", 80))
+	referenced := make(map[int]bool)
+	for _, children := range graph.Children {
+		for _, id := range children {
+			referenced[id] = true
+		}
+	}
+	out.Printf("📊 %d composite(s) referencing %d monitor(s)\n", len(graph.Children), len(referenced))
+	if missing := graph.MissingRefs(); len(missing) > 0 {
+		out.Printf("❓ %d referenced monitor(s) not found: %s\n", len(missing), joinIDs(missing, ", "))
+	}
+	for _, cycle := range graph.Cycles() {
+		out.Printf("🔁 Cycle: %s -> %d\n", joinIDs(cycle, " -> "), cycle[0])
+	}
+}
+
+// printGraphChildren prints the monitors a composite references under it;
+// path holds the composites above, to stop at cycles
+func printGraphChildren(graph *datadog.DependencyGraph, id int, indent string, path map[int]bool) {
+	children := graph.Children[id]
+	for i, child := range children {
+		branch, next := "├── ", "│   "
+		if i == len(children)-1 {
+			branch, next = "└── ", "    "
+		}
+		if path[child] {
+			out.Printf("%s%s🔁 ID %d (cycle)\n", indent, branch, child)
+			continue
+		}
+		out.Printf("%s%s%s\n", indent, branch, graphNodeLabel(graph, child))
+		if _, ok := graph.Children[child]; ok {
+			path[child] = true
+			printGraphChildren(graph, child, indent+next, path)
+			delete(path, child)
+		}
+	}
+}
+
+// graphNodeLabel describes a monitor of the graph on one line
+func graphNodeLabel(graph *datadog.DependencyGraph, id int) string {
+	monitor, ok := graph.Monitors[id]
+	if !ok {
+		return fmt.Sprintf("❓ ID %d (not found)", id)
+	}
+	state := monitor.OverallState
+	if state == "" {
+		state = "OK"
+	}
+	icon := ""
+	if monitor.Type == datadog.CompositeType {
+		icon = "🧩 "
+	}
+	return fmt.Sprintf("%sID %d: %s [%s]", icon, monitor.ID, monitor.Name, state)
+}
+
+// printGraphDot prints the composites and the monitors they reference in
+// Graphviz format; edges on a cycle are red
+func printGraphDot(graph *datadog.DependencyGraph) {
+	onCycle := make(map[[2]int]bool)
+	for _, cycle := range graph.Cycles() {
+		for i, id := range cycle {
+			onCycle[[2]int{id, cycle[(i+1)%len(cycle)]}] = true
+		}
+	}
+
+	ids := make([]int, 0, len(graph.Children))
+	nodes := make(map[int]bool)
+	for id, children := range graph.Children {
+		ids = append(ids, id)
+		nodes[id] = true
+		for _, child := range children {
+			nodes[child] = true
+		}
+	}
+	sort.Ints(ids)
+	nodeIDs := make([]int, 0, len(nodes))
+	for id := range nodes {
+		nodeIDs = append(nodeIDs, id)
+	}
+	sort.Ints(nodeIDs)
+
+	fmt.Println("digraph monitors {")
+	fmt.Println("  rankdir=LR;")
+	fmt.Println("  node [shape=box];")
+	for _, id := range nodeIDs {
+		monitor, ok := graph.Monitors[id]
+		switch {
+		case !ok:
+			fmt.Printf("  m%d [label=%s, style=dashed];\n", id, dotQuote(fmt.Sprintf("%d (not found)", id)))
+		case monitor.Type == datadog.CompositeType:
+			fmt.Printf("  m%d [label=%s, style=bold];\n", id, dotQuote(fmt.Sprintf("%d: %s", id, monitor.Name)))
+		default:
+			fmt.Printf("  m%d [label=%s];\n", id, dotQuote(fmt.Sprintf("%d: %s", id, monitor.Name)))
+		}
+	}
+	for _, id := range ids {
+		for _, child := range graph.Children[id] {
+			if onCycle[[2]int{id, child}] {
+				fmt.Printf("  m%d -> m%d [color=red];\n", id, child)
+			} else {
+				fmt.Printf("  m%d -> m%d;\n", id, child)
+			}
+		}
+	}
+	fmt.Println("}")
+}
+
+// dotQuote quotes a Graphviz string
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// joinIDs joins monitor IDs with sep
+func joinIDs(ids []int, sep string) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, sep)
+}
+
+// warnCompositeReferences warns about monitors about to be deleted that
+// composites outside the deletion still reference. Failing to load the
+// references is only logged: the warning must not block a deletion.
+func warnCompositeReferences(client *datadog.Client, monitors []datadog.Monitor) {
+	index, err := client.CompositeReferences()
+	if err != nil {
+		logVerbose("could not check composite references: %v", err)
+		return
+	}
+
+	deleting := make(map[int]bool, len(monitors))
+	for _, monitor := range monitors {
+		deleting[monitor.ID] = true
+	}
+	warned := 0
+	for _, monitor := range monitors {
+		for _, composite := range index.ReferencedBy(monitor.ID) {
+			if deleting[composite.ID] {
+				continue
+			}
+			if warned == 0 {
+				out.Println("\n⚠️  Composite monitors reference monitors about to be deleted:")
+			}
+			warned++
+			out.Printf("   ⚠️  ID %d: %s is used by composite ID %d: %s\n", monitor.ID, monitor.Name, composite.ID, composite.Name)
+		}
+	}
+	if warned > 0 {
+		out.Println("💡 Those composites will stop evaluating; check them with: datadog-monitor-manager graph")
+	}
+}
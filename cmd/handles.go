@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var handlesCmd = &cobra.Command{
+	Use:   "handles",
+	Short: "Notification handles referenced by monitors",
+	Long:  `Report on the notification handles (@slack-..., @pagerduty-..., emails) monitors notify`,
+}
+
+var handlesReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Show which monitors notify each handle",
+	Long: `Scan the message and escalation message of monitors for notification
+handles (@slack-*, @pagerduty-*, @teams-*, @webhook-* and @user@domain) and
+show each handle with the number of monitors using it.
+
+With --find, only the monitors referencing that handle are listed, e.g. before
+deleting a Slack channel. Replace the handle afterwards with edit-message.
+
+Examples:
+  handles report
+  handles report --env prd
+  handles report --find @slack-old-team
+  handles report --output json`,
+	RunE: runHandlesReport,
+}
+
+var (
+	handlesReportFind       string
+	handlesReportOutput     string
+	handlesReportService    string
+	handlesReportEnv        string
+	handlesReportNamespace  string
+	handlesReportFilterTags string
+	handlesReportQuery      string
+)
+
+func init() {
+	rootCmd.AddCommand(handlesCmd)
+	handlesCmd.AddCommand(handlesReportCmd)
+	handlesReportCmd.Flags().StringVar(&handlesReportFind, "find", "", "Only list the monitors referencing this handle (e.g., @slack-old-team)")
+	handlesReportCmd.Flags().StringVarP(&handlesReportOutput, "output", "o", "table", "Output format: table or json")
+	handlesReportCmd.Flags().StringVar(&handlesReportService, "service", "", "Filter by service")
+	handlesReportCmd.Flags().StringVar(&handlesReportEnv, "env", "", "Filter by environment")
+	handlesReportCmd.Flags().StringVar(&handlesReportNamespace, "namespace", "", "Filter by namespace")
+	handlesReportCmd.Flags().StringVar(&handlesReportFilterTags, "filter-tags", "", "Filter by tags (comma-separated)")
+	handlesReportCmd.Flags().StringVar(&handlesReportQuery, "query", "", "Complex search query (e.g., service:(service1 OR service2))")
+}
+
+func runHandlesReport(cmd *cobra.Command, args []string) error {
+	if handlesReportOutput != "table" && handlesReportOutput != "json" {
+		return fmt.Errorf("invalid --output %q (must be table or json)", handlesReportOutput)
+	}
+	find := datadog.NormalizeHandle(handlesReportFind)
+
+	selector := monitorSelector{
+		Query:     handlesReportQuery,
+		Service:   handlesReportService,
+		Env:       handlesReportEnv,
+		Namespace: handlesReportNamespace,
+		Tags:      splitCommaList(handlesReportFilterTags),
+		Cacheable: true,
+	}
+	if err := selector.validate(); err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	monitors, err := fetchMonitors(client, selector)
+	if err != nil {
+		errOut.Printf("❌ Error listing monitors: %v\n", err)
+		return err
+	}
+
+	report := datadog.BuildHandleReport(monitors)
+	if find != "" {
+		var found []datadog.HandleUsage
+		for _, usage := range report {
+			if strings.EqualFold(usage.Handle, find) {
+				found = append(found, usage)
+			}
+		}
+		report = found
+	} else if handlesReportOutput == "table" {
+		// The monitor lists are only part of --find
+		for i := range report {
+			report[i].Monitors = nil
+		}
+	}
+
+	if handlesReportOutput == "json" {
+		if report == nil {
+			report = []datadog.HandleUsage{}
+		}
+		jsonData, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	if find != "" {
+		if len(report) == 0 {
+			out.Printf("ℹ️  No monitor references %s\n", find)
+			return nil
+		}
+		printHandleMonitors(report[0])
+		return nil
+	}
+
+	if len(report) == 0 {
+		out.Println("ℹ️  No notification handles found")
+		return nil
+	}
+	printHandleReport(report, len(monitors))
+	return nil
+}
+
+// printHandleReport prints each handle with the number of monitors using it
+func printHandleReport(report []datadog.HandleUsage, scanned int) {
+	out.Printf("\n📣 Notification handles in %d monitor(s):\n", scanned)
+	out.Println(strings.Repeat("=", 80))
+	out.Printf("%8s  %s\n", "MONITORS", "HANDLE")
+	for _, usage := range report {
+		out.Printf("%8d  %s\n", usage.Count, usage.Handle)
+	}
+	out.Printf("\n📊 %d handle(s)\n", len(report))
+	out.Println("💡 List the monitors of a handle with --find @handle")
+}
+
+// printHandleMonitors prints the monitors referencing one handle, with a
+// hint on replacing it
+func printHandleMonitors(usage datadog.HandleUsage) {
+	out.Printf("\n📣 %d monitor(s) reference %s:\n", usage.Count, usage.Handle)
+	out.Println(strings.Repeat("=", 80))
+	for _, monitor := range usage.Monitors {
+		out.Printf("   ID %d: %s [%s]\n", monitor.ID, monitor.Name, monitor.State)
+	}
+	out.Printf("\n💡 Replace it with edit-message and the same filters: --replace \"%s=@new-handle\" (monitors without it are left unchanged)\n", usage.Handle)
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadogtest"
+)
+
+// newTestServer starts a fake Datadog API the commands of the test talk to,
+// with config and cache directories of their own
+func newTestServer(t *testing.T) *datadogtest.Server {
+	t.Helper()
+	srv := datadogtest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.Setenv(t)
+	t.Setenv("DDMM_CONFIG_DIR", t.TempDir())
+	t.Setenv("DDMM_CACHE_DIR", t.TempDir())
+	return srv
+}
+
+// writeConfig writes the config file of the config directory newTestServer
+// set up
+func writeConfig(t *testing.T, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(os.Getenv("DDMM_CONFIG_DIR"), "config.yaml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// cliResult is the outcome of a command line run by runCLI
+type cliResult struct {
+	Stdout string
+	Stderr string
+	Err    error
+}
+
+// runCLI runs a command line as Execute does, in ctx (background when nil),
+// capturing stdout and stderr. The flags of every command are reset first, so
+// each run starts from the defaults.
+func runCLI(t *testing.T, ctx context.Context, args ...string) cliResult {
+	t.Helper()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	resetCommandState()
+
+	var res cliResult
+	res.Stdout, res.Stderr = captureOutput(t, func() {
+		rootCmd.SetArgs(args)
+		res.Err = executeContext(ctx)
+	})
+	return res
+}
+
+// captureOutput runs fn and returns what it wrote to stdout and stderr
+func captureOutput(t *testing.T, fn func()) (stdout, stderr string) {
+	t.Helper()
+	outFile, errFile := captureFile(t), captureFile(t)
+	savedStdout, savedStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = outFile, errFile
+	defer func() { os.Stdout, os.Stderr = savedStdout, savedStderr }()
+	fn()
+	return readCapture(t, outFile), readCapture(t, errFile)
+}
+
+// resetCommandState resets the flags of every command to their defaults and
+// the state an invocation leaves behind
+func resetCommandState() {
+	var reset func(cmd *cobra.Command)
+	reset = func(cmd *cobra.Command) {
+		for _, flags := range []*pflag.FlagSet{cmd.Flags(), cmd.PersistentFlags()} {
+			flags.VisitAll(func(f *pflag.Flag) {
+				if slice, ok := f.Value.(pflag.SliceValue); ok {
+					slice.Replace(nil)
+				} else {
+					f.Value.Set(f.DefValue)
+				}
+				f.Changed = false
+			})
+		}
+		// Cobra keeps the context of an earlier run for subcommands that have one
+		cmd.SetContext(nil)
+		for _, sub := range cmd.Commands() {
+			reset(sub)
+		}
+	}
+	reset(rootCmd)
+
+	protectedSkipped = nil
+	runStats = nil
+	runCtx = context.Background()
+	cancelTimeout = nil
+	envConflictsWarned = false
+	loadedCfg = nil
+}
+
+// captureFile returns a file standing in for stdout or stderr
+func captureFile(t *testing.T) *os.File {
+	t.Helper()
+	f, err := os.CreateTemp(t.TempDir(), "output-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+// readCapture returns what was written to a capture file
+func readCapture(t *testing.T, f *os.File) string {
+	t.Helper()
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// writeServiceSpec writes a service spec for checkout/prd/shop applying a
+// template file with two monitors, and returns its path
+func writeServiceSpec(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	template := `{
+  "templates": [
+    {
+      "name": "Error Rate",
+      "config": {
+        "name": "Monitor {service} - Error Rate",
+        "type": "query alert",
+        "query": "sum(last_5m):sum:http.errors{service:{service},env:{env}}.as_count() > 10",
+        "message": "Error rate too high",
+        "options": {"thresholds": {"critical": 10}}
+      }
+    },
+    {
+      "name": "Latency",
+      "config": {
+        "name": "Monitor {service} - Latency",
+        "type": "query alert",
+        "query": "avg(last_5m):avg:http.request.duration{service:{service},env:{env}} > 2",
+        "message": "Latency too high",
+        "options": {"thresholds": {"critical": 2}}
+      }
+    }
+  ]
+}
+`
+	spec := `service: checkout
+env: prd
+namespace: shop
+templates:
+  - file: monitors.json
+`
+	if err := os.WriteFile(filepath.Join(dir, "monitors.json"), []byte(template), 0644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "service.yaml")
+	if err := os.WriteFile(path, []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
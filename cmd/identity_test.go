@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+// editTemplateFile replaces the first occurrence of from in the template file
+// of a spec written by writeServiceSpec
+func editTemplateFile(t *testing.T, spec, from, to string) {
+	t.Helper()
+	path := filepath.Join(filepath.Dir(spec), "monitors.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), from) {
+		t.Fatalf("template has no %q", from)
+	}
+	if err := os.WriteFile(path, []byte(strings.Replace(string(data), from, to, 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApplyRenameUpdatesInPlace(t *testing.T) {
+	srv := newTestServer(t)
+	spec := writeServiceSpec(t)
+	if res := runCLI(t, nil, "apply", "-f", spec); res.Err != nil {
+		t.Fatalf("apply: %v\n%s", res.Err, res.Stderr)
+	}
+
+	editTemplateFile(t, spec, "Monitor {service} - Error Rate", "[{env}] {service} errors")
+	srv.ResetRequests()
+	res := runCLI(t, nil, "apply", "-f", spec)
+	if res.Err != nil {
+		t.Fatalf("apply: %v\n%s", res.Err, res.Stderr)
+	}
+	srv.AssertRequestCount(t, 0, "POST", "/monitor")
+	srv.AssertRequestCount(t, 1, "PUT", "/monitor/1000")
+	if got := len(srv.Monitors()); got != 2 {
+		t.Errorf("%d monitors after the rename, want 2", got)
+	}
+	if m, _ := srv.Monitor(1000); m.Name != "[prd] checkout errors" {
+		t.Errorf("renamed monitor has name %q", m.Name)
+	}
+}
+
+func TestApplyMigratesNameMatchedMonitors(t *testing.T) {
+	srv := newTestServer(t)
+	// A monitor created before template identities existed
+	srv.AddMonitor(datadog.Monitor{
+		Name:    "Monitor checkout - Error Rate",
+		Type:    "query alert",
+		Query:   "sum(last_5m):sum:http.errors{service:checkout,env:prd}.as_count() > 10",
+		Message: "Error rate too high",
+		Tags:    []string{"service:checkout", "env:prd", "namespace:shop", "managed-by:ddmm"},
+	})
+	spec := writeServiceSpec(t)
+
+	res := runCLI(t, nil, "apply", "-f", spec)
+	if res.Err != nil {
+		t.Fatalf("apply: %v\n%s", res.Err, res.Stderr)
+	}
+	srv.AssertRequestCount(t, 1, "PUT", "/monitor/1000")
+	srv.AssertRequestCount(t, 1, "POST", "/monitor")
+	m, _ := srv.Monitor(1000)
+	if !slices.Contains(m.Tags, "template-id:monitors-error-rate") {
+		t.Fatalf("name-matched monitor was not given its identity: %v", m.Tags)
+	}
+
+	// Once migrated, a rename finds the monitor by its identity
+	editTemplateFile(t, spec, "Monitor {service} - Error Rate", "Monitor {service} - Errors")
+	srv.ResetRequests()
+	if res := runCLI(t, nil, "apply", "-f", spec); res.Err != nil {
+		t.Fatalf("apply: %v\n%s", res.Err, res.Stderr)
+	}
+	srv.AssertRequestCount(t, 0, "POST", "/monitor")
+	if m, _ := srv.Monitor(1000); m.Name != "Monitor checkout - Errors" {
+		t.Errorf("migrated monitor has name %q", m.Name)
+	}
+}
+
+func TestApplyDuplicateIdentitiesFallBackToName(t *testing.T) {
+	srv := newTestServer(t)
+	identity := []string{"service:checkout", "env:prd", "namespace:shop", "managed-by:ddmm", "template-id:monitors-error-rate"}
+	for _, name := range []string{"Copy A", "Copy B", "Monitor checkout - Error Rate"} {
+		srv.AddMonitor(datadog.Monitor{
+			Name:  name,
+			Type:  "query alert",
+			Query: "sum(last_5m):sum:http.errors{service:checkout,env:prd}.as_count() > 10",
+			Tags:  slices.Clone(identity),
+		})
+	}
+	spec := writeServiceSpec(t)
+
+	// Of the monitors sharing the identity, the one with the name is updated
+	if res := runCLI(t, nil, "apply", "-f", spec); res.Err != nil {
+		t.Fatalf("apply: %v\n%s", res.Err, res.Stderr)
+	}
+	srv.AssertRequestCount(t, 1, "PUT", "/monitor/1002")
+	srv.AssertRequestCount(t, 0, "PUT", "/monitor/1000")
+	srv.AssertRequestCount(t, 0, "PUT", "/monitor/1001")
+
+	// Without a monitor of that name, no copy is picked: the name matches
+	// nothing either, so a new monitor is created (the latency monitor is up
+	// to date)
+	editTemplateFile(t, spec, "Monitor {service} - Error Rate", "Monitor {service} - Errors")
+	srv.ResetRequests()
+	if res := runCLI(t, nil, "apply", "-f", spec); res.Err != nil {
+		t.Fatalf("apply: %v\n%s", res.Err, res.Stderr)
+	}
+	for _, id := range []string{"1000", "1001", "1002"} {
+		srv.AssertRequestCount(t, 0, "PUT", "/monitor/"+id)
+	}
+	srv.AssertRequestCount(t, 1, "POST", "/monitor")
+}
@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/prometheus"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Convert monitors from other alerting systems into templates",
+	Long: `Convert alert definitions from other alerting systems into monitor templates.
+
+Examples:
+  import prometheus --file rules.yaml --mapping prom-mapping.yaml`,
+}
+
+var importPrometheusCmd = &cobra.Command{
+	Use:   "prometheus",
+	Short: "Convert Prometheus alerting rules into monitor templates",
+	Long: `Convert the alerting rules of a Prometheus rules file, or of PrometheusRule
+resources, into query alert templates.
+
+Each alert's PromQL expression becomes the closest Datadog metric query:
+  - metric names are translated with the mapping file ("metrics:"), or with
+    --metric-prefix (<prefix>.<metric>, as collected by the OpenMetrics check)
+  - label matchers become the scope, labels in by (...) the group-by; label
+    names are translated with the mapping file ("labels:"), namespace, pod and
+    container map to the Agent's Kubernetes tags by default
+  - sum/avg/min/max aggregate the query, rate()/irate() become .as_rate() and
+    increase() .as_count()
+  - the threshold comparison becomes the critical threshold, and "for:" the
+    evaluation window
+Labels become tags, and the summary, description and runbook_url annotations
+the message ({{ $labels.x }} and {{ $value }} are translated).
+
+Rules with no Datadog equivalent (vector matching, unmapped metrics,
+functions other than rate/irate/increase, ...) are listed with the reason.
+Recording rules are listed as skipped.
+
+The templates are written to --out-dir, one file per rule group. With --apply,
+the written templates are applied (upserted by name) as well.
+
+Mapping file:
+  metric_prefix: prometheus
+  metrics:
+    http_requests_total: trace.http.request.hits
+  labels:
+    job: service
+
+Examples:
+  import prometheus --file rules.yaml --mapping prom-mapping.yaml
+  import prometheus --file prometheusrule.yaml --metric-prefix prometheus --out-dir templates/prometheus
+  import prometheus --file rules.yaml --mapping prom-mapping.yaml --apply --env prd`,
+	RunE: runImportPrometheus,
+}
+
+var (
+	importFile         string
+	importMapping      string
+	importMetricPrefix string
+	importOutDir       string
+	importForce        bool
+	importApply        bool
+	importUppercaseEnv bool
+	importService      string
+	importEnv          string
+	importNamespace    string
+)
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.AddCommand(importPrometheusCmd)
+	importPrometheusCmd.Flags().StringVarP(&importFile, "file", "f", "", "Prometheus rules file or PrometheusRule manifest (required)")
+	importPrometheusCmd.Flags().StringVar(&importMapping, "mapping", "", "YAML file mapping Prometheus metric and label names to Datadog ones")
+	importPrometheusCmd.Flags().StringVar(&importMetricPrefix, "metric-prefix", "", "Map unmapped metrics to <prefix>.<metric> (overrides the mapping file's metric_prefix)")
+	importPrometheusCmd.Flags().StringVar(&importOutDir, "out-dir", filepath.Join("templates", "prometheus"), "Directory to write the template files to")
+	importPrometheusCmd.Flags().BoolVar(&importForce, "force", false, "Overwrite existing template files")
+	importPrometheusCmd.Flags().BoolVar(&importApply, "apply", false, "Apply the written templates")
+	importPrometheusCmd.Flags().StringVar(&importService, "service", "", "Service to render the templates with (--apply)")
+	importPrometheusCmd.Flags().StringVar(&importEnv, "env", "", "Environment to render the templates with (--apply)")
+	importPrometheusCmd.Flags().StringVar(&importNamespace, "namespace", "", "Namespace to render the templates with (--apply)")
+	importPrometheusCmd.Flags().BoolVar(&importUppercaseEnv, "uppercase-env-in-name", false, "Render {env} in names uppercased, as before {env:upper} (--apply)")
+	importPrometheusCmd.Flags().MarkDeprecated("uppercase-env-in-name", "use {env:upper} in template names instead; the flag will be removed in the next release")
+	importPrometheusCmd.MarkFlagRequired("file")
+}
+
+func runImportPrometheus(cmd *cobra.Command, args []string) error {
+	if !importApply && (importService != "" || importEnv != "" || importNamespace != "") {
+		return fmt.Errorf("--service, --env and --namespace can only be used with --apply")
+	}
+
+	groups, err := prometheus.LoadRules(importFile)
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+	mapping, err := prometheus.LoadMapping(importMapping)
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+	if importMetricPrefix != "" {
+		mapping.MetricPrefix = importMetricPrefix
+	}
+
+	conversion := prometheus.Convert(groups, mapping)
+
+	out.Printf("📥 Prometheus rules: %s\n", importFile)
+	out.Println(strings.Repeat("=", 80))
+	for _, converted := range conversion.Converted {
+		out.Printf("✅ %s/%s\n", converted.Group, converted.Alert)
+		out.Printf("   Query: %s\n", converted.Template.Config["query"])
+		for _, note := range converted.Notes {
+			out.Printf("   ⚠️  %s\n", note)
+		}
+	}
+	for _, skipped := range conversion.Skipped {
+		out.Printf("⏭️  %s/%s: %s\n", skipped.Group, skipped.Alert, skipped.Reason)
+		out.Printf("   Expr: %s\n", strings.Join(strings.Fields(skipped.Expr), " "))
+	}
+	out.Println(strings.Repeat("=", 80))
+	out.Printf("Converted %d rule(s), %d not converted\n", len(conversion.Converted), len(conversion.Skipped))
+
+	if len(conversion.Converted) == 0 {
+		return nil
+	}
+
+	files, err := writeImportedTemplates(conversion.Converted)
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+	for _, file := range files {
+		out.Printf("📝 Wrote %s\n", file)
+	}
+
+	if !importApply {
+		return nil
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+	applyOpts := datadog.ApplyOptions{
+		RenderOptions: datadog.RenderOptions{Service: importService, Env: importEnv, Namespace: importNamespace, UppercaseEnvInName: importUppercaseEnv},
+		Upsert:        true,
+	}
+	out.Println()
+	applied := 0
+	for _, file := range files {
+		out.Printf("📄 Applying %s\n", filepath.Base(file))
+		results, err := client.ApplyTemplateWithOptions(file, applyOpts)
+		if err != nil {
+			errOut.Printf("❌ Error applying %s: %v\n", file, err)
+			return err
+		}
+		for _, result := range results {
+			printApplyResult(result, "   ")
+		}
+		applied += len(results)
+	}
+	out.Printf("\n✅ Applied %d monitor(s) from %d template file(s)\n", applied, len(files))
+	return nil
+}
+
+// writeImportedTemplates writes the converted rules to one template file per
+// rule group in importOutDir and returns the file paths. Existing files are
+// only overwritten with --force.
+func writeImportedTemplates(converted []prometheus.ConvertedRule) ([]string, error) {
+	var order []string
+	byFile := make(map[string][]datadog.TemplateData)
+	for _, rule := range converted {
+		file := filepath.Join(importOutDir, importedTemplateFileName(rule.Group))
+		if _, ok := byFile[file]; !ok {
+			order = append(order, file)
+		}
+		byFile[file] = append(byFile[file], rule.Template)
+	}
+
+	if !importForce {
+		for _, file := range order {
+			if _, err := os.Stat(file); err == nil {
+				return nil, fmt.Errorf("%s already exists (use --force to overwrite)", file)
+			}
+		}
+	}
+	if err := os.MkdirAll(importOutDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %v", importOutDir, err)
+	}
+	for _, file := range order {
+		var data bytes.Buffer
+		encoder := json.NewEncoder(&data)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(datadog.TemplateFile{Templates: byFile[file]}); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(file, data.Bytes(), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %v", file, err)
+		}
+	}
+	return order, nil
+}
+
+// importedTemplateFileName returns the template file name of a rule group:
+// the group name, lowercase, with runs of other characters replaced by '-'
+func importedTemplateFileName(group string) string {
+	var b strings.Builder
+	pending := false
+	for _, r := range strings.ToLower(group) {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			pending = true
+			continue
+		}
+		if pending && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		pending = false
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		return "prometheus.json"
+	}
+	return b.String() + ".json"
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Write the built-in starter templates into the template directory",
+	Long: `Write the built-in Kubernetes starter templates into --template-dir so there
+is something to apply and adapt. Existing files are kept unless --force.
+
+The basic preset has the crashloop, unavailable replicas and memory limit
+templates; full (the default) has every built-in template. See them with
+"template list-builtin".
+
+Examples:
+  init
+  init --preset basic --template-dir monitors/
+  init --force`,
+	RunE: runInit,
+}
+
+var (
+	initTemplateDir string
+	initPreset      string
+	initForce       bool
+)
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+	initCmd.Flags().StringVar(&initTemplateDir, "template-dir", "templates", "Directory to write the templates to (default: templates/)")
+	initCmd.Flags().StringVar(&initPreset, "preset", datadog.PresetFull, "Templates to write: basic or full")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "Overwrite existing template files")
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	templates, err := datadog.BuiltinTemplatesForPreset(initPreset)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(initTemplateDir, 0o755); err != nil {
+		errOut.Printf("❌ Error creating %s: %v\n", initTemplateDir, err)
+		return err
+	}
+
+	written, skipped := 0, 0
+	for _, template := range templates {
+		if err := template.Validate(); err != nil {
+			return fmt.Errorf("built-in template %s is invalid: %w", template.Name, err)
+		}
+		data, err := template.Data()
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(initTemplateDir, template.FileName())
+		if _, err := os.Stat(path); err == nil && !initForce {
+			out.Printf("⏭️  %s already exists (use --force to overwrite)\n", path)
+			skipped++
+			continue
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			errOut.Printf("❌ Error writing %s: %v\n", path, err)
+			return err
+		}
+		out.Printf("✅ Wrote %s - %s\n", path, template.Description)
+		written++
+	}
+
+	out.Printf("\n📊 %d template(s) written, %d skipped\n", written, skipped)
+	if written > 0 {
+		out.Printf("💡 Apply them with: datadog-monitor-manager template --template-dir %s --service <service> --env <env> --namespace <namespace>\n", initTemplateDir)
+	}
+	return nil
+}
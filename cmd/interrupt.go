@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var (
+	timeout time.Duration
+
+	// runCtx is cancelled on SIGINT/SIGTERM or when --timeout expires
+	runCtx        = context.Background()
+	cancelTimeout context.CancelFunc
+)
+
+// signalContext returns a context cancelled by the first SIGINT or SIGTERM.
+// After that the default signal behavior is restored, so a second Ctrl-C
+// terminates immediately.
+func signalContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
+
+// startCommandContext derives the command context from the signal context,
+// applying --timeout
+func startCommandContext(ctx context.Context) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if timeout > 0 {
+		ctx, cancelTimeout = context.WithTimeout(ctx, timeout)
+	}
+	runCtx = ctx
+}
+
+// stopCommandContext releases the --timeout timer
+func stopCommandContext() {
+	if cancelTimeout != nil {
+		cancelTimeout()
+	}
+}
+
+// commandContext returns the context of the running command
+func commandContext() context.Context {
+	return runCtx
+}
+
+// newClient creates a Datadog client whose requests are cancelled with the
+// command context, recorded in the --stats collector and, when an audit log
+// is configured, audited. Its mutating requests clear the --cache inventory
+// and, with an expected org, are refused in another org.
+func newClient() (*datadog.Client, error) {
+	opts := []datadog.Option{datadog.WithContext(commandContext())}
+	if runStats != nil {
+		opts = append(opts, datadog.WithStats(runStats))
+	}
+	auditPath, err := auditLogPath()
+	if err != nil {
+		return nil, err
+	}
+	if auditPath != "" {
+		opts = append(opts, datadog.WithAudit(newAuditLog(auditPath)))
+	}
+	// Mutating requests invalidate the --cache inventory, with or without --cache
+	opts = append(opts, datadog.WithInventoryFile(datadog.DefaultInventoryPath()))
+	check, err := orgCheck()
+	if err != nil {
+		return nil, err
+	}
+	if check != nil {
+		opts = append(opts, datadog.WithOrgCheck(check))
+	}
+	warnEnvConflicts()
+	return datadog.NewClient(opts...)
+}
+
+// envConflictsWarned is set once the environment variable conflicts were
+// reported, so commands creating several clients warn once
+var envConflictsWarned bool
+
+// warnEnvConflicts warns about credential and site variables ignored because
+// a higher-precedence spelling is set to another value
+func warnEnvConflicts() {
+	if envConflictsWarned {
+		return
+	}
+	envConflictsWarned = true
+	env, err := datadog.ResolveProcessEnv()
+	if err != nil {
+		// NewClient reports it
+		return
+	}
+	for _, conflict := range env.EnvConflicts() {
+		errOut.Printf("⚠️  %s\n", conflict)
+	}
+}
+
+// isInterrupted reports whether err comes from an interrupt, an expired
+// --timeout, a reached --request-budget/--fail-fast limit or a failed
+// --expect-org check
+func isInterrupted(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || datadog.IsLimitExceeded(err) || datadog.IsOrgCheckFailed(err)
+}
+
+// interruption returns the error that should stop a bulk loop: the command
+// context's error once it is done, or err when it is itself a cancellation.
+// Before an operation (err is nil) it is also the limit error once
+// --request-budget or --fail-fast is reached.
+func interruption(err error) error {
+	if ctxErr := commandContext().Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if isInterrupted(err) {
+		return err
+	}
+	if err == nil && runStats != nil {
+		return runStats.Exceeded()
+	}
+	return nil
+}
+
+// printInterrupted marks the summary that follows as partial when a bulk
+// operation was stopped after done of total items (total <= 0 when unknown)
+func printInterrupted(err error, done, total int, unit string) {
+	if err == nil {
+		return
+	}
+	reason := "🛑 Interrupted"
+	var limitErr *datadog.LimitError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		reason = fmt.Sprintf("⏱️  Timed out (--timeout %s)", timeout)
+	case errors.As(err, &limitErr) && limitErr.RequestBudget > 0:
+		reason = fmt.Sprintf("🧮 Request budget spent (--request-budget %d)", limitErr.RequestBudget)
+	case errors.As(err, &limitErr):
+		reason = fmt.Sprintf("🛑 Stopped at %d failure(s) (--fail-fast)", limitErr.FailFast)
+	case datadog.IsOrgCheckFailed(err):
+		reason = "🔒 Stopped by the org check (--expect-org)"
+	}
+	progress := fmt.Sprintf("%d %s", done, unit)
+	if total > 0 {
+		progress = fmt.Sprintf("%d of %d %s", done, total, unit)
+	}
+	out.Printf("\n%s after %s - the results below are PARTIAL\n", reason, progress)
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadogtest"
+)
+
+// interruptAt returns a context cancelled, as by Ctrl-C, while the n-th
+// request with method is in flight. The request is held until the client
+// gives up on it.
+func interruptAt(t *testing.T, srv *datadogtest.Server, method string, n int) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	var mu sync.Mutex
+	seen := 0
+	srv.Received = func(r *http.Request) {
+		if r.Method != method {
+			return
+		}
+		mu.Lock()
+		seen++
+		interrupt := seen == n
+		mu.Unlock()
+		if interrupt {
+			cancel()
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+				t.Error("the client didn't abort the request in flight")
+			}
+		}
+	}
+	return ctx
+}
+
+func TestInterruptMidBatch(t *testing.T) {
+	for _, tc := range []struct {
+		args    []string
+		method  string
+		summary []string
+	}{
+		{[]string{"delete-all", "--service", "old"}, "DELETE", []string{"Interrupted after 2 of 6 monitor(s) - the results below are PARTIAL", "Successfully deleted: 2"}},
+		{[]string{"set-renotify", "--service", "old", "--interval", "30"}, "PUT", []string{"Interrupted after 2 of 6 monitor(s) - the results below are PARTIAL", "Successfully updated: 2"}},
+	} {
+		t.Run(tc.args[0], func(t *testing.T) {
+			srv := newTestServer(t)
+			var monitors []datadog.Monitor
+			for i := 0; i < 6; i++ {
+				monitors = append(monitors, srv.AddMonitor(datadog.Monitor{Name: "old " + strconv.Itoa(i), Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90", Tags: []string{"service:old"}}))
+			}
+
+			res := runCLI(t, interruptAt(t, srv, tc.method, 3), append([]string{"--yes"}, tc.args...)...)
+			if !errors.Is(res.Err, context.Canceled) || ExitCode(res.Err) != ExitInterrupted {
+				t.Fatalf("interrupted run = %v (exit code %d), want context.Canceled and %d\n%s", res.Err, ExitCode(res.Err), ExitInterrupted, res.Stdout)
+			}
+			for _, want := range tc.summary {
+				if !strings.Contains(res.Stdout, want) {
+					t.Errorf("summary lacks %q:\n%s", want, res.Stdout)
+				}
+			}
+			// Nothing is sent after the interrupt
+			for _, monitor := range monitors[3:] {
+				srv.AssertRequestCount(t, 0, tc.method, "/monitor/"+strconv.Itoa(monitor.ID))
+			}
+		})
+	}
+}
@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
@@ -24,11 +27,77 @@ Examples:
   list --tags-only                        # Show only unique tags from all monitors
   list --service myapp --tags-only       # Show only tags from monitors with service tag
   list --monitor-id 12345 --tags-only    # Show only tags from monitor ID 12345
+  list --tags-only --tag-key team --with-counts # Monitors per team: tag, most common first
+  list --env prd --tags-only --with-counts --min-count 5 --output json
   list --query "service:(service1 OR service2)" # List monitors with complex query
   list --status "No Data"                       # List monitors with No Data status
   list --query "..." --status "No Data"         # Combine query and status filter
-  list --status "No Data"                       # List monitors with No Data status
-  list --query "..." --status "No Data"         # Combine query and status filter`,
+  list --status Alert --any-group               # Also monitors with one group alerting
+  list --missing-tag-key service                # Monitors without a service tag
+  list --missing-tag-key service --missing-tag-key team --missing-all --output csv > untagged.csv
+  list --service myapp --group-states all       # Show the state of every group
+  list --services checkout,payments --env prd   # Monitors of either service
+  list --query "..." --simple --limit 10        # Preview what a query matches
+  list --modified-since 7d --sort modified --desc  # Recent changes first
+  list --created-before 2023-01-01              # Monitors created before 2023
+  list --env prd --format '{{.ID}}\t{{.Name}}\t{{.OverallState}}'
+  list --status Alert --format-preset slack     # Paste into a chat
+  list --status Alert --watch                   # Live view during an incident
+  list --alerting --env prd --show-groups       # What is alerting, longest first
+  list --env prd --simple --watch --interval 30s --watch-notify
+  list --alerting --in-downtime                 # Alerts suppressed now, and by what
+  list --env prd --not-in-downtime              # Monitors no downtime silences
+
+--query is mutually exclusive with --tags, --service, --services, --env,
+--namespace and positional tags; --status, --filter-services, --limit, --simple and
+--tags-only all compose with it.
+
+When an exact tag (positional or --tags) matches no monitor, a free-text search
+for the tag value runs instead (e.g. "myap" for service:myap), and its results
+are shown under a "no exact tag matches" banner on stderr. --exact turns this
+off.
+
+--missing-tag-key keeps the monitors without a tag of the key (repeatable):
+with several keys, those missing any of them (--missing-any, the default) or
+all of them (--missing-all). A tag without a colon or value ("service",
+"service:") doesn't count. The summary line reports how many of the checked
+monitors lack the tags.
+
+--output csv prints the monitors (ID, name, type, state, tags and --fields)
+as CSV for spreadsheets, with the summary line on stderr; with --tags-only it
+prints tag,count rows.
+
+--tags-only prints the distinct tags of the listed monitors, sorted. --tag-key
+keeps the tags of one key, --with-counts prints tag<TAB>count sorted by count
+(most common first), --min-count hides tags on fewer monitors, and
+--output json prints an object of tag to count.
+
+--format executes a Go template per monitor with the monitor fields (.ID,
+.Name, .Type, .Query, .Message, .Tags, .OverallState, .Priority, ...) and .URL,
+the monitor's link. Helper functions: join, tagvalue, truncate, mdescape and
+slackescape, e.g. '{{.ID}} {{tagvalue "env" .Tags}} {{.Name | truncate 40}}'.
+\t and \n stand for a tab and a newline. --format-preset picks a built-in
+format: slack or markdown-table.
+
+--watch redraws the list every --interval (default 15s, at least 5s) until
+Ctrl-C, with every filter and output format. Monitors whose state changed since
+the previous poll are highlighted (new alerts in red, recoveries in green) and
+listed under the monitors, as are monitors that stopped matching the filters.
+--watch-notify rings the terminal bell on new alerts.
+
+--alerting lists the monitors alerting now (overall, or in one of their
+groups) with how long they have been alerting: since the earliest
+last_triggered_ts of their groups in Alert. The longest alerting come first.
+--show-groups adds the names of the alerting groups. --simple prints
+ID<TAB>duration<TAB>name.
+
+--in-downtime keeps the monitors an active downtime silences, showing the
+downtime ID, end time and scope (a column with --simple and --output csv), and
+--not-in-downtime the others. The active downtimes are fetched once. A
+downtime silences a monitor when its monitor_id is the monitor's, and every
+tag of its monitor_tags and of its scope is a tag of the monitor ("*"
+matches any monitor). A downtime scoped to groups, such as host:a on a
+monitor grouped by host, silences only part of the monitor and doesn't count.`,
 	RunE: runList,
 }
 
@@ -44,6 +113,40 @@ var (
 	listTagsOnly       bool
 	listMonitorID      int
 	listLimit          int
+	listFields         string
+	listCreatedBy      string
+	listCreatedSince   string
+	listCreatedBefore  string
+	listModifiedSince  string
+	listModifiedBefore string
+	listSort           string
+	listDesc           bool
+	listGroupStates    string
+	listAnyGroup       bool
+	listFormat         string
+	listFormatPreset   string
+
+	listTagKey     string
+	listWithCounts bool
+	listMinCount   int
+	listOutput     string
+
+	listMissingTagKeys []string
+	listMissingAny     bool
+	listMissingAll     bool
+
+	listExact    bool
+	listServices string
+
+	listWatch       bool
+	listInterval    time.Duration
+	listWatchNotify bool
+
+	listAlerting   bool
+	listShowGroups bool
+
+	listInDowntime    bool
+	listNotInDowntime bool
 )
 
 func init() {
@@ -52,19 +155,193 @@ func init() {
 	listCmd.Flags().StringVar(&listEnv, "env", "", "Filter by environment")
 	listCmd.Flags().StringVar(&listNamespace, "namespace", "", "Filter by namespace")
 	listCmd.Flags().StringVar(&listTags, "tags", "", "Search in all tags (like UI search box)")
-	listCmd.Flags().StringVar(&listQuery, "query", "", "Complex search query (e.g., service:(service1 OR service2))")
-	listCmd.Flags().StringVar(&listStatus, "status", "", "Filter by monitor status (e.g., No Data, Alert, Warn, OK, muted)")
+	listCmd.Flags().StringVar(&listQuery, "query", "", "Complex search query (e.g., service:(service1 OR service2)); cannot be combined with --tags/--service/--env/--namespace")
+	listCmd.Flags().StringVar(&listStatus, "status", "", "Filter by monitor state, comma-separated (OK, Alert, Warn, No Data, Unknown, Skipped, Ignored)")
+	listCmd.Flags().StringVar(&listServices, "services", "", "Filter by any of these services (comma-separated, OR-matched; cannot be combined with --service)")
 	listCmd.Flags().StringVar(&listFilterServices, "filter-services", "", "Filter by multiple services (comma-separated, filters locally after query/tags)")
 	listCmd.Flags().BoolVar(&listSimple, "simple", false, "Simple output format (ID and name only)")
 	listCmd.Flags().BoolVar(&listTagsOnly, "tags-only", false, "Show only tags from monitors")
 	listCmd.Flags().IntVar(&listMonitorID, "monitor-id", 0, "Get tags from a specific monitor (use with --tags-only)")
+	listCmd.Flags().StringVar(&listTagKey, "tag-key", "", "With --tags-only, only show the tags of this key (e.g., team)")
+	listCmd.Flags().BoolVar(&listWithCounts, "with-counts", false, "With --tags-only, print the number of monitors per tag, most common first")
+	listCmd.Flags().IntVar(&listMinCount, "min-count", 0, "With --tags-only, hide tags on fewer monitors than this")
+	listCmd.Flags().StringVarP(&listOutput, "output", "o", "table", "Output format: table, csv, or with --tags-only json (an object of tag to count)")
+	listCmd.Flags().StringArrayVar(&listMissingTagKeys, "missing-tag-key", []string{}, "Only monitors without a tag of this key, e.g. service (can be used multiple times)")
+	listCmd.Flags().BoolVar(&listMissingAny, "missing-any", false, "With several --missing-tag-key, keep monitors missing any of the keys (default)")
+	listCmd.Flags().BoolVar(&listMissingAll, "missing-all", false, "With several --missing-tag-key, keep monitors missing all of the keys")
+	listCmd.Flags().BoolVar(&listExact, "exact", false, "Don't fall back to a free-text search when an exact tag matches nothing")
 	listCmd.Flags().IntVar(&listLimit, "limit", 0, "Limit number of monitors to show (e.g., --limit 1 for one example)")
+	listCmd.Flags().StringVar(&listFields, "fields", "", "Extra fields to show (comma-separated): "+strings.Join(listFieldNames(), ", "))
+	listCmd.Flags().StringVar(&listCreatedBy, "created-by", "", "Only monitors created by this user (email or handle)")
+	listCmd.Flags().StringVar(&listCreatedSince, "created-since", "", "Only monitors created at or after this time (RFC3339, YYYY-MM-DD or a duration ago like 7d)")
+	listCmd.Flags().StringVar(&listCreatedBefore, "created-before", "", "Only monitors created before this time (RFC3339, YYYY-MM-DD or a duration ago like 7d)")
+	listCmd.Flags().StringVar(&listModifiedSince, "modified-since", "", "Only monitors modified at or after this time (RFC3339, YYYY-MM-DD or a duration ago like 7d)")
+	listCmd.Flags().StringVar(&listModifiedBefore, "modified-before", "", "Only monitors modified before this time (RFC3339, YYYY-MM-DD or a duration ago like 7d)")
+	listCmd.Flags().StringVar(&listSort, "sort", "", "Sort by: "+strings.Join(monitorSortKeys, ", "))
+	listCmd.Flags().BoolVar(&listDesc, "desc", false, "With --sort, sort in descending order")
+	listCmd.Flags().StringVar(&listGroupStates, "group-states", "", "Show per-group states of multi-alert monitors for these states (comma-separated: all, alert, warn, no data)")
+	listCmd.Flags().BoolVar(&listAnyGroup, "any-group", false, "With --status, also match monitors with any group in that state")
+	listCmd.Flags().StringVar(&listFormat, "format", "", "Go template executed per monitor (e.g., '{{.ID}}\\t{{.Name}}\\t{{.OverallState}}')")
+	listCmd.Flags().StringVar(&listFormatPreset, "format-preset", "", "Built-in output format: "+strings.Join(monitorFormatPresetNames(), ", "))
+	listCmd.Flags().BoolVar(&listWatch, "watch", false, "Redraw the list every --interval until Ctrl-C, highlighting state changes")
+	listCmd.Flags().DurationVar(&listInterval, "interval", 15*time.Second, "With --watch, how often to poll (minimum 5s)")
+	listCmd.Flags().BoolVar(&listWatchNotify, "watch-notify", false, "With --watch, ring the terminal bell on new alerts")
+	listCmd.Flags().BoolVar(&listAlerting, "alerting", false, "Only monitors alerting now, with how long they have been alerting, longest first")
+	listCmd.Flags().BoolVar(&listShowGroups, "show-groups", false, "With --alerting, show the names of the alerting groups")
+	listCmd.Flags().BoolVar(&listInDowntime, "in-downtime", false, "Only monitors silenced by an active downtime, with the downtime ID and end time")
+	listCmd.Flags().BoolVar(&listNotInDowntime, "not-in-downtime", false, "Only monitors no active downtime silences")
+}
+
+// listFieldValues extracts the extra fields list can show with --fields
+var listFieldValues = map[string]func(datadog.Monitor) string{
+	"creator.name": func(m datadog.Monitor) string {
+		if m.Creator == nil {
+			return ""
+		}
+		return m.Creator.Name
+	},
+	"creator.email": func(m datadog.Monitor) string {
+		if m.Creator == nil {
+			return ""
+		}
+		return m.Creator.Email
+	},
+	"creator.handle": func(m datadog.Monitor) string {
+		if m.Creator == nil {
+			return ""
+		}
+		return m.Creator.Handle
+	},
+	"created":  func(m datadog.Monitor) string { return formatTimestamp(m.CreatedAt) },
+	"modified": func(m datadog.Monitor) string { return formatTimestamp(m.Modified) },
+	"query":    func(m datadog.Monitor) string { return m.Query },
+}
+
+// listFieldNames returns the names accepted by --fields, sorted
+func listFieldNames() []string {
+	names := make([]string, 0, len(listFieldValues))
+	for name := range listFieldValues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 func runList(cmd *cobra.Command, args []string) error {
-	client, err := datadog.NewClient()
+	fields := splitCommaList(listFields)
+	for _, field := range fields {
+		if _, ok := listFieldValues[field]; !ok {
+			return fmt.Errorf("unknown field %q in --fields (must be one of: %s)", field, strings.Join(listFieldNames(), ", "))
+		}
+	}
+
+	now := time.Now()
+	created, err := parseTimeRange("created", listCreatedSince, listCreatedBefore, now)
+	if err != nil {
+		return err
+	}
+	modified, err := parseTimeRange("modified", listModifiedSince, listModifiedBefore, now)
+	if err != nil {
+		return err
+	}
+	if listDesc && listSort == "" {
+		return fmt.Errorf("--desc requires --sort")
+	}
+	if listAnyGroup && listStatus == "" {
+		return fmt.Errorf("--any-group requires --status")
+	}
+	groupStates, err := datadog.NormalizeGroupStates(splitCommaList(listGroupStates))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		return err
+	}
+	formatter, err := newMonitorFormatter(listFormat, listFormatPreset)
+	if err != nil {
+		return err
+	}
+	if formatter != nil && (listSimple || listTagsOnly) {
+		return fmt.Errorf("--format and --format-preset cannot be combined with --simple or --tags-only")
+	}
+	if listOutput != "table" && listOutput != "json" && listOutput != "csv" {
+		return fmt.Errorf("invalid --output %q (must be table, csv or json)", listOutput)
+	}
+	if !listTagsOnly && (listTagKey != "" || listWithCounts || listMinCount != 0 || listOutput == "json") {
+		return fmt.Errorf("--tag-key, --with-counts, --min-count and --output json require --tags-only")
+	}
+	if listOutput == "csv" && (formatter != nil || listSimple || listWatch) {
+		return fmt.Errorf("--output csv cannot be combined with --format, --format-preset, --simple or --watch")
+	}
+	if err := validateMissingTagKeys(); err != nil {
+		return err
+	}
+	if !listWatch && (cmd.Flags().Changed("interval") || listWatchNotify) {
+		return fmt.Errorf("--interval and --watch-notify require --watch")
+	}
+	if listWatch && listTagsOnly {
+		return fmt.Errorf("--watch cannot be combined with --tags-only")
+	}
+	if listWatch && listInterval < minStatusWatch {
+		return fmt.Errorf("--interval must be at least %s", minStatusWatch)
+	}
+	if err := validateListAlerting(formatter); err != nil {
+		return err
+	}
+	if listInDowntime && listNotInDowntime {
+		return fmt.Errorf("cannot use --in-downtime together with --not-in-downtime")
+	}
+
+	selector := monitorSelector{
+		Query:          listQuery,
+		Service:        listService,
+		Services:       splitCommaList(listServices),
+		Env:            listEnv,
+		Namespace:      listNamespace,
+		Status:         listStatus,
+		FilterServices: listFilterServices,
+		GroupStates:    groupStates,
+		AnyGroup:       listAnyGroup,
+		TagFallback:    tagFallbackShow,
+		// --watch polls live states, never the cached inventory
+		Cacheable: !listWatch,
+	}
+	if listAlerting {
+		// Alerting monitors and those with a group in Alert, fetched with
+		// group_states=alert for the trigger times
+		selector.Status = "Alert"
+		selector.AnyGroup = true
+	}
+	if listExact {
+		selector.TagFallback = tagFallbackNone
+	}
+
+	// If tags flag is empty but we have positional args that look like tags, use them
+	tagFilter := listTags
+	if tagFilter == "" {
+		for _, arg := range args {
+			// If argument contains ':', treat it as a tag
+			if strings.Contains(arg, ":") {
+				tagFilter = arg
+				break
+			}
+		}
+	}
+	if tagFilter != "" {
+		// If the search text looks like a tag (contains ':'), use tag filter directly;
+		// otherwise use it as search text for flexible search
+		if strings.Contains(tagFilter, ":") {
+			selector.Tags = []string{tagFilter}
+		} else {
+			selector.Search = tagFilter
+		}
+	}
+
+	if err := selector.validate(); err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
 		return err
 	}
 
@@ -72,162 +349,265 @@ func runList(cmd *cobra.Command, args []string) error {
 	if listMonitorID > 0 && listTagsOnly {
 		monitor, err := client.GetMonitor(listMonitorID)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "❌ Error getting monitor: %v\n", err)
+			reportMonitorError("getting monitor", err)
 			return err
 		}
 
-		// Print tags, one per line, sorted
-		tags := make([]string, len(monitor.Tags))
-		copy(tags, monitor.Tags)
-		sort.Strings(tags)
+		return printListTags(filterMonitorsMissingTagKeys([]datadog.Monitor{*monitor}, listMissingTagKeys, listMissingAll))
+	}
 
-		for _, tag := range tags {
-			fmt.Println(tag)
+	if formatter != nil {
+		formatter.appURL = client.AppURL()
+	}
+	view := listView{fields: fields, formatter: formatter}
+	if listWatch {
+		return watchList(client, selector, created, modified, view)
+	}
+
+	monitors, counts, err := listMonitors(client, selector, created, modified)
+	if err != nil {
+		errOut.Printf("❌ Error listing monitors: %v\n", err)
+		return err
+	}
+	if listTagsOnly {
+		return printListTags(monitors)
+	}
+	if listAlerting {
+		printAlertingMonitors(monitors, now, counts.downtimes)
+		return nil
+	}
+	view.counts = counts
+	return view.print(monitors)
+}
+
+// validateListAlerting checks that --alerting is not combined with the flags
+// it stands for or whose output it replaces
+func validateListAlerting(formatter *monitorFormatter) error {
+	if !listAlerting {
+		if listShowGroups {
+			return fmt.Errorf("--show-groups requires --alerting")
 		}
 		return nil
 	}
+	if listStatus != "" || listAnyGroup || listGroupStates != "" || listSort != "" {
+		return fmt.Errorf("--alerting cannot be combined with --status, --any-group, --group-states or --sort")
+	}
+	if listTagsOnly || listWatch || formatter != nil || listOutput != "table" || listFields != "" {
+		return fmt.Errorf("--alerting cannot be combined with --tags-only, --watch, --format, --format-preset, --output or --fields")
+	}
+	return nil
+}
 
-	var monitors []datadog.Monitor
+// sortByAlertingSince sorts monitors by how long they have been alerting,
+// longest first; monitors without a trigger time come last, by ID
+func sortByAlertingSince(monitors []datadog.Monitor) {
+	sort.SliceStable(monitors, func(i, j int) bool {
+		a, b := datadog.AlertingSince(monitors[i]), datadog.AlertingSince(monitors[j])
+		switch {
+		case a.IsZero() != b.IsZero():
+			return b.IsZero()
+		case !a.Equal(b):
+			return a.Before(b)
+		}
+		return monitors[i].ID < monitors[j].ID
+	})
+}
 
-	// If query flag is set, use it directly
-	if listQuery != "" {
-		monitors, err = client.ListMonitors(nil, listQuery)
-	} else if listTags != "" {
-		// If the search text looks like a tag (contains ':'), use tag filter directly
-		if strings.Contains(listTags, ":") {
-			// Use exact tag filter via API
-			exactTag := listTags
-			tags := []string{exactTag}
-			monitors, err = client.ListMonitors(tags, "")
-		} else {
-			// Use search text for flexible search (no ':')
-			monitors, err = client.ListMonitors(nil, listTags)
-		}
-	} else {
-		// If tags flag is empty but we have positional args that look like tags, use them
-		if len(args) > 0 {
-			for _, arg := range args {
-				// If argument contains ':', treat it as a tag
-				if strings.Contains(arg, ":") {
-					listTags = arg
-					break
-				}
+// alertingDuration returns how long a monitor has been alerting at now, e.g.
+// "2h 13m", or "unknown" without a trigger time
+func alertingDuration(monitor datadog.Monitor, now time.Time) string {
+	since := datadog.AlertingSince(monitor)
+	if since.IsZero() {
+		return "unknown"
+	}
+	return formatDuration(now.Sub(since.Time()))
+}
+
+// alertingGroupNames returns the names of the alerting groups of a monitor
+func alertingGroupNames(monitor datadog.Monitor) []string {
+	var names []string
+	for _, group := range datadog.AlertingGroups(monitor) {
+		names = append(names, group.Name)
+	}
+	return names
+}
+
+// printAlertingMonitors prints the monitors of list --alerting, sorted by
+// sortByAlertingSince, with how long they have been alerting, with
+// --show-groups the alerting groups and with --in-downtime the downtimes
+// silencing them
+func printAlertingMonitors(monitors []datadog.Monitor, now time.Time, downtimes map[int][]datadog.Downtime) {
+	if listSimple {
+		for _, monitor := range monitors {
+			line := fmt.Sprintf("%d\t%s\t%s", monitor.ID, alertingDuration(monitor, now), monitor.Name)
+			if listShowGroups {
+				line += "\t" + strings.Join(alertingGroupNames(monitor), ",")
+			}
+			if listInDowntime {
+				line += "\t" + downtimeSummary(downtimes[monitor.ID])
 			}
+			fmt.Println(line)
 		}
+		return
+	}
 
-		if listTags != "" {
-			exactTag := listTags
-			tags := []string{exactTag}
-			monitors, err = client.ListMonitors(tags, "")
+	if len(monitors) == 0 {
+		out.Println("\n✅ No monitors alerting")
+		return
+	}
+	out.Printf("\n🔴 %d monitor(s) alerting, longest first:\n", len(monitors))
+	out.Println(strings.Repeat("-", 80))
+	for _, monitor := range monitors {
+		out.Printf("\nID %d: %s\n", monitor.ID, monitor.Name)
+		if since := datadog.AlertingSince(monitor); since.IsZero() {
+			out.Println("   ⏱️  alerting (trigger time unknown)")
 		} else {
-			var tags []string
-			if listService != "" {
-				tags = append(tags, fmt.Sprintf("service:%s", listService))
-			}
-			if listEnv != "" {
-				tags = append(tags, fmt.Sprintf("env:%s", listEnv))
-			}
-			if listNamespace != "" {
-				tags = append(tags, fmt.Sprintf("namespace:%s", listNamespace))
-			}
+			out.Printf("   ⏱️  alerting for %s (since %s)\n", alertingDuration(monitor, now), formatTime(since.Time()))
+		}
+		for _, downtime := range downtimes[monitor.ID] {
+			out.Printf("   🔕 Downtime %s\n", downtimeLine(downtime))
+		}
+		if !listShowGroups {
+			continue
+		}
+		if names := alertingGroupNames(monitor); len(names) > 0 {
+			out.Printf("   Groups (%d): %s\n", len(names), strings.Join(names, ", "))
+		}
+	}
+}
 
-			if len(tags) > 0 {
-				monitors, err = client.ListMonitors(tags, "")
-			} else {
-				monitors, err = client.ListMonitors(nil, "")
-			}
+// validateMissingTagKeys checks --missing-tag-key and the flags combining
+// its keys; a trailing colon (service:) is accepted
+func validateMissingTagKeys() error {
+	if len(listMissingTagKeys) == 0 && (listMissingAny || listMissingAll) {
+		return fmt.Errorf("--missing-any and --missing-all require --missing-tag-key")
+	}
+	if listMissingAny && listMissingAll {
+		return fmt.Errorf("cannot use --missing-any together with --missing-all")
+	}
+	for i, key := range listMissingTagKeys {
+		key = strings.TrimSuffix(strings.TrimSpace(key), ":")
+		if key == "" || strings.Contains(key, ":") {
+			return fmt.Errorf("invalid --missing-tag-key %q (must be a tag key, e.g. service)", listMissingTagKeys[i])
 		}
+		listMissingTagKeys[i] = key
 	}
+	return nil
+}
 
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Error listing monitors: %v\n", err)
-		return err
+// missingTagKeysDescription describes the --missing-tag-key filter for the
+// summary line, e.g. "without a service tag"
+func missingTagKeysDescription() string {
+	switch {
+	case len(listMissingTagKeys) == 1:
+		return fmt.Sprintf("without a %s tag", listMissingTagKeys[0])
+	case listMissingAll:
+		return fmt.Sprintf("without any of the tag keys %s", strings.Join(listMissingTagKeys, ", "))
+	default:
+		return fmt.Sprintf("missing one of the tag keys %s", strings.Join(listMissingTagKeys, ", "))
 	}
+}
 
-	// Filter by status if specified
-	if listStatus != "" {
-		var filteredMonitors []datadog.Monitor
-		for _, monitor := range monitors {
-			// Normalize status comparison (case-insensitive)
-			if strings.EqualFold(monitor.OverallState, listStatus) {
-				filteredMonitors = append(filteredMonitors, monitor)
-			}
-		}
-		monitors = filteredMonitors
+// listCounts are the monitors --missing-tag-key checked and those it kept,
+// before --limit. With --in-downtime, downtimes holds the active downtimes
+// silencing each listed monitor.
+type listCounts struct {
+	checked int
+	missing int
+
+	downtimes map[int][]datadog.Downtime
+}
+
+// listMonitors fetches the monitors matching selector and applies the
+// client-side filters, --sort and --limit
+func listMonitors(client *datadog.Client, selector monitorSelector, created, modified timeRange) ([]datadog.Monitor, listCounts, error) {
+	var counts listCounts
+	monitors, err := fetchMonitors(client, selector)
+	if err != nil {
+		return nil, counts, err
 	}
 
-	// Filter by services if specified
-	if listFilterServices != "" {
-		services := strings.Split(listFilterServices, ",")
-		for i := range services {
-			services[i] = strings.TrimSpace(services[i])
+	if listCreatedBy != "" {
+		monitors = filterMonitorsByCreator(monitors, listCreatedBy)
+	}
+	monitors = filterMonitorsByTime(monitors, created, modified)
+	if listInDowntime || listNotInDowntime {
+		if monitors, counts.downtimes, err = filterMonitorsByDowntime(client, monitors, listInDowntime); err != nil {
+			return nil, counts, err
 		}
-		var filteredMonitors []datadog.Monitor
-		for _, monitor := range monitors {
-			for _, service := range services {
-				tag := fmt.Sprintf("service:%s", service)
-				for _, monitorTag := range monitor.Tags {
-					if monitorTag == tag {
-						filteredMonitors = append(filteredMonitors, monitor)
-						goto nextMonitor
-					}
-				}
-			}
-		nextMonitor:
+	}
+	counts.checked = len(monitors)
+	monitors = filterMonitorsMissingTagKeys(monitors, listMissingTagKeys, listMissingAll)
+	counts.missing = len(monitors)
+	if listAlerting {
+		sortByAlertingSince(monitors)
+	} else if listSort != "" {
+		if err := sortMonitors(monitors, listSort, listDesc); err != nil {
+			return nil, counts, err
 		}
-		monitors = filteredMonitors
 	}
 
 	// Apply limit if specified
 	if listLimit > 0 && len(monitors) > listLimit {
 		monitors = monitors[:listLimit]
 	}
+	return monitors, counts, nil
+}
 
-	if listTagsOnly {
-		// Collect all unique tags
-		tagSet := make(map[string]bool)
-		for _, monitor := range monitors {
-			for _, tag := range monitor.Tags {
-				tagSet[tag] = true
-			}
-		}
-
-		// Convert to slice and sort for consistent output
-		var tags []string
-		for tag := range tagSet {
-			tags = append(tags, tag)
-		}
-		sort.Strings(tags)
+// listView prints listed monitors in the output format of the flags. With
+// --watch, changes holds the monitors whose state changed since the previous
+// poll, which are highlighted.
+type listView struct {
+	fields    []string
+	formatter *monitorFormatter
+	changes   map[int]listStateChange
+	counts    listCounts
+}
 
-		// Print tags, one per line
-		for _, tag := range tags {
-			fmt.Println(tag)
-		}
-		return nil
+// print prints monitors with --format, --simple, --output csv or as the
+// default table
+func (v listView) print(monitors []datadog.Monitor) error {
+	if len(listMissingTagKeys) > 0 && (v.formatter != nil || listSimple || listOutput == "csv") {
+		// Keep the data on stdout machine-readable
+		errOut.Printf("📊 %d of %d monitor(s) %s\n", v.counts.missing, v.counts.checked, missingTagKeysDescription())
+	}
+	if v.formatter != nil {
+		return v.formatter.print(os.Stdout, monitors)
+	}
+	if listOutput == "csv" {
+		return printListCSV(monitors, v.fields, v.counts.downtimes)
 	}
 
 	if listSimple {
 		// Simple format: ID, State, and name
 		for _, monitor := range monitors {
-			state := monitor.OverallState
-			if state == "" {
-				state = "OK"
+			line := fmt.Sprintf("%d\t%s\t%s", monitor.ID, listState(monitor), monitor.Name)
+			for _, field := range v.fields {
+				line += "\t" + listFieldValues[field](monitor)
+			}
+			if listInDowntime {
+				line += "\t" + downtimeSummary(v.counts.downtimes[monitor.ID])
 			}
-			fmt.Printf("%d\t%s\t%s\n", monitor.ID, state, monitor.Name)
+			fmt.Println(v.highlight(monitor, line))
 		}
 		return nil
 	}
 
 	totalCount := len(monitors)
-	if listLimit > 0 {
-		fmt.Printf("\n📊 Showing %d monitor(s) (limited):\n", totalCount)
-	} else {
-		fmt.Printf("\n📊 Found %d monitors:\n", totalCount)
+	switch {
+	case len(listMissingTagKeys) > 0 && totalCount < v.counts.missing:
+		out.Printf("\n📊 %d of %d monitor(s) %s, showing %d (limited):\n", v.counts.missing, v.counts.checked, missingTagKeysDescription(), totalCount)
+	case len(listMissingTagKeys) > 0:
+		out.Printf("\n📊 %d of %d monitor(s) %s:\n", v.counts.missing, v.counts.checked, missingTagKeysDescription())
+	case listLimit > 0:
+		out.Printf("\n📊 Showing %d monitor(s) (limited):\n", totalCount)
+	default:
+		out.Printf("\n📊 Found %d monitors:\n", totalCount)
 	}
 	if totalCount == 0 {
 		return nil
 	}
-	fmt.Println(strings.Repeat("-", 80))
+	out.Println(strings.Repeat("-", 80))
 
 	for _, monitor := range monitors {
 		enabledStatus := "🟢 Enabled"
@@ -236,22 +616,285 @@ func runList(cmd *cobra.Command, args []string) error {
 		}
 
 		// Show alert state if available
-		alertState := monitor.OverallState
-		if alertState == "" {
-			alertState = "OK"
+		alertState := listState(monitor)
+		if change, ok := v.changes[monitor.ID]; ok && change.From != "" {
+			alertState += fmt.Sprintf(" (was %s)", change.From)
 		}
 
-		fmt.Printf("\nID: %d\n", monitor.ID)
-		fmt.Printf("Name: %s\n", monitor.Name)
-		fmt.Printf("Type: %s\n", monitor.Type)
-		fmt.Printf("Status: %s\n", enabledStatus)
-		fmt.Printf("State: %s\n", alertState)
+		out.Printf("\nID: %d\n", monitor.ID)
+		out.Printf("Name: %s\n", v.highlight(monitor, monitor.Name))
+		out.Printf("Type: %s\n", monitor.Type)
+		out.Printf("Status: %s\n", enabledStatus)
+		out.Printf("State: %s\n", v.highlight(monitor, alertState))
 		if len(monitor.Tags) > 0 {
-			fmt.Printf("Tags: %s\n", strings.Join(monitor.Tags, ", "))
+			out.Printf("Tags: %s\n", strings.Join(monitor.Tags, ", "))
 		} else {
-			fmt.Printf("Tags: (none)\n")
+			out.Printf("Tags: (none)\n")
+		}
+		for _, field := range v.fields {
+			out.Printf("%s: %s\n", field, listFieldValues[field](monitor))
+		}
+		if downtimes := v.counts.downtimes[monitor.ID]; len(downtimes) > 0 {
+			out.Printf("Downtimes (%d):\n", len(downtimes))
+			for _, downtime := range downtimes {
+				out.Printf("  🔕 %s\n", downtimeLine(downtime))
+			}
+		}
+		printGroupStates(monitor)
+	}
+
+	return nil
+}
+
+// printListCSV prints monitors as CSV for --output csv: ID, name, type,
+// state, tags (comma-separated in one cell), the --fields and with
+// --in-downtime the downtimes silencing the monitor
+func printListCSV(monitors []datadog.Monitor, fields []string, downtimes map[int][]datadog.Downtime) error {
+	w := csv.NewWriter(os.Stdout)
+	header := append([]string{"id", "name", "type", "state", "tags"}, fields...)
+	if listInDowntime {
+		header = append(header, "downtimes")
+	}
+	w.Write(header)
+	for _, monitor := range monitors {
+		record := []string{fmt.Sprint(monitor.ID), monitor.Name, monitor.Type, listState(monitor), strings.Join(monitor.Tags, ",")}
+		for _, field := range fields {
+			record = append(record, listFieldValues[field](monitor))
+		}
+		if listInDowntime {
+			record = append(record, downtimeSummary(downtimes[monitor.ID]))
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// highlight colors text of a monitor whose state changed since the previous
+// poll in the color of its new state
+func (v listView) highlight(monitor datadog.Monitor, text string) string {
+	if _, ok := v.changes[monitor.ID]; !ok {
+		return text
+	}
+	return colorize(statusColors[listState(monitor)], text)
+}
+
+// listState returns the state list shows for a monitor, OK when the API
+// reports none
+func listState(monitor datadog.Monitor) string {
+	if monitor.OverallState == "" {
+		return "OK"
+	}
+	return monitor.OverallState
+}
+
+// listStateChange is the change of a monitor's state between two --watch
+// polls. From is empty for a monitor that newly matches the filters.
+type listStateChange struct {
+	From, To string
+}
+
+// listStateChanges compares the monitors of a poll with those of the previous
+// one: it returns the state changes by monitor ID and the monitors that no
+// longer match, sorted by ID
+func listStateChanges(previous map[int]datadog.Monitor, monitors []datadog.Monitor) (map[int]listStateChange, []datadog.Monitor) {
+	changes := make(map[int]listStateChange)
+	seen := make(map[int]bool, len(monitors))
+	for _, monitor := range monitors {
+		seen[monitor.ID] = true
+		before, ok := previous[monitor.ID]
+		switch {
+		case !ok:
+			changes[monitor.ID] = listStateChange{To: listState(monitor)}
+		case listState(before) != listState(monitor):
+			changes[monitor.ID] = listStateChange{From: listState(before), To: listState(monitor)}
+		}
+	}
+	var gone []datadog.Monitor
+	for id, monitor := range previous {
+		if !seen[id] {
+			gone = append(gone, monitor)
+		}
+	}
+	sort.Slice(gone, func(i, j int) bool { return gone[i].ID < gone[j].ID })
+	return changes, gone
+}
+
+// watchList redraws the list every --interval until Ctrl-C, with one client
+// for every poll. Errors are reported and retried at the next poll.
+func watchList(client *datadog.Client, selector monitorSelector, created, modified timeRange, view listView) error {
+	var previous map[int]datadog.Monitor
+	for {
+		monitors, counts, err := listMonitors(client, selector, created, modified)
+		switch {
+		case err != nil && isInterrupted(err):
+			return nil
+		case err != nil:
+			// Keep watching through transient errors
+			errOut.Printf("❌ Error listing monitors: %v (retrying in %s)\n", err, listInterval)
+		default:
+			var gone []datadog.Monitor
+			view.changes = nil
+			view.counts = counts
+			if previous != nil {
+				view.changes, gone = listStateChanges(previous, monitors)
+			}
+			if stdoutIsTerminal() {
+				out.Print("\033[H\033[2J")
+			}
+			if err := view.print(monitors); err != nil {
+				return err
+			}
+			printListChanges(monitors, view.changes, gone)
+			out.Printf("\n🔄 %s, refreshing every %s (Ctrl-C to stop)\n", formatTime(time.Now()), listInterval)
+
+			previous = make(map[int]datadog.Monitor, len(monitors))
+			for _, monitor := range monitors {
+				previous[monitor.ID] = monitor
+			}
+		}
+
+		select {
+		case <-time.After(listInterval):
+		case <-commandContext().Done():
+			return nil
 		}
 	}
+}
 
+// printListChanges prints the state changes of a --watch poll, and rings the
+// terminal bell with --watch-notify when a monitor newly alerts
+func printListChanges(monitors []datadog.Monitor, changes map[int]listStateChange, gone []datadog.Monitor) {
+	if len(changes) == 0 && len(gone) == 0 {
+		return
+	}
+	newAlerts := 0
+	out.Printf("\n🔔 Changed since the previous poll:\n")
+	for _, monitor := range monitors {
+		change, ok := changes[monitor.ID]
+		if !ok {
+			continue
+		}
+		line := fmt.Sprintf("   ID %d: %s: %s → %s", monitor.ID, monitor.Name, change.From, change.To)
+		if change.From == "" {
+			line = fmt.Sprintf("   ID %d: %s: now matches (%s)", monitor.ID, monitor.Name, change.To)
+		}
+		out.Println(colorize(statusColors[change.To], line))
+		if change.To == "Alert" {
+			newAlerts++
+		}
+	}
+	for _, monitor := range gone {
+		out.Printf("   ID %d: %s: no longer matches (was %s)\n", monitor.ID, monitor.Name, listState(monitor))
+	}
+	if newAlerts > 0 && listWatchNotify {
+		fmt.Fprint(os.Stderr, "\a")
+	}
+}
+
+// printListTags prints the tags of monitors for --tags-only: one per line,
+// sorted by name, or with --with-counts by count; with --output json an
+// object of tag to count, with --output csv tag,count rows
+func printListTags(monitors []datadog.Monitor) error {
+	var counts []datadog.TagCount
+	for _, count := range datadog.CountTags(monitors, listTagKey) {
+		if count.Count >= listMinCount {
+			counts = append(counts, count)
+		}
+	}
+
+	if listOutput == "json" {
+		object := make(map[string]int, len(counts))
+		for _, count := range counts {
+			object[count.Tag] = count.Count
+		}
+		jsonData, err := json.MarshalIndent(object, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	}
+	if listOutput == "csv" {
+		if !listWithCounts {
+			sort.Slice(counts, func(i, j int) bool { return counts[i].Tag < counts[j].Tag })
+		}
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"tag", "count"})
+		for _, count := range counts {
+			w.Write([]string{count.Tag, fmt.Sprint(count.Count)})
+		}
+		w.Flush()
+		return w.Error()
+	}
+
+	if !listWithCounts {
+		sort.Slice(counts, func(i, j int) bool { return counts[i].Tag < counts[j].Tag })
+	}
+	for _, count := range counts {
+		tag := count.Tag
+		if key, value, ok := strings.Cut(tag, ":"); ok {
+			tag = colorize("36", key) + ":" + value
+		}
+		if listWithCounts {
+			fmt.Printf("%s\t%d\n", tag, count.Count)
+		} else {
+			fmt.Println(tag)
+		}
+	}
 	return nil
 }
+
+// filterMonitorsByDowntime fetches the active downtimes once and keeps the
+// monitors an active downtime silences (inDowntime) or those none does. The
+// downtimes silencing each kept monitor are returned by monitor ID.
+func filterMonitorsByDowntime(client *datadog.Client, monitors []datadog.Monitor, inDowntime bool) ([]datadog.Monitor, map[int][]datadog.Downtime, error) {
+	downtimes, err := client.ListDowntimes(true)
+	if err != nil {
+		return nil, nil, err
+	}
+	index := datadog.NewDowntimeIndex(downtimes, time.Now())
+	logVerbose("%d active downtime(s)", index.Len())
+
+	var kept []datadog.Monitor
+	silencing := make(map[int][]datadog.Downtime)
+	for _, monitor := range monitors {
+		matching := index.Matching(monitor)
+		if (len(matching) > 0) != inDowntime {
+			continue
+		}
+		kept = append(kept, monitor)
+		if len(matching) > 0 {
+			silencing[monitor.ID] = matching
+		}
+	}
+	return kept, silencing, nil
+}
+
+// downtimeEnd describes when a downtime ends
+func downtimeEnd(downtime datadog.Downtime) string {
+	if downtime.End == 0 {
+		return "no end"
+	}
+	return "until " + formatTime(time.Unix(downtime.End, 0))
+}
+
+// downtimeLine describes a downtime silencing a monitor, e.g.
+// "1002 until 2024-01-07 04:00:00 (scope: env:prd): deploy window"
+func downtimeLine(downtime datadog.Downtime) string {
+	line := fmt.Sprintf("%d %s (scope: %s)", downtime.ID, downtimeEnd(downtime), datadog.DowntimeScope(downtime))
+	if message := strings.TrimSpace(downtime.Message); message != "" {
+		line += ": " + truncateText(strings.Join(strings.Fields(message), " "), 80)
+	}
+	return line
+}
+
+// downtimeSummary describes the downtimes silencing a monitor in one
+// cell, e.g. "123 until 2024-01-07 04:00:00; 456 no end"
+func downtimeSummary(downtimes []datadog.Downtime) string {
+	parts := make([]string, len(downtimes))
+	for i, downtime := range downtimes {
+		parts[i] = fmt.Sprintf("%d %s", downtime.ID, downtimeEnd(downtime))
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadogtest"
+)
+
+func TestListDowntimeFilters(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddMonitor(datadog.Monitor{Name: "Checkout errors", Type: "query alert", Query: "avg(last_5m):avg:errors{service:checkout} > 1", Tags: []string{"service:checkout", "env:prd"}})
+	srv.AddMonitor(datadog.Monitor{Name: "Billing errors", Type: "query alert", Query: "avg(last_5m):avg:errors{service:billing} > 1", Tags: []string{"service:billing", "env:prd"}})
+	srv.AddDowntime(datadog.Downtime{Scope: []string{"env:prd"}, MonitorTags: []string{"service:checkout"}, Active: true})
+	// Scheduled for later, so it silences nothing yet
+	srv.AddDowntime(datadog.Downtime{Scope: []string{"*"}, MonitorTags: []string{"service:billing"}, Active: true, Start: time.Now().Add(time.Hour).Unix()})
+
+	res := runCLI(t, nil, "list", "--in-downtime")
+	if res.Err != nil {
+		t.Fatalf("list --in-downtime: %v\n%s", res.Err, res.Stderr)
+	}
+	if !strings.Contains(res.Stdout, "Checkout errors") || strings.Contains(res.Stdout, "Billing errors") {
+		t.Errorf("list --in-downtime:\n%s", res.Stdout)
+	}
+
+	res = runCLI(t, nil, "list", "--not-in-downtime")
+	if res.Err != nil {
+		t.Fatalf("list --not-in-downtime: %v\n%s", res.Err, res.Stderr)
+	}
+	if strings.Contains(res.Stdout, "Checkout errors") || !strings.Contains(res.Stdout, "Billing errors") {
+		t.Errorf("list --not-in-downtime:\n%s", res.Stdout)
+	}
+}
+
+func TestListByService(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddMonitor(datadog.Monitor{Name: "Checkout errors", Type: "query alert", Query: "avg(last_5m):avg:errors{service:checkout} > 1", Tags: []string{"service:checkout", "env:prd"}})
+	srv.AddMonitor(datadog.Monitor{Name: "Billing errors", Type: "query alert", Query: "avg(last_5m):avg:errors{service:billing} > 1", Tags: []string{"service:billing", "env:prd"}})
+
+	res := runCLI(t, nil, "list", "--service", "checkout")
+	if res.Err != nil {
+		t.Fatalf("list: %v\n%s", res.Err, res.Stderr)
+	}
+	if !strings.Contains(res.Stdout, "Checkout errors") || strings.Contains(res.Stdout, "Billing errors") {
+		t.Errorf("list --service checkout:\n%s", res.Stdout)
+	}
+	srv.AssertNoMutations(t)
+}
+
+// addMonitorFixture adds the monitors of a JSON fixture in testdata to srv
+func addMonitorFixture(t *testing.T, srv *datadogtest.Server, name string) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var monitors []datadog.Monitor
+	if err := json.Unmarshal(data, &monitors); err != nil {
+		t.Fatal(err)
+	}
+	for _, monitor := range monitors {
+		srv.AddMonitor(monitor)
+	}
+}
+
+func TestServicesNarrowing(t *testing.T) {
+	srv := newTestServer(t)
+	addMonitorFixture(t, srv, "services-monitors.json")
+	want := []string{"api errors", "worker lag", "shared queue"}
+
+	res := runCLI(t, nil, "list", "--services", "api, worker", "--env", "prd", "--verbose")
+	if res.Err != nil {
+		t.Fatalf("list: %v\n%s", res.Err, res.Stderr)
+	}
+	var listed []string
+	for _, line := range strings.Split(res.Stdout, "\n") {
+		if name, ok := strings.CutPrefix(line, "Name: "); ok {
+			listed = append(listed, name)
+		}
+	}
+	if !slices.Equal(listed, want) {
+		t.Errorf("listed %q, want %q", listed, want)
+	}
+	// monitor_tags can't OR values: the services are matched locally, the
+	// other filters by the API
+	for _, req := range srv.RequestsTo("GET", "/monitor") {
+		if tags := req.Query.Get("monitor_tags"); tags != "env:prd" {
+			t.Errorf("monitor_tags %q, want env:prd", tags)
+		}
+	}
+	if !strings.Contains(res.Stderr, "fetched 6 monitor(s) without a service filter, 3 belong to api, worker") {
+		t.Errorf("extra monitors fetched not reported:\n%s", res.Stderr)
+	}
+
+	// A single service is sent to the API
+	srv.ResetRequests()
+	if res := runCLI(t, nil, "list", "--services", "api", "--env", "prd"); res.Err != nil {
+		t.Fatalf("list: %v\n%s", res.Err, res.Stderr)
+	}
+	for _, req := range srv.RequestsTo("GET", "/monitor") {
+		if tags := req.Query.Get("monitor_tags"); tags != "service:api,env:prd" {
+			t.Errorf("monitor_tags %q, want service:api,env:prd", tags)
+		}
+	}
+
+	// Bulk commands act on the same monitors
+	srv.ResetRequests()
+	if res := runCLI(t, nil, "add-tags", "--yes", "--services", "api,worker", "--env", "prd", "--tag", "team:core"); res.Err != nil {
+		t.Fatalf("add-tags: %v\n%s", res.Err, res.Stderr)
+	}
+	if puts := srv.RequestsTo("PUT", "/monitor/*"); len(puts) != len(want) {
+		t.Errorf("add-tags updated %d monitor(s), want %d", len(puts), len(want))
+	}
+	if res := runCLI(t, nil, "delete-all", "--yes", "--services", "api,worker", "--env", "prd"); res.Err != nil {
+		t.Fatalf("delete-all: %v\n%s", res.Err, res.Stderr)
+	}
+	var left []string
+	for _, monitor := range srv.Monitors() {
+		left = append(left, monitor.Name)
+		if slices.Contains(monitor.Tags, "team:core") {
+			t.Errorf("%s was tagged", monitor.Name)
+		}
+	}
+	slices.Sort(left)
+	if wantLeft := []string{"api errors staging", "api-gateway errors", "host disk", "web latency"}; !slices.Equal(left, wantLeft) {
+		t.Errorf("monitors left %q, want %q", left, wantLeft)
+	}
+
+	for _, args := range [][]string{
+		{"list"},
+		{"add-tags", "--tag", "team:core"},
+		{"remove-tags", "--tag", "team:core"},
+		{"delete-all"},
+	} {
+		res := runCLI(t, nil, append(args, "--service", "api", "--services", "api,worker")...)
+		if res.Err == nil || !strings.Contains(res.Err.Error(), "cannot use --service together with --services") {
+			t.Errorf("%s with --service and --services: %v", args[0], res.Err)
+		}
+	}
+}
+
+func TestSortByAlertingSince(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "alerting-monitors.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var monitors []datadog.Monitor
+	if err := json.Unmarshal(data, &monitors); err != nil {
+		t.Fatal(err)
+	}
+
+	// Longest alerting first, those without a trigger time last by ID
+	sortByAlertingSince(monitors)
+	var ids []int
+	for _, monitor := range monitors {
+		ids = append(ids, monitor.ID)
+	}
+	if got := joinIDs(ids, " "); got != "2 1 3 4 5 6" {
+		t.Errorf("sorted IDs %s, want 2 1 3 4 5 6", got)
+	}
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, want := range []string{"2d 3h", "2h 12m", "15m", "unknown", "unknown", "unknown"} {
+		if got := alertingDuration(monitors[i], now); got != want {
+			t.Errorf("monitor %d alerting for %q, want %q", monitors[i].ID, got, want)
+		}
+	}
+	if got := strings.Join(alertingGroupNames(monitors[1]), ","); got != "env:prd,pod:a,env:prd,pod:b" {
+		t.Errorf("alerting groups of monitor 1: %s", got)
+	}
+}
+
+func TestListAlerting(t *testing.T) {
+	srv := newTestServer(t)
+	addMonitorFixture(t, srv, "alerting-monitors.json")
+
+	res := runCLI(t, nil, "list", "--alerting", "--env", "prd", "--show-groups", "--time-format", "utc")
+	if res.Err != nil {
+		t.Fatalf("list --alerting: %v\n%s", res.Err, res.Stderr)
+	}
+	for _, req := range srv.RequestsTo("GET", "/monitor") {
+		if got := req.Query.Get("group_states"); got != "alert" {
+			t.Errorf("monitors listed with group_states %q, want alert", got)
+		}
+	}
+	if !strings.Contains(res.Stdout, "4 monitor(s) alerting, longest first") {
+		t.Errorf("list --alerting did not count 4 monitors:\n%s", res.Stdout)
+	}
+	// The Warn monitor is listed for its alerting group, the OK and No Data
+	// monitors are not
+	var listed []string
+	for _, line := range strings.Split(res.Stdout, "\n") {
+		if name, ok := strings.CutPrefix(line, "ID "); ok {
+			listed = append(listed, name)
+		}
+	}
+	want := []string{"2: api latency", "1: checkout errors", "3: worker lag", "4: disk full"}
+	if !slices.Equal(listed, want) {
+		t.Errorf("listed %q, want %q", listed, want)
+	}
+	for _, line := range []string{
+		"alerting for", "(since 2024-04-29 08:30:00 UTC)",
+		"(since 2024-05-01 09:48:00 UTC)",
+		"Groups (2): env:prd,pod:a, env:prd,pod:b",
+		"Groups (1): queue:orders",
+		"alerting (trigger time unknown)",
+	} {
+		if !strings.Contains(res.Stdout, line) {
+			t.Errorf("list --alerting output has no %q:\n%s", line, res.Stdout)
+		}
+	}
+
+	res = runCLI(t, nil, "list", "--alerting", "--env", "prd", "--simple")
+	if res.Err != nil {
+		t.Fatalf("list --alerting --simple: %v\n%s", res.Err, res.Stderr)
+	}
+	lines := strings.Split(strings.TrimSuffix(res.Stdout, "\n"), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "2\t") || !strings.HasSuffix(lines[0], "\tapi latency") || lines[3] != "4\tunknown\tdisk full" {
+		t.Errorf("list --alerting --simple printed:\n%s", res.Stdout)
+	}
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--show-groups"}, "--show-groups requires --alerting"},
+		{[]string{"--alerting", "--status", "Warn"}, "--alerting cannot be combined with --status"},
+		{[]string{"--alerting", "--watch"}, "--alerting cannot be combined with --tags-only, --watch"},
+	} {
+		srv.ResetRequests()
+		res := runCLI(t, nil, append([]string{"list", "--env", "prd"}, tc.args...)...)
+		if res.Err == nil || !strings.Contains(res.Err.Error(), tc.want) {
+			t.Errorf("list %v = %v, want %q", tc.args, res.Err, tc.want)
+		}
+		if got := len(srv.Requests()); got != 0 {
+			t.Errorf("list %v made %d request(s)", tc.args, got)
+		}
+	}
+}
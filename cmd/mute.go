@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var muteCmd = &cobra.Command{
+	Use:   "mute",
+	Short: "Mute a monitor or some of its groups",
+	Long: `Mute a whole monitor, or only some of its groups with --scope (e.g., a single
+pod of a multi-alert monitor). Muting a scope that is already muted is a no-op.
+
+Examples:
+  mute --monitor-id 12345
+  mute --monitor-id 12345 --scope pod:checkout-7f9 --scope pod:checkout-8a1
+  mute --monitor-id 12345 --scope host:db-1 --duration 2h`,
+	RunE: runMute,
+}
+
+var (
+	muteMonitorID int
+	muteScopes    []string
+	muteDuration  string
+)
+
+func init() {
+	rootCmd.AddCommand(muteCmd)
+	muteCmd.Flags().IntVar(&muteMonitorID, "monitor-id", 0, "Monitor ID (required)")
+	muteCmd.MarkFlagRequired("monitor-id")
+	muteCmd.Flags().StringArrayVar(&muteScopes, "scope", []string{}, "Only mute groups matching this scope, e.g. pod:checkout-7f9 (can be used multiple times)")
+	muteCmd.Flags().StringVar(&muteDuration, "duration", "", "Unmute automatically after this long (e.g., 30m, 2h, 1d; default: indefinitely)")
+}
+
+// scopeLabel describes a mute scope for display
+func scopeLabel(scope string) string {
+	if scope == "" || scope == datadog.WholeMonitorScope {
+		return "all groups"
+	}
+	return scope
+}
+
+func runMute(cmd *cobra.Command, args []string) error {
+	var end time.Time
+	if muteDuration != "" {
+		duration, err := parseDuration(muteDuration)
+		if err != nil || duration <= 0 {
+			return fmt.Errorf("invalid --duration %q (a duration like 30m, 2h or 1d; leave it out to mute indefinitely)", muteDuration)
+		}
+		end = time.Now().Add(duration)
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	monitor, err := client.GetMonitor(muteMonitorID)
+	if err != nil {
+		reportMonitorError("getting monitor", err)
+		return err
+	}
+	out.Printf("🔍 Monitor %d: %s\n", monitor.ID, monitor.Name)
+
+	scopes := muteScopes
+	if len(scopes) == 0 {
+		scopes = []string{""}
+	}
+
+	var failed int
+	for _, scope := range scopes {
+		if datadog.IsScopeSilenced(*monitor, scope) {
+			out.Printf("⏭️  %s already muted (no-op)\n", scopeLabel(scope))
+			continue
+		}
+		if _, err := client.MuteMonitor(monitor.ID, scope, end); err != nil {
+			errOut.Printf("❌ Error muting %s: %v\n", scopeLabel(scope), err)
+			failed++
+			continue
+		}
+		if end.IsZero() {
+			out.Printf("🔇 Muted %s\n", scopeLabel(scope))
+		} else {
+			out.Printf("🔇 Muted %s %s\n", scopeLabel(scope), muteEndLabel(end))
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to mute %d scope(s)", failed)
+	}
+	return nil
+}
+
+// createMutedDefault is the --create-muted value when the flag has none
+const createMutedDefault = "24h"
+
+// createMutedIndefinite is the --create-muted value muting new monitors with
+// no expiry
+const createMutedIndefinite = "indefinite"
+
+// addCreateMutedFlag registers --create-muted[=duration] on a command that
+// creates monitors
+func addCreateMutedFlag(cmd *cobra.Command, value *string) {
+	cmd.Flags().StringVar(value, "create-muted", "", fmt.Sprintf("Create new monitors muted for this long (%s without a value, or %q); updated monitors are not muted", createMutedDefault, createMutedIndefinite))
+	cmd.Flags().Lookup("create-muted").NoOptDefVal = createMutedDefault
+}
+
+// parseCreateMuted parses a --create-muted value: how long after now new
+// monitors stay muted (e.g., 24h, 2d), or "indefinite". It returns when the
+// mute ends, zero for an indefinite mute.
+func parseCreateMuted(value string, now time.Time) (time.Time, error) {
+	if value == createMutedIndefinite {
+		return time.Time{}, nil
+	}
+	duration, err := parseDuration(value)
+	if err != nil || duration <= 0 {
+		return time.Time{}, fmt.Errorf("invalid --create-muted %q (a duration like 24h or 2d, or %q)", value, createMutedIndefinite)
+	}
+	return now.Add(duration), nil
+}
+
+// muteEndLabel describes when a mute ends, e.g. "until 2024-01-02 15:04:05"
+// or, with --time-format relative, "for another 2h 0m"
+func muteEndLabel(end time.Time) string {
+	if end.IsZero() {
+		return "indefinitely"
+	}
+	if timeFormat == timeFormatRelative {
+		return "for another " + formatDuration(time.Until(end))
+	}
+	return "until " + formatTime(end)
+}
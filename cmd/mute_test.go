@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+func TestParseCreateMuted(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		value string
+		want  time.Time
+	}{
+		{"24h", now.Add(24 * time.Hour)},
+		{"30m", now.Add(30 * time.Minute)},
+		{"2d", now.Add(48 * time.Hour)},
+		// An indefinite mute has no end
+		{"indefinite", time.Time{}},
+	} {
+		got, err := parseCreateMuted(tc.value, now)
+		if err != nil || !got.Equal(tc.want) {
+			t.Errorf("parseCreateMuted(%q) = %v, %v, want %v", tc.value, got, err, tc.want)
+		}
+	}
+	for _, value := range []string{"0h", "0d", "-1h", "forever", "Indefinite", ""} {
+		if _, err := parseCreateMuted(value, now); err == nil || !strings.HasPrefix(err.Error(), "invalid --create-muted") {
+			t.Errorf("parseCreateMuted(%q) = %v", value, err)
+		}
+	}
+}
+
+func TestTemplateCreateMuted(t *testing.T) {
+	srv := newTestServer(t)
+	spec := writeServiceSpec(t)
+	dir := filepath.Dir(spec)
+	// The error rate monitor exists already: updating it doesn't mute it
+	srv.AddMonitor(datadog.Monitor{Name: "Monitor checkout - Error Rate", Type: "query alert", Query: "sum(last_5m):sum:http.errors{service:checkout,env:prd}.as_count() > 10", Tags: []string{"service:checkout", "env:prd", "namespace:shop"}})
+
+	start := time.Now()
+	res := runCLI(t, nil, "template", "--template-dir", dir, "--service", "checkout", "--env", "prd", "--namespace", "shop", "--create-muted=2h")
+	if res.Err != nil {
+		t.Fatalf("template: %v\n%s", res.Err, res.Stderr)
+	}
+	for _, monitor := range srv.Monitors() {
+		scopes := datadog.SilencedScopes(monitor)
+		if monitor.ID == 1000 {
+			if len(scopes) != 0 {
+				t.Errorf("updated monitor muted: %v", scopes)
+			}
+			continue
+		}
+		if len(scopes) != 1 || scopes[0].Scope != "*" || scopes[0].End.Before(start.Add(2*time.Hour).Truncate(time.Second)) || scopes[0].End.After(time.Now().Add(2*time.Hour)) {
+			t.Errorf("created monitor %d muted %v, want the whole monitor for 2h", monitor.ID, scopes)
+		}
+	}
+	if !strings.Contains(res.Stdout, "Created Latency: Monitor ID 1001 (muted)") || !strings.Contains(res.Stdout, "1 new monitor(s) created muted until ") {
+		t.Errorf("mute not reported:\n%s", res.Stdout)
+	}
+
+	// Without a value, new monitors are muted for a day
+	srv = newTestServer(t)
+	if res := runCLI(t, nil, "template", "--template-dir", dir, "--service", "checkout", "--env", "prd", "--namespace", "shop", "--create-muted"); res.Err != nil {
+		t.Fatalf("template: %v\n%s", res.Err, res.Stderr)
+	}
+	for _, monitor := range srv.Monitors() {
+		if scopes := datadog.SilencedScopes(monitor); len(scopes) != 1 || scopes[0].End.Sub(start).Round(time.Hour) != 24*time.Hour {
+			t.Errorf("monitor %d muted %v", monitor.ID, scopes)
+		}
+	}
+}
+
+func TestMuteDuration(t *testing.T) {
+	srv := newTestServer(t)
+	monitor := srv.AddMonitor(datadog.Monitor{Name: "CPU", Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90"})
+	id := strconv.Itoa(monitor.ID)
+
+	// A mute that would already be over is rejected before any request
+	for _, duration := range []string{"0s", "0d", "-1h", "-2d", "soon"} {
+		res := runCLI(t, nil, "mute", "--monitor-id", id, "--duration", duration)
+		if res.Err == nil || !strings.HasPrefix(res.Err.Error(), "invalid --duration") {
+			t.Errorf("mute --duration %s = %v, want an invalid --duration error", duration, res.Err)
+		}
+	}
+	if requests := srv.Requests(); len(requests) != 0 {
+		t.Errorf("requests sent for an invalid duration: %v", requests)
+	}
+
+	start := time.Now()
+	if res := runCLI(t, nil, "mute", "--monitor-id", id, "--duration", "2h"); res.Err != nil {
+		t.Fatalf("mute: %v\n%s", res.Err, res.Stderr)
+	}
+	muted, _ := srv.Monitor(monitor.ID)
+	if scopes := datadog.SilencedScopes(muted); len(scopes) != 1 || scopes[0].End.Sub(start).Round(time.Hour) != 2*time.Hour {
+		t.Errorf("monitor muted %v, want the whole monitor for 2h", scopes)
+	}
+}
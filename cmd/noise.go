@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var noiseCmd = &cobra.Command{
+	Use:   "noise",
+	Short: "Alert fatigue reports from monitor event history",
+	Long:  `Report on how often monitors alert, from their alert events`,
+}
+
+var noiseReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Rank the noisiest monitors by how often they alerted",
+	Long: `Pull the alert events of the monitors matching the filters over --since and
+rank the noisiest: per monitor, the number of times it triggered, how many of
+those alerts recovered (cycles) and the mean time spent in alert. Each group
+of a multi-alert monitor triggers on its own; alerts still open count until
+now.
+
+Events are requested a day at a time, filtered by the --service, --env,
+--namespace and --filter-tags tags, and matched to the monitors by ID.
+Rate-limited requests are retried once the limit resets.
+
+Examples:
+  noise report
+  noise report --env prd --since 30d --top 20
+  noise report --service checkout --output json`,
+	RunE: runNoiseReport,
+}
+
+var (
+	noiseReportService    string
+	noiseReportEnv        string
+	noiseReportNamespace  string
+	noiseReportFilterTags string
+	noiseReportQuery      string
+	noiseReportSince      string
+	noiseReportTop        int
+	noiseReportOutput     string
+)
+
+func init() {
+	rootCmd.AddCommand(noiseCmd)
+	noiseCmd.AddCommand(noiseReportCmd)
+	noiseReportCmd.Flags().StringVar(&noiseReportService, "service", "", "Filter by service")
+	noiseReportCmd.Flags().StringVar(&noiseReportEnv, "env", "", "Filter by environment")
+	noiseReportCmd.Flags().StringVar(&noiseReportNamespace, "namespace", "", "Filter by namespace")
+	noiseReportCmd.Flags().StringVar(&noiseReportFilterTags, "filter-tags", "", "Filter by tags (comma-separated)")
+	noiseReportCmd.Flags().StringVar(&noiseReportQuery, "query", "", "Complex search query (e.g., service:(service1 OR service2))")
+	noiseReportCmd.Flags().StringVar(&noiseReportSince, "since", "14d", "How far back to count alerts (e.g., 24h, 14d, 2w)")
+	noiseReportCmd.Flags().IntVar(&noiseReportTop, "top", 10, "Number of monitors to show (0 for all)")
+	noiseReportCmd.Flags().StringVarP(&noiseReportOutput, "output", "o", "table", "Output format: table or json")
+}
+
+func runNoiseReport(cmd *cobra.Command, args []string) error {
+	if noiseReportOutput != "table" && noiseReportOutput != "json" {
+		return fmt.Errorf("invalid --output %q (must be table or json)", noiseReportOutput)
+	}
+	if noiseReportTop < 0 {
+		return fmt.Errorf("--top must be positive")
+	}
+	since, err := parseDuration(noiseReportSince)
+	if err != nil {
+		return fmt.Errorf("--since: %w", err)
+	}
+	if since <= 0 {
+		return fmt.Errorf("--since must be positive")
+	}
+
+	selector := monitorSelector{
+		Query:     noiseReportQuery,
+		Service:   noiseReportService,
+		Env:       noiseReportEnv,
+		Namespace: noiseReportNamespace,
+		Tags:      splitCommaList(noiseReportFilterTags),
+	}
+	if err := selector.validate(); err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	monitors, err := fetchMonitors(client, selector)
+	if err != nil {
+		errOut.Printf("❌ Error listing monitors: %v\n", err)
+		return err
+	}
+	if len(monitors) == 0 {
+		out.Println("ℹ️  No monitors found matching the specified filters")
+		return nil
+	}
+
+	// The monitor filters narrow the events down server-side; --query can't
+	eventTags := append([]string(nil), selector.Tags...)
+	for _, filter := range []struct{ key, value string }{{"service", noiseReportService}, {"env", noiseReportEnv}, {"namespace", noiseReportNamespace}} {
+		if filter.value != "" {
+			eventTags = append(eventTags, filter.key+":"+filter.value)
+		}
+	}
+	to := time.Now()
+	from := to.Add(-since)
+	logVerbose("listing alert events from %s to %s for %d monitor(s)", from.Format(time.RFC3339), to.Format(time.RFC3339), len(monitors))
+	events, err := client.ListEvents(datadog.EventQuery{Sources: []string{"alert"}, Tags: eventTags}, from, to)
+	if err != nil {
+		if isInterrupted(err) {
+			return interruption(err)
+		}
+		errOut.Printf("❌ Error listing events: %v\n", err)
+		return err
+	}
+	logVerbose("%d alert event(s) in the period", len(events))
+
+	report := datadog.BuildNoiseReport(monitors, events, to)
+	noisy := len(report)
+	if noiseReportTop > 0 && len(report) > noiseReportTop {
+		report = report[:noiseReportTop]
+	}
+
+	if noiseReportOutput == "json" {
+		jsonData, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	printNoiseReport(report, noisy, len(monitors))
+	return nil
+}
+
+// printNoiseReport prints the noisiest monitors as a table
+func printNoiseReport(report []datadog.NoiseSummary, noisy, total int) {
+	out.Printf("\n📣 Noisiest monitors over the last %s:\n", noiseReportSince)
+	out.Println(strings.Repeat("=", 80))
+	if len(report) == 0 {
+		out.Printf("✅ None of the %d monitor(s) alerted\n", total)
+		return
+	}
+
+	out.Printf("%4s %10s %9s %7s %14s  %s\n", "RANK", "ID", "TRIGGERS", "CYCLES", "MEAN IN ALERT", "NAME")
+	for i, summary := range report {
+		alerting := ""
+		if summary.Alerting {
+			alerting = " 🔴 alerting"
+		}
+		mean := formatDuration(time.Duration(summary.MeanTimeInAlertSeconds * float64(time.Second)))
+		out.Printf("%4d %10d %9d %7d %14s  %s%s\n", i+1, summary.MonitorID, summary.Triggers, summary.Cycles, mean, summary.Name, alerting)
+	}
+
+	out.Printf("\n📊 %d of %d monitor(s) alerted in the last %s\n", noisy, total, noiseReportSince)
+}
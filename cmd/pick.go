@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+// previewQueryLength is how much of a query monitor previews show
+const previewQueryLength = 100
+
+// printMonitorPreviewDetails prints the type, identity tags and truncated
+// query of a monitor under its preview line
+func printMonitorPreviewDetails(monitor datadog.Monitor, indent string) {
+	var identity []string
+	for _, key := range []string{"env", "service", "namespace"} {
+		for _, tag := range monitor.Tags {
+			if strings.HasPrefix(tag, key+":") {
+				identity = append(identity, tag)
+			}
+		}
+	}
+	if len(identity) == 0 {
+		identity = []string{"(no env/service/namespace tags)"}
+	}
+	out.Printf("%sType: %s | %s\n", indent, monitor.Type, strings.Join(identity, ", "))
+	out.Printf("%sQuery: %s\n", indent, truncateText(strings.Join(strings.Fields(monitor.Query), " "), previewQueryLength))
+}
+
+// truncateText cuts s to n characters, ending it with "…"
+func truncateText(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
+
+// pickMonitors lets the user exclude monitors from an operation on the
+// terminal: the monitors are listed with numbers, and each answer toggles the
+// exclusion of the numbers (or ranges such as 3-7) it lists, until an empty
+// answer. It returns the monitors left. The caller checks that stdin is a
+// terminal.
+func pickMonitors(monitors []datadog.Monitor, details bool) ([]datadog.Monitor, error) {
+	excluded := make(map[int]bool)
+	for {
+		out.Println()
+		for i, monitor := range monitors {
+			mark := "[x]"
+			if excluded[i] {
+				mark = "[ ]"
+			}
+			out.Printf("   %s %3d  ID %d: %s\n", mark, i+1, monitor.ID, monitor.Name)
+			if details {
+				printMonitorPreviewDetails(monitor, "              ")
+			}
+		}
+		out.Printf("\n%d of %d monitor(s) selected ([x]). ", len(monitors)-len(excluded), len(monitors))
+		out.Print("Numbers to toggle (e.g. 2,5-7), 'all' or 'none', Enter when done: ")
+
+		answer, err := readAnswer()
+		if err != nil {
+			return nil, err
+		}
+		answer = strings.TrimSpace(strings.ToLower(answer))
+		switch answer {
+		case "":
+			var picked []datadog.Monitor
+			for i, monitor := range monitors {
+				if !excluded[i] {
+					picked = append(picked, monitor)
+				}
+			}
+			return picked, nil
+		case "none":
+			for i := range monitors {
+				excluded[i] = true
+			}
+			continue
+		case "all":
+			excluded = make(map[int]bool)
+			continue
+		}
+
+		numbers, err := parseNumberSelection(answer, len(monitors))
+		if err != nil {
+			out.Printf("⚠️  %v\n", err)
+			continue
+		}
+		for _, n := range numbers {
+			if excluded[n-1] {
+				delete(excluded, n-1)
+			} else {
+				excluded[n-1] = true
+			}
+		}
+	}
+}
+
+// parseNumberSelection parses a comma-separated list of numbers and ranges
+// (e.g. "2,5-7") between 1 and max, returning the distinct numbers sorted
+func parseNumberSelection(value string, max int) ([]int, error) {
+	seen := make(map[int]bool)
+	for _, part := range splitCommaList(strings.ReplaceAll(value, " ", ",")) {
+		from, to, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", part)
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(to); err != nil || last < first {
+				return nil, fmt.Errorf("invalid range %q", part)
+			}
+		}
+		if first < 1 || last > max {
+			return nil, fmt.Errorf("%q is out of range (1-%d)", part, max)
+		}
+		for n := first; n <= last; n++ {
+			seen[n] = true
+		}
+	}
+
+	numbers := make([]int, 0, len(seen))
+	for n := range seen {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+	return numbers, nil
+}
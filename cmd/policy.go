@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Monitor configuration policies",
+	Long: `Work with the monitor configuration policies of the organization.
+
+Policies use the Datadog API v2, which is behind a feature flag: set
+DDMM_API_V2=1 or api_v2: true in the config file to enable it.`,
+}
+
+var policyListRemoteCmd = &cobra.Command{
+	Use:   "list-remote",
+	Short: "List the monitor configuration policies defined in Datadog",
+	Long: `List the monitor configuration policies defined in Datadog
+(GET /api/v2/monitor/policy).
+
+Requires the API v2 feature flag (DDMM_API_V2=1 or api_v2: true in the config file).
+
+Examples:
+  DDMM_API_V2=1 policy list-remote
+  DDMM_API_V2=1 policy list-remote --output json`,
+	RunE: runPolicyListRemote,
+}
+
+var policyListRemoteOutput string
+
+func init() {
+	rootCmd.AddCommand(policyCmd)
+	policyCmd.AddCommand(policyListRemoteCmd)
+	policyListRemoteCmd.Flags().StringVarP(&policyListRemoteOutput, "output", "o", "table", "Output format: table or json")
+}
+
+func runPolicyListRemote(cmd *cobra.Command, args []string) error {
+	if policyListRemoteOutput != "table" && policyListRemoteOutput != "json" {
+		return fmt.Errorf("invalid --output %q (must be table or json)", policyListRemoteOutput)
+	}
+	if err := requireAPIV2("policy list-remote"); err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	policies, err := client.ListMonitorPolicies()
+	if err != nil {
+		errOut.Printf("❌ Error listing monitor policies: %v\n", err)
+		return err
+	}
+
+	if policyListRemoteOutput == "json" {
+		jsonData, err := json.MarshalIndent(policies, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	if len(policies) == 0 {
+		out.Println("ℹ️  No monitor configuration policies found")
+		return nil
+	}
+
+	out.Printf("\n📜 Monitor configuration policies (%d):\n", len(policies))
+	out.Println(strings.Repeat("=", 80))
+	for _, policy := range policies {
+		required := "optional"
+		if policy.Required {
+			required = "required"
+		}
+		values := "any value"
+		if len(policy.ValidValues) > 0 {
+			values = strings.Join(policy.ValidValues, ", ")
+		}
+		out.Printf("   %s  %s tag %q (%s): %s\n", policy.ID, policy.PolicyType, policy.TagKey, required, values)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var presetsCmd = &cobra.Command{
+	Use:   "presets",
+	Short: "Monitor option presets",
+	Long:  `Work with the named option presets template and apply add with --preset`,
+}
+
+var presetsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the option presets and their option values",
+	Long: `List the option presets --preset can apply, built-in and from the presets
+section of the config file, with the options each one sets.
+
+--preset (template and apply, repeatable) merges the options of the presets
+into every rendered template: later presets win over earlier ones, and the
+template's own options win over every preset. Presets override the options
+--k8s-defaults injects, which are only set when still unset. Objects such as
+scheduling_options are merged key by key; other values are replaced.
+
+A preset in the config file replaces the built-in preset of the same name:
+
+  presets:
+    payments-hours:
+      description: Payments on-call hours
+      options:
+        renotify_interval: 60
+        scheduling_options:
+          custom_schedule:
+            recurrences:
+              - rrule: FREQ=DAILY;BYHOUR=8,9,10,11,12,13,14,15,16,17,18,19
+                timezone: Europe/Berlin
+
+Examples:
+  presets list
+  presets list --json`,
+	RunE: runPresetsList,
+}
+
+var presetsListJSON bool
+
+func init() {
+	rootCmd.AddCommand(presetsCmd)
+	presetsCmd.AddCommand(presetsListCmd)
+	presetsListCmd.Flags().BoolVar(&presetsListJSON, "json", false, "Print the presets as a JSON object of name to options")
+}
+
+// optionPresets returns the built-in presets and those of the config file
+func optionPresets() (map[string]datadog.OptionPreset, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	custom := make(map[string]datadog.OptionPreset, len(cfg.Presets))
+	for name, preset := range cfg.Presets {
+		custom[name] = datadog.OptionPreset{Description: preset.Description, Options: preset.Options}
+	}
+	return datadog.OptionPresets(custom), nil
+}
+
+// presetOptions returns the merged options of the --preset presets; nil
+// without presets
+func presetOptions(names []string) (map[string]interface{}, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	presets, err := optionPresets()
+	if err != nil {
+		return nil, err
+	}
+	return datadog.PresetOptions(names, presets)
+}
+
+// printPresets notes the --preset presets in a command header
+func printPresets(names []string) {
+	if len(names) > 0 {
+		out.Printf("🎛️  Presets: %s\n", strings.Join(names, ", "))
+	}
+}
+
+func runPresetsList(cmd *cobra.Command, args []string) error {
+	presets, err := optionPresets()
+	if err != nil {
+		return err
+	}
+	names := datadog.SortedPresetNames(presets)
+
+	if presetsListJSON {
+		options := make(map[string]map[string]interface{}, len(presets))
+		for name, preset := range presets {
+			options[name] = preset.Options
+		}
+		data, err := json.MarshalIndent(options, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	out.Printf("\n🎛️  %d option preset(s):\n", len(names))
+	for _, name := range names {
+		preset := presets[name]
+		source := "built-in"
+		if preset.Custom {
+			source = "config file"
+		}
+		out.Printf("\n%s (%s)\n", name, source)
+		if preset.Description != "" {
+			out.Printf("   %s\n", preset.Description)
+		}
+		data, err := json.MarshalIndent(preset.Options, "   ", "  ")
+		if err != nil {
+			return err
+		}
+		out.Printf("   %s\n", data)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestApplyPresets(t *testing.T) {
+	srv := newTestServer(t)
+	writeConfig(t, `presets:
+  team:
+    description: Team defaults
+    options:
+      renotify_interval: 120
+      evaluation_delay: 60
+`)
+	spec := writeServiceSpec(t)
+	// The template's own options win over every preset
+	editTemplateFile(t, spec, `"options": {"thresholds": {"critical": 2}}`, `"options": {"thresholds": {"critical": 2}, "renotify_interval": 15}`)
+
+	res := runCLI(t, nil, "apply", "-f", spec, "--preset", "low-noise", "--preset", "team")
+	if res.Err != nil {
+		t.Fatalf("apply: %v\n%s", res.Err, res.Stderr)
+	}
+	for _, tc := range []struct {
+		id                 int
+		renotify, evaluate float64
+	}{
+		// Later presets win: team over low-noise
+		{1000, 120, 60},
+		{1001, 15, 60},
+	} {
+		m, _ := srv.Monitor(tc.id)
+		if m.Options["renotify_interval"] != tc.renotify || m.Options["evaluation_delay"] != tc.evaluate {
+			t.Errorf("monitor %d options %v, want renotify_interval %v and evaluation_delay %v", tc.id, m.Options, tc.renotify, tc.evaluate)
+		}
+	}
+
+	if res := runCLI(t, nil, "apply", "-f", spec, "--preset", "quiet"); res.Err == nil {
+		t.Error("apply with an unknown preset succeeded")
+	}
+}
+
+func TestPresetsListJSON(t *testing.T) {
+	newTestServer(t)
+	writeConfig(t, `presets:
+  strict:
+    options:
+      notify_no_data: true
+      no_data_timeframe: 5
+`)
+	res := runCLI(t, nil, "presets", "list", "--json")
+	if res.Err != nil {
+		t.Fatalf("presets list: %v\n%s", res.Err, res.Stderr)
+	}
+	var presets map[string]map[string]interface{}
+	if err := json.Unmarshal([]byte(res.Stdout), &presets); err != nil {
+		t.Fatalf("%v\n%s", err, res.Stdout)
+	}
+	if len(presets) != 3 || presets["strict"]["no_data_timeframe"] != 5.0 || presets["low-noise"]["evaluation_delay"] != 1800.0 {
+		t.Errorf("presets %v", presets)
+	}
+}
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Display modes for human-facing output
+const (
+	// displayDecorated prints emoji and box drawing (the default on terminals)
+	displayDecorated = "decorated"
+	// displayPlain prints ASCII decorations only (the default when stdout is
+	// not a terminal, e.g. CI logs)
+	displayPlain = "plain"
+	// displayMachine prints one JSON object per message line
+	displayMachine = "machine"
+)
+
+// displayModes are the values of --display besides auto
+var displayModes = []string{displayDecorated, displayPlain, displayMachine}
+
+var (
+	// displayFlag is --display: auto or one of displayModes
+	displayFlag string
+	// display is the resolved display mode
+	display = displayDecorated
+)
+
+// printer prints human-facing messages to stdout or stderr in the display
+// mode. Commands print messages through out and errOut; data meant for other
+// programs (JSON, Terraform, Graphviz, --format output) is written with fmt
+// so no display mode alters it.
+type printer struct {
+	stderr bool
+}
+
+var (
+	out    = printer{}
+	errOut = printer{stderr: true}
+)
+
+// Printf prints a formatted message
+func (p printer) Printf(format string, args ...interface{}) {
+	p.write(fmt.Sprintf(format, args...))
+}
+
+// Println prints a message followed by a newline
+func (p printer) Println(args ...interface{}) {
+	p.write(fmt.Sprintln(args...))
+}
+
+// Print prints a message
+func (p printer) Print(args ...interface{}) {
+	p.write(fmt.Sprint(args...))
+}
+
+// writer returns the stream the printer writes to; os.Stdout is looked up on
+// every write since --output-file - redirects it
+func (p printer) writer() io.Writer {
+	if p.stderr {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+func (p printer) write(text string) {
+	switch display {
+	case displayPlain:
+		io.WriteString(p.writer(), plainText(text))
+	case displayMachine:
+		p.writeMachine(text)
+	default:
+		io.WriteString(p.writer(), text)
+	}
+}
+
+// machineMessage is one message line in machine mode
+type machineMessage struct {
+	Stream  string `json:"stream"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+// writeMachine prints every non-empty line of text as a machineMessage,
+// leaving out separator lines
+func (p printer) writeMachine(text string) {
+	stream := "stdout"
+	if p.stderr {
+		stream = "stderr"
+	}
+	encoder := json.NewEncoder(p.writer())
+	for _, line := range strings.Split(text, "\n") {
+		message := strings.TrimSpace(plainText(line))
+		if strings.Trim(message, "=-") == "" {
+			continue
+		}
+		// The level carries the status label
+		for _, d := range statusDecorations {
+			if strings.HasPrefix(message, d.plain) {
+				message = strings.TrimSpace(strings.TrimPrefix(message, d.plain))
+				break
+			}
+		}
+		encoder.Encode(machineMessage{Stream: stream, Level: messageLevel(line), Message: message})
+	}
+}
+
+// statusDecorations are the decorations that carry meaning, with their plain
+// replacement and machine mode level
+var statusDecorations = []struct {
+	emoji, plain, level string
+}{
+	{"❌", "[ERROR]", "error"},
+	{"⛔", "[ERROR]", "error"},
+	{"⚠️", "[WARN]", "warning"},
+	{"⚠", "[WARN]", "warning"},
+	{"✅", "[OK]", "success"},
+	{"ℹ️", "[INFO]", "info"},
+	{"💡", "[HINT]", "info"},
+}
+
+// plainReplacer maps box drawing and typographic symbols to ASCII
+var plainReplacer = strings.NewReplacer(
+	"├── ", "|-- ", "└── ", "`-- ", "│", "|", "─", "-", "═", "=",
+	"→", "->", "←", "<-", "×", "x", "…", "...", "•", "*", "≥", ">=", "≤", "<=",
+	"“", `"`, "”", `"`, "‘", "'", "’", "'",
+)
+
+// plainText replaces the decorations of text with ASCII: status emoji become
+// labels such as [ERROR], other emoji are dropped with the space after them.
+// Letters of any script (e.g. in monitor names) are kept.
+func plainText(text string) string {
+	for _, d := range statusDecorations {
+		// Emoji are followed by two spaces where they render narrow
+		text = strings.ReplaceAll(text, d.emoji+"  ", d.plain+" ")
+		text = strings.ReplaceAll(text, d.emoji, d.plain)
+	}
+	text = plainReplacer.Replace(text)
+
+	var b strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		if !isDecoration(runes[i]) {
+			b.WriteRune(runes[i])
+			continue
+		}
+		// Drop the emoji with its variation selectors and the spaces after it
+		for i+1 < len(runes) && (isDecoration(runes[i+1]) || runes[i+1] == ' ') {
+			i++
+		}
+	}
+	return b.String()
+}
+
+// isDecoration reports whether r is an emoji, pictograph or symbol rather
+// than text
+func isDecoration(r rune) bool {
+	switch {
+	case r == '\uFE0F' || r == '\u200D' || r == '\u20E3':
+		// Variation selectors and joiners of emoji sequences
+		return true
+	case r >= 0x1F000 && r <= 0x1FAFF:
+		return true
+	case r >= 0x2190 && r <= 0x2BFF:
+		// Arrows, technical symbols, box drawing, dingbats
+		return true
+	case r == 'ℹ':
+		return true
+	}
+	return r > unicode.MaxASCII && unicode.Is(unicode.So, r)
+}
+
+// messageLevel returns the machine mode level of a message line from its
+// status decoration
+func messageLevel(line string) string {
+	trimmed := strings.TrimSpace(line)
+	for _, d := range statusDecorations {
+		if strings.HasPrefix(trimmed, d.emoji) {
+			return d.level
+		}
+	}
+	return "info"
+}
+
+// resolveDisplay sets the display mode from --display, else DDMM_DISPLAY,
+// else decorated on terminals and plain otherwise
+func resolveDisplay() error {
+	mode := displayFlag
+	if mode == "" {
+		mode = os.Getenv("DDMM_DISPLAY")
+	}
+	if mode == "" || mode == "auto" {
+		display = displayPlain
+		if stdoutIsTerminal() {
+			display = displayDecorated
+		}
+		return nil
+	}
+	for _, valid := range displayModes {
+		if mode == valid {
+			display = mode
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid --display %q (must be auto, %s)", mode, strings.Join(displayModes, ", "))
+}
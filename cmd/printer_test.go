@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+// assertNoDecorations fails when text has a character other than ASCII or a
+// letter, i.e. an emoji, symbol or box drawing character
+func assertNoDecorations(t *testing.T, name, text string) {
+	t.Helper()
+	for i, r := range text {
+		if r > unicode.MaxASCII && !unicode.IsLetter(r) {
+			line := text[strings.LastIndex(text[:i], "\n")+1:]
+			line, _, _ = strings.Cut(line, "\n")
+			t.Errorf("%s: %q (%d bytes) in plain output: %q", name, r, utf8.RuneLen(r), line)
+			return
+		}
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"❌ Error: boom", "[ERROR] Error: boom"},
+		{"⚠️  Skipped", "[WARN] Skipped"},
+		{"✅ Created", "[OK] Created"},
+		{"ℹ️  No monitors found", "[INFO] No monitors found"},
+		{"💡 Use --yes", "[HINT] Use --yes"},
+		{"⛔ Refused", "[ERROR] Refused"},
+		// Other emoji are dropped with their spaces
+		{"🔍 Finding monitors", "Finding monitors"},
+		{"   🏷️  Tags: a, b", "   Tags: a, b"},
+		{"🕐 Recorded: now", "Recorded: now"},
+		{"📊 Found 3 monitor(s)", "Found 3 monitor(s)"},
+		{"Created (🔇 muted)", "Created (muted)"},
+		{"⏱️  Kubernetes defaults", "Kubernetes defaults"},
+		{"👨‍💻 Owner", "Owner"},
+		// Box drawing and typography become ASCII
+		{"├── a\n│   └── b", "|-- a\n|   `-- b"},
+		{"═══ ─── ", "=== --- "},
+		{"a → b ← c", "a -> b <- c"},
+		{"“quoted” ‘text’…", `"quoted" 'text'...`},
+		{"• item ≥ 2 ≤ 3 × 4", "* item >= 2 <= 3 x 4"},
+		// Letters of any script are text
+		{"Élan vital – 日本語 ✅", "Élan vital – 日本語 [OK]"},
+	} {
+		if got := plainText(tc.in); got != tc.want {
+			t.Errorf("plainText(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestPlainModeHasNoDecorations(t *testing.T) {
+	srv := newTestServer(t)
+	t.Setenv("DDMM_DISPLAY", "plain")
+	srv.AddMonitor(datadog.Monitor{Name: "cpu", Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90", Tags: []string{"env:prd", "owner:alice"}, OverallState: "Alert"})
+	spec := writeServiceSpec(t)
+
+	for _, args := range [][]string{
+		{"apply", "-f", spec},
+		{"apply", "-f", spec},
+		{"list", "--env", "prd"},
+		{"list", "--env", "prod"},
+		{"list", "--tags-only"},
+		{"describe", "--monitor-id", "1000"},
+		{"add-tags", "--yes", "--tag", "team:sre", "1000", "1001"},
+		{"remove-tags", "--yes", "--tag", "owner:*", "1000"},
+		{"delete-all", "--yes", "--services", "checkout,billing"},
+		{"whoami"},
+		{"list", "--status", "bogus"},
+	} {
+		res := runCLI(t, nil, args...)
+		name := strings.Join(args, " ")
+		assertNoDecorations(t, name, res.Stdout)
+		assertNoDecorations(t, name, res.Stderr)
+	}
+}
+
+func TestMachineMode(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddMonitor(datadog.Monitor{Name: "cpu", Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90", Tags: []string{"env:prd"}})
+
+	res := runCLI(t, nil, "--display", "machine", "add-tags", "--yes", "--tag", "team:sre", "1000")
+	if res.Err != nil {
+		t.Fatalf("add-tags: %v\n%s", res.Err, res.Stderr)
+	}
+	levels := make(map[string]int)
+	for _, line := range strings.Split(strings.TrimSpace(res.Stdout), "\n") {
+		var message machineMessage
+		if err := json.Unmarshal([]byte(line), &message); err != nil {
+			t.Fatalf("line %q is not a message: %v", line, err)
+		}
+		if message.Stream != "stdout" || message.Message == "" || strings.HasPrefix(message.Message, "[") {
+			t.Errorf("message %+v", message)
+		}
+		assertNoDecorations(t, "machine mode", message.Message)
+		levels[message.Level]++
+	}
+	if levels["success"] != 1 {
+		t.Errorf("levels %v, want one success", levels)
+	}
+}
+
+func TestResolveDisplay(t *testing.T) {
+	t.Cleanup(func() { display = displayDecorated; displayFlag = "" })
+	for _, tc := range []struct {
+		flag, env string
+		want      string
+	}{
+		{"plain", "machine", displayPlain},
+		{"", "machine", displayMachine},
+		{"decorated", "", displayDecorated},
+		// Tests don't run on a terminal
+		{"", "", displayPlain},
+		{"auto", "decorated", displayPlain},
+	} {
+		displayFlag = tc.flag
+		t.Setenv("DDMM_DISPLAY", tc.env)
+		if err := resolveDisplay(); err != nil || display != tc.want {
+			t.Errorf("--display %q, DDMM_DISPLAY %q: %s, %v, want %s", tc.flag, tc.env, display, err, tc.want)
+		}
+	}
+
+	displayFlag = "ascii"
+	if err := resolveDisplay(); err == nil || !strings.Contains(err.Error(), "must be auto, decorated, plain, machine") {
+		t.Errorf("--display ascii: %v", err)
+	}
+}
+
+func TestFormatTimeFormats(t *testing.T) {
+	t.Cleanup(func() { timeFormat = timeFormatLocal })
+	date := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		format string
+		when   time.Time
+		want   string
+	}{
+		{timeFormatUTC, date, "2024-05-01 12:30:00 UTC"},
+		{timeFormatLocal, date, date.Local().Format("2006-01-02 15:04:05")},
+		{timeFormatRelative, time.Now().Add(2*time.Hour + 30*time.Second), "in 2h 0m"},
+	} {
+		timeFormat = tc.format
+		if got := formatTime(tc.when); got != tc.want {
+			t.Errorf("formatTime with %s = %q, want %q", tc.format, got, tc.want)
+		}
+	}
+
+	res := runCLI(t, nil, "--time-format", "iso", "version")
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "invalid --time-format") {
+		t.Errorf("--time-format iso: %v", res.Err)
+	}
+}
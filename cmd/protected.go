@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var (
+	// includeProtected is --include-protected: let bulk changes touch
+	// protected monitors (only together with --yes)
+	includeProtected bool
+
+	// protectedSkipped are the protected monitors the bulk selections of the
+	// invocation left out, listed at the end by printProtectedSkipped
+	protectedSkipped []datadog.Monitor
+)
+
+// checkIncludeProtected refuses --include-protected without an explicit --yes,
+// so protected monitors are never changed by answering a prompt
+func checkIncludeProtected() error {
+	if includeProtected && !assumeYes {
+		return fmt.Errorf("--include-protected must be combined with --yes")
+	}
+	return nil
+}
+
+// protectedTagKey returns the tag key that protects monitors:
+// protected_tag_key from the config file, else protected
+func protectedTagKey() string {
+	if cfg, err := loadConfig(); err == nil && cfg.ProtectedTagKey != "" {
+		return cfg.ProtectedTagKey
+	}
+	return datadog.DefaultProtectedTagKey
+}
+
+// skipProtected leaves the protected monitors out of the selection of a bulk
+// change, recording them for the summary; with --include-protected they are
+// kept, with a warning. fetchMonitors calls it for Mutating selectors, so
+// every bulk command skips them the same way.
+func skipProtected(monitors []datadog.Monitor) ([]datadog.Monitor, error) {
+	key := protectedTagKey()
+	var kept, protected []datadog.Monitor
+	for _, monitor := range monitors {
+		if datadog.IsProtected(monitor, key) {
+			protected = append(protected, monitor)
+		} else {
+			kept = append(kept, monitor)
+		}
+	}
+	if len(protected) == 0 {
+		return monitors, nil
+	}
+	if includeProtected {
+		errOut.Printf("⚠️  Including %d protected monitor(s) (%s:true) because of --include-protected\n", len(protected), key)
+		return monitors, nil
+	}
+
+	seen := make(map[int]bool, len(protectedSkipped))
+	for _, monitor := range protectedSkipped {
+		seen[monitor.ID] = true
+	}
+	for _, monitor := range protected {
+		if !seen[monitor.ID] {
+			protectedSkipped = append(protectedSkipped, monitor)
+		}
+	}
+	out.Printf("🛡️  Skipping %d protected monitor(s) (%s:true)\n", len(protected), key)
+	return kept, nil
+}
+
+// printProtectedSkipped lists the protected monitors skipped by the
+// invocation, after the command's own summary
+func printProtectedSkipped() {
+	if len(protectedSkipped) == 0 {
+		return
+	}
+	out.Printf("\n🛡️  Protected (skipped): %d\n", len(protectedSkipped))
+	for _, monitor := range protectedSkipped {
+		out.Printf("   🔒 ID %d: %s\n", monitor.ID, monitor.Name)
+	}
+	out.Println("💡 Protected monitors are only changed with --include-protected --yes")
+}
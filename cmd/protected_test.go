@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadogtest"
+)
+
+// monitorMutations returns the changes the server received for a monitor
+func monitorMutations(srv *datadogtest.Server, id int) []string {
+	var mutations []string
+	path := "/monitor/" + strconv.Itoa(id)
+	for _, r := range srv.Mutations() {
+		if r.Path == path || strings.HasPrefix(r.Path, path+"/") || (r.Path == "/monitor/bulk_resolve" && strings.Contains(string(r.Body), strconv.Itoa(id))) {
+			mutations = append(mutations, r.String())
+		}
+	}
+	return mutations
+}
+
+func TestBulkCommandsSkipProtected(t *testing.T) {
+	namespaces := filepath.Join(t.TempDir(), "namespaces.txt")
+	if err := os.WriteFile(namespaces, []byte("live\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"add-tags", "--service", "api", "--tag", "team:sre"},
+		{"remove-tags", "--service", "api", "--tag", "owner:alice"},
+		{"delete-all", "--service", "api"},
+		{"disable", "--service", "api"},
+		{"edit-message", "--service", "api", "--append", "@pagerduty-api"},
+		{"resolve", "--service", "api"},
+		{"retag", "--service", "api", "--from", "owner:alice", "--to", "owner:bob"},
+		{"set-escalation", "--service", "api", "--set", "Still alerting"},
+		{"set-renotify", "--service", "api", "--interval", "30"},
+		{"cleanup", "namespaces", "--file", namespaces, "--grace-period", "0"},
+	} {
+		t.Run(args[0], func(t *testing.T) {
+			srv := newTestServer(t)
+			tags := []string{"service:api", "owner:alice", "namespace:gone"}
+			open := srv.AddMonitor(datadog.Monitor{Name: "cpu", Type: "metric alert", Query: "avg(last_5m):avg:cpu{service:api} > 90", Message: "CPU is high", Tags: tags, OverallState: "Alert"})
+			locked := srv.AddMonitor(datadog.Monitor{Name: "disk", Type: "metric alert", Query: "avg(last_5m):avg:disk{service:api} > 90", Message: "Disk is full", Tags: append([]string{"protected:true"}, tags...), OverallState: "Alert"})
+
+			res := runCLI(t, nil, append([]string{"--yes"}, args...)...)
+			if res.Err != nil {
+				t.Fatalf("%v\n%s%s", res.Err, res.Stdout, res.Stderr)
+			}
+			if got := monitorMutations(srv, open.ID); len(got) == 0 {
+				t.Errorf("monitor %d was not changed:\n%s", open.ID, res.Stdout)
+			}
+			if got := monitorMutations(srv, locked.ID); len(got) > 0 {
+				t.Errorf("protected monitor %d was changed: %v", locked.ID, got)
+			}
+			_, section, _ := strings.Cut(res.Stdout, "Protected (skipped): 1")
+			if !strings.Contains(section, "ID "+strconv.Itoa(locked.ID)+": disk") {
+				t.Errorf("protected monitor not listed as skipped:\n%s", res.Stdout)
+			}
+		})
+	}
+}
+
+func TestIncludeProtected(t *testing.T) {
+	srv := newTestServer(t)
+	locked := srv.AddMonitor(datadog.Monitor{Name: "disk", Type: "metric alert", Query: "avg(last_5m):avg:disk{*} > 90", Tags: []string{"service:api", "protected:true"}})
+
+	// Never by answering a prompt
+	res := runCLI(t, nil, "add-tags", "--include-protected", "--service", "api", "--tag", "team:sre")
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "--include-protected must be combined with --yes") {
+		t.Errorf("--include-protected without --yes = %v", res.Err)
+	}
+	srv.AssertNoMutations(t)
+
+	res = runCLI(t, nil, "add-tags", "--yes", "--include-protected", "--service", "api", "--tag", "team:sre")
+	if res.Err != nil {
+		t.Fatalf("add-tags --include-protected: %v\n%s", res.Err, res.Stderr)
+	}
+	srv.AssertRequestCount(t, 1, "PUT", "/monitor/"+strconv.Itoa(locked.ID))
+	if !strings.Contains(res.Stderr, "Including 1 protected monitor(s)") || strings.Contains(res.Stdout, "Protected (skipped)") {
+		t.Errorf("unexpected output:\n%s%s", res.Stdout, res.Stderr)
+	}
+}
+
+func TestProtectedTagKeyFromConfig(t *testing.T) {
+	srv := newTestServer(t)
+	writeConfig(t, "protected_tag_key: frozen\n")
+	frozen := srv.AddMonitor(datadog.Monitor{Name: "disk", Type: "metric alert", Query: "avg(last_5m):avg:disk{*} > 90", Tags: []string{"service:api", "frozen:TRUE"}})
+	open := srv.AddMonitor(datadog.Monitor{Name: "cpu", Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90", Tags: []string{"service:api", "protected:true"}})
+
+	res := runCLI(t, nil, "add-tags", "--yes", "--service", "api", "--tag", "team:sre")
+	if res.Err != nil {
+		t.Fatalf("add-tags: %v\n%s", res.Err, res.Stderr)
+	}
+	srv.AssertRequestCount(t, 0, "PUT", "/monitor/"+strconv.Itoa(frozen.ID))
+	srv.AssertRequestCount(t, 1, "PUT", "/monitor/"+strconv.Itoa(open.ID))
+}
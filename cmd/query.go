@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var queryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Work with monitor queries",
+	Long:  `Work with metric monitor queries`,
+}
+
+var queryPreviewCmd = &cobra.Command{
+	Use:   "preview",
+	Short: "Show what a metric monitor query returns right now",
+	Long: `Evaluate a metric monitor query against current data before creating the
+monitor. The alert wrapper (time aggregation, window and threshold) is stripped,
+the metrics query is run over the evaluation window, and the latest datapoint
+and window aggregate of each group are printed along with whether they would
+breach the threshold.
+
+Examples:
+  query preview --query 'avg(last_5m):avg:system.cpu.user{service:foo} > 80'
+  query preview --query 'max(last_15m):max:kubernetes.memory.usage{service:foo} by {pod} > 1e9'`,
+	RunE: runQueryPreview,
+}
+
+var queryPreviewQuery string
+
+func init() {
+	rootCmd.AddCommand(queryCmd)
+	queryCmd.AddCommand(queryPreviewCmd)
+	queryPreviewCmd.Flags().StringVar(&queryPreviewQuery, "query", "", "Metric monitor query (required)")
+	queryPreviewCmd.MarkFlagRequired("query")
+}
+
+func runQueryPreview(cmd *cobra.Command, args []string) error {
+	if _, err := datadog.ParseMetricMonitorQuery(queryPreviewQuery); err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	preview, err := client.PreviewMonitorQuery(queryPreviewQuery, time.Now())
+	if err != nil {
+		errOut.Printf("❌ Error querying metrics: %v\n", err)
+		return err
+	}
+
+	out.Println("\n🔎 Query preview:")
+	out.Printf("📈 Metrics query: %s\n", preview.Query.Metric)
+	out.Printf("🚨 Alert when: %s(%s) %s %s\n", preview.Query.Aggregation, preview.Query.Window, preview.Query.Comparator, formatValue(preview.Query.Threshold))
+	out.Println(strings.Repeat("=", 80))
+	printQueryPreview(preview, "")
+
+	return nil
+}
+
+// printQueryPreview prints the groups of a query preview and whether they would breach
+func printQueryPreview(preview *datadog.QueryPreview, indent string) {
+	if len(preview.Series) == 0 {
+		out.Printf("%s⚠️  No series returned for the last %s - check the metric name and scope\n", indent, strings.TrimPrefix(preview.Query.Window, "last_"))
+		return
+	}
+
+	for _, series := range preview.Series {
+		marker, verdict := "🟢", "OK"
+		if series.Breach {
+			marker, verdict = "🔴", "would ALERT"
+		}
+		out.Printf("%s%s %s: %s(%s) = %s → %s (latest %s at %s, %d point(s))\n",
+			indent, marker, series.Scope,
+			preview.Query.Aggregation, preview.Query.Window, formatValue(series.Value), verdict,
+			formatValue(series.Latest.Value), series.Latest.Timestamp.Format("15:04:05"), series.Points)
+	}
+	out.Printf("%s📊 %d group(s), %d would breach %s %s\n", indent, len(preview.Series), preview.Breaching(), preview.Query.Comparator, formatValue(preview.Query.Threshold))
+}
+
+// formatValue formats a metric value compactly
+func formatValue(value float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.4f", value), "0"), ".")
+}
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -10,14 +9,32 @@ import (
 )
 
 var removeTagsCmd = &cobra.Command{
-	Use:   "remove-tags",
+	Use:   "remove-tags [monitor-id...]",
 	Short: "Remove tags from monitors",
-	Long:  `Remove tags from a single monitor or multiple monitors matching filters`,
-	RunE:  runRemoveTags,
+	Long: `Remove tags from a single monitor or multiple monitors matching filters.
+
+--tag takes exact tags or glob patterns: * matches any run of characters and
+? a single one, so owner:* removes every owner tag whatever its value. With
+--regex, --tag values are regular expressions matched against the whole tag.
+Patterns show the concrete tags to remove from each monitor before asking for
+confirmation; monitors without a matching tag are left alone.
+
+Several monitor IDs (--monitor-id repeated or comma-separated, or given as
+arguments) are updated as a batch: one confirmation and one summary of the
+updated, unchanged and failed monitors.
+
+Examples:
+  remove-tags --monitor-id 12345 --tag team:old
+  remove-tags 12345 67890 --tag team:old
+  remove-tags --services api,worker --tag 'owner:*'
+  remove-tags --service myapp --tag 'owner:*'
+  remove-tags --env prd --tag '*-deprecated'
+  remove-tags --env prd --tag '(owner|contact):.*' --regex`,
+	RunE: runRemoveTags,
 }
 
 var (
-	removeTagsMonitorID      int
+	removeTagsMonitorIDs     []int
 	removeTagsService        string
 	removeTagsEnv            string
 	removeTagsNamespace      string
@@ -26,20 +43,27 @@ var (
 	removeTagsStatus         string
 	removeTagsFilterServices string
 	removeTagsTags           []string
+	removeTagsRollbackFile   string
+	removeTagsRegex          bool
+
+	removeTagsServices string
 )
 
 func init() {
 	rootCmd.AddCommand(removeTagsCmd)
-	removeTagsCmd.Flags().IntVar(&removeTagsMonitorID, "monitor-id", 0, "Monitor ID (for single monitor)")
+	removeTagsCmd.Flags().IntSliceVar(&removeTagsMonitorIDs, "monitor-id", nil, "Monitor ID (comma-separated or repeated for several; monitor IDs can also be given as arguments)")
 	removeTagsCmd.Flags().StringVar(&removeTagsService, "service", "", "Filter by service (for multiple monitors)")
 	removeTagsCmd.Flags().StringVar(&removeTagsEnv, "env", "", "Filter by environment (for multiple monitors)")
 	removeTagsCmd.Flags().StringVar(&removeTagsNamespace, "namespace", "", "Filter by namespace (for multiple monitors)")
 	removeTagsCmd.Flags().StringVar(&removeTagsFilterTags, "filter-tags", "", "Filter by tags (comma-separated, for multiple monitors)")
 	removeTagsCmd.Flags().StringVar(&removeTagsQuery, "query", "", "Complex search query (e.g., service:(service1 OR service2))")
-	removeTagsCmd.Flags().StringVar(&removeTagsStatus, "status", "", "Filter by monitor state (e.g., No Data, Alert, Warn, OK) when updating multiple monitors")
+	removeTagsCmd.Flags().StringVar(&removeTagsStatus, "status", "", "Filter by monitor state, comma-separated (OK, Alert, Warn, No Data, Unknown, Skipped, Ignored) when updating multiple monitors")
+	removeTagsCmd.Flags().StringVar(&removeTagsServices, "services", "", "Filter by any of these services (comma-separated, OR-matched; cannot be combined with --service)")
 	removeTagsCmd.Flags().StringVar(&removeTagsFilterServices, "filter-services", "", "Filter by multiple services (comma-separated, filters locally after query/tags)")
-	removeTagsCmd.Flags().StringArrayVar(&removeTagsTags, "tag", []string{}, "Tags to remove (required, can be used multiple times)")
+	removeTagsCmd.Flags().StringArrayVar(&removeTagsTags, "tag", []string{}, "Tags to remove, or glob patterns such as 'owner:*' (required, can be used multiple times)")
+	removeTagsCmd.Flags().BoolVar(&removeTagsRegex, "regex", false, "Treat --tag values as regular expressions matched against the whole tag")
 	removeTagsCmd.MarkFlagRequired("tag")
+	removeTagsCmd.Flags().StringVar(&removeTagsRollbackFile, "rollback-file", "", "Write the tags of each updated monitor before and after the change to this file (undo with: rollback --file)")
 }
 
 func runRemoveTags(cmd *cobra.Command, args []string) error {
@@ -47,291 +71,204 @@ func runRemoveTags(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("at least one --tag is required")
 	}
 
-	// Validate: either monitor-id or filters must be provided
-	if removeTagsMonitorID == 0 && removeTagsService == "" && removeTagsEnv == "" && removeTagsNamespace == "" && removeTagsFilterTags == "" && removeTagsQuery == "" {
-		return fmt.Errorf("either --monitor-id or filter flags (--service, --env, --namespace, --filter-tags, --query) must be provided")
+	monitorIDs, err := monitorIDArgs(removeTagsMonitorIDs, args)
+	if err != nil {
+		return err
 	}
 
-	// Cannot use --query together with other filter flags
-	if removeTagsQuery != "" && (removeTagsService != "" || removeTagsEnv != "" || removeTagsNamespace != "" || removeTagsFilterTags != "") {
-		return fmt.Errorf("cannot use --query together with other filter flags (--service, --env, --namespace, --filter-tags)")
+	// Validate: either monitor IDs or filters must be provided
+	if len(monitorIDs) == 0 && removeTagsService == "" && removeTagsServices == "" && removeTagsEnv == "" && removeTagsNamespace == "" && removeTagsFilterTags == "" && removeTagsQuery == "" {
+		return fmt.Errorf("either monitor IDs (--monitor-id or arguments) or filter flags (--service, --services, --env, --namespace, --filter-tags, --query) must be provided")
 	}
 
-	// Cannot use both monitor-id and filters
-	if removeTagsMonitorID > 0 && (removeTagsService != "" || removeTagsEnv != "" || removeTagsNamespace != "" || removeTagsFilterTags != "" || removeTagsQuery != "" || removeTagsStatus != "") {
-		return fmt.Errorf("cannot use --monitor-id together with filter flags")
+	// Cannot use both monitor IDs and filters
+	if len(monitorIDs) > 0 && (removeTagsService != "" || removeTagsServices != "" || removeTagsEnv != "" || removeTagsNamespace != "" || removeTagsFilterTags != "" || removeTagsQuery != "" || removeTagsStatus != "") {
+		return fmt.Errorf("cannot use monitor IDs together with filter flags")
 	}
 
 	// Cannot use --query together with other filter flags
-	if removeTagsQuery != "" && (removeTagsService != "" || removeTagsEnv != "" || removeTagsNamespace != "" || removeTagsFilterTags != "") {
-		return fmt.Errorf("cannot use --query together with other filter flags (--service, --env, --namespace, --filter-tags)")
+	if removeTagsQuery != "" && (removeTagsService != "" || removeTagsServices != "" || removeTagsEnv != "" || removeTagsNamespace != "" || removeTagsFilterTags != "") {
+		return fmt.Errorf("cannot use --query together with other filter flags (--service, --services, --env, --namespace, --filter-tags)")
+	}
+	if removeTagsService != "" && removeTagsServices != "" {
+		return fmt.Errorf("cannot use --service together with --services")
+	}
+	if _, err := parseStatusFlag(removeTagsStatus); err != nil {
+		return err
+	}
+
+	matcher, err := datadog.NewTagMatcher(removeTagsTags, removeTagsRegex)
+	if err != nil {
+		return err
 	}
 
-	client, err := datadog.NewClient()
+	client, err := newClient()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		errOut.Printf("❌ Error: %v\n", err)
 		return err
 	}
 
-	if removeTagsMonitorID > 0 {
+	if len(monitorIDs) == 1 {
 		// Single monitor
-		updated, err := client.RemoveTagsFromMonitor(removeTagsMonitorID, removeTagsTags)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "❌ Error removing tags: %v\n", err)
-			return err
+		monitorID := monitorIDs[0]
+		var before *datadog.Monitor
+		if removeTagsRollbackFile != "" || matcher.HasPatterns() {
+			before, err = client.GetMonitor(monitorID)
+			if err != nil {
+				reportMonitorError("getting monitor", err)
+				return err
+			}
 		}
-
-		fmt.Printf("✅ Tags removed from monitor %d\n", removeTagsMonitorID)
-		fmt.Printf("Monitor: %s\n", updated.Name)
-		fmt.Printf("Tags: %s\n", strings.Join(updated.Tags, ", "))
-	} else if removeTagsQuery != "" {
-		// Use query to find monitors
-		fmt.Println("\n🔍 Finding monitors with query:")
-		fmt.Printf("🔎 Query: %s\n", removeTagsQuery)
-		if removeTagsStatus != "" {
-			fmt.Printf("🚦 Status: %s\n", removeTagsStatus)
+		if matcher.HasPatterns() {
+			matched := matcher.Matching(before.Tags)
+			if len(matched) == 0 {
+				out.Printf("ℹ️  No tags of monitor %d match %s\n", monitorID, strings.Join(removeTagsTags, ", "))
+				return nil
+			}
+			out.Printf("🏷️  Removing: %s\n", strings.Join(matched, ", "))
 		}
-		fmt.Println(strings.Repeat("=", 80))
 
-		monitors, err := client.ListMonitors(nil, removeTagsQuery)
+		updated, changed, err := client.RemoveMatchingTags(monitorID, matcher)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "❌ Error listing monitors: %v\n", err)
+			reportMonitorError("removing tags", err)
 			return err
 		}
-
-		if removeTagsStatus != "" {
-			monitors = filterMonitorsByState(monitors, removeTagsStatus)
-		}
-
-		if removeTagsFilterServices != "" {
-			services := strings.Split(removeTagsFilterServices, ",")
-			for i := range services {
-				services[i] = strings.TrimSpace(services[i])
-			}
-			monitors = filterMonitorsByServices(monitors, services)
-		}
-
-		if len(monitors) == 0 {
-			fmt.Println("ℹ️  No monitors found matching the specified query/status/filters")
+		if !changed {
+			out.Printf("ℹ️  Monitor %d has none of %s, left unchanged\n", monitorID, strings.Join(removeTagsTags, ", "))
 			return nil
 		}
 
-		fmt.Printf("📊 Found %d monitor(s) matching the query\n\n", len(monitors))
-
-		// Remove tags from each monitor
-		var results []map[string]interface{}
-		for _, monitor := range monitors {
-			updated, err := client.RemoveTagsFromMonitor(monitor.ID, removeTagsTags)
-			if err != nil {
-				results = append(results, map[string]interface{}{
-					"id":     monitor.ID,
-					"name":   monitor.Name,
-					"status": fmt.Sprintf("failed: %v", err),
-				})
-			} else {
-				results = append(results, map[string]interface{}{
-					"id":     updated.ID,
-					"name":   updated.Name,
-					"status": "updated",
-					"tags":   updated.Tags,
-				})
-			}
+		out.Printf("✅ Tags removed from monitor %d\n", monitorID)
+		out.Printf("Monitor: %s\n", updated.Name)
+		out.Printf("Tags: %s\n", strings.Join(updated.Tags, ", "))
+		if removeTagsRollbackFile != "" {
+			return writeTagRollback(removeTagsRollbackFile, "remove-tags", removeTagsTags, []datadog.TagUpdateResult{datadog.NewTagUpdateResult(*before, updated, true, nil)})
 		}
+		return nil
+	}
 
-		var successful []map[string]interface{}
-		var failed []map[string]interface{}
-
-		for _, result := range results {
-			if status, ok := result["status"].(string); ok && status == "updated" {
-				successful = append(successful, result)
-			} else {
-				failed = append(failed, result)
-			}
-		}
-
-		fmt.Printf("\n📊 Results:\n")
-		fmt.Printf("✅ Successfully updated: %d\n", len(successful))
-		fmt.Printf("❌ Failed: %d\n", len(failed))
-
-		if len(successful) > 0 {
-			fmt.Println("\n✅ Successfully updated monitors:")
-			for _, result := range successful {
-				id, _ := result["id"].(int)
-				name, _ := result["name"].(string)
-				var tags []string
-				if tagsInterface, ok := result["tags"].([]interface{}); ok {
-					for _, tag := range tagsInterface {
-						if tagStr, ok := tag.(string); ok {
-							tags = append(tags, tagStr)
-						}
-					}
-				} else if tagsStr, ok := result["tags"].([]string); ok {
-					tags = tagsStr
-				}
-				fmt.Printf("   ✅ ID %d: %s\n", id, name)
-				if len(tags) > 0 {
-					fmt.Printf("      Tags: %s\n", strings.Join(tags, ", "))
-				}
-			}
+	// Several monitor IDs are updated as a batch, like the monitors matching
+	// filters; the IDs that can't be fetched are counted in the summary
+	var monitors []datadog.Monitor
+	var fetchFailed []datadog.TagUpdateResult
+	if len(monitorIDs) > 1 {
+		out.Printf("\n🔍 Fetching %d monitor(s) by ID\n", len(monitorIDs))
+		out.Println(strings.Repeat("=", 80))
+		monitors, fetchFailed, err = fetchMonitorsByID(client, monitorIDs)
+		if err != nil {
+			printInterrupted(err, len(monitors)+len(fetchFailed), len(monitorIDs), "monitor(s)")
+			return err
 		}
-
-		if len(failed) > 0 {
-			fmt.Println("\n❌ Failed to update monitors:")
-			for _, result := range failed {
-				id, _ := result["id"].(int)
-				name, _ := result["name"].(string)
-				status, _ := result["status"].(string)
-				fmt.Printf("   ⚠️  ID %d: %s - %s\n", id, name, status)
+		if len(monitors) == 0 {
+			if len(fetchFailed) > 0 {
+				printTagUpdateResults(fetchFailed)
+			} else {
+				out.Println("ℹ️  No monitors left to update")
 			}
+			return nil
 		}
+		out.Printf("📊 Found %d of %d monitor(s)\n", len(monitors), len(monitorIDs))
 	} else {
-		// Multiple monitors
-		fmt.Println("\n🔍 Finding monitors to update with filters:")
-		if removeTagsService != "" {
-			fmt.Printf("📦 Service: %s\n", removeTagsService)
-		}
-		if removeTagsEnv != "" {
-			fmt.Printf("🌍 Environment: %s\n", removeTagsEnv)
-		}
-		if removeTagsNamespace != "" {
-			fmt.Printf("🏷️  Namespace: %s\n", removeTagsNamespace)
-		}
-		if removeTagsStatus != "" {
-			fmt.Printf("🚦 Status: %s\n", removeTagsStatus)
-		}
-		if removeTagsFilterServices != "" {
-			fmt.Printf("🔍 Filter Services: %s\n", removeTagsFilterServices)
-		}
-
-		var filterTags []string
-		if removeTagsFilterTags != "" {
-			filterTags = strings.Split(removeTagsFilterTags, ",")
-			for i := range filterTags {
-				filterTags[i] = strings.TrimSpace(filterTags[i])
-			}
-			if len(filterTags) > 0 {
-				fmt.Printf("🏷️  Filter Tags: %s\n", strings.Join(filterTags, ", "))
-			}
+		selector := monitorSelector{
+			Query:          removeTagsQuery,
+			Service:        removeTagsService,
+			Services:       splitCommaList(removeTagsServices),
+			Env:            removeTagsEnv,
+			Namespace:      removeTagsNamespace,
+			Tags:           splitCommaList(removeTagsFilterTags),
+			Status:         removeTagsStatus,
+			FilterServices: removeTagsFilterServices,
+			Mutating:       true,
 		}
-		fmt.Println(strings.Repeat("=", 80))
 
-		var results []map[string]interface{}
-		if removeTagsStatus == "" && removeTagsFilterServices == "" {
-			// Keep existing behavior (more efficient) when status/filter-services filter is not requested
-			results, err = client.RemoveTagsFromMonitors(removeTagsService, removeTagsEnv, removeTagsNamespace, filterTags, removeTagsTags)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "❌ Error removing tags: %v\n", err)
-				return err
-			}
+		if removeTagsQuery != "" {
+			out.Println("\n🔍 Finding monitors with query:")
+			out.Printf("🔎 Query: %s\n", removeTagsQuery)
 		} else {
-			// When filtering by status or filter-services, we need to list and filter locally
-			// Check if filterTags contains wildcards - if so, use as query instead
-			var monitors []datadog.Monitor
-			var err error
-			if len(filterTags) > 0 && (strings.Contains(filterTags[0], "*") || strings.Contains(filterTags[0], "?")) {
-				// Wildcard pattern - use as query
-				monitors, err = client.ListMonitors(nil, filterTags[0])
-			} else {
-				// Exact tags - use as tag filter
-				monitors, err = client.ListMonitors(filterTags, "")
+			out.Println("\n🔍 Finding monitors to update with filters:")
+			if removeTagsService != "" {
+				out.Printf("📦 Service: %s\n", removeTagsService)
 			}
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "❌ Error listing monitors: %v\n", err)
-				return err
+			if len(selector.Services) > 0 {
+				out.Printf("📦 Services: %s\n", strings.Join(selector.Services, ", "))
 			}
-
-			monitors = filterMonitorsByServiceEnvNamespace(monitors, removeTagsService, removeTagsEnv, removeTagsNamespace)
-
-			if removeTagsFilterServices != "" {
-				services := strings.Split(removeTagsFilterServices, ",")
-				for i := range services {
-					services[i] = strings.TrimSpace(services[i])
-				}
-				monitors = filterMonitorsByServices(monitors, services)
+			if removeTagsEnv != "" {
+				out.Printf("🌍 Environment: %s\n", removeTagsEnv)
 			}
-
-			if removeTagsStatus != "" {
-				monitors = filterMonitorsByState(monitors, removeTagsStatus)
+			if removeTagsNamespace != "" {
+				out.Printf("🏷️  Namespace: %s\n", removeTagsNamespace)
 			}
-
-			if len(monitors) == 0 {
-				fmt.Println("ℹ️  No monitors found matching the specified filters")
-				return nil
+			if len(selector.Tags) > 0 {
+				out.Printf("🏷️  Filter Tags: %s\n", strings.Join(selector.Tags, ", "))
 			}
+		}
+		if removeTagsStatus != "" {
+			out.Printf("🚦 Status: %s\n", removeTagsStatus)
+		}
+		if removeTagsFilterServices != "" {
+			out.Printf("🔍 Filter Services: %s\n", removeTagsFilterServices)
+		}
+		out.Println(strings.Repeat("=", 80))
 
-			fmt.Printf("📊 Found %d monitor(s) matching the filters\n\n", len(monitors))
-
-			for _, monitor := range monitors {
-				updated, err := client.RemoveTagsFromMonitor(monitor.ID, removeTagsTags)
-				if err != nil {
-					results = append(results, map[string]interface{}{
-						"id":     monitor.ID,
-						"name":   monitor.Name,
-						"status": fmt.Sprintf("failed: %v", err),
-					})
-				} else {
-					results = append(results, map[string]interface{}{
-						"id":     updated.ID,
-						"name":   updated.Name,
-						"status": "updated",
-						"tags":   updated.Tags,
-					})
-				}
-			}
+		monitors, err = fetchMonitors(client, selector)
+		if err != nil {
+			errOut.Printf("❌ Error listing monitors: %v\n", err)
+			return err
 		}
 
-		if len(results) == 0 {
-			fmt.Println("ℹ️  No monitors found matching the specified filters")
+		if len(monitors) == 0 {
+			out.Println("ℹ️  No monitors found matching the specified filters")
 			return nil
 		}
 
-		var successful []map[string]interface{}
-		var failed []map[string]interface{}
+		out.Printf("📊 Found %d monitor(s) matching the filters\n", len(monitors))
+	}
 
-		for _, result := range results {
-			if status, ok := result["status"].(string); ok && status == "updated" {
-				successful = append(successful, result)
-			} else {
-				failed = append(failed, result)
-			}
+	// Preview the monitors affected, with the concrete tags each pattern
+	// resolves to, and skip monitors without any of the tags
+	var matching []datadog.Monitor
+	out.Println("\n🏷️  Tags to remove:")
+	for _, monitor := range monitors {
+		matched := matcher.Matching(monitor.Tags)
+		if len(matched) == 0 {
+			continue
 		}
+		matching = append(matching, monitor)
+		out.Printf("   ID %d: %s\n      - %s\n", monitor.ID, monitor.Name, strings.Join(matched, ", "))
+	}
+	if len(matching) == 0 {
+		out.Printf("ℹ️  No tags match %s on the %d monitor(s)\n", strings.Join(removeTagsTags, ", "), len(monitors))
+		if len(fetchFailed) > 0 {
+			printTagUpdateResults(fetchFailed)
+		}
+		return nil
+	}
+	if skipped := len(monitors) - len(matching); skipped > 0 {
+		out.Printf("⏭️  %d monitor(s) without a matching tag are left alone\n", skipped)
+	}
+	monitors = matching
 
-		fmt.Printf("\n📊 Results:\n")
-		fmt.Printf("✅ Successfully updated: %d\n", len(successful))
-		fmt.Printf("❌ Failed: %d\n", len(failed))
+	confirmed, err := confirm(len(monitors), "remove tags from", monitorSample(monitors))
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+	if !confirmed {
+		out.Println("❌ Update cancelled")
+		return nil
+	}
 
-		if len(successful) > 0 {
-			fmt.Println("\n✅ Successfully updated monitors:")
-			for _, result := range successful {
-				id, _ := result["id"].(int)
-				name, _ := result["name"].(string)
-				var tags []string
-				if tagsInterface, ok := result["tags"].([]interface{}); ok {
-					for _, tag := range tagsInterface {
-						if tagStr, ok := tag.(string); ok {
-							tags = append(tags, tagStr)
-						}
-					}
-				} else if tagsStr, ok := result["tags"].([]string); ok {
-					tags = tagsStr
-				}
-				fmt.Printf("   ✅ ID %d: %s\n", id, name)
-				if len(tags) > 0 {
-					fmt.Printf("      Tags: %s\n", strings.Join(tags, ", "))
-				}
-			}
-		}
+	results, err := updateTagsOnMonitors(monitors, func(monitorID int) (*datadog.Monitor, bool, error) {
+		return client.RemoveMatchingTags(monitorID, matcher)
+	})
+	printInterrupted(err, len(results), len(monitors), "monitor(s)")
+	printTagUpdateResults(append(fetchFailed, results...))
 
-		if len(failed) > 0 {
-			fmt.Println("\n❌ Failed to update monitors:")
-			for _, result := range failed {
-				id, _ := result["id"].(int)
-				name, _ := result["name"].(string)
-				status, _ := result["status"].(string)
-				fmt.Printf("   ⚠️  ID %d: %s - %s\n", id, name, status)
-			}
+	if removeTagsRollbackFile != "" {
+		if rollbackErr := writeTagRollback(removeTagsRollbackFile, "remove-tags", removeTagsTags, results); rollbackErr != nil && err == nil {
+			err = rollbackErr
 		}
 	}
 
-	return nil
+	return err
 }
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+func TestRemoveTagsPatterns(t *testing.T) {
+	monitors := []string{"env:prd owner:alice team:sre", "env:prd owner:bob", "env:prd contact:carol"}
+	for _, tc := range []struct {
+		name string
+		args []string
+		// want are the tags left on each monitor
+		want []string
+	}{
+		// Plain tags are removed exactly, as before patterns existed
+		{"plain tag", []string{"--tag", "owner:alice"}, []string{"env:prd team:sre", "env:prd owner:bob", "env:prd contact:carol"}},
+		{"plain tag as a regex", []string{"--tag", "owner:bob", "--regex"}, []string{"env:prd owner:alice team:sre", "env:prd", "env:prd contact:carol"}},
+		{"glob", []string{"--tag", "owner:*"}, []string{"env:prd team:sre", "env:prd", "env:prd contact:carol"}},
+		{"regex", []string{"--tag", "(owner|contact):.*", "--regex"}, []string{"env:prd team:sre", "env:prd", "env:prd"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := newTestServer(t)
+			var ids []int
+			for _, tags := range monitors {
+				ids = append(ids, srv.AddMonitor(datadog.Monitor{Name: "cpu " + tags, Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90", Tags: strings.Fields(tags)}).ID)
+			}
+
+			res := runCLI(t, nil, append([]string{"remove-tags", "--yes", "--env", "prd"}, tc.args...)...)
+			if res.Err != nil {
+				t.Fatalf("remove-tags: %v\n%s", res.Err, res.Stderr)
+			}
+			for i, id := range ids {
+				m, _ := srv.Monitor(id)
+				if got := strings.Join(m.Tags, " "); got != tc.want[i] {
+					t.Errorf("monitor %d has tags %q, want %q", id, got, tc.want[i])
+				}
+				// Monitors without a matching tag are left alone
+				if tc.want[i] == monitors[i] {
+					srv.AssertRequestCount(t, 0, "PUT", "/monitor/"+strconv.Itoa(id))
+				}
+			}
+		})
+	}
+}
+
+func TestRemoveTagsPatternPreview(t *testing.T) {
+	srv := newTestServer(t)
+	monitor := srv.AddMonitor(datadog.Monitor{Name: "cpu", Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90", Tags: []string{"owner:alice", "owner:bob", "env:prd"}})
+	id := strconv.Itoa(monitor.ID)
+
+	// The concrete tags a pattern resolves to are shown
+	res := runCLI(t, nil, "remove-tags", "--yes", "--tag", "owner:*", id)
+	if res.Err != nil {
+		t.Fatalf("remove-tags: %v\n%s", res.Err, res.Stderr)
+	}
+	if !strings.Contains(res.Stdout, "Removing: owner:alice, owner:bob") {
+		t.Errorf("matched tags not shown:\n%s", res.Stdout)
+	}
+	if m, _ := srv.Monitor(monitor.ID); !slices.Equal(m.Tags, []string{"env:prd"}) {
+		t.Errorf("tags %v, want [env:prd]", m.Tags)
+	}
+
+	srv.ResetRequests()
+	res = runCLI(t, nil, "remove-tags", "--yes", "--tag", "owner:*", id)
+	if res.Err != nil {
+		t.Fatalf("remove-tags: %v\n%s", res.Err, res.Stderr)
+	}
+	srv.AssertNoMutations(t)
+	if !strings.Contains(res.Stdout, "No tags of monitor "+id+" match owner:*") {
+		t.Errorf("unexpected output:\n%s", res.Stdout)
+	}
+
+	// An invalid regex fails before anything is fetched
+	srv.ResetRequests()
+	res = runCLI(t, nil, "remove-tags", "--yes", "--regex", "--tag", "owner:(", id)
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "invalid tag regex") {
+		t.Errorf("remove-tags with an invalid regex = %v", res.Err)
+	}
+	srv.AssertRequestCount(t, 0, "GET", "/monitor/"+id)
+}
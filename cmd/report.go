@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Documents generated from monitors",
+	Long:  `Generate documents about monitors, such as a runbook for the team running a service`,
+}
+
+var reportRunbookCmd = &cobra.Command{
+	Use:   "runbook",
+	Short: "Render a Markdown runbook of the monitors matching filters",
+	Long: `Fetch the monitors matching the filters and render a Markdown runbook to hand
+to the team running the service: a table of contents, then the monitors grouped
+by category, each with its name, link in the Datadog app, query, thresholds and
+the start of its message.
+
+A monitor's category is its category: tag, else the part of its name after the
+last " - " (the templates name monitors "Monitor {service} - CPU throttling"),
+else "Other". Categories are sorted by name, "Other" last.
+
+--template renders the document with a Go template of your own instead. It is
+executed with:
+
+  .Title, .Generated (time), .Total
+  .Categories: .Name, .Anchor, .Monitors
+  each monitor: the monitor fields (.ID, .Name, .Type, .Query, .Message,
+    .Tags, .Options, .OverallState, ...), .URL, .Anchor, .Excerpt and
+    .Thresholds (.Name, .Value)
+
+with the --format functions (join, tagvalue, truncate, mdescape) plus quote,
+which prefixes every line with "> ".
+
+Examples:
+  report runbook --service myapp --env prd
+  report runbook --service myapp --env prd --output-file docs/runbook.md
+  report runbook --filter-tags team:payments --title "Payments on-call"
+  report runbook --service myapp --template custom.md.tmpl`,
+	RunE: runReportRunbook,
+}
+
+var (
+	reportRunbookService    string
+	reportRunbookEnv        string
+	reportRunbookNamespace  string
+	reportRunbookFilterTags string
+	reportRunbookQuery      string
+	reportRunbookTitle      string
+	reportRunbookTemplate   string
+	reportRunbookOutputFile string
+)
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.AddCommand(reportRunbookCmd)
+	reportRunbookCmd.Flags().StringVar(&reportRunbookService, "service", "", "Filter by service")
+	reportRunbookCmd.Flags().StringVar(&reportRunbookEnv, "env", "", "Filter by environment")
+	reportRunbookCmd.Flags().StringVar(&reportRunbookNamespace, "namespace", "", "Filter by namespace")
+	reportRunbookCmd.Flags().StringVar(&reportRunbookFilterTags, "filter-tags", "", "Filter by tags (comma-separated)")
+	reportRunbookCmd.Flags().StringVar(&reportRunbookQuery, "query", "", "Complex search query (e.g., service:(service1 OR service2))")
+	reportRunbookCmd.Flags().StringVar(&reportRunbookTitle, "title", "", "Title of the document (default: from --service and --env)")
+	reportRunbookCmd.Flags().StringVar(&reportRunbookTemplate, "template", "", "Go template file to render the document with instead of the default layout")
+	reportRunbookCmd.Flags().StringVar(&reportRunbookOutputFile, "output-file", "", "Write the document to this file instead of stdout")
+}
+
+// runbookFuncs returns the helper functions of runbook templates: the
+// --format functions plus quote, which prefixes every line with "> " (a
+// Markdown blockquote)
+func runbookFuncs() template.FuncMap {
+	funcs := template.FuncMap{
+		"quote": func(s string) string {
+			return "> " + strings.ReplaceAll(s, "\n", "\n> ")
+		},
+	}
+	for name, fn := range monitorFormatFuncs {
+		funcs[name] = fn
+	}
+	return funcs
+}
+
+// defaultRunbookTemplate is the layout of report runbook without --template
+const defaultRunbookTemplate = `# {{.Title}}
+
+Generated {{.Generated.Format "2006-01-02 15:04 MST"}}: {{.Total}} monitor(s) in {{len .Categories}} categories.
+
+## Contents
+{{range .Categories}}
+- [{{.Name}}](#{{.Anchor}}) ({{len .Monitors}})
+{{- range .Monitors}}
+  - [{{mdescape .Name}}](#{{.Anchor}})
+{{- end}}
+{{- end}}
+{{range .Categories}}
+## {{.Name}}
+{{range .Monitors}}
+### {{.Name}}
+
+- **Monitor:** {{if .URL}}[{{.ID}}]({{.URL}}){{else}}{{.ID}}{{end}}
+- **Type:** ` + "`{{.Type}}`" + `
+{{- with .Thresholds}}
+- **Thresholds:** {{range $i, $t := .}}{{if $i}}, {{end}}{{$t.Name}} ` + "`{{$t.Value}}`" + `{{end}}
+{{- end}}
+{{- with tagvalue "team" .Tags}}
+- **Team:** {{.}}
+{{- end}}
+
+**Query:**
+
+` + "```" + `
+{{.Query}}
+` + "```" + `
+{{- with .Excerpt}}
+
+**Message:**
+
+{{quote .}}
+{{- end}}
+{{end}}{{end}}`
+
+func runReportRunbook(cmd *cobra.Command, args []string) error {
+	text := defaultRunbookTemplate
+	name := "runbook"
+	if reportRunbookTemplate != "" {
+		data, err := os.ReadFile(reportRunbookTemplate)
+		if err != nil {
+			return fmt.Errorf("failed to read --template: %v", err)
+		}
+		text, name = string(data), reportRunbookTemplate
+	}
+	tmpl, err := template.New(name).Funcs(runbookFuncs()).Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid --template: %w", err)
+	}
+
+	selector := monitorSelector{
+		Query:     reportRunbookQuery,
+		Service:   reportRunbookService,
+		Env:       reportRunbookEnv,
+		Namespace: reportRunbookNamespace,
+		Tags:      splitCommaList(reportRunbookFilterTags),
+		Cacheable: true,
+	}
+	if err := selector.validate(); err != nil {
+		return err
+	}
+	if !selector.hasFilters() {
+		return fmt.Errorf("at least one filter is required (--service, --env, --namespace, --filter-tags or --query)")
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	monitors, err := fetchMonitors(client, selector)
+	if err != nil {
+		errOut.Printf("❌ Error listing monitors: %v\n", err)
+		return err
+	}
+	if len(monitors) == 0 {
+		errOut.Println("ℹ️  No monitors found")
+		return nil
+	}
+
+	runbook := datadog.BuildRunbook(runbookTitle(), monitors, client.AppURL(), time.Now())
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, runbook); err != nil {
+		errOut.Printf("❌ Error rendering the runbook: %v\n", err)
+		return err
+	}
+	if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+
+	if reportRunbookOutputFile == "" {
+		fmt.Print(buf.String())
+		return nil
+	}
+	if err := os.WriteFile(reportRunbookOutputFile, buf.Bytes(), 0644); err != nil {
+		errOut.Printf("❌ Error writing %s: %v\n", reportRunbookOutputFile, err)
+		return err
+	}
+	errOut.Printf("✅ Wrote the runbook of %d monitor(s) in %d categories to %s\n", runbook.Total, len(runbook.Categories), reportRunbookOutputFile)
+	return nil
+}
+
+// runbookTitle returns --title, or a title made of --service and --env
+func runbookTitle() string {
+	if reportRunbookTitle != "" {
+		return reportRunbookTitle
+	}
+	title := "Monitor runbook"
+	if reportRunbookService != "" {
+		title = reportRunbookService + " runbook"
+	}
+	if reportRunbookEnv != "" {
+		title += " (" + reportRunbookEnv + ")"
+	}
+	return title
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadogtest"
+)
+
+// generatedLinePattern matches the generation time of the default runbook
+// layout, which changes on every run
+var generatedLinePattern = regexp.MustCompile(`(?m)^Generated [^:]+ [0-9:]+ [A-Z]+:`)
+
+// assertGolden compares got with the golden file at path, or writes it with
+// datadogtest.UpdateSnapshotsEnv set
+func assertGolden(t *testing.T, path string, got []byte) {
+	t.Helper()
+	if os.Getenv(datadogtest.UpdateSnapshotsEnv) != "" {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (write it with %s=1)", err, datadogtest.UpdateSnapshotsEnv)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output doesn't match %s (update it with %s=1):\n%s", path, datadogtest.UpdateSnapshotsEnv, got)
+	}
+}
+
+// normalizeRunbook replaces what changes between runs of report runbook, the
+// generation time and the fake server's address, with fixed values
+func normalizeRunbook(srv *datadogtest.Server, runbook string) string {
+	runbook = generatedLinePattern.ReplaceAllString(runbook, "Generated 2024-05-01 12:00 UTC:")
+	return strings.ReplaceAll(runbook, srv.URL, "https://app.datadoghq.com")
+}
+
+func TestReportRunbookDefaultLayout(t *testing.T) {
+	srv := newTestServer(t)
+	addMonitorFixture(t, srv, "runbook-monitors.json")
+
+	res := runCLI(t, nil, "report", "runbook", "--service", "checkout", "--env", "prd")
+	if res.Err != nil {
+		t.Fatalf("report runbook: %v\n%s", res.Err, res.Stderr)
+	}
+	if !generatedLinePattern.MatchString(res.Stdout) {
+		t.Fatalf("no generation time in the runbook:\n%s", res.Stdout)
+	}
+	assertGolden(t, "testdata/runbook.golden.md", []byte(normalizeRunbook(srv, res.Stdout)))
+
+	// --output-file writes the same document
+	path := filepath.Join(t.TempDir(), "runbook.md")
+	res = runCLI(t, nil, "report", "runbook", "--service", "checkout", "--env", "prd", "--output-file", path)
+	if res.Err != nil {
+		t.Fatalf("report runbook --output-file: %v\n%s", res.Err, res.Stderr)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "testdata/runbook.golden.md", []byte(normalizeRunbook(srv, string(data))))
+	if res.Stdout != "" || !strings.Contains(res.Stderr, "Wrote the runbook of 5 monitor(s) in 3 categories to "+path) {
+		t.Errorf("report runbook --output-file printed:\n%s\n%s", res.Stdout, res.Stderr)
+	}
+}
+
+func TestReportRunbookCustomTemplate(t *testing.T) {
+	srv := newTestServer(t)
+	addMonitorFixture(t, srv, "runbook-monitors.json")
+	path := filepath.Join(t.TempDir(), "custom.md.tmpl")
+	tmpl := `{{.Title}}: {{.Total}}
+{{range .Categories}}{{.Name}}:{{range .Monitors}} {{.ID}}{{end}}
+{{end}}{{with index .Categories 0}}{{with index .Monitors 0}}{{quote .Excerpt}}{{end}}{{end}}`
+	if err := os.WriteFile(path, []byte(tmpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	res := runCLI(t, nil, "report", "runbook", "--service", "checkout", "--title", "Checkout on-call", "--template", path)
+	if res.Err != nil {
+		t.Fatalf("report runbook --template: %v\n%s", res.Err, res.Stderr)
+	}
+	want := `Checkout on-call: 5
+Error Rate: 1000
+Performance: 1001 1003 1002
+Other: 1004
+> Error rate too high on {{service.name}}
+> Check the recent deploys.
+> @slack-checkout
+`
+	if res.Stdout != want {
+		t.Errorf("report runbook --template printed:\n%s\nwant:\n%s", res.Stdout, want)
+	}
+}
+
+func TestReportRunbookErrors(t *testing.T) {
+	invalid := filepath.Join(t.TempDir(), "invalid.md.tmpl")
+	if err := os.WriteFile(invalid, []byte("{{.Title"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--title", "Runbook"}, "at least one filter is required"},
+		{[]string{"--service", "checkout", "--template", invalid}, "invalid --template"},
+		{[]string{"--service", "checkout", "--template", filepath.Join(t.TempDir(), "missing.tmpl")}, "failed to read --template"},
+	} {
+		srv := newTestServer(t)
+		res := runCLI(t, nil, append([]string{"report", "runbook"}, tc.args...)...)
+		if res.Err == nil || !strings.Contains(res.Err.Error(), tc.want) {
+			t.Errorf("report runbook %v = %v, want %q", tc.args, res.Err, tc.want)
+		}
+		if got := len(srv.Requests()); got != 0 {
+			t.Errorf("report runbook %v made %d request(s)", tc.args, got)
+		}
+	}
+}
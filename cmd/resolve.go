@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var resolveCmd = &cobra.Command{
+	Use:   "resolve",
+	Short: "Manually resolve alerting monitors or some of their groups",
+	Long: `Manually resolve a monitor, like the Resolve button of the UI. This clears
+alerts that can't recover on their own, e.g. an error count over a window
+after a one-off spike.
+
+Without --group every group of the monitor is resolved. A group is given as
+its comma-separated tags, e.g. --group 'service:foo,pod:bar'. Monitors
+selected with filter flags are resolved the same way; monitors already OK are
+skipped.
+
+Examples:
+  resolve --monitor-id 12345
+  resolve --monitor-id 12345 --group 'service:checkout,pod:checkout-7f9'
+  resolve --service myapp --env prd --status Alert`,
+	RunE: runResolve,
+}
+
+var (
+	resolveMonitorID      int
+	resolveGroups         []string
+	resolveService        string
+	resolveEnvironment    string
+	resolveNamespace      string
+	resolveFilterTags     string
+	resolveQuery          string
+	resolveStatus         string
+	resolveFilterServices string
+)
+
+func init() {
+	rootCmd.AddCommand(resolveCmd)
+	resolveCmd.Flags().IntVar(&resolveMonitorID, "monitor-id", 0, "Monitor ID (for single monitor)")
+	resolveCmd.Flags().StringArrayVar(&resolveGroups, "group", []string{}, "Only resolve this group, as comma-separated tags, e.g. 'service:foo,pod:bar' (can be used multiple times)")
+	resolveCmd.Flags().StringVar(&resolveService, "service", "", "Filter by service (for multiple monitors)")
+	resolveCmd.Flags().StringVar(&resolveEnvironment, "env", "", "Filter by environment (for multiple monitors)")
+	resolveCmd.Flags().StringVar(&resolveNamespace, "namespace", "", "Filter by namespace (for multiple monitors)")
+	resolveCmd.Flags().StringVar(&resolveFilterTags, "filter-tags", "", "Filter by tags (comma-separated, for multiple monitors)")
+	resolveCmd.Flags().StringVar(&resolveQuery, "query", "", "Complex search query (e.g., service:(service1 OR service2))")
+	resolveCmd.Flags().StringVar(&resolveStatus, "status", "", "Filter by monitor state, comma-separated (OK, Alert, Warn, No Data, Unknown, Skipped, Ignored)")
+	resolveCmd.Flags().StringVar(&resolveFilterServices, "filter-services", "", "Filter by multiple services (comma-separated, filters locally after query/tags)")
+}
+
+// groupLabel describes a resolve group for display
+func groupLabel(group string) string {
+	if group == datadog.AllGroups {
+		return "all groups"
+	}
+	return group
+}
+
+func runResolve(cmd *cobra.Command, args []string) error {
+	selector := monitorSelector{
+		Query:          resolveQuery,
+		Service:        resolveService,
+		Env:            resolveEnvironment,
+		Namespace:      resolveNamespace,
+		Tags:           splitCommaList(resolveFilterTags),
+		Status:         resolveStatus,
+		FilterServices: resolveFilterServices,
+		Mutating:       true,
+	}
+
+	if resolveMonitorID == 0 && !selector.hasFilters() {
+		return fmt.Errorf("either --monitor-id or filter flags (--service, --env, --namespace, --filter-tags, --query) must be provided")
+	}
+	if resolveMonitorID > 0 && (selector.hasFilters() || resolveStatus != "" || resolveFilterServices != "") {
+		return fmt.Errorf("cannot use --monitor-id together with filter flags")
+	}
+	if err := selector.validate(); err != nil {
+		return err
+	}
+
+	var groups []string
+	for _, group := range resolveGroups {
+		normalized, err := datadog.NormalizeResolveGroup(group)
+		if err != nil {
+			return err
+		}
+		groups = append(groups, normalized)
+	}
+	if len(groups) == 0 {
+		groups = []string{datadog.AllGroups}
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	var monitors []datadog.Monitor
+	if resolveMonitorID > 0 {
+		monitor, err := client.GetMonitor(resolveMonitorID)
+		if err != nil {
+			reportMonitorError("getting monitor", err)
+			return err
+		}
+		monitors = []datadog.Monitor{*monitor}
+	} else {
+		monitors, err = fetchMonitors(client, selector)
+		if err != nil {
+			errOut.Printf("❌ Error listing monitors: %v\n", err)
+			return err
+		}
+	}
+
+	var toResolve []datadog.Monitor
+	for _, monitor := range monitors {
+		if canonicalMonitorState(monitor.OverallState) == "ok" {
+			out.Printf("⏭️  ID %d: %s - already OK\n", monitor.ID, monitor.Name)
+			continue
+		}
+		toResolve = append(toResolve, monitor)
+	}
+
+	if len(toResolve) == 0 {
+		out.Printf("ℹ️  No monitors to resolve (%d monitor(s) matched)\n", len(monitors))
+		return nil
+	}
+
+	labels := make([]string, len(groups))
+	for i, group := range groups {
+		labels[i] = groupLabel(group)
+	}
+	out.Printf("🎯 Groups: %s\n", strings.Join(labels, "; "))
+
+	confirmed, err := confirm(len(toResolve), "resolve", monitorSample(toResolve))
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+	if !confirmed {
+		out.Println("❌ Resolve cancelled")
+		return nil
+	}
+
+	resolved, unknownGroups := 0, 0
+	var failures bulkFailures
+	for i, monitor := range toResolve {
+		if failures.stop(i, nil) {
+			break
+		}
+		results, err := client.ResolveMonitor(monitor.ID, groups)
+		if err != nil {
+			if failures.stop(i, err) {
+				break
+			}
+			failures.add(monitor, err)
+			continue
+		}
+		for _, result := range results {
+			switch result.Status {
+			case datadog.StatusResolved:
+				resolved++
+				state := ""
+				if result.State != "" {
+					state = fmt.Sprintf(" (now %s)", result.State)
+				}
+				out.Printf("✅ ID %d: %s - resolved %s%s\n", monitor.ID, monitor.Name, groupLabel(result.Group), state)
+			case datadog.StatusNotFound:
+				unknownGroups++
+				out.Printf("🔍 ID %d: %s - no group %s\n", monitor.ID, monitor.Name, result.Group)
+			default:
+				failures.add(monitor, result.Err)
+			}
+		}
+	}
+
+	failures.printInterrupted(len(toResolve))
+	out.Printf("\n📊 Results:\n")
+	out.Printf("✅ Resolved: %d group(s)\n", resolved)
+	if unknownGroups > 0 {
+		out.Printf("🔍 Unknown groups: %d\n", unknownGroups)
+	}
+	failures.printCounts()
+
+	failures.printDetails("resolve")
+	return failures.interrupted
+}
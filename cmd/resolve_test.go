@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadogtest"
+)
+
+// addAlertingMonitor adds a monitor alerting on pod:b of its two groups
+func addAlertingMonitor(srv *datadogtest.Server) datadog.Monitor {
+	return srv.AddMonitor(datadog.Monitor{
+		Name:         "Checkout errors",
+		Type:         "metric alert",
+		Query:        "sum(last_5m):sum:errors{service:checkout} by {pod} > 10",
+		Tags:         []string{"service:checkout"},
+		OverallState: "Alert",
+		State: &datadog.MonitorState{Groups: map[string]datadog.GroupState{
+			"pod:a,service:checkout": {Status: "OK"},
+			"pod:b,service:checkout": {Status: "Alert"},
+		}},
+	})
+}
+
+func TestResolveAllGroups(t *testing.T) {
+	srv := newTestServer(t)
+	monitor := addAlertingMonitor(srv)
+
+	res := runCLI(t, nil, "resolve", "--monitor-id", "1000", "--yes")
+	if res.Err != nil {
+		t.Fatalf("resolve: %v\n%s", res.Err, res.Stderr)
+	}
+	srv.AssertRequestCount(t, 1, "POST", "/monitor/bulk_resolve")
+	if got, _ := srv.Monitor(monitor.ID); got.OverallState != "OK" {
+		t.Errorf("monitor state %q after resolving, want OK", got.OverallState)
+	}
+	for _, want := range []string{"Groups: all groups", "ID 1000: Checkout errors - resolved all groups (now OK)", "Resolved: 1 group(s)"} {
+		if !strings.Contains(res.Stdout, want) {
+			t.Errorf("stdout has no %q:\n%s", want, res.Stdout)
+		}
+	}
+}
+
+func TestResolveGroups(t *testing.T) {
+	srv := newTestServer(t)
+	monitor := addAlertingMonitor(srv)
+
+	// Tags are given in any order; pod:c is not a group of the monitor
+	res := runCLI(t, nil, "resolve", "--monitor-id", "1000", "--group", "service:checkout,pod:b", "--group", "pod:c,service:checkout", "--yes")
+	if res.Err != nil {
+		t.Fatalf("resolve: %v\n%s", res.Err, res.Stderr)
+	}
+	if got, _ := srv.Monitor(monitor.ID); got.OverallState != "OK" || got.State.Groups["pod:b,service:checkout"].Status != "OK" {
+		t.Errorf("monitor after resolving pod:b: %q, %+v", got.OverallState, got.State)
+	}
+	for _, want := range []string{
+		"Groups: pod:b,service:checkout; pod:c,service:checkout",
+		"ID 1000: Checkout errors - resolved pod:b,service:checkout (now OK)",
+		"ID 1000: Checkout errors - no group pod:c,service:checkout",
+		"Resolved: 1 group(s)",
+		"Unknown groups: 1",
+	} {
+		if !strings.Contains(res.Stdout, want) {
+			t.Errorf("stdout has no %q:\n%s", want, res.Stdout)
+		}
+	}
+}
+
+func TestResolveNotAlerting(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddMonitor(datadog.Monitor{Name: "Checkout latency", Type: "metric alert", Query: "avg(last_5m):avg:latency{service:checkout} > 1", Tags: []string{"service:checkout"}})
+	addAlertingMonitor(srv)
+
+	res := runCLI(t, nil, "resolve", "--monitor-id", "1000", "--yes")
+	if res.Err != nil {
+		t.Fatalf("resolve: %v\n%s", res.Err, res.Stderr)
+	}
+	srv.AssertNoMutations(t)
+	for _, want := range []string{"ID 1000: Checkout latency - already OK", "No monitors to resolve (1 monitor(s) matched)"} {
+		if !strings.Contains(res.Stdout, want) {
+			t.Errorf("stdout has no %q:\n%s", want, res.Stdout)
+		}
+	}
+
+	// Selected by filters, only the alerting monitor is resolved
+	srv.ResetRequests()
+	res = runCLI(t, nil, "resolve", "--service", "checkout", "--yes")
+	if res.Err != nil {
+		t.Fatalf("resolve --service: %v\n%s", res.Err, res.Stderr)
+	}
+	srv.AssertRequestCount(t, 1, "POST", "/monitor/bulk_resolve")
+	for _, want := range []string{"ID 1000: Checkout latency - already OK", "ID 1001: Checkout errors - resolved all groups (now OK)"} {
+		if !strings.Contains(res.Stdout, want) {
+			t.Errorf("stdout has no %q:\n%s", want, res.Stdout)
+		}
+	}
+}
+
+func TestResolveInvalidGroup(t *testing.T) {
+	for _, group := range []string{"checkout", "service:checkout,", "pod:checkout-*"} {
+		srv := newTestServer(t)
+		addAlertingMonitor(srv)
+
+		res := runCLI(t, nil, "resolve", "--monitor-id", "1000", "--group", group, "--yes")
+		if res.Err == nil || !strings.Contains(res.Err.Error(), "invalid group") {
+			t.Errorf("resolve --group %q = %v, want an invalid group error", group, res.Err)
+		}
+		if requests := srv.Requests(); len(requests) != 0 {
+			t.Errorf("resolve --group %q sent requests: %v", group, requests)
+		}
+	}
+}
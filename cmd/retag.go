@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var retagCmd = &cobra.Command{
+	Use:   "retag",
+	Short: "Rename a tag value on monitors",
+	Long: `Replace a key:value tag with another on a single monitor or on multiple
+monitors, e.g. env:hml with env:stg after an environment rename.
+
+With --include-queries, the whole-tag occurrences of --from in queries and
+messages are rewritten as well (avg:...{env:hml} becomes avg:...{env:stg});
+env:hml2 or myenv:hml are left alone. The changes are previewed before asking
+for confirmation, and each rewritten query is checked with the validate
+endpoint before the monitor is updated: monitors whose new query fails
+validation are left unchanged and reported as failed.
+
+Without --monitor-id or filter flags, the monitors tagged --from are retagged;
+with --include-queries, every monitor is scanned for the tag.
+
+Examples:
+  retag --from env:hml --to env:stg
+  retag --from env:hml --to env:stg --include-queries
+  retag --from team:old --to team:new --service myapp`,
+	RunE: runRetag,
+}
+
+var (
+	retagMonitorID      int
+	retagService        string
+	retagEnv            string
+	retagNamespace      string
+	retagFilterTags     string
+	retagQuery          string
+	retagFrom           string
+	retagTo             string
+	retagIncludeQueries bool
+)
+
+func init() {
+	rootCmd.AddCommand(retagCmd)
+	retagCmd.Flags().IntVar(&retagMonitorID, "monitor-id", 0, "Monitor ID (for single monitor)")
+	retagCmd.Flags().StringVar(&retagService, "service", "", "Filter by service (for multiple monitors)")
+	retagCmd.Flags().StringVar(&retagEnv, "env", "", "Filter by environment (for multiple monitors)")
+	retagCmd.Flags().StringVar(&retagNamespace, "namespace", "", "Filter by namespace (for multiple monitors)")
+	retagCmd.Flags().StringVar(&retagFilterTags, "filter-tags", "", "Filter by tags (comma-separated, for multiple monitors)")
+	retagCmd.Flags().StringVar(&retagQuery, "query", "", "Complex search query (e.g., service:(service1 OR service2))")
+	retagCmd.Flags().StringVar(&retagFrom, "from", "", "Tag to replace, key:value (required)")
+	retagCmd.Flags().StringVar(&retagTo, "to", "", "Replacement tag, key:value (required)")
+	retagCmd.Flags().BoolVar(&retagIncludeQueries, "include-queries", false, "Also rewrite the tag in queries and messages (queries are validated before updating)")
+	retagCmd.MarkFlagRequired("from")
+	retagCmd.MarkFlagRequired("to")
+}
+
+func runRetag(cmd *cobra.Command, args []string) error {
+	retag, err := datadog.NewRetag(retagFrom, retagTo, retagIncludeQueries)
+	if err != nil {
+		return err
+	}
+
+	selector := monitorSelector{
+		Query:     retagQuery,
+		Service:   retagService,
+		Env:       retagEnv,
+		Namespace: retagNamespace,
+		Tags:      splitCommaList(retagFilterTags),
+		Mutating:  true,
+	}
+	if retagMonitorID > 0 && selector.hasFilters() {
+		return fmt.Errorf("cannot use --monitor-id together with filter flags")
+	}
+	if err := selector.validate(); err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	var monitors []datadog.Monitor
+	if retagMonitorID > 0 {
+		monitor, err := client.GetMonitor(retagMonitorID)
+		if err != nil {
+			reportMonitorError("getting monitor", err)
+			return err
+		}
+		monitors = []datadog.Monitor{*monitor}
+	} else {
+		if !selector.hasFilters() && !retagIncludeQueries {
+			selector.Tags = []string{retag.From}
+		}
+		monitors, err = fetchMonitors(client, selector)
+		if err != nil {
+			errOut.Printf("❌ Error listing monitors: %v\n", err)
+			return err
+		}
+	}
+
+	// Preview the changes
+	var toUpdate []datadog.Monitor
+	queries := 0
+	for _, monitor := range monitors {
+		change := retag.Apply(monitor)
+		if !change.Changed() {
+			continue
+		}
+		if len(toUpdate) == 0 {
+			out.Printf("\n🏷️  Retagging %s → %s:\n", retag.From, retag.To)
+		}
+		toUpdate = append(toUpdate, monitor)
+		out.Printf("\n   ID %d: %s\n", monitor.ID, monitor.Name)
+		if change.Tags {
+			out.Printf("      Tags: - %s + %s\n", retag.From, retag.To)
+		}
+		if change.Query {
+			queries++
+			out.Printf("      Query:\n")
+			out.Printf("      - %s\n", monitor.Query)
+			out.Printf("      + %s\n", change.Monitor.Query)
+		}
+		if change.Message {
+			out.Printf("      Message:\n")
+			out.Printf("      - %s\n", messageSnippet(monitor.Message, change.Monitor.Message))
+			out.Printf("      + %s\n", messageSnippet(change.Monitor.Message, monitor.Message))
+		}
+	}
+
+	if len(toUpdate) == 0 {
+		out.Printf("ℹ️  No monitors to retag (%d monitor(s) checked, none use %s)\n", len(monitors), retag.From)
+		return nil
+	}
+
+	out.Printf("\n📊 %d monitor(s) will be updated (%d query rewrite(s))\n", len(toUpdate), queries)
+
+	confirmed, err := confirm(len(toUpdate), "retag", monitorSample(toUpdate))
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+	if !confirmed {
+		out.Println("❌ Retag cancelled")
+		return nil
+	}
+
+	updated, unchanged := 0, 0
+	var failures bulkFailures
+	for i, monitor := range toUpdate {
+		if failures.stop(i, nil) {
+			break
+		}
+		_, change, err := client.RetagMonitor(monitor.ID, retag)
+		if err != nil {
+			if failures.stop(i, err) {
+				break
+			}
+			failures.add(monitor, err)
+			continue
+		}
+		if change.Changed() {
+			updated++
+		} else {
+			unchanged++
+		}
+	}
+
+	failures.printInterrupted(len(toUpdate))
+	out.Printf("\n📊 Results:\n")
+	out.Printf("✅ Successfully updated: %d\n", updated)
+	if unchanged > 0 {
+		out.Printf("⏭️  Unchanged (changed meanwhile): %d\n", unchanged)
+	}
+	failures.printCounts()
+
+	failures.printDetails("retag")
+
+	return failures.interrupted
+}
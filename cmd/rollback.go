@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Restore the tags recorded in a rollback file",
+	Long: `Restore the tag sets monitors had before an add-tags/remove-tags run made with
+--rollback-file.
+
+Monitors whose tags changed again after that run (their tags no longer match
+the post-change snapshot in the file) are skipped unless --force.
+
+Examples:
+  add-tags --env prd --tag team:payments --rollback-file rollback.json
+  rollback --file rollback.json`,
+	RunE: runRollback,
+}
+
+var (
+	rollbackFile  string
+	rollbackForce bool
+)
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+	rollbackCmd.Flags().StringVarP(&rollbackFile, "file", "f", "", "Rollback file written by --rollback-file (required)")
+	rollbackCmd.MarkFlagRequired("file")
+	rollbackCmd.Flags().BoolVar(&rollbackForce, "force", false, "Restore monitors even if their tags changed since the recorded update")
+}
+
+func runRollback(cmd *cobra.Command, args []string) error {
+	rollback, err := datadog.LoadTagRollback(rollbackFile)
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	out.Printf("↩️  Rolling back: %s\n", rollback.Operation)
+	out.Printf("🕐 Recorded: %s\n", formatTime(rollback.CreatedAt))
+	out.Println(strings.Repeat("=", 80))
+
+	if len(rollback.Monitors) == 0 {
+		out.Println("ℹ️  The rollback file has no monitors")
+		return nil
+	}
+	out.Printf("📊 %d monitor(s) in the rollback file\n", len(rollback.Monitors))
+
+	sample := make([]string, len(rollback.Monitors))
+	for i, item := range rollback.Monitors {
+		sample[i] = fmt.Sprintf("ID %d: %s", item.ID, item.Name)
+	}
+	confirmed, err := confirm(len(rollback.Monitors), "restore the tags of", sample)
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+	if !confirmed {
+		out.Println("❌ Rollback cancelled")
+		return nil
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	restored, unchanged := 0, 0
+	var changed []string
+	var failures bulkFailures
+	for i, item := range rollback.Monitors {
+		if failures.stop(i, nil) {
+			break
+		}
+		_, done, err := client.RollbackTags(item, rollbackForce)
+		var changedErr *datadog.TagsChangedError
+		if errors.As(err, &changedErr) {
+			changed = append(changed, fmt.Sprintf("ID %d: %s (now: %s)", item.ID, item.Name, strings.Join(changedErr.Current, ", ")))
+			continue
+		}
+		if err != nil {
+			if failures.stop(i, err) {
+				break
+			}
+			failures.add(datadog.Monitor{ID: item.ID, Name: item.Name}, err)
+			continue
+		}
+		if done {
+			restored++
+			out.Printf("   ✅ ID %d: %s\n", item.ID, item.Name)
+		} else {
+			unchanged++
+		}
+	}
+
+	failures.printInterrupted(len(rollback.Monitors))
+	out.Printf("\n📊 Results:\n")
+	out.Printf("✅ Successfully restored: %d\n", restored)
+	out.Printf("⏭️  Already restored: %d\n", unchanged)
+	if len(changed) > 0 {
+		out.Printf("⚠️  Skipped (tags changed since): %d\n", len(changed))
+	}
+	failures.printCounts()
+
+	if len(changed) > 0 {
+		out.Println("\n⚠️  Monitors whose tags changed since the recorded update (use --force to restore anyway):")
+		for _, entry := range changed {
+			out.Printf("   ⚠️  %s\n", entry)
+		}
+	}
+	failures.printDetails("restore")
+
+	return failures.interrupted
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+func TestRollbackRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		command string
+		tag     string
+	}{
+		{"add-tags", "team:sre"},
+		// Removing the only tag of a monitor is restored too
+		{"remove-tags", "owner:alice"},
+	} {
+		t.Run(tc.command, func(t *testing.T) {
+			srv := newTestServer(t)
+			cpu := srv.AddMonitor(datadog.Monitor{Name: "cpu", Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90", Tags: []string{"env:prd", "owner:alice"}})
+			srv.AddMonitor(datadog.Monitor{Name: "disk", Type: "metric alert", Query: "avg(last_5m):avg:disk{*} > 90", Tags: []string{"owner:alice"}})
+			other := srv.AddMonitor(datadog.Monitor{Name: "mem", Type: "metric alert", Query: "avg(last_5m):avg:mem{*} > 90", Tags: []string{"env:stg"}})
+			before := srv.Monitors()
+			file := filepath.Join(t.TempDir(), "rollback.json")
+
+			res := runCLI(t, nil, tc.command, "--yes", "--tag", tc.tag, "--rollback-file", file, "1000", "1001")
+			if res.Err != nil {
+				t.Fatalf("%s: %v\n%s", tc.command, res.Err, res.Stderr)
+			}
+			if !strings.Contains(res.Stdout, "Rollback file written: "+file+" (2 monitor(s))") {
+				t.Errorf("rollback file not reported:\n%s", res.Stdout)
+			}
+			if m, _ := srv.Monitor(cpu.ID); slices.Equal(m.Tags, cpu.Tags) {
+				t.Fatalf("%s changed nothing", tc.command)
+			}
+
+			srv.ResetRequests()
+			res = runCLI(t, nil, "rollback", "--yes", "--file", file)
+			if res.Err != nil {
+				t.Fatalf("rollback: %v\n%s", res.Err, res.Stderr)
+			}
+			if !strings.Contains(res.Stdout, "Successfully restored: 2") {
+				t.Errorf("unexpected output:\n%s", res.Stdout)
+			}
+			for i, monitor := range srv.Monitors() {
+				if !datadog.SameTags(monitor.Tags, before[i].Tags) {
+					t.Errorf("monitor %d has tags %v after the rollback, want %v", monitor.ID, monitor.Tags, before[i].Tags)
+				}
+			}
+			srv.AssertRequestCount(t, 0, "PUT", "/monitor/"+strconv.Itoa(other.ID))
+
+			// Rolling back again changes nothing
+			srv.ResetRequests()
+			res = runCLI(t, nil, "rollback", "--yes", "--file", file)
+			if res.Err != nil {
+				t.Fatalf("rollback: %v\n%s", res.Err, res.Stderr)
+			}
+			srv.AssertNoMutations(t)
+			if !strings.Contains(res.Stdout, "Already restored: 2") {
+				t.Errorf("unexpected output:\n%s", res.Stdout)
+			}
+		})
+	}
+}
+
+func TestRollbackSkipsChangedTags(t *testing.T) {
+	srv := newTestServer(t)
+	monitor := srv.AddMonitor(datadog.Monitor{Name: "cpu", Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90", Tags: []string{"env:prd"}})
+	file := filepath.Join(t.TempDir(), "rollback.json")
+	if res := runCLI(t, nil, "add-tags", "--yes", "--tag", "team:sre", "--rollback-file", file, "1000"); res.Err != nil {
+		t.Fatalf("add-tags: %v\n%s", res.Err, res.Stderr)
+	}
+
+	// Someone changed the tags again after the recorded update
+	edited, _ := srv.Monitor(monitor.ID)
+	edited.Tags = []string{"env:prd", "team:web"}
+	srv.AddMonitor(edited)
+
+	srv.ResetRequests()
+	res := runCLI(t, nil, "rollback", "--yes", "--file", file)
+	if res.Err != nil {
+		t.Fatalf("rollback: %v\n%s", res.Err, res.Stderr)
+	}
+	srv.AssertNoMutations(t)
+	if !strings.Contains(res.Stdout, "Skipped (tags changed since): 1") || !strings.Contains(res.Stdout, "ID 1000: cpu (now: env:prd, team:web)") {
+		t.Errorf("changed monitor not reported:\n%s", res.Stdout)
+	}
+
+	res = runCLI(t, nil, "rollback", "--yes", "--force", "--file", file)
+	if res.Err != nil {
+		t.Fatalf("rollback --force: %v\n%s", res.Err, res.Stderr)
+	}
+	if m, _ := srv.Monitor(monitor.ID); !slices.Equal(m.Tags, []string{"env:prd"}) {
+		t.Errorf("tags %v after rollback --force, want [env:prd]", m.Tags)
+	}
+}
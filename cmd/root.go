@@ -1,7 +1,12 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/version"
 )
 
 var rootCmd = &cobra.Command{
@@ -11,17 +16,74 @@ var rootCmd = &cobra.Command{
 Creates Kubernetes-specific monitors/alerts in Datadog via API
 Pipeline-ready with auto-detection capabilities
 
-Version: 1.0.0`,
-	Version: "1.0.0",
+Version: ` + version.Version,
+	Version: version.Version,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := resolveDisplay(); err != nil {
+			return err
+		}
+		if err := resolveTimeFormat(); err != nil {
+			return err
+		}
+		if cacheTTL <= 0 {
+			return fmt.Errorf("--cache-ttl must be positive")
+		}
+		if requestBudget < 0 || failFast < 0 {
+			return fmt.Errorf("--request-budget and --fail-fast must not be negative")
+		}
+		if err := checkIdentityFlags(cmd); err != nil {
+			return err
+		}
+		if err := checkIncludeProtected(); err != nil {
+			return err
+		}
+		startCommandContext(cmd.Context())
+		startStats()
+		startUpdateCheck(cmd)
+		return nil
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
+// Ctrl-C and --timeout cancel the command context; bulk commands then stop and
+// print a partial summary. API call statistics (--stats) and an available
+// upgrade are reported at the end, after the protected monitors bulk commands
+// skipped.
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := signalContext()
+	defer stop()
+	return executeContext(ctx)
+}
+
+// executeContext runs the command line in ctx, reporting what Execute reports
+// after the command
+func executeContext(ctx context.Context) error {
+	defer stopCommandContext()
+	defer printUpdateNotice()
+	defer reportStats()
+	defer printProtectedSkipped()
+	return rootCmd.ExecuteContext(ctx)
 }
 
 func init() {
 	rootCmd.SetVersionTemplate("{{.Version}}\n")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Config file (default: $DDMM_CONFIG, else config.yaml in the config directory or a legacy ~/.ddmm.yaml)")
+	rootCmd.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", false, "Skip confirmation prompts (or set DDMM_ASSUME_YES=1)")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "Stop after this long (e.g., 10m), printing a partial summary (default: no timeout)")
+	rootCmd.PersistentFlags().BoolVar(&showStats, "stats", false, "Print API call statistics (requests, errors, bytes, timing) after the command")
+	rootCmd.PersistentFlags().StringVar(&statsJSONFile, "stats-json", "", "Write API call statistics as JSON to this file after the command")
+	rootCmd.PersistentFlags().BoolVar(&sanitizeValues, "sanitize", false, "Map --service, --env and --namespace values with characters tags can't hold to valid ones (lowercase, invalid characters replaced with '_')")
+	rootCmd.PersistentFlags().BoolVar(&noUpdateCheck, "no-update-check", false, "Don't check for a newer release (or set DDMM_NO_UPDATE_CHECK=1)")
+	rootCmd.PersistentFlags().StringVar(&displayFlag, "display", "", "Output style: auto (decorated on terminals, plain otherwise), decorated, plain or machine (JSON lines) (or set DDMM_DISPLAY)")
+	rootCmd.PersistentFlags().StringVar(&timeFormatFlag, "time-format", "", "How dates are shown: local, utc or relative (or set DDMM_TIME_FORMAT; default: local)")
+	rootCmd.PersistentFlags().BoolVar(&useCache, "cache", false, "Serve read-only commands from a cached monitor inventory (see: cache status)")
+	rootCmd.PersistentFlags().DurationVar(&cacheTTL, "cache-ttl", 5*time.Minute, "With --cache, how long the cached monitors are used before refetching")
+	rootCmd.PersistentFlags().IntVar(&requestBudget, "request-budget", 0, "Stop bulk commands once this many API calls were made, printing a partial summary (default: unlimited)")
+	rootCmd.PersistentFlags().IntVar(&failFast, "fail-fast", 0, "Stop bulk commands after this many failed monitors, printing a partial summary (default: unlimited)")
+	rootCmd.PersistentFlags().StringVar(&expectOrg, "expect-org", "", "Refuse to change anything unless the keys belong to this Datadog org, by name or public ID (default: expect_org in the config file; see: whoami)")
+	rootCmd.PersistentFlags().BoolVar(&noOrgCheck, "no-org-check", false, "Don't check the org of the keys against --expect-org or expect_org")
+	rootCmd.PersistentFlags().BoolVar(&includeProtected, "include-protected", false, "Let bulk commands change monitors tagged protected:true (or protected_tag_key in the config file); requires --yes")
 	cobra.OnInitialize()
 }
 
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+// sanitizeValues is --sanitize: map invalid service/env/namespace values to
+// valid ones instead of rejecting them
+var sanitizeValues bool
+
+// identityFlags are the flags whose values end up in tags, queries and
+// monitor names
+var identityFlags = []string{"service", "env", "namespace"}
+
+// checkIdentityFlags validates the --service, --env and --namespace flags
+// the command was given, replacing them with their sanitized values when
+// --sanitize is set
+func checkIdentityFlags(cmd *cobra.Command) error {
+	for _, name := range identityFlags {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || !flag.Changed || flag.Value.Type() != "string" {
+			continue
+		}
+		value, err := checkIdentityValue("--"+name, flag.Value.String())
+		if err != nil {
+			return err
+		}
+		if err := flag.Value.Set(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkIdentityValue validates a service, env or namespace value, named by
+// label in messages, or with --sanitize returns its sanitized form (printing
+// it when it changed)
+func checkIdentityValue(label, value string) (string, error) {
+	if value == "" {
+		return value, nil
+	}
+	if !sanitizeValues {
+		if err := datadog.CheckTagValue(value); err != nil {
+			return "", fmt.Errorf("invalid %s %q: %v (use --sanitize to replace them with '_')", label, value, err)
+		}
+		return value, nil
+	}
+
+	sanitized := datadog.SanitizeTagValue(value)
+	if sanitized == "" {
+		return "", fmt.Errorf("invalid %s %q: nothing is left after sanitizing", label, value)
+	}
+	if sanitized != value {
+		errOut.Printf("🧹 Sanitized %s: %q → %s\n", label, value, sanitized)
+	}
+	return sanitized, nil
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Work with the monitor template JSON Schema",
+	Long:  `Work with the JSON Schema that monitor templates are validated against`,
+}
+
+var schemaPrintCmd = &cobra.Command{
+	Use:   "print",
+	Short: "Print the monitor template JSON Schema",
+	Long: `Print the JSON Schema monitor templates are validated against, e.g. to
+configure editor autocomplete:
+
+  datadog-monitor-manager schema print > monitor-template.schema.json`,
+	RunE: runSchemaPrint,
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+	schemaCmd.AddCommand(schemaPrintCmd)
+}
+
+func runSchemaPrint(cmd *cobra.Command, args []string) error {
+	fmt.Print(string(datadog.TemplateSchema))
+	return nil
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+func TestSchemaPrint(t *testing.T) {
+	res := runCLI(t, nil, "schema", "print")
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if res.Stdout != string(datadog.TemplateSchema) {
+		t.Error("schema print does not print the embedded schema")
+	}
+}
+
+func TestApplySchemaValidation(t *testing.T) {
+	srv := newTestServer(t)
+	spec := writeServiceSpec(t)
+	editTemplateFile(t, spec, `"message": "Error rate too high"`, `"mesage": "Error rate too high"`)
+
+	res := runCLI(t, nil, "apply", "-f", spec)
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "/templates/0/config/mesage: unknown property") {
+		t.Fatalf("apply with a misspelled property = %v", res.Err)
+	}
+	srv.AssertNoMutations(t)
+
+	if res := runCLI(t, nil, "apply", "-f", spec, "--no-schema-validation"); res.Err != nil {
+		t.Fatalf("apply --no-schema-validation: %v\n%s", res.Err, res.Stderr)
+	}
+	srv.AssertRequestCount(t, 2, "POST", "/monitor")
+}
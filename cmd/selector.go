@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+// monitorSelector describes how a command selects the monitors it works on.
+// list, add-tags, remove-tags, delete-all and the other bulk commands build one
+// of these from their flags and go through fetchMonitors, so the filtering
+// semantics stay identical.
+type monitorSelector struct {
+	Query          string   // Complex search query (e.g., service:(a OR b))
+	Search         string   // Free-text search (like the UI search box)
+	Tags           []string // Exact tags, sent to the API as monitor_tags
+	Service        string
+	Services       []string // OR-matched services, narrowed locally (monitor_tags can't OR values)
+	Env            string
+	Namespace      string
+	Status         string // Comma-separated monitor states (e.g., Alert,Warn)
+	FilterServices string // Comma-separated services, filtered locally
+	// GroupStates fetches the per-group states (all, alert, warn, no data)
+	GroupStates []string
+	// AnyGroup makes Status also match monitors with any group in that state
+	AnyGroup bool
+	// TagFallback is what happens when the exact Tags match no monitor
+	TagFallback tagFallback
+	// Cacheable lets read-only commands serve exact tag filters from the
+	// --cache monitor inventory
+	Cacheable bool
+	// Mutating marks the selection of a bulk change: protected monitors are
+	// left out unless --include-protected (see skipProtected)
+	Mutating bool
+}
+
+// tagFallback is what fetchMonitors does when exact tag filters match nothing
+type tagFallback int
+
+const (
+	// tagFallbackWarn runs a free-text search for the tags and, when it finds
+	// monitors, warns that the tags may be misspelled
+	tagFallbackWarn tagFallback = iota
+	// tagFallbackShow returns the free-text search results instead, under a
+	// banner (list)
+	tagFallbackShow
+	// tagFallbackNone doesn't search (list --exact)
+	tagFallbackNone
+)
+
+// groupStates returns the group_states to fetch: GroupStates, or with AnyGroup
+// the groups that can match the states
+func (s monitorSelector) groupStates(states []string) []string {
+	if len(s.GroupStates) > 0 || !s.AnyGroup || len(states) == 0 {
+		return s.GroupStates
+	}
+	var groupStates []string
+	for _, state := range states {
+		groupState := datadog.GroupStatesFor(canonicalMonitorState(state))
+		if groupState == datadog.GroupStatesAll {
+			return []string{datadog.GroupStatesAll}
+		}
+		groupStates = append(groupStates, groupState)
+	}
+	return groupStates
+}
+
+// hasFilters reports whether any selection filter (besides status and
+// filter-services, which only narrow a selection) is set
+func (s monitorSelector) hasFilters() bool {
+	return s.Query != "" || s.Search != "" || len(s.Tags) > 0 || s.Service != "" || len(s.Services) > 0 || s.Env != "" || s.Namespace != ""
+}
+
+// validate checks that mutually exclusive filters are not combined and that
+// Status only names monitor states
+func (s monitorSelector) validate() error {
+	if s.Query != "" && (s.Search != "" || len(s.Tags) > 0 || s.Service != "" || len(s.Services) > 0 || s.Env != "" || s.Namespace != "") {
+		return fmt.Errorf("cannot use --query together with other filter flags (--tags, --service, --services, --env, --namespace)")
+	}
+	if s.Service != "" && len(s.Services) > 0 {
+		return fmt.Errorf("cannot use --service together with --services")
+	}
+	_, err := parseStatusFlag(s.Status)
+	return err
+}
+
+// fetchMonitors lists monitors from the API and applies the local filters
+func fetchMonitors(client *datadog.Client, s monitorSelector) ([]datadog.Monitor, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	states, _ := parseStatusFlag(s.Status)
+	// A single service needs no local narrowing
+	if len(s.Services) == 1 {
+		s.Service, s.Services = s.Services[0], nil
+	}
+
+	// Filters use the same env vocabulary as template
+	if s.Env != "" {
+		if cfg, err := loadConfig(); err == nil {
+			s.Env = datadog.ResolveEnvAlias(s.Env, cfg.EnvAliases)
+		}
+	}
+
+	var monitors []datadog.Monitor
+	var err error
+	if s.Query != "" {
+		monitors, err = client.ListMonitorsWithOptions(datadog.ListMonitorsOptions{Search: s.Query, GroupStates: s.groupStates(states)})
+	} else {
+		tags := append([]string(nil), s.Tags...)
+		search := s.Search
+		// Wildcard patterns can't be expressed as monitor_tags - use them as search text instead
+		if len(tags) > 0 && (strings.Contains(tags[0], "*") || strings.Contains(tags[0], "?")) {
+			search = strings.TrimSpace(strings.Join([]string{search, tags[0]}, " "))
+			tags = tags[1:]
+		}
+		if s.Service != "" {
+			tags = append(tags, fmt.Sprintf("service:%s", s.Service))
+		}
+		if s.Env != "" {
+			tags = append(tags, fmt.Sprintf("env:%s", s.Env))
+		}
+		if s.Namespace != "" {
+			tags = append(tags, fmt.Sprintf("namespace:%s", s.Namespace))
+		}
+		groupStates := s.groupStates(states)
+		if s.Cacheable && useCache && search == "" && len(groupStates) == 0 {
+			// The inventory holds every monitor; exact tags are matched locally
+			monitors, err = cachedMonitors(client)
+			monitors = filterMonitorsByTags(monitors, tags)
+		} else {
+			monitors, err = client.ListMonitorsWithOptions(datadog.ListMonitorsOptions{Tags: tags, Search: search, GroupStates: groupStates})
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Only a tag filter the API matched nothing for can be misspelled
+	noTagMatch := len(monitors) == 0 && len(s.Tags) > 0
+
+	monitors = filterMonitorsByServiceEnvNamespace(monitors, s.Service, s.Env, s.Namespace)
+
+	if len(s.Services) > 0 {
+		fetched := len(monitors)
+		monitors = filterMonitorsByServices(monitors, s.Services)
+		logVerbose("--services: fetched %d monitor(s) without a service filter, %d belong to %s", fetched, len(monitors), strings.Join(s.Services, ", "))
+	}
+
+	if s.FilterServices != "" {
+		monitors = filterMonitorsByServices(monitors, splitCommaList(s.FilterServices))
+	}
+
+	if len(states) > 0 {
+		monitors = filterMonitorsByState(monitors, states, s.AnyGroup)
+	}
+
+	if noTagMatch && s.TagFallback != tagFallbackNone {
+		if search := fuzzyTagSearch(s.Tags); search != "" {
+			fuzzy, err := searchFuzzyTags(client, s, search, states)
+			if err != nil {
+				logVerbose("fuzzy tag search failed: %v", err)
+			} else if len(fuzzy) > 0 && s.TagFallback == tagFallbackShow {
+				errOut.Printf("🔎 No exact tag matches for %s; showing fuzzy search results for %q\n", strings.Join(s.Tags, ", "), search)
+				return fuzzy, nil
+			} else if len(fuzzy) > 0 {
+				errOut.Printf("💡 No monitor has exactly the tag(s) %s, but a free-text search for %q finds %d monitor(s), e.g. ID %d: %s - check the spelling\n",
+					strings.Join(s.Tags, ", "), search, len(fuzzy), fuzzy[0].ID, fuzzy[0].Name)
+			}
+		}
+	}
+
+	if len(monitors) == 0 {
+		suggestFilterValues(client, s.Service, s.Env, s.Namespace)
+	}
+
+	if s.Mutating {
+		return skipProtected(monitors)
+	}
+	return monitors, nil
+}
+
+// fuzzyTagSearch returns the free-text search standing in for exact tag
+// filters that matched nothing: the values of key:value tags (the part most
+// likely mistyped) and other tags whole. Excluded (!=) and wildcard tags are
+// left out; "" when nothing is left.
+func fuzzyTagSearch(tags []string) string {
+	var terms []string
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, "!") || strings.ContainsAny(tag, "*?") {
+			continue
+		}
+		if _, value, ok := strings.Cut(tag, ":"); ok && value != "" {
+			tag = value
+		}
+		terms = append(terms, tag)
+	}
+	return strings.Join(terms, " ")
+}
+
+// searchFuzzyTags runs the free-text search of fuzzyTagSearch with the other
+// filters of the selector
+func searchFuzzyTags(client *datadog.Client, s monitorSelector, search string, states []string) ([]datadog.Monitor, error) {
+	var tags []string
+	for _, filter := range []struct{ key, value string }{{"service", s.Service}, {"env", s.Env}, {"namespace", s.Namespace}} {
+		if filter.value != "" {
+			tags = append(tags, filter.key+":"+filter.value)
+		}
+	}
+	monitors, err := client.ListMonitorsWithOptions(datadog.ListMonitorsOptions{Tags: tags, Search: strings.TrimSpace(s.Search + " " + search), GroupStates: s.groupStates(states)})
+	if err != nil {
+		return nil, err
+	}
+	monitors = filterMonitorsByServices(monitors, s.Services)
+	if s.FilterServices != "" {
+		monitors = filterMonitorsByServices(monitors, splitCommaList(s.FilterServices))
+	}
+	return filterMonitorsByState(monitors, states, s.AnyGroup), nil
+}
+
+// suggestFilterValues explains an empty result: for each service/env/namespace
+// filter whose tag exists on no monitor at all, it prints the closest existing
+// value (e.g. "did you mean env:prd?")
+func suggestFilterValues(client *datadog.Client, service, env, namespace string) {
+	if service == "" && env == "" && namespace == "" {
+		return
+	}
+
+	index, err := client.TagValueIndex()
+	if err != nil {
+		logVerbose("could not load tag values for suggestions: %v", err)
+		return
+	}
+
+	for _, filter := range []struct{ key, value string }{
+		{"service", service},
+		{"env", env},
+		{"namespace", namespace},
+	} {
+		if filter.value == "" || index.Has(filter.key, filter.value) {
+			continue
+		}
+		if suggestion := datadog.SuggestClosest(filter.value, index.Values(filter.key)); suggestion != "" {
+			errOut.Printf("💡 No monitor has the tag %s:%s - did you mean %s:%s?\n", filter.key, filter.value, filter.key, suggestion)
+		} else {
+			errOut.Printf("💡 No monitor has the tag %s:%s\n", filter.key, filter.value)
+		}
+	}
+}
+
+// splitCommaList splits a comma-separated flag value, trimming whitespace and dropping empty items
+func splitCommaList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+func TestListSuggestsFilterValues(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddMonitor(datadog.Monitor{Name: "Checkout errors", Type: "query alert", Query: "avg(last_5m):avg:errors{service:checkout} > 1", Tags: []string{"service:checkout", "env:prd", "namespace:shop"}})
+	srv.AddMonitor(datadog.Monitor{Name: "Billing errors", Type: "query alert", Query: "avg(last_5m):avg:errors{service:billing} > 1", Tags: []string{"service:billing", "env:stg"}})
+
+	for _, tc := range []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"mistyped env", []string{"--env", "prod"}, []string{"No monitor has the tag env:prod - did you mean env:prd?"}},
+		{"mistyped service", []string{"--service", "chekout"}, []string{"did you mean service:checkout?"}},
+		{"case only", []string{"--namespace", "SHOP"}, []string{"did you mean namespace:shop?"}},
+		{"several filters", []string{"--service", "biling", "--env", "prd"}, []string{"did you mean service:billing?"}},
+		{"nothing close", []string{"--env", "sandbox"}, []string{"No monitor has the tag env:sandbox\n"}},
+	} {
+		res := runCLI(t, nil, append([]string{"list"}, tc.args...)...)
+		if res.Err != nil {
+			t.Fatalf("%s: %v\n%s", tc.name, res.Err, res.Stderr)
+		}
+		for _, want := range tc.want {
+			if !strings.Contains(res.Stderr, want) {
+				t.Errorf("%s: no %q in:\n%s", tc.name, want, res.Stderr)
+			}
+		}
+	}
+
+	// Existing values get no suggestion, even when their combination matches nothing
+	res := runCLI(t, nil, "list", "--service", "billing", "--env", "prd")
+	if res.Err != nil {
+		t.Fatalf("list: %v\n%s", res.Err, res.Stderr)
+	}
+	if strings.Contains(res.Stderr, "No monitor has the tag") {
+		t.Errorf("suggestion for existing values:\n%s", res.Stderr)
+	}
+}
+
+func TestFuzzyTagSearch(t *testing.T) {
+	for _, tc := range []struct {
+		tags []string
+		want string
+	}{
+		{[]string{"team:paymnets"}, "paymnets"},
+		{[]string{"team:payments", "critical"}, "payments critical"},
+		{[]string{"url:http://host:8080"}, "http://host:8080"},
+		// Exclusions and wildcards are left out
+		{[]string{"!team:payments", "team:pay*", "env:pr?", "tier:1"}, "1"},
+		{[]string{"team:"}, "team:"},
+		{[]string{"!team:payments"}, ""},
+		{nil, ""},
+	} {
+		if got := fuzzyTagSearch(tc.tags); got != tc.want {
+			t.Errorf("fuzzyTagSearch(%q) = %q, want %q", tc.tags, got, tc.want)
+		}
+	}
+}
+
+func TestSplitCommaList(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		want  []string
+	}{
+		{"api", []string{"api"}},
+		{"api,worker", []string{"api", "worker"}},
+		{" api , worker ", []string{"api", "worker"}},
+		// Empty items from doubled or trailing commas are dropped
+		{"api,,worker,", []string{"api", "worker"}},
+		{"No Data, Alert", []string{"No Data", "Alert"}},
+		{"", nil},
+		{" , ,", nil},
+	} {
+		if got := splitCommaList(tc.value); !slices.Equal(got, tc.want) {
+			t.Errorf("splitCommaList(%q) = %q, want %q", tc.value, got, tc.want)
+		}
+	}
+}
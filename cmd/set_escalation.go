@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var setEscalationCmd = &cobra.Command{
+	Use:   "set-escalation",
+	Short: "Set the escalation message of monitors in bulk",
+	Long: `Set, append to, replace text in or clear the escalation message (the text
+sent with re-notifications, options.escalation_message) of a single monitor or
+of multiple monitors matching filters.
+
+Append is idempotent: monitors whose escalation message already contains the
+text are skipped. The escalation message is only sent while renotification is
+enabled (see set-renotify).
+
+Examples:
+  set-escalation --service myapp --env prd --set "Still alerting: @pagerduty-secondary"
+  set-escalation --filter-tags priority:p1 --append "\n@pagerduty-secondary"
+  set-escalation --query "team:payments" --replace "@pagerduty-old=@pagerduty-new"
+  set-escalation --monitor-id 12345 --clear`,
+	RunE: runSetEscalation,
+}
+
+var (
+	setEscalationMonitorID      int
+	setEscalationService        string
+	setEscalationEnv            string
+	setEscalationNamespace      string
+	setEscalationFilterTags     string
+	setEscalationQuery          string
+	setEscalationStatus         string
+	setEscalationFilterServices string
+	setEscalationSet            string
+	setEscalationAppend         string
+	setEscalationReplace        []string
+	setEscalationClear          bool
+)
+
+func init() {
+	rootCmd.AddCommand(setEscalationCmd)
+	setEscalationCmd.Flags().IntVar(&setEscalationMonitorID, "monitor-id", 0, "Monitor ID (for single monitor)")
+	setEscalationCmd.Flags().StringVar(&setEscalationService, "service", "", "Filter by service (for multiple monitors)")
+	setEscalationCmd.Flags().StringVar(&setEscalationEnv, "env", "", "Filter by environment (for multiple monitors)")
+	setEscalationCmd.Flags().StringVar(&setEscalationNamespace, "namespace", "", "Filter by namespace (for multiple monitors)")
+	setEscalationCmd.Flags().StringVar(&setEscalationFilterTags, "filter-tags", "", "Filter by tags (comma-separated, for multiple monitors)")
+	setEscalationCmd.Flags().StringVar(&setEscalationQuery, "query", "", "Complex search query (e.g., service:(service1 OR service2))")
+	setEscalationCmd.Flags().StringVar(&setEscalationStatus, "status", "", "Filter by monitor state, comma-separated (OK, Alert, Warn, No Data, Unknown, Skipped, Ignored)")
+	setEscalationCmd.Flags().StringVar(&setEscalationFilterServices, "filter-services", "", "Filter by multiple services (comma-separated, filters locally after query/tags)")
+	setEscalationCmd.Flags().StringVar(&setEscalationSet, "set", "", "Replace the whole escalation message with this text")
+	setEscalationCmd.Flags().StringVar(&setEscalationAppend, "append", "", "Text to append to the escalation message (skipped if already present)")
+	setEscalationCmd.Flags().StringArrayVar(&setEscalationReplace, "replace", []string{}, "Literal replacement old=new (can be used multiple times)")
+	setEscalationCmd.Flags().BoolVar(&setEscalationClear, "clear", false, "Remove the escalation message")
+}
+
+func runSetEscalation(cmd *cobra.Command, args []string) error {
+	edit := datadog.EscalationEdit{
+		Set:   strings.ReplaceAll(setEscalationSet, `\n`, "\n"),
+		Clear: setEscalationClear,
+		MessageEdit: datadog.MessageEdit{
+			Append: strings.ReplaceAll(setEscalationAppend, `\n`, "\n"),
+		},
+	}
+	for _, value := range setEscalationReplace {
+		replacement, err := datadog.ParseReplacement(value)
+		if err != nil {
+			return err
+		}
+		edit.Replace = append(edit.Replace, replacement)
+	}
+	if edit.IsEmpty() {
+		return fmt.Errorf("at least one of --set, --append, --replace or --clear is required")
+	}
+	if edit.Clear && (edit.Set != "" || !edit.MessageEdit.IsEmpty()) {
+		return fmt.Errorf("--clear cannot be combined with --set, --append or --replace")
+	}
+
+	selector := monitorSelector{
+		Query:          setEscalationQuery,
+		Service:        setEscalationService,
+		Env:            setEscalationEnv,
+		Namespace:      setEscalationNamespace,
+		Tags:           splitCommaList(setEscalationFilterTags),
+		Status:         setEscalationStatus,
+		FilterServices: setEscalationFilterServices,
+		Mutating:       true,
+	}
+
+	if setEscalationMonitorID == 0 && !selector.hasFilters() {
+		return fmt.Errorf("either --monitor-id or filter flags (--service, --env, --namespace, --filter-tags, --query) must be provided")
+	}
+	if setEscalationMonitorID > 0 && (selector.hasFilters() || setEscalationStatus != "" || setEscalationFilterServices != "") {
+		return fmt.Errorf("cannot use --monitor-id together with filter flags")
+	}
+	if err := selector.validate(); err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	var monitors []datadog.Monitor
+	if setEscalationMonitorID > 0 {
+		monitor, err := client.GetMonitor(setEscalationMonitorID)
+		if err != nil {
+			reportMonitorError("getting monitor", err)
+			return err
+		}
+		monitors = []datadog.Monitor{*monitor}
+	} else {
+		monitors, err = fetchMonitors(client, selector)
+		if err != nil {
+			errOut.Printf("❌ Error listing monitors: %v\n", err)
+			return err
+		}
+	}
+
+	// Preview the changes
+	var toUpdate []datadog.Monitor
+	unchanged := 0
+	withoutRenotify := 0
+	for _, monitor := range monitors {
+		escalation := datadog.EscalationMessage(monitor)
+		edited, changed := edit.Apply(escalation)
+		if !changed {
+			unchanged++
+			continue
+		}
+		if len(toUpdate) == 0 {
+			out.Println("\n📝 Escalation message changes:")
+		}
+		toUpdate = append(toUpdate, monitor)
+		out.Printf("\n   ID %d: %s\n", monitor.ID, monitor.Name)
+		out.Printf("      - %s\n", messageSnippet(escalation, edited))
+		out.Printf("      + %s\n", messageSnippet(edited, escalation))
+		if renotify := datadog.RenotifyFromOptions(monitor.Options); edited != "" && (renotify.Interval == nil || *renotify.Interval == 0) {
+			withoutRenotify++
+		}
+	}
+
+	if len(toUpdate) == 0 {
+		out.Printf("ℹ️  No escalation messages to change (%d monitor(s) matched, all already up to date)\n", len(monitors))
+		return nil
+	}
+
+	out.Printf("\n📊 %d monitor(s) will be updated, %d already up to date\n", len(toUpdate), unchanged)
+	if withoutRenotify > 0 {
+		out.Printf("💡 %d of them don't renotify, so the escalation message won't be sent until you enable it with set-renotify --interval\n", withoutRenotify)
+	}
+
+	confirmed, err := confirm(len(toUpdate), "update the escalation message of", nil)
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+	if !confirmed {
+		out.Println("❌ Update cancelled")
+		return nil
+	}
+
+	updated := 0
+	var failures bulkFailures
+	for i, monitor := range toUpdate {
+		if failures.stop(i, nil) {
+			break
+		}
+		_, changed, err := client.EditMonitorEscalation(monitor.ID, edit)
+		if err != nil {
+			if failures.stop(i, err) {
+				break
+			}
+			failures.add(monitor, err)
+			continue
+		}
+		if changed {
+			updated++
+		} else {
+			unchanged++
+		}
+	}
+
+	failures.printInterrupted(len(toUpdate))
+	out.Printf("\n📊 Results:\n")
+	out.Printf("✅ Successfully updated: %d\n", updated)
+	out.Printf("⏭️  Unchanged: %d\n", unchanged)
+	failures.printCounts()
+
+	failures.printDetails("update")
+
+	return failures.interrupted
+}
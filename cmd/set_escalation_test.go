@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+func TestSetEscalationKeepsOptions(t *testing.T) {
+	srv := newTestServer(t)
+	monitor := srv.AddMonitor(datadog.Monitor{
+		Name:  "api CPU",
+		Type:  "metric alert",
+		Query: "avg(last_5m):avg:cpu{service:api,env:prd} > 90",
+		Tags:  []string{"service:api", "env:prd"},
+		Options: map[string]interface{}{
+			"escalation_message": "Still alerting: @pagerduty-old",
+			"renotify_interval":  60,
+			"notify_no_data":     true,
+		},
+	})
+	id := strconv.Itoa(monitor.ID)
+
+	res := runCLI(t, nil, "set-escalation", "--yes", "--monitor-id", id, "--replace", "@pagerduty-old=@pagerduty-secondary")
+	if res.Err != nil {
+		t.Fatalf("set-escalation: %v\n%s", res.Err, res.Stderr)
+	}
+	srv.AssertRequestCount(t, 1, "PUT", "/monitor/"+id)
+	m, _ := srv.Monitor(monitor.ID)
+	if got := datadog.EscalationMessage(m); got != "Still alerting: @pagerduty-secondary" {
+		t.Errorf("escalation message %q", got)
+	}
+	if m.Options["renotify_interval"] != float64(60) || m.Options["notify_no_data"] != true {
+		t.Errorf("other options lost by set-escalation: %v", m.Options)
+	}
+
+	// Updating something else keeps the escalation message
+	res = runCLI(t, nil, "edit-message", "--yes", "--monitor-id", id, "--append", " @slack-api")
+	if res.Err != nil {
+		t.Fatalf("edit-message: %v\n%s", res.Err, res.Stderr)
+	}
+	m, _ = srv.Monitor(monitor.ID)
+	if !strings.HasSuffix(m.Message, "@slack-api") {
+		t.Fatalf("message %q not edited", m.Message)
+	}
+	if got := datadog.EscalationMessage(m); got != "Still alerting: @pagerduty-secondary" {
+		t.Errorf("escalation message %q after updating the message", got)
+	}
+
+	// Running the same edit again changes nothing
+	srv.ResetRequests()
+	res = runCLI(t, nil, "set-escalation", "--yes", "--monitor-id", id, "--replace", "@pagerduty-old=@pagerduty-secondary")
+	if res.Err != nil {
+		t.Fatalf("set-escalation: %v\n%s", res.Err, res.Stderr)
+	}
+	srv.AssertNoMutations(t)
+
+	res = runCLI(t, nil, "set-escalation", "--yes", "--monitor-id", id, "--clear")
+	if res.Err != nil {
+		t.Fatalf("set-escalation --clear: %v\n%s", res.Err, res.Stderr)
+	}
+	if m, _ := srv.Monitor(monitor.ID); datadog.EscalationMessage(m) != "" || m.Options["renotify_interval"] != float64(60) {
+		t.Errorf("options after clearing the escalation message: %v", m.Options)
+	}
+}
+
+func TestSetEscalationFlagErrors(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--monitor-id", "1000"}, "at least one of --set, --append, --replace or --clear is required"},
+		{[]string{"--monitor-id", "1000", "--clear", "--set", "x"}, "--clear cannot be combined"},
+		{[]string{"--set", "x"}, "either --monitor-id or filter flags"},
+		{[]string{"--monitor-id", "1000", "--replace", "nothing"}, "expected old=new"},
+	} {
+		srv := newTestServer(t)
+		res := runCLI(t, nil, append([]string{"set-escalation", "--yes"}, tc.args...)...)
+		if res.Err == nil || !strings.Contains(res.Err.Error(), tc.want) {
+			t.Errorf("set-escalation %v = %v, want %q", tc.args, res.Err, tc.want)
+		}
+		if got := len(srv.Requests()); got != 0 {
+			t.Errorf("set-escalation %v made %d request(s)", tc.args, got)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var setRenotifyCmd = &cobra.Command{
+	Use:   "set-renotify",
+	Short: "Change renotification settings of monitors in bulk",
+	Long: `Set renotify_interval, renotify_occurrences, renotify_statuses and
+notification_preset_name on a single monitor or on multiple monitors matching filters.
+
+Only the settings passed as flags are changed. --interval 0 disables renotification.
+
+Valid statuses: alert, warn, no data
+Valid presets:  show_all, hide_query, hide_handles, hide_all
+
+Examples:
+  set-renotify --service myapp --interval 30 --occurrences 3
+  set-renotify --env prd --statuses "alert,no data" --preset hide_query
+  set-renotify --monitor-id 12345 --interval 0`,
+	RunE: runSetRenotify,
+}
+
+var (
+	setRenotifyMonitorID      int
+	setRenotifyService        string
+	setRenotifyEnv            string
+	setRenotifyNamespace      string
+	setRenotifyFilterTags     string
+	setRenotifyQuery          string
+	setRenotifyStatus         string
+	setRenotifyFilterServices string
+	setRenotifyInterval       int
+	setRenotifyOccurrences    int
+	setRenotifyStatuses       string
+	setRenotifyPreset         string
+)
+
+func init() {
+	rootCmd.AddCommand(setRenotifyCmd)
+	setRenotifyCmd.Flags().IntVar(&setRenotifyMonitorID, "monitor-id", 0, "Monitor ID (for single monitor)")
+	setRenotifyCmd.Flags().StringVar(&setRenotifyService, "service", "", "Filter by service (for multiple monitors)")
+	setRenotifyCmd.Flags().StringVar(&setRenotifyEnv, "env", "", "Filter by environment (for multiple monitors)")
+	setRenotifyCmd.Flags().StringVar(&setRenotifyNamespace, "namespace", "", "Filter by namespace (for multiple monitors)")
+	setRenotifyCmd.Flags().StringVar(&setRenotifyFilterTags, "filter-tags", "", "Filter by tags (comma-separated, for multiple monitors)")
+	setRenotifyCmd.Flags().StringVar(&setRenotifyQuery, "query", "", "Complex search query (e.g., service:(service1 OR service2))")
+	setRenotifyCmd.Flags().StringVar(&setRenotifyStatus, "status", "", "Filter by monitor state, comma-separated (OK, Alert, Warn, No Data, Unknown, Skipped, Ignored)")
+	setRenotifyCmd.Flags().StringVar(&setRenotifyFilterServices, "filter-services", "", "Filter by multiple services (comma-separated, filters locally after query/tags)")
+	setRenotifyCmd.Flags().IntVar(&setRenotifyInterval, "interval", 0, "Minutes between renotifications (0 disables renotify)")
+	setRenotifyCmd.Flags().IntVar(&setRenotifyOccurrences, "occurrences", 0, "Number of renotifications to send")
+	setRenotifyCmd.Flags().StringVar(&setRenotifyStatuses, "statuses", "", "States that renotify (comma-separated: alert, warn, no data)")
+	setRenotifyCmd.Flags().StringVar(&setRenotifyPreset, "preset", "", "Notification preset (show_all, hide_query, hide_handles, hide_all)")
+}
+
+func runSetRenotify(cmd *cobra.Command, args []string) error {
+	var settings datadog.RenotifySettings
+	if cmd.Flags().Changed("interval") {
+		settings.Interval = &setRenotifyInterval
+	}
+	if cmd.Flags().Changed("occurrences") {
+		settings.Occurrences = &setRenotifyOccurrences
+	}
+	for _, status := range splitCommaList(setRenotifyStatuses) {
+		settings.Statuses = append(settings.Statuses, strings.ToLower(status))
+	}
+	settings.NotificationPreset = setRenotifyPreset
+
+	if settings.IsEmpty() {
+		return fmt.Errorf("at least one of --interval, --occurrences, --statuses or --preset is required")
+	}
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	selector := monitorSelector{
+		Query:          setRenotifyQuery,
+		Service:        setRenotifyService,
+		Env:            setRenotifyEnv,
+		Namespace:      setRenotifyNamespace,
+		Tags:           splitCommaList(setRenotifyFilterTags),
+		Status:         setRenotifyStatus,
+		FilterServices: setRenotifyFilterServices,
+		Mutating:       true,
+	}
+
+	if setRenotifyMonitorID == 0 && !selector.hasFilters() {
+		return fmt.Errorf("either --monitor-id or filter flags (--service, --env, --namespace, --filter-tags, --query) must be provided")
+	}
+	if setRenotifyMonitorID > 0 && (selector.hasFilters() || setRenotifyStatus != "" || setRenotifyFilterServices != "") {
+		return fmt.Errorf("cannot use --monitor-id together with filter flags")
+	}
+	if err := selector.validate(); err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	var monitors []datadog.Monitor
+	if setRenotifyMonitorID > 0 {
+		monitor, err := client.GetMonitor(setRenotifyMonitorID)
+		if err != nil {
+			reportMonitorError("getting monitor", err)
+			return err
+		}
+		monitors = []datadog.Monitor{*monitor}
+	} else {
+		monitors, err = fetchMonitors(client, selector)
+		if err != nil {
+			errOut.Printf("❌ Error listing monitors: %v\n", err)
+			return err
+		}
+	}
+
+	// Preview the changes
+	var toUpdate []datadog.Monitor
+	unchanged := 0
+	for _, monitor := range monitors {
+		before := datadog.RenotifyFromOptions(monitor.Options)
+		preview := monitor
+		if !settings.ApplyTo(&preview) {
+			unchanged++
+			continue
+		}
+		if len(toUpdate) == 0 {
+			out.Println("\n🔔 Renotify changes:")
+		}
+		toUpdate = append(toUpdate, monitor)
+		out.Printf("\n   ID %d: %s\n", monitor.ID, monitor.Name)
+		out.Printf("      - %s\n", before)
+		out.Printf("      + %s\n", datadog.RenotifyFromOptions(preview.Options))
+	}
+
+	if len(toUpdate) == 0 {
+		out.Printf("ℹ️  No renotify settings to change (%d monitor(s) matched, all already up to date)\n", len(monitors))
+		return nil
+	}
+
+	out.Printf("\n📊 %d monitor(s) will be updated, %d already up to date\n", len(toUpdate), unchanged)
+
+	confirmed, err := confirm(len(toUpdate), "update the renotify settings of", nil)
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+	if !confirmed {
+		out.Println("❌ Update cancelled")
+		return nil
+	}
+
+	updated := 0
+	var failures bulkFailures
+	for i, monitor := range toUpdate {
+		if failures.stop(i, nil) {
+			break
+		}
+		_, changed, err := client.SetMonitorRenotify(monitor.ID, settings)
+		if err != nil {
+			if failures.stop(i, err) {
+				break
+			}
+			failures.add(monitor, err)
+			continue
+		}
+		if changed {
+			updated++
+		} else {
+			unchanged++
+		}
+	}
+
+	failures.printInterrupted(len(toUpdate))
+	out.Printf("\n📊 Results:\n")
+	out.Printf("✅ Successfully updated: %d\n", updated)
+	out.Printf("⏭️  Unchanged: %d\n", unchanged)
+	failures.printCounts()
+
+	failures.printDetails("update")
+
+	return failures.interrupted
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var staleMetricsCmd = &cobra.Command{
+	Use:   "stale-metrics",
+	Short: "Find metric monitors on metrics that stopped reporting",
+	Long: `Find the metric monitors, among those matching the filters, whose query reads
+a metric that hasn't reported a value within --since (default 1d): typically a
+metric that was renamed or whose integration was removed, leaving the monitor
+in No Data for good.
+
+The metric names are read from every aggregator:metric{scope} term of the
+query, arithmetic (a / b * 100) and functions (anomalies, top, rollup, ...)
+included, and checked against the metrics that reported since --since. Metric
+monitors no metric name can be read from are counted and left alone.
+
+--action mute mutes the stale monitors (whole monitor, no end time) and
+--action delete deletes them, after a confirmation.
+
+Examples:
+  stale-metrics --env prd
+  stale-metrics --service myapp --since 7d
+  stale-metrics --namespace legacy --action mute
+  stale-metrics --filter-tags team:payments --action delete --yes`,
+	RunE: runStaleMetrics,
+}
+
+var (
+	staleMetricsService    string
+	staleMetricsEnv        string
+	staleMetricsNamespace  string
+	staleMetricsFilterTags string
+	staleMetricsQuery      string
+	staleMetricsSince      string
+	staleMetricsAction     string
+)
+
+func init() {
+	rootCmd.AddCommand(staleMetricsCmd)
+	staleMetricsCmd.Flags().StringVar(&staleMetricsService, "service", "", "Filter by service")
+	staleMetricsCmd.Flags().StringVar(&staleMetricsEnv, "env", "", "Filter by environment")
+	staleMetricsCmd.Flags().StringVar(&staleMetricsNamespace, "namespace", "", "Filter by namespace")
+	staleMetricsCmd.Flags().StringVar(&staleMetricsFilterTags, "filter-tags", "", "Filter by tags (comma-separated)")
+	staleMetricsCmd.Flags().StringVar(&staleMetricsQuery, "query", "", "Complex search query (e.g., service:(service1 OR service2))")
+	staleMetricsCmd.Flags().StringVar(&staleMetricsSince, "since", "1d", "A metric is stale when it hasn't reported within this long (e.g., 12h, 1d, 2w)")
+	staleMetricsCmd.Flags().StringVar(&staleMetricsAction, "action", "", "What to do with the stale monitors after a confirmation: mute or delete (default: only report them)")
+}
+
+func runStaleMetrics(cmd *cobra.Command, args []string) error {
+	since, err := parseDuration(staleMetricsSince)
+	if err != nil || since <= 0 {
+		return fmt.Errorf("invalid --since %q (e.g., 12h, 1d, 2w)", staleMetricsSince)
+	}
+	switch staleMetricsAction {
+	case "", "mute", "delete":
+	default:
+		return fmt.Errorf("invalid --action %q (must be mute or delete)", staleMetricsAction)
+	}
+
+	selector := monitorSelector{
+		Query:     staleMetricsQuery,
+		Service:   staleMetricsService,
+		Env:       staleMetricsEnv,
+		Namespace: staleMetricsNamespace,
+		Tags:      splitCommaList(staleMetricsFilterTags),
+		Cacheable: staleMetricsAction == "",
+		Mutating:  staleMetricsAction != "",
+	}
+	if err := selector.validate(); err != nil {
+		return err
+	}
+	if !selector.hasFilters() {
+		return fmt.Errorf("at least one filter is required (--service, --env, --namespace, --filter-tags or --query)")
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	monitors, err := fetchMonitors(client, selector)
+	if err != nil {
+		errOut.Printf("❌ Error listing monitors: %v\n", err)
+		return err
+	}
+	active, err := client.ActiveMetrics(time.Now().Add(-since))
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	stale, unparsed := datadog.FindStaleMetricMonitors(monitors, active)
+	checked := 0
+	for _, monitor := range monitors {
+		if datadog.IsMetricMonitor(monitor.Type) {
+			checked++
+		}
+	}
+
+	out.Printf("\n🔍 Checked %d metric monitor(s) against the metrics that reported in the last %s\n", checked-len(unparsed), formatDuration(since))
+	if len(unparsed) > 0 {
+		logVerbose("no metric name found in the queries of %d monitor(s): %s", len(unparsed), strings.Join(monitorSample(unparsed), "; "))
+		out.Printf("⏭️  %d metric monitor(s) without a metric name in their query were skipped\n", len(unparsed))
+	}
+	if len(stale) == 0 {
+		out.Println("✅ No monitors on stale metrics")
+		return nil
+	}
+
+	out.Printf("\n🕸️  %d monitor(s) on metrics that stopped reporting:\n", len(stale))
+	staleMonitors := make([]datadog.Monitor, len(stale))
+	for i, s := range stale {
+		staleMonitors[i] = s.Monitor
+		out.Printf("   ID %d: %s (%s)\n", s.Monitor.ID, s.Monitor.Name, listState(s.Monitor))
+		out.Printf("      Missing: %s\n", strings.Join(s.Missing, ", "))
+	}
+
+	if staleMetricsAction == "" {
+		out.Println("\n💡 Mute or delete them with --action mute or --action delete")
+		return nil
+	}
+	return actOnStaleMonitors(client, staleMonitors)
+}
+
+// actOnStaleMonitors mutes or deletes the stale monitors (--action) after a
+// confirmation
+func actOnStaleMonitors(client *datadog.Client, monitors []datadog.Monitor) error {
+	action, done := "mute", "muted"
+	if staleMetricsAction == "delete" {
+		action, done = "permanently delete", "deleted"
+	}
+
+	var targets []datadog.Monitor
+	for _, monitor := range monitors {
+		if staleMetricsAction == "mute" && datadog.IsScopeSilenced(monitor, datadog.WholeMonitorScope) {
+			out.Printf("⏭️  ID %d: %s already muted\n", monitor.ID, monitor.Name)
+			continue
+		}
+		targets = append(targets, monitor)
+	}
+	if len(targets) == 0 {
+		out.Println("ℹ️  Nothing left to mute")
+		return nil
+	}
+
+	// The monitors were just listed; the prompt needs no sample
+	confirmed, err := confirm(len(targets), action, nil)
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+	if !confirmed {
+		out.Println("❌ Cancelled")
+		return nil
+	}
+
+	succeeded := 0
+	var failures bulkFailures
+	for i, monitor := range targets {
+		if failures.stop(i, nil) {
+			break
+		}
+		if staleMetricsAction == "delete" {
+			err = client.DeleteMonitor(monitor.ID)
+		} else {
+			_, err = client.MuteMonitor(monitor.ID, "", time.Time{})
+		}
+		if err != nil {
+			if failures.stop(i, err) {
+				break
+			}
+			failures.add(monitor, err)
+			continue
+		}
+		succeeded++
+		out.Printf("✅ ID %d: %s %s\n", monitor.ID, monitor.Name, done)
+	}
+
+	failures.printInterrupted(len(targets))
+	out.Printf("\n📊 Results:\n")
+	out.Printf("✅ Successfully %s: %d\n", done, succeeded)
+	failures.printCounts()
+	failures.printDetails(staleMetricsAction)
+	return failures.interrupted
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var (
+	showStats     bool
+	statsJSONFile string
+
+	// runStats collects the API calls of every client of the command, with
+	// --stats or --stats-json, and counts them against --request-budget and
+	// --fail-fast
+	runStats *datadog.Stats
+)
+
+var (
+	requestBudget int
+	failFast      int
+)
+
+// startStats starts collecting API call statistics when --stats or
+// --stats-json is set, or a --request-budget or --fail-fast limit needs them
+func startStats() {
+	if showStats || statsJSONFile != "" || requestBudget > 0 || failFast > 0 {
+		runStats = datadog.NewStats()
+		runStats.SetLimits(datadog.Limits{RequestBudget: requestBudget, FailFast: failFast})
+	}
+}
+
+// recordFailure counts a failed operation of a bulk loop against --fail-fast;
+// monitors that no longer exist are not failures
+func recordFailure(err error) {
+	if runStats != nil && err != nil && !datadog.IsMonitorNotFound(err) {
+		runStats.RecordFailure()
+	}
+}
+
+// reportStats prints the statistics table (--stats) to stderr and writes them
+// as JSON (--stats-json). It runs after the command, even a failed one.
+func reportStats() {
+	if runStats == nil {
+		return
+	}
+	summary := runStats.Summary()
+
+	if statsJSONFile != "" {
+		data, err := json.MarshalIndent(summary, "", "  ")
+		if err == nil {
+			err = os.WriteFile(statsJSONFile, append(data, '\n'), 0644)
+		}
+		if err != nil {
+			errOut.Printf("⚠️  Could not write stats file %s: %v\n", statsJSONFile, err)
+		}
+	}
+
+	if showStats {
+		printStats(summary)
+	}
+}
+
+// printStats prints the statistics as a table
+func printStats(summary datadog.StatsSummary) {
+	errOut.Printf("\n📈 API stats:\n")
+	errOut.Println(strings.Repeat("=", 80))
+	errOut.Printf("⏱️  Wall time: %s (API: %s)\n", formatMillis(summary.WallTimeMS), formatMillis(summary.APITimeMS))
+	errOut.Printf("📊 Requests: %d (errors: %d, retries: %d)\n", summary.Requests, summary.Errors, summary.Retries)
+	errOut.Printf("📦 Bytes: %s sent, %s received\n", formatBytes(summary.BytesSent), formatBytes(summary.BytesReceived))
+	if len(summary.Endpoints) == 0 {
+		return
+	}
+
+	endpointWidth := len("ENDPOINT")
+	for _, entry := range summary.Endpoints {
+		if len(entry.Endpoint) > endpointWidth {
+			endpointWidth = len(entry.Endpoint)
+		}
+	}
+	errOut.Printf("\n%-7s %-*s %6s %6s %10s %10s\n", "METHOD", endpointWidth, "ENDPOINT", "STATUS", "COUNT", "TOTAL", "AVG")
+	for _, entry := range summary.Endpoints {
+		status := "error"
+		if entry.Status != 0 {
+			status = fmt.Sprintf("%d", entry.Status)
+		}
+		errOut.Printf("%-7s %-*s %6s %6d %10s %10s\n", entry.Method, endpointWidth, entry.Endpoint, status, entry.Count,
+			formatMillis(entry.DurationMS), formatMillis(entry.DurationMS/float64(entry.Count)))
+	}
+}
+
+// formatMillis formats milliseconds as a rounded duration (e.g., 1.25s, 340ms)
+func formatMillis(ms float64) string {
+	d := time.Duration(ms * float64(time.Millisecond))
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(time.Millisecond).String()
+	default:
+		return d.Round(time.Microsecond).String()
+	}
+}
+
+// formatBytes formats a byte count with a binary unit (e.g., 12.3 KiB)
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, suffix := float64(n)/unit, "KiB"
+	for _, next := range []string{"MiB", "GiB"} {
+		if value < unit {
+			break
+		}
+		value, suffix = value/unit, next
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
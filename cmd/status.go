@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show a one-screen overview of monitor states",
+	Long: `Show how many monitors are in Alert, Warn, No Data and OK per env tag (and
+per priority when monitors have one), followed by the monitors currently
+alerting, highest priority first, with links to Datadog.
+
+With --watch the overview is refreshed in place at the given interval until
+Ctrl-C. --output json prints the overview as JSON for wallboards (one compact
+document per line with --watch).
+
+Examples:
+  status
+  status --service payments
+  status --watch 30s
+  status --output json`,
+	RunE: runStatus,
+}
+
+var (
+	statusOutput     string
+	statusWatch      time.Duration
+	statusService    string
+	statusEnv        string
+	statusNamespace  string
+	statusFilterTags string
+	statusQuery      string
+)
+
+// minStatusWatch is the shortest --watch interval, to stay clear of API rate limits
+const minStatusWatch = 5 * time.Second
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().StringVarP(&statusOutput, "output", "o", "table", "Output format: table or json")
+	statusCmd.Flags().DurationVar(&statusWatch, "watch", 0, "Refresh the overview at this interval (e.g., 30s) until Ctrl-C")
+	statusCmd.Flags().StringVar(&statusService, "service", "", "Filter by service")
+	statusCmd.Flags().StringVar(&statusEnv, "env", "", "Filter by environment")
+	statusCmd.Flags().StringVar(&statusNamespace, "namespace", "", "Filter by namespace")
+	statusCmd.Flags().StringVar(&statusFilterTags, "filter-tags", "", "Filter by tags (comma-separated)")
+	statusCmd.Flags().StringVar(&statusQuery, "query", "", "Complex search query (e.g., service:(service1 OR service2))")
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	if statusOutput != "table" && statusOutput != "json" {
+		return fmt.Errorf("invalid --output %q (must be table or json)", statusOutput)
+	}
+	if statusWatch != 0 && statusWatch < minStatusWatch {
+		return fmt.Errorf("--watch must be at least %s", minStatusWatch)
+	}
+
+	selector := monitorSelector{
+		Query:     statusQuery,
+		Service:   statusService,
+		Env:       statusEnv,
+		Namespace: statusNamespace,
+		Tags:      splitCommaList(statusFilterTags),
+	}
+	if err := selector.validate(); err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	for {
+		monitors, err := fetchMonitors(client, selector)
+		switch {
+		case err != nil && statusWatch != 0 && isInterrupted(err):
+			return nil
+		case err != nil && statusWatch != 0:
+			// Keep the wallboard running through transient errors
+			errOut.Printf("❌ Error listing monitors: %v (retrying in %s)\n", err, statusWatch)
+		case err != nil:
+			errOut.Printf("❌ Error listing monitors: %v\n", err)
+			return err
+		default:
+			if err := printStatus(datadog.BuildStatusOverview(monitors, client.AppURL())); err != nil {
+				return err
+			}
+		}
+
+		if statusWatch == 0 {
+			return nil
+		}
+		select {
+		case <-time.After(statusWatch):
+		case <-commandContext().Done():
+			return nil
+		}
+	}
+}
+
+// printStatus prints the overview as JSON or as a matrix, redrawing the
+// screen with --watch
+func printStatus(overview datadog.StatusOverview) error {
+	if statusOutput == "json" {
+		var jsonData []byte
+		var err error
+		if statusWatch != 0 {
+			jsonData, err = json.Marshal(overview)
+		} else {
+			jsonData, err = json.MarshalIndent(overview, "", "  ")
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	if statusWatch != 0 && stdoutIsTerminal() {
+		out.Print("\033[H\033[2J")
+	}
+	printStatusOverview(overview)
+	return nil
+}
+
+// statusColors are the ANSI colors of the state columns
+var statusColors = map[string]string{
+	"Alert":   "31", // red
+	"Warn":    "33", // yellow
+	"No Data": "35", // magenta
+	"OK":      "32", // green
+}
+
+// printStatusOverview prints the state × env (× priority) matrix and the
+// alerting monitors
+func printStatusOverview(overview datadog.StatusOverview) {
+	envWidth := len("TOTAL")
+	for _, row := range overview.Rows {
+		if len(row.Env) > envWidth {
+			envWidth = len(row.Env)
+		}
+	}
+	columns := append(append([]string{}, datadog.StatusStates...), datadog.OtherState)
+
+	out.Printf("\n📊 Monitor status: %d monitor(s) as of %s\n", overview.Total, formatTime(overview.Generated))
+	out.Println(strings.Repeat("=", 80))
+
+	header := fmt.Sprintf("%-*s", envWidth, "ENV")
+	if overview.ByPriority {
+		header += fmt.Sprintf(" %-8s", "PRIORITY")
+	}
+	for _, column := range columns {
+		header += fmt.Sprintf(" %8s", strings.ToUpper(column))
+	}
+	out.Printf("%s %8s\n", header, "TOTAL")
+
+	printRow := func(env, priority string, states map[string]int, total int) {
+		line := fmt.Sprintf("%-*s", envWidth, env)
+		if overview.ByPriority {
+			line += fmt.Sprintf(" %-8s", priority)
+		}
+		for _, column := range columns {
+			cell := fmt.Sprintf(" %8d", states[column])
+			if states[column] > 0 {
+				cell = colorize(statusColors[column], cell)
+			}
+			line += cell
+		}
+		out.Printf("%s %8d\n", line, total)
+	}
+	for _, row := range overview.Rows {
+		printRow(row.Env, row.Priority, row.States, row.Total)
+	}
+	out.Println(strings.Repeat("-", 80))
+	printRow("TOTAL", "", overview.States, overview.Total)
+
+	if len(overview.Alerting) == 0 {
+		out.Println(colorize(statusColors["OK"], "\n✅ No monitors alerting"))
+		return
+	}
+	out.Printf("\n🚨 Alerting monitors (%d):\n", len(overview.Alerting))
+	for _, monitor := range overview.Alerting {
+		priority := "  "
+		if monitor.Priority != 0 {
+			priority = fmt.Sprintf("P%d", monitor.Priority)
+		}
+		line := fmt.Sprintf("   %s  ID %d: %s [%s]", priority, monitor.ID, monitor.Name, strings.Join(monitor.Envs, ", "))
+		if monitor.Priority == 1 || monitor.Priority == 2 {
+			line = colorize(statusColors["Alert"], line)
+		}
+		out.Println(line)
+		if monitor.URL != "" {
+			out.Printf("         %s\n", monitor.URL)
+		}
+	}
+}
+
+// stdoutIsTerminal reports whether stdout is an interactive terminal
+func stdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// stderrIsTerminal reports whether stderr is an interactive terminal
+func stderrIsTerminal() bool {
+	info, err := os.Stderr.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorize wraps text in an ANSI color when stdout is a terminal and NO_COLOR is unset
+func colorize(color, text string) string {
+	if color == "" || os.Getenv("NO_COLOR") != "" || !stdoutIsTerminal() {
+		return text
+	}
+	return "\033[" + color + "m" + text + "\033[0m"
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTemplateTagReplacesSingleValuedKeys(t *testing.T) {
+	template := `{"name": "{service} CPU", "type": "metric alert", "query": "avg(last_5m):avg:cpu{service:{service}} > 90", "tags": ["team:old", "role:web"]}`
+	for _, tc := range []struct {
+		name         string
+		config       string
+		tags, absent []string
+		replaced     string
+	}{
+		{"default keys", "",
+			[]string{"team:new", "role:web", "role:api"}, []string{"team:old"},
+			"Replaced tag team:old with team:new"},
+		{"keys from the config file", "single_valued_tag_keys: [role]\n",
+			[]string{"team:old", "team:new", "role:api"}, []string{"role:web"},
+			"Replaced tag role:web with role:api"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := newTestServer(t)
+			if tc.config != "" {
+				writeConfig(t, tc.config)
+			}
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "cpu.json"), []byte(template), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			res := runCLI(t, nil, "template", "--template-dir", dir, "--service", "checkout", "--env", "prd", "--namespace", "shop",
+				"--tag", "team:new", "--tag", "role:api")
+			if res.Err != nil {
+				t.Fatalf("template: %v\n%s", res.Err, res.Stderr)
+			}
+			m, _ := srv.Monitor(1000)
+			for _, tag := range tc.tags {
+				if !slices.Contains(m.Tags, tag) {
+					t.Errorf("tags %v lack %s", m.Tags, tag)
+				}
+			}
+			for _, tag := range tc.absent {
+				if slices.Contains(m.Tags, tag) {
+					t.Errorf("tags %v still have %s", m.Tags, tag)
+				}
+			}
+			if strings.Count(res.Stdout, "Replaced tag") != 1 || !strings.Contains(res.Stdout, tc.replaced) {
+				t.Errorf("output does not show only %q:\n%s", tc.replaced, res.Stdout)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var teamsCmd = &cobra.Command{
+	Use:   "teams",
+	Short: "Monitor ownership by team",
+	Long:  `Report on monitor ownership using team tags`,
+}
+
+var teamsReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Show monitor counts and alert posture per team",
+	Long: `Group monitors by their team tag and show, per team, the number of monitors,
+counts by state, monitors whose message has no runbook and the monitor that
+has gone unmodified the longest.
+
+The tag key defaults to "team" and can be changed with --team-tag or
+team_tag_key in the config file. With --check-teams, team tags are compared
+against the Datadog Teams API and tags without a matching team are flagged.
+
+Examples:
+  teams report
+  teams report --env prd --check-teams
+  teams report --team payments
+  teams report --output json`,
+	RunE: runTeamsReport,
+}
+
+var (
+	teamsReportTeamTag    string
+	teamsReportTeam       string
+	teamsReportOutput     string
+	teamsReportCheckTeams bool
+	teamsReportService    string
+	teamsReportEnv        string
+	teamsReportNamespace  string
+	teamsReportFilterTags string
+	teamsReportQuery      string
+)
+
+func init() {
+	rootCmd.AddCommand(teamsCmd)
+	teamsCmd.AddCommand(teamsReportCmd)
+	teamsReportCmd.Flags().StringVar(&teamsReportTeamTag, "team-tag", "", "Tag key that identifies the owning team (default: team_tag_key from the config file, or team)")
+	teamsReportCmd.Flags().StringVar(&teamsReportTeam, "team", "", "Only show this team, with its monitor list")
+	teamsReportCmd.Flags().StringVarP(&teamsReportOutput, "output", "o", "table", "Output format: table or json")
+	teamsReportCmd.Flags().BoolVar(&teamsReportCheckTeams, "check-teams", false, "Flag team tags that don't match a Datadog Team")
+	teamsReportCmd.Flags().StringVar(&teamsReportService, "service", "", "Filter by service")
+	teamsReportCmd.Flags().StringVar(&teamsReportEnv, "env", "", "Filter by environment")
+	teamsReportCmd.Flags().StringVar(&teamsReportNamespace, "namespace", "", "Filter by namespace")
+	teamsReportCmd.Flags().StringVar(&teamsReportFilterTags, "filter-tags", "", "Filter by tags (comma-separated)")
+	teamsReportCmd.Flags().StringVar(&teamsReportQuery, "query", "", "Complex search query (e.g., service:(service1 OR service2))")
+}
+
+// teamStateColumns are the states shown as their own column in the team report;
+// any other state is counted as "other"
+var teamStateColumns = []string{"OK", "Alert", "Warn", "No Data"}
+
+func runTeamsReport(cmd *cobra.Command, args []string) error {
+	if teamsReportOutput != "table" && teamsReportOutput != "json" {
+		return fmt.Errorf("invalid --output %q (must be table or json)", teamsReportOutput)
+	}
+
+	tagKey := teamsReportTeamTag
+	if tagKey == "" {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		tagKey = cfg.TeamTagKey
+	}
+	if tagKey == "" {
+		tagKey = datadog.DefaultTeamTagKey
+	}
+
+	selector := monitorSelector{
+		Query:     teamsReportQuery,
+		Service:   teamsReportService,
+		Env:       teamsReportEnv,
+		Namespace: teamsReportNamespace,
+		Tags:      splitCommaList(teamsReportFilterTags),
+		Cacheable: true,
+	}
+	if teamsReportTeam != "" && teamsReportTeam != datadog.NoTeam && teamsReportQuery == "" {
+		// Let the API narrow the list down to the team
+		selector.Tags = append(selector.Tags, fmt.Sprintf("%s:%s", tagKey, teamsReportTeam))
+	}
+	if err := selector.validate(); err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	monitors, err := fetchMonitors(client, selector)
+	if err != nil {
+		errOut.Printf("❌ Error listing monitors: %v\n", err)
+		return err
+	}
+
+	report := datadog.BuildTeamReport(monitors, tagKey)
+
+	if teamsReportCheckTeams {
+		teams, err := client.ListTeams()
+		if err != nil {
+			errOut.Printf("❌ Error listing teams: %v\n", err)
+			return err
+		}
+		datadog.MarkKnownTeams(report, teams)
+	}
+
+	if teamsReportTeam != "" {
+		var selected []datadog.TeamSummary
+		for _, summary := range report {
+			if summary.Team == teamsReportTeam {
+				selected = append(selected, summary)
+			}
+		}
+		if len(selected) == 0 {
+			out.Printf("ℹ️  No monitors found for %s:%s\n", tagKey, teamsReportTeam)
+			return nil
+		}
+		report = selected
+	} else {
+		// The monitor lists are only part of the drill-down
+		for i := range report {
+			report[i].Monitors = nil
+		}
+	}
+
+	if teamsReportOutput == "json" {
+		jsonData, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	printTeamReport(report, tagKey)
+	if teamsReportTeam != "" {
+		printTeamMonitors(report[0])
+	}
+
+	return nil
+}
+
+// printTeamReport prints the team summaries as a table
+func printTeamReport(report []datadog.TeamSummary, tagKey string) {
+	teamWidth := len("TEAM")
+	for _, summary := range report {
+		if len(summary.Team) > teamWidth {
+			teamWidth = len(summary.Team)
+		}
+	}
+
+	out.Printf("\n👥 Monitors by %s tag:\n", tagKey)
+	out.Println(strings.Repeat("=", 80))
+	out.Printf("%-*s %8s %6s %6s %6s %8s %6s %11s  %s\n", teamWidth, "TEAM", "MONITORS", "OK", "ALERT", "WARN", "NO DATA", "OTHER", "NO RUNBOOK", "OLDEST UNMODIFIED")
+
+	total := 0
+	var unknown []string
+	for _, summary := range report {
+		other := summary.Total
+		counts := make([]int, len(teamStateColumns))
+		for i, state := range teamStateColumns {
+			counts[i] = summary.States[state]
+			other -= counts[i]
+		}
+
+		oldest := "-"
+		if summary.OldestUnmodified != nil {
+			oldest = fmt.Sprintf("%s (ID %d)", formatModified(*summary.OldestUnmodified), summary.OldestUnmodified.ID)
+		}
+
+		out.Printf("%-*s %8d %6d %6d %6d %8d %6d %11d  %s\n", teamWidth, summary.Team, summary.Total, counts[0], counts[1], counts[2], counts[3], other, summary.MissingRunbook, oldest)
+
+		total += summary.Total
+		if summary.KnownTeam != nil && !*summary.KnownTeam {
+			unknown = append(unknown, summary.Team)
+		}
+	}
+
+	out.Printf("\n📊 %d team(s), %d monitor(s)\n", len(report), total)
+	if len(unknown) > 0 {
+		out.Printf("⚠️  %s tag(s) without a matching Datadog Team: %s\n", tagKey, strings.Join(unknown, ", "))
+	}
+}
+
+// printTeamMonitors prints the monitor list of one team
+func printTeamMonitors(summary datadog.TeamSummary) {
+	out.Printf("\n📋 Monitors of %s:\n", summary.Team)
+	for _, monitor := range summary.Monitors {
+		runbook := ""
+		if !datadog.HasRunbook(monitor) {
+			runbook = " 📕 no runbook"
+		}
+		out.Printf("   ID %d: %s [%s] (modified: %s)%s\n", monitor.ID, monitor.Name, monitor.OverallState, formatModified(monitor), runbook)
+	}
+}
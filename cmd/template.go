@@ -3,173 +3,1111 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/fetch"
 )
 
 var templateCmd = &cobra.Command{
-	Use:   "template",
-	Short: "Apply monitor templates from JSON files",
-	Long:  `Apply monitor templates from JSON files`,
-	RunE:  runTemplate,
+	Use:     "template",
+	Aliases: []string{"templates"},
+	Short:   "Apply monitor templates from JSON files",
+	Long: `Apply monitor templates from JSON files
+
+The {service}, {env} and {namespace} placeholders and {key} variables are
+replaced in names, queries and messages as given; a modifier changes the case
+of the value: {env:upper}, {service:title} (my-app gives My-App) or
+{namespace:lower}. {env} in names is no longer uppercased implicitly: use
+{env:upper}, or the deprecated --uppercase-env-in-name for one more release.
+
+With --for-each-tag, the templates are applied once per distinct value of a tag
+key found on existing monitors. For service and namespace the value is bound to
+{service}/{namespace}; any other key is available as a {key} variable.
+
+Templates with "disabled": true are validated but never applied. --only and
+--skip select templates by glob patterns matched against template names and
+template file names; templates excluded by them are not validated either.
+Skipped templates are counted separately in the summary.
+
+--threshold-scale multiplies the thresholds of the rendered monitors of an
+environment (e.g. dev=2.0 makes dev alert at twice the prd values) and
+rewrites the query's comparison value to match; threshold_scale in the config
+file sets the factors for every run. --dry-run shows the scaled values.
+
+options.notify_by must name tags or facets the query groups by ("by {...}"
+or .by(...)); a rendered monitor that doesn't fails with the offending values,
+before any API call. --fix-notify-by drops them with a warning instead.
+
+--preset merges the options of a named preset (business-hours, low-noise,
+strict, or one from the config file; see presets list) into every rendered
+template, under the template's own options; later presets win.
+
+--summary-only replaces the line per monitor with one line per template file
+(created/updated/unchanged/failed counts) followed by the totals, for CI logs;
+files that fail are still printed with their error, and failed templates and
+conflicts are still listed at the end. --output json prints the per-file
+breakdown as JSON on stdout instead (human output goes to stderr).
+
+Examples:
+  template --service myapp --env prd --namespace myapp
+  template --service myapp --env prd --namespace myapp --atomic
+  template --service myapp --env prd --namespace myapp --summary-only
+  template --service myapp --env prd --namespace myapp --preset low-noise --preset business-hours
+  template --service myapp --env prd --namespace myapp --output json > apply-summary.json
+  template --service myapp --env prd --namespace myapp --create-muted=24h
+  template --service myapp --env dev --namespace myapp --threshold-scale dev=2.0,hml=1.5 --dry-run
+  template --interactive
+  template --service myapp --env prd --namespace myapp --var team=payments
+  template --service myapp --env prd --namespace myapp --only "CPU*,Memory*" --skip "JVM*"
+  template --service myapp --env prd --namespace myapp --template-dir 'git::https://github.com/org/monitors.git//k8s?ref=v1.2.0'
+  template --for-each-tag service --for-each-filter env:prd --env prd --namespace apps --exclude 'legacy-*' --dry-run`,
+	RunE: runTemplate,
 }
 
 var (
-	templateService   string
-	templateEnv       string
-	templateNamespace string
-	templateFile      string
-	templateDir       string
-	templateNoUpsert  bool
-	templateTags      []string
+	templateService     string
+	templateEnv         string
+	templateNamespace   string
+	templateFile        string
+	templateDir         string
+	templateNoUpsert    bool
+	templateAllowDup    bool
+	templateSuffix      bool
+	templateAllowAnyEnv bool
+	templateNoSchema    bool
+	templateTags        []string
+	templateStrictScope bool
+	templateProtect     bool
+	templateAtomic      bool
+	templateInteractive bool
+	templateVars        []string
+	templateOnly        string
+	templateSkip        string
+
+	templateForEachTag    string
+	templateForEachFilter string
+	templateExclude       []string
+	templateDryRun        bool
+	templateMaxIterations int
+	templateNameOverflow  string
+	templateK8sDefaults   bool
+	templateK8sEvalDelay  int
+	templateK8sGroupDelay int
+	templatePreviewData   bool
+	templateStateFile     string
+	templateRefresh       bool
+	templateRefreshRemote bool
+	templateOutputFile    string
+
+	templateCreateMuted    string
+	templateThresholdScale string
+	templateMatchBy        string
+	templateFixNotifyBy    bool
+	templateUppercaseEnv   bool
+
+	templateSummaryOnly bool
+	templateOutput      string
+	templatePresets     []string
 )
 
+// templateReceipt collects the apply results for --output-file
+var templateReceipt *datadog.ApplyReceipt
+
+// templateSummary collects the per-file breakdown for --output json
+var templateSummary *templateApplySummary
+
 func init() {
 	rootCmd.AddCommand(templateCmd)
-	templateCmd.Flags().StringVar(&templateService, "service", "", "Service name (required)")
-	templateCmd.MarkFlagRequired("service")
-	templateCmd.Flags().StringVar(&templateEnv, "env", "", "Environment: dev, hml, prd, corp (required)")
-	templateCmd.MarkFlagRequired("env")
-	templateCmd.Flags().StringVar(&templateNamespace, "namespace", "", "Kubernetes namespace (required)")
-	templateCmd.MarkFlagRequired("namespace")
-	templateCmd.Flags().StringVarP(&templateFile, "file", "f", "", "Path to JSON template file")
-	templateCmd.Flags().StringVar(&templateDir, "template-dir", "templates", "Directory containing JSON templates (default: templates/)")
+	templateCmd.Flags().StringVar(&templateService, "service", "", "Service name (required unless bound by --for-each-tag)")
+	templateCmd.Flags().StringVar(&templateEnv, "env", "", "Environment, e.g. dev, hml, prd, corp (required; validated against 'environments' in the config file)")
+	templateCmd.Flags().StringVar(&templateNamespace, "namespace", "", "Kubernetes namespace (required unless bound by --for-each-tag)")
+	templateCmd.Flags().StringVarP(&templateFile, "file", "f", "", "Path or https:// URL of a JSON template file, or a git:: source")
+	templateCmd.Flags().StringVar(&templateDir, "template-dir", "templates", "Directory containing JSON templates, or a git:: source (default: templates/)")
+	templateCmd.Flags().BoolVar(&templateRefreshRemote, "refresh-templates", false, "Fetch remote template sources again instead of using the cached copy")
 	templateCmd.Flags().BoolVar(&templateNoUpsert, "no-upsert", false, "Only create new monitors (fail if exists). Default is to update existing monitors.")
-	templateCmd.Flags().StringArrayVar(&templateTags, "tag", []string{}, "Additional tags to add to monitors (can be used multiple times)")
+	templateCmd.Flags().BoolVar(&templateAllowDup, "allow-duplicate", false, "With --no-upsert, create a second monitor when the name already exists")
+	templateCmd.Flags().BoolVar(&templateSuffix, "suffix-on-conflict", false, "With --no-upsert, append \" (2)\", \" (3)\", ... to names that already exist")
+	templateCmd.Flags().BoolVar(&templateAllowAnyEnv, "allow-any-env", false, "Accept any environment name without validation or warnings")
+	templateCmd.Flags().BoolVar(&templateNoSchema, "no-schema-validation", false, "Skip validating templates against the monitor template schema")
+	templateCmd.Flags().BoolVar(&templateStrictScope, "strict-scope", false, "Fail when a template query is scoped to another env or service than the one applied")
+	templateCmd.Flags().BoolVar(&templateProtect, "protect-unmanaged", false, "Don't update existing monitors without the managed-by:ddmm tag; report them as conflicts (exit code 4)")
+	templateCmd.Flags().BoolVar(&templateAtomic, "atomic", false, "Validate every monitor with the API before writing any, and roll back this run's changes if a write fails")
+	templateCmd.Flags().StringArrayVar(&templateVars, "var", []string{}, "Template variable replacing {key} placeholders, as key=value (can be used multiple times)")
+	templateCmd.Flags().BoolVar(&templateInteractive, "interactive", false, "Prompt for missing --service, --env, --namespace and template variables (only when stdin is a terminal)")
+	templateCmd.Flags().StringVar(&templateOnly, "only", "", "Only apply templates whose name or file name matches these globs (comma-separated, e.g., \"CPU*,Memory*\")")
+	templateCmd.Flags().StringVar(&templateSkip, "skip", "", "Skip templates whose name or file name matches these globs (comma-separated, e.g., \"JVM*\")")
+	templateCmd.Flags().StringVar(&templateNameOverflow, "name-overflow", "error", "What to do with monitor names over 200 characters: error, truncate-hash or abbreviate (the service)")
+	templateCmd.Flags().BoolVar(&templateK8sDefaults, "k8s-defaults", false, "Set evaluation_delay and new_group_delay on metric monitors on kubernetes./container. metrics when the template doesn't")
+	templateCmd.Flags().IntVar(&templateK8sEvalDelay, "k8s-evaluation-delay", 0, "evaluation_delay --k8s-defaults sets, in seconds (default: k8s_defaults in the config file, or 300)")
+	templateCmd.Flags().IntVar(&templateK8sGroupDelay, "k8s-new-group-delay", 0, "new_group_delay --k8s-defaults sets, in seconds (default: k8s_defaults in the config file, or 300)")
+	templateCmd.Flags().StringArrayVar(&templateTags, "tag", []string{}, "Additional tags to add to monitors (can be used multiple times); a tag of a single-valued key such as team replaces the template's tag of that key")
+	templateCmd.Flags().StringVar(&templateForEachTag, "for-each-tag", "", "Apply the templates once per value of this tag key found on monitors (e.g., service)")
+	templateCmd.Flags().StringVar(&templateForEachFilter, "for-each-filter", "", "Only use tag values from monitors with these tags (comma-separated, e.g., env:prd)")
+	templateCmd.Flags().StringArrayVar(&templateExclude, "exclude", []string{}, "Skip tag values matching this glob (can be used multiple times)")
+	templateCmd.Flags().BoolVar(&templateDryRun, "dry-run", false, "Only render the monitors (with --for-each-tag, only list the expansion) without applying anything")
+	templateCmd.Flags().StringVar(&templateStateFile, "state-file", "", "Record applied template hashes here and skip the API when nothing changed")
+	templateCmd.Flags().BoolVar(&templateRefresh, "refresh", false, "With --state-file, apply even if the state says everything is up to date")
+	templateCmd.Flags().BoolVar(&templatePreviewData, "preview-data", false, "With --dry-run, evaluate each rendered metric monitor query against current data")
+	templateCmd.Flags().IntVar(&templateMaxIterations, "max-iterations", 50, "Refuse --for-each-tag expansions with more values than this")
+	templateCmd.Flags().StringVar(&templateOutputFile, "output-file", "", "Write the apply results (IDs, statuses, URLs, errors) as JSON to this file, or - for stdout (human output then goes to stderr)")
+	addCreateMutedFlag(templateCmd, &templateCreateMuted)
+	templateCmd.Flags().StringVar(&templateMatchBy, "match-by", "name", "How upserts find a monitor without the template's identity: name, or query to also match a renamed monitor by its type and query (threshold excluded)")
+	templateCmd.Flags().BoolVar(&templateFixNotifyBy, "fix-notify-by", false, "Drop options.notify_by values the query doesn't group by, with a warning, instead of failing the template")
+	templateCmd.Flags().BoolVar(&templateUppercaseEnv, "uppercase-env-in-name", false, "Render {env} in names uppercased, as before {env:upper}")
+	templateCmd.Flags().MarkDeprecated("uppercase-env-in-name", "use {env:upper} in template names instead; the flag will be removed in the next release")
+	templateCmd.Flags().BoolVar(&templateSummaryOnly, "summary-only", false, "Print one line per template file (created/updated/unchanged/failed counts) and the totals instead of a line per monitor")
+	templateCmd.Flags().StringVarP(&templateOutput, "output", "o", "text", "Output format: text, or json for a per-file breakdown on stdout (implies --summary-only; human output then goes to stderr)")
+	templateCmd.Flags().StringArrayVar(&templatePresets, "preset", []string{}, "Apply the options of this preset under the templates' own options (can be used multiple times, later presets win; see presets list)")
+	templateCmd.Flags().StringVar(&templateThresholdScale, "threshold-scale", "", "Multiply thresholds per environment after rendering, e.g. dev=2.0,hml=1.5 (default: threshold_scale in the config file)")
 }
 
+// runTemplate runs the template command; with --output json, the per-file
+// breakdown is written whatever the outcome
 func runTemplate(cmd *cobra.Command, args []string) error {
-	client, err := datadog.NewClient()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+	if err := checkTemplateOutput(); err != nil {
 		return err
 	}
+	if templateOutput != "json" {
+		// No breakdown left over from an earlier run in the same process
+		templateSummary = nil
+		return runTemplateReceipt()
+	}
 
-	service := templateService
-	env := templateEnv
-	namespace := templateNamespace
+	templateSummary = &templateApplySummary{Files: []templateFileSummary{}}
+	stdout := os.Stdout
+	// The breakdown owns stdout; everything printed for humans goes to stderr
+	os.Stdout = os.Stderr
+	err := runTemplateReceipt()
+	os.Stdout = stdout
 
-	// Validate env
-	validEnvs := map[string]bool{"dev": true, "hml": true, "prd": true, "corp": true}
-	if !validEnvs[env] {
-		return fmt.Errorf("invalid environment: %s (must be dev, hml, prd, or corp)", env)
+	if err != nil {
+		templateSummary.Error = err.Error()
 	}
+	if writeErr := templateSummary.write(stdout); writeErr != nil {
+		errOut.Printf("❌ Error writing the summary: %v\n", writeErr)
+		if err == nil {
+			err = writeErr
+		}
+	}
+	return err
+}
 
-	fmt.Println("\n🚀 Applying monitor templates for:")
-	fmt.Printf("📦 Service: %s\n", service)
-	fmt.Printf("🌍 Environment: %s\n", env)
-	fmt.Printf("🏷️  Namespace: %s\n", namespace)
-	fmt.Println(strings.Repeat("=", 80))
+// runTemplateReceipt runs the templates; with --output-file, the apply
+// receipt is written whatever the outcome
+func runTemplateReceipt() error {
+	if templateOutputFile == "" {
+		return runTemplateApply()
+	}
+	if templateDryRun {
+		return fmt.Errorf("--output-file cannot be combined with --dry-run")
+	}
+
+	templateReceipt = datadog.NewApplyReceipt(time.Now())
+	stdout := os.Stdout
+	if templateOutputFile == "-" {
+		// The receipt owns stdout; everything printed for humans goes to stderr
+		os.Stdout = os.Stderr
+	}
+	err := runTemplateApply()
+	os.Stdout = stdout
+
+	if err != nil && templateReceipt.Success {
+		templateReceipt.AddError(datadog.RenderOptions{Service: templateService, Env: templateEnv, Namespace: templateNamespace}, "", err)
+	}
+	var writeErr error
+	if templateOutputFile == "-" {
+		writeErr = templateReceipt.Write(stdout)
+	} else if writeErr = templateReceipt.Save(templateOutputFile); writeErr == nil {
+		errOut.Printf("🧾 Apply results written to %s\n", templateOutputFile)
+	}
+	if writeErr != nil {
+		errOut.Printf("❌ Error writing apply results: %v\n", writeErr)
+		if err == nil {
+			err = writeErr
+		}
+	}
+	return err
+}
+
+// recordApplyResults adds results to the --output-file receipt, if any
+func recordApplyResults(client *datadog.Client, opts datadog.ApplyOptions, file string, results []datadog.ApplyResult) {
+	if templateReceipt != nil {
+		templateReceipt.AddResults(opts.RenderOptions, file, client.AppURL(), results)
+	}
+}
+
+// recordApplyError adds a failed template file (or apply, with an empty
+// file) to the --output-file receipt, if any
+func recordApplyError(opts datadog.ApplyOptions, file string, err error) {
+	if templateReceipt != nil {
+		templateReceipt.AddError(opts.RenderOptions, file, err)
+	}
+}
+
+func runTemplateApply() error {
+	// Without a terminal, --interactive is ignored so missing values stay errors
+	interactive := templateInteractive && stdinIsTerminal()
+	if templateInteractive && !interactive {
+		logVerbose("--interactive: stdin is not a terminal, not prompting")
+	}
+	if interactive {
+		if err := promptTemplateInputs(); err != nil {
+			return err
+		}
+		// Prompted values are checked like the flags
+		for _, input := range []struct {
+			name  string
+			value *string
+		}{{"--service", &templateService}, {"--env", &templateEnv}, {"--namespace", &templateNamespace}} {
+			value, err := checkIdentityValue(input.name, *input.value)
+			if err != nil {
+				return err
+			}
+			*input.value = value
+		}
+	}
 
-	upsert := !templateNoUpsert
+	if templateEnv == "" {
+		return fmt.Errorf(`required flag(s) "env" not set`)
+	}
+	if templateForEachTag == "" {
+		if templateService == "" || templateNamespace == "" {
+			return fmt.Errorf("--service and --namespace are required (or use --for-each-tag)")
+		}
+	} else if (templateService == "" && templateForEachTag != "service") || (templateNamespace == "" && templateForEachTag != "namespace") {
+		return fmt.Errorf("--service and --namespace are required unless bound by --for-each-tag")
+	}
+	if templateForEachTag == "env" {
+		return fmt.Errorf("--for-each-tag env is not supported; apply once per environment instead")
+	}
+	if (templateAllowDup || templateSuffix) && !templateNoUpsert {
+		return fmt.Errorf("--allow-duplicate and --suffix-on-conflict can only be used together with --no-upsert")
+	}
+	if templateAllowDup && templateSuffix {
+		return fmt.Errorf("--allow-duplicate and --suffix-on-conflict are mutually exclusive")
+	}
+	if templatePreviewData && !templateDryRun {
+		return fmt.Errorf("--preview-data can only be used together with --dry-run")
+	}
+	matchByQuery, err := parseMatchBy(templateMatchBy)
+	if err != nil {
+		return err
+	}
+	if matchByQuery && templateNoUpsert {
+		return fmt.Errorf("--match-by query cannot be used together with --no-upsert")
+	}
+	selection := datadog.TemplateSelection{Only: splitCommaList(templateOnly), Skip: splitCommaList(templateSkip)}
+	if err := selection.Validate(); err != nil {
+		return err
+	}
+	nameOverflow, err := datadog.ParseNameOverflowPolicy(templateNameOverflow)
+	if err != nil {
+		return err
+	}
+	k8s, err := k8sDefaults(templateK8sDefaults, templateK8sEvalDelay, templateK8sGroupDelay)
+	if err != nil {
+		return err
+	}
+	var mutedUntil time.Time
+	if templateCreateMuted != "" {
+		if mutedUntil, err = parseCreateMuted(templateCreateMuted, time.Now()); err != nil {
+			return err
+		}
+	}
 
 	if templateFile != "" {
-		// Apply template file
-		results, err := client.ApplyTemplate(templateFile, service, env, namespace, upsert, templateTags)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "❌ Error applying template: %v\n", err)
+		if templateFile, err = fetchTemplateSource(templateFile, templateRefreshRemote); err != nil {
 			return err
 		}
+	} else if templateDir, err = fetchTemplateDir(templateDir, templateRefreshRemote); err != nil {
+		return err
+	}
 
-		if len(results) > 0 {
-			createdCount := 0
-			updatedCount := 0
-			for _, result := range results {
-				if wasCreated, ok := result["was_created"].(bool); ok && wasCreated {
-					createdCount++
-				} else {
-					updatedCount++
+	vars, err := parseTemplateVars(templateVars)
+	if err != nil {
+		return err
+	}
+	if interactive {
+		if err := promptTemplateVars(vars); err != nil {
+			return err
+		}
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	// Validate env (after mapping configured aliases)
+	env, err := resolveEnv(templateEnv, templateAllowAnyEnv)
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	scale, err := thresholdScale(templateThresholdScale, env, 0)
+	if err != nil {
+		return err
+	}
+	presets, err := presetOptions(templatePresets)
+	if err != nil {
+		return err
+	}
+
+	applyOpts := datadog.ApplyOptions{
+		RenderOptions: datadog.RenderOptions{
+			Service:             templateService,
+			Env:                 env,
+			Namespace:           templateNamespace,
+			AdditionalTags:      templateTags,
+			Vars:                vars,
+			EnvAliases:          cfg.EnvAliases,
+			NameOverflow:        nameOverflow,
+			SingleValuedTagKeys: cfg.SingleValuedTagKeys,
+			UppercaseEnvInName:  templateUppercaseEnv,
+		},
+		Upsert:               !templateNoUpsert,
+		OnNameConflict:       nameConflictPolicy(),
+		SkipSchemaValidation: templateNoSchema,
+		StrictScope:          templateStrictScope,
+		ProtectUnmanaged:     templateProtect,
+		MatchByQuery:         matchByQuery,
+		Selection:            selection,
+		K8sDefaults:          k8s,
+		CreateMuted:          templateCreateMuted != "",
+		CreateMutedUntil:     mutedUntil,
+		ThresholdScale:       scale,
+		FixNotifyBy:          templateFixNotifyBy,
+		PresetOptions:        presets,
+	}
+
+	if templateForEachTag == "" {
+		if templateDryRun {
+			return renderTemplates(client, applyOpts)
+		}
+		return applyTemplates(client, applyOpts)
+	}
+
+	values, err := client.DistinctTagValues(templateForEachTag, splitCommaList(templateForEachFilter))
+	if err != nil {
+		errOut.Printf("❌ Error listing %s tag values: %v\n", templateForEachTag, err)
+		return err
+	}
+	values, err = excludeGlobs(values, templateExclude)
+	if err != nil {
+		return err
+	}
+
+	out.Printf("\n🔁 Found %d value(s) for tag %s", len(values), templateForEachTag)
+	if templateForEachFilter != "" {
+		out.Printf(" (filter: %s)", templateForEachFilter)
+	}
+	out.Println(":")
+	for _, value := range values {
+		out.Printf("   - %s:%s\n", templateForEachTag, value)
+	}
+
+	if len(values) > templateMaxIterations {
+		return fmt.Errorf("--for-each-tag %s expands to %d values, more than --max-iterations %d (narrow it with --for-each-filter or --exclude)",
+			templateForEachTag, len(values), templateMaxIterations)
+	}
+	if templateDryRun && !templatePreviewData {
+		out.Println("\nℹ️  Dry run: no templates were applied")
+		return nil
+	}
+
+	var failed []string
+	// conflict is the first --protect-unmanaged conflict; conflicted counts the values with one
+	var conflict error
+	conflicted := 0
+	for i, value := range values {
+		opts := applyOpts
+		switch templateForEachTag {
+		case "service":
+			opts.Service = value
+		case "namespace":
+			opts.Namespace = value
+		default:
+			opts.Vars = map[string]string{templateForEachTag: value}
+			for key, val := range vars {
+				if key != templateForEachTag {
+					opts.Vars[key] = val
+				}
+			}
+		}
+		apply := applyTemplates
+		if templateDryRun {
+			apply = renderTemplates
+		}
+		if err := apply(client, opts); err != nil {
+			if isInterrupted(err) {
+				printInterrupted(err, i, len(values), fmt.Sprintf("%s value(s)", templateForEachTag))
+				return err
+			}
+			if datadog.IsUnmanagedConflict(err) {
+				if conflict == nil {
+					conflict = err
 				}
+				conflicted++
 			}
+			failed = append(failed, value)
+		}
+	}
 
-			if createdCount > 0 && updatedCount > 0 {
-				fmt.Printf("✅ Applied %d monitors: %d created, %d updated\n", len(results), createdCount, updatedCount)
-			} else if createdCount > 0 {
-				fmt.Printf("✅ Created %d new monitors\n", createdCount)
-			} else {
-				fmt.Printf("✅ Updated %d existing monitors\n", updatedCount)
+	if len(failed) == 0 {
+		return nil
+	}
+	err = fmt.Errorf("failed to apply templates for %s: %s", templateForEachTag, strings.Join(failed, ", "))
+	if conflicted == len(failed) {
+		// Keep the conflict exit code when conflicts are the only failure
+		return fmt.Errorf("%v: %w", err, conflict)
+	}
+	return err
+}
+
+// excludeGlobs drops the values matching any of the glob patterns
+func excludeGlobs(values, patterns []string) ([]string, error) {
+	var kept []string
+	for _, value := range values {
+		excluded := false
+		for _, pattern := range patterns {
+			matched, err := path.Match(pattern, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --exclude pattern %q: %v", pattern, err)
+			}
+			if matched {
+				excluded = true
+				break
 			}
+		}
+		if !excluded {
+			kept = append(kept, value)
+		}
+	}
+	return kept, nil
+}
 
-			for _, result := range results {
-				templateName, _ := result["template_name"].(string)
-				monitorID, _ := result["id"].(int)
-				wasCreated, _ := result["was_created"].(bool)
-				action := "🆕 Created"
-				if !wasCreated {
-					action = "🔄 Updated"
+// printScopeWarnings prints the query scope mismatches of an applied template
+func printScopeWarnings(result datadog.ApplyResult, indent string) {
+	for _, mismatch := range result.ScopeWarnings {
+		out.Printf("%s⚠️  %s (use {%s} in the template, or --strict-scope to fail)\n", indent, mismatch, mismatch.Key)
+	}
+}
+
+// fetchTemplateSource returns the local path of a template file or directory
+// source, fetching https:// and git:: sources into the template cache first
+// (a fresh cached copy is reused unless refresh is set)
+func fetchTemplateSource(source string, refresh bool) (string, error) {
+	if !fetch.IsRemote(source) {
+		return source, nil
+	}
+	fetcher := fetch.NewFetcher()
+	fetcher.Refresh = refresh
+	local, err := fetcher.Fetch(commandContext(), source)
+	if err != nil {
+		errOut.Printf("❌ Error fetching templates: %v\n", err)
+		return "", err
+	}
+	logVerbose("templates from %s are in %s", source, local)
+	return local, nil
+}
+
+// fetchTemplateDir is fetchTemplateSource for --template-dir, which must be a directory
+func fetchTemplateDir(source string, refresh bool) (string, error) {
+	local, err := fetchTemplateSource(source, refresh)
+	if err != nil || local == source {
+		return local, err
+	}
+	if info, err := os.Stat(local); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("--template-dir %s is not a directory (use --file for a single template)", source)
+	}
+	return local, nil
+}
+
+// templateDirFiles returns the JSON template files in the template directory,
+// printing hints when there are none
+func templateDirFiles() ([]string, error) {
+	if _, err := os.Stat(templateDir); os.IsNotExist(err) {
+		errOut.Printf("❌ Template directory not found: %s\n", templateDir)
+		errOut.Printf("💡 Create the directory and add JSON template files:\n")
+		errOut.Printf("   mkdir %s\n", templateDir)
+		errOut.Printf("   # Export templates from Datadog UI and save as .json files\n")
+		return nil, err
+	}
+
+	// Find all JSON files in template directory
+	matches, err := filepath.Glob(filepath.Join(templateDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(matches) == 0 {
+		errOut.Printf("❌ No JSON template files found in: %s\n", templateDir)
+		errOut.Printf("💡 Add JSON template files exported from Datadog UI\n")
+		return nil, fmt.Errorf("no template files found")
+	}
+
+	return matches, nil
+}
+
+// renderTemplates renders the template file, or every template in the template
+// directory, without applying them. With --preview-data, metric monitor queries
+// are evaluated against current data and queries returning no series are flagged.
+func renderTemplates(client *datadog.Client, applyOpts datadog.ApplyOptions) error {
+	out.Println("\n🧪 Dry run: rendering monitor templates for:")
+	out.Printf("📦 Service: %s\n", applyOpts.Service)
+	out.Printf("🌍 Environment: %s\n", applyOpts.Env)
+	out.Printf("🏷️  Namespace: %s\n", applyOpts.Namespace)
+	printThresholdScale(applyOpts.ThresholdScale)
+	printPresets(templatePresets)
+	out.Println(strings.Repeat("=", 80))
+
+	files, err := templateFiles()
+	if err != nil {
+		return err
+	}
+
+	rendered := 0
+	skipped := 0
+	var noSeries []string
+	for _, file := range files {
+		monitors, skippedTemplates, err := datadog.RenderSelectedTemplates(file, applyOpts)
+		if err != nil {
+			errOut.Printf("❌ Error rendering %s: %v\n", filepath.Base(file), err)
+			return err
+		}
+
+		out.Printf("\n📄 %s\n", filepath.Base(file))
+		for _, t := range skippedTemplates {
+			skipped++
+			out.Printf("   ⏭️  Skipped %s (%s)\n", t.TemplateName, t.Reason)
+		}
+		for _, r := range monitors {
+			rendered++
+			out.Printf("   📝 %s\n", r.Monitor.Name)
+			if r.NameOverflow != "" {
+				logVerbose("%s: %s", r.TemplateName, r.NameOverflow)
+			}
+			out.Printf("      Query: %s\n", r.Monitor.Query)
+			if len(r.K8sDefaults) > 0 {
+				out.Printf("      ⏱️  Kubernetes defaults: %s\n", k8sDefaultsSummary(r.Monitor, r.K8sDefaults))
+			}
+			printScaledThresholds(r.ScaledThresholds, "      ")
+			printNotifyByDropped(r.NotifyByDropped, "      ")
+			printTagsReplaced(r.TagsReplaced, "      ")
+
+			if !templatePreviewData {
+				continue
+			}
+			if !datadog.IsMetricMonitor(r.Monitor.Type) {
+				out.Printf("      ⏭️  No data preview for %q monitors\n", r.Monitor.Type)
+				continue
+			}
+			preview, err := client.PreviewMonitorQuery(r.Monitor.Query, time.Now())
+			if err != nil {
+				if isInterrupted(err) {
+					return err
 				}
-				fmt.Printf("   %s %s: Monitor ID %d\n", action, templateName, monitorID)
+				out.Printf("      ⚠️  Could not preview: %v\n", err)
+				continue
+			}
+			printQueryPreview(preview, "      ")
+			if len(preview.Series) == 0 {
+				noSeries = append(noSeries, fmt.Sprintf("%s (%s)", r.TemplateName, filepath.Base(file)))
 			}
-		} else {
-			fmt.Printf("❌ Failed to apply template: %s\n", templateFile)
 		}
-	} else {
-		// Apply all templates from directory
-		if _, err := os.Stat(templateDir); os.IsNotExist(err) {
-			fmt.Fprintf(os.Stderr, "❌ Template directory not found: %s\n", templateDir)
-			fmt.Fprintf(os.Stderr, "💡 Create the directory and add JSON template files:\n")
-			fmt.Fprintf(os.Stderr, "   mkdir %s\n", templateDir)
-			fmt.Fprintf(os.Stderr, "   # Export templates from Datadog UI and save as .json files\n")
-			return err
+	}
+
+	out.Printf("\nℹ️  Dry run: %d monitor(s) rendered", rendered)
+	if skipped > 0 {
+		out.Printf(", %d template(s) skipped", skipped)
+	}
+	out.Println(", nothing was applied")
+	if len(noSeries) > 0 {
+		out.Printf("⚠️  %d template(s) with queries returning no series:\n", len(noSeries))
+		for _, name := range noSeries {
+			out.Printf("   - %s\n", name)
 		}
+	}
+	return nil
+}
+
+// applyTemplates applies the template file, or every template in the template
+// directory, for one service/env/namespace
+func applyTemplates(client *datadog.Client, applyOpts datadog.ApplyOptions) error {
+	out.Println("\n🚀 Applying monitor templates for:")
+	out.Printf("📦 Service: %s\n", applyOpts.Service)
+	out.Printf("🌍 Environment: %s\n", applyOpts.Env)
+	out.Printf("🏷️  Namespace: %s\n", applyOpts.Namespace)
+	printThresholdScale(applyOpts.ThresholdScale)
+	printPresets(templatePresets)
+	out.Println(strings.Repeat("=", 80))
 
-		// Find all JSON files in template directory
-		matches, err := filepath.Glob(filepath.Join(templateDir, "*.json"))
+	// With --state-file, skip the API entirely when nothing changed since the last apply
+	state, rendered := checkApplyState(applyOpts)
+	stateKey := datadog.ApplyTargetKey(applyOpts.RenderOptions)
+	if state != nil && !templateRefresh && state.UpToDate(stateKey, rendered) {
+		out.Printf("✅ Up to date: %d monitor(s) unchanged since %s (state file %s, no API calls made)\n",
+			len(rendered), formatTime(state.Targets[stateKey].AppliedAt), templateStateFile)
+		unchanged := make([]datadog.ApplyResult, len(rendered))
+		for i, r := range rendered {
+			id := state.Targets[stateKey].Monitors[r.Monitor.Name].ID
+			unchanged[i] = datadog.ApplyResult{TemplateName: r.TemplateName, ID: id, Name: r.Monitor.Name, Status: datadog.StatusUnchanged}
+		}
+		recordApplyResults(client, applyOpts, "", unchanged)
+		if templateSummary != nil {
+			templateSummary.Totals.Unchanged += len(unchanged)
+		}
+		return nil
+	}
+
+	// Set when Ctrl-C or --timeout stopped the run; what was applied is still reported
+	var interrupted error
+	var applied []datadog.ApplyResult
+
+	if templateAtomic {
+		results, err := applyTemplatesAtomic(client, applyOpts)
 		if err != nil {
+			recordApplyError(applyOpts, "", err)
 			return err
 		}
-
-		if len(matches) == 0 {
-			fmt.Fprintf(os.Stderr, "❌ No JSON template files found in: %s\n", templateDir)
-			fmt.Fprintf(os.Stderr, "💡 Add JSON template files exported from Datadog UI\n")
-			return fmt.Errorf("no template files found")
+		recordApplyResults(client, applyOpts, "", results)
+		applied = results
+	} else if templateFile != "" && !templateSummaryOnly {
+		// Apply template file
+		results, err := client.ApplyTemplateWithOptions(templateFile, applyOpts)
+		applied = results
+		recordApplyResults(client, applyOpts, templateFile, results)
+		if err != nil {
+			recordApplyError(applyOpts, templateFile, err)
+		}
+		if err != nil && !isInterrupted(err) {
+			errOut.Printf("❌ Error applying template: %v\n", err)
+			return err
+		}
+		if err != nil {
+			interrupted = err
+			printInterrupted(interrupted, len(results), 0, "template(s)")
+			if len(results) == 0 {
+				return interrupted
+			}
 		}
 
-		fmt.Printf("📁 Found %d template files in %s\n", len(matches), templateDir)
+		if len(results) > 0 {
+			counts := countApplyResults(results)
+			if counts.Created > 0 && counts.Updated > 0 {
+				out.Printf("✅ Applied %d monitors: %d created, %d updated\n", counts.Created+counts.Updated, counts.Created, counts.Updated)
+			} else if counts.Created > 0 {
+				out.Printf("✅ Created %d new monitors\n", counts.Created)
+			} else if counts.Updated > 0 {
+				out.Printf("✅ Updated %d existing monitors\n", counts.Updated)
+			}
+			if counts.Unchanged > 0 {
+				out.Printf("✅ %d monitor(s) already up to date\n", counts.Unchanged)
+			}
+			if counts.Skipped > 0 {
+				out.Printf("⏭️  Skipped %d template(s)\n", counts.Skipped)
+			}
 
-		totalCreated := 0
-		totalUpdated := 0
+			for _, result := range results {
+				printApplyResult(result, "   ")
+			}
+		} else {
+			out.Printf("❌ Failed to apply template: %s\n", templateFile)
+		}
+	} else {
+		// Apply all templates from directory (or, with --summary-only, the template file)
+		matches, err := templateFiles()
+		if err != nil {
+			return err
+		}
+		if templateFile == "" {
+			out.Printf("📁 Found %d template files in %s\n", len(matches), templateDir)
+		}
 
+		summary := templateSummary
+		if summary == nil {
+			summary = &templateApplySummary{}
+		}
+		filesDone := 0
 		for _, templateFile := range matches {
+			if interrupted = interruption(nil); interrupted != nil {
+				break
+			}
+
 			templateName := filepath.Base(templateFile)
-			fmt.Printf("\n📄 Applying template: %s\n", templateName)
+			if !templateSummaryOnly {
+				out.Printf("\n📄 Applying template: %s\n", templateName)
+			}
 
-			results, err := client.ApplyTemplate(templateFile, service, env, namespace, upsert, templateTags)
+			results, err := client.ApplyTemplateWithOptions(templateFile, applyOpts)
+			recordApplyResults(client, applyOpts, templateFile, results)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "   ❌ Failed to apply template: %v\n", err)
+				recordApplyError(applyOpts, templateFile, err)
+			}
+			fileSummary := templateFileSummary{File: templateName}
+			if interrupted = interruption(err); interrupted != nil {
+				applied = append(applied, results...)
+				// Still count the monitors of this file applied before the interrupt
+				fileSummary.applyCounts = countApplyResults(results)
+				summary.add(fileSummary)
+				if templateSummaryOnly {
+					printTemplateFileSummary(fileSummary)
+				} else {
+					for _, result := range results {
+						printApplyResult(result, "   ")
+					}
+				}
+				break
+			}
+			filesDone++
+			if err != nil {
+				fileSummary.Error = err.Error()
+				summary.add(fileSummary)
+				if templateSummaryOnly {
+					printTemplateFileSummary(fileSummary)
+				} else {
+					errOut.Printf("   ❌ Failed to apply template: %v\n", err)
+				}
 				continue
 			}
+			applied = append(applied, results...)
+			fileSummary.applyCounts = countApplyResults(results)
+			summary.add(fileSummary)
 
-			if len(results) > 0 {
+			if templateSummaryOnly {
+				printTemplateFileSummary(fileSummary)
+			} else if len(results) > 0 {
 				for _, result := range results {
-					monitorName, _ := result["template_name"].(string)
-					monitorID, _ := result["id"].(int)
-					wasCreated, _ := result["was_created"].(bool)
-					action := "🆕 Created"
-					if !wasCreated {
-						action = "🔄 Updated"
-					}
-					fmt.Printf("   %s %s: Monitor ID %d\n", action, monitorName, monitorID)
-
-					if wasCreated {
-						totalCreated++
-					} else {
-						totalUpdated++
-					}
+					printApplyResult(result, "   ")
 				}
 			} else {
-				fmt.Println("   ❌ Failed to apply template")
+				out.Println("   ❌ Failed to apply template")
+			}
+		}
+
+		printInterrupted(interrupted, filesDone, len(matches), "template file(s)")
+		totals := summary.Totals
+		out.Printf("\n✅ Successfully applied monitors:\n")
+		out.Printf("   🆕 Created: %d\n", totals.Created)
+		out.Printf("   🔄 Updated: %d\n", totals.Updated)
+		if totals.Unchanged > 0 {
+			out.Printf("   ✅ Up to date: %d\n", totals.Unchanged)
+		}
+		out.Printf("   📊 Total: %d\n", totals.Created+totals.Updated+totals.Unchanged)
+		if totals.Skipped > 0 {
+			out.Printf("   ⏭️  Skipped: %d\n", totals.Skipped)
+		}
+		if templateSummaryOnly {
+			out.Printf("   📄 Files: %d", len(summary.Files))
+			if failed := summary.failedFiles(); failed > 0 {
+				out.Printf(" (%d failed)", failed)
 			}
+			out.Println()
 		}
+	}
 
-		fmt.Printf("\n✅ Successfully applied monitors:\n")
-		fmt.Printf("   🆕 Created: %d\n", totalCreated)
-		fmt.Printf("   🔄 Updated: %d\n", totalUpdated)
-		fmt.Printf("   📊 Total: %d\n", totalCreated+totalUpdated)
+	printCreateMutedSummary(applied, applyOpts)
+	conflicts := printUnmanagedSummary(applied)
+	if err := printFailedTemplates(applied); conflicts == nil {
+		conflicts = err
 	}
 
-	return nil
+	if state != nil {
+		state.Record(stateKey, rendered, applied, time.Now())
+		if err := state.Save(templateStateFile); err != nil {
+			errOut.Printf("⚠️  Could not write state file: %v\n", err)
+		} else {
+			out.Printf("💾 State saved to %s\n", templateStateFile)
+		}
+	}
+
+	if interrupted != nil {
+		return interrupted
+	}
+	return conflicts
+}
+
+// applyTemplatesAtomic applies the templates all-or-nothing (--atomic): every
+// monitor is rendered and validated first, composites are written last, and a
+// failed write rolls back what this run changed
+func applyTemplatesAtomic(client *datadog.Client, applyOpts datadog.ApplyOptions) ([]datadog.ApplyResult, error) {
+	files, err := templateFiles()
+	if err != nil {
+		return nil, err
+	}
+	var rendered []datadog.RenderedMonitor
+	var skipped []datadog.ApplyResult
+	for _, file := range files {
+		monitors, skippedTemplates, err := datadog.RenderSelectedTemplates(file, applyOpts)
+		if err != nil {
+			errOut.Printf("❌ Error rendering template %s: %v\n", filepath.Base(file), err)
+			return nil, err
+		}
+		rendered = append(rendered, monitors...)
+		for _, t := range skippedTemplates {
+			skipped = append(skipped, datadog.ApplyResult{TemplateName: t.TemplateName, Status: datadog.StatusSkipped, SkipReason: t.Reason})
+		}
+	}
+
+	atomic := client.NewAtomicApply(applyOpts)
+	if err := runPreflight(atomic, rendered); err != nil {
+		return nil, err
+	}
+
+	var results []datadog.ApplyResult
+	for _, r := range datadog.OrderForApply(rendered) {
+		result, err := atomic.ApplyMonitor(r)
+		if err != nil {
+			return nil, rollbackAtomic(atomic, err)
+		}
+		printApplyResult(result, "   ")
+		results = append(results, result)
+	}
+	for _, result := range skipped {
+		printApplyResult(result, "   ")
+	}
+	out.Printf("\n✅ Applied %d monitor(s) from %d template file(s)", len(results), len(files))
+	if len(skipped) > 0 {
+		out.Printf(", %d template(s) skipped", len(skipped))
+	}
+	out.Println()
+	return append(results, skipped...), nil
+}
+
+// printApplyResult prints the outcome of applying one template
+func printApplyResult(result datadog.ApplyResult, indent string) {
+	switch result.Status {
+	case datadog.StatusCreated:
+		if result.Muted {
+			out.Printf("%s🆕 Created %s: Monitor ID %d (🔇 muted)\n", indent, result.TemplateName, result.ID)
+		} else {
+			out.Printf("%s🆕 Created %s: Monitor ID %d\n", indent, result.TemplateName, result.ID)
+		}
+	case datadog.StatusExisting:
+		out.Printf("%s♻️  Already created %s: Monitor ID %d (found by %s tag)\n", indent, result.TemplateName, result.ID, datadog.FingerprintTagKey)
+	case datadog.StatusConflict:
+		out.Printf("%s⛔ Conflict %s: Monitor ID %d is not managed by this tool (created by %s), left unchanged\n",
+			indent, result.TemplateName, result.ID, formatCreator(result.Creator))
+	case datadog.StatusAdopted:
+		if result.AdoptMonitorID != 0 {
+			out.Printf("%s🤝 Adopted %s: Monitor ID %d (adopt_monitor_id)\n", indent, result.TemplateName, result.ID)
+		} else {
+			out.Printf("%s🔄 Updated %s: Monitor ID %d (⚠️  had no %s tag)\n", indent, result.TemplateName, result.ID, datadog.ManagedByTag)
+		}
+	case datadog.StatusSkipped:
+		out.Printf("%s⏭️  Skipped %s (%s)\n", indent, result.TemplateName, result.SkipReason)
+	case datadog.StatusUnchanged:
+		out.Printf("%s✅ Up to date %s: Monitor ID %d (no update needed)\n", indent, result.TemplateName, result.ID)
+	case datadog.StatusFailed:
+		out.Printf("%s❌ Failed %s: %v\n", indent, result.TemplateName, result.Err)
+	default:
+		out.Printf("%s🔄 Updated %s: Monitor ID %d\n", indent, result.TemplateName, result.ID)
+	}
+	if result.RenamedFrom != "" {
+		out.Printf("%s   🔎 Matched by query, renamed from %q\n", indent, result.RenamedFrom)
+	}
+	if result.AdoptRedundant {
+		out.Printf("%s   ⚠️  adopt_monitor_id is now redundant: the monitor is already managed by this template, remove the key\n", indent)
+	}
+	if result.NameOverflow != "" {
+		logVerbose("%s: %s", result.TemplateName, result.NameOverflow)
+	}
+	if len(result.K8sDefaults) > 0 {
+		out.Printf("%s   ⏱️  Added %s (Kubernetes defaults)\n", indent, strings.Join(result.K8sDefaults, ", "))
+	}
+	printNotifyByDropped(result.NotifyByDropped, indent+"   ")
+	printTagsReplaced(result.TagsReplaced, indent+"   ")
+	printScopeWarnings(result, indent+"   ")
+}
+
+// printTagsReplaced notes the template tags --tag values of single-valued
+// keys replaced
+func printTagsReplaced(replaced []datadog.TagReplacement, indent string) {
+	for _, r := range replaced {
+		out.Printf("%s🏷️  Replaced tag %s with %s\n", indent, r.From, r.To)
+	}
+}
+
+// printNotifyByDropped warns about the notify_by values --fix-notify-by
+// dropped from a monitor
+func printNotifyByDropped(dropped []string, indent string) {
+	if len(dropped) > 0 {
+		out.Printf("%s⚠️  Dropped %s from notify_by: the query doesn't group by it\n", indent, strings.Join(dropped, ", "))
+	}
+}
+
+// printCreateMutedSummary notes when the monitors created muted
+// (--create-muted) will start notifying, and how to lift the mute early
+func printCreateMutedSummary(results []datadog.ApplyResult, opts datadog.ApplyOptions) {
+	muted := 0
+	for _, result := range results {
+		if result.Muted {
+			muted++
+		}
+	}
+	if muted == 0 {
+		return
+	}
+	out.Printf("\n🔇 %d new monitor(s) created muted %s\n", muted, muteEndLabel(opts.CreateMutedUntil))
+	out.Printf("💡 Unmute them once data flows with: datadog-monitor-manager wait --service %s --env %s --until-not-state \"No Data\" --unmute\n", opts.Service, opts.Env)
+}
+
+// printThresholdScale notes a --threshold-scale factor in a command header
+func printThresholdScale(factor float64) {
+	if factor > 0 && factor != 1 {
+		out.Printf("📏 Threshold scale: x%s\n", strconv.FormatFloat(factor, 'f', -1, 64))
+	}
+}
+
+// printScaledThresholds prints the thresholds --threshold-scale changed on a
+// rendered monitor, and those it had to leave alone
+func printScaledThresholds(scaled []datadog.ScaledThreshold, indent string) {
+	var changed []string
+	for _, s := range scaled {
+		if s.Skipped != "" {
+			out.Printf("%s⚠️  Threshold scale: %s\n", indent, s)
+			continue
+		}
+		changed = append(changed, s.String())
+	}
+	if len(changed) > 0 {
+		out.Printf("%s📏 Scaled: %s\n", indent, strings.Join(changed, ", "))
+	}
+}
+
+// k8sDefaultsSummary lists the injected Kubernetes default options with their
+// values, e.g. "evaluation_delay=300, new_group_delay=300"
+func k8sDefaultsSummary(monitor datadog.Monitor, injected []string) string {
+	parts := make([]string, len(injected))
+	for i, option := range injected {
+		parts[i] = fmt.Sprintf("%s=%v", option, monitor.Options[option])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// printUnmanagedSummary summarizes the unmanaged monitors met during an apply:
+// conflicts (left alone with --protect-unmanaged) and adopted monitors
+// (updated without it). It returns an error for exit code ExitConflict when
+// there were conflicts.
+func printUnmanagedSummary(results []datadog.ApplyResult) error {
+	var conflicts []datadog.ApplyResult
+	adopted := 0
+	for _, result := range results {
+		switch result.Status {
+		case datadog.StatusConflict:
+			conflicts = append(conflicts, result)
+		case datadog.StatusAdopted:
+			if result.AdoptMonitorID == 0 {
+				adopted++
+			}
+		}
+	}
+
+	if adopted > 0 {
+		out.Printf("\n⚠️  %d existing monitor(s) without the %s tag were overwritten and are now tagged.\n", adopted, datadog.ManagedByTag)
+		out.Println("   Use --protect-unmanaged to refuse such updates; it will become the default in a future release.")
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	out.Printf("\n⛔ Conflicts: %d monitor(s) share a name with a template but are not managed by this tool:\n", len(conflicts))
+	for _, result := range conflicts {
+		out.Printf("   ⚠️  ID %d: %s (created by %s)\n", result.ID, result.Name, formatCreator(result.Creator))
+	}
+	out.Printf("💡 Rename the template or the monitor, or add the %s tag to the monitor to let the tool manage it\n", datadog.ManagedByTag)
+	return fmt.Errorf("%d template(s) not applied: %w", len(conflicts), conflicts[0].Err)
+}
+
+// printFailedTemplates lists the templates that failed on their own: an
+// adopt_monitor_id that could not be adopted (the monitor is missing or its
+// name is taken) or an ambiguous --match-by query. It returns an error when
+// there were any.
+func printFailedTemplates(results []datadog.ApplyResult) error {
+	var failed []datadog.ApplyResult
+	for _, result := range results {
+		if result.Status == datadog.StatusFailed {
+			failed = append(failed, result)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	out.Printf("\n❌ Failed: %d template(s) were not applied:\n", len(failed))
+	for _, result := range failed {
+		out.Printf("   ⚠️  %s: %v\n", result.TemplateName, result.Err)
+	}
+	return fmt.Errorf("%d template(s) not applied: %w", len(failed), failed[0].Err)
+}
+
+// checkApplyState loads --state-file and renders the templates to compare
+// against it. It returns a nil state when no state file is used or the
+// templates cannot be rendered; an unreadable state file is replaced.
+func checkApplyState(applyOpts datadog.ApplyOptions) (*datadog.ApplyState, []datadog.RenderedMonitor) {
+	if templateStateFile == "" {
+		return nil, nil
+	}
+
+	files, err := templateFiles()
+	if err != nil {
+		return nil, nil
+	}
+	var rendered []datadog.RenderedMonitor
+	for _, file := range files {
+		monitors, err := datadog.RenderTemplateFile(file, applyOpts)
+		if err != nil {
+			errOut.Printf("⚠️  State check skipped, could not render %s: %v\n", filepath.Base(file), err)
+			return nil, nil
+		}
+		rendered = append(rendered, monitors...)
+	}
+
+	state, err := datadog.LoadApplyState(templateStateFile)
+	if err != nil {
+		errOut.Printf("⚠️  Ignoring state file: %v\n", err)
+		state = datadog.NewApplyState()
+	}
+	return state, rendered
+}
+
+// nameConflictPolicy returns what --no-upsert does with names that already exist
+func nameConflictPolicy() datadog.NameConflictPolicy {
+	switch {
+	case templateAllowDup:
+		return datadog.ConflictAllowDuplicate
+	case templateSuffix:
+		return datadog.ConflictSuffix
+	}
+	return datadog.ConflictFail
+}
+
+// parseMatchBy reports whether --match-by makes upserts fall back to matching
+// monitors by query
+func parseMatchBy(value string) (bool, error) {
+	switch value {
+	case "name":
+		return false, nil
+	case "query":
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid --match-by %q (must be name or query)", value)
+}
+
+// templateFiles returns --file, or the JSON template files in the template directory
+func templateFiles() ([]string, error) {
+	if templateFile != "" {
+		return []string{templateFile}, nil
+	}
+	return templateDirFiles()
 }
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var templateListBuiltinCmd = &cobra.Command{
+	Use:   "list-builtin",
+	Short: "List the built-in starter templates",
+	Long: `List the built-in Kubernetes starter templates that "init" writes, with the
+presets they belong to`,
+	RunE: runTemplateListBuiltin,
+}
+
+func init() {
+	templateCmd.AddCommand(templateListBuiltinCmd)
+}
+
+func runTemplateListBuiltin(cmd *cobra.Command, args []string) error {
+	width := 0
+	for _, template := range datadog.BuiltinTemplates {
+		if len(template.Name) > width {
+			width = len(template.Name)
+		}
+	}
+
+	out.Printf("📦 Built-in templates (%d):\n", len(datadog.BuiltinTemplates))
+	for _, template := range datadog.BuiltinTemplates {
+		preset := datadog.PresetFull
+		if template.Basic {
+			preset = datadog.PresetBasic + ", " + datadog.PresetFull
+		}
+		out.Printf("   %-*s  %s [%s]\n", width, template.Name, template.Description, preset)
+	}
+	out.Println("\n💡 Write them to templates/ with: datadog-monitor-manager init [--preset basic|full]")
+	return nil
+}
@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var templateDocsCmd = &cobra.Command{
+	Use:   "docs [template files...]",
+	Short: "Print the parameters each template expects",
+	Long: `Print a reference of the parameters each template expects: the {service},
+{env} and {namespace} placeholders it uses, its {key} variables (set with
+--var), where each one is used, and an example invocation. Datadog's
+{{variables}} and group-by clauses such as "by {service}" are not parameters.
+
+Templates document themselves with optional "description" and "params" keys:
+
+  {
+    "name": "CPU usage",
+    "description": "Alerts when the pods of a service use too much CPU",
+    "params": {
+      "threshold": {"description": "CPU percent", "default": "90"},
+      "team": {"description": "Owning team", "example": "payments"}
+    },
+    "config": {...}
+  }
+
+A param with a default is optional; a variable without a --var or a default
+fails the apply. --format markdown prints a README section to commit next to
+the templates.
+
+Without arguments every template in --template-dir is documented.
+
+Examples:
+  template docs
+  template docs templates/cpu.json
+  template docs --format markdown > templates/README.md`,
+	RunE: runTemplateDocs,
+}
+
+var (
+	templateDocsDir     string
+	templateDocsRefresh bool
+	templateDocsFormat  string
+)
+
+func init() {
+	templateCmd.AddCommand(templateDocsCmd)
+	templateDocsCmd.Flags().StringVar(&templateDocsDir, "template-dir", "templates", "Directory containing JSON templates, or a git:: source (default: templates/)")
+	templateDocsCmd.Flags().BoolVar(&templateDocsRefresh, "refresh-templates", false, "Fetch a remote --template-dir again instead of using the cached copy")
+	templateDocsCmd.Flags().StringVar(&templateDocsFormat, "format", "text", "Output format: text or markdown")
+}
+
+// templateDocsFile is the documentation of the templates of one file
+type templateDocsFile struct {
+	path string
+	docs []datadog.TemplateDoc
+}
+
+func runTemplateDocs(cmd *cobra.Command, args []string) error {
+	if templateDocsFormat != "text" && templateDocsFormat != "markdown" {
+		return fmt.Errorf("invalid --format %q (must be text or markdown)", templateDocsFormat)
+	}
+
+	files := args
+	if len(files) == 0 {
+		dir, err := fetchTemplateDir(templateDocsDir, templateDocsRefresh)
+		if err != nil {
+			return err
+		}
+		matches, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil {
+			return err
+		}
+		if len(matches) == 0 {
+			return fmt.Errorf("no JSON templates found in %s", dir)
+		}
+		files = matches
+	}
+
+	var documented []templateDocsFile
+	for _, file := range files {
+		templates, err := datadog.LoadTemplates(file, false)
+		if err != nil {
+			errOut.Printf("❌ Error: %v\n", err)
+			return err
+		}
+		entry := templateDocsFile{path: file}
+		for _, template := range templates {
+			entry.docs = append(entry.docs, datadog.DocumentTemplate(template))
+		}
+		documented = append(documented, entry)
+	}
+
+	if templateDocsFormat == "markdown" {
+		printTemplateDocsMarkdown(documented)
+		return nil
+	}
+	printTemplateDocs(documented)
+	return nil
+}
+
+// printTemplateDocs prints the parameter reference of templates
+func printTemplateDocs(files []templateDocsFile) {
+	for _, file := range files {
+		out.Printf("\n📄 %s\n", file.path)
+		for _, doc := range file.docs {
+			title := doc.Name
+			if doc.Disabled {
+				title += " (disabled)"
+			}
+			out.Printf("\n   📝 %s\n", title)
+			if doc.Description != "" {
+				out.Printf("      %s\n", doc.Description)
+			}
+
+			if len(doc.Params) == 0 {
+				out.Println("      No parameters")
+			} else {
+				width := 0
+				for _, param := range doc.Params {
+					if len(param.Name)+2 > width {
+						width = len(param.Name) + 2
+					}
+				}
+				out.Println("      Parameters:")
+				for _, param := range doc.Params {
+					out.Printf("        %-*s  %s\n", width, "{"+param.Name+"}", templateParamSummary(param))
+				}
+			}
+			out.Printf("      Example:\n        %s\n", templateDocsExample(file, doc))
+		}
+	}
+}
+
+// printTemplateDocsMarkdown prints the parameter reference of templates as a
+// Markdown section
+func printTemplateDocsMarkdown(files []templateDocsFile) {
+	fmt.Println("## Monitor Templates")
+	for _, file := range files {
+		for _, doc := range file.docs {
+			title := doc.Name
+			if doc.Disabled {
+				title += " (disabled)"
+			}
+			fmt.Printf("\n### %s\n\n", title)
+			if doc.Description != "" {
+				fmt.Printf("%s\n\n", doc.Description)
+			}
+			fmt.Printf("Template file: `%s`\n\n", filepath.ToSlash(file.path))
+
+			if len(doc.Params) > 0 {
+				fmt.Println("| Parameter | Required | Default | Description | Used in |")
+				fmt.Println("|-----------|----------|---------|-------------|---------|")
+				for _, param := range doc.Params {
+					required := "no"
+					if param.Required {
+						required = "yes"
+					}
+					defaultValue := ""
+					if param.Default != "" {
+						defaultValue = "`" + markdownCellEscape(param.Default) + "`"
+					}
+					fmt.Printf("| `{%s}` | %s | %s | %s | %s |\n", param.Name, required, defaultValue,
+						markdownCellEscape(templateParamDescription(param)), strings.Join(templateParamFields(param), ", "))
+				}
+				fmt.Println()
+			}
+			fmt.Printf("```bash\n%s\n```\n", templateDocsExample(file, doc))
+		}
+	}
+}
+
+// templateParamSummary returns the one-line description of a param in text output
+func templateParamSummary(param datadog.ParamDoc) string {
+	var parts []string
+	switch {
+	case param.Required:
+		parts = append(parts, "required")
+	case param.Default != "":
+		parts = append(parts, fmt.Sprintf("default %q", param.Default))
+	}
+	if description := templateParamDescription(param); description != "" {
+		parts = append(parts, description)
+	}
+	parts = append(parts, "("+strings.Join(templateParamFields(param), ", ")+")")
+	return strings.Join(parts, "  ")
+}
+
+// templateParamDescription returns the description of a param; built-in
+// params name the flag that sets them
+func templateParamDescription(param datadog.ParamDoc) string {
+	if !param.Builtin {
+		return param.Description
+	}
+	description := "set by --" + param.Name
+	if param.Description != "" {
+		description = param.Description + ", " + description
+	}
+	return description
+}
+
+// templateParamFields returns the fields a param is used in, or "unused" for
+// a param documented but not used
+func templateParamFields(param datadog.ParamDoc) []string {
+	if len(param.Fields) == 0 {
+		return []string{"unused"}
+	}
+	return param.Fields
+}
+
+// templateDocsExample returns a template command applying one template with
+// its required params
+func templateDocsExample(file templateDocsFile, doc datadog.TemplateDoc) string {
+	example := fmt.Sprintf("datadog-monitor-manager template --file %s --service <service> --env <env> --namespace <namespace>", filepath.ToSlash(file.path))
+	for _, param := range doc.Params {
+		if param.Builtin || !param.Required {
+			continue
+		}
+		value := "<" + param.Name + ">"
+		if param.Example != "" {
+			value = shellQuote(param.Example)
+		}
+		example += fmt.Sprintf(" --var %s=%s", param.Name, value)
+	}
+	if len(file.docs) > 1 {
+		example += " --only " + shellQuote(doc.Name)
+	}
+	return example
+}
+
+// shellQuote single-quotes a value for a shell command line when it has
+// characters the shell would interpret
+func shellQuote(value string) string {
+	if value != "" && strings.Trim(value, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-.,:/@=+") == "" {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+// templateDefaults are the values offered when prompting for template inputs,
+// detected from the environment: the Datadog unified service tagging variables
+// (DD_SERVICE, DD_ENV), the pod namespace and the current directory.
+type templateDefaults struct {
+	Service   string
+	Env       string
+	Namespace string
+}
+
+// detectTemplateDefaults returns the prompt defaults that can be detected
+func detectTemplateDefaults() templateDefaults {
+	defaults := templateDefaults{
+		Service:   os.Getenv("DD_SERVICE"),
+		Env:       os.Getenv("DD_ENV"),
+		Namespace: os.Getenv("POD_NAMESPACE"),
+	}
+	if defaults.Service == "" {
+		if dir, err := os.Getwd(); err == nil && filepath.Base(dir) != string(filepath.Separator) {
+			defaults.Service = filepath.Base(dir)
+		}
+	}
+	if defaults.Namespace == "" {
+		// Services are usually deployed to a namespace of the same name
+		defaults.Namespace = defaults.Service
+	}
+	return defaults
+}
+
+// promptTemplateInputs asks for the --service, --env and --namespace values
+// that were not given and aren't bound by --for-each-tag
+func promptTemplateInputs() error {
+	defaults := detectTemplateDefaults()
+	var err error
+
+	if templateService == "" && templateForEachTag != "service" {
+		if templateService, err = promptValue("📦 Service", defaults.Service, requireValue("service")); err != nil {
+			return err
+		}
+		if os.Getenv("POD_NAMESPACE") == "" {
+			defaults.Namespace = templateService
+		}
+	}
+	if templateEnv == "" {
+		validate, err := promptEnvValidator()
+		if err != nil {
+			return err
+		}
+		if templateEnv, err = promptValue("🌍 Environment", defaults.Env, validate); err != nil {
+			return err
+		}
+	}
+	if templateNamespace == "" && templateForEachTag != "namespace" {
+		if templateNamespace, err = promptValue("🏷️  Namespace", defaults.Namespace, requireValue("namespace")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// promptEnvValidator accepts the configured environments (or their aliases),
+// or any non-empty environment when none are configured or with --allow-any-env
+func promptEnvValidator() (func(string) error, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return func(env string) error {
+		if env == "" {
+			return fmt.Errorf("environment is required")
+		}
+		if templateAllowAnyEnv || len(cfg.Environments) == 0 {
+			return nil
+		}
+		resolved := datadog.ResolveEnvAlias(env, cfg.EnvAliases)
+		for _, valid := range cfg.Environments {
+			if resolved == valid {
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid environment: %s (must be one of: %s)", env, strings.Join(cfg.Environments, ", "))
+	}, nil
+}
+
+// promptTemplateVars asks for the template variables used by the templates
+// that have no value in vars yet (and aren't bound by --for-each-tag),
+// offering the default of their params
+func promptTemplateVars(vars map[string]string) error {
+	files, err := templateFiles()
+	if err != nil {
+		return err
+	}
+
+	var templates []datadog.TemplateData
+	for _, file := range files {
+		loaded, err := datadog.LoadTemplates(file, false)
+		if err != nil {
+			return err
+		}
+		templates = append(templates, loaded...)
+	}
+
+	params := datadog.TemplateParams(templates)
+	for _, key := range datadog.TemplateVariables(templates) {
+		if _, ok := vars[key]; ok || key == templateForEachTag {
+			continue
+		}
+		label := fmt.Sprintf("🔤 Template variable {%s}", key)
+		if description := params[key].Description; description != "" {
+			label += " (" + description + ")"
+		}
+		value, err := promptValue(label, params[key].Default, requireValue(key))
+		if err != nil {
+			return err
+		}
+		vars[key] = value
+	}
+	return nil
+}
+
+// requireValue returns a prompt validator rejecting empty values
+func requireValue(name string) func(string) error {
+	return func(value string) error {
+		if value == "" {
+			return fmt.Errorf("%s is required", name)
+		}
+		return nil
+	}
+}
+
+// parseTemplateVars parses --var key=value flags
+func parseTemplateVars(values []string) (map[string]string, error) {
+	vars := make(map[string]string, len(values))
+	for _, value := range values {
+		key, val, ok := strings.Cut(value, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --var %q (expected key=value)", value)
+		}
+		vars[key] = val
+	}
+	return vars, nil
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+// applyCounts counts the outcomes of applied templates
+type applyCounts struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Skipped   int `json:"skipped"`
+	// Failed counts the templates that failed on their own or conflicted
+	// with an unmanaged monitor
+	Failed int `json:"failed"`
+}
+
+// countApplyResults counts the outcomes of apply results; monitors found by
+// their fingerprint tag count as created
+func countApplyResults(results []datadog.ApplyResult) applyCounts {
+	var counts applyCounts
+	for _, result := range results {
+		switch result.Status {
+		case datadog.StatusCreated, datadog.StatusExisting:
+			counts.Created++
+		case datadog.StatusUnchanged:
+			counts.Unchanged++
+		case datadog.StatusSkipped:
+			counts.Skipped++
+		case datadog.StatusConflict, datadog.StatusFailed:
+			counts.Failed++
+		default:
+			counts.Updated++
+		}
+	}
+	return counts
+}
+
+// add adds other to the counts
+func (c *applyCounts) add(other applyCounts) {
+	c.Created += other.Created
+	c.Updated += other.Updated
+	c.Unchanged += other.Unchanged
+	c.Skipped += other.Skipped
+	c.Failed += other.Failed
+}
+
+// templateFileSummary is the outcome of applying one template file
+type templateFileSummary struct {
+	File string `json:"file"`
+	applyCounts
+	// Error is why the file could not be applied at all
+	Error string `json:"error,omitempty"`
+}
+
+// templateApplySummary is the per-file breakdown of an apply, printed as
+// JSON by --output json
+type templateApplySummary struct {
+	Files  []templateFileSummary `json:"files"`
+	Totals applyCounts           `json:"totals"`
+	// Error is the error the command failed with
+	Error string `json:"error,omitempty"`
+}
+
+// add records the outcome of a template file
+func (s *templateApplySummary) add(file templateFileSummary) {
+	s.Files = append(s.Files, file)
+	s.Totals.add(file.applyCounts)
+}
+
+// failedFiles counts the files that could not be applied at all
+func (s *templateApplySummary) failedFiles() int {
+	failed := 0
+	for _, file := range s.Files {
+		if file.Error != "" {
+			failed++
+		}
+	}
+	return failed
+}
+
+// write prints the summary as indented JSON
+func (s *templateApplySummary) write(w io.Writer) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// printTemplateFileSummary prints the one-line outcome of a template file for
+// --summary-only; a file that could not be applied is printed with its error
+func printTemplateFileSummary(file templateFileSummary) {
+	if file.Error != "" {
+		errOut.Printf("❌ %s: failed: %s\n", file.File, file.Error)
+		return
+	}
+	parts := []string{
+		fmt.Sprintf("%d created", file.Created),
+		fmt.Sprintf("%d updated", file.Updated),
+		fmt.Sprintf("%d unchanged", file.Unchanged),
+		fmt.Sprintf("%d failed", file.Failed),
+	}
+	if file.Skipped > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped", file.Skipped))
+	}
+	status := "✅"
+	if file.Failed > 0 {
+		status = "❌"
+	}
+	out.Printf("%s %s: %s\n", status, file.File, strings.Join(parts, ", "))
+}
+
+// checkTemplateOutput validates --summary-only and --output; --output json
+// implies --summary-only
+func checkTemplateOutput() error {
+	switch templateOutput {
+	case "text":
+	case "json":
+		templateSummaryOnly = true
+		if templateForEachTag != "" {
+			return fmt.Errorf("--output json cannot be combined with --for-each-tag")
+		}
+		if templateOutputFile == "-" {
+			return fmt.Errorf("--output json cannot be combined with --output-file - (both write to stdout)")
+		}
+	default:
+		return fmt.Errorf("invalid --output %q (must be text or json)", templateOutput)
+	}
+	if templateSummaryOnly && (templateDryRun || templateAtomic) {
+		return fmt.Errorf("--summary-only and --output json cannot be combined with --dry-run or --atomic")
+	}
+	return nil
+}
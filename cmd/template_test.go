@@ -0,0 +1,291 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadogtest"
+)
+
+func TestTemplateNoUpsertNameConflict(t *testing.T) {
+	dir := t.TempDir()
+	template := `{"name": "{service} CPU", "type": "metric alert", "query": "avg(last_5m):avg:cpu{service:{service}} > 90"}`
+	if err := os.WriteFile(filepath.Join(dir, "cpu.json"), []byte(template), 0644); err != nil {
+		t.Fatal(err)
+	}
+	args := []string{"template", "--template-dir", dir, "--service", "checkout", "--env", "prd", "--namespace", "shop", "--no-upsert"}
+
+	for _, tc := range []struct {
+		name string
+		flag string
+		// want are the monitor names after the run, "" when it fails
+		want string
+	}{
+		{"refused", "", ""},
+		{"duplicate allowed", "--allow-duplicate", "checkout CPU,checkout CPU"},
+		{"suffixed", "--suffix-on-conflict", "checkout CPU,checkout CPU (2)"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := newTestServer(t)
+			srv.AddMonitor(datadog.Monitor{Name: "checkout CPU", Type: "metric alert", Query: "avg(last_5m):avg:cpu{service:checkout} > 90"})
+
+			res := runCLI(t, nil, append(args, tc.flag)...)
+			if tc.want == "" {
+				// A template failing doesn't fail a directory run: the conflict is reported
+				if !strings.Contains(res.Stderr, `"checkout CPU" already exists (ID 1000), use upsert or --allow-duplicate`) {
+					t.Errorf("name conflict not reported:\n%s", res.Stderr)
+				}
+				srv.AssertNoMutations(t)
+				return
+			}
+			if res.Err != nil {
+				t.Fatalf("template: %v\n%s", res.Err, res.Stderr)
+			}
+			var names []string
+			for _, monitor := range srv.Monitors() {
+				names = append(names, monitor.Name)
+			}
+			if got := strings.Join(names, ","); got != tc.want {
+				t.Errorf("monitors %q, want %q", got, tc.want)
+			}
+			// The existing monitor is never updated
+			srv.AssertRequestCount(t, 0, "PUT", "/monitor/1000")
+		})
+	}
+
+	for _, flag := range []string{"--allow-duplicate", "--suffix-on-conflict"} {
+		res := runCLI(t, nil, "template", "--template-dir", dir, "--service", "checkout", "--env", "prd", "--namespace", "shop", flag)
+		if res.Err == nil || !strings.Contains(res.Err.Error(), "can only be used together with --no-upsert") {
+			t.Errorf("%s without --no-upsert: %v", flag, res.Err)
+		}
+	}
+}
+
+func TestTemplateK8sDefaults(t *testing.T) {
+	dir := t.TempDir()
+	for name, template := range map[string]string{
+		"pods.json":    `{"name": "{service} pod CPU", "type": "metric alert", "query": "avg(last_5m):avg:kubernetes.cpu.usage.total{service:{service}} > 90"}`,
+		"delayed.json": `{"name": "{service} restarts", "type": "metric alert", "query": "max(last_5m):max:kubernetes_state.container.restarts{service:{service}} > 3", "options": {"evaluation_delay": 30}}`,
+		"hosts.json":   `{"name": "{service} host CPU", "type": "metric alert", "query": "avg(last_5m):avg:system.cpu.user{service:{service}} > 90"}`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(template), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	args := []string{"template", "--template-dir", dir, "--service", "checkout", "--env", "prd", "--namespace", "shop"}
+	delays := func(srv *datadogtest.Server, name string) string {
+		for _, monitor := range srv.Monitors() {
+			if monitor.Name == name {
+				return fmt.Sprint(monitor.Options["evaluation_delay"], " ", monitor.Options["new_group_delay"])
+			}
+		}
+		t.Fatalf("no monitor %q", name)
+		return ""
+	}
+
+	for _, tc := range []struct {
+		name   string
+		config string
+		flags  []string
+		// pods and delayed are the delays of the two Kubernetes monitors
+		pods, delayed string
+	}{
+		{"off", "", nil, "<nil> <nil>", "30 <nil>"},
+		{"built-in delays", "", []string{"--k8s-defaults"}, "300 300", "30 300"},
+		{"config file", "k8s_defaults:\n  evaluation_delay: 120\n", []string{"--k8s-defaults"}, "120 300", "30 300"},
+		{"flags over the config file", "k8s_defaults:\n  evaluation_delay: 120\n  new_group_delay: 90\n", []string{"--k8s-defaults", "--k8s-new-group-delay", "60"}, "120 60", "30 60"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := newTestServer(t)
+			if tc.config != "" {
+				writeConfig(t, tc.config)
+			}
+			res := runCLI(t, nil, append(args, tc.flags...)...)
+			if res.Err != nil {
+				t.Fatalf("template: %v\n%s", res.Err, res.Stderr)
+			}
+			if got := delays(srv, "checkout pod CPU"); got != tc.pods {
+				t.Errorf("pod monitor delays %s, want %s", got, tc.pods)
+			}
+			if got := delays(srv, "checkout restarts"); got != tc.delayed {
+				t.Errorf("restarts monitor delays %s, want %s", got, tc.delayed)
+			}
+			if got := delays(srv, "checkout host CPU"); got != "<nil> <nil>" {
+				t.Errorf("host monitor delays %s, want none", got)
+			}
+			if added := strings.Count(res.Stdout, "(Kubernetes defaults)"); (len(tc.flags) > 0) != (added == 2) {
+				t.Errorf("%d monitor(s) reported with Kubernetes defaults:\n%s", added, res.Stdout)
+			}
+		})
+	}
+
+	res := runCLI(t, nil, append(args, "--k8s-evaluation-delay", "60")...)
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "can only be used together with --k8s-defaults") {
+		t.Errorf("--k8s-evaluation-delay without --k8s-defaults: %v", res.Err)
+	}
+}
+
+func TestTemplateSummaryOutput(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"cpu.json": `{"name": "{service} CPU", "type": "metric alert", "query": "avg(last_5m):avg:cpu{service:{service}} > 90"}`,
+		"memory.json": `{"templates": [
+			{"name": "Memory", "config": {"name": "{service} memory", "type": "metric alert", "query": "avg(last_5m):avg:mem{service:{service}} > 90"}},
+			{"name": "Swap", "config": {"name": "{service} swap", "type": "metric alert", "query": "avg(last_5m):avg:swap{service:{service}} > 90"}}
+		]}`,
+		"broken.json": `{"name": "{service} broken",`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	args := []string{"template", "--template-dir", dir, "--service", "checkout", "--env", "prd", "--namespace", "shop"}
+
+	t.Run("summary-only", func(t *testing.T) {
+		srv := newTestServer(t)
+		res := runCLI(t, nil, append(args, "--summary-only")...)
+		if res.Err != nil {
+			t.Fatalf("template: %v\n%s", res.Err, res.Stderr)
+		}
+		for _, want := range []string{
+			"[OK] cpu.json: 1 created, 0 updated, 0 unchanged, 0 failed\n",
+			"[OK] memory.json: 2 created, 0 updated, 0 unchanged, 0 failed\n",
+			"Created: 3\n",
+			"Files: 3 (1 failed)\n",
+		} {
+			if !strings.Contains(res.Stdout, want) {
+				t.Errorf("no %q in:\n%s", want, res.Stdout)
+			}
+		}
+		// No line per monitor, but the failure with its error
+		if strings.Contains(res.Stdout, "Applying template") || strings.Contains(res.Stdout, "Monitor ID") {
+			t.Errorf("per-monitor lines with --summary-only:\n%s", res.Stdout)
+		}
+		if !strings.Contains(res.Stderr, "[ERROR] broken.json: failed: ") {
+			t.Errorf("failed file not reported:\n%s", res.Stderr)
+		}
+
+		srv.ResetRequests()
+		res = runCLI(t, nil, append(args, "--summary-only")...)
+		if res.Err != nil {
+			t.Fatalf("template: %v\n%s", res.Err, res.Stderr)
+		}
+		srv.AssertNoMutations(t)
+		if !strings.Contains(res.Stdout, "[OK] memory.json: 0 created, 0 updated, 2 unchanged, 0 failed\n") {
+			t.Errorf("second run:\n%s", res.Stdout)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		newTestServer(t)
+		res := runCLI(t, nil, append(args, "--output", "json")...)
+		if res.Err != nil {
+			t.Fatalf("template: %v\n%s", res.Err, res.Stderr)
+		}
+		// stdout holds only the breakdown
+		var summary templateApplySummary
+		decoder := json.NewDecoder(strings.NewReader(res.Stdout))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&summary); err != nil {
+			t.Fatalf("stdout is not a summary: %v\n%s", err, res.Stdout)
+		}
+		if decoder.More() {
+			t.Errorf("more than the summary on stdout:\n%s", res.Stdout)
+		}
+		var files []string
+		for _, file := range summary.Files {
+			files = append(files, fmt.Sprintf("%s %d/%d/%d/%d %t", file.File, file.Created, file.Updated, file.Unchanged, file.Failed, file.Error != ""))
+		}
+		if want := "broken.json 0/0/0/0 true, cpu.json 1/0/0/0 false, memory.json 2/0/0/0 false"; strings.Join(files, ", ") != want {
+			t.Errorf("files %q, want %q", strings.Join(files, ", "), want)
+		}
+		if summary.Totals != (applyCounts{Created: 3}) || summary.Error != "" {
+			t.Errorf("totals %+v, error %q", summary.Totals, summary.Error)
+		}
+		// Human output goes to stderr
+		if !strings.Contains(res.Stderr, "[OK] cpu.json: 1 created") || !strings.Contains(res.Stderr, "broken.json: failed") {
+			t.Errorf("human output not on stderr:\n%s", res.Stderr)
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		newTestServer(t)
+		res := runCLI(t, nil, args...)
+		if res.Err != nil {
+			t.Fatalf("template: %v\n%s", res.Err, res.Stderr)
+		}
+		for _, want := range []string{"Applying template: cpu.json", "Created Single Template: Monitor ID 1000", "Created: 3\n"} {
+			if !strings.Contains(res.Stdout, want) {
+				t.Errorf("no %q in:\n%s", want, res.Stdout)
+			}
+		}
+		if strings.Contains(res.Stdout, "Files:") || strings.Contains(res.Stdout, "cpu.json: 1 created") {
+			t.Errorf("summary lines without --summary-only:\n%s", res.Stdout)
+		}
+	})
+
+	for _, extra := range [][]string{
+		{"--output", "yaml"},
+		{"--summary-only", "--dry-run"},
+		{"--output", "json", "--atomic"},
+	} {
+		if res := runCLI(t, nil, append(args, extra...)...); res.Err == nil {
+			t.Errorf("template %s succeeded", strings.Join(extra, " "))
+		}
+	}
+}
+
+func TestTemplateThresholdScaleDryRun(t *testing.T) {
+	dir := t.TempDir()
+	for name, template := range map[string]string{
+		"cpu.json": `{"name": "{service} CPU", "type": "metric alert", "query": "avg(last_5m):avg:system.cpu.user{service:{service}} > 80",
+  "options": {"thresholds": {"critical": 80, "warning": 60}}}`,
+		"queue.json": `{"name": "{service} queue", "type": "metric alert", "query": "avg(last_5m):avg:queue.depth{service:{service}} > 2.2",
+  "options": {"thresholds": {"critical": 2.2, "critical_recovery": 2}}}`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(template), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	args := []string{"template", "--template-dir", dir, "--service", "checkout", "--namespace", "shop", "--threshold-scale", "dev=1.5,hml=1.3", "--dry-run"}
+
+	srv := newTestServer(t)
+	res := runCLI(t, nil, append(args, "--env", "hml")...)
+	if res.Err != nil {
+		t.Fatalf("template --dry-run: %v\n%s", res.Err, res.Stderr)
+	}
+	srv.AssertNoMutations(t)
+	for _, line := range []string{
+		"Threshold scale: x1.3\n",
+		"Query: avg(last_5m):avg:system.cpu.user{service:checkout} > 104\n",
+		"Scaled: critical 80 -> 104, warning 60 -> 78, query 80 -> 104\n",
+		"Query: avg(last_5m):avg:queue.depth{service:checkout} > 2.86\n",
+		"Threshold scale: critical_recovery left at 2 (scaled value 3 would cross critical 2.86)\n",
+		"Scaled: critical 2.2 -> 2.86, query 2.2 -> 2.86\n",
+	} {
+		if !strings.Contains(res.Stdout, line) {
+			t.Errorf("template --dry-run output has no %q:\n%s", line, res.Stdout)
+		}
+	}
+
+	// Environments without a factor are left alone
+	res = runCLI(t, nil, append(args, "--env", "prd")...)
+	if res.Err != nil {
+		t.Fatalf("template --dry-run: %v\n%s", res.Err, res.Stderr)
+	}
+	if strings.Contains(res.Stdout, "Threshold scale") || strings.Contains(res.Stdout, "Scaled:") || !strings.Contains(res.Stdout, "{service:checkout} > 80\n") {
+		t.Errorf("template --dry-run in prd scaled thresholds:\n%s", res.Stdout)
+	}
+
+	for _, value := range []string{"dev=0", "dev=-1", "dev=x"} {
+		res := runCLI(t, nil, "template", "--template-dir", dir, "--service", "checkout", "--env", "dev", "--namespace", "shop", "--threshold-scale", value, "--dry-run")
+		if res.Err == nil || !strings.Contains(res.Err.Error(), "must be a positive number") {
+			t.Errorf("--threshold-scale %s = %v", value, res.Err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var templateTestCmd = &cobra.Command{
+	Use:   "test [template files...]",
+	Short: "Run rendering tests for templates",
+	Long: `Render templates with the inputs listed in their test files and check the
+results. The test file for templates/cpu.json is templates/cpu_test.yaml:
+
+  cases:
+    - name: production rendering
+      template: CPU usage            # only needed for multi-template files
+      inputs: {service: myapp, env: prd, namespace: myapp, vars: {threshold: "90"}}
+      expected:
+        name: "[PRD] myapp CPU usage"            # exact match
+        query:
+          - contains: "service:myapp"
+          - regex: "> 9\\d$"
+        tags: [service:myapp, env:prd]
+        thresholds: {critical: 90}
+
+Without arguments every template in --template-dir is checked. Templates
+without a test file are skipped. Exits non-zero when any case fails.
+
+--update rewrites the expected name and query of every case with the current
+rendering.`,
+	RunE: runTemplateTest,
+}
+
+var (
+	templateTestDir      string
+	templateTestUpdate   bool
+	templateTestRefresh  bool
+	templateTestUpperEnv bool
+)
+
+func init() {
+	templateCmd.AddCommand(templateTestCmd)
+	templateTestCmd.Flags().StringVar(&templateTestDir, "template-dir", "templates", "Directory containing JSON templates, or a git:: source (default: templates/)")
+	templateTestCmd.Flags().BoolVar(&templateTestRefresh, "refresh-templates", false, "Fetch a remote --template-dir again instead of using the cached copy")
+	templateTestCmd.Flags().BoolVar(&templateTestUpdate, "update", false, "Regenerate expected name/query snapshots from the current rendering")
+	templateTestCmd.Flags().BoolVar(&templateTestUpperEnv, "uppercase-env-in-name", false, "Render {env} in names uppercased, as before {env:upper}")
+	templateTestCmd.Flags().MarkDeprecated("uppercase-env-in-name", "use {env:upper} in template names instead; the flag will be removed in the next release")
+}
+
+func runTemplateTest(cmd *cobra.Command, args []string) error {
+	files := args
+	if len(files) == 0 {
+		dir, err := fetchTemplateDir(templateTestDir, templateTestRefresh)
+		if err != nil {
+			return err
+		}
+		matches, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil {
+			return err
+		}
+		files = matches
+	}
+
+	passed, failed, skipped := 0, 0, 0
+	for _, templateFile := range files {
+		testFile := datadog.TemplateTestPath(templateFile)
+		if _, err := os.Stat(testFile); os.IsNotExist(err) {
+			skipped++
+			continue
+		}
+
+		out.Printf("\n📄 %s\n", templateFile)
+
+		templates, err := datadog.LoadTemplateFromJSON(templateFile)
+		if err != nil {
+			out.Printf("   ❌ %v\n", err)
+			failed++
+			continue
+		}
+		tests, err := datadog.LoadTemplateTests(testFile)
+		if err != nil {
+			out.Printf("   ❌ %v\n", err)
+			failed++
+			continue
+		}
+		for i := range tests.Cases {
+			tests.Cases[i].Inputs.UppercaseEnvInName = templateTestUpperEnv
+		}
+
+		if templateTestUpdate {
+			for i := range tests.Cases {
+				if err := datadog.UpdateTestCaseSnapshot(templates, &tests.Cases[i]); err != nil {
+					out.Printf("   ❌ %s: %v\n", tests.Cases[i].Name, err)
+					failed++
+					continue
+				}
+				out.Printf("   🔄 %s: snapshot updated\n", tests.Cases[i].Name)
+				passed++
+			}
+			if err := datadog.SaveTemplateTests(testFile, tests); err != nil {
+				errOut.Printf("❌ Error writing %s: %v\n", testFile, err)
+				return err
+			}
+			continue
+		}
+
+		for _, tc := range tests.Cases {
+			failures := datadog.CheckTestCase(templates, tc)
+			if len(failures) == 0 {
+				out.Printf("   ✅ %s\n", tc.Name)
+				passed++
+				continue
+			}
+			out.Printf("   ❌ %s\n", tc.Name)
+			for _, failure := range failures {
+				out.Printf("      - %s\n", failure)
+			}
+			failed++
+		}
+	}
+
+	out.Println("\n" + strings.Repeat("=", 80))
+	out.Printf("📊 %d passed, %d failed, %d template(s) without tests\n", passed, failed, skipped)
+
+	if failed > 0 {
+		return fmt.Errorf("%d template test case(s) failed", failed)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUppercaseEnvFlagOnNameRenderingCommands(t *testing.T) {
+	for _, path := range [][]string{
+		{"template"},
+		{"template", "test"},
+		{"apply"},
+		{"daemon"},
+		{"import", "prometheus"},
+	} {
+		cmd, _, err := rootCmd.Find(path)
+		if err != nil {
+			t.Fatalf("%s: %v", strings.Join(path, " "), err)
+		}
+		flag := cmd.Flags().Lookup("uppercase-env-in-name")
+		if flag == nil {
+			t.Errorf("%s has no --uppercase-env-in-name", strings.Join(path, " "))
+		} else if flag.Deprecated == "" {
+			t.Errorf("%s --uppercase-env-in-name is not deprecated", strings.Join(path, " "))
+		}
+	}
+}
+
+func TestTemplateTestUppercaseEnv(t *testing.T) {
+	newTestServer(t)
+	dir := t.TempDir()
+	template := `{"name": "[{env}] {service} CPU", "type": "metric alert", "query": "avg(last_5m):avg:cpu{env:{env},service:{service}} > 90"}`
+	tests := `cases:
+  - name: uppercased env
+    inputs: {service: api, env: prd, namespace: api}
+    expected:
+      name: "[PRD] api CPU"
+`
+	if err := os.WriteFile(filepath.Join(dir, "cpu.json"), []byte(template), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cpu_test.yaml"), []byte(tests), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if res := runCLI(t, nil, "template", "test", "--template-dir", dir); res.Err == nil {
+		t.Errorf("template test without the flag passed:\n%s", res.Stdout)
+	}
+	res := runCLI(t, nil, "template", "test", "--template-dir", dir, "--uppercase-env-in-name")
+	if res.Err != nil {
+		t.Errorf("template test --uppercase-env-in-name: %v\n%s", res.Err, res.Stdout)
+	}
+	if !strings.Contains(res.Stderr, "deprecated") {
+		t.Errorf("no deprecation warning:\n%s", res.Stderr)
+	}
+}
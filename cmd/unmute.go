@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var unmuteCmd = &cobra.Command{
+	Use:   "unmute",
+	Short: "Unmute a monitor or some of its groups",
+	Long: `Remove the mute of one or more scopes of a monitor, or every mute with
+--all-scopes. Unmuting a scope that is not muted is a no-op.
+
+Examples:
+  unmute --monitor-id 12345 --scope pod:checkout-7f9
+  unmute --monitor-id 12345 --all-scopes`,
+	RunE: runUnmute,
+}
+
+var (
+	unmuteMonitorID int
+	unmuteScopes    []string
+	unmuteAllScopes bool
+)
+
+func init() {
+	rootCmd.AddCommand(unmuteCmd)
+	unmuteCmd.Flags().IntVar(&unmuteMonitorID, "monitor-id", 0, "Monitor ID (required)")
+	unmuteCmd.MarkFlagRequired("monitor-id")
+	unmuteCmd.Flags().StringArrayVar(&unmuteScopes, "scope", []string{}, "Scope to unmute, e.g. pod:checkout-7f9 (can be used multiple times)")
+	unmuteCmd.Flags().BoolVar(&unmuteAllScopes, "all-scopes", false, "Remove every mute of the monitor, whole-monitor and scoped")
+	unmuteCmd.MarkFlagsMutuallyExclusive("scope", "all-scopes")
+}
+
+func runUnmute(cmd *cobra.Command, args []string) error {
+	if len(unmuteScopes) == 0 && !unmuteAllScopes {
+		return fmt.Errorf("either --scope or --all-scopes must be provided")
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	monitor, err := client.GetMonitor(unmuteMonitorID)
+	if err != nil {
+		reportMonitorError("getting monitor", err)
+		return err
+	}
+	out.Printf("🔍 Monitor %d: %s\n", monitor.ID, monitor.Name)
+
+	if unmuteAllScopes {
+		if len(datadog.SilencedScopes(*monitor)) == 0 {
+			out.Println("⏭️  Monitor is not muted (no-op)")
+			return nil
+		}
+		if _, err := client.UnmuteMonitor(monitor.ID, ""); err != nil {
+			reportMonitorError("unmuting monitor", err)
+			return err
+		}
+		out.Println("🔊 Unmuted all scopes")
+		return nil
+	}
+
+	var failed int
+	for _, scope := range unmuteScopes {
+		if !datadog.IsScopeSilenced(*monitor, scope) {
+			out.Printf("⏭️  %s is not muted (no-op)\n", scopeLabel(scope))
+			continue
+		}
+		if _, err := client.UnmuteMonitor(monitor.ID, scope); err != nil {
+			errOut.Printf("❌ Error unmuting %s: %v\n", scopeLabel(scope), err)
+			failed++
+			continue
+		}
+		out.Printf("🔊 Unmuted %s\n", scopeLabel(scope))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to unmute %d scope(s)", failed)
+	}
+	return nil
+}
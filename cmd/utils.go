@@ -2,7 +2,11 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
 )
@@ -18,15 +22,83 @@ func canonicalMonitorState(s string) string {
 	return strings.ToLower(s)
 }
 
-func filterMonitorsByState(monitors []datadog.Monitor, desiredState string) []datadog.Monitor {
-	want := canonicalMonitorState(desiredState)
-	if want == "" {
+// monitorStates are the monitor states Datadog reports, as it spells them
+var monitorStates = []string{"OK", "Alert", "Warn", "No Data", "Unknown", "Skipped", "Ignored"}
+
+// monitorStateAliases maps other common spellings (after canonicalMonitorState)
+// to a monitor state
+var monitorStateAliases = map[string]string{
+	"alerting":  "Alert",
+	"triggered": "Alert",
+	"warning":   "Warn",
+	"nodata":    "No Data",
+}
+
+// parseMonitorState returns the Datadog spelling of a monitor state, accepting
+// any case, "-"/"_" for spaces and the aliases in monitorStateAliases
+func parseMonitorState(value string) (string, error) {
+	state := canonicalMonitorState(value)
+	for _, valid := range monitorStates {
+		if state == canonicalMonitorState(valid) {
+			return valid, nil
+		}
+	}
+	if alias, ok := monitorStateAliases[state]; ok {
+		return alias, nil
+	}
+	if alias, ok := monitorStateAliases[strings.ReplaceAll(state, " ", "")]; ok {
+		return alias, nil
+	}
+	return "", fmt.Errorf("invalid monitor state %q (must be one of: %s)", value, strings.Join(monitorStates, ", "))
+}
+
+// parseMonitorStates parses a comma-separated --status value (e.g.
+// "Alert,Warn") into distinct monitor states
+func parseMonitorStates(value string) ([]string, error) {
+	var states []string
+	seen := make(map[string]bool)
+	for _, item := range splitCommaList(value) {
+		state, err := parseMonitorState(item)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[state] {
+			seen[state] = true
+			states = append(states, state)
+		}
+	}
+	return states, nil
+}
+
+// parseStatusFlag parses the --status flag shared by the commands that select
+// monitors
+func parseStatusFlag(value string) ([]string, error) {
+	states, err := parseMonitorStates(value)
+	if err != nil {
+		return nil, fmt.Errorf("--status: %v", err)
+	}
+	return states, nil
+}
+
+// filterMonitorsByState keeps the monitors in one of states; with anyGroup
+// also those with any group in one of them (monitors fetched with group
+// states). A monitor without a reported state counts as OK.
+func filterMonitorsByState(monitors []datadog.Monitor, states []string, anyGroup bool) []datadog.Monitor {
+	if len(states) == 0 {
 		return monitors
 	}
 	var filtered []datadog.Monitor
 	for _, m := range monitors {
-		if canonicalMonitorState(m.OverallState) == want {
-			filtered = append(filtered, m)
+		overall := m.OverallState
+		if overall == "" {
+			overall = "OK"
+		}
+		for _, state := range states {
+			want := canonicalMonitorState(state)
+			if canonicalMonitorState(overall) == want || (anyGroup && datadog.HasGroupInState(m, want)) {
+				filtered = append(filtered, m)
+				break
+			}
 		}
 	}
 	return filtered
@@ -56,6 +128,67 @@ func filterMonitorsByServiceEnvNamespace(monitors []datadog.Monitor, service, en
 	return filtered
 }
 
+// filterMonitorsByTags keeps the monitors with every tag, as monitor_tags
+// does; a "!=" prefix excludes monitors with the tag instead
+func filterMonitorsByTags(monitors []datadog.Monitor, tags []string) []datadog.Monitor {
+	if len(tags) == 0 {
+		return monitors
+	}
+
+	var filtered []datadog.Monitor
+	for _, monitor := range monitors {
+		matches := true
+		for _, tag := range tags {
+			if excluded, ok := strings.CutPrefix(tag, "!="); ok {
+				matches = !hasExactTag(monitor.Tags, excluded)
+			} else {
+				matches = hasExactTag(monitor.Tags, tag)
+			}
+			if !matches {
+				break
+			}
+		}
+		if matches {
+			filtered = append(filtered, monitor)
+		}
+	}
+	return filtered
+}
+
+// filterMonitorsMissingTagKeys keeps the monitors without a tag of one of
+// the keys, or with all without a tag of any of them: the inverse of a key
+// filter, for finding untagged monitors. A tag without a colon or a value
+// ("service", "service:") has no key, so it leaves the key missing.
+func filterMonitorsMissingTagKeys(monitors []datadog.Monitor, keys []string, all bool) []datadog.Monitor {
+	if len(keys) == 0 {
+		return monitors
+	}
+
+	var filtered []datadog.Monitor
+	for _, monitor := range monitors {
+		missing := 0
+		for _, key := range keys {
+			if !hasTagKey(monitor.Tags, key) {
+				missing++
+			}
+		}
+		if (all && missing == len(keys)) || (!all && missing > 0) {
+			filtered = append(filtered, monitor)
+		}
+	}
+	return filtered
+}
+
+// hasTagKey reports whether one of the tags is key:value with a value
+func hasTagKey(tags []string, key string) bool {
+	for _, tag := range tags {
+		if tagKey, value, ok := strings.Cut(tag, ":"); ok && tagKey == key && value != "" {
+			return true
+		}
+	}
+	return false
+}
+
 func hasExactTag(tags []string, want string) bool {
 	for _, t := range tags {
 		if t == want {
@@ -81,3 +214,390 @@ func filterMonitorsByServices(monitors []datadog.Monitor, services []string) []d
 	}
 	return filtered
 }
+
+// filterMonitorsByCreator keeps monitors created by the user with this email or handle (case-insensitive)
+func filterMonitorsByCreator(monitors []datadog.Monitor, creator string) []datadog.Monitor {
+	var filtered []datadog.Monitor
+	for _, monitor := range monitors {
+		if monitor.Creator == nil {
+			continue
+		}
+		if strings.EqualFold(monitor.Creator.Email, creator) || strings.EqualFold(monitor.Creator.Handle, creator) {
+			filtered = append(filtered, monitor)
+		}
+	}
+	return filtered
+}
+
+// formatCreator formats the creator of a monitor as "Name <email>"
+func formatCreator(creator *datadog.Creator) string {
+	if creator == nil {
+		return "unknown"
+	}
+	email := creator.Email
+	if email == "" {
+		email = creator.Handle
+	}
+	if creator.Name == "" {
+		return email
+	}
+	return fmt.Sprintf("%s <%s>", creator.Name, email)
+}
+
+// updateTagsOnMonitors applies a tag update to each monitor and collects per-monitor
+// results; update reports whether it changed the monitor. On interrupt or
+// --timeout it stops and returns the results so far together with the
+// interruption error.
+func updateTagsOnMonitors(monitors []datadog.Monitor, update func(monitorID int) (*datadog.Monitor, bool, error)) ([]datadog.TagUpdateResult, error) {
+	var results []datadog.TagUpdateResult
+	for _, monitor := range monitors {
+		if err := interruption(nil); err != nil {
+			return results, err
+		}
+		updated, changed, err := update(monitor.ID)
+		if stopErr := interruption(err); err != nil && stopErr != nil {
+			return results, stopErr
+		}
+		results = append(results, datadog.NewTagUpdateResult(monitor, updated, changed, err))
+		recordFailure(err)
+	}
+	return results, nil
+}
+
+// monitorIDArgs returns the monitor IDs of a --monitor-id flag and of the
+// positional arguments (comma-separated or not), in order, without duplicates
+func monitorIDArgs(flagIDs []int, args []string) ([]int, error) {
+	ids := append([]int(nil), flagIDs...)
+	for _, arg := range args {
+		for _, value := range splitCommaList(arg) {
+			id, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid monitor ID %q", value)
+			}
+			ids = append(ids, id)
+		}
+	}
+
+	seen := make(map[int]bool, len(ids))
+	unique := ids[:0]
+	for _, id := range ids {
+		if id <= 0 {
+			return nil, fmt.Errorf("invalid monitor ID %d", id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique, nil
+}
+
+// fetchMonitorsByID fetches the monitors of a batch of monitor IDs. The IDs
+// that can't be fetched are reported and returned as failed results, for the
+// summary of the tag update. Like a Mutating selection, the batch leaves out
+// protected monitors (see skipProtected).
+func fetchMonitorsByID(client *datadog.Client, ids []int) ([]datadog.Monitor, []datadog.TagUpdateResult, error) {
+	monitors, failures := client.GetMonitors(ids, nil, 0, nil)
+	var failed []datadog.TagUpdateResult
+	for _, id := range ids {
+		err, ok := failures[id]
+		if !ok {
+			continue
+		}
+		if stopErr := interruption(err); stopErr != nil {
+			return monitors, failed, stopErr
+		}
+		reportMonitorError(fmt.Sprintf("getting monitor %d", id), err)
+		recordFailure(err)
+		failed = append(failed, datadog.NewTagUpdateResult(datadog.Monitor{ID: id}, nil, false, err))
+	}
+	monitors, err := skipProtected(monitors)
+	return monitors, failed, err
+}
+
+// printTagUpdateResults prints the summary of a bulk tag update
+func printTagUpdateResults(results []datadog.TagUpdateResult) {
+	var successful, unchanged, notFound, failed []datadog.TagUpdateResult
+	for _, result := range results {
+		switch result.Status {
+		case datadog.StatusUpdated:
+			successful = append(successful, result)
+		case datadog.StatusUnchanged:
+			unchanged = append(unchanged, result)
+		case datadog.StatusNotFound:
+			notFound = append(notFound, result)
+		default:
+			failed = append(failed, result)
+		}
+	}
+
+	out.Printf("\n📊 Results:\n")
+	out.Printf("✅ Successfully updated: %d\n", len(successful))
+	if len(unchanged) > 0 {
+		out.Printf("⏭️  Unchanged (tags already as requested): %d\n", len(unchanged))
+	}
+	if len(notFound) > 0 {
+		out.Printf("🔍 Not found (deleted meanwhile): %d\n", len(notFound))
+	}
+	out.Printf("❌ Failed: %d\n", len(failed))
+
+	if len(successful) > 0 {
+		out.Println("\n✅ Successfully updated monitors:")
+		for _, result := range successful {
+			out.Printf("   ✅ ID %d: %s\n", result.ID, result.Name)
+			if len(result.Tags) > 0 {
+				out.Printf("      Tags: %s\n", strings.Join(result.Tags, ", "))
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		out.Println("\n❌ Failed to update monitors:")
+		for _, result := range failed {
+			out.Printf("   ⚠️  ID %d: %s - %v\n", result.ID, result.Name, result.Err)
+		}
+	}
+}
+
+// writeTagRollback writes the rollback file of a tag update, so it can be undone with the rollback command
+func writeTagRollback(path, command string, tags []string, results []datadog.TagUpdateResult) error {
+	rollback := datadog.NewTagRollback(fmt.Sprintf("%s %s", command, strings.Join(tags, ",")), results, time.Now())
+	if err := rollback.Save(path); err != nil {
+		errOut.Printf("❌ Error writing rollback file %s: %v\n", path, err)
+		return err
+	}
+	out.Printf("\n↩️  Rollback file written: %s (%d monitor(s))\n", path, len(rollback.Monitors))
+	out.Printf("💡 Undo with: datadog-monitor-manager rollback --file %s\n", path)
+	return nil
+}
+
+// formatDuration formats a duration as days, hours and minutes (e.g., "3d 4h", "12m")
+func formatDuration(d time.Duration) string {
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
+
+// Values of --time-format
+const (
+	timeFormatLocal    = "local"
+	timeFormatUTC      = "utc"
+	timeFormatRelative = "relative"
+)
+
+// timeLayout is how dates shown to humans are formatted
+const timeLayout = "2006-01-02 15:04:05"
+
+var (
+	// timeFormatFlag is --time-format
+	timeFormatFlag string
+	// timeFormat is the resolved --time-format
+	timeFormat = timeFormatLocal
+)
+
+// resolveTimeFormat sets the time format from --time-format, else
+// DDMM_TIME_FORMAT, else local
+func resolveTimeFormat() error {
+	format := timeFormatFlag
+	if format == "" {
+		format = os.Getenv("DDMM_TIME_FORMAT")
+	}
+	switch format {
+	case "":
+		timeFormat = timeFormatLocal
+	case timeFormatLocal, timeFormatUTC, timeFormatRelative:
+		timeFormat = format
+	default:
+		return fmt.Errorf("invalid --time-format %q (must be local, utc or relative)", format)
+	}
+	return nil
+}
+
+// formatTime formats a date shown to humans in the --time-format: local
+// time, UTC (e.g. "2024-01-02 15:04:05 UTC") or relative to now (e.g.
+// "3 hours ago", "in 2h 0m")
+func formatTime(t time.Time) string {
+	switch timeFormat {
+	case timeFormatUTC:
+		return t.UTC().Format(timeLayout) + " UTC"
+	case timeFormatRelative:
+		if d := time.Until(t); d >= time.Minute {
+			return "in " + formatDuration(d)
+		}
+		return formatAgo(time.Since(t))
+	default:
+		return t.Local().Format(timeLayout)
+	}
+}
+
+// formatTimestamp formats a monitor timestamp with a relative suffix,
+// e.g. "2024-01-02 15:04:05 (3 months ago)"
+func formatTimestamp(ts datadog.Timestamp) string {
+	if ts.IsZero() {
+		return "unknown"
+	}
+	t := ts.Time()
+	if timeFormat == timeFormatRelative {
+		return formatTime(t)
+	}
+	return fmt.Sprintf("%s (%s)", formatTime(t), formatAgo(time.Since(t)))
+}
+
+// formatAgo formats an elapsed duration in its largest unit, e.g. "3 months ago"
+func formatAgo(d time.Duration) string {
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"year", 365 * 24 * time.Hour},
+		{"month", 30 * 24 * time.Hour},
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+	for _, unit := range units {
+		if count := int(d / unit.size); count >= 1 {
+			if count == 1 {
+				return fmt.Sprintf("1 %s ago", unit.name)
+			}
+			return fmt.Sprintf("%d %ss ago", count, unit.name)
+		}
+	}
+	return "just now"
+}
+
+// parseDuration parses a duration like time.ParseDuration, also accepting whole
+// days and weeks (e.g., 7d, 2w)
+func parseDuration(value string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if strings.HasSuffix(value, suffix) {
+			count, err := strconv.Atoi(strings.TrimSuffix(value, suffix))
+			if err != nil || count < 0 {
+				return 0, fmt.Errorf("invalid duration %q (e.g., 12h, 7d, 2w)", value)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q (e.g., 12h, 7d, 2w)", value)
+	}
+	return d, nil
+}
+
+// parseTimeBound parses a --*-since/--*-before value: an RFC3339 time, a date
+// (2006-01-02, midnight UTC) or a duration before now (e.g., 24h, 7d, 2w)
+func parseTimeBound(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	d, err := parseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q (use RFC3339, YYYY-MM-DD or a duration like 24h, 7d)", value)
+	}
+	return now.Add(-d), nil
+}
+
+// timeRange is an optional since/before window; zero bounds are open
+type timeRange struct {
+	since  time.Time
+	before time.Time
+}
+
+// parseTimeRange parses the --<field>-since/--<field>-before flag values of
+// one timestamp field, rejecting an empty range
+func parseTimeRange(field, since, before string, now time.Time) (timeRange, error) {
+	var r timeRange
+	var err error
+	if since != "" {
+		if r.since, err = parseTimeBound(since, now); err != nil {
+			return r, err
+		}
+	}
+	if before != "" {
+		if r.before, err = parseTimeBound(before, now); err != nil {
+			return r, err
+		}
+	}
+	if !r.since.IsZero() && !r.before.IsZero() && !r.since.Before(r.before) {
+		return r, fmt.Errorf("--%s-since %s is not before --%s-before %s: no monitor can match", field, since, field, before)
+	}
+	return r, nil
+}
+
+// set reports whether the range has a bound
+func (r timeRange) set() bool {
+	return !r.since.IsZero() || !r.before.IsZero()
+}
+
+// contains reports whether a timestamp is in the range; an unknown (zero)
+// timestamp never is
+func (r timeRange) contains(ts datadog.Timestamp) bool {
+	if ts.IsZero() {
+		return false
+	}
+	t := ts.Time()
+	if !r.since.IsZero() && t.Before(r.since) {
+		return false
+	}
+	if !r.before.IsZero() && !t.Before(r.before) {
+		return false
+	}
+	return true
+}
+
+// filterMonitorsByTime keeps monitors whose created and modified times are in the ranges
+func filterMonitorsByTime(monitors []datadog.Monitor, created, modified timeRange) []datadog.Monitor {
+	if !created.set() && !modified.set() {
+		return monitors
+	}
+	var filtered []datadog.Monitor
+	for _, monitor := range monitors {
+		if created.set() && !created.contains(monitor.CreatedAt) {
+			continue
+		}
+		if modified.set() && !modified.contains(monitor.Modified) {
+			continue
+		}
+		filtered = append(filtered, monitor)
+	}
+	return filtered
+}
+
+// monitorSortKeys are the values accepted by --sort
+var monitorSortKeys = []string{"id", "name", "created", "modified"}
+
+// sortMonitors sorts monitors by id, name, created or modified time
+func sortMonitors(monitors []datadog.Monitor, key string, desc bool) error {
+	var less func(a, b datadog.Monitor) bool
+	switch key {
+	case "id":
+		less = func(a, b datadog.Monitor) bool { return a.ID < b.ID }
+	case "name":
+		less = func(a, b datadog.Monitor) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }
+	case "created":
+		less = func(a, b datadog.Monitor) bool { return a.CreatedAt.Time().Before(b.CreatedAt.Time()) }
+	case "modified":
+		less = func(a, b datadog.Monitor) bool { return a.Modified.Time().Before(b.Modified.Time()) }
+	default:
+		return fmt.Errorf("invalid --sort %q (must be one of: %s)", key, strings.Join(monitorSortKeys, ", "))
+	}
+	sort.SliceStable(monitors, func(i, j int) bool {
+		if desc {
+			return less(monitors[j], monitors[i])
+		}
+		return less(monitors[i], monitors[j])
+	})
+	return nil
+}
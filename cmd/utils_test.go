@@ -0,0 +1,335 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+func TestParseStatusFlag(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		want  []string
+	}{
+		{"Alert", []string{"Alert"}},
+		{"OK", []string{"OK"}},
+		// Any case, and - or _ for spaces
+		{"no data", []string{"No Data"}},
+		{"NO_DATA", []string{"No Data"}},
+		{"no-data", []string{"No Data"}},
+		{"  ignored ", []string{"Ignored"}},
+		// Aliases
+		{"alerting", []string{"Alert"}},
+		{"triggered", []string{"Alert"}},
+		{"warning", []string{"Warn"}},
+		{"nodata", []string{"No Data"}},
+		{"NoData", []string{"No Data"}},
+		// Comma lists keep their order without duplicates
+		{"Alert,Warn", []string{"Alert", "Warn"}},
+		{"warn, alerting, Alert,,", []string{"Warn", "Alert"}},
+		{"", nil},
+	} {
+		got, err := parseStatusFlag(tc.value)
+		if err != nil {
+			t.Errorf("parseStatusFlag(%q): %v", tc.value, err)
+			continue
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("parseStatusFlag(%q) = %q, want %q", tc.value, got, tc.want)
+		}
+	}
+
+	for _, value := range []string{"down", "Alert,bogus", "no  data x", "al"} {
+		_, err := parseStatusFlag(value)
+		if err == nil {
+			t.Errorf("parseStatusFlag(%q) succeeded", value)
+			continue
+		}
+		if msg := err.Error(); !strings.HasPrefix(msg, "--status: invalid monitor state") || !strings.Contains(msg, "OK, Alert, Warn, No Data, Unknown, Skipped, Ignored") {
+			t.Errorf("parseStatusFlag(%q) = %v", value, err)
+		}
+	}
+}
+
+func TestStatusFlag(t *testing.T) {
+	srv := newTestServer(t)
+	for _, state := range []string{"OK", "Alert", "Warn", "No Data"} {
+		srv.AddMonitor(datadog.Monitor{Name: state + " monitor", Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90", Tags: []string{"env:prd"}, OverallState: state})
+	}
+
+	res := runCLI(t, nil, "list", "--env", "prd", "--status", "alerting,nodata")
+	if res.Err != nil {
+		t.Fatalf("list --status: %v\n%s", res.Err, res.Stderr)
+	}
+	for _, state := range []string{"OK", "Alert", "Warn", "No Data"} {
+		if listed, want := strings.Contains(res.Stdout, state+" monitor"), state == "Alert" || state == "No Data"; listed != want {
+			t.Errorf("%s monitor listed: %v, want %v\n%s", state, listed, want, res.Stdout)
+		}
+	}
+
+	// Every command selecting monitors rejects an unknown state before
+	// calling the API
+	srv.ResetRequests()
+	for _, args := range [][]string{
+		{"list", "--status", "alerting,down"},
+		{"add-tags", "--env", "prd", "--tag", "team:sre", "--status", "down"},
+		{"remove-tags", "--env", "prd", "--tag", "team:sre", "--status", "down"},
+	} {
+		res := runCLI(t, nil, args...)
+		if res.Err == nil || !strings.Contains(res.Err.Error(), `invalid monitor state "down"`) {
+			t.Errorf("%s: %v", strings.Join(args, " "), res.Err)
+		}
+	}
+	if reqs := srv.Requests(); len(reqs) != 0 {
+		t.Errorf("%d request(s) with an invalid --status", len(reqs))
+	}
+}
+
+// keptIDs joins the IDs of monitors with commas
+func keptIDs(monitors []datadog.Monitor) string {
+	ids := make([]int, len(monitors))
+	for i, monitor := range monitors {
+		ids[i] = monitor.ID
+	}
+	return joinIDs(ids, ",")
+}
+
+func TestFilterMonitorsMissingTagKeys(t *testing.T) {
+	monitors := []datadog.Monitor{
+		{ID: 1, Tags: nil},
+		{ID: 2, Tags: []string{"service:api", "team:sre"}},
+		{ID: 3, Tags: []string{"service:api", "service:web"}},
+		// Malformed tags have no key
+		{ID: 4, Tags: []string{"service", "team:"}},
+		{ID: 5, Tags: []string{":api", "team:sre"}},
+		{ID: 6, Tags: []string{"service:api"}},
+		{ID: 7, Tags: []string{"servicex:api", "team:sre:lead"}},
+	}
+	for _, tc := range []struct {
+		keys []string
+		all  bool
+		want string
+	}{
+		{nil, false, "1,2,3,4,5,6,7"},
+		{[]string{"service"}, false, "1,4,5,7"},
+		{[]string{"team"}, false, "1,3,4,6"},
+		{[]string{"service", "team"}, false, "1,3,4,5,6,7"},
+		{[]string{"service", "team"}, true, "1,4"},
+		{[]string{"owner"}, true, "1,2,3,4,5,6,7"},
+	} {
+		if got := keptIDs(filterMonitorsMissingTagKeys(monitors, tc.keys, tc.all)); got != tc.want {
+			t.Errorf("keys %v (all %v): kept %s, want %s", tc.keys, tc.all, got, tc.want)
+		}
+	}
+}
+
+func TestFilterMonitorsByTags(t *testing.T) {
+	monitors := []datadog.Monitor{
+		{ID: 1, Tags: []string{"env:prd", "team:sre"}},
+		{ID: 2, Tags: []string{"env:prd", "team:web"}},
+		{ID: 3, Tags: []string{"env:stg", "team:sre"}},
+		{ID: 4, Tags: nil},
+	}
+	for _, tc := range []struct {
+		tags []string
+		want string
+	}{
+		{nil, "1,2,3,4"},
+		{[]string{"env:prd"}, "1,2"},
+		{[]string{"env:prd", "team:sre"}, "1"},
+		// Negation excludes the monitors with the tag, including untagged ones
+		{[]string{"!=team:sre"}, "2,4"},
+		{[]string{"env:prd", "!=team:sre"}, "2"},
+		{[]string{"!=env:prd", "!=team:sre"}, "4"},
+		// Negation is exact, like the inclusion
+		{[]string{"!=team"}, "1,2,3,4"},
+		{[]string{"!=env:prd", "env:prd"}, ""},
+	} {
+		if got := keptIDs(filterMonitorsByTags(monitors, tc.tags)); got != tc.want {
+			t.Errorf("tags %q: kept %s, want %s", tc.tags, got, tc.want)
+		}
+	}
+}
+
+func TestListMissingTagKey(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddMonitor(datadog.Monitor{Name: "tagged", Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90", Tags: []string{"env:prd", "service:api", "team:sre"}})
+	srv.AddMonitor(datadog.Monitor{Name: "no service", Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90", Tags: []string{"env:prd", "service", "team:sre"}})
+	srv.AddMonitor(datadog.Monitor{Name: "untagged", Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90", Tags: []string{"env:prd"}})
+
+	res := runCLI(t, nil, "list", "--env", "prd", "--missing-tag-key", "service")
+	if res.Err != nil {
+		t.Fatalf("list: %v\n%s", res.Err, res.Stderr)
+	}
+	if !strings.Contains(res.Stdout, "2 of 3 monitor(s) without a service tag") || strings.Contains(res.Stdout, "Name: tagged\n") {
+		t.Errorf("unexpected output:\n%s", res.Stdout)
+	}
+
+	res = runCLI(t, nil, "list", "--env", "prd", "--missing-tag-key", "service:", "--missing-tag-key", "team", "--missing-all", "--output", "csv")
+	if res.Err != nil {
+		t.Fatalf("list: %v\n%s", res.Err, res.Stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(res.Stdout), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "id,name,type,state,tags") || !strings.Contains(lines[1], ",untagged,") {
+		t.Errorf("unexpected CSV:\n%s", res.Stdout)
+	}
+	// The count goes to stderr, keeping the CSV clean
+	if !strings.Contains(res.Stderr, "1 of 3 monitor(s) without any of the tag keys service, team") {
+		t.Errorf("no count on stderr:\n%s", res.Stderr)
+	}
+
+	for _, args := range [][]string{
+		{"--missing-all"},
+		{"--missing-tag-key", "service", "--missing-any", "--missing-all"},
+		{"--missing-tag-key", "service:api"},
+	} {
+		if res := runCLI(t, nil, append([]string{"list"}, args...)...); res.Err == nil {
+			t.Errorf("list %s succeeded", strings.Join(args, " "))
+		}
+	}
+}
+
+func TestFilterMonitorsByServices(t *testing.T) {
+	monitors := []datadog.Monitor{
+		{ID: 1, Tags: []string{"service:api"}},
+		{ID: 2, Tags: []string{"service:worker"}},
+		{ID: 3, Tags: []string{"service:api-gateway"}},
+		{ID: 4, Tags: []string{"service:web", "service:worker"}},
+		{ID: 5, Tags: nil},
+	}
+	for _, tc := range []struct {
+		services []string
+		want     string
+	}{
+		{nil, "1,2,3,4,5"},
+		{[]string{"api"}, "1"},
+		// Any of the services, each monitor once
+		{[]string{"api", "worker"}, "1,2,4"},
+		{[]string{"worker", "web"}, "2,4"},
+		{[]string{"db"}, ""},
+	} {
+		if got := keptIDs(filterMonitorsByServices(monitors, tc.services)); got != tc.want {
+			t.Errorf("services %q: kept %s, want %s", tc.services, got, tc.want)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		want  time.Duration
+	}{
+		{"24h", 24 * time.Hour},
+		{"90m", 90 * time.Minute},
+		{"1h30m", 90 * time.Minute},
+		{"2d", 48 * time.Hour},
+		{"2w", 14 * 24 * time.Hour},
+		{"0d", 0},
+	} {
+		got, err := parseDuration(tc.value)
+		if err != nil || got != tc.want {
+			t.Errorf("parseDuration(%q) = %v, %v, want %v", tc.value, got, err, tc.want)
+		}
+	}
+	for _, value := range []string{"", "2", "d", "-1d", "1.5d", "2 days", "1y"} {
+		if _, err := parseDuration(value); err == nil || !strings.Contains(err.Error(), "e.g., 12h, 7d, 2w") {
+			t.Errorf("parseDuration(%q) = %v", value, err)
+		}
+	}
+}
+
+func TestParseTimeBound(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		value string
+		want  time.Time
+	}{
+		{"2024-04-30T08:00:00Z", time.Date(2024, 4, 30, 8, 0, 0, 0, time.UTC)},
+		{"2024-04-30T10:00:00+02:00", time.Date(2024, 4, 30, 8, 0, 0, 0, time.UTC)},
+		// A date is midnight UTC
+		{"2024-04-30", time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)},
+		// A duration is that long before now
+		{"2h", now.Add(-2 * time.Hour)},
+		{"90m", now.Add(-90 * time.Minute)},
+		{"1d", now.Add(-24 * time.Hour)},
+		{"2w", now.Add(-14 * 24 * time.Hour)},
+		{"0d", now},
+	} {
+		got, err := parseTimeBound(tc.value, now)
+		if err != nil || !got.Equal(tc.want) {
+			t.Errorf("parseTimeBound(%q) = %v, %v, want %v", tc.value, got, err, tc.want)
+		}
+	}
+	for _, value := range []string{"", "yesterday", "-1d", "1y", "2024-13-01", "2024/04/30", "2024-04-30 08:00:00", "2024-04-30T08:00:00"} {
+		if got, err := parseTimeBound(value, now); err == nil || err.Error() != fmt.Sprintf("invalid time %q (use RFC3339, YYYY-MM-DD or a duration like 24h, 7d)", value) {
+			t.Errorf("parseTimeBound(%q) = %v, %v", value, got, err)
+		}
+	}
+}
+
+func TestParseTimeRange(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		since, before string
+		want          timeRange
+	}{
+		{"", "", timeRange{}},
+		{"7d", "", timeRange{since: now.Add(-7 * 24 * time.Hour)}},
+		{"", "2024-04-30", timeRange{before: time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)}},
+		{"2w", "1d", timeRange{since: now.Add(-14 * 24 * time.Hour), before: now.Add(-24 * time.Hour)}},
+	} {
+		got, err := parseTimeRange("created", tc.since, tc.before, now)
+		if err != nil || got != tc.want {
+			t.Errorf("parseTimeRange(%q, %q) = %+v, %v, want %+v", tc.since, tc.before, got, err, tc.want)
+		}
+	}
+	for _, tc := range []struct{ since, before, err string }{
+		// Since after before, or equal: the range is empty
+		{"1d", "2d", "--created-since 1d is not before --created-before 2d: no monitor can match"},
+		{"2024-05-01", "2024-04-30T00:00:00Z", "--created-since 2024-05-01 is not before --created-before 2024-04-30T00:00:00Z"},
+		{"2024-04-30", "2024-04-30T00:00:00Z", "is not before"},
+		{"soon", "1d", `invalid time "soon"`},
+		{"1d", "later", `invalid time "later"`},
+	} {
+		if _, err := parseTimeRange("created", tc.since, tc.before, now); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("parseTimeRange(%q, %q) = %v, want %q", tc.since, tc.before, err, tc.err)
+		}
+	}
+}
+
+func TestListTimeRangeFlags(t *testing.T) {
+	srv := newTestServer(t)
+	res := runCLI(t, nil, "list", "--modified-since", "1d", "--modified-before", "7d")
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "--modified-since 1d is not before --modified-before 7d") {
+		t.Errorf("list = %v, want the empty range rejected", res.Err)
+	}
+	if requests := srv.Requests(); len(requests) != 0 {
+		t.Errorf("requests sent: %v", requests)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	for _, tc := range []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0m"},
+		{59 * time.Second, "0m"},
+		{45 * time.Minute, "45m"},
+		{time.Hour, "1h 0m"},
+		{2*time.Hour + 13*time.Minute + 30*time.Second, "2h 13m"},
+		{23*time.Hour + 59*time.Minute, "23h 59m"},
+		// Days drop the minutes
+		{24 * time.Hour, "1d 0h"},
+		{51*time.Hour + 30*time.Minute, "2d 3h"},
+		{40 * 24 * time.Hour, "40d 0h"},
+	} {
+		if got := formatDuration(tc.d); got != tc.want {
+			t.Errorf("formatDuration(%v) = %q, want %q", tc.d, got, tc.want)
+		}
+	}
+}
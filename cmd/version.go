@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/version"
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version, optionally checking for a newer release",
+	Long: `Print the version of datadog-monitor-manager. With --check, the latest GitHub
+release is looked up and an upgrade hint is printed when it is newer.
+
+Other commands check at most once a day in the background and print the same
+hint on stderr; disable that with --no-update-check or DDMM_NO_UPDATE_CHECK=1.`,
+	RunE: runVersion,
+}
+
+var (
+	versionCheck  bool
+	noUpdateCheck bool
+)
+
+// updateNoticeGrace is how long a command waits at exit for a background update
+// check still in flight before giving up on it
+const updateNoticeGrace = 200 * time.Millisecond
+
+// updateNotice receives the upgrade hint of the background update check ("" for none)
+var updateNotice chan string
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+	versionCmd.Flags().BoolVar(&versionCheck, "check", false, "Check GitHub for a newer release")
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	fmt.Println(version.Version)
+	if !versionCheck {
+		return nil
+	}
+
+	check, err := version.Check(commandContext(), true)
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+	if hint := upgradeHint(check.Latest); hint != "" {
+		out.Println(hint)
+	} else if version.IsRelease() {
+		out.Printf("✅ Up to date (latest release: %s)\n", check.Latest)
+	} else {
+		out.Printf("ℹ️  Development build; latest release is %s\n", check.Latest)
+	}
+	return nil
+}
+
+// upgradeHint returns the upgrade message when latest is newer than this binary
+func upgradeHint(latest string) string {
+	if !version.IsNewer(latest, version.Version) {
+		return ""
+	}
+	return fmt.Sprintf("⬆️  A newer version is available: %s (you have %s)\n   Download: %s", latest, version.Version, version.ReleasesPage)
+}
+
+// updateCheckEnabled reports whether the background update check runs for cmd
+func updateCheckEnabled(cmd *cobra.Command) bool {
+	if noUpdateCheck || os.Getenv("DDMM_NO_UPDATE_CHECK") != "" || !version.IsRelease() {
+		return false
+	}
+	switch cmd.Name() {
+	case "version", "help", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return false
+	}
+	return true
+}
+
+// startUpdateCheck looks up the latest release in the background, using the
+// cached result when it is less than a day old. Failures are ignored.
+func startUpdateCheck(cmd *cobra.Command) {
+	if !updateCheckEnabled(cmd) {
+		return
+	}
+	notice := make(chan string, 1)
+	updateNotice = notice
+	go func() {
+		check, err := version.Check(context.Background(), false)
+		if err != nil {
+			notice <- ""
+			return
+		}
+		notice <- upgradeHint(check.Latest)
+	}()
+}
+
+// printUpdateNotice prints the upgrade hint of the background update check on
+// stderr. A check still in flight is given updateNoticeGrace, then abandoned.
+func printUpdateNotice() {
+	if updateNotice == nil {
+		return
+	}
+	select {
+	case hint := <-updateNotice:
+		if hint != "" {
+			errOut.Printf("\n%s\n", hint)
+		}
+	case <-time.After(updateNoticeGrace):
+	}
+}
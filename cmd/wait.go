@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var waitCmd = &cobra.Command{
+	Use:   "wait",
+	Short: "Wait until monitors reach (or leave) a state",
+	Long: `Poll monitors until all of them satisfy a condition, e.g. after deploying
+monitors for a new service, wait until they receive data.
+
+The monitors are selected once, by --monitor-id or by filters, and polled every
+--poll-interval with a progress line per poll. The command exits 0 once every
+monitor satisfies the condition, 124 when --timeout expires first and 130 on
+Ctrl-C. A monitor deleted while waiting is dropped from the wait and makes the
+command exit 3 at the end.
+
+With --unmute, the monitors muted as a whole (e.g. created with
+--create-muted) are unmuted once every monitor satisfies the condition, so a
+pipeline can lift the bootstrap mute as soon as data flows.
+
+Examples:
+  wait --monitor-id 12345,12346 --until-state OK --timeout 15m
+  wait --service myapp --env prd --until-not-state "No Data" --timeout 10m --poll-interval 20s
+  wait --service myapp --env prd --until-not-state "No Data" --timeout 2h --unmute`,
+	RunE: runWait,
+}
+
+var (
+	waitMonitorIDs    []int
+	waitUntilState    string
+	waitUntilNotState string
+	waitPollInterval  time.Duration
+	waitService       string
+	waitEnv           string
+	waitNamespace     string
+	waitFilterTags    string
+	waitQuery         string
+
+	waitUnmute bool
+)
+
+// waitProgressSample is how many waiting monitors a progress line names
+const waitProgressSample = 3
+
+func init() {
+	rootCmd.AddCommand(waitCmd)
+	waitCmd.Flags().IntSliceVar(&waitMonitorIDs, "monitor-id", nil, "Monitor IDs to wait for (comma-separated or repeated)")
+	waitCmd.Flags().StringVar(&waitUntilState, "until-state", "", "Wait until every monitor is in this state (e.g., OK)")
+	waitCmd.Flags().StringVar(&waitUntilNotState, "until-not-state", "", "Wait until no monitor is in this state (e.g., \"No Data\")")
+	waitCmd.Flags().DurationVar(&waitPollInterval, "poll-interval", 30*time.Second, "Time between polls")
+	waitCmd.Flags().StringVar(&waitService, "service", "", "Filter by service")
+	waitCmd.Flags().StringVar(&waitEnv, "env", "", "Filter by environment")
+	waitCmd.Flags().StringVar(&waitNamespace, "namespace", "", "Filter by namespace")
+	waitCmd.Flags().StringVar(&waitFilterTags, "filter-tags", "", "Filter by tags (comma-separated)")
+	waitCmd.Flags().StringVar(&waitQuery, "query", "", "Complex search query (e.g., service:(service1 OR service2))")
+	waitCmd.Flags().BoolVar(&waitUnmute, "unmute", false, "Unmute the monitors muted as a whole once the condition is met")
+}
+
+// waitCondition is the state a monitor must be in, or must have left
+type waitCondition struct {
+	state  string // as given on the command line
+	negate bool
+}
+
+// satisfied reports whether a monitor meets the condition
+func (w waitCondition) satisfied(monitor datadog.Monitor) bool {
+	state := monitor.OverallState
+	if state == "" {
+		state = "OK"
+	}
+	return (canonicalMonitorState(state) == canonicalMonitorState(w.state)) != w.negate
+}
+
+// String describes the condition, e.g. "in OK" or "out of No Data"
+func (w waitCondition) String() string {
+	if w.negate {
+		return "out of " + w.state
+	}
+	return "in " + w.state
+}
+
+func runWait(cmd *cobra.Command, args []string) error {
+	if (waitUntilState == "") == (waitUntilNotState == "") {
+		return fmt.Errorf("exactly one of --until-state or --until-not-state is required")
+	}
+	condition := waitCondition{state: waitUntilState}
+	flag := "--until-state"
+	if waitUntilNotState != "" {
+		condition = waitCondition{state: waitUntilNotState, negate: true}
+		flag = "--until-not-state"
+	}
+	state, err := parseMonitorState(condition.state)
+	if err != nil {
+		return fmt.Errorf("%s: %v", flag, err)
+	}
+	condition.state = state
+	if waitPollInterval < time.Second {
+		return fmt.Errorf("--poll-interval must be at least 1s")
+	}
+
+	selector := monitorSelector{
+		Query:     waitQuery,
+		Service:   waitService,
+		Env:       waitEnv,
+		Namespace: waitNamespace,
+		Tags:      splitCommaList(waitFilterTags),
+	}
+	if len(waitMonitorIDs) > 0 && selector.hasFilters() {
+		return fmt.Errorf("--monitor-id cannot be combined with filter flags")
+	}
+	if len(waitMonitorIDs) == 0 && !selector.hasFilters() {
+		return fmt.Errorf("--monitor-id or a filter (--service, --env, --namespace, --filter-tags, --query) is required")
+	}
+	if err := selector.validate(); err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	// The first poll fixes the set of monitors to wait for
+	monitors, missing, err := pollWaitMonitors(client, selector, waitMonitorIDs)
+	if err != nil {
+		errOut.Printf("❌ Error listing monitors: %v\n", err)
+		return err
+	}
+	if len(monitors) == 0 && len(missing) == 0 {
+		out.Println("ℹ️  No monitors found")
+		return fmt.Errorf("no monitors match the filters")
+	}
+	waiting := make(map[int]datadog.Monitor, len(monitors))
+	for _, monitor := range monitors {
+		waiting[monitor.ID] = monitor
+	}
+	total := len(monitors) + len(missing)
+
+	out.Printf("\n⏳ Waiting for %d monitor(s) to be %s (polling every %s", total, condition, waitPollInterval)
+	if timeout > 0 {
+		out.Printf(", timeout %s", timeout)
+	}
+	out.Println(")")
+	reportMissing(missing)
+
+	for {
+		var ready []datadog.Monitor
+		var notReady []datadog.Monitor
+		for _, monitor := range waiting {
+			if condition.satisfied(monitor) {
+				ready = append(ready, monitor)
+			} else {
+				notReady = append(notReady, monitor)
+			}
+		}
+		sort.Slice(notReady, func(i, j int) bool { return notReady[i].ID < notReady[j].ID })
+		printWaitProgress(len(ready), len(waiting), notReady)
+
+		if len(notReady) == 0 {
+			if len(missing) > 0 {
+				out.Printf("\n⚠️  %d monitor(s) are %s, but %d were deleted while waiting\n", len(ready), condition, len(missing))
+				return &datadog.ErrMonitorNotFound{ID: missing[0].ID}
+			}
+			out.Printf("\n✅ All %d monitor(s) are %s\n", len(ready), condition)
+			if waitUnmute {
+				return unmuteReady(client, ready)
+			}
+			return nil
+		}
+
+		select {
+		case <-time.After(waitPollInterval):
+		case <-commandContext().Done():
+			return waitStopped(commandContext().Err(), notReady)
+		}
+
+		ids := make([]int, 0, len(waiting))
+		for id := range waiting {
+			ids = append(ids, id)
+		}
+		if len(waitMonitorIDs) == 0 {
+			// Monitors matching the filters later on are not waited for
+			ids = nil
+		}
+		polled, gone, err := pollWaitMonitors(client, selector, ids)
+		if err != nil {
+			if isInterrupted(err) {
+				return waitStopped(interruption(err), notReady)
+			}
+			// Keep waiting through transient API errors
+			errOut.Printf("⚠️  Poll failed, retrying in %s: %v\n", waitPollInterval, err)
+			continue
+		}
+
+		seen := make(map[int]bool, len(polled))
+		for _, monitor := range polled {
+			if _, ok := waiting[monitor.ID]; ok {
+				waiting[monitor.ID] = monitor
+				seen[monitor.ID] = true
+			}
+		}
+		if len(waitMonitorIDs) == 0 {
+			// A monitor no longer listed was deleted (or lost its tags)
+			for id, monitor := range waiting {
+				if !seen[id] {
+					gone = append(gone, monitor)
+				}
+			}
+		}
+		for _, monitor := range gone {
+			delete(waiting, monitor.ID)
+		}
+		reportMissing(gone)
+		missing = append(missing, gone...)
+	}
+}
+
+// pollWaitMonitors fetches the monitors with the given IDs, returning those
+// that no longer exist apart, or else the monitors matching the selector
+func pollWaitMonitors(client *datadog.Client, selector monitorSelector, ids []int) ([]datadog.Monitor, []datadog.Monitor, error) {
+	if len(ids) == 0 {
+		monitors, err := fetchMonitors(client, selector)
+		return monitors, nil, err
+	}
+
+	var monitors, missing []datadog.Monitor
+	for _, id := range ids {
+		monitor, err := client.GetMonitor(id)
+		if datadog.IsMonitorNotFound(err) {
+			missing = append(missing, datadog.Monitor{ID: id})
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		monitors = append(monitors, *monitor)
+	}
+	return monitors, missing, nil
+}
+
+// printWaitProgress prints the progress line of a poll
+func printWaitProgress(ready, total int, notReady []datadog.Monitor) {
+	line := fmt.Sprintf("⏳ [%s] %d/%d monitor(s) ready", time.Now().Format("15:04:05"), ready, total)
+	if len(notReady) > 0 {
+		var names []string
+		for i, monitor := range notReady {
+			if i == waitProgressSample {
+				names = append(names, fmt.Sprintf("%d more", len(notReady)-waitProgressSample))
+				break
+			}
+			names = append(names, fmt.Sprintf("ID %d %s", monitor.ID, monitor.OverallState))
+		}
+		line += fmt.Sprintf(" - waiting for %s", strings.Join(names, ", "))
+	}
+	out.Println(line)
+}
+
+// reportMissing warns about monitors deleted while waiting
+func reportMissing(missing []datadog.Monitor) {
+	for _, monitor := range missing {
+		errOut.Printf("🔍 Monitor %d no longer exists; no longer waiting for it\n", monitor.ID)
+	}
+}
+
+// waitStopped reports the monitors still not ready when the wait timed out or
+// was interrupted, and returns err
+func waitStopped(err error, notReady []datadog.Monitor) error {
+	reason := "🛑 Interrupted"
+	if errors.Is(err, context.DeadlineExceeded) {
+		reason = fmt.Sprintf("⏱️  Timed out (--timeout %s)", timeout)
+	}
+	out.Printf("\n%s with %d monitor(s) not ready:\n", reason, len(notReady))
+	for _, monitor := range notReady {
+		out.Printf("   ID %d: %s [%s]\n", monitor.ID, monitor.Name, monitor.OverallState)
+	}
+	return err
+}
+
+// unmuteReady lifts the whole-monitor mutes of the monitors a wait ended with
+// (--unmute); monitors that are not muted are left alone
+func unmuteReady(client *datadog.Client, monitors []datadog.Monitor) error {
+	sort.Slice(monitors, func(i, j int) bool { return monitors[i].ID < monitors[j].ID })
+	unmuted, failed := 0, 0
+	for _, monitor := range monitors {
+		if !datadog.IsScopeSilenced(monitor, "") {
+			continue
+		}
+		if _, err := client.UnmuteMonitor(monitor.ID, ""); err != nil {
+			errOut.Printf("❌ Error unmuting monitor %d: %v\n", monitor.ID, err)
+			failed++
+			continue
+		}
+		out.Printf("🔊 Unmuted ID %d: %s\n", monitor.ID, monitor.Name)
+		unmuted++
+	}
+	if unmuted == 0 && failed == 0 {
+		out.Println("⏭️  No monitor was muted (no-op)")
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to unmute %d monitor(s)", failed)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadogtest"
+)
+
+// setStateOnPoll sets the state of a monitor when it is fetched for the n-th
+// time, i.e. on the (n-1)-th poll after the first
+func setStateOnPoll(srv *datadogtest.Server, id, n int, state string) {
+	var mu sync.Mutex
+	seen := 0
+	path := "/api/v1/monitor/" + strconv.Itoa(id)
+	srv.Received = func(r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != path {
+			return
+		}
+		mu.Lock()
+		seen++
+		set := seen == n
+		mu.Unlock()
+		if set {
+			srv.SetMonitorState(id, state)
+		}
+	}
+}
+
+// addWaitMonitor adds a monitor in state, muted as a whole when muted is set
+func addWaitMonitor(srv *datadogtest.Server, name, state string, muted bool) datadog.Monitor {
+	monitor := datadog.Monitor{Name: name, Type: "metric alert", Query: "avg(last_5m):avg:cpu{service:checkout} > 90", Tags: []string{"service:checkout"}, OverallState: state}
+	if muted {
+		monitor.Options = map[string]interface{}{"silenced": map[string]interface{}{"*": nil}}
+	}
+	return srv.AddMonitor(monitor)
+}
+
+func TestWaitReachesState(t *testing.T) {
+	srv := newTestServer(t)
+	addWaitMonitor(srv, "Checkout CPU", "No Data", false)
+	addWaitMonitor(srv, "Checkout latency", "OK", false)
+	setStateOnPoll(srv, 1000, 2, "OK")
+
+	res := runCLI(t, nil, "wait", "--monitor-id", "1000,1001", "--until-not-state", "No Data", "--poll-interval", "1s")
+	if res.Err != nil {
+		t.Fatalf("wait: %v\n%s", res.Err, res.Stderr)
+	}
+	// One poll after the first
+	srv.AssertRequestCount(t, 2, "GET", "/monitor/1000")
+	for _, want := range []string{
+		"Waiting for 2 monitor(s) to be out of No Data (polling every 1s)",
+		"1/2 monitor(s) ready - waiting for ID 1000 No Data",
+		"2/2 monitor(s) ready",
+		"All 2 monitor(s) are out of No Data",
+	} {
+		if !strings.Contains(res.Stdout, want) {
+			t.Errorf("stdout has no %q:\n%s", want, res.Stdout)
+		}
+	}
+	srv.AssertNoMutations(t)
+}
+
+func TestWaitTimeout(t *testing.T) {
+	srv := newTestServer(t)
+	addWaitMonitor(srv, "Checkout CPU", "Alert", true)
+
+	res := runCLI(t, nil, "wait", "--monitor-id", "1000", "--until-state", "OK", "--poll-interval", "1s", "--timeout", "1500ms", "--unmute")
+	if code := ExitCode(res.Err); code != ExitTimeout {
+		t.Errorf("exit code %d (%v), want %d", code, res.Err, ExitTimeout)
+	}
+	for _, want := range []string{"Timed out (--timeout 1.5s) with 1 monitor(s) not ready", "ID 1000: Checkout CPU [Alert]"} {
+		if !strings.Contains(res.Stdout, want) {
+			t.Errorf("stdout has no %q:\n%s", want, res.Stdout)
+		}
+	}
+	// Nothing is unmuted when the condition was never met
+	srv.AssertNoMutations(t)
+	if monitor, _ := srv.Monitor(1000); !datadog.IsScopeSilenced(monitor, "") {
+		t.Errorf("monitor unmuted after the timeout: %v", monitor.Options)
+	}
+}
+
+func TestWaitUnmute(t *testing.T) {
+	srv := newTestServer(t)
+	addWaitMonitor(srv, "Checkout CPU", "No Data", true)
+	addWaitMonitor(srv, "Checkout latency", "OK", false)
+	setStateOnPoll(srv, 1000, 2, "OK")
+
+	res := runCLI(t, nil, "wait", "--monitor-id", "1000,1001", "--until-state", "OK", "--poll-interval", "1s", "--unmute")
+	if res.Err != nil {
+		t.Fatalf("wait --unmute: %v\n%s", res.Err, res.Stderr)
+	}
+	// Only the monitor muted as a whole is unmuted
+	srv.AssertRequestCount(t, 1, "POST", "/monitor/1000/unmute")
+	srv.AssertRequestCount(t, 0, "POST", "/monitor/1001/unmute")
+	if monitor, _ := srv.Monitor(1000); datadog.IsScopeSilenced(monitor, "") {
+		t.Errorf("monitor still muted: %v", monitor.Options)
+	}
+	if !strings.Contains(res.Stdout, "Unmuted ID 1000: Checkout CPU") || strings.Contains(res.Stdout, "Unmuted ID 1001") {
+		t.Errorf("stdout:\n%s", res.Stdout)
+	}
+
+	// Without muted monitors --unmute is a no-op
+	srv.Received = nil
+	srv.ResetRequests()
+	res = runCLI(t, nil, "wait", "--monitor-id", "1000,1001", "--until-state", "OK", "--poll-interval", "1s", "--unmute")
+	if res.Err != nil {
+		t.Fatalf("wait --unmute: %v\n%s", res.Err, res.Stderr)
+	}
+	srv.AssertNoMutations(t)
+	if !strings.Contains(res.Stdout, "No monitor was muted (no-op)") {
+		t.Errorf("stdout:\n%s", res.Stdout)
+	}
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show the Datadog organization of the API keys",
+	Long: `Show the Datadog organization DD_API_KEY and DD_APP_KEY belong to, and
+whether it is the one --expect-org (or expect_org in the config file) pins.
+
+With an expected org, every command looks the org up once before its first
+change and refuses to change anything in another org; read-only commands and
+dry runs don't look it up. The expected org is a name (case-insensitive) or a
+public ID, both printed here. --no-org-check skips the check.
+
+--verbose also shows which environment variables the keys and the site came
+from. Keys are read from DD_API_KEY_FILE/DD_APP_KEY_FILE, else DD_API_KEY/
+DD_APP_KEY, else DATADOG_API_KEY/DATADOG_APP_KEY; the API URL from
+DDMM_API_URL, else DD_SITE, else DATADOG_HOST, else DATADOG_SITE, else
+datadoghq.com. Variables ignored because a higher one is set to another value
+are warned about by every command.
+
+Examples:
+  whoami
+  whoami --verbose
+  whoami --expect-org "Acme Production"`,
+	RunE: runWhoami,
+}
+
+var (
+	// expectOrg is --expect-org: the org mutating requests may be sent to
+	expectOrg string
+	// noOrgCheck is --no-org-check: ignore --expect-org and expect_org
+	noOrgCheck bool
+
+	// runOrgCheck is the org check shared by the clients of the invocation
+	runOrgCheck *datadog.OrgCheck
+)
+
+func init() {
+	rootCmd.AddCommand(whoamiCmd)
+}
+
+// expectedOrg returns the org to pin: --expect-org, else expect_org in the
+// config file; empty with --no-org-check
+func expectedOrg() (string, error) {
+	if noOrgCheck {
+		return "", nil
+	}
+	if expectOrg != "" {
+		return expectOrg, nil
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return "", err
+	}
+	return cfg.ExpectOrg, nil
+}
+
+// orgCheck returns the org check of the invocation, nil when no org is
+// expected. It is created once so the org is looked up at most once.
+func orgCheck() (*datadog.OrgCheck, error) {
+	if runOrgCheck != nil {
+		return runOrgCheck, nil
+	}
+	expected, err := expectedOrg()
+	if err != nil || expected == "" {
+		return nil, err
+	}
+	runOrgCheck = &datadog.OrgCheck{Expected: expected}
+	return runOrgCheck, nil
+}
+
+func runWhoami(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+
+	org, err := client.GetOrg()
+	if err != nil {
+		errOut.Printf("❌ Error: %v\n", err)
+		return err
+	}
+	out.Printf("🏢 Organization: %s\n", org.Name)
+	out.Printf("🆔 Public ID: %s\n", org.PublicID)
+	out.Printf("🌐 App: %s\n", client.AppURL())
+	if verbose {
+		if err := printEnvSources(); err != nil {
+			return err
+		}
+	}
+
+	expected, err := expectedOrg()
+	if err != nil {
+		return err
+	}
+	switch {
+	case expected == "":
+		out.Printf("💡 Pin it with --expect-org %q or expect_org in the config file\n", org.Name)
+	case org.Matches(expected):
+		out.Printf("✅ Matches the expected org %q\n", expected)
+	default:
+		mismatch := &datadog.OrgCheckError{Expected: expected, Org: org}
+		errOut.Printf("❌ Expected org %q: changes would be refused\n", expected)
+		return mismatch
+	}
+	return nil
+}
+
+// printEnvSources prints the environment variables the keys and the API URL
+// were resolved from (whoami --verbose)
+func printEnvSources() error {
+	env, err := datadog.ResolveProcessEnv()
+	if err != nil {
+		return err
+	}
+	out.Println("\n🔑 Resolved from the environment:")
+	printEnvKeySource("API key", env.APIKey, env.APIKeyFile)
+	printEnvKeySource("App key", env.AppKey, env.AppKeyFile)
+	switch {
+	case env.APIURL.Source == "":
+		out.Printf("   API URL: %s (default)\n", env.APIURL.Value)
+	case env.APIURL.Raw != env.APIURL.Value:
+		out.Printf("   API URL: %s (from %s=%s)\n", env.APIURL.Value, env.APIURL.Source, env.APIURL.Raw)
+	default:
+		out.Printf("   API URL: %s (from %s)\n", env.APIURL.Value, env.APIURL.Source)
+	}
+	printEnvIgnored(env.APIURL)
+	return nil
+}
+
+// printEnvKeySource prints where a key came from: its file, else its variable
+func printEnvKeySource(label string, key, file datadog.EnvSetting) {
+	switch {
+	case file.Source != "":
+		out.Printf("   %s: file %s (from %s)\n", label, file.Value, file.Source)
+		if key.Source != "" {
+			out.Printf("      %s is ignored while %s is set\n", key.Source, file.Source)
+		}
+	case key.Source != "":
+		out.Printf("   %s: %s (from %s)\n", label, maskKey(key.Value), key.Source)
+	}
+	printEnvIgnored(key)
+}
+
+// printEnvIgnored lists the variables of a setting ignored for a conflicting value
+func printEnvIgnored(setting datadog.EnvSetting) {
+	for _, name := range setting.Conflicts {
+		out.Printf("      ⚠️  %s is ignored (%s takes precedence and differs)\n", name, setting.Source)
+	}
+}
+
+// maskKey shows the last 4 characters of a key
+func maskKey(key string) string {
+	if len(key) <= 4 {
+		return strings.Repeat("*", len(key))
+	}
+	return strings.Repeat("*", 4) + key[len(key)-4:]
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadogtest"
+)
+
+func TestWhoamiVerboseEnvSources(t *testing.T) {
+	newTestServer(t)
+	t.Setenv("DATADOG_API_KEY", "another-api-key")
+	t.Setenv("DATADOG_APP_KEY", datadogtest.AppKey)
+	t.Setenv("DATADOG_SITE", "datadoghq.eu")
+
+	res := runCLI(t, nil, "whoami", "--verbose")
+	if res.Err != nil {
+		t.Fatalf("whoami: %v\n%s", res.Err, res.Stderr)
+	}
+	for _, want := range []string{"(from DD_API_KEY)", "(from DD_APP_KEY)", "(from DDMM_API_URL"} {
+		if !strings.Contains(res.Stdout, want) {
+			t.Errorf("whoami --verbose output lacks %q:\n%s", want, res.Stdout)
+		}
+	}
+	// Only the variables set to another value are reported, once
+	if got := strings.Count(res.Stderr, "DATADOG_API_KEY is set to a different value than DD_API_KEY"); got != 1 {
+		t.Errorf("API key conflict reported %d times:\n%s", got, res.Stderr)
+	}
+	if !strings.Contains(res.Stderr, "DATADOG_SITE is set to a different value than DDMM_API_URL") {
+		t.Errorf("site conflict not reported:\n%s", res.Stderr)
+	}
+	if strings.Contains(res.Stderr, "DATADOG_APP_KEY") {
+		t.Errorf("same-value DATADOG_APP_KEY reported as a conflict:\n%s", res.Stderr)
+	}
+}
@@ -1,10 +1,11 @@
 module github.com/tbernacchi/datadog-monitor-manager
 
-go 1.21
-
-require github.com/spf13/cobra v1.8.0
+go 1.24
 
 require (
-	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/pflag v1.0.5 // indirect
+	github.com/spf13/cobra v1.8.0
+	github.com/spf13/pflag v1.0.5
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require github.com/inconshreveable/mousetrap v1.1.0 // indirect
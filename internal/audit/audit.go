@@ -0,0 +1,174 @@
+// Package audit writes and reads the local audit trail of mutating API calls,
+// an append-only JSON lines file.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/paths"
+)
+
+// PathEnv is the environment variable that enables the audit log
+const PathEnv = "DDMM_AUDIT_LOG"
+
+// Outcomes of an audited request
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailed  = "failed" // the API answered with an error status
+	OutcomeError   = "error"  // no response (network error, cancelled)
+)
+
+// Entry is one mutating API request
+type Entry struct {
+	Time     time.Time `json:"time"`
+	User     string    `json:"user"`
+	Command  string    `json:"command"`
+	Method   string    `json:"method"`
+	Endpoint string    `json:"endpoint"`
+	// Resource is the kind of object changed: monitor, slo or downtime
+	Resource string          `json:"resource,omitempty"`
+	ID       string          `json:"id,omitempty"`
+	Name     string          `json:"name,omitempty"`
+	Previous json.RawMessage `json:"previous,omitempty"`
+	Status   int             `json:"status,omitempty"`
+	Outcome  string          `json:"outcome"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// Log appends entries to an audit log file. Writing is best-effort: failures
+// are passed to OnError and never fail the audited request. It is safe for
+// concurrent use.
+type Log struct {
+	Path    string
+	User    string
+	Command string
+	// OnError is called when an entry can't be written
+	OnError func(error)
+
+	mu sync.Mutex
+}
+
+// NewLog returns a log writing to path on behalf of the current user, for the
+// given command line
+func NewLog(path, command string) *Log {
+	return &Log{Path: path, User: CurrentUser(), Command: command}
+}
+
+// CurrentUser returns the name of the user running the tool
+func CurrentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return "unknown"
+}
+
+// Append writes an entry, filling in the time, user and command
+func (l *Log) Append(entry Entry) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+	entry.User = l.User
+	entry.Command = l.Command
+
+	data, err := json.Marshal(entry)
+	if err == nil {
+		err = l.write(append(data, '\n'))
+	}
+	if err != nil && l.OnError != nil {
+		l.OnError(fmt.Errorf("failed to write audit log %s: %v", l.Path, err))
+	}
+}
+
+// write appends one line to the file, creating it owner-readable only
+func (l *Log) write(line []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := paths.EnsureParent(l.Path); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(l.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Read returns the last entries of an audit log, oldest first; last <= 0
+// returns every entry. Lines that aren't valid entries are skipped.
+func Read(path string, last int) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil {
+			continue
+		}
+		entries = append(entries, entry)
+		if last > 0 && len(entries) > 2*last {
+			entries = append([]Entry(nil), entries[len(entries)-last:]...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if last > 0 && len(entries) > last {
+		entries = entries[len(entries)-last:]
+	}
+	return entries, nil
+}
+
+// secretFlag matches the names of flags whose values must not be logged
+var secretFlag = regexp.MustCompile(`(?i)(key|token|secret|password)`)
+
+// RedactArgs joins command line arguments, replacing the values of flags
+// whose names look secret (--api-key x, --token=x) with "***"
+func RedactArgs(args []string) string {
+	redacted := make([]string, len(args))
+	redactNext := false
+	for i, arg := range args {
+		redacted[i] = arg
+		if redactNext && !strings.HasPrefix(arg, "-") {
+			redacted[i] = "***"
+			redactNext = false
+			continue
+		}
+		redactNext = false
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		flag, _, hasValue := strings.Cut(arg, "=")
+		if !secretFlag.MatchString(strings.TrimLeft(flag, "-")) {
+			continue
+		}
+		if hasValue {
+			redacted[i] = flag + "=***"
+		} else {
+			redactNext = true
+		}
+	}
+	return strings.Join(redacted, " ")
+}
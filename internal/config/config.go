@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/paths"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the user configuration loaded from the config file
+type Config struct {
+	// Environments lists the valid environment names; empty means any environment is accepted
+	Environments []string `yaml:"environments,omitempty"`
+	// EnvAliases maps alternative environment names to canonical ones (e.g., production: prd)
+	EnvAliases map[string]string `yaml:"env_aliases,omitempty"`
+	// TeamTagKey is the tag key monitors are owned by (default: team)
+	TeamTagKey string `yaml:"team_tag_key,omitempty"`
+	// APIV2 enables the commands backed by the Datadog API v2 (also DDMM_API_V2=1)
+	APIV2 bool `yaml:"api_v2,omitempty"`
+	// AuditLog is the file mutating API calls are recorded in (also DDMM_AUDIT_LOG);
+	// empty disables the audit log
+	AuditLog string `yaml:"audit_log,omitempty"`
+	// K8sDefaults overrides the delays --k8s-defaults injects
+	K8sDefaults K8sDefaults `yaml:"k8s_defaults,omitempty"`
+	// ThresholdScale multiplies the thresholds of monitors rendered for an
+	// environment (e.g., dev: 2.0); --threshold-scale overrides it per env
+	ThresholdScale map[string]float64 `yaml:"threshold_scale,omitempty"`
+	// ExpectOrg is the Datadog org, by name or public ID, changes may be made
+	// in; --expect-org overrides it
+	ExpectOrg string `yaml:"expect_org,omitempty"`
+	// ProtectedTagKey is the tag key that, set to true, keeps monitors out of
+	// bulk changes (default: protected)
+	ProtectedTagKey string `yaml:"protected_tag_key,omitempty"`
+	// Presets are option presets --preset applies besides the built-in ones,
+	// by name; a preset named like a built-in one replaces it
+	Presets map[string]Preset `yaml:"presets,omitempty"`
+	// SingleValuedTagKeys are the tag keys a --tag value replaces the
+	// template's tags of instead of being added next to them; unset means
+	// team, env, service, namespace and priority, [] always adds
+	SingleValuedTagKeys []string `yaml:"single_valued_tag_keys,omitempty"`
+}
+
+// Preset is a named bundle of monitor options
+type Preset struct {
+	Description string                 `yaml:"description,omitempty"`
+	Options     map[string]interface{} `yaml:"options"`
+}
+
+// K8sDefaults are the evaluation_delay and new_group_delay, in seconds,
+// --k8s-defaults injects into monitors on Kubernetes metrics (0: the built-in 300)
+type K8sDefaults struct {
+	EvaluationDelay int `yaml:"evaluation_delay,omitempty"`
+	NewGroupDelay   int `yaml:"new_group_delay,omitempty"`
+}
+
+// DefaultPath returns the config file path: DDMM_CONFIG, else config.yaml in
+// the config directory (paths.ConfigDir). The legacy ~/.ddmm.yaml is used
+// while it exists, unless DDMM_CONFIG_DIR is set. Empty when no config
+// directory can be resolved.
+func DefaultPath() string {
+	if path := os.Getenv("DDMM_CONFIG"); path != "" {
+		return path
+	}
+	if os.Getenv(paths.ConfigDirEnv) == "" {
+		if home, err := paths.Home(); err == nil {
+			legacy := filepath.Join(home, ".ddmm.yaml")
+			if _, err := os.Stat(legacy); err == nil {
+				return legacy
+			}
+		}
+	}
+	dir, err := paths.ConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "config.yaml")
+}
+
+// Load reads the config file at path. A missing file is not an error and
+// yields an empty config.
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read config file %s: %v", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("invalid YAML in config file %s: %v", path, err)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,67 @@
+package datadog
+
+import "fmt"
+
+// AdoptError is returned for a template whose adopt_monitor_id can't be
+// adopted: the monitor doesn't exist, or another monitor has the name the
+// template renders
+type AdoptError struct {
+	ID     int
+	Reason string
+}
+
+func (e *AdoptError) Error() string {
+	return fmt.Sprintf("cannot adopt monitor %d (adopt_monitor_id): %s", e.ID, e.Reason)
+}
+
+// adoptedStatus returns the status of updating target from a template with
+// adopt_monitor_id, and whether the key is redundant because target already
+// carries the template's identity (an earlier run adopted it)
+func adoptedStatus(monitor, target Monitor) (ResultStatus, bool) {
+	if matchIdentity(monitor, []Monitor{target}) != nil {
+		return StatusUpdated, true
+	}
+	return StatusAdopted, false
+}
+
+// checkAdoption checks that the monitor with the given ID exists among
+// monitors and can take the rendered monitor's name, which no other monitor
+// may have. It returns the monitor to adopt.
+func checkAdoption(monitor Monitor, id int, monitors []Monitor) (Monitor, error) {
+	var target *Monitor
+	for i := range monitors {
+		switch {
+		case monitors[i].ID == id:
+			target = &monitors[i]
+		case monitors[i].Name == monitor.Name:
+			return Monitor{}, &AdoptError{ID: id, Reason: fmt.Sprintf("monitor %d already has the name %q", monitors[i].ID, monitor.Name)}
+		}
+	}
+	if target == nil {
+		return Monitor{}, &AdoptError{ID: id, Reason: "monitor not found"}
+	}
+	return *target, nil
+}
+
+// adoptMonitor updates the monitor with the given ID from a rendered monitor,
+// whatever its name or tags, after checking it exists and that no other
+// monitor has the rendered name (see checkAdoption). The status is
+// StatusAdopted, or StatusUpdated when redundant reports the monitor already
+// carries the template's identity.
+func (c *Client) adoptMonitor(id int, monitor *Monitor) (updated *Monitor, status ResultStatus, redundant bool, err error) {
+	monitors, err := c.ListMonitors(nil, "")
+	if err != nil {
+		return nil, StatusFailed, false, err
+	}
+	target, err := checkAdoption(*monitor, id, monitors)
+	if err != nil {
+		return nil, StatusFailed, false, err
+	}
+
+	status, redundant = adoptedStatus(*monitor, target)
+	updated, err = c.UpdateMonitor(id, monitor)
+	if err != nil {
+		return nil, StatusFailed, false, err
+	}
+	return updated, status, redundant, nil
+}
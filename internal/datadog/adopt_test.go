@@ -0,0 +1,73 @@
+package datadog
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTemplateFile writes a template file in a temporary directory
+func writeTemplateFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "monitors.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAdoptMonitorIDRoundTrip(t *testing.T) {
+	for _, id := range []int{12345, 1<<53 + 1, 9007199254740993123} {
+		template := TemplateData{Name: "CPU", Config: map[string]interface{}{"name": "CPU"}, AdoptMonitorID: id}
+		data, err := json.Marshal(template)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded TemplateData
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.AdoptMonitorID != id {
+			t.Errorf("adopt_monitor_id %d decoded as %d", id, decoded.AdoptMonitorID)
+		}
+	}
+
+	// Without adoption the key is left out
+	data, _ := json.Marshal(TemplateData{Name: "CPU"})
+	if strings.Contains(string(data), "adopt_monitor_id") {
+		t.Errorf("adopt_monitor_id encoded without adoption: %s", data)
+	}
+}
+
+func TestLoadTemplatesAdoptMonitorID(t *testing.T) {
+	const query = `"type": "metric alert", "query": "avg(last_5m):avg:cpu{service:{service}} > 90", "message": "CPU"`
+	for _, tc := range []struct {
+		name, file string
+		want       int
+	}{
+		{"single template", `{"name": "CPU", ` + query + `, "adopt_monitor_id": 12345}`, 12345},
+		{"single template above 2^53", `{"name": "CPU", ` + query + `, "adopt_monitor_id": 9007199254740993}`, 9007199254740993},
+		{"templates array", `{"templates": [{"name": "CPU", "adopt_monitor_id": 12345, "config": {"name": "CPU", ` + query + `}}]}`, 12345},
+		{"templates array above 2^53", `{"templates": [{"name": "CPU", "adopt_monitor_id": 9007199254740993, "config": {"name": "CPU", ` + query + `}}]}`, 9007199254740993},
+	} {
+		templates, err := LoadTemplates(writeTemplateFile(t, tc.file), false)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if len(templates) != 1 || templates[0].AdoptMonitorID != tc.want {
+			t.Errorf("%s: templates %+v, want adopt_monitor_id %d", tc.name, templates, tc.want)
+			continue
+		}
+		// The key isn't part of the monitor definition
+		if _, ok := templates[0].Config["adopt_monitor_id"]; ok {
+			t.Errorf("%s: adopt_monitor_id left in the monitor definition", tc.name)
+		}
+	}
+
+	if _, err := LoadTemplates(writeTemplateFile(t, `{"name": "CPU", `+query+`, "adopt_monitor_id": 1.5}`), false); err == nil {
+		t.Error("fractional adopt_monitor_id accepted")
+	}
+}
@@ -0,0 +1,276 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// APM block kinds
+const (
+	APMErrorRate  = "error_rate"
+	APMLatency    = "latency"
+	APMThroughput = "throughput"
+)
+
+// APMPercentiles are the latency percentiles of trace distributions
+var APMPercentiles = []string{"p50", "p75", "p90", "p95", "p99"}
+
+// APMBlock is the structured "apm" block of an APM template, compiled into
+// the trace metric query of a query alert and its critical/warning
+// thresholds:
+//
+//	error_rate: avg(last_5m):sum:trace.<operation>.errors{scope}.as_rate() / sum:trace.<operation>.hits{scope}.as_rate() > critical
+//	latency:    percentile(last_5m):<percentile>:trace.<operation>{scope} > critical (seconds)
+//	throughput: avg(last_5m):sum:trace.<operation>.hits{scope}.as_rate() < critical (requests per second)
+type APMBlock struct {
+	Kind string `json:"kind"`
+	// Operation is the traced operation the trace metrics are named after
+	// (default http.request)
+	Operation string `json:"operation,omitempty"`
+	// Service and Env scope the query (default {service} and {env})
+	Service string `json:"service,omitempty"`
+	Env     string `json:"env,omitempty"`
+	// Scope are more tags the query is scoped to, e.g. resource_name:get_/orders
+	Scope   []string `json:"scope,omitempty"`
+	GroupBy []string `json:"group_by,omitempty"`
+	// Percentile is the latency percentile (latency only): p50, p75, p90, p95 or p99
+	Percentile string            `json:"percentile,omitempty"`
+	Threshold  APMBlockThreshold `json:"threshold"`
+}
+
+// APMBlockThreshold is the alert condition of an APM block
+type APMBlockThreshold struct {
+	Critical float64  `json:"critical"`
+	Warning  *float64 `json:"warning,omitempty"`
+	// Window is the evaluation window, e.g. 5m (default 5m)
+	Window string `json:"window,omitempty"`
+	// Comparator defaults to < for throughput (alert on a drop) and > otherwise
+	Comparator string `json:"comparator,omitempty"`
+}
+
+// apmDefaultOperation is the operation of an APM block without one
+const apmDefaultOperation = "http.request"
+
+// apmScope returns the {...} scope of an APM block's metrics
+func apmScope(block APMBlock) string {
+	service, env := block.Service, block.Env
+	if service == "" {
+		service = "{service}"
+	}
+	if env == "" {
+		env = "{env}"
+	}
+	scope := append([]string{"service:" + service, "env:" + env}, block.Scope...)
+	return "{" + strings.Join(scope, ",") + "}"
+}
+
+// apmMetric returns a trace metric of an APM block with its scope and
+// group-by, e.g. trace.http.request.hits{service:x,env:y} by {resource_name}
+func apmMetric(block APMBlock, suffix string) string {
+	operation := block.Operation
+	if operation == "" {
+		operation = apmDefaultOperation
+	}
+	metric := "trace." + operation + suffix + apmScope(block)
+	if len(block.GroupBy) > 0 {
+		metric += " by {" + strings.Join(block.GroupBy, ",") + "}"
+	}
+	return metric
+}
+
+// apmComparator returns the comparator of an APM block
+func apmComparator(block APMBlock) string {
+	switch {
+	case block.Threshold.Comparator != "":
+		return block.Threshold.Comparator
+	case block.Kind == APMThroughput:
+		return "<"
+	default:
+		return ">"
+	}
+}
+
+// CompileAPMQuery builds the query of an APM block. The block must be valid
+// (see LintAPMBlock).
+func CompileAPMQuery(block APMBlock) string {
+	window := block.Threshold.Window
+	if window == "" {
+		window = "5m"
+	}
+	var query string
+	switch block.Kind {
+	case APMErrorRate:
+		query = fmt.Sprintf("avg(last_%s):sum:%s.as_rate() / sum:%s.as_rate()", window, apmMetric(block, ".errors"), apmMetric(block, ".hits"))
+	case APMLatency:
+		query = fmt.Sprintf("percentile(last_%s):%s:%s", window, block.Percentile, apmMetric(block, ""))
+	case APMThroughput:
+		query = fmt.Sprintf("avg(last_%s):sum:%s.as_rate()", window, apmMetric(block, ".hits"))
+	}
+	return fmt.Sprintf("%s %s %s", query, apmComparator(block), formatThreshold(block.Threshold.Critical))
+}
+
+// LintAPMBlock returns the problems of an APM block beyond the schema, by the
+// name of the property they concern: the percentile is set for latency (and
+// only then), an error rate is a fraction, and the warning threshold is on
+// the less severe side of the critical one
+func LintAPMBlock(block APMBlock) map[string][]string {
+	problems := make(map[string][]string)
+	add := func(key, problem string) {
+		problems[key] = append(problems[key], problem)
+	}
+	switch block.Kind {
+	case APMErrorRate, APMLatency, APMThroughput:
+	default:
+		add("kind", fmt.Sprintf("must be %s, %s or %s", APMErrorRate, APMLatency, APMThroughput))
+	}
+
+	switch {
+	case block.Kind == APMLatency && block.Percentile == "":
+		add("percentile", fmt.Sprintf("latency needs a percentile (%s)", strings.Join(APMPercentiles, ", ")))
+	case block.Kind == APMLatency && !containsString(APMPercentiles, block.Percentile):
+		add("percentile", fmt.Sprintf("%q is not a trace percentile (%s)", block.Percentile, strings.Join(APMPercentiles, ", ")))
+	case block.Kind != APMLatency && block.Percentile != "":
+		add("percentile", "only latency takes a percentile")
+	}
+
+	if strings.ContainsAny(block.Operation, "{} ") {
+		add("operation", "must be an operation name such as http.request, not a metric or scope")
+	}
+	if block.Threshold.Window != "" && !logWindowPattern.MatchString(block.Threshold.Window) {
+		add("threshold/window", "must be a duration like 5m, 1h or 1d")
+	}
+	critical := block.Threshold.Critical
+	if block.Kind == APMErrorRate && (critical <= 0 || critical > 1) {
+		add("threshold/critical", fmt.Sprintf("an error rate is a fraction of the requests: %s is not between 0 and 1 (write 5%% as 0.05)", formatThreshold(critical)))
+	}
+	if warning := block.Threshold.Warning; warning != nil && !lessSevere(*warning, critical, strings.HasPrefix(apmComparator(block), ">")) {
+		add("threshold/warning", fmt.Sprintf("%s must be on the less severe side of critical %s", formatThreshold(*warning), formatThreshold(critical)))
+	}
+	for _, list := range []struct {
+		name   string
+		values []string
+	}{{"scope", block.Scope}, {"group_by", block.GroupBy}} {
+		for i, value := range list.values {
+			if strings.TrimSpace(value) == "" {
+				add(fmt.Sprintf("%s/%d", list.name, i), "is empty")
+			}
+		}
+	}
+	return problems
+}
+
+// traceCountTermPattern matches a trace count metric of a query (hits and
+// errors are counts) with the functions applied to it
+var traceCountTermPattern = regexp.MustCompile(`\btrace\.[\w.]+?\.(?:hits|errors)(?:\.by_http_status)?\{[^}]*\}(?:\s*by\s*\{[^}]*\})?((?:\.\w+\(\))*)`)
+
+// scopeBracesPattern matches the {...} of a query, whose tag values may hold
+// a slash
+var scopeBracesPattern = regexp.MustCompile(`\{[^}]*\}`)
+
+// LintAPMQuery returns the problem of a hand-written query dividing trace
+// counts (trace.*.hits, trace.*.errors) that aren't all turned into rates
+// with .as_rate(): a ratio of raw counts depends on the rollup interval and
+// the evaluation aggregation, the usual way an error rate goes wrong
+func LintAPMQuery(query string) string {
+	if !strings.Contains(scopeBracesPattern.ReplaceAllString(query, "{}"), "/") {
+		return ""
+	}
+	terms := traceCountTermPattern.FindAllStringSubmatch(query, -1)
+	if len(terms) == 0 {
+		return ""
+	}
+	for _, term := range terms {
+		if !strings.Contains(term[1], ".as_rate()") {
+			return fmt.Sprintf("divides trace counts without .as_rate() (%s): the ratio then depends on the rollup interval; apply .as_rate() to each count, or use an \"apm\" block", strings.TrimSuffix(term[0], term[1]))
+		}
+	}
+	return ""
+}
+
+// lintAPMTemplate checks the APM block of a template, or the query of a
+// query alert written by hand: the block's template is a query alert without
+// its own query or another block
+func lintAPMTemplate(config map[string]interface{}, pointer string) []SchemaViolation {
+	monitorType, _ := config["type"].(string)
+	raw, hasBlock := config["apm"]
+	if !hasBlock {
+		query, _ := config["query"].(string)
+		if problem := LintAPMQuery(query); problem != "" {
+			return []SchemaViolation{{Pointer: pointer + "/query", Message: problem}}
+		}
+		return nil
+	}
+
+	block, err := decodeAPMBlock(raw)
+	if err != nil {
+		// Type problems are reported by the schema
+		return nil
+	}
+	var violations []SchemaViolation
+	blockPointer := pointer + "/apm"
+	if _, hasQuery := config["query"]; hasQuery {
+		violations = append(violations, SchemaViolation{Pointer: pointer + "/query", Message: `set either "query" or an "apm" block, not both`})
+	}
+	for _, other := range []string{"log", "service_check"} {
+		if _, ok := config[other]; ok {
+			violations = append(violations, SchemaViolation{Pointer: blockPointer, Message: fmt.Sprintf(`set either a %q or an "apm" block, not both`, other)})
+		}
+	}
+	if monitorType != "query alert" && monitorType != "metric alert" {
+		violations = append(violations, SchemaViolation{Pointer: pointer + "/type", Message: `an "apm" block needs type "query alert"`, Value: config["type"]})
+	}
+	return append(violations, problemViolations(blockPointer, LintAPMBlock(block))...)
+}
+
+// decodeAPMBlock converts the "apm" value of a template into an APMBlock
+func decodeAPMBlock(raw interface{}) (APMBlock, error) {
+	var block APMBlock
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return block, err
+	}
+	err = json.Unmarshal(data, &block)
+	return block, err
+}
+
+// compileAPMBlocks replaces the "apm" block of each template with the
+// compiled query, and sets the critical/warning thresholds unless the
+// template sets them
+func compileAPMBlocks(templateFile string, templates []TemplateData) error {
+	for _, template := range templates {
+		raw, ok := template.Config["apm"]
+		if !ok {
+			continue
+		}
+		block, err := decodeAPMBlock(raw)
+		if err != nil {
+			return fmt.Errorf("invalid apm block in template %s (%s): %v", template.Name, templateFile, err)
+		}
+		if problems := LintAPMBlock(block); len(problems) > 0 {
+			violations := problemViolations("apm", problems)
+			return fmt.Errorf("invalid apm block in template %s (%s): %s: %s", template.Name, templateFile, violations[0].Pointer, violations[0].Message)
+		}
+		delete(template.Config, "apm")
+		template.Config["query"] = CompileAPMQuery(block)
+
+		options, _ := template.Config["options"].(map[string]interface{})
+		if options == nil {
+			options = make(map[string]interface{})
+			template.Config["options"] = options
+		}
+		thresholds, _ := options["thresholds"].(map[string]interface{})
+		if thresholds == nil {
+			thresholds = make(map[string]interface{})
+			options["thresholds"] = thresholds
+		}
+		if _, ok := thresholds["critical"]; !ok {
+			thresholds["critical"] = block.Threshold.Critical
+		}
+		if _, ok := thresholds["warning"]; !ok && block.Threshold.Warning != nil {
+			thresholds["warning"] = *block.Threshold.Warning
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package datadog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompileAPMQuery(t *testing.T) {
+	warning := 0.02
+	for _, tc := range []struct {
+		name  string
+		block APMBlock
+		want  string
+	}{
+		{"error rate", APMBlock{Kind: APMErrorRate, Threshold: APMBlockThreshold{Critical: 0.05}},
+			"avg(last_5m):sum:trace.http.request.errors{service:{service},env:{env}}.as_rate() / sum:trace.http.request.hits{service:{service},env:{env}}.as_rate() > 0.05"},
+		{"error rate by resource", APMBlock{Kind: APMErrorRate, Operation: "grpc.server", Scope: []string{"resource_name:get_/orders"}, GroupBy: []string{"resource_name"},
+			Threshold: APMBlockThreshold{Critical: 0.1, Warning: &warning, Window: "15m"}},
+			"avg(last_15m):sum:trace.grpc.server.errors{service:{service},env:{env},resource_name:get_/orders} by {resource_name}.as_rate() / sum:trace.grpc.server.hits{service:{service},env:{env},resource_name:get_/orders} by {resource_name}.as_rate() > 0.1"},
+		{"latency", APMBlock{Kind: APMLatency, Percentile: "p99", Threshold: APMBlockThreshold{Critical: 2}},
+			"percentile(last_5m):p99:trace.http.request{service:{service},env:{env}} > 2"},
+		{"latency fixed scope", APMBlock{Kind: APMLatency, Percentile: "p95", Service: "checkout", Env: "prd", Threshold: APMBlockThreshold{Critical: 0.75, Window: "1h"}},
+			"percentile(last_1h):p95:trace.http.request{service:checkout,env:prd} > 0.75"},
+		{"throughput", APMBlock{Kind: APMThroughput, Threshold: APMBlockThreshold{Critical: 10}},
+			"avg(last_5m):sum:trace.http.request.hits{service:{service},env:{env}}.as_rate() < 10"},
+		{"throughput above", APMBlock{Kind: APMThroughput, Operation: "rack.request", Threshold: APMBlockThreshold{Critical: 1000, Comparator: ">="}},
+			"avg(last_5m):sum:trace.rack.request.hits{service:{service},env:{env}}.as_rate() >= 1000"},
+	} {
+		if got := CompileAPMQuery(tc.block); got != tc.want {
+			t.Errorf("%s:\n got %s\nwant %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestLintAPMBlock(t *testing.T) {
+	warning := func(v float64) *float64 { return &v }
+	for _, tc := range []struct {
+		name  string
+		block APMBlock
+		want  string // key: first problem, or "" for a valid block
+	}{
+		{"valid latency", APMBlock{Kind: APMLatency, Percentile: "p90", Threshold: APMBlockThreshold{Critical: 1, Warning: warning(0.5)}}, ""},
+		{"valid throughput", APMBlock{Kind: APMThroughput, Threshold: APMBlockThreshold{Critical: 10, Warning: warning(20)}}, ""},
+		{"unknown kind", APMBlock{Kind: "apdex", Threshold: APMBlockThreshold{Critical: 1}}, "kind: must be error_rate, latency or throughput"},
+		{"latency without percentile", APMBlock{Kind: APMLatency, Threshold: APMBlockThreshold{Critical: 1}}, "percentile: latency needs a percentile"},
+		{"percentile p999", APMBlock{Kind: APMLatency, Percentile: "p999", Threshold: APMBlockThreshold{Critical: 1}}, `percentile: "p999" is not a trace percentile`},
+		{"percentile 99", APMBlock{Kind: APMLatency, Percentile: "99", Threshold: APMBlockThreshold{Critical: 1}}, `percentile: "99" is not a trace percentile`},
+		{"percentile on error rate", APMBlock{Kind: APMErrorRate, Percentile: "p99", Threshold: APMBlockThreshold{Critical: 0.05}}, "percentile: only latency takes a percentile"},
+		{"error rate as percent", APMBlock{Kind: APMErrorRate, Threshold: APMBlockThreshold{Critical: 5}}, "threshold/critical: an error rate is a fraction"},
+		{"operation is a metric", APMBlock{Kind: APMThroughput, Operation: "trace.http.request{env:prd}", Threshold: APMBlockThreshold{Critical: 1}}, "operation: must be an operation name"},
+		{"bad window", APMBlock{Kind: APMThroughput, Threshold: APMBlockThreshold{Critical: 1, Window: "last_5m"}}, "threshold/window: must be a duration"},
+		{"warning past critical", APMBlock{Kind: APMLatency, Percentile: "p99", Threshold: APMBlockThreshold{Critical: 1, Warning: warning(2)}}, "threshold/warning: 2 must be on the less severe side of critical 1"},
+		{"throughput warning below critical", APMBlock{Kind: APMThroughput, Threshold: APMBlockThreshold{Critical: 10, Warning: warning(5)}}, "threshold/warning: 5 must be on the less severe side"},
+		{"empty scope", APMBlock{Kind: APMThroughput, Scope: []string{" "}, Threshold: APMBlockThreshold{Critical: 1}}, "scope/0: is empty"},
+	} {
+		problems := LintAPMBlock(tc.block)
+		if tc.want == "" {
+			if len(problems) > 0 {
+				t.Errorf("%s: problems %v", tc.name, problems)
+			}
+			continue
+		}
+		key, message, _ := strings.Cut(tc.want, ": ")
+		if len(problems[key]) == 0 || !strings.HasPrefix(problems[key][0], message) {
+			t.Errorf("%s: problems %v, want %s: %s", tc.name, problems, key, message)
+		}
+	}
+}
+
+func TestLintAPMQuery(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		bad   bool
+	}{
+		{"avg(last_5m):sum:trace.http.request.errors{service:a,env:prd}.as_rate() / sum:trace.http.request.hits{service:a,env:prd}.as_rate() > 0.05", false},
+		{"avg(last_5m):sum:trace.http.request.errors{service:a,env:prd}.as_count() / sum:trace.http.request.hits{service:a,env:prd}.as_count() > 0.05", true},
+		{"avg(last_5m):sum:trace.http.request.errors{service:a} / sum:trace.http.request.hits{service:a}.as_rate() > 0.05", true},
+		{"avg(last_5m):sum:trace.http.request.errors{service:a} by {resource_name}.as_rate() / sum:trace.http.request.hits{service:a} by {resource_name} > 0.05", true},
+		{"avg(last_5m):sum:trace.http.request.hits.by_http_status{http.status_class:5xx} / sum:trace.http.request.hits{*}.as_rate() > 0.05", true},
+		// No division, or a slash inside a scope only
+		{"avg(last_5m):sum:trace.http.request.hits{service:a}.as_count() < 10", false},
+		{"avg(last_5m):sum:trace.http.request.hits{resource_name:get_/orders}.as_count() < 10", false},
+		// Divisions of other metrics are none of the lint's business
+		{"avg(last_5m):sum:http.errors{*}.as_count() / sum:http.requests{*}.as_count() > 0.05", false},
+		{"percentile(last_5m):p99:trace.http.request{service:a} / 1000 > 2", false},
+	} {
+		if got := LintAPMQuery(tc.query); (got != "") != tc.bad {
+			t.Errorf("LintAPMQuery(%q) = %q, want a problem: %v", tc.query, got, tc.bad)
+		}
+	}
+}
+
+func TestRenderAPMTemplate(t *testing.T) {
+	path := writeTemplateFile(t, `{
+  "templates": [
+    {
+      "name": "Error rate",
+      "config": {
+        "name": "{service} error rate",
+        "type": "query alert",
+        "message": "Error rate too high",
+        "apm": {"kind": "error_rate", "threshold": {"critical": 0.05, "warning": 0.02}}
+      }
+    },
+    {
+      "name": "Latency",
+      "config": {
+        "name": "{service} p99 latency",
+        "type": "query alert",
+        "message": "Slow",
+        "options": {"thresholds": {"critical": 3}},
+        "apm": {"kind": "latency", "percentile": "p99", "threshold": {"critical": 2}}
+      }
+    }
+  ]
+}`)
+	rendered, _, err := RenderSelectedTemplates(path, ApplyOptions{RenderOptions: RenderOptions{Service: "checkout", Env: "prd"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rendered) != 2 {
+		t.Fatalf("%d monitors rendered, want 2", len(rendered))
+	}
+	errorRate, latency := rendered[0].Monitor, rendered[1].Monitor
+	if want := "avg(last_5m):sum:trace.http.request.errors{service:checkout,env:prd}.as_rate() / sum:trace.http.request.hits{service:checkout,env:prd}.as_rate() > 0.05"; errorRate.Query != want {
+		t.Errorf("error rate query %q, want %q", errorRate.Query, want)
+	}
+	thresholds := errorRate.Options["thresholds"].(map[string]interface{})
+	if thresholds["critical"] != 0.05 || thresholds["warning"] != 0.02 {
+		t.Errorf("error rate thresholds %v", thresholds)
+	}
+	// Thresholds set by the template win over the block's
+	if thresholds := latency.Options["thresholds"].(map[string]interface{}); thresholds["critical"] != 3.0 {
+		t.Errorf("latency thresholds %v", thresholds)
+	}
+	if latency.Query != "percentile(last_5m):p99:trace.http.request{service:checkout,env:prd} > 2" {
+		t.Errorf("latency query %q", latency.Query)
+	}
+
+	invalid := writeTemplateFile(t, `{"name": "x", "type": "query alert", "apm": {"kind": "latency", "percentile": "p42", "threshold": {"critical": 1}}}`)
+	if _, _, err := RenderSelectedTemplates(invalid, ApplyOptions{RenderOptions: RenderOptions{Service: "checkout", Env: "prd"}}); err == nil || !strings.Contains(err.Error(), "not a trace percentile") {
+		t.Errorf("rendering an invalid block: %v", err)
+	}
+}
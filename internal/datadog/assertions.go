@@ -0,0 +1,253 @@
+package datadog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TemplateTestSuffix is appended to a template's base name to find its test file
+// (e.g. templates/cpu.json -> templates/cpu_test.yaml)
+const TemplateTestSuffix = "_test.yaml"
+
+// Assertion checks a rendered string. In YAML it is either a plain string
+// (exact match) or a mapping with one of equals, contains or regex.
+type Assertion struct {
+	Equals   *string `yaml:"equals,omitempty"`
+	Contains string  `yaml:"contains,omitempty"`
+	Regex    string  `yaml:"regex,omitempty"`
+}
+
+// UnmarshalYAML accepts both "value" and {contains: value} forms
+func (a *Assertion) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		value := node.Value
+		a.Equals = &value
+		return nil
+	}
+	type plain Assertion
+	if err := node.Decode((*plain)(a)); err != nil {
+		return err
+	}
+	set := 0
+	if a.Equals != nil {
+		set++
+	}
+	if a.Contains != "" {
+		set++
+	}
+	if a.Regex != "" {
+		set++
+	}
+	if set != 1 {
+		return fmt.Errorf("line %d: assertion needs exactly one of equals, contains or regex", node.Line)
+	}
+	return nil
+}
+
+// MarshalYAML writes exact matches as plain strings
+func (a Assertion) MarshalYAML() (interface{}, error) {
+	if a.Equals != nil {
+		return *a.Equals, nil
+	}
+	type plain Assertion
+	return plain(a), nil
+}
+
+// Check returns an error describing why actual does not satisfy the assertion
+func (a Assertion) Check(actual string) error {
+	switch {
+	case a.Equals != nil:
+		if actual != *a.Equals {
+			return fmt.Errorf("expected %q, got %q", *a.Equals, actual)
+		}
+	case a.Contains != "":
+		if !strings.Contains(actual, a.Contains) {
+			return fmt.Errorf("expected to contain %q, got %q", a.Contains, actual)
+		}
+	case a.Regex != "":
+		re, err := regexp.Compile(a.Regex)
+		if err != nil {
+			return fmt.Errorf("invalid regex %q: %v", a.Regex, err)
+		}
+		if !re.MatchString(actual) {
+			return fmt.Errorf("expected to match /%s/, got %q", a.Regex, actual)
+		}
+	}
+	return nil
+}
+
+// TemplateTestInputs are the values a test case renders the template with
+type TemplateTestInputs struct {
+	Service   string            `yaml:"service"`
+	Env       string            `yaml:"env"`
+	Namespace string            `yaml:"namespace"`
+	Vars      map[string]string `yaml:"vars,omitempty"`
+	Tags      []string          `yaml:"tags,omitempty"`
+	// UppercaseEnvInName comes from the deprecated --uppercase-env-in-name,
+	// not from test files
+	UppercaseEnvInName bool `yaml:"-"`
+}
+
+// TemplateTestExpected lists what the rendered monitor must look like
+type TemplateTestExpected struct {
+	Name       *Assertion         `yaml:"name,omitempty"`
+	Query      []Assertion        `yaml:"query,omitempty"`
+	Message    []Assertion        `yaml:"message,omitempty"`
+	Tags       []string           `yaml:"tags,omitempty"`
+	Thresholds map[string]float64 `yaml:"thresholds,omitempty"`
+}
+
+// TemplateTestCase is a single rendering test. Template selects a template by
+// name in multi-template files.
+type TemplateTestCase struct {
+	Name     string               `yaml:"name"`
+	Template string               `yaml:"template,omitempty"`
+	Inputs   TemplateTestInputs   `yaml:"inputs"`
+	Expected TemplateTestExpected `yaml:"expected"`
+}
+
+// TemplateTestFile is the content of a *_test.yaml file
+type TemplateTestFile struct {
+	Cases []TemplateTestCase `yaml:"cases"`
+}
+
+// TemplateTestPath returns the test file path for a template file
+func TemplateTestPath(templateFile string) string {
+	return strings.TrimSuffix(templateFile, filepath.Ext(templateFile)) + TemplateTestSuffix
+}
+
+// LoadTemplateTests loads a template test file
+func LoadTemplateTests(testFile string) (*TemplateTestFile, error) {
+	data, err := os.ReadFile(testFile)
+	if err != nil {
+		return nil, fmt.Errorf("test file not found: %s", testFile)
+	}
+
+	var tests TemplateTestFile
+	if err := yaml.Unmarshal(data, &tests); err != nil {
+		return nil, fmt.Errorf("invalid YAML in test file %s: %v", testFile, err)
+	}
+	return &tests, nil
+}
+
+// SaveTemplateTests writes a template test file
+func SaveTemplateTests(testFile string, tests *TemplateTestFile) error {
+	var b strings.Builder
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(tests); err != nil {
+		return err
+	}
+	return os.WriteFile(testFile, []byte(b.String()), 0644)
+}
+
+// RenderTestCase renders the template a test case refers to
+func RenderTestCase(templates []TemplateData, tc TemplateTestCase) (map[string]interface{}, error) {
+	var template *TemplateData
+	if tc.Template == "" {
+		if len(templates) != 1 {
+			return nil, fmt.Errorf("file has %d templates, set 'template' to pick one", len(templates))
+		}
+		template = &templates[0]
+	} else {
+		for i := range templates {
+			if templates[i].Name == tc.Template {
+				template = &templates[i]
+				break
+			}
+		}
+		if template == nil {
+			return nil, fmt.Errorf("template %q not found", tc.Template)
+		}
+	}
+
+	vars, err := templateVars(*template, tc.Inputs.Vars)
+	if err != nil {
+		return nil, err
+	}
+	return RenderTemplate(template.Config, RenderOptions{
+		Service:            tc.Inputs.Service,
+		Env:                tc.Inputs.Env,
+		Namespace:          tc.Inputs.Namespace,
+		AdditionalTags:     tc.Inputs.Tags,
+		Vars:               vars,
+		UppercaseEnvInName: tc.Inputs.UppercaseEnvInName,
+	}), nil
+}
+
+// CheckTestCase renders a test case and returns every failed expectation
+func CheckTestCase(templates []TemplateData, tc TemplateTestCase) []string {
+	rendered, err := RenderTestCase(templates, tc)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var failures []string
+	name, _ := rendered["name"].(string)
+	query, _ := rendered["query"].(string)
+	message, _ := rendered["message"].(string)
+
+	if tc.Expected.Name != nil {
+		if err := tc.Expected.Name.Check(name); err != nil {
+			failures = append(failures, "name: "+err.Error())
+		}
+	}
+	for _, assertion := range tc.Expected.Query {
+		if err := assertion.Check(query); err != nil {
+			failures = append(failures, "query: "+err.Error())
+		}
+	}
+	for _, assertion := range tc.Expected.Message {
+		if err := assertion.Check(message); err != nil {
+			failures = append(failures, "message: "+err.Error())
+		}
+	}
+
+	tags, _ := rendered["tags"].([]string)
+	for _, tag := range tc.Expected.Tags {
+		if !containsString(tags, tag) {
+			failures = append(failures, fmt.Sprintf("tags: missing %q (got %s)", tag, strings.Join(tags, ", ")))
+		}
+	}
+
+	var thresholds map[string]interface{}
+	if options, ok := rendered["options"].(map[string]interface{}); ok {
+		thresholds, _ = options["thresholds"].(map[string]interface{})
+	}
+	keys := make([]string, 0, len(tc.Expected.Thresholds))
+	for key := range tc.Expected.Thresholds {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		expected := tc.Expected.Thresholds[key]
+		actual, ok := thresholds[key].(float64)
+		if !ok {
+			failures = append(failures, fmt.Sprintf("thresholds.%s: missing (expected %s)", key, formatThreshold(expected)))
+		} else if actual != expected {
+			failures = append(failures, fmt.Sprintf("thresholds.%s: expected %s, got %s", key, formatThreshold(expected), formatThreshold(actual)))
+		}
+	}
+
+	return failures
+}
+
+// UpdateTestCaseSnapshot replaces the expected name and query of a test case
+// with exact matches of the current rendering
+func UpdateTestCaseSnapshot(templates []TemplateData, tc *TemplateTestCase) error {
+	rendered, err := RenderTestCase(templates, *tc)
+	if err != nil {
+		return err
+	}
+	name, _ := rendered["name"].(string)
+	query, _ := rendered["query"].(string)
+	tc.Expected.Name = &Assertion{Equals: &name}
+	tc.Expected.Query = []Assertion{{Equals: &query}}
+	return nil
+}
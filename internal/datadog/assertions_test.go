@@ -0,0 +1,73 @@
+package datadog
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestAssertionCheck(t *testing.T) {
+	equals := func(s string) Assertion { return Assertion{Equals: &s} }
+	for _, tc := range []struct {
+		name      string
+		assertion Assertion
+		actual    string
+		err       string
+	}{
+		{"equals", equals("CPU high"), "CPU high", ""},
+		{"equals mismatch", equals("CPU high"), "CPU high ", `expected "CPU high", got "CPU high "`},
+		{"equals empty", equals(""), "", ""},
+		{"equals empty mismatch", equals(""), "CPU", `expected "", got "CPU"`},
+		{"contains", Assertion{Contains: "env:prd"}, "avg:cpu{env:prd} > 90", ""},
+		{"contains mismatch", Assertion{Contains: "env:prd"}, "avg:cpu{env:stg} > 90", `expected to contain "env:prd", got "avg:cpu{env:stg} > 90"`},
+		{"contains is case-sensitive", Assertion{Contains: "PRD"}, "env:prd", `expected to contain "PRD", got "env:prd"`},
+		{"regex", Assertion{Regex: `last_\d+m`}, "avg(last_5m):avg:cpu{*} > 90", ""},
+		{"regex anchored", Assertion{Regex: `^avg\(`}, "avg(last_5m):avg:cpu{*} > 90", ""},
+		{"regex mismatch", Assertion{Regex: `^sum\(`}, "avg(last_5m)", `expected to match /^sum\(/, got "avg(last_5m)"`},
+		{"invalid regex", Assertion{Regex: `last_(`}, "avg(last_5m)", "invalid regex \"last_(\": error parsing regexp: missing closing ): `last_(`"},
+		// Equals wins when several are set in code
+		{"equals before contains", Assertion{Equals: new(string), Contains: "x"}, "x", `expected "", got "x"`},
+		{"no assertion", Assertion{}, "anything", ""},
+	} {
+		err := tc.assertion.Check(tc.actual)
+		if tc.err == "" {
+			if err != nil {
+				t.Errorf("%s: Check(%q) = %v, want nil", tc.name, tc.actual, err)
+			}
+		} else if err == nil || err.Error() != tc.err {
+			t.Errorf("%s: Check(%q) = %v, want %q", tc.name, tc.actual, err, tc.err)
+		}
+	}
+}
+
+func TestAssertionYAML(t *testing.T) {
+	var expected TemplateTestExpected
+	err := yaml.Unmarshal([]byte(`
+name: CPU high
+query:
+  - contains: env:prd
+  - regex: last_\d+m
+message:
+  - equals: ""
+`), &expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected.Name == nil || expected.Name.Equals == nil || *expected.Name.Equals != "CPU high" {
+		t.Errorf("name = %+v, want an exact match", expected.Name)
+	}
+	if len(expected.Query) != 2 || expected.Query[0].Contains != "env:prd" || expected.Query[1].Regex != `last_\d+m` {
+		t.Errorf("query = %+v", expected.Query)
+	}
+	if len(expected.Message) != 1 || expected.Message[0].Equals == nil || *expected.Message[0].Equals != "" {
+		t.Errorf("message = %+v, want an exact match of the empty string", expected.Message)
+	}
+
+	for _, input := range []string{"{}", "{contains: a, regex: b}"} {
+		var a Assertion
+		if err := yaml.Unmarshal([]byte(input), &a); err == nil || !strings.Contains(err.Error(), "exactly one of equals, contains or regex") {
+			t.Errorf("Unmarshal(%s) = %v, want an error", input, err)
+		}
+	}
+}
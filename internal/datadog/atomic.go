@@ -0,0 +1,431 @@
+package datadog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MonitorValidationError is returned when the API rejects a monitor definition
+type MonitorValidationError struct {
+	Name   string
+	Errors []string
+}
+
+// Error implements the error interface
+func (e *MonitorValidationError) Error() string {
+	return fmt.Sprintf("monitor %q is invalid: %s", e.Name, strings.Join(e.Errors, "; "))
+}
+
+// ValidateMonitor checks a monitor definition with the API without creating it
+func (c *Client) ValidateMonitor(monitor *Monitor) error {
+	resp, err := c.makeRequest("POST", "/monitor/validate", monitor)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest {
+		body, _ := io.ReadAll(resp.Body)
+		var result struct {
+			Errors []string `json:"errors"`
+		}
+		if json.Unmarshal(body, &result) != nil || len(result.Errors) == 0 {
+			result.Errors = []string{strings.TrimSpace(string(body))}
+		}
+		return &MonitorValidationError{Name: monitor.Name, Errors: result.Errors}
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to validate monitor: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// RestoreMonitor puts back the editable fields of a monitor snapshot
+func (c *Client) RestoreMonitor(snapshot Monitor) (*Monitor, error) {
+	body := map[string]interface{}{
+		"name":    snapshot.Name,
+		"query":   snapshot.Query,
+		"message": snapshot.Message,
+		"tags":    nonNilTags(snapshot.Tags),
+	}
+	if snapshot.Options != nil {
+		body["options"] = snapshot.Options
+	}
+	return c.putMonitor(snapshot.ID, body)
+}
+
+// OrderForApply returns the rendered monitors with composite monitors last, so
+// the monitors they reference are written first
+func OrderForApply(rendered []RenderedMonitor) []RenderedMonitor {
+	ordered := append([]RenderedMonitor{}, rendered...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Monitor.Type != "composite" && ordered[j].Monitor.Type == "composite"
+	})
+	return ordered
+}
+
+// PreflightFailure is a rendered monitor that would fail to apply
+type PreflightFailure struct {
+	TemplateName string `json:"template_name"`
+	Name         string `json:"name"`
+	Err          error  `json:"-"`
+}
+
+// AtomicChange is one write of an atomic apply, and its rollback outcome
+type AtomicChange struct {
+	Kind    string `json:"kind"` // monitor, slo or downtime
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Created bool   `json:"created"`
+	// RolledBack is set once the change was undone: created objects are
+	// deleted, updated ones restored from their snapshot
+	RolledBack bool  `json:"rolled_back"`
+	Err        error `json:"-"`
+
+	undo func(c *Client) error
+}
+
+// Action describes how the change is undone
+func (ch AtomicChange) Action() string {
+	if ch.Created {
+		return "delete"
+	}
+	return "restore"
+}
+
+// AtomicApply applies monitors, SLOs and downtimes all-or-nothing: Preflight
+// checks every monitor before anything is written, and every write is recorded
+// with a snapshot of what it replaced so that Rollback can undo the run.
+type AtomicApply struct {
+	client   *Client
+	opts     ApplyOptions
+	existing map[string]Monitor
+	monitors []Monitor
+	changes  []AtomicChange
+}
+
+// NewAtomicApply starts an atomic apply with the given options
+func (c *Client) NewAtomicApply(opts ApplyOptions) *AtomicApply {
+	return &AtomicApply{client: c, opts: opts}
+}
+
+// Preflight checks every rendered monitor without writing anything: the scope
+// check with StrictScope, the API validation, monitors that already exist
+// without Upsert, unmanaged monitors with ProtectUnmanaged, and
+// adopt_monitor_id monitors that are missing or whose name is taken. It must be
+// called before ApplyMonitor.
+func (a *AtomicApply) Preflight(rendered []RenderedMonitor) ([]PreflightFailure, error) {
+	monitors, err := a.client.ListMonitors(nil, "")
+	if err != nil {
+		return nil, err
+	}
+	a.monitors = monitors
+	a.existing = make(map[string]Monitor, len(monitors))
+	for _, monitor := range monitors {
+		if _, ok := a.existing[monitor.Name]; !ok {
+			a.existing[monitor.Name] = monitor
+		}
+	}
+
+	var failures []PreflightFailure
+	for _, r := range rendered {
+		if err := a.client.interrupted(); err != nil {
+			return failures, err
+		}
+		fail := func(err error) {
+			failures = append(failures, PreflightFailure{TemplateName: r.TemplateName, Name: r.Monitor.Name, Err: err})
+		}
+
+		if a.opts.StrictScope {
+			if mismatches := a.scopeWarnings(r.Monitor); len(mismatches) > 0 {
+				fail(&ScopeMismatchError{TemplateName: r.TemplateName, Mismatches: mismatches})
+				continue
+			}
+		}
+		if r.AdoptMonitorID != 0 {
+			// The monitor is named by ID, so name conflicts are checkAdoption's
+			if _, err := checkAdoption(r.Monitor, r.AdoptMonitorID, a.monitors); err != nil {
+				fail(err)
+				continue
+			}
+		} else {
+			if a.created(r.Monitor) != nil {
+				continue
+			}
+			existing, exists := a.existing[r.Monitor.Name]
+			if a.opts.Upsert {
+				var err error
+				if existing, exists, err = a.upsertTarget(r.Monitor); err != nil {
+					fail(err)
+					continue
+				}
+			}
+			switch {
+			case exists && !a.opts.Upsert && a.opts.OnNameConflict == ConflictFail:
+				fail(&MonitorExistsError{ID: existing.ID, Name: existing.Name})
+				continue
+			case exists && a.opts.Upsert && a.opts.ProtectUnmanaged && !IsManaged(existing):
+				fail(&UnmanagedMonitorError{ID: existing.ID, Name: existing.Name, Creator: existing.Creator})
+				continue
+			}
+		}
+		monitor := r.Monitor
+		if err := a.client.ValidateMonitor(&monitor); err != nil {
+			if stop := a.client.interrupted(); stop != nil {
+				return failures, stop
+			}
+			fail(err)
+		}
+	}
+	return failures, nil
+}
+
+// scopeWarnings returns the env/service mismatches of a monitor query
+func (a *AtomicApply) scopeWarnings(monitor Monitor) []ScopeMismatch {
+	return CheckQueryScope(monitor.Query, map[string]string{
+		"env":     ResolveEnvAlias(a.opts.Env, a.opts.EnvAliases),
+		"service": a.opts.Service,
+	})
+}
+
+// upsertTarget returns the existing monitor an upsert of a rendered monitor
+// updates, matched by template-id tag before name like UpsertMonitor, and
+// then by query with MatchByQuery
+func (a *AtomicApply) upsertTarget(monitor Monitor) (Monitor, bool, error) {
+	if existing := matchIdentity(monitor, a.monitors); existing != nil {
+		return *existing, true, nil
+	}
+	if existing, ok := a.existing[monitor.Name]; ok || !a.opts.MatchByQuery {
+		return existing, ok, nil
+	}
+	existing, err := matchQuery(monitor, a.monitors)
+	if existing == nil || err != nil {
+		return Monitor{}, false, err
+	}
+	return *existing, true, nil
+}
+
+// created returns the monitor an earlier run created for a rendered monitor,
+// found by its fingerprint tag like ApplyTemplateWithOptions; upserts find it
+// by identity instead
+func (a *AtomicApply) created(monitor Monitor) *Monitor {
+	if a.opts.Upsert {
+		return nil
+	}
+	return matchFingerprint(monitor, a.monitors)
+}
+
+// ApplyMonitor creates the rendered monitor, or updates the existing monitor
+// with the same template-id tag or name, and records how to undo it
+func (a *AtomicApply) ApplyMonitor(r RenderedMonitor) (ApplyResult, error) {
+	if err := a.client.interrupted(); err != nil {
+		return ApplyResult{}, err
+	}
+	monitor := r.Monitor
+
+	if r.AdoptMonitorID != 0 {
+		existing, err := checkAdoption(monitor, r.AdoptMonitorID, a.monitors)
+		if err != nil {
+			return ApplyResult{}, fmt.Errorf("failed to apply %s: %w", r.TemplateName, err)
+		}
+		status, redundant := adoptedStatus(monitor, existing)
+		updated, err := a.client.UpdateMonitor(existing.ID, &monitor)
+		if err != nil {
+			return ApplyResult{}, fmt.Errorf("failed to apply %s: %w", r.TemplateName, err)
+		}
+		a.record(AtomicChange{Kind: "monitor", ID: strconv.Itoa(existing.ID), Name: existing.Name, undo: func(c *Client) error {
+			_, err := c.RestoreMonitor(existing)
+			return err
+		}})
+		return ApplyResult{TemplateName: r.TemplateName, ID: updated.ID, Name: updated.Name, Status: status, NameOverflow: r.NameOverflow, K8sDefaults: r.K8sDefaults, NotifyByDropped: r.NotifyByDropped, TagsReplaced: r.TagsReplaced, ScopeWarnings: a.scopeWarnings(monitor), AdoptMonitorID: r.AdoptMonitorID, AdoptRedundant: redundant}, nil
+	}
+
+	if a.opts.Upsert {
+		existing, ok, err := a.upsertTarget(monitor)
+		if err != nil {
+			return ApplyResult{}, fmt.Errorf("failed to apply %s: %w", r.TemplateName, err)
+		}
+		if ok {
+			status := StatusUpdated
+			if !IsManaged(existing) {
+				status = StatusAdopted
+			}
+			renamedFrom := ""
+			if existing.Name != monitor.Name && matchIdentity(monitor, a.monitors) == nil {
+				// Matched by query
+				renamedFrom = existing.Name
+			}
+			if DiffDrift(monitor, existing).Empty() {
+				// Nothing to update, nor to roll back
+				return ApplyResult{TemplateName: r.TemplateName, ID: existing.ID, Name: existing.Name, Status: StatusUnchanged, NameOverflow: r.NameOverflow, K8sDefaults: r.K8sDefaults, NotifyByDropped: r.NotifyByDropped, TagsReplaced: r.TagsReplaced, ScopeWarnings: a.scopeWarnings(monitor)}, nil
+			}
+			updated, err := a.client.UpdateMonitor(existing.ID, &monitor)
+			if err != nil {
+				return ApplyResult{}, fmt.Errorf("failed to apply %s: %w", r.TemplateName, err)
+			}
+			a.record(AtomicChange{Kind: "monitor", ID: strconv.Itoa(existing.ID), Name: existing.Name, undo: func(c *Client) error {
+				_, err := c.RestoreMonitor(existing)
+				return err
+			}})
+			return ApplyResult{TemplateName: r.TemplateName, ID: updated.ID, Name: updated.Name, Status: status, NameOverflow: r.NameOverflow, K8sDefaults: r.K8sDefaults, NotifyByDropped: r.NotifyByDropped, TagsReplaced: r.TagsReplaced, ScopeWarnings: a.scopeWarnings(monitor), RenamedFrom: renamedFrom}, nil
+		}
+	}
+
+	// A monitor an earlier run created is left alone, and not rolled back
+	if existing := a.created(monitor); existing != nil {
+		return ApplyResult{TemplateName: r.TemplateName, ID: existing.ID, Name: existing.Name, Status: StatusExisting, NameOverflow: r.NameOverflow, K8sDefaults: r.K8sDefaults, NotifyByDropped: r.NotifyByDropped, TagsReplaced: r.TagsReplaced, ScopeWarnings: a.scopeWarnings(monitor)}, nil
+	}
+
+	var created *Monitor
+	var err error
+	create := a.opts.forCreate(monitor)
+	if a.opts.Upsert {
+		created, err = a.client.CreateMonitor(&create)
+	} else {
+		created, err = a.client.CreateMonitorStrict(&create, a.opts.OnNameConflict)
+	}
+	if err != nil {
+		return ApplyResult{}, fmt.Errorf("failed to apply %s: %w", r.TemplateName, err)
+	}
+	a.record(AtomicChange{Kind: "monitor", ID: strconv.Itoa(created.ID), Name: created.Name, Created: true, undo: func(c *Client) error {
+		return c.DeleteMonitor(created.ID)
+	}})
+	return ApplyResult{TemplateName: r.TemplateName, ID: created.ID, Name: created.Name, Status: StatusCreated, NameOverflow: r.NameOverflow, K8sDefaults: r.K8sDefaults, NotifyByDropped: r.NotifyByDropped, TagsReplaced: r.TagsReplaced, ScopeWarnings: a.scopeWarnings(monitor), Muted: a.opts.CreateMuted}, nil
+}
+
+// ApplySLO creates or updates an SLO, matching by name, and records how to undo it
+func (a *AtomicApply) ApplySLO(slo *SLO) (*SLO, bool, error) {
+	if err := a.client.interrupted(); err != nil {
+		return nil, false, err
+	}
+	existing, err := a.client.FindSLOByName(slo.Name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if existing != nil {
+		snapshot := *existing
+		updated, err := a.client.UpdateSLO(existing.ID, slo)
+		if err != nil {
+			return nil, false, err
+		}
+		a.record(AtomicChange{Kind: "slo", ID: snapshot.ID, Name: snapshot.Name, undo: func(c *Client) error {
+			_, err := c.UpdateSLO(snapshot.ID, &snapshot)
+			return err
+		}})
+		return updated, false, nil
+	}
+
+	created, err := a.client.CreateSLO(slo)
+	if err != nil {
+		return nil, false, err
+	}
+	a.record(AtomicChange{Kind: "slo", ID: created.ID, Name: created.Name, Created: true, undo: func(c *Client) error {
+		return c.DeleteSLO(created.ID)
+	}})
+	return created, true, nil
+}
+
+// ApplyDowntime creates or updates a downtime, matching like UpsertDowntime,
+// and records how to undo it
+func (a *AtomicApply) ApplyDowntime(downtime *Downtime) (*Downtime, bool, error) {
+	if err := a.client.interrupted(); err != nil {
+		return nil, false, err
+	}
+	existing, err := a.client.findDowntime(downtime.Message)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if existing != nil {
+		snapshot := *existing
+		updated, err := a.client.UpdateDowntime(existing.ID, downtime)
+		if err != nil {
+			return nil, false, err
+		}
+		a.record(AtomicChange{Kind: "downtime", ID: strconv.Itoa(snapshot.ID), Name: snapshot.Message, undo: func(c *Client) error {
+			_, err := c.UpdateDowntime(snapshot.ID, &snapshot)
+			return err
+		}})
+		return updated, false, nil
+	}
+
+	created, err := a.client.CreateDowntime(downtime)
+	if err != nil {
+		return nil, false, err
+	}
+	a.record(AtomicChange{Kind: "downtime", ID: strconv.Itoa(created.ID), Name: created.Message, Created: true, undo: func(c *Client) error {
+		return c.CancelDowntime(created.ID)
+	}})
+	return created, true, nil
+}
+
+// record remembers a write so Rollback can undo it
+func (a *AtomicApply) record(change AtomicChange) {
+	a.changes = append(a.changes, change)
+}
+
+// Changes returns the writes made so far
+func (a *AtomicApply) Changes() []AtomicChange {
+	return a.changes
+}
+
+// Rollback undoes the writes made so far, newest first, and returns every
+// change with its outcome. It keeps going after a failed undo, and still runs
+// after Ctrl-C or --timeout cancelled the client's context.
+func (a *AtomicApply) Rollback() []AtomicChange {
+	client := *a.client
+	client.ctx = context.WithoutCancel(a.client.ctx)
+
+	results := make([]AtomicChange, 0, len(a.changes))
+	for i := len(a.changes) - 1; i >= 0; i-- {
+		change := a.changes[i]
+		change.Err = change.undo(&client)
+		change.RolledBack = change.Err == nil
+		results = append(results, change)
+	}
+	a.changes = nil
+	return results
+}
+
+// MarshalJSON serializes Err as an "error" message
+func (ch AtomicChange) MarshalJSON() ([]byte, error) {
+	type plain AtomicChange
+	return json.Marshal(struct {
+		plain
+		Action string `json:"action"`
+		Error  string `json:"error,omitempty"`
+	}{plain(ch), ch.Action(), errorString(ch.Err)})
+}
+
+// RollbackIncompleteError is returned when some writes of a failed atomic
+// apply could not be undone
+type RollbackIncompleteError struct {
+	Failed int
+	Err    error
+}
+
+// Error implements the error interface
+func (e *RollbackIncompleteError) Error() string {
+	return fmt.Sprintf("%v; rollback incomplete: %d change(s) could not be undone", e.Err, e.Failed)
+}
+
+// Unwrap returns the error that triggered the rollback
+func (e *RollbackIncompleteError) Unwrap() error {
+	return e.Err
+}
+
+// IsRollbackIncomplete reports whether err (or an error it wraps) is a RollbackIncompleteError
+func IsRollbackIncomplete(err error) bool {
+	var target *RollbackIncompleteError
+	return errors.As(err, &target)
+}
@@ -0,0 +1,136 @@
+package datadog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/audit"
+)
+
+// auditErrorBodyLimit bounds the response body kept in the error of a failed entry
+const auditErrorBodyLimit = 300
+
+// isMutating reports whether a request changes anything in Datadog; monitor
+// validation only checks a definition
+func isMutating(req *http.Request) bool {
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return false
+	}
+	return !strings.HasSuffix(req.URL.Path, "/monitor/validate")
+}
+
+// auditResource returns the kind and ID of the object a request path refers
+// to, e.g. /api/v1/monitor/123/mute gives monitor and 123
+func auditResource(path string) (string, string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		switch segment {
+		case "monitor", "slo", "downtime":
+			if i+1 < len(segments) && segments[i+1] != "bulk_resolve" {
+				return segment, segments[i+1]
+			}
+			return segment, ""
+		}
+	}
+	return "", ""
+}
+
+// auditSnapshot is the compact previous value of an updated or deleted monitor
+type auditSnapshot struct {
+	Name    string                 `json:"name"`
+	Type    string                 `json:"type,omitempty"`
+	Query   string                 `json:"query,omitempty"`
+	Message string                 `json:"message,omitempty"`
+	Tags    []string               `json:"tags,omitempty"`
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+// auditedObject picks the ID and name out of a response body: a monitor,
+// downtime or SLO, or an SLO list ({"data": [...]})
+type auditedObject struct {
+	ID      json.RawMessage `json:"id"`
+	Name    string          `json:"name"`
+	Message string          `json:"message"`
+	Data    []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"data"`
+}
+
+// doAudited sends a mutating request and appends its outcome to the audit
+// log, with the previous value of updated and deleted monitors
+func (c *Client) doAudited(req *http.Request) (*http.Response, error) {
+	entry := audit.Entry{Method: req.Method, Endpoint: req.URL.Path}
+	entry.Resource, entry.ID = auditResource(req.URL.Path)
+
+	if entry.Resource == "monitor" && (req.Method == http.MethodPut || req.Method == http.MethodDelete) {
+		if id, err := strconv.Atoi(entry.ID); err == nil {
+			if previous, err := c.GetMonitor(id); err == nil {
+				entry.Name = previous.Name
+				entry.Previous, _ = json.Marshal(auditSnapshot{
+					Name:    previous.Name,
+					Type:    previous.Type,
+					Query:   previous.Query,
+					Message: previous.Message,
+					Tags:    previous.Tags,
+					Options: previous.Options,
+				})
+			}
+		}
+	}
+
+	resp, err := c.send(req)
+	if err != nil {
+		entry.Outcome = audit.OutcomeError
+		entry.Error = err.Error()
+		c.audit.Append(entry)
+		return nil, err
+	}
+
+	// Read the body to find what was changed, and put it back for the caller
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		entry.Outcome = audit.OutcomeError
+		entry.Error = err.Error()
+		c.audit.Append(entry)
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	entry.Status = resp.StatusCode
+	entry.Outcome = audit.OutcomeSuccess
+	if resp.StatusCode >= http.StatusBadRequest {
+		entry.Outcome = audit.OutcomeFailed
+		message := strings.TrimSpace(string(body))
+		if len(message) > auditErrorBodyLimit {
+			message = message[:auditErrorBodyLimit] + "..."
+		}
+		entry.Error = message
+	} else {
+		var object auditedObject
+		if json.Unmarshal(body, &object) == nil {
+			if entry.ID == "" {
+				entry.ID = strings.Trim(string(object.ID), `"`)
+			}
+			switch {
+			case object.Name != "":
+				entry.Name = object.Name
+			case len(object.Data) > 0:
+				if entry.ID == "" {
+					entry.ID = object.Data[0].ID
+				}
+				entry.Name = object.Data[0].Name
+			case entry.Name == "" && entry.Resource == "downtime":
+				entry.Name = object.Message
+			}
+		}
+	}
+
+	c.audit.Append(entry)
+	return resp, nil
+}
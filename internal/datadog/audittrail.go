@@ -0,0 +1,169 @@
+package datadog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// auditEventsPageSize is the page size used when listing audit events
+const auditEventsPageSize = 100
+
+// AuditEvent is a change to a monitor recorded by the Datadog Audit Trail
+type AuditEvent struct {
+	ID   string    `json:"id"`
+	Time time.Time `json:"timestamp"`
+	// Action is what was done, e.g. created, modified or deleted
+	Action string `json:"action"`
+	// Actor is the email, else the name, of the user who made the change
+	Actor string `json:"actor,omitempty"`
+	// Message is the summary the Audit Trail gives of the change
+	Message string `json:"message,omitempty"`
+	// ChangedFields are the fields, options as dotted keys (e.g.
+	// options.thresholds.critical), whose value changed; only set when the
+	// event records the previous and new value of the monitor
+	ChangedFields []string `json:"changed_fields,omitempty"`
+}
+
+// AuditTrailForbiddenError is returned when the audit events API answers 403:
+// the organization doesn't have Audit Trail enabled, or the application key
+// can't read it
+type AuditTrailForbiddenError struct {
+	Body string
+}
+
+// Error implements the error interface
+func (e *AuditTrailForbiddenError) Error() string {
+	return fmt.Sprintf("the audit events API refused the request (403): Audit Trail is not enabled for this organization, or the application key lacks the audit_logs_read permission (body: %s)", e.Body)
+}
+
+// IsAuditTrailForbidden reports whether err (or an error it wraps) is an AuditTrailForbiddenError
+func IsAuditTrailForbidden(err error) bool {
+	var forbidden *AuditTrailForbiddenError
+	return errors.As(err, &forbidden)
+}
+
+// auditEventsResponse is the JSON:API envelope of GET /api/v2/audit/events
+type auditEventsResponse struct {
+	Data []struct {
+		ID         string `json:"id"`
+		Attributes struct {
+			Timestamp  time.Time `json:"timestamp"`
+			Message    string    `json:"message"`
+			Attributes struct {
+				Action string `json:"action"`
+				Usr    struct {
+					Email string `json:"email"`
+					Name  string `json:"name"`
+				} `json:"usr"`
+				Asset struct {
+					PrevValue map[string]interface{} `json:"prev_value"`
+					NewValue  map[string]interface{} `json:"new_value"`
+				} `json:"asset"`
+			} `json:"attributes"`
+		} `json:"attributes"`
+	} `json:"data"`
+	Meta struct {
+		Page struct {
+			After string `json:"after"`
+		} `json:"page"`
+	} `json:"meta"`
+}
+
+// MonitorAuditEvents lists the Audit Trail events of a monitor since the
+// given time, oldest first, following the page cursors
+func (c *Client) MonitorAuditEvents(monitorID int, since time.Time) ([]AuditEvent, error) {
+	var events []AuditEvent
+	cursor := ""
+	for {
+		if err := c.interrupted(); err != nil {
+			return events, err
+		}
+		req, err := c.newV2Request("GET", "/audit/events", nil)
+		if err != nil {
+			return nil, err
+		}
+		q := req.URL.Query()
+		q.Set("filter[query]", "@asset.type:monitor @asset.id:"+strconv.Itoa(monitorID))
+		q.Set("filter[from]", since.UTC().Format(time.RFC3339))
+		q.Set("filter[to]", "now")
+		q.Set("sort", "timestamp")
+		q.Set("page[limit]", strconv.Itoa(auditEventsPageSize))
+		if cursor != "" {
+			q.Set("page[cursor]", cursor)
+		}
+		req.URL.RawQuery = q.Encode()
+
+		resp, err := c.do(req)
+		if err != nil {
+			return events, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusForbidden {
+				return events, &AuditTrailForbiddenError{Body: string(body)}
+			}
+			return events, fmt.Errorf("failed to list audit events: status %d, body: %s", resp.StatusCode, string(body))
+		}
+
+		var result auditEventsResponse
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return events, err
+		}
+
+		for _, data := range result.Data {
+			attributes := data.Attributes.Attributes
+			actor := attributes.Usr.Email
+			if actor == "" {
+				actor = attributes.Usr.Name
+			}
+			events = append(events, AuditEvent{
+				ID:            data.ID,
+				Time:          data.Attributes.Timestamp,
+				Action:        attributes.Action,
+				Actor:         actor,
+				Message:       data.Attributes.Message,
+				ChangedFields: changedFields(attributes.Asset.PrevValue, attributes.Asset.NewValue),
+			})
+		}
+		cursor = result.Meta.Page.After
+		if cursor == "" || len(result.Data) == 0 {
+			break
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	return events, nil
+}
+
+// changedFields returns the sorted dotted keys whose value differs between
+// the previous and new value of an asset; nil unless both are recorded
+func changedFields(prev, next map[string]interface{}) []string {
+	if prev == nil || next == nil {
+		return nil
+	}
+	from := flattenOptions(prev, "", make(map[string]interface{}))
+	to := flattenOptions(next, "", make(map[string]interface{}))
+	var changed []string
+	for key, value := range to {
+		if !reflect.DeepEqual(from[key], value) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range from {
+		if _, ok := to[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
@@ -0,0 +1,56 @@
+package datadog
+
+import (
+	"sort"
+	"sync"
+)
+
+// DefaultFetchConcurrency is how many monitors GetMonitors fetches at once
+// unless told otherwise
+const DefaultFetchConcurrency = 4
+
+// GetMonitors fetches monitors one by one by ID, with the full detail the list
+// endpoint omits, with at most concurrency requests in flight. The monitors
+// are returned sorted by ID, and the failures by monitor ID. progress, when
+// set, is called after each fetch with the number done so far; calls are
+// never concurrent.
+func (c *Client) GetMonitors(ids []int, groupStates []string, concurrency int, progress func(done, total int)) ([]Monitor, map[int]error) {
+	if concurrency < 1 {
+		concurrency = DefaultFetchConcurrency
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		monitors []Monitor
+		failures = make(map[int]error)
+		done     int
+	)
+	slots := make(chan struct{}, concurrency)
+	for _, id := range ids {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(id int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			monitor, err := c.GetMonitorWithGroupStates(id, groupStates)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failures[id] = err
+			} else {
+				monitors = append(monitors, *monitor)
+			}
+			done++
+			if progress != nil {
+				progress(done, len(ids))
+			}
+		}(id)
+	}
+	wg.Wait()
+
+	sort.Slice(monitors, func(i, j int) bool { return monitors[i].ID < monitors[j].ID })
+	return monitors, failures
+}
@@ -0,0 +1,74 @@
+package datadog
+
+import (
+	"embed"
+	"fmt"
+)
+
+//go:embed builtin/*.json
+var builtinFS embed.FS
+
+// Built-in template presets
+const (
+	PresetBasic = "basic"
+	PresetFull  = "full"
+)
+
+// BuiltinTemplate is a starter monitor template shipped with the binary
+type BuiltinTemplate struct {
+	Name        string // File name without extension, e.g. pod-crashloop
+	Description string
+	// Basic templates are part of the basic preset; the full preset has every template
+	Basic bool
+}
+
+// BuiltinTemplates lists the embedded Kubernetes starter templates
+var BuiltinTemplates = []BuiltinTemplate{
+	{Name: "pod-crashloop", Description: "Pods stuck in CrashLoopBackOff", Basic: true},
+	{Name: "deployment-replicas-unavailable", Description: "Deployments with unavailable replicas for 15 minutes", Basic: true},
+	{Name: "memory-limit", Description: "Pods using more than 90% of their memory limit", Basic: true},
+	{Name: "cpu-throttling", Description: "Pods CPU throttled more than 25% of the time"},
+	{Name: "pvc-nearly-full", Description: "Persistent volume claims more than 90% full"},
+	{Name: "job-failures", Description: "Jobs with failed pods"},
+}
+
+// FileName returns the name the template is written as
+func (t BuiltinTemplate) FileName() string {
+	return t.Name + ".json"
+}
+
+// Data returns the template JSON
+func (t BuiltinTemplate) Data() ([]byte, error) {
+	return builtinFS.ReadFile("builtin/" + t.FileName())
+}
+
+// Validate checks the template against the monitor template schema
+func (t BuiltinTemplate) Validate() error {
+	data, err := t.Data()
+	if err != nil {
+		return err
+	}
+	templates, single, err := parseTemplates(t.FileName(), data)
+	if err != nil {
+		return err
+	}
+	return validateTemplates(t.FileName(), templates, single, nil)
+}
+
+// BuiltinTemplatesForPreset returns the built-in templates of a preset
+func BuiltinTemplatesForPreset(preset string) ([]BuiltinTemplate, error) {
+	switch preset {
+	case PresetFull:
+		return BuiltinTemplates, nil
+	case PresetBasic:
+		var templates []BuiltinTemplate
+		for _, template := range BuiltinTemplates {
+			if template.Basic {
+				templates = append(templates, template)
+			}
+		}
+		return templates, nil
+	default:
+		return nil, fmt.Errorf("invalid preset %q (must be %s or %s)", preset, PresetBasic, PresetFull)
+	}
+}
@@ -0,0 +1,159 @@
+package datadog
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/paths"
+)
+
+// DefaultCacheDir returns the directory used for cached API responses
+func DefaultCacheDir() string {
+	return filepath.Join(paths.CacheDir(), "http")
+}
+
+// responseCache stores GET response bodies on disk together with their ETag so
+// repeated requests can be revalidated with If-None-Match
+type responseCache struct {
+	dir string
+}
+
+// key identifies a request; credentials are part of the key so different
+// organizations never share entries
+func (rc *responseCache) key(req *http.Request) string {
+	h := sha256.New()
+	io.WriteString(h, req.Method+" "+req.URL.String()+" ")
+	io.WriteString(h, req.Header.Get("DD-API-KEY")+" "+req.Header.Get("DD-APPLICATION-KEY")+" "+req.Header.Get("Authorization"))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (rc *responseCache) etagPath(key string) string {
+	return filepath.Join(rc.dir, key+".etag")
+}
+
+func (rc *responseCache) bodyPath(key string) string {
+	return filepath.Join(rc.dir, key+".body")
+}
+
+// prepare adds If-None-Match to the request when a cached entry exists
+func (rc *responseCache) prepare(req *http.Request, key string) {
+	etag, err := os.ReadFile(rc.etagPath(key))
+	if err != nil || len(etag) == 0 {
+		return
+	}
+	if _, err := os.Stat(rc.bodyPath(key)); err != nil {
+		return
+	}
+	req.Header.Set("If-None-Match", string(etag))
+}
+
+// serve replaces a 304 Not Modified response with the cached body
+func (rc *responseCache) serve(resp *http.Response, key string) bool {
+	body, err := os.Open(rc.bodyPath(key))
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	resp.StatusCode = http.StatusOK
+	resp.Status = "200 OK (cached)"
+	resp.ContentLength = -1
+	resp.Body = body
+	return true
+}
+
+// store wraps the response body so it is written to the cache as it is read.
+// The entry is only committed once the body has been read completely.
+func (rc *responseCache) store(resp *http.Response, key string) {
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		return
+	}
+	if err := paths.EnsureDir(rc.dir); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(rc.dir, key+".*.tmp")
+	if err != nil {
+		return
+	}
+	resp.Body = &cachingBody{
+		body:  resp.Body,
+		tmp:   tmp,
+		cache: rc,
+		key:   key,
+		etag:  etag,
+	}
+}
+
+// cachingBody tees a response body into a temporary cache file
+type cachingBody struct {
+	body  io.ReadCloser
+	tmp   *os.File
+	cache *responseCache
+	key   string
+	etag  string
+	eof   bool
+	err   bool
+}
+
+func (b *cachingBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if n > 0 && !b.err {
+		if _, werr := b.tmp.Write(p[:n]); werr != nil {
+			b.err = true
+		}
+	}
+	if err == io.EOF {
+		b.eof = true
+	} else if err != nil {
+		b.err = true
+	}
+	return n, err
+}
+
+func (b *cachingBody) Close() error {
+	err := b.body.Close()
+	b.tmp.Close()
+	if b.eof && !b.err {
+		// Drop the old ETag first so a half-written entry is never revalidated
+		os.Remove(b.cache.etagPath(b.key))
+		if os.Rename(b.tmp.Name(), b.cache.bodyPath(b.key)) == nil {
+			os.WriteFile(b.cache.etagPath(b.key), []byte(b.etag), 0o600)
+			return err
+		}
+	}
+	os.Remove(b.tmp.Name())
+	return err
+}
+
+// gzipBody decompresses a gzip-encoded response body
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (g *gzipBody) Close() error {
+	g.Reader.Close()
+	return g.body.Close()
+}
+
+// decompress transparently decodes gzip-encoded responses
+func decompress(resp *http.Response) error {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") || resp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	reader, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		resp.Body.Close()
+		return err
+	}
+	resp.Body = &gzipBody{Reader: reader, body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.ContentLength = -1
+	return nil
+}
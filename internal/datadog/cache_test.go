@@ -0,0 +1,259 @@
+package datadog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+)
+
+// etagServer is an API stub serving one monitor with an ETag, answering 304
+// when If-None-Match matches it
+type etagServer struct {
+	mu   sync.Mutex
+	etag string
+	body string
+	gzip bool
+	// before304 runs before a 304 is written
+	before304 func()
+	// ifNoneMatch is the If-None-Match header of each request
+	ifNoneMatch []string
+}
+
+func (s *etagServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ifNoneMatch = append(s.ifNoneMatch, r.Header.Get("If-None-Match"))
+	w.Header().Set("ETag", s.etag)
+	if r.Header.Get("If-None-Match") == s.etag {
+		if s.before304 != nil {
+			s.before304()
+		}
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if s.gzip {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write([]byte(s.body))
+		zw.Close()
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(buf.Bytes())
+		return
+	}
+	w.Write([]byte(s.body))
+}
+
+// set changes the monitor served and its ETag
+func (s *etagServer) set(etag, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.etag = etag
+	s.body = `{"id": 1, "name": "` + name + `", "type": "metric alert", "query": "avg(last_5m):avg:cpu{*} > 90"}`
+}
+
+// newCachingClient returns a client of srv caching responses in dir
+func newCachingClient(t *testing.T, srv *httptest.Server, dir string) *Client {
+	t.Helper()
+	client, err := NewClientWithOptions(WithAPIKey("api-key"), WithAppKey("app-key"), WithBaseURL(srv.URL), WithCacheDir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// assertMonitorName gets monitor 1 and checks its name
+func assertMonitorName(t *testing.T, client *Client, want string) {
+	t.Helper()
+	monitor, err := client.GetMonitor(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if monitor.Name != want {
+		t.Errorf("monitor name %q, want %q", monitor.Name, want)
+	}
+}
+
+func TestResponseCacheRevalidates(t *testing.T) {
+	for _, gzipped := range []bool{false, true} {
+		stub := &etagServer{gzip: gzipped}
+		stub.set(`"v1"`, "CPU")
+		srv := httptest.NewServer(stub)
+		dir := t.TempDir()
+		client := newCachingClient(t, srv, dir)
+
+		// The first request has nothing to revalidate; the second is
+		// answered from the cache after a 304
+		assertMonitorName(t, client, "CPU")
+		assertMonitorName(t, client, "CPU")
+
+		// A changed monitor is served and cached again
+		stub.set(`"v2"`, "CPU high")
+		assertMonitorName(t, client, "CPU high")
+		assertMonitorName(t, client, "CPU high")
+		srv.Close()
+
+		want := []string{"", `"v1"`, `"v1"`, `"v2"`}
+		if strings.Join(stub.ifNoneMatch, " ") != strings.Join(want, " ") {
+			t.Errorf("gzip %v: If-None-Match %q, want %q", gzipped, stub.ifNoneMatch, want)
+		}
+		// Gzip responses are cached decompressed
+		bodies, _ := filepath.Glob(filepath.Join(dir, "*.body"))
+		if len(bodies) != 1 {
+			t.Fatalf("gzip %v: cached bodies %q, want one", gzipped, bodies)
+		}
+		if cached, err := os.ReadFile(bodies[0]); err != nil || !strings.Contains(string(cached), `"CPU high"`) {
+			t.Errorf("gzip %v: cached body %q, %v", gzipped, cached, err)
+		}
+	}
+}
+
+func TestResponseCacheMissingBody(t *testing.T) {
+	dir := t.TempDir()
+	stub := &etagServer{}
+	stub.set(`"v1"`, "CPU")
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+	client := newCachingClient(t, srv, dir)
+	assertMonitorName(t, client, "CPU")
+
+	// The cached body vanishes between the revalidation and the 304: the
+	// request is sent again without If-None-Match
+	stub.before304 = func() {
+		bodies, _ := filepath.Glob(filepath.Join(dir, "*.body"))
+		for _, body := range bodies {
+			os.Remove(body)
+		}
+	}
+	assertMonitorName(t, client, "CPU")
+	if want := []string{"", `"v1"`, ""}; strings.Join(stub.ifNoneMatch, " ") != strings.Join(want, " ") {
+		t.Errorf("If-None-Match %q, want %q", stub.ifNoneMatch, want)
+	}
+
+	// The retried response is cached again
+	stub.before304 = nil
+	assertMonitorName(t, client, "CPU")
+	if last := stub.ifNoneMatch[len(stub.ifNoneMatch)-1]; last != `"v1"` {
+		t.Errorf("If-None-Match %q after the retry, want the ETag", last)
+	}
+
+	// Without the body, the ETag isn't sent at all
+	bodies, _ := filepath.Glob(filepath.Join(dir, "*.body"))
+	for _, body := range bodies {
+		os.Remove(body)
+	}
+	assertMonitorName(t, client, "CPU")
+	if last := stub.ifNoneMatch[len(stub.ifNoneMatch)-1]; last != "" {
+		t.Errorf("If-None-Match %q without a cached body, want none", last)
+	}
+}
+
+// cacheEntries returns the extensions of the files in a cache directory
+func cacheEntries(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, filepath.Ext(entry.Name()))
+	}
+	return names
+}
+
+func TestCachingBodyCommitsOnEOF(t *testing.T) {
+	const body = `{"id": 1, "name": "CPU"}`
+	rc := &responseCache{dir: t.TempDir()}
+	newResponse := func(r io.Reader, etag string) *http.Response {
+		resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(r)}
+		if etag != "" {
+			resp.Header.Set("ETag", etag)
+		}
+		return resp
+	}
+
+	// Closed before the end of the body: nothing is cached
+	resp := newResponse(strings.NewReader(body), `"v1"`)
+	rc.store(resp, "partial")
+	if _, err := resp.Body.Read(make([]byte, 5)); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	// A read error: nothing is cached
+	resp = newResponse(io.MultiReader(strings.NewReader(body), iotest.ErrReader(errors.New("connection reset"))), `"v1"`)
+	rc.store(resp, "failed")
+	if _, err := io.ReadAll(resp.Body); err == nil {
+		t.Fatal("reading the body succeeded, want the read error")
+	}
+	resp.Body.Close()
+
+	// Without an ETag there is nothing to revalidate with
+	resp = newResponse(strings.NewReader(body), "")
+	rc.store(resp, "no-etag")
+	io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if entries := cacheEntries(t, rc.dir); len(entries) != 0 {
+		t.Fatalf("cache entries %q, want none", entries)
+	}
+
+	// Read to EOF: the entry is committed on Close, not before
+	resp = newResponse(strings.NewReader(body), `"v1"`)
+	rc.store(resp, "complete")
+	if got, err := io.ReadAll(resp.Body); err != nil || string(got) != body {
+		t.Fatalf("body %q, %v", got, err)
+	}
+	if _, err := os.Stat(rc.bodyPath("complete")); !os.IsNotExist(err) {
+		t.Errorf("body cached before Close: %v", err)
+	}
+	resp.Body.Close()
+	if cached, err := os.ReadFile(rc.bodyPath("complete")); err != nil || string(cached) != body {
+		t.Errorf("cached body %q, %v", cached, err)
+	}
+	if etag, err := os.ReadFile(rc.etagPath("complete")); err != nil || string(etag) != `"v1"` {
+		t.Errorf("cached ETag %q, %v", etag, err)
+	}
+	if entries := cacheEntries(t, rc.dir); strings.Join(entries, " ") != ".body .etag" {
+		t.Errorf("cache entries %q, want the body and ETag only", entries)
+	}
+}
+
+func TestResponseCacheKey(t *testing.T) {
+	rc := &responseCache{}
+	newRequest := func(method, url, apiKey, appKey, authorization string) *http.Request {
+		req := httptest.NewRequest(method, url, nil)
+		req.Header.Set("DD-API-KEY", apiKey)
+		req.Header.Set("DD-APPLICATION-KEY", appKey)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		return req
+	}
+	base := rc.key(newRequest("GET", "https://api.datadoghq.com/api/v1/monitor?page=0", "a", "b", ""))
+	if same := rc.key(newRequest("GET", "https://api.datadoghq.com/api/v1/monitor?page=0", "a", "b", "")); same != base {
+		t.Error("the same request has two keys")
+	}
+	for name, req := range map[string]*http.Request{
+		"method":        newRequest("HEAD", "https://api.datadoghq.com/api/v1/monitor?page=0", "a", "b", ""),
+		"path":          newRequest("GET", "https://api.datadoghq.com/api/v1/monitor/1?page=0", "a", "b", ""),
+		"query":         newRequest("GET", "https://api.datadoghq.com/api/v1/monitor?page=1", "a", "b", ""),
+		"host":          newRequest("GET", "https://api.datadoghq.eu/api/v1/monitor?page=0", "a", "b", ""),
+		"API key":       newRequest("GET", "https://api.datadoghq.com/api/v1/monitor?page=0", "c", "b", ""),
+		"app key":       newRequest("GET", "https://api.datadoghq.com/api/v1/monitor?page=0", "a", "c", ""),
+		"authorization": newRequest("GET", "https://api.datadoghq.com/api/v1/monitor?page=0", "a", "b", "Bearer token"),
+	} {
+		if rc.key(req) == base {
+			t.Errorf("a different %s has the same key", name)
+		}
+	}
+}
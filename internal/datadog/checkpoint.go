@@ -0,0 +1,135 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Checkpoint records the progress of a bulk delete (or mute) in a file so a
+// run that was interrupted or crashed can resume where it stopped: the target
+// monitors are written before the first change, and each monitor is added to
+// Done right after it is handled. Every save replaces the file atomically, so
+// a crash leaves the previous version intact.
+type Checkpoint struct {
+	// Operation identifies the run (command and filters); a checkpoint is
+	// only resumed by the same operation
+	Operation string            `json:"operation"`
+	Created   time.Time         `json:"created"`
+	Targets   []CheckpointEntry `json:"targets"`
+	Done      []int             `json:"done"`
+
+	path string
+	done map[int]bool
+}
+
+// CheckpointEntry is a target monitor of a checkpoint
+type CheckpointEntry struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// NewCheckpoint starts the checkpoint of operation on monitors at path. It
+// isn't written until Save.
+func NewCheckpoint(path, operation string, monitors []Monitor, now time.Time) *Checkpoint {
+	checkpoint := &Checkpoint{Operation: operation, Created: now.UTC(), Done: []int{}, path: path, done: make(map[int]bool)}
+	for _, monitor := range monitors {
+		checkpoint.Targets = append(checkpoint.Targets, CheckpointEntry{ID: monitor.ID, Name: monitor.Name})
+	}
+	return checkpoint
+}
+
+// LoadCheckpoint reads the checkpoint at path; nil without error when there is
+// no file
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint file %s: %v", path, err)
+	}
+	checkpoint.path = path
+	checkpoint.done = make(map[int]bool, len(checkpoint.Done))
+	for _, id := range checkpoint.Done {
+		checkpoint.done[id] = true
+	}
+	return &checkpoint, nil
+}
+
+// Save writes the checkpoint file atomically
+func (c *Checkpoint) Save() error {
+	return writeJSONFile(c.path, c, ".ddmm-checkpoint-*")
+}
+
+// IsDone reports whether the monitor was handled by this or an earlier run;
+// false for a nil checkpoint
+func (c *Checkpoint) IsDone(id int) bool {
+	return c != nil && c.done[id]
+}
+
+// MarkDone records the monitor as handled and saves the checkpoint; a nil
+// checkpoint does nothing
+func (c *Checkpoint) MarkDone(id int) error {
+	if c == nil || c.done[id] {
+		return nil
+	}
+	c.done[id] = true
+	c.Done = append(c.Done, id)
+	if err := c.Save(); err != nil {
+		return fmt.Errorf("failed to update checkpoint file %s: %v", c.path, err)
+	}
+	return nil
+}
+
+// Remaining returns the targets not done yet, as monitors with their ID and
+// name, in the original order
+func (c *Checkpoint) Remaining() []Monitor {
+	var remaining []Monitor
+	for _, target := range c.Targets {
+		if !c.done[target.ID] {
+			remaining = append(remaining, Monitor{ID: target.ID, Name: target.Name})
+		}
+	}
+	return remaining
+}
+
+// Complete reports whether every target is done
+func (c *Checkpoint) Complete() bool {
+	return len(c.Remaining()) == 0
+}
+
+// DeleteMonitorsWithCheckpoint is DeleteMonitors recording each monitor
+// deleted, or already gone, in checkpoint; monitors it has done are skipped.
+// Failed monitors stay pending, so a resumed run retries them. A checkpoint
+// that can't be saved stops the loop.
+func (c *Client) DeleteMonitorsWithCheckpoint(monitors []Monitor, checkpoint *Checkpoint) ([]DeleteResult, error) {
+	var results []DeleteResult
+	for _, monitor := range monitors {
+		if checkpoint.IsDone(monitor.ID) {
+			continue
+		}
+		if err := c.interrupted(); err != nil {
+			return results, err
+		}
+		err := c.DeleteMonitor(monitor.ID)
+		if stopErr := c.stopError(err); stopErr != nil {
+			return results, stopErr
+		}
+		result := NewDeleteResult(monitor, err)
+		results = append(results, result)
+		c.recordFailure(err)
+		if result.Status == StatusDeleted || result.Status == StatusNotFound {
+			if err := checkpoint.MarkDone(monitor.ID); err != nil {
+				return results, err
+			}
+		}
+	}
+
+	return results, nil
+}
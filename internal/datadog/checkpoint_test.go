@@ -0,0 +1,51 @@
+package datadog
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	if checkpoint, err := LoadCheckpoint(path); checkpoint != nil || err != nil {
+		t.Fatalf("LoadCheckpoint of a missing file = %v, %v", checkpoint, err)
+	}
+
+	monitors := []Monitor{{ID: 3, Name: "c"}, {ID: 1, Name: "a"}, {ID: 2, Name: "b"}}
+	checkpoint := NewCheckpoint(path, "delete-all service=old", monitors, time.Now())
+	if err := checkpoint.Save(); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int{1, 1} {
+		if err := checkpoint.MarkDone(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A crash after MarkDone loses nothing: the file has every monitor done
+	loaded, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.Done, []int{1}) || !loaded.IsDone(1) || loaded.IsDone(3) {
+		t.Errorf("loaded done %v", loaded.Done)
+	}
+	// The remaining monitors keep their order
+	if want := []Monitor{{ID: 3, Name: "c"}, {ID: 2, Name: "b"}}; !reflect.DeepEqual(loaded.Remaining(), want) {
+		t.Errorf("Remaining() = %+v, want %+v", loaded.Remaining(), want)
+	}
+	for _, id := range []int{3, 2} {
+		loaded.MarkDone(id)
+	}
+	if !loaded.Complete() {
+		t.Error("checkpoint not complete with every target done")
+	}
+
+	// Without --checkpoint-file nothing is done or recorded
+	var none *Checkpoint
+	if none.IsDone(1) || none.MarkDone(1) != nil {
+		t.Error("nil checkpoint recorded a monitor")
+	}
+}
@@ -0,0 +1,79 @@
+package datadog
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// namespaceNamePattern matches valid Kubernetes namespace names (DNS labels)
+var namespaceNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
+
+// ParseNamespaceList reads namespace names, one per line. It accepts plain names
+// as well as the output of `kubectl get ns -o name` (namespace/foo); blank lines
+// and lines starting with # are ignored. Any other line, such as a table header
+// or an error message, fails the whole list rather than being taken for a
+// namespace.
+func ParseNamespaceList(r io.Reader) ([]string, error) {
+	seen := make(map[string]bool)
+	var namespaces []string
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name := strings.TrimPrefix(line, "namespace/")
+		if !namespaceNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("line %d: %q is not a namespace name (expected one name per line, or the output of kubectl get ns -o name)", lineNumber, line)
+		}
+		if !seen[name] {
+			seen[name] = true
+			namespaces = append(namespaces, name)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// MonitorNamespace returns the value of a monitor's namespace tag, if any
+func MonitorNamespace(monitor Monitor) (string, bool) {
+	for _, tag := range monitor.Tags {
+		if strings.HasPrefix(tag, "namespace:") {
+			return strings.TrimPrefix(tag, "namespace:"), true
+		}
+	}
+	return "", false
+}
+
+// StaleNamespaceMonitors returns the monitors whose namespace tag is not one of
+// the live namespaces. Monitors without a namespace tag are never stale, and
+// monitors modified within gracePeriod before now are skipped.
+func StaleNamespaceMonitors(monitors []Monitor, live []string, gracePeriod time.Duration, now time.Time) []Monitor {
+	isLive := make(map[string]bool, len(live))
+	for _, namespace := range live {
+		isLive[namespace] = true
+	}
+
+	var stale []Monitor
+	for _, monitor := range monitors {
+		namespace, ok := MonitorNamespace(monitor)
+		if !ok || isLive[namespace] {
+			continue
+		}
+		if gracePeriod > 0 && !monitor.Modified.IsZero() && now.Sub(monitor.Modified.Time()) < gracePeriod {
+			continue
+		}
+		stale = append(stale, monitor)
+	}
+	return stale
+}
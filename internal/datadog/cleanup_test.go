@@ -0,0 +1,115 @@
+package datadog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseNamespaceList(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"kubectl get ns -o name", "namespace/default\nnamespace/kube-system\nnamespace/shop\n", []string{"default", "kube-system", "shop"}},
+		{"plain names, sorted", "shop\nbilling\n", []string{"billing", "shop"}},
+		{"duplicates", "shop\nnamespace/shop\nshop\n", []string{"shop"}},
+		{"blank lines, comments and spaces", "# live namespaces\n\n  shop  \r\n\tbilling\n", []string{"billing", "shop"}},
+		{"no trailing newline", "namespace/shop", []string{"shop"}},
+		{"empty", "", nil},
+		{"only comments", "# none yet\n", nil},
+	} {
+		got, err := ParseNamespaceList(strings.NewReader(tc.input))
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: ParseNamespaceList = %q, %v, want %q", tc.name, got, err, tc.want)
+		}
+	}
+}
+
+func TestParseNamespaceListUnparseable(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{"table output", "NAME          STATUS   AGE\ndefault       Active   12d\n", `line 1: "NAME          STATUS   AGE" is not a namespace name`},
+		{"kubectl error", "namespace/shop\nerror: You must be logged in to the server (Unauthorized)\n", `line 2: "error: You must be logged in to the server (Unauthorized)"`},
+		{"other resource kind", "namespace/shop\npod/checkout-7f9\n", `line 2: "pod/checkout-7f9"`},
+		{"uppercase", "Shop\n", `line 1: "Shop"`},
+		{"JSON", `{"kind": "NamespaceList", "items": []}`, `line 1: "{\"kind\": \"NamespaceList\", \"items\": []}"`},
+		{"too long", strings.Repeat("a", 64), "line 1:"},
+		{"leading dash", "-shop\n", `line 1: "-shop"`},
+	} {
+		if got, err := ParseNamespaceList(strings.NewReader(tc.input)); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: ParseNamespaceList = %q, %v, want %q", tc.name, got, err, tc.err)
+		}
+	}
+}
+
+func TestStaleNamespaceMonitors(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	modified := func(ago time.Duration) Timestamp {
+		return Timestamp{time: now.Add(-ago)}
+	}
+	monitors := []Monitor{
+		{ID: 1, Tags: []string{"namespace:shop", "env:prd"}, Modified: modified(30 * 24 * time.Hour)},
+		{ID: 2, Tags: []string{"env:prd", "namespace:old-shop"}, Modified: modified(30 * 24 * time.Hour)},
+		{ID: 3, Tags: []string{"env:prd"}},
+		{ID: 4, Tags: []string{"namespace:old-shop"}, Modified: modified(time.Hour)},
+		{ID: 5, Tags: []string{"namespace:gone"}},
+		{ID: 6, Tags: []string{"kube_namespace:old-shop"}},
+		{ID: 7, Tags: []string{"namespace:Shop"}, Modified: modified(30 * 24 * time.Hour)},
+	}
+	ids := func(stale []Monitor) []int {
+		var ids []int
+		for _, monitor := range stale {
+			ids = append(ids, monitor.ID)
+		}
+		return ids
+	}
+
+	for _, tc := range []struct {
+		name        string
+		live        []string
+		gracePeriod time.Duration
+		want        []int
+	}{
+		// Without a namespace tag (3, and 6 with another key) a monitor is
+		// never stale; tags are compared as written (7)
+		{"set difference", []string{"shop", "billing"}, 0, []int{2, 4, 5, 7}},
+		// Monitors modified within the grace period are skipped; never
+		// modified ones (5) are not
+		{"grace period", []string{"shop", "billing"}, 7 * 24 * time.Hour, []int{2, 5, 7}},
+		{"every namespace live", []string{"shop", "old-shop", "gone", "Shop"}, 0, nil},
+		// Live namespaces without monitors change nothing
+		{"namespaces without monitors", []string{"shop", "billing", "payments", "kube-system"}, 0, []int{2, 4, 5, 7}},
+		{"no live namespaces", nil, 0, []int{1, 2, 4, 5, 7}},
+	} {
+		if got := ids(StaleNamespaceMonitors(monitors, tc.live, tc.gracePeriod, now)); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: stale monitors %v, want %v", tc.name, got, tc.want)
+		}
+	}
+	if stale := StaleNamespaceMonitors(nil, []string{"shop"}, 0, now); len(stale) != 0 {
+		t.Errorf("stale monitors without monitors: %v", stale)
+	}
+}
+
+func TestMonitorNamespace(t *testing.T) {
+	for _, tc := range []struct {
+		tags []string
+		want string
+		ok   bool
+	}{
+		{[]string{"env:prd", "namespace:shop"}, "shop", true},
+		{[]string{"namespace:shop", "namespace:billing"}, "shop", true},
+		{[]string{"namespace:"}, "", true},
+		{[]string{"kube_namespace:shop"}, "", false},
+		{nil, "", false},
+	} {
+		if got, ok := MonitorNamespace(Monitor{Tags: tc.tags}); got != tc.want || ok != tc.ok {
+			t.Errorf("MonitorNamespace(%q) = %q, %v, want %q, %v", tc.tags, got, ok, tc.want, tc.ok)
+		}
+	}
+}
@@ -2,54 +2,38 @@ package datadog
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
-	"strconv"
 	"strings"
+	"time"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/audit"
 )
 
 // Config holds Datadog API configuration
 type Config struct {
-	APIKey  string
-	AppKey  string
-	APIURL  string
-	Headers map[string]string
-}
-
-// Timestamp is a custom type that can unmarshal both string and int64 timestamps
-type Timestamp int64
-
-// UnmarshalJSON implements custom unmarshaling for Timestamp
-func (t *Timestamp) UnmarshalJSON(data []byte) error {
-	// Try to unmarshal as string first
-	var str string
-	if err := json.Unmarshal(data, &str); err == nil {
-		// Try to parse as int64 string
-		if val, err := strconv.ParseInt(str, 10, 64); err == nil {
-			*t = Timestamp(val)
-			return nil
-		}
-		// If it's not a numeric string, return 0
-		*t = 0
-		return nil
-	}
-
-	// Try to unmarshal as int64
-	var val int64
-	if err := json.Unmarshal(data, &val); err != nil {
-		*t = 0
-		return nil
-	}
-	*t = Timestamp(val)
-	return nil
+	APIKey string
+	AppKey string
+	// APIURL is the API v1 base URL (e.g., https://api.datadoghq.com/api/v1)
+	APIURL string
+	// APIV2URL is the API v2 base URL (e.g., https://api.datadoghq.com/api/v2)
+	APIV2URL string
+	// Credentials supplies the authentication headers of each request
+	Credentials CredentialsProvider
+	Headers     map[string]string
 }
 
-// Int64 returns the timestamp as int64
-func (t Timestamp) Int64() int64 {
-	return int64(t)
+// Creator is the user who created a monitor
+type Creator struct {
+	ID     int    `json:"id,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Email  string `json:"email,omitempty"`
+	Handle string `json:"handle,omitempty"`
 }
 
 // Monitor represents a Datadog monitor
@@ -62,14 +46,28 @@ type Monitor struct {
 	Tags         []string               `json:"tags,omitempty"`
 	Options      map[string]interface{} `json:"options,omitempty"`
 	OverallState string                 `json:"overall_state,omitempty"`
-	CreatedAt    Timestamp              `json:"created_at,omitempty"`
-	Modified     Timestamp              `json:"modified,omitempty"`
+	CreatedAt    Timestamp              `json:"created_at,omitzero"`
+	Modified     Timestamp              `json:"modified,omitzero"`
+	Creator      *Creator               `json:"creator,omitempty"`
+	// Priority is the monitor priority from 1 (highest) to 5, nil when unset
+	Priority *int `json:"priority,omitempty"`
+	// State holds the per-group states, only when fetched with group states
+	State *MonitorState `json:"state,omitempty"`
 }
 
 // TemplateData represents a template structure
 type TemplateData struct {
 	Name   string                 `json:"name"`
 	Config map[string]interface{} `json:"config"`
+	// Disabled templates are validated but never applied
+	Disabled bool `json:"disabled,omitempty"`
+	// AdoptMonitorID is an existing monitor the template updates and takes
+	// over, instead of finding its monitor by identity or name
+	AdoptMonitorID int `json:"adopt_monitor_id,omitempty"`
+	// Description and Params document the template and its variables for
+	// template docs; params with a default don't need a --var
+	Description string                   `json:"description,omitempty"`
+	Params      map[string]TemplateParam `json:"params,omitempty"`
 }
 
 // TemplateFile represents a template file structure
@@ -82,45 +80,82 @@ type TemplateFile struct {
 type Client struct {
 	config *Config
 	client *http.Client
+	cache  *responseCache
+	ctx    context.Context
+	stats  *Stats
+	audit  *audit.Log
+	// inventory is the monitor inventory file mutating requests invalidate
+	inventory string
+	// orgCheck is verified before mutating requests
+	orgCheck *OrgCheck
 }
 
-// NewClient creates a new Datadog API client
-func NewClient() (*Client, error) {
-	apiKey := os.Getenv("DD_API_KEY")
-	if apiKey == "" {
-		apiKey = os.Getenv("DATADOG_API_KEY")
-	}
+// APIKeyFileEnv and AppKeyFileEnv name files holding the keys, read again
+// when they change so keys rotated during a run are picked up; they take
+// precedence over DD_API_KEY and DD_APP_KEY
+const (
+	APIKeyFileEnv = "DD_API_KEY_FILE"
+	AppKeyFileEnv = "DD_APP_KEY_FILE"
+)
 
-	appKey := os.Getenv("DD_APP_KEY")
-	if appKey == "" {
-		appKey = os.Getenv("DATADOG_APP_KEY")
+// APIURLEnv overrides the API v1 base URL of clients created from the
+// environment, e.g. to point commands at a proxy or at a datadogtest server;
+// it takes precedence over the site variables (see SiteEnvs)
+const APIURLEnv = "DDMM_API_URL"
+
+// NewClient creates a new Datadog API client using credentials and the site
+// from the environment (see ResolveEnv). opts are applied after the
+// environment-derived options.
+func NewClient(opts ...Option) (*Client, error) {
+	env, err := ResolveProcessEnv()
+	if err != nil {
+		return nil, err
 	}
+	apiKey, appKey := env.APIKey.Value, env.AppKey.Value
+	apiKeyFile, appKeyFile := env.APIKeyFile.Value, env.AppKeyFile.Value
 
-	if apiKey == "" || appKey == "" {
-		return nil, fmt.Errorf("DD_API_KEY and DD_APP_KEY environment variables required\n\nSet them with:\n  export DD_API_KEY='your-api-key'\n  export DD_APP_KEY='your-app-key'")
+	if (apiKey == "" && apiKeyFile == "") || (appKey == "" && appKeyFile == "") {
+		return nil, fmt.Errorf("DD_API_KEY and DD_APP_KEY environment variables required\n\nSet them with:\n  export DD_API_KEY='your-api-key'\n  export DD_APP_KEY='your-app-key'\n\nor DATADOG_API_KEY and DATADOG_APP_KEY, or point DD_API_KEY_FILE and DD_APP_KEY_FILE at files holding them")
 	}
 
-	config := &Config{
-		APIKey: apiKey,
-		AppKey: appKey,
-		APIURL: "https://api.datadoghq.com/api/v1",
-		Headers: map[string]string{
-			"DD-API-KEY":         apiKey,
-			"DD-APPLICATION-KEY": appKey,
-			"Content-Type":       "application/json",
-		},
+	envOpts := []Option{WithAPIKey(apiKey), WithAppKey(appKey)}
+	if apiKeyFile != "" || appKeyFile != "" {
+		credentials, err := NewFileCredentials(apiKeyFile, appKeyFile, apiKey, appKey)
+		if err != nil {
+			return nil, err
+		}
+		envOpts = append(envOpts, WithCredentials(credentials))
+	}
+	if env.APIURL.Source != "" {
+		envOpts = append(envOpts, WithBaseURL(env.APIURL.Value))
+	}
+	if os.Getenv("DDMM_NO_CACHE") == "" {
+		envOpts = append(envOpts, WithCacheDir(DefaultCacheDir()))
 	}
 
-	return &Client{
-		config: config,
-		client: &http.Client{},
-	}, nil
+	return NewClientWithOptions(append(envOpts, opts...)...)
 }
 
-// makeRequest performs an HTTP request to the Datadog API
-func (c *Client) makeRequest(method, endpoint string, body interface{}) (*http.Response, error) {
-	url := fmt.Sprintf("%s%s", c.config.APIURL, endpoint)
+// newRequest builds an HTTP request to the Datadog API with the configured headers
+func (c *Client) newRequest(method, endpoint string, body interface{}) (*http.Request, error) {
+	return c.newRequestURL(method, fmt.Sprintf("%s%s", c.config.APIURL, endpoint), body)
+}
 
+// newV2Request builds an HTTP request to the Datadog API v2 (e.g., /team)
+func (c *Client) newV2Request(method, endpoint string, body interface{}) (*http.Request, error) {
+	return c.newRequestURL(method, fmt.Sprintf("%s%s", c.config.APIV2URL, endpoint), body)
+}
+
+// apiV2URL derives the API v2 base URL from a v1 base URL
+func apiV2URL(apiURL string) string {
+	if strings.HasSuffix(apiURL, "/v1") {
+		return strings.TrimSuffix(apiURL, "/v1") + "/v2"
+	}
+	return apiURL + "/v2"
+}
+
+// newRequestURL builds an HTTP request to url with the configured headers
+func (c *Client) newRequestURL(method, url string, body interface{}) (*http.Request, error) {
 	var reqBody io.Reader
 	if body != nil {
 		jsonData, err := json.Marshal(body)
@@ -130,7 +165,7 @@ func (c *Client) makeRequest(method, endpoint string, body interface{}) (*http.R
 		reqBody = bytes.NewBuffer(jsonData)
 	}
 
-	req, err := http.NewRequest(method, url, reqBody)
+	req, err := http.NewRequestWithContext(c.ctx, method, url, reqBody)
 	if err != nil {
 		return nil, err
 	}
@@ -138,15 +173,158 @@ func (c *Client) makeRequest(method, endpoint string, body interface{}) (*http.R
 	for key, value := range c.config.Headers {
 		req.Header.Set(key, value)
 	}
+	if err := c.setCredentials(req); err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept-Encoding", "gzip")
 
+	return req, nil
+}
+
+// do sends a request, recording mutating requests in the audit log. Mutating
+// requests also invalidate the monitor inventory, whether they succeed or not,
+// and are refused when the org check fails.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if c.orgCheck != nil && isMutating(req) {
+		if err := c.orgCheck.verify(c); err != nil {
+			return nil, err
+		}
+	}
+	if c.inventory != "" && isMutating(req) {
+		defer ClearInventory(c.inventory)
+	}
+	if c.audit != nil && isMutating(req) {
+		return c.doAudited(req)
+	}
+	return c.send(req)
+}
+
+// send sends a request, decompressing gzip responses and revalidating cached
+// GET responses with their ETag
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	var cacheKey string
+	if c.cache != nil && req.Method == http.MethodGet {
+		cacheKey = c.cache.key(req)
+		c.cache.prepare(req, cacheKey)
+	}
+
+	if c.stats != nil {
+		if err := c.stats.reserve(); err != nil {
+			return nil, err
+		}
+	}
+	started := time.Now()
 	resp, err := c.client.Do(req)
 	if err != nil {
+		if c.stats != nil {
+			c.stats.recordRequest(req, 0, time.Since(started))
+		}
 		return nil, err
 	}
+	if c.stats != nil {
+		c.stats.recordRequest(req, resp.StatusCode, time.Since(started))
+		c.stats.countBody(resp)
+	}
+	if err := decompress(resp); err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusForbidden {
+		// The keys may have been rotated: retry once with them resolved again
+		retry, err := c.credentialsRetry(req)
+		if err != nil || retry != nil {
+			resp.Body.Close()
+		}
+		if err != nil {
+			return nil, err
+		}
+		if retry != nil {
+			if c.stats != nil {
+				c.stats.recordRetry()
+			}
+			return c.send(retry)
+		}
+	}
+
+	if cacheKey != "" {
+		switch resp.StatusCode {
+		case http.StatusNotModified:
+			if !c.cache.serve(resp, cacheKey) {
+				// The cached body vanished; drop the entry and retry without revalidation
+				resp.Body.Close()
+				os.Remove(c.cache.etagPath(cacheKey))
+				req.Header.Del("If-None-Match")
+				if c.stats != nil {
+					c.stats.recordRetry()
+				}
+				return c.do(req)
+			}
+		case http.StatusOK:
+			c.cache.store(resp, cacheKey)
+		}
+	}
 
 	return resp, nil
 }
 
+// interrupted returns the context error once the client's context is cancelled
+// or timed out, or a *LimitError once a limit of its Stats is reached, so bulk
+// operations can stop between monitors
+func (c *Client) interrupted() error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	if c.stats != nil {
+		return c.stats.Exceeded()
+	}
+	return nil
+}
+
+// stopError returns the error that stops a bulk loop after an operation failed
+// with err: the context error once cancelled or timed out, or err itself when
+// the request budget or the org check refused the request. Other failures
+// don't stop the loop.
+func (c *Client) stopError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if ctxErr := c.ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if IsLimitExceeded(err) || IsOrgCheckFailed(err) {
+		return err
+	}
+	return nil
+}
+
+// recordFailure counts a failed operation of a bulk loop against the fail-fast
+// limit; monitors that no longer exist are not failures
+func (c *Client) recordFailure(err error) {
+	if c.stats != nil && err != nil && !IsMonitorNotFound(err) {
+		c.stats.RecordFailure()
+	}
+}
+
+// makeRequest performs an HTTP request to the Datadog API
+func (c *Client) makeRequest(method, endpoint string, body interface{}) (*http.Response, error) {
+	req, err := c.newRequest(method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.do(req)
+}
+
+// makeV2Request performs an HTTP request to the Datadog API v2
+func (c *Client) makeV2Request(method, endpoint string, body interface{}) (*http.Response, error) {
+	req, err := c.newV2Request(method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.do(req)
+}
+
 // CreateMonitor creates a new monitor
 func (c *Client) CreateMonitor(monitor *Monitor) (*Monitor, error) {
 	resp, err := c.makeRequest("POST", "/monitor", monitor)
@@ -170,13 +348,30 @@ func (c *Client) CreateMonitor(monitor *Monitor) (*Monitor, error) {
 
 // UpdateMonitor updates an existing monitor
 func (c *Client) UpdateMonitor(monitorID int, monitor *Monitor) (*Monitor, error) {
+	return c.putMonitor(monitorID, monitor)
+}
+
+// SetMonitorTags replaces the tags of a monitor, leaving everything else as is.
+// Unlike UpdateMonitor it can also remove every tag.
+func (c *Client) SetMonitorTags(monitorID int, tags []string) (*Monitor, error) {
+	if tags == nil {
+		tags = []string{}
+	}
+	return c.putMonitor(monitorID, map[string]interface{}{"tags": tags})
+}
+
+// putMonitor sends a monitor edit; the API only changes the fields in body
+func (c *Client) putMonitor(monitorID int, body interface{}) (*Monitor, error) {
 	endpoint := fmt.Sprintf("/monitor/%d", monitorID)
-	resp, err := c.makeRequest("PUT", endpoint, monitor)
+	resp, err := c.makeRequest("PUT", endpoint, body)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, &ErrMonitorNotFound{ID: monitorID}
+	}
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("failed to update monitor: status %d, body: %s", resp.StatusCode, string(body))
@@ -206,38 +401,137 @@ func (c *Client) FindMonitorByName(name string) (*Monitor, error) {
 	return nil, nil
 }
 
-// UpsertMonitor creates or updates a monitor
-func (c *Client) UpsertMonitor(monitor *Monitor) (*Monitor, bool, error) {
-	existing, err := c.FindMonitorByName(monitor.Name)
+// NameConflictPolicy says what CreateMonitorStrict does when a monitor with
+// the same name already exists
+type NameConflictPolicy int
+
+const (
+	// ConflictFail refuses to create the monitor with a *MonitorExistsError
+	ConflictFail NameConflictPolicy = iota
+	// ConflictAllowDuplicate creates a second monitor with the same name
+	ConflictAllowDuplicate
+	// ConflictSuffix appends the first free " (2)", " (3)", ... to the name
+	ConflictSuffix
+)
+
+// CreateMonitorStrict creates a monitor without ever updating an existing one.
+// When the name is taken, policy decides between failing, creating a duplicate
+// and creating it under a suffixed name.
+func (c *Client) CreateMonitorStrict(monitor *Monitor, policy NameConflictPolicy) (*Monitor, error) {
+	if policy == ConflictAllowDuplicate {
+		return c.CreateMonitor(monitor)
+	}
+
+	monitors, err := c.ListMonitors(nil, "")
 	if err != nil {
-		return nil, false, err
+		return nil, err
+	}
+	names := make(map[string]int, len(monitors))
+	for _, existing := range monitors {
+		if _, ok := names[existing.Name]; !ok {
+			names[existing.Name] = existing.ID
+		}
+	}
+
+	id, taken := names[monitor.Name]
+	if !taken {
+		return c.CreateMonitor(monitor)
+	}
+	if policy != ConflictSuffix {
+		return nil, &MonitorExistsError{ID: id, Name: monitor.Name}
+	}
+
+	suffixed := *monitor
+	for n := 2; ; n++ {
+		suffixed.Name = fmt.Sprintf("%s (%d)", monitor.Name, n)
+		if _, taken := names[suffixed.Name]; !taken {
+			return c.CreateMonitor(&suffixed)
+		}
+	}
+}
+
+// UpsertMonitor creates a monitor, or updates the monitor with the same
+// template-id tag or, failing that, the same name (see findUpsertTarget). The
+// status is StatusCreated, StatusUpdated, StatusAdopted when the updated
+// monitor had no managed-by tag, or StatusUnchanged when the monitor already
+// matches (see DiffDrift) and no update is sent. With protectUnmanaged such a monitor is left
+// alone and an *UnmanagedMonitorError returned with StatusConflict.
+func (c *Client) UpsertMonitor(monitor *Monitor, protectUnmanaged bool) (*Monitor, ResultStatus, error) {
+	updated, status, _, err := c.upsertMonitor(monitor, *monitor, protectUnmanaged, nil)
+	return updated, status, err
+}
+
+// upsertMonitor is UpsertMonitor posting create rather than monitor when no
+// monitor matches, e.g. a muted copy. With byQuery, a monitor matched by
+// neither identity nor name is looked up by query; renamedFrom is then the
+// name it had before the update renamed it back to the template's.
+func (c *Client) upsertMonitor(monitor *Monitor, create Monitor, protectUnmanaged bool, byQuery *queryIndex) (updated *Monitor, status ResultStatus, renamedFrom string, err error) {
+	existing, err := c.findUpsertTarget(*monitor)
+	if err != nil {
+		return nil, StatusFailed, "", err
+	}
+	if existing == nil && byQuery != nil {
+		if existing, err = byQuery.match(*monitor); err != nil {
+			return nil, StatusFailed, "", err
+		}
+		if existing != nil {
+			renamedFrom = existing.Name
+		}
 	}
 
 	if existing != nil {
+		status := StatusUpdated
+		if !IsManaged(*existing) {
+			if protectUnmanaged {
+				return existing, StatusConflict, "", &UnmanagedMonitorError{ID: existing.ID, Name: existing.Name, Creator: existing.Creator}
+			}
+			status = StatusAdopted
+		}
+		if DiffDrift(*monitor, *existing).Empty() {
+			// Updating would change nothing but server-side defaults
+			return existing, StatusUnchanged, renamedFrom, nil
+		}
 		updated, err := c.UpdateMonitor(existing.ID, monitor)
-		return updated, false, err
+		if err != nil {
+			return nil, StatusFailed, "", err
+		}
+		return updated, status, renamedFrom, nil
 	}
 
-	created, err := c.CreateMonitor(monitor)
-	return created, true, err
+	created, err := c.CreateMonitor(&create)
+	if err != nil {
+		return nil, StatusFailed, "", err
+	}
+	return created, StatusCreated, "", nil
 }
 
 // ListMonitors lists existing monitors
 func (c *Client) ListMonitors(tags []string, searchText string) ([]Monitor, error) {
-	endpoint := "/monitor"
-	req, err := http.NewRequest("GET", c.config.APIURL+endpoint, nil)
+	return c.ListMonitorsWithOptions(ListMonitorsOptions{Tags: tags, Search: searchText})
+}
+
+// ListMonitorsOptions are the filters of ListMonitorsWithOptions
+type ListMonitorsOptions struct {
+	// Tags are exact monitor tags; a "!=" prefix excludes the tag
+	Tags []string
+	// Search is free text or a search query
+	Search string
+	// GroupStates fetches the per-group states in Monitor.State for these
+	// states (all, alert, warn, no data)
+	GroupStates []string
+}
+
+// ListMonitorsWithOptions lists monitors with the given filters
+func (c *Client) ListMonitorsWithOptions(opts ListMonitorsOptions) ([]Monitor, error) {
+	req, err := c.newRequest("GET", "/monitor", nil)
 	if err != nil {
 		return nil, err
 	}
 
-	for key, value := range c.config.Headers {
-		req.Header.Set(key, value)
-	}
-
 	q := req.URL.Query()
-	if len(tags) > 0 {
+	if len(opts.Tags) > 0 {
 		var tagList []string
-		for _, tag := range tags {
+		for _, tag := range opts.Tags {
 			if strings.HasPrefix(tag, "!=") {
 				tagList = append(tagList, "!"+strings.TrimPrefix(tag, "!="))
 			} else {
@@ -246,12 +540,15 @@ func (c *Client) ListMonitors(tags []string, searchText string) ([]Monitor, erro
 		}
 		q.Set("monitor_tags", strings.Join(tagList, ","))
 	}
-	if searchText != "" {
-		q.Set("query", searchText)
+	if opts.Search != "" {
+		q.Set("query", opts.Search)
+	}
+	if len(opts.GroupStates) > 0 {
+		q.Set("group_states", strings.Join(opts.GroupStates, ","))
 	}
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -262,8 +559,31 @@ func (c *Client) ListMonitors(tags []string, searchText string) ([]Monitor, erro
 		return nil, fmt.Errorf("failed to list monitors: status %d, body: %s", resp.StatusCode, string(body))
 	}
 
+	return decodeMonitorList(resp.Body)
+}
+
+// decodeMonitorList decodes a JSON array of monitors one element at a time so
+// the whole response body is never buffered in memory
+func decodeMonitorList(r io.Reader) ([]Monitor, error) {
+	decoder := json.NewDecoder(r)
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, fmt.Errorf("failed to list monitors: expected a JSON array, got %v", token)
+	}
+
 	var monitors []Monitor
-	if err := json.NewDecoder(resp.Body).Decode(&monitors); err != nil {
+	for decoder.More() {
+		var monitor Monitor
+		if err := decoder.Decode(&monitor); err != nil {
+			return nil, err
+		}
+		monitors = append(monitors, monitor)
+	}
+
+	if _, err := decoder.Token(); err != nil {
 		return nil, err
 	}
 
@@ -272,13 +592,31 @@ func (c *Client) ListMonitors(tags []string, searchText string) ([]Monitor, erro
 
 // GetMonitor gets detailed monitor information
 func (c *Client) GetMonitor(monitorID int) (*Monitor, error) {
-	endpoint := fmt.Sprintf("/monitor/%d", monitorID)
-	resp, err := c.makeRequest("GET", endpoint, nil)
+	return c.GetMonitorWithGroupStates(monitorID, nil)
+}
+
+// GetMonitorWithGroupStates retrieves a monitor with the per-group states in
+// Monitor.State for the given states (all, alert, warn, no data)
+func (c *Client) GetMonitorWithGroupStates(monitorID int, groupStates []string) (*Monitor, error) {
+	req, err := c.newRequest("GET", fmt.Sprintf("/monitor/%d", monitorID), nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(groupStates) > 0 {
+		q := req.URL.Query()
+		q.Set("group_states", strings.Join(groupStates, ","))
+		req.URL.RawQuery = q.Encode()
+	}
+
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, &ErrMonitorNotFound{ID: monitorID}
+	}
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("failed to get monitor: status %d, body: %s", resp.StatusCode, string(body))
@@ -301,6 +639,9 @@ func (c *Client) DeleteMonitor(monitorID int) error {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return &ErrMonitorNotFound{ID: monitorID}
+	}
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		return fmt.Errorf("failed to delete monitor: status %d, body: %s", resp.StatusCode, string(body))
@@ -309,8 +650,73 @@ func (c *Client) DeleteMonitor(monitorID int) error {
 	return nil
 }
 
+// MuteMonitor mutes a monitor. An empty scope mutes all groups; a scope such as
+// pod:checkout-7f9 mutes only the matching groups. A zero end mutes indefinitely.
+func (c *Client) MuteMonitor(monitorID int, scope string, end time.Time) (*Monitor, error) {
+	body := map[string]interface{}{}
+	if scope != "" {
+		body["scope"] = scope
+	}
+	if !end.IsZero() {
+		body["end"] = end.Unix()
+	}
+
+	endpoint := fmt.Sprintf("/monitor/%d/mute", monitorID)
+	resp, err := c.makeRequest("POST", endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, &ErrMonitorNotFound{ID: monitorID}
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to mute monitor: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var monitor Monitor
+	if err := json.NewDecoder(resp.Body).Decode(&monitor); err != nil {
+		return nil, err
+	}
+
+	return &monitor, nil
+}
+
+// UnmuteMonitor unmutes a monitor. An empty scope clears every mute of the
+// monitor (all scopes); otherwise only the mute of that scope is removed.
+func (c *Client) UnmuteMonitor(monitorID int, scope string) (*Monitor, error) {
+	body := map[string]interface{}{"all_scopes": true}
+	if scope != "" {
+		body = map[string]interface{}{"scope": scope}
+	}
+
+	endpoint := fmt.Sprintf("/monitor/%d/unmute", monitorID)
+	resp, err := c.makeRequest("POST", endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, &ErrMonitorNotFound{ID: monitorID}
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to unmute monitor: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var monitor Monitor
+	if err := json.NewDecoder(resp.Body).Decode(&monitor); err != nil {
+		return nil, err
+	}
+
+	return &monitor, nil
+}
+
 // DeleteMonitorsByFilter deletes all monitors matching the specified filters
-func (c *Client) DeleteMonitorsByFilter(service, env, namespace string, tags []string) ([]map[string]interface{}, error) {
+func (c *Client) DeleteMonitorsByFilter(service, env, namespace string, tags []string) ([]DeleteResult, error) {
 	monitors, err := c.ListMonitors(tags, "")
 	if err != nil {
 		return nil, err
@@ -366,157 +772,197 @@ func (c *Client) DeleteMonitorsByFilter(service, env, namespace string, tags []s
 		}
 	}
 
-	// Delete each matching monitor
-	var results []map[string]interface{}
-	for _, monitor := range filteredMonitors {
-		err := c.DeleteMonitor(monitor.ID)
-		if err != nil {
-			results = append(results, map[string]interface{}{
-				"id":     monitor.ID,
-				"name":   monitor.Name,
-				"status": fmt.Sprintf("failed: %v", err),
-			})
-		} else {
-			results = append(results, map[string]interface{}{
-				"id":     monitor.ID,
-				"name":   monitor.Name,
-				"status": "deleted",
-			})
-		}
-	}
+	return c.DeleteMonitors(filteredMonitors)
+}
 
-	return results, nil
+// DeleteMonitors deletes the given monitors one by one, stopping with the
+// results so far on interruption
+func (c *Client) DeleteMonitors(monitors []Monitor) ([]DeleteResult, error) {
+	return c.DeleteMonitorsWithCheckpoint(monitors, nil)
 }
 
-// LoadTemplateFromJSON loads monitor templates from JSON file
+// LoadTemplateFromJSON loads monitor templates from JSON file and validates
+// them against the monitor template schema
 func LoadTemplateFromJSON(templateFile string) ([]TemplateData, error) {
+	return LoadTemplates(templateFile, true)
+}
+
+// LoadTemplates loads monitor templates from JSON file. With validate, every
+// template is checked against the monitor template schema and all violations
+// are reported together. "log", "service_check" and "apm" blocks are compiled
+// into log, service check and APM trace metric monitor queries.
+func LoadTemplates(templateFile string, validate bool) ([]TemplateData, error) {
+	templates, _, err := LoadSelectedTemplates(templateFile, validate, TemplateSelection{})
+	return templates, err
+}
+
+// LoadSelectedTemplates is LoadTemplates for the templates a selection
+// includes (disabled ones among them). The templates it excludes are
+// returned apart, without being validated; when --skip matches the file name
+// the file isn't even parsed.
+func LoadSelectedTemplates(templateFile string, validate bool, selection TemplateSelection) ([]TemplateData, []SkippedTemplate, error) {
+	if selection.skipsFile(templateFile) {
+		return nil, skippedFile(templateFile), nil
+	}
+
 	data, err := os.ReadFile(templateFile)
 	if err != nil {
-		return nil, fmt.Errorf("template file not found: %s", templateFile)
+		return nil, nil, fmt.Errorf("template file not found: %s", templateFile)
+	}
+
+	templates, single, err := parseTemplates(templateFile, data)
+	if err != nil {
+		return nil, nil, err
 	}
 
+	indexes, skipped := selectTemplates(templateFile, templates, selection)
+	excluded := make(map[int]bool)
+	for i := range templates {
+		excluded[i] = true
+	}
+	selected := make([]TemplateData, 0, len(indexes))
+	for _, i := range indexes {
+		excluded[i] = false
+		selected = append(selected, templates[i])
+	}
+
+	if validate {
+		if err := validateTemplates(templateFile, templates, single, excluded); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := compileLogBlocks(templateFile, selected); err != nil {
+		return nil, nil, err
+	}
+	if err := compileServiceCheckBlocks(templateFile, selected); err != nil {
+		return nil, nil, err
+	}
+	if err := compileAPMBlocks(templateFile, selected); err != nil {
+		return nil, nil, err
+	}
+
+	return selected, skipped, nil
+}
+
+// parseTemplates parses a template file, which holds either a templates array
+// or a single monitor template. single reports the latter.
+func parseTemplates(templateFile string, data []byte) ([]TemplateData, bool, error) {
 	var templateFileData TemplateFile
 	if err := json.Unmarshal(data, &templateFileData); err != nil {
 		// Try as single template
 		var singleTemplate map[string]interface{}
 		if err := json.Unmarshal(data, &singleTemplate); err != nil {
-			return nil, fmt.Errorf("invalid JSON in template file %s: %v", templateFile, err)
+			return nil, false, fmt.Errorf("invalid JSON in template file %s: %v", templateFile, err)
 		}
-		return []TemplateData{
-			{Name: "Single Template", Config: singleTemplate},
-		}, nil
+		template, err := singleTemplateData(templateFile, data, singleTemplate)
+		if err != nil {
+			return nil, false, err
+		}
+		return []TemplateData{template}, true, nil
 	}
 
 	if len(templateFileData.Templates) > 0 {
-		return templateFileData.Templates, nil
+		return templateFileData.Templates, false, nil
 	}
 
 	// If no templates array, treat the whole file as a single template
 	var singleTemplate map[string]interface{}
 	if err := json.Unmarshal(data, &singleTemplate); err != nil {
-		return nil, fmt.Errorf("invalid JSON in template file %s: %v", templateFile, err)
+		return nil, false, fmt.Errorf("invalid JSON in template file %s: %v", templateFile, err)
+	}
+	template, err := singleTemplateData(templateFile, data, singleTemplate)
+	if err != nil {
+		return nil, false, err
 	}
-	return []TemplateData{
-		{Name: "Single Template", Config: singleTemplate},
-	}, nil
+	return []TemplateData{template}, true, nil
 }
 
-// CustomizeTemplate customizes a template with service-specific values
-func CustomizeTemplate(template map[string]interface{}, service, env, namespace string, additionalTags []string) map[string]interface{} {
-	customized := make(map[string]interface{})
-	for k, v := range template {
-		customized[k] = v
-	}
-
-	// Replace placeholders in name
-	if name, ok := customized["name"].(string); ok {
-		customized["name"] = strings.ReplaceAll(
-			strings.ReplaceAll(
-				strings.ReplaceAll(name, "{service}", service),
-				"{env}", strings.ToUpper(env)),
-			"{namespace}", namespace)
-	}
-
-	// Replace placeholders in query
-	if query, ok := customized["query"].(string); ok {
-		// Preserve "by {service}" literally
-		query = strings.ReplaceAll(query, "by {service}", "by __SERVICE_PRESERVE__")
-		query = strings.ReplaceAll(query, "{service}", service)
-		query = strings.ReplaceAll(query, "__SERVICE_PRESERVE__", "{service}")
-		query = strings.ReplaceAll(query, "{env}", env)
-		query = strings.ReplaceAll(query, "{namespace}", namespace)
-		customized["query"] = query
-	}
-
-	// Replace placeholders in message
-	if message, ok := customized["message"].(string); ok {
-		customized["message"] = strings.ReplaceAll(
-			strings.ReplaceAll(
-				strings.ReplaceAll(message, "{service}", service),
-				"{env}", env),
-			"{namespace}", namespace)
-	}
-
-	// Add/update tags
-	var tags []string
-	if existingTags, ok := customized["tags"].([]interface{}); ok {
-		for _, tag := range existingTags {
-			if tagStr, ok := tag.(string); ok {
-				tags = append(tags, tagStr)
-			}
+// singleTemplateData wraps a single monitor template file, moving its
+// adopt_monitor_id, description and params out of the monitor definition.
+// adopt_monitor_id is decoded from the file data as an integer: the float64
+// of config loses the low digits of IDs above 2^53 and accepts fractions.
+func singleTemplateData(templateFile string, data []byte, config map[string]interface{}) (TemplateData, error) {
+	template := TemplateData{Name: "Single Template", Config: config}
+	if _, ok := config["adopt_monitor_id"]; ok {
+		var adopt struct {
+			AdoptMonitorID int `json:"adopt_monitor_id"`
+		}
+		if err := json.Unmarshal(data, &adopt); err != nil {
+			return TemplateData{}, fmt.Errorf("invalid adopt_monitor_id in template file %s: %v", templateFile, err)
 		}
+		template.AdoptMonitorID = adopt.AdoptMonitorID
+		delete(config, "adopt_monitor_id")
 	}
+	takeTemplateMetadata(&template)
+	return template, nil
+}
 
-	// Add service-specific tags
-	serviceTags := []string{
-		fmt.Sprintf("service:%s", service),
-		fmt.Sprintf("env:%s", env),
-		fmt.Sprintf("namespace:%s", namespace),
-	}
+// CustomizeTemplate customizes a template with service-specific values
+func CustomizeTemplate(template map[string]interface{}, service, env, namespace string, additionalTags []string) map[string]interface{} {
+	return RenderTemplate(template, RenderOptions{
+		Service:        service,
+		Env:            env,
+		Namespace:      namespace,
+		AdditionalTags: additionalTags,
+	})
+}
 
-	for _, tag := range serviceTags {
-		found := false
-		for _, existingTag := range tags {
-			if existingTag == tag {
-				found = true
-				break
-			}
-		}
-		if !found {
-			tags = append(tags, tag)
-		}
-	}
+// ApplyTemplate applies monitor templates from JSON file
+func (c *Client) ApplyTemplate(templateFile, service, env, namespace string, upsert bool, additionalTags []string) ([]ApplyResult, error) {
+	return c.ApplyTemplateWithOptions(templateFile, ApplyOptions{
+		RenderOptions: RenderOptions{
+			Service:        service,
+			Env:            env,
+			Namespace:      namespace,
+			AdditionalTags: additionalTags,
+		},
+		Upsert: upsert,
+	})
+}
 
-	// Add additional tags
-	for _, tag := range additionalTags {
-		found := false
-		for _, existingTag := range tags {
-			if existingTag == tag {
-				found = true
-				break
-			}
-		}
-		if !found {
-			tags = append(tags, tag)
-		}
-	}
+// RenderedMonitor is a monitor rendered from a template, before it is applied
+type RenderedMonitor struct {
+	TemplateName string
+	Monitor      Monitor
+	// NameOverflow describes how a name over MaxMonitorNameLength was shortened
+	NameOverflow string
+	// K8sDefaults are the options set by ApplyOptions.K8sDefaults
+	K8sDefaults []string
+	// ScaledThresholds are the thresholds ApplyOptions.ThresholdScale changed or skipped
+	ScaledThresholds []ScaledThreshold
+	// NotifyByDropped are the options.notify_by values ApplyOptions.FixNotifyBy dropped
+	NotifyByDropped []string
+	// TagsReplaced are the template tags additional tags of single-valued
+	// keys replaced
+	TagsReplaced []TagReplacement
+	// AdoptMonitorID is the template's adopt_monitor_id
+	AdoptMonitorID int
+}
 
-	customized["tags"] = tags
-	return customized
+// RenderTemplateFile loads a template file and renders every template in it
+// into a monitor tagged with ManagedByTag, its template-id and its
+// fingerprint, without calling the API. Disabled
+// templates and those excluded by opts.Selection are left out.
+func RenderTemplateFile(templateFile string, opts ApplyOptions) ([]RenderedMonitor, error) {
+	rendered, _, err := RenderSelectedTemplates(templateFile, opts)
+	return rendered, err
 }
 
-// ApplyTemplate applies monitor templates from JSON file
-func (c *Client) ApplyTemplate(templateFile, service, env, namespace string, upsert bool, additionalTags []string) ([]map[string]interface{}, error) {
-	templates, err := LoadTemplateFromJSON(templateFile)
+// RenderSelectedTemplates is RenderTemplateFile that also returns the
+// templates it left out, with the reason
+func RenderSelectedTemplates(templateFile string, opts ApplyOptions) ([]RenderedMonitor, []SkippedTemplate, error) {
+	templates, skipped, err := LoadSelectedTemplates(templateFile, !opts.SkipSchemaValidation, opts.Selection)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	var results []map[string]interface{}
+	var rendered []RenderedMonitor
 	for _, templateData := range templates {
-		templateName := templateData.Name
-		if templateName == "" {
-			templateName = "Unknown Template"
+		templateName := templateDisplayName(templateData)
+		if templateData.Disabled {
+			skipped = append(skipped, SkippedTemplate{TemplateName: templateName, Reason: SkipReasonDisabled})
+			continue
 		}
 
 		templateConfig := templateData.Config
@@ -525,64 +971,163 @@ func (c *Client) ApplyTemplate(templateFile, service, env, namespace string, ups
 			templateBytes, _ := json.Marshal(templateData)
 			json.Unmarshal(templateBytes, &templateConfig)
 		}
+		templateConfig = applyPresetOptions(templateConfig, opts.PresetOptions)
+
+		// Fill the variables without a --var from the defaults of the params
+		renderOpts := opts.RenderOptions
+		if renderOpts.Vars, err = templateVars(templateData, opts.Vars); err != nil {
+			return nil, nil, err
+		}
 
 		// Customize the template
-		customizedTemplate := CustomizeTemplate(templateConfig, service, env, namespace, additionalTags)
+		customizedTemplate, replacedTags := renderTemplate(templateConfig, renderOpts)
 
 		// Convert to Monitor
 		monitorBytes, err := json.Marshal(customizedTemplate)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		var monitor Monitor
 		if err := json.Unmarshal(monitorBytes, &monitor); err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+
+		name, note, err := FitMonitorName(monitor.Name, opts.Service, opts.NameOverflow)
+		if err != nil {
+			return nil, nil, fmt.Errorf("template %s: %w", templateName, err)
+		}
+		monitor.Name = name
+
+		var injected []string
+		if opts.K8sDefaults != nil {
+			injected = ApplyK8sDefaults(&monitor, *opts.K8sDefaults)
+		}
+		var scaled []ScaledThreshold
+		if opts.ThresholdScale > 0 && opts.ThresholdScale != 1 {
+			scaled = ScaleThresholds(&monitor, opts.ThresholdScale)
+		}
+
+		// notify_by must name group-by values, which placeholders can break
+		var dropped []string
+		if opts.FixNotifyBy {
+			dropped = FixNotifyBy(&monitor)
+		} else if err := CheckNotifyBy(monitor); err != nil {
+			return nil, nil, fmt.Errorf("template %s: %w", templateName, err)
+		}
+
+		addManagedByTag(&monitor)
+		addTemplateIDTag(&monitor, TemplateIdentity(templateFile, templateData.Name, opts.Vars))
+		addFingerprintTag(&monitor)
+		rendered = append(rendered, RenderedMonitor{TemplateName: templateName, Monitor: monitor, NameOverflow: note, K8sDefaults: injected, ScaledThresholds: scaled, NotifyByDropped: dropped, TagsReplaced: replacedTags, AdoptMonitorID: templateData.AdoptMonitorID})
+	}
+
+	return rendered, skipped, nil
+}
+
+// ApplyTemplateWithOptions applies monitor templates from JSON file
+func (c *Client) ApplyTemplateWithOptions(templateFile string, opts ApplyOptions) ([]ApplyResult, error) {
+	rendered, skipped, err := RenderSelectedTemplates(templateFile, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []ApplyResult
+	for _, s := range skipped {
+		results = append(results, ApplyResult{TemplateName: s.TemplateName, Status: StatusSkipped, SkipReason: s.Reason})
+	}
+	var byQuery *queryIndex
+	if opts.MatchByQuery {
+		byQuery = &queryIndex{client: c}
+	}
+	for _, r := range rendered {
+		if err := c.interrupted(); err != nil {
+			return results, err
 		}
 
-		// Create or update the monitor
+		templateName := r.TemplateName
+		monitor := r.Monitor
+
+		// Catch hardcoded scopes such as env:dev in a template applied to prd
+		scopeWarnings := CheckQueryScope(monitor.Query, map[string]string{
+			"env":     ResolveEnvAlias(opts.Env, opts.EnvAliases),
+			"service": opts.Service,
+		})
+		if len(scopeWarnings) > 0 && opts.StrictScope {
+			return results, &ScopeMismatchError{TemplateName: templateName, Mismatches: scopeWarnings}
+		}
+
+		// Create or update the monitor. Upserts find a monitor created by an
+		// earlier run by identity; creates look for its fingerprint.
 		var result *Monitor
-		var wasCreated bool
-		if upsert {
-			result, wasCreated, err = c.UpsertMonitor(&monitor)
-		} else {
-			result, err = c.CreateMonitor(&monitor)
-			wasCreated = true
+		status := StatusCreated
+		redundant := false
+		renamedFrom := ""
+		create := opts.forCreate(monitor)
+		if r.AdoptMonitorID != 0 {
+			result, status, redundant, err = c.adoptMonitor(r.AdoptMonitorID, &monitor)
+		} else if opts.Upsert {
+			result, status, renamedFrom, err = c.upsertMonitor(&monitor, create, opts.ProtectUnmanaged, byQuery)
+		} else if result, err = c.findCreated(monitor); err == nil {
+			if result != nil {
+				status = StatusExisting
+			} else {
+				result, err = c.CreateMonitorStrict(&create, opts.OnNameConflict)
+			}
 		}
 
+		var adoptErr *AdoptError
+		var queryErr *QueryMatchError
+		if errors.As(err, &adoptErr) || errors.As(err, &queryErr) {
+			// Only this template fails; carry on with the others
+			results = append(results, ApplyResult{TemplateName: templateName, ID: r.AdoptMonitorID, Name: monitor.Name, Status: StatusFailed, AdoptMonitorID: r.AdoptMonitorID, Err: err})
+			continue
+		}
+		if status == StatusConflict {
+			// Leave the unmanaged monitor alone and carry on with the other templates
+			results = append(results, ApplyResult{
+				TemplateName: templateName,
+				ID:           result.ID,
+				Name:         result.Name,
+				Status:       StatusConflict,
+				Creator:      result.Creator,
+				Err:          err,
+			})
+			continue
+		}
 		if err != nil {
-			return nil, fmt.Errorf("failed to apply %s: %v", templateName, err)
+			// Return what was applied so far alongside the error
+			return results, fmt.Errorf("failed to apply %s: %w", templateName, err)
 		}
 
-		resultMap := map[string]interface{}{
-			"template_name": templateName,
-			"id":            result.ID,
-			"was_created":   wasCreated,
-		}
-		results = append(results, resultMap)
+		results = append(results, ApplyResult{
+			TemplateName:    templateName,
+			ID:              result.ID,
+			Name:            result.Name,
+			Status:          status,
+			NameOverflow:    r.NameOverflow,
+			K8sDefaults:     r.K8sDefaults,
+			NotifyByDropped: r.NotifyByDropped,
+			TagsReplaced:    r.TagsReplaced,
+			ScopeWarnings:   scopeWarnings,
+			Muted:           status == StatusCreated && opts.CreateMuted,
+			AdoptMonitorID:  r.AdoptMonitorID,
+			AdoptRedundant:  redundant,
+			RenamedFrom:     renamedFrom,
+		})
 	}
 
 	return results, nil
 }
 
 // CheckMonitorsExist checks which monitors from template already exist
-func (c *Client) CheckMonitorsExist(templateFile, service, env, namespace string) (map[string]interface{}, error) {
+func (c *Client) CheckMonitorsExist(templateFile, service, env, namespace string) ([]TemplateMonitorCheck, error) {
 	templates, err := LoadTemplateFromJSON(templateFile)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(templates) == 0 {
-		return map[string]interface{}{
-			"total":    0,
-			"existing": []interface{}{},
-			"missing":  []interface{}{},
-		}, nil
-	}
-
-	var existing []map[string]interface{}
-	var missing []map[string]interface{}
-
+	var checks []TemplateMonitorCheck
 	for _, templateData := range templates {
 		templateName := templateData.Name
 		if templateName == "" {
@@ -603,96 +1148,99 @@ func (c *Client) CheckMonitorsExist(templateFile, service, env, namespace string
 			return nil, err
 		}
 
+		check := TemplateMonitorCheck{TemplateName: templateName, MonitorName: monitorName}
 		if existingMonitor != nil {
-			status := "enabled"
-			if existingMonitor.OverallState == "No Data" {
-				status = "no_data"
-			}
-			existing = append(existing, map[string]interface{}{
-				"template_name": templateName,
-				"monitor_name":  monitorName,
-				"monitor_id":    existingMonitor.ID,
-				"status":        status,
-			})
-		} else {
-			missing = append(missing, map[string]interface{}{
-				"template_name": templateName,
-				"monitor_name":  monitorName,
-			})
+			check.Exists = true
+			check.MonitorID = existingMonitor.ID
+			check.State = existingMonitor.OverallState
 		}
+		checks = append(checks, check)
 	}
 
-	return map[string]interface{}{
-		"total":    len(templates),
-		"existing": existing,
-		"missing":  missing,
-	}, nil
+	return checks, nil
 }
 
-// AddTagsToMonitor adds tags to a monitor
-func (c *Client) AddTagsToMonitor(monitorID int, tagsToAdd []string) (*Monitor, error) {
+// AddTagsToMonitor adds tags to a monitor. The monitor is only updated when
+// it lacks one of the tags; the returned bool reports that.
+func (c *Client) AddTagsToMonitor(monitorID int, tagsToAdd []string) (*Monitor, bool, error) {
 	// Get current monitor
 	monitor, err := c.GetMonitor(monitorID)
 	if err != nil {
-		return nil, err
+		return nil, false, err
+	}
+
+	missing := MissingTags(*monitor, tagsToAdd)
+	if len(missing) == 0 {
+		return monitor, false, nil
 	}
+	monitor.Tags = append(monitor.Tags, missing...)
 
-	// Merge tags (avoid duplicates)
+	// Update monitor
+	updatedMonitor, err := c.UpdateMonitor(monitorID, monitor)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return updatedMonitor, true, nil
+}
+
+// MissingTags returns the tags, without duplicates, a monitor doesn't have
+func MissingTags(monitor Monitor, tags []string) []string {
 	existingTags := make(map[string]bool)
 	for _, tag := range monitor.Tags {
 		existingTags[tag] = true
 	}
 
-	for _, tag := range tagsToAdd {
+	var missing []string
+	for _, tag := range tags {
 		if !existingTags[tag] {
-			monitor.Tags = append(monitor.Tags, tag)
+			missing = append(missing, tag)
+			existingTags[tag] = true
 		}
 	}
+	return missing
+}
 
-	// Update monitor
-	updatedMonitor, err := c.UpdateMonitor(monitorID, monitor)
+// RemoveTagsFromMonitor removes tags from a monitor. The monitor is only
+// updated when it has one of the tags; the returned bool reports that.
+func (c *Client) RemoveTagsFromMonitor(monitorID int, tagsToRemove []string) (*Monitor, bool, error) {
+	matcher, err := NewTagMatcher(tagsToRemove, false)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
-
-	return updatedMonitor, nil
+	return c.RemoveMatchingTags(monitorID, matcher)
 }
 
-// RemoveTagsFromMonitor removes tags from a monitor
-func (c *Client) RemoveTagsFromMonitor(monitorID int, tagsToRemove []string) (*Monitor, error) {
+// RemoveMatchingTags removes the tags selected by matcher from a monitor. The
+// monitor is only updated when a tag matches; the returned bool reports that.
+func (c *Client) RemoveMatchingTags(monitorID int, matcher *TagMatcher) (*Monitor, bool, error) {
 	// Get current monitor
 	monitor, err := c.GetMonitor(monitorID)
 	if err != nil {
-		return nil, err
-	}
-
-	// Create a map of tags to remove for quick lookup
-	tagsToRemoveMap := make(map[string]bool)
-	for _, tag := range tagsToRemove {
-		tagsToRemoveMap[tag] = true
+		return nil, false, err
 	}
 
 	// Filter out tags to remove
 	var newTags []string
 	for _, tag := range monitor.Tags {
-		if !tagsToRemoveMap[tag] {
+		if !matcher.Match(tag) {
 			newTags = append(newTags, tag)
 		}
 	}
+	if len(newTags) == len(monitor.Tags) {
+		return monitor, false, nil
+	}
 
-	monitor.Tags = newTags
-
-	// Update monitor
-	updatedMonitor, err := c.UpdateMonitor(monitorID, monitor)
+	// Only the tags are sent, so removing every tag works too
+	updated, err := c.SetMonitorTags(monitorID, newTags)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
-
-	return updatedMonitor, nil
+	return updated, true, nil
 }
 
 // AddTagsToMonitors adds tags to multiple monitors matching filters
-func (c *Client) AddTagsToMonitors(service, env, namespace string, tags []string, tagsToAdd []string) ([]map[string]interface{}, error) {
+func (c *Client) AddTagsToMonitors(service, env, namespace string, tags []string, tagsToAdd []string) ([]TagUpdateResult, error) {
 	// Find monitors matching filters
 	monitors, err := c.ListMonitors(tags, "")
 	if err != nil {
@@ -750,30 +1298,24 @@ func (c *Client) AddTagsToMonitors(service, env, namespace string, tags []string
 	}
 
 	// Add tags to each monitor
-	var results []map[string]interface{}
+	var results []TagUpdateResult
 	for _, monitor := range filteredMonitors {
-		updated, err := c.AddTagsToMonitor(monitor.ID, tagsToAdd)
-		if err != nil {
-			results = append(results, map[string]interface{}{
-				"id":     monitor.ID,
-				"name":   monitor.Name,
-				"status": fmt.Sprintf("failed: %v", err),
-			})
-		} else {
-			results = append(results, map[string]interface{}{
-				"id":     updated.ID,
-				"name":   updated.Name,
-				"status": "updated",
-				"tags":   updated.Tags,
-			})
+		if err := c.interrupted(); err != nil {
+			return results, err
 		}
+		updated, changed, err := c.AddTagsToMonitor(monitor.ID, tagsToAdd)
+		if stopErr := c.stopError(err); stopErr != nil {
+			return results, stopErr
+		}
+		results = append(results, NewTagUpdateResult(monitor, updated, changed, err))
+		c.recordFailure(err)
 	}
 
 	return results, nil
 }
 
 // RemoveTagsFromMonitors removes tags from multiple monitors matching filters
-func (c *Client) RemoveTagsFromMonitors(service, env, namespace string, tags []string, tagsToRemove []string) ([]map[string]interface{}, error) {
+func (c *Client) RemoveTagsFromMonitors(service, env, namespace string, tags []string, tagsToRemove []string) ([]TagUpdateResult, error) {
 	// Find monitors matching filters
 	monitors, err := c.ListMonitors(tags, "")
 	if err != nil {
@@ -831,23 +1373,17 @@ func (c *Client) RemoveTagsFromMonitors(service, env, namespace string, tags []s
 	}
 
 	// Remove tags from each monitor
-	var results []map[string]interface{}
+	var results []TagUpdateResult
 	for _, monitor := range filteredMonitors {
-		updated, err := c.RemoveTagsFromMonitor(monitor.ID, tagsToRemove)
-		if err != nil {
-			results = append(results, map[string]interface{}{
-				"id":     monitor.ID,
-				"name":   monitor.Name,
-				"status": fmt.Sprintf("failed: %v", err),
-			})
-		} else {
-			results = append(results, map[string]interface{}{
-				"id":     updated.ID,
-				"name":   updated.Name,
-				"status": "updated",
-				"tags":   updated.Tags,
-			})
+		if err := c.interrupted(); err != nil {
+			return results, err
+		}
+		updated, changed, err := c.RemoveTagsFromMonitor(monitor.ID, tagsToRemove)
+		if stopErr := c.stopError(err); stopErr != nil {
+			return results, stopErr
 		}
+		results = append(results, NewTagUpdateResult(monitor, updated, changed, err))
+		c.recordFailure(err)
 	}
 
 	return results, nil
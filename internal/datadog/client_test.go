@@ -0,0 +1,231 @@
+package datadog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// headerRecorder is an API stub recording the headers of the requests it gets
+type headerRecorder struct {
+	mu      sync.Mutex
+	headers []http.Header
+	paths   []string
+}
+
+func (h *headerRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	h.headers = append(h.headers, r.Header.Clone())
+	h.paths = append(h.paths, r.URL.Path)
+	h.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"id": 1, "name": "CPU", "type": "metric alert", "query": "avg(last_5m):avg:cpu{*} > 90"}`))
+}
+
+// countingTransport counts the requests sent through it
+type countingTransport struct {
+	mu    sync.Mutex
+	count int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.count++
+	t.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestClientHeaders(t *testing.T) {
+	recorder := &headerRecorder{}
+	srv := httptest.NewServer(recorder)
+	defer srv.Close()
+	transport := &countingTransport{}
+
+	client, err := NewClientWithOptions(
+		WithAPIKey("api-key"),
+		WithAppKey("app-key"),
+		WithBaseURL(srv.URL+"/api/v1/"),
+		WithHTTPClient(&http.Client{Transport: transport}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetMonitor(1); err != nil {
+		t.Fatalf("GetMonitor: %v", err)
+	}
+	if _, err := client.UpdateMonitor(1, &Monitor{Name: "CPU", Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90"}); err != nil {
+		t.Fatalf("UpdateMonitor: %v", err)
+	}
+
+	if transport.count != 2 {
+		t.Errorf("%d request(s) through the HTTP client, want 2", transport.count)
+	}
+	// The trailing slash of the base URL is dropped
+	if len(recorder.paths) != 2 || recorder.paths[0] != "/api/v1/monitor/1" {
+		t.Fatalf("paths %v, want /api/v1/monitor/1", recorder.paths)
+	}
+	for i, header := range recorder.headers {
+		for key, want := range map[string]string{
+			"DD-API-KEY":         "api-key",
+			"DD-APPLICATION-KEY": "app-key",
+			"Content-Type":       "application/json",
+			"Accept-Encoding":    "gzip",
+			"User-Agent":         DefaultUserAgent(),
+		} {
+			if got := header.Get(key); got != want {
+				t.Errorf("request %d: %s = %q, want %q", i+1, key, got, want)
+			}
+		}
+	}
+}
+
+func TestClientUserAgent(t *testing.T) {
+	recorder := &headerRecorder{}
+	srv := httptest.NewServer(recorder)
+	defer srv.Close()
+
+	client, err := NewClientWithOptions(WithAPIKey("api-key"), WithAppKey("app-key"), WithBaseURL(srv.URL), WithUserAgent("ci-bot/2.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetMonitor(1); err != nil {
+		t.Fatalf("GetMonitor: %v", err)
+	}
+	if got := recorder.headers[0].Get("User-Agent"); got != "ci-bot/2.0" {
+		t.Errorf("User-Agent %q, want ci-bot/2.0", got)
+	}
+}
+
+func TestClientGzipResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte(`{"id": 7, "name": "Compressed"}`))
+		gz.Close()
+	}))
+	defer srv.Close()
+
+	client, err := NewClientWithOptions(WithAPIKey("api-key"), WithAppKey("app-key"), WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	monitor, err := client.GetMonitor(7)
+	if err != nil {
+		t.Fatalf("GetMonitor: %v", err)
+	}
+	if monitor.Name != "Compressed" {
+		t.Errorf("name %q, want Compressed", monitor.Name)
+	}
+}
+
+func TestNewClientWithOptionsRequiresKeys(t *testing.T) {
+	for _, opts := range [][]Option{
+		nil,
+		{WithAPIKey("api-key")},
+		{WithAppKey("app-key")},
+	} {
+		if _, err := NewClientWithOptions(opts...); err == nil {
+			t.Errorf("NewClientWithOptions with %d key(s) succeeded", len(opts))
+		}
+	}
+	if _, err := NewClientWithOptions(WithCredentials(StaticCredentials{APIKey: "api-key", AppKey: "app-key"})); err != nil {
+		t.Errorf("NewClientWithOptions with a credentials provider: %v", err)
+	}
+}
+
+// monitorListFixture returns the JSON of a list of n synthetic monitors
+func monitorListFixture(t testing.TB, n int) []byte {
+	t.Helper()
+	monitors := make([]Monitor, n)
+	for i := range monitors {
+		service := fmt.Sprintf("service-%d", i%200)
+		monitors[i] = Monitor{
+			ID:           1000 + i,
+			Name:         fmt.Sprintf("[prd] %s error rate %d", service, i),
+			Type:         "query alert",
+			Query:        fmt.Sprintf("sum(last_5m):sum:trace.http.request.errors{env:prd,service:%s}.as_count() > %d", service, i%50+1),
+			Message:      "Error rate is high {{#is_alert}}@slack-alerts{{/is_alert}}",
+			Tags:         []string{"env:prd", "service:" + service, "team:sre", "managed-by:datadog-monitor-manager"},
+			Options:      map[string]interface{}{"thresholds": map[string]interface{}{"critical": i%50 + 1}, "notify_no_data": false, "renotify_interval": 60},
+			OverallState: "OK",
+			CreatedAt:    Timestamp{time: time.Unix(1700000000+int64(i), 0)},
+			Creator:      &Creator{ID: 1, Email: "sre@example.com"},
+		}
+	}
+	data, err := json.Marshal(monitors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestDecodeMonitorList(t *testing.T) {
+	data := monitorListFixture(t, 50)
+	monitors, err := decodeMonitorList(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []Monitor
+	if err := json.Unmarshal(data, &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(monitors, want) {
+		t.Error("streamed monitors differ from the decoded array")
+	}
+
+	if monitors, err := decodeMonitorList(strings.NewReader(`[]`)); err != nil || len(monitors) != 0 {
+		t.Errorf("empty list = %v, %v", monitors, err)
+	}
+	for _, body := range []string{``, `{"errors": ["Forbidden"]}`, `[{"id": 1}`, `[{"id": "one"}]`} {
+		if _, err := decodeMonitorList(strings.NewReader(body)); err == nil {
+			t.Errorf("decodeMonitorList(%q) succeeded", body)
+		}
+	}
+}
+
+func BenchmarkDecodeMonitorList(b *testing.B) {
+	data := monitorListFixture(b, 10000)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		monitors, err := decodeMonitorList(bytes.NewReader(data))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(monitors) != 10000 {
+			b.Fatalf("%d monitors, want 10000", len(monitors))
+		}
+	}
+}
+
+// BenchmarkDecodeMonitorListBuffered is the baseline of
+// BenchmarkDecodeMonitorList: the whole body read, then decoded at once
+func BenchmarkDecodeMonitorListBuffered(b *testing.B) {
+	data := monitorListFixture(b, 10000)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		body, err := io.ReadAll(bytes.NewReader(data))
+		if err != nil {
+			b.Fatal(err)
+		}
+		var monitors []Monitor
+		if err := json.Unmarshal(body, &monitors); err != nil {
+			b.Fatal(err)
+		}
+		if len(monitors) != 10000 {
+			b.Fatalf("%d monitors, want 10000", len(monitors))
+		}
+	}
+}
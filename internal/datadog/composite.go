@@ -0,0 +1,203 @@
+package datadog
+
+import "sort"
+
+// CompositeType is the type of composite monitors
+const CompositeType = "composite"
+
+// CompositeRefs returns the IDs of the monitors a composite query references,
+// in order of first appearance, e.g. [12345 67890] for "12345 && !67890"
+func CompositeRefs(query string) []int {
+	var ids []int
+	seen := make(map[int]bool)
+	for i := 0; i < len(query); {
+		if !isDigit(query[i]) {
+			i++
+			continue
+		}
+		start := i
+		id := 0
+		for i < len(query) && isDigit(query[i]) {
+			id = id*10 + int(query[i]-'0')
+			i++
+		}
+		// Digits inside a word are not an ID
+		if (start > 0 && isWordChar(query[start-1])) || (i < len(query) && isWordChar(query[i])) {
+			continue
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isWordChar(c byte) bool {
+	return isDigit(c) || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '.'
+}
+
+// ReferenceIndex maps monitor IDs to the composite monitors referencing them
+type ReferenceIndex map[int][]Monitor
+
+// NewReferenceIndex indexes the references of the composites among monitors
+func NewReferenceIndex(monitors []Monitor) ReferenceIndex {
+	index := make(ReferenceIndex)
+	for _, monitor := range monitors {
+		if monitor.Type != CompositeType {
+			continue
+		}
+		for _, id := range CompositeRefs(monitor.Query) {
+			index[id] = append(index[id], monitor)
+		}
+	}
+	for id := range index {
+		composites := index[id]
+		sort.Slice(composites, func(i, j int) bool { return composites[i].ID < composites[j].ID })
+	}
+	return index
+}
+
+// ReferencedBy returns the composites referencing a monitor, sorted by ID
+func (index ReferenceIndex) ReferencedBy(monitorID int) []Monitor {
+	return index[monitorID]
+}
+
+// CompositeReferences lists every monitor and indexes the composite references
+func (c *Client) CompositeReferences() (ReferenceIndex, error) {
+	monitors, err := c.ListMonitors(nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return NewReferenceIndex(monitors), nil
+}
+
+// DependencyGraph holds monitors and the monitors their composites reference
+type DependencyGraph struct {
+	// Monitors are the monitors of the graph by ID; referenced monitors that
+	// could not be fetched are missing
+	Monitors map[int]Monitor
+	// Children are the monitor IDs each composite references
+	Children map[int][]int
+}
+
+// NewDependencyGraph builds the graph of monitors, which must include the
+// monitors their composites reference
+func NewDependencyGraph(monitors []Monitor) *DependencyGraph {
+	graph := &DependencyGraph{Monitors: make(map[int]Monitor, len(monitors)), Children: make(map[int][]int)}
+	for _, monitor := range monitors {
+		graph.Monitors[monitor.ID] = monitor
+		if monitor.Type == CompositeType {
+			graph.Children[monitor.ID] = CompositeRefs(monitor.Query)
+		}
+	}
+	return graph
+}
+
+// MissingRefs returns the referenced monitor IDs the graph has no monitor
+// for, sorted
+func (g *DependencyGraph) MissingRefs() []int {
+	var missing []int
+	seen := make(map[int]bool)
+	for _, children := range g.Children {
+		for _, id := range children {
+			if _, ok := g.Monitors[id]; !ok && !seen[id] {
+				seen[id] = true
+				missing = append(missing, id)
+			}
+		}
+	}
+	sort.Ints(missing)
+	return missing
+}
+
+// Roots returns the IDs of the composites no other composite of the graph
+// references, sorted. Composites only reachable through a cycle are roots
+// as well, so every composite appears in the tree.
+func (g *DependencyGraph) Roots() []int {
+	referenced := make(map[int]bool)
+	for _, children := range g.Children {
+		for _, id := range children {
+			referenced[id] = true
+		}
+	}
+	var roots []int
+	for id := range g.Children {
+		if !referenced[id] {
+			roots = append(roots, id)
+		}
+	}
+	sort.Ints(roots)
+
+	// Composites in a cycle no root reaches
+	reached := make(map[int]bool)
+	var walk func(id int)
+	walk = func(id int) {
+		if reached[id] {
+			return
+		}
+		reached[id] = true
+		for _, child := range g.Children[id] {
+			walk(child)
+		}
+	}
+	for _, id := range roots {
+		walk(id)
+	}
+	var rest []int
+	for id := range g.Children {
+		if !reached[id] {
+			rest = append(rest, id)
+		}
+	}
+	sort.Ints(rest)
+	for _, id := range rest {
+		if !reached[id] {
+			roots = append(roots, id)
+			walk(id)
+		}
+	}
+	return roots
+}
+
+// Cycles returns every reference cycle among the composites, each as the IDs
+// along the cycle starting from its lowest ID, sorted by that ID. A composite
+// referencing itself is a cycle of one.
+func (g *DependencyGraph) Cycles() [][]int {
+	ids := make([]int, 0, len(g.Children))
+	for id := range g.Children {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	// Each cycle is found once, from its lowest ID, going through higher IDs
+	// only
+	var cycles [][]int
+	for _, start := range ids {
+		path := []int{start}
+		onPath := map[int]bool{start: true}
+		var visit func(id int)
+		visit = func(id int) {
+			for _, child := range g.Children[id] {
+				if child == start {
+					cycles = append(cycles, append([]int(nil), path...))
+					continue
+				}
+				if _, composite := g.Children[child]; !composite || child < start || onPath[child] {
+					continue
+				}
+				path = append(path, child)
+				onPath[child] = true
+				visit(child)
+				path = path[:len(path)-1]
+				delete(onPath, child)
+			}
+		}
+		visit(start)
+	}
+	return cycles
+}
@@ -0,0 +1,167 @@
+package datadog
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCompositeRefs(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		want  []int
+	}{
+		{"12345 && !67890", []int{12345, 67890}},
+		{"(1 || 2) && 3", []int{1, 2, 3}},
+		{"!(1&&2)||3", []int{1, 2, 3}},
+		// Repeated references are listed once, at their first appearance
+		{"3 && (1 || 3) && !1", []int{3, 1}},
+		{"  42  ", []int{42}},
+		// Digits inside words or decimals are not IDs
+		{"a1 && 2b && c_3 && 4.5 && v1.2 && 6", []int{6}},
+		{"", nil},
+		{"&& || !", nil},
+	} {
+		if got := CompositeRefs(tc.query); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("CompositeRefs(%q) = %v, want %v", tc.query, got, tc.want)
+		}
+	}
+}
+
+// compositeGraph builds a graph from composites given as "id: query" and
+// plain monitors given as IDs
+func compositeGraph(composites []string, monitors ...int) *DependencyGraph {
+	var all []Monitor
+	for _, composite := range composites {
+		id, query, _ := strings.Cut(composite, ": ")
+		n, _ := strconv.Atoi(id)
+		all = append(all, Monitor{ID: n, Type: CompositeType, Query: query})
+	}
+	for _, id := range monitors {
+		all = append(all, Monitor{ID: id, Type: "metric alert"})
+	}
+	return NewDependencyGraph(all)
+}
+
+func TestDependencyGraph(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		composites []string
+		monitors   []int
+		roots      []int
+		cycles     [][]int
+		missing    []int
+	}{
+		{
+			name:       "flat",
+			composites: []string{"10: 1 && 2"},
+			monitors:   []int{1, 2},
+			roots:      []int{10},
+		},
+		{
+			name:       "nested",
+			composites: []string{"10: 11 && 1", "11: 12 || 2", "12: 3 && !4"},
+			monitors:   []int{1, 2, 3, 4},
+			roots:      []int{10},
+		},
+		{
+			name:       "shared child",
+			composites: []string{"10: 12 && 1", "11: 12 && 2", "12: 3"},
+			monitors:   []int{1, 2, 3},
+			roots:      []int{10, 11},
+		},
+		{
+			name:       "self-reference",
+			composites: []string{"10: 10 && 1"},
+			monitors:   []int{1},
+			roots:      []int{10},
+			cycles:     [][]int{{10}},
+		},
+		{
+			name:       "two-node cycle",
+			composites: []string{"10: 11", "11: 10 && 1"},
+			monitors:   []int{1},
+			roots:      []int{10},
+			cycles:     [][]int{{10, 11}},
+		},
+		{
+			name:       "three-node cycle under a root",
+			composites: []string{"5: 12", "10: 11", "11: 12", "12: 10 || 1"},
+			monitors:   []int{1},
+			roots:      []int{5},
+			cycles:     [][]int{{10, 11, 12}},
+		},
+		{
+			// 10 -> 11 -> 12 -> 10 and 10 -> 12 -> 10 share the edge 12 -> 10
+			name:       "cycles sharing edges",
+			composites: []string{"10: 11 && 12", "11: 12", "12: 10"},
+			roots:      []int{10},
+			cycles:     [][]int{{10, 11, 12}, {10, 12}},
+		},
+		{
+			// 13 is only on the cycle 10 -> 13 -> 11 -> 12 -> 10
+			name:       "cycle through a finished branch",
+			composites: []string{"10: 11 && 13", "11: 12", "12: 10", "13: 11"},
+			roots:      []int{10},
+			cycles:     [][]int{{10, 11, 12}, {10, 13, 11, 12}},
+		},
+		{
+			name:       "separate cycles",
+			composites: []string{"10: 11", "11: 10", "20: 20"},
+			roots:      []int{10, 20},
+			cycles:     [][]int{{10, 11}, {20}},
+		},
+		{
+			name:       "missing monitors",
+			composites: []string{"10: 1 && 99", "11: 98 || 99 || 10"},
+			monitors:   []int{1},
+			roots:      []int{11},
+			missing:    []int{98, 99},
+		},
+		{
+			name:     "no composites",
+			monitors: []int{1, 2},
+		},
+	} {
+		graph := compositeGraph(tc.composites, tc.monitors...)
+		if roots := graph.Roots(); !reflect.DeepEqual(roots, tc.roots) {
+			t.Errorf("%s: Roots() = %v, want %v", tc.name, roots, tc.roots)
+		}
+		if cycles := graph.Cycles(); !reflect.DeepEqual(cycles, tc.cycles) {
+			t.Errorf("%s: Cycles() = %v, want %v", tc.name, cycles, tc.cycles)
+		}
+		if missing := graph.MissingRefs(); !reflect.DeepEqual(missing, tc.missing) {
+			t.Errorf("%s: MissingRefs() = %v, want %v", tc.name, missing, tc.missing)
+		}
+	}
+}
+
+func TestReferenceIndex(t *testing.T) {
+	index := NewReferenceIndex([]Monitor{
+		{ID: 20, Type: CompositeType, Query: "1 && 2"},
+		{ID: 10, Type: CompositeType, Query: "1 || 20"},
+		{ID: 1, Type: "metric alert", Query: "avg(last_5m):avg:cpu{host:a1} > 2"},
+	})
+	ids := func(monitors []Monitor) []int {
+		var ids []int
+		for _, monitor := range monitors {
+			ids = append(ids, monitor.ID)
+		}
+		return ids
+	}
+	for _, tc := range []struct {
+		id   int
+		want []int
+	}{
+		{1, []int{10, 20}},
+		{2, []int{20}},
+		{20, []int{10}},
+		// Queries of other monitor types are not references
+		{5, nil},
+	} {
+		if got := ids(index.ReferencedBy(tc.id)); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("ReferencedBy(%d) = %v, want %v", tc.id, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package datadog
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CredentialsProvider supplies the authentication headers of every request,
+// so credentials can change during a run (keys rotated by Vault, short-lived
+// scoped keys, ...) without recreating the client. The headers are usually
+// DD-API-KEY and DD-APPLICATION-KEY; a provider may send an Authorization
+// header instead.
+type CredentialsProvider interface {
+	// Headers returns the authentication headers of the next request
+	Headers() (map[string]string, error)
+	// Refresh resolves the credentials again after the API rejected them
+	// (403) and reports whether they changed, i.e. whether the request is
+	// worth retrying with them
+	Refresh() (bool, error)
+}
+
+// StaticCredentials are an API key and an application key that never change
+type StaticCredentials struct {
+	APIKey string
+	AppKey string
+}
+
+// Headers returns the key headers
+func (s StaticCredentials) Headers() (map[string]string, error) {
+	return keyHeaders(s.APIKey, s.AppKey), nil
+}
+
+// Refresh never changes static keys
+func (s StaticCredentials) Refresh() (bool, error) {
+	return false, nil
+}
+
+// keyHeaders returns the headers authenticating with an API and an
+// application key
+func keyHeaders(apiKey, appKey string) map[string]string {
+	return map[string]string{
+		"DD-API-KEY":         apiKey,
+		"DD-APPLICATION-KEY": appKey,
+	}
+}
+
+// FileCredentials reads the keys from files, such as the ones a Vault agent
+// renders, and reads a file again whenever its modification time or size
+// changes, so a key rotated mid-run is picked up by the next request. A key
+// without a file is the fixed APIKey or AppKey.
+type FileCredentials struct {
+	APIKeyFile string
+	AppKeyFile string
+	APIKey     string
+	AppKey     string
+
+	mu     sync.Mutex
+	apiKey keyFile
+	appKey keyFile
+}
+
+// keyFile is the last key read from a file, with the file's state then
+type keyFile struct {
+	value    string
+	modified time.Time
+	size     int64
+}
+
+// NewFileCredentials reads the key files once, failing when one can't be
+// read or is empty. An empty path uses the fixed key instead.
+func NewFileCredentials(apiKeyFile, appKeyFile, apiKey, appKey string) (*FileCredentials, error) {
+	f := &FileCredentials{APIKeyFile: apiKeyFile, AppKeyFile: appKeyFile, APIKey: apiKey, AppKey: appKey}
+	if _, err := f.Refresh(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Headers returns the key headers, reading the files that changed since
+// they were last read
+func (f *FileCredentials) Headers() (map[string]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.read(false); err != nil {
+		return nil, err
+	}
+	return keyHeaders(f.apiKey.value, f.appKey.value), nil
+}
+
+// Refresh reads the key files again, changed or not: a file rewritten
+// within the same second with a key of the same length looks unchanged
+func (f *FileCredentials) Refresh() (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.read(true)
+}
+
+// read reads the key files (only the changed ones unless force) and
+// reports whether a key changed
+func (f *FileCredentials) read(force bool) (bool, error) {
+	apiChanged, err := readKeyFile(&f.apiKey, f.APIKeyFile, f.APIKey, force)
+	if err != nil {
+		return false, err
+	}
+	appChanged, err := readKeyFile(&f.appKey, f.AppKeyFile, f.AppKey, force)
+	if err != nil {
+		return false, err
+	}
+	return apiChanged || appChanged, nil
+}
+
+// readKeyFile updates key from path (or to fixed without a path) and reports
+// whether its value changed
+func readKeyFile(key *keyFile, path, fixed string, force bool) (bool, error) {
+	if path == "" {
+		changed := key.value != fixed
+		key.value = fixed
+		return changed, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read key file: %v", err)
+	}
+	if !force && key.value != "" && info.ModTime().Equal(key.modified) && info.Size() == key.size {
+		return false, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read key file: %v", err)
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return false, fmt.Errorf("key file %s is empty", path)
+	}
+	changed := key.value != value
+	*key = keyFile{value: value, modified: info.ModTime(), size: info.Size()}
+	return changed, nil
+}
+
+// setCredentials sets the authentication headers of a request
+func (c *Client) setCredentials(req *http.Request) error {
+	headers, err := c.config.Credentials.Headers()
+	if err != nil {
+		return err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	return nil
+}
+
+// credentialsRetried marks the context of a request already retried with
+// refreshed credentials
+type credentialsRetried struct{}
+
+// credentialsRetry returns req again, with its credentials resolved again,
+// after the API rejected it with a 403. It returns nil when the request was
+// already retried or the credentials didn't change, so the 403 stands.
+func (c *Client) credentialsRetry(req *http.Request) (*http.Request, error) {
+	if req.Context().Value(credentialsRetried{}) != nil {
+		return nil, nil
+	}
+	changed, err := c.config.Credentials.Refresh()
+	if err != nil {
+		return nil, fmt.Errorf("the API rejected the credentials (status 403) and resolving them again failed: %v", err)
+	}
+	if !changed {
+		return nil, nil
+	}
+
+	retry := req.Clone(context.WithValue(req.Context(), credentialsRetried{}, true))
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+	retry.Header.Del("If-None-Match")
+	if err := c.setCredentials(retry); err != nil {
+		return nil, err
+	}
+	return retry, nil
+}
@@ -0,0 +1,103 @@
+package datadog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Duplicate cluster reasons
+const (
+	DuplicateByQuery = "query"
+	DuplicateByName  = "name"
+)
+
+// DuplicateCluster is a group of monitors considered duplicates of each other
+type DuplicateCluster struct {
+	Reason   string    // DuplicateByQuery or DuplicateByName
+	Key      string    // Normalized type+query, or the exact name
+	Monitors []Monitor // Most recently modified first
+}
+
+// Keep returns the monitor to keep: the most recently modified one
+func (c DuplicateCluster) Keep() Monitor {
+	return c.Monitors[0]
+}
+
+// Duplicates returns the monitors that would be removed when fixing the cluster
+func (c DuplicateCluster) Duplicates() []Monitor {
+	return c.Monitors[1:]
+}
+
+// NormalizeQuery returns the query with all whitespace removed, so queries
+// that only differ in spacing compare equal
+func NormalizeQuery(query string) string {
+	return strings.Join(strings.Fields(query), "")
+}
+
+// FindDuplicateClusters groups monitors by normalized type+query and,
+// separately, by exact name. Only groups with more than one monitor are
+// returned, query clusters first, each sorted by key.
+func FindDuplicateClusters(monitors []Monitor) []DuplicateCluster {
+	byQuery := make(map[string][]Monitor)
+	byName := make(map[string][]Monitor)
+	for _, monitor := range monitors {
+		if monitor.Query != "" {
+			key := fmt.Sprintf("%s|%s", monitor.Type, NormalizeQuery(monitor.Query))
+			byQuery[key] = append(byQuery[key], monitor)
+		}
+		byName[monitor.Name] = append(byName[monitor.Name], monitor)
+	}
+
+	var clusters []DuplicateCluster
+	clusters = append(clusters, buildClusters(DuplicateByQuery, byQuery)...)
+	clusters = append(clusters, buildClusters(DuplicateByName, byName)...)
+	return clusters
+}
+
+// buildClusters turns groups with more than one monitor into sorted clusters
+func buildClusters(reason string, groups map[string][]Monitor) []DuplicateCluster {
+	var keys []string
+	for key, group := range groups {
+		if len(group) > 1 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	clusters := make([]DuplicateCluster, 0, len(keys))
+	for _, key := range keys {
+		group := append([]Monitor(nil), groups[key]...)
+		sort.SliceStable(group, func(i, j int) bool {
+			if !group[i].Modified.Equal(group[j].Modified) {
+				return group[j].Modified.Before(group[i].Modified)
+			}
+			return group[i].ID > group[j].ID
+		})
+		clusters = append(clusters, DuplicateCluster{Reason: reason, Key: key, Monitors: group})
+	}
+	return clusters
+}
+
+// DuplicatesToRemove returns the monitors to remove when fixing the clusters.
+// A monitor kept in any cluster is never removed, even if it is a duplicate in
+// another cluster.
+func DuplicatesToRemove(clusters []DuplicateCluster) []Monitor {
+	keep := make(map[int]bool)
+	for _, cluster := range clusters {
+		keep[cluster.Keep().ID] = true
+	}
+
+	seen := make(map[int]bool)
+	var remove []Monitor
+	for _, cluster := range clusters {
+		for _, monitor := range cluster.Duplicates() {
+			if keep[monitor.ID] || seen[monitor.ID] {
+				continue
+			}
+			seen[monitor.ID] = true
+			remove = append(remove, monitor)
+		}
+	}
+	return remove
+}
@@ -0,0 +1,97 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// loadMonitorFixture reads a list of monitors from testdata
+func loadMonitorFixture(t *testing.T, name string) []Monitor {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var monitors []Monitor
+	if err := json.Unmarshal(data, &monitors); err != nil {
+		t.Fatal(err)
+	}
+	return monitors
+}
+
+// clusterIDs returns the IDs of a cluster in order
+func clusterIDs(cluster DuplicateCluster) string {
+	ids := make([]string, len(cluster.Monitors))
+	for i, monitor := range cluster.Monitors {
+		ids[i] = fmt.Sprint(monitor.ID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestFindDuplicateClusters(t *testing.T) {
+	clusters := FindDuplicateClusters(loadMonitorFixture(t, "duplicate-monitors.json"))
+
+	var got []string
+	for _, cluster := range clusters {
+		got = append(got, fmt.Sprintf("%s %s: %s", cluster.Reason, cluster.Key, clusterIDs(cluster)))
+	}
+	want := []string{
+		// Whitespace doesn't matter; most recently modified first, then by
+		// highest ID; the query of another env is not a duplicate
+		`query log alert|logs("status:error").index("*").rollup("count").last("5m")>10: 8,7`,
+		"query metric alert|avg(last_5m):avg:cpu{env:prd}>90: 2,1,3",
+		// Monitors without a query are only compared by name; names are
+		// case-sensitive and only exact names match
+		"name CPU high: 4,1",
+		"name Composite: 9,10",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("clusters:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	// Monitor 5 (query alert) and 6 (metric alert) have different types
+	for _, cluster := range clusters {
+		if strings.Contains(cluster.Key, "disk") {
+			t.Errorf("monitors of different types clustered: %s", clusterIDs(cluster))
+		}
+	}
+}
+
+func TestDuplicatesToRemove(t *testing.T) {
+	clusters := FindDuplicateClusters(loadMonitorFixture(t, "duplicate-monitors.json"))
+	var ids []string
+	for _, monitor := range DuplicatesToRemove(clusters) {
+		ids = append(ids, fmt.Sprint(monitor.ID))
+	}
+	// In cluster order; monitor 1, a duplicate in two clusters, is listed once
+	if got := strings.Join(ids, ","); got != "7,1,3,10" {
+		t.Errorf("removed %s, want 7,1,3,10", got)
+	}
+
+	// A monitor kept in one cluster survives being a duplicate in another
+	monitors := []Monitor{
+		{ID: 1, Name: "A", Type: "metric alert", Query: "q > 1"},
+		{ID: 2, Name: "B", Type: "metric alert", Query: "q > 1"},
+		{ID: 3, Name: "B", Type: "metric alert", Query: "r > 1"},
+	}
+	clusters = FindDuplicateClusters(monitors)
+	if len(clusters) != 2 || clusters[0].Keep().ID != 2 || clusters[1].Keep().ID != 3 {
+		t.Fatalf("clusters %+v", clusters)
+	}
+	removed := DuplicatesToRemove(clusters)
+	if len(removed) != 1 || removed[0].ID != 1 {
+		t.Errorf("removed %+v, want only monitor 1", removed)
+	}
+}
+
+func TestFindDuplicateClustersNone(t *testing.T) {
+	if clusters := FindDuplicateClusters(nil); len(clusters) != 0 {
+		t.Errorf("clusters of no monitors: %+v", clusters)
+	}
+	single := []Monitor{{ID: 1, Name: "A", Type: "metric alert", Query: "q > 1"}}
+	if clusters := FindDuplicateClusters(single); len(clusters) != 0 {
+		t.Errorf("clusters of one monitor: %+v", clusters)
+	}
+}
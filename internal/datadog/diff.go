@@ -0,0 +1,106 @@
+package datadog
+
+import (
+	"reflect"
+	"sort"
+)
+
+// FieldChange is a field whose value differs between two monitors
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// MonitorDiff lists the differences between two monitors. Options are
+// compared leaf by leaf, with nested keys joined by dots (e.g. thresholds.critical).
+type MonitorDiff struct {
+	Fields      []FieldChange `json:"fields"`
+	TagsAdded   []string      `json:"tags_added"`
+	TagsRemoved []string      `json:"tags_removed"`
+	Options     []FieldChange `json:"options"`
+}
+
+// Empty reports whether the monitors are identical in every compared field
+func (d MonitorDiff) Empty() bool {
+	return len(d.Fields) == 0 && len(d.TagsAdded) == 0 && len(d.TagsRemoved) == 0 && len(d.Options) == 0
+}
+
+// DiffMonitors compares the definition of two monitors: name, type, query,
+// message, priority, tags (order-insensitive) and options. IDs, state and timestamps are ignored.
+func DiffMonitors(from, to Monitor) MonitorDiff {
+	diff := MonitorDiff{Fields: []FieldChange{}, TagsAdded: []string{}, TagsRemoved: []string{}, Options: []FieldChange{}}
+
+	for _, field := range []struct {
+		name     string
+		from, to string
+	}{
+		{"name", from.Name, to.Name},
+		{"type", from.Type, to.Type},
+		{"query", from.Query, to.Query},
+		{"message", from.Message, to.Message},
+	} {
+		if field.from != field.to {
+			diff.Fields = append(diff.Fields, FieldChange{Field: field.name, From: field.from, To: field.to})
+		}
+	}
+	if !priorityEqual(from.Priority, to.Priority) {
+		diff.Fields = append(diff.Fields, FieldChange{Field: "priority", From: priorityValue(from.Priority), To: priorityValue(to.Priority)})
+	}
+
+	fromTags := make(map[string]bool)
+	for _, tag := range from.Tags {
+		fromTags[tag] = true
+	}
+	toTags := make(map[string]bool)
+	for _, tag := range to.Tags {
+		toTags[tag] = true
+		if !fromTags[tag] {
+			diff.TagsAdded = append(diff.TagsAdded, tag)
+		}
+	}
+	for _, tag := range from.Tags {
+		if !toTags[tag] {
+			diff.TagsRemoved = append(diff.TagsRemoved, tag)
+		}
+	}
+	sort.Strings(diff.TagsAdded)
+	sort.Strings(diff.TagsRemoved)
+
+	fromOptions := flattenOptions(from.Options, "", map[string]interface{}{})
+	toOptions := flattenOptions(to.Options, "", map[string]interface{}{})
+	keys := make(map[string]bool)
+	for key := range fromOptions {
+		keys[key] = true
+	}
+	for key := range toOptions {
+		keys[key] = true
+	}
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+	for _, key := range sortedKeys {
+		if !reflect.DeepEqual(fromOptions[key], toOptions[key]) {
+			diff.Options = append(diff.Options, FieldChange{Field: key, From: fromOptions[key], To: toOptions[key]})
+		}
+	}
+
+	return diff
+}
+
+// flattenOptions collects the leaf values of nested option maps under dotted keys
+func flattenOptions(options map[string]interface{}, prefix string, leaves map[string]interface{}) map[string]interface{} {
+	for key, value := range options {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenOptions(nested, key, leaves)
+			continue
+		}
+		leaves[key] = value
+	}
+	return leaves
+}
@@ -0,0 +1,88 @@
+package datadog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DisabledTag marks monitors disabled with the disable command, so they can be
+// found with list --tags status:disabled
+const DisabledTag = "status:disabled"
+
+// DisabledAtTagKey is the key of the tag recording when a monitor was disabled
+// (disabled_at:<unix seconds>)
+const DisabledAtTagKey = "disabled_at"
+
+// IsDisabled reports whether a monitor carries the disabled marker tag
+func IsDisabled(monitor Monitor) bool {
+	for _, tag := range monitor.Tags {
+		if tag == DisabledTag {
+			return true
+		}
+	}
+	return false
+}
+
+// DisabledSince returns when a monitor was disabled, from its disabled_at tag
+func DisabledSince(monitor Monitor) (time.Time, bool) {
+	for _, tag := range monitor.Tags {
+		value, ok := strings.CutPrefix(tag, DisabledAtTagKey+":")
+		if !ok {
+			continue
+		}
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.Unix(seconds, 0), true
+	}
+	return time.Time{}, false
+}
+
+// withoutDisabledTags returns tags without the disabled marker and timestamp
+func withoutDisabledTags(tags []string) []string {
+	var kept []string
+	for _, tag := range tags {
+		if tag == DisabledTag || strings.HasPrefix(tag, DisabledAtTagKey+":") {
+			continue
+		}
+		kept = append(kept, tag)
+	}
+	return kept
+}
+
+// DisableMonitor mutes a monitor with no end time and tags it with the
+// disabled marker and the time it was disabled
+func (c *Client) DisableMonitor(monitorID int, at time.Time) (*Monitor, error) {
+	if _, err := c.MuteMonitor(monitorID, "", time.Time{}); err != nil {
+		return nil, err
+	}
+
+	monitor, err := c.GetMonitor(monitorID)
+	if err != nil {
+		return nil, err
+	}
+
+	monitor.Tags = append(withoutDisabledTags(monitor.Tags),
+		DisabledTag,
+		fmt.Sprintf("%s:%d", DisabledAtTagKey, at.Unix()))
+
+	return c.UpdateMonitor(monitorID, monitor)
+}
+
+// EnableMonitor unmutes a monitor and removes the disabled marker tags
+func (c *Client) EnableMonitor(monitorID int) (*Monitor, error) {
+	if _, err := c.UnmuteMonitor(monitorID, ""); err != nil {
+		return nil, err
+	}
+
+	monitor, err := c.GetMonitor(monitorID)
+	if err != nil {
+		return nil, err
+	}
+
+	monitor.Tags = withoutDisabledTags(monitor.Tags)
+	return c.UpdateMonitor(monitorID, monitor)
+}
@@ -0,0 +1,262 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DowntimeRecurrence represents the repeat rule of a downtime
+type DowntimeRecurrence struct {
+	Type      string   `json:"type" yaml:"type"`
+	Period    int      `json:"period,omitempty" yaml:"period,omitempty"`
+	WeekDays  []string `json:"week_days,omitempty" yaml:"week_days,omitempty"`
+	UntilDate int64    `json:"until_date,omitempty" yaml:"until_date,omitempty"`
+}
+
+// Downtime represents a Datadog downtime (maintenance window)
+type Downtime struct {
+	ID          int                 `json:"id,omitempty"`
+	Scope       []string            `json:"scope"`
+	MonitorID   int                 `json:"monitor_id,omitempty"`
+	MonitorTags []string            `json:"monitor_tags,omitempty"`
+	Start       int64               `json:"start,omitempty"`
+	End         int64               `json:"end,omitempty"`
+	Timezone    string              `json:"timezone,omitempty"`
+	Message     string              `json:"message,omitempty"`
+	Recurrence  *DowntimeRecurrence `json:"recurrence,omitempty"`
+	Active      bool                `json:"active,omitempty"`
+	Disabled    bool                `json:"disabled,omitempty"`
+}
+
+// ListDowntimes lists downtimes; with currentOnly only active ones are returned
+func (c *Client) ListDowntimes(currentOnly bool) ([]Downtime, error) {
+	endpoint := "/downtime"
+	if currentOnly {
+		endpoint += "?current_only=true"
+	}
+	resp, err := c.makeRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to list downtimes: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var downtimes []Downtime
+	if err := json.NewDecoder(resp.Body).Decode(&downtimes); err != nil {
+		return nil, err
+	}
+
+	return downtimes, nil
+}
+
+// CreateDowntime schedules a new downtime
+func (c *Client) CreateDowntime(downtime *Downtime) (*Downtime, error) {
+	resp, err := c.makeRequest("POST", "/downtime", downtime)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to create downtime: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var result Downtime
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// UpdateDowntime updates an existing downtime
+func (c *Client) UpdateDowntime(downtimeID int, downtime *Downtime) (*Downtime, error) {
+	endpoint := fmt.Sprintf("/downtime/%d", downtimeID)
+	resp, err := c.makeRequest("PUT", endpoint, downtime)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to update downtime: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var result Downtime
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// CancelDowntime cancels a downtime
+func (c *Client) CancelDowntime(downtimeID int) error {
+	resp, err := c.makeRequest("DELETE", fmt.Sprintf("/downtime/%d", downtimeID), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to cancel downtime: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// downtimeKey returns what identifies a downtime across runs: the "[name]"
+// prefix BuildDowntime gives its message, or the whole message without one
+func downtimeKey(message string) string {
+	if !strings.HasPrefix(message, "[") {
+		return message
+	}
+	if i := strings.Index(message, "] "); i >= 0 {
+		return message[:i+1]
+	}
+	return message
+}
+
+// findDowntime returns the non-disabled downtime with the same key as
+// message (see downtimeKey), so a downtime whose message text changed is
+// still found by its name, or nil
+func (c *Client) findDowntime(message string) (*Downtime, error) {
+	downtimes, err := c.ListDowntimes(false)
+	if err != nil {
+		return nil, err
+	}
+
+	key := downtimeKey(message)
+	for _, existing := range downtimes {
+		if existing.Disabled {
+			continue
+		}
+		if existing.Message == key || strings.HasPrefix(existing.Message, key+" ") {
+			return &existing, nil
+		}
+	}
+	return nil, nil
+}
+
+// UpsertDowntime creates or updates a downtime, matching non-disabled
+// downtimes by the "[name]" prefix of their message (by their exact message
+// for downtimes without one)
+func (c *Client) UpsertDowntime(downtime *Downtime) (*Downtime, bool, error) {
+	existing, err := c.findDowntime(downtime.Message)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if existing != nil {
+		updated, err := c.UpdateDowntime(existing.ID, downtime)
+		return updated, false, err
+	}
+
+	created, err := c.CreateDowntime(downtime)
+	return created, true, err
+}
+
+// DowntimeIndex finds the active downtimes silencing a monitor
+type DowntimeIndex struct {
+	downtimes []Downtime
+}
+
+// NewDowntimeIndex indexes the downtimes active at now: not disabled, started
+// (a zero start started on creation) and not ended yet (a zero end never ends)
+func NewDowntimeIndex(downtimes []Downtime, now time.Time) *DowntimeIndex {
+	index := &DowntimeIndex{}
+	for _, downtime := range downtimes {
+		if downtime.Disabled || !downtime.Active {
+			continue
+		}
+		if downtime.Start != 0 && downtime.Start > now.Unix() {
+			continue
+		}
+		if downtime.End != 0 && downtime.End <= now.Unix() {
+			continue
+		}
+		index.downtimes = append(index.downtimes, downtime)
+	}
+	return index
+}
+
+// Len returns the number of active downtimes
+func (i *DowntimeIndex) Len() int {
+	return len(i.downtimes)
+}
+
+// Matching returns the active downtimes silencing a monitor, by ID
+func (i *DowntimeIndex) Matching(monitor Monitor) []Downtime {
+	var matching []Downtime
+	for _, downtime := range i.downtimes {
+		if DowntimeSilences(downtime, monitor) {
+			matching = append(matching, downtime)
+		}
+	}
+	return matching
+}
+
+// DowntimeSilences reports whether a downtime applies to a monitor, reading
+// its monitor_id, monitor_tags and scope as the API does, with the monitor's
+// tags standing for its groups:
+//   - with a monitor_id, only that monitor is silenced
+//   - every monitor_tags tag must be a tag of the monitor (AND)
+//   - every scope tag must be a tag of the monitor (AND); a scope entry may
+//     itself list several tags separated by commas
+//
+// A "*" monitor tag or scope matches any monitor, as does an empty list.
+// Downtimes scoped to groups the monitor has no tag for (host:a on a monitor
+// grouped by host) don't match: only part of the monitor is silenced.
+func DowntimeSilences(downtime Downtime, monitor Monitor) bool {
+	if downtime.MonitorID != 0 && downtime.MonitorID != monitor.ID {
+		return false
+	}
+	tags := make(map[string]bool, len(monitor.Tags))
+	for _, tag := range monitor.Tags {
+		tags[strings.ToLower(strings.TrimSpace(tag))] = true
+	}
+	return hasDowntimeTags(tags, downtime.MonitorTags) && hasDowntimeTags(tags, downtime.Scope)
+}
+
+// hasDowntimeTags reports whether every tag of a downtime's monitor_tags or
+// scope is among tags (lowercased); "*" matches anything
+func hasDowntimeTags(tags map[string]bool, required []string) bool {
+	for _, entry := range required {
+		for _, tag := range strings.Split(entry, ",") {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			if tag == "" || tag == WholeMonitorScope {
+				continue
+			}
+			if !tags[tag] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// DowntimeScope describes the scope of a downtime, e.g. "env:prd, role:db",
+// or "*" for every group
+func DowntimeScope(downtime Downtime) string {
+	var scope []string
+	for _, entry := range downtime.Scope {
+		if entry = strings.TrimSpace(entry); entry != "" && entry != WholeMonitorScope {
+			scope = append(scope, entry)
+		}
+	}
+	if len(scope) == 0 {
+		return WholeMonitorScope
+	}
+	return strings.Join(scope, ", ")
+}
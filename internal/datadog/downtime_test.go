@@ -0,0 +1,124 @@
+package datadog
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewDowntimeIndex(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	downtimes := []Downtime{
+		{ID: 1, Scope: []string{"*"}, Active: true},
+		{ID: 2, Scope: []string{"*"}, Active: true, Start: now.Unix() - 60, End: now.Unix() + 60},
+		{ID: 3, Scope: []string{"*"}, Active: true, Start: now.Unix() + 60},
+		{ID: 4, Scope: []string{"*"}, Active: true, End: now.Unix()},
+		{ID: 5, Scope: []string{"*"}, Active: true, Disabled: true},
+		{ID: 6, Scope: []string{"*"}},
+	}
+	index := NewDowntimeIndex(downtimes, now)
+
+	var ids []int
+	for _, downtime := range index.Matching(Monitor{ID: 100}) {
+		ids = append(ids, downtime.ID)
+	}
+	// Not started yet (3), ended (4), disabled (5) and inactive (6) are left out
+	if want := []int{1, 2}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("active downtimes %v, want %v", ids, want)
+	}
+	if index.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", index.Len())
+	}
+}
+
+func TestDowntimeSilences(t *testing.T) {
+	monitor := Monitor{ID: 100, Tags: []string{"service:checkout", "env:prd", "Team:Payments"}}
+	for _, tc := range []struct {
+		name     string
+		downtime Downtime
+		want     bool
+	}{
+		{"whole org", Downtime{Scope: []string{"*"}}, true},
+		{"empty scope", Downtime{}, true},
+		{"scope tag", Downtime{Scope: []string{"env:prd"}}, true},
+		{"scope tags are ANDed", Downtime{Scope: []string{"env:prd", "service:checkout"}}, true},
+		{"AND with a missing tag", Downtime{Scope: []string{"env:prd", "service:billing"}}, false},
+		{"comma-separated scope entry", Downtime{Scope: []string{"env:prd,service:checkout"}}, true},
+		{"comma-separated with a missing tag", Downtime{Scope: []string{"env:prd, host:a"}}, false},
+		{"case and spaces", Downtime{Scope: []string{" ENV:PRD "}, MonitorTags: []string{"team:payments"}}, true},
+		{"other env", Downtime{Scope: []string{"env:stg"}}, false},
+		{"group scope the monitor has no tag for", Downtime{Scope: []string{"host:a"}}, false},
+		{"monitor tags", Downtime{Scope: []string{"*"}, MonitorTags: []string{"service:checkout", "env:prd"}}, true},
+		{"monitor tags are ANDed", Downtime{Scope: []string{"*"}, MonitorTags: []string{"service:checkout", "env:stg"}}, false},
+		{"* monitor tag", Downtime{Scope: []string{"env:prd"}, MonitorTags: []string{"*"}}, true},
+		{"monitor ID", Downtime{MonitorID: 100, Scope: []string{"*"}}, true},
+		{"other monitor ID", Downtime{MonitorID: 101, Scope: []string{"*"}}, false},
+		{"monitor ID and scope", Downtime{MonitorID: 100, Scope: []string{"env:prd"}}, true},
+		{"monitor ID and another scope", Downtime{MonitorID: 100, Scope: []string{"env:stg"}}, false},
+	} {
+		if got := DowntimeSilences(tc.downtime, monitor); got != tc.want {
+			t.Errorf("%s: DowntimeSilences = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestDowntimeIndexMatching(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	index := NewDowntimeIndex([]Downtime{
+		{ID: 1, Active: true, Scope: []string{"env:prd"}, MonitorTags: []string{"service:checkout"}},
+		{ID: 2, Active: true, MonitorID: 200, Scope: []string{"*"}},
+		{ID: 3, Active: true, Scope: []string{"env:stg"}},
+	}, now)
+
+	for _, tc := range []struct {
+		monitor Monitor
+		want    []int
+	}{
+		{Monitor{ID: 100, Tags: []string{"service:checkout", "env:prd"}}, []int{1}},
+		{Monitor{ID: 200, Tags: []string{"service:checkout", "env:prd"}}, []int{1, 2}},
+		{Monitor{ID: 300, Tags: []string{"service:billing", "env:stg"}}, []int{3}},
+		{Monitor{ID: 400, Tags: []string{"service:billing", "env:prd"}}, nil},
+	} {
+		var ids []int
+		for _, downtime := range index.Matching(tc.monitor) {
+			ids = append(ids, downtime.ID)
+		}
+		if !reflect.DeepEqual(ids, tc.want) {
+			t.Errorf("monitor %d: downtimes %v, want %v", tc.monitor.ID, ids, tc.want)
+		}
+	}
+}
+
+func TestDowntimeScope(t *testing.T) {
+	for _, tc := range []struct {
+		scope []string
+		want  string
+	}{
+		{nil, "*"},
+		{[]string{"*"}, "*"},
+		{[]string{"env:prd", " role:db "}, "env:prd, role:db"},
+		{[]string{"*", "env:prd"}, "env:prd"},
+	} {
+		if got := DowntimeScope(Downtime{Scope: tc.scope}); got != tc.want {
+			t.Errorf("DowntimeScope(%q) = %q, want %q", tc.scope, got, tc.want)
+		}
+	}
+}
+
+func TestDowntimeKey(t *testing.T) {
+	for _, tc := range []struct {
+		message, want string
+	}{
+		{"[deploy window]", "[deploy window]"},
+		{"[deploy window] Weekly deploys", "[deploy window]"},
+		{"[deploy window] Weekly [maintenance] deploys", "[deploy window]"},
+		// Downtimes not created from a spec are identified by their message
+		{"Weekly deploys", "Weekly deploys"},
+		{"[unterminated name", "[unterminated name"},
+		{"", ""},
+	} {
+		if got := downtimeKey(tc.message); got != tc.want {
+			t.Errorf("downtimeKey(%q) = %q, want %q", tc.message, got, tc.want)
+		}
+	}
+}
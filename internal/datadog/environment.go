@@ -0,0 +1,171 @@
+package datadog
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// The environment variables credentials and the site are read from, highest
+// precedence first. Other Datadog tooling spells them differently (DD_* for
+// the agent and dd-trace, DATADOG_* for dogshell and the Terraform provider),
+// so every spelling is accepted.
+var (
+	APIKeyEnvs = []string{"DD_API_KEY", "DATADOG_API_KEY"}
+	AppKeyEnvs = []string{"DD_APP_KEY", "DATADOG_APP_KEY"}
+	// SiteEnvs select the API URL: DDMM_API_URL is a full API v1 URL,
+	// DD_SITE and DATADOG_SITE a site (datadoghq.eu, us3.datadoghq.com, ...)
+	// and DATADOG_HOST a host URL (https://api.datadoghq.eu)
+	SiteEnvs = []string{APIURLEnv, "DD_SITE", "DATADOG_HOST", "DATADOG_SITE"}
+)
+
+// datadogDomains are the domains of the Datadog sites, whose app hosts
+// (app.datadoghq.eu, us3.datadoghq.com) map to api. hosts
+var datadogDomains = []string{"datadoghq.com", "datadoghq.eu", "ddog-gov.com"}
+
+// EnvSetting is a setting resolved from the environment
+type EnvSetting struct {
+	// Value is the resolved value: the key, or the API v1 URL of the site
+	Value string
+	// Source is the variable Value came from; empty when none is set
+	Source string
+	// Raw is the variable's value as set, e.g. the site Value was derived from
+	Raw string
+	// Conflicts are the lower-precedence variables also set, to a value that
+	// resolves differently; they are ignored
+	Conflicts []string
+}
+
+// EnvResolution is the credentials and API URL resolved from the environment
+type EnvResolution struct {
+	APIKey     EnvSetting
+	AppKey     EnvSetting
+	APIKeyFile EnvSetting
+	AppKeyFile EnvSetting
+	// APIURL is the API v1 URL; Source is empty for DefaultBaseURL
+	APIURL EnvSetting
+}
+
+// ResolveEnv resolves the credentials and the API URL from the variables of
+// lookup (os.Getenv for the process environment). Each setting takes the
+// first variable of its list that is set, in the order of APIKeyEnvs,
+// AppKeyEnvs and SiteEnvs; key files (APIKeyFileEnv, AppKeyFileEnv) take
+// precedence over the keys. A site variable that can't be turned into a URL
+// is an error.
+func ResolveEnv(lookup func(string) string) (EnvResolution, error) {
+	resolution := EnvResolution{
+		APIKey:     resolveSetting(lookup, APIKeyEnvs, identity),
+		AppKey:     resolveSetting(lookup, AppKeyEnvs, identity),
+		APIKeyFile: resolveSetting(lookup, []string{APIKeyFileEnv}, identity),
+		AppKeyFile: resolveSetting(lookup, []string{AppKeyFileEnv}, identity),
+	}
+
+	for _, name := range SiteEnvs {
+		if value := strings.TrimSpace(lookup(name)); value != "" {
+			if _, err := siteAPIURL(name, value); err != nil {
+				return resolution, err
+			}
+		}
+	}
+	resolution.APIURL = resolveSetting(lookup, SiteEnvs, func(name, value string) string {
+		apiURL, _ := siteAPIURL(name, value)
+		return apiURL
+	})
+	if resolution.APIURL.Source == "" {
+		resolution.APIURL.Value = DefaultBaseURL
+	}
+	return resolution, nil
+}
+
+// identity resolves a variable to its value
+func identity(name, value string) string {
+	return value
+}
+
+// resolveSetting takes the first of names set in lookup, resolved by
+// resolve, and records the others set to a value that resolves differently
+func resolveSetting(lookup func(string) string, names []string, resolve func(name, value string) string) EnvSetting {
+	var setting EnvSetting
+	for _, name := range names {
+		raw := strings.TrimSpace(lookup(name))
+		if raw == "" {
+			continue
+		}
+		value := resolve(name, raw)
+		switch {
+		case setting.Source == "":
+			setting = EnvSetting{Value: value, Source: name, Raw: raw}
+		case value != setting.Value:
+			setting.Conflicts = append(setting.Conflicts, name)
+		}
+	}
+	return setting
+}
+
+// siteAPIURL returns the API v1 URL a site variable selects
+func siteAPIURL(name, value string) (string, error) {
+	switch name {
+	case APIURLEnv:
+		return strings.TrimSuffix(value, "/"), nil
+	case "DATADOG_HOST":
+		host := value
+		if !strings.Contains(host, "://") {
+			host = "https://" + host
+		}
+		u, err := url.Parse(host)
+		if err != nil || u.Host == "" {
+			return "", fmt.Errorf("invalid %s %q: want a host URL such as https://api.datadoghq.eu", name, value)
+		}
+		u.Host = apiHost(u.Host)
+		path := strings.TrimSuffix(u.Path, "/")
+		if path == "" {
+			path = "/api/v1"
+		}
+		return fmt.Sprintf("%s://%s%s", u.Scheme, u.Host, path), nil
+	default:
+		site := strings.TrimSuffix(value, "/")
+		if i := strings.Index(site, "://"); i >= 0 {
+			site = site[i+3:]
+		}
+		if site == "" || strings.ContainsAny(site, "/ ") {
+			return "", fmt.Errorf("invalid %s %q: want a site such as datadoghq.eu or us3.datadoghq.com", name, value)
+		}
+		return "https://" + apiHost(site) + "/api/v1", nil
+	}
+}
+
+// apiHost returns the API host of a Datadog site or app host
+// (datadoghq.eu, app.datadoghq.eu and api.datadoghq.eu give api.datadoghq.eu);
+// hosts outside the Datadog domains, such as proxies, are kept
+func apiHost(host string) string {
+	host = strings.ToLower(host)
+	for _, domain := range datadogDomains {
+		if host != domain && !strings.HasSuffix(host, "."+domain) {
+			continue
+		}
+		if strings.HasPrefix(host, "api.") {
+			return host
+		}
+		return "api." + strings.TrimPrefix(host, "app.")
+	}
+	return host
+}
+
+// EnvConflicts describes the variables ignored because a higher-precedence
+// variable of the same setting is set to another value
+func (r EnvResolution) EnvConflicts() []string {
+	var conflicts []string
+	for _, setting := range []EnvSetting{r.APIKey, r.AppKey, r.APIURL} {
+		for _, name := range setting.Conflicts {
+			conflicts = append(conflicts, fmt.Sprintf("%s is set to a different value than %s, which takes precedence; %s is ignored", name, setting.Source, name))
+		}
+	}
+	return conflicts
+}
+
+// ResolveProcessEnv resolves the credentials and the API URL from the
+// process environment
+func ResolveProcessEnv() (EnvResolution, error) {
+	return ResolveEnv(os.Getenv)
+}
@@ -0,0 +1,128 @@
+package datadog
+
+import (
+	"strings"
+	"testing"
+)
+
+// envLookup returns a lookup function over a fixed environment
+func envLookup(env map[string]string) func(string) string {
+	return func(name string) string { return env[name] }
+}
+
+// TestEnvPrecedence pins the order variables are read in: changing it
+// silently switches the keys or the site of existing setups
+func TestEnvPrecedence(t *testing.T) {
+	if got := strings.Join(APIKeyEnvs, " "); got != "DD_API_KEY DATADOG_API_KEY" {
+		t.Errorf("APIKeyEnvs = %s", got)
+	}
+	if got := strings.Join(AppKeyEnvs, " "); got != "DD_APP_KEY DATADOG_APP_KEY" {
+		t.Errorf("AppKeyEnvs = %s", got)
+	}
+	if got := strings.Join(SiteEnvs, " "); got != "DDMM_API_URL DD_SITE DATADOG_HOST DATADOG_SITE" {
+		t.Errorf("SiteEnvs = %s", got)
+	}
+
+	for _, tc := range []struct {
+		name             string
+		env              map[string]string
+		apiKey, apiKeyBy string
+		appKey, appKeyBy string
+		url, urlBy       string
+	}{
+		{"nothing set", nil, "", "", "", "", DefaultBaseURL, ""},
+		{"DATADOG_* only", map[string]string{"DATADOG_API_KEY": "a2", "DATADOG_APP_KEY": "p2", "DATADOG_SITE": "datadoghq.eu"},
+			"a2", "DATADOG_API_KEY", "p2", "DATADOG_APP_KEY", "https://api.datadoghq.eu/api/v1", "DATADOG_SITE"},
+		{"DD_* wins over DATADOG_*", map[string]string{"DD_API_KEY": "a1", "DATADOG_API_KEY": "a2", "DD_APP_KEY": "p1", "DATADOG_APP_KEY": "p2"},
+			"a1", "DD_API_KEY", "p1", "DD_APP_KEY", DefaultBaseURL, ""},
+		{"DD_SITE wins over DATADOG_HOST", map[string]string{"DD_SITE": "us3.datadoghq.com", "DATADOG_HOST": "https://api.datadoghq.eu", "DATADOG_SITE": "ddog-gov.com"},
+			"", "", "", "", "https://api.us3.datadoghq.com/api/v1", "DD_SITE"},
+		{"DATADOG_HOST wins over DATADOG_SITE", map[string]string{"DATADOG_HOST": "https://app.datadoghq.eu", "DATADOG_SITE": "ddog-gov.com"},
+			"", "", "", "", "https://api.datadoghq.eu/api/v1", "DATADOG_HOST"},
+		{"DDMM_API_URL wins over everything", map[string]string{"DDMM_API_URL": "http://localhost:8080/api/v1/", "DD_SITE": "datadoghq.eu"},
+			"", "", "", "", "http://localhost:8080/api/v1", "DDMM_API_URL"},
+		{"blank values are unset", map[string]string{"DD_API_KEY": "  ", "DATADOG_API_KEY": " a2 ", "DD_SITE": ""},
+			"a2", "DATADOG_API_KEY", "", "", DefaultBaseURL, ""},
+	} {
+		env, err := ResolveEnv(envLookup(tc.env))
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		for _, check := range []struct {
+			what              string
+			setting           EnvSetting
+			wantValue, wantBy string
+		}{
+			{"API key", env.APIKey, tc.apiKey, tc.apiKeyBy},
+			{"App key", env.AppKey, tc.appKey, tc.appKeyBy},
+			{"API URL", env.APIURL, tc.url, tc.urlBy},
+		} {
+			if check.setting.Value != check.wantValue || check.setting.Source != check.wantBy {
+				t.Errorf("%s: %s = %q from %q, want %q from %q", tc.name, check.what, check.setting.Value, check.setting.Source, check.wantValue, check.wantBy)
+			}
+		}
+	}
+}
+
+func TestEnvKeyFiles(t *testing.T) {
+	env, err := ResolveEnv(envLookup(map[string]string{"DD_API_KEY_FILE": "/run/secrets/api", "DD_API_KEY": "a1"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The variable is still resolved; callers read the file instead
+	if env.APIKeyFile.Value != "/run/secrets/api" || env.APIKeyFile.Source != APIKeyFileEnv || env.APIKey.Source != "DD_API_KEY" {
+		t.Errorf("key file %+v, key %+v", env.APIKeyFile, env.APIKey)
+	}
+}
+
+func TestEnvConflicts(t *testing.T) {
+	env, err := ResolveEnv(envLookup(map[string]string{
+		"DD_API_KEY": "a1", "DATADOG_API_KEY": "a2",
+		"DD_APP_KEY": "p1", "DATADOG_APP_KEY": "p1",
+		// Different spellings of the same site are no conflict
+		"DD_SITE": "datadoghq.eu", "DATADOG_HOST": "https://app.datadoghq.eu", "DATADOG_SITE": "datadoghq.com",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"DATADOG_API_KEY is set to a different value than DD_API_KEY, which takes precedence; DATADOG_API_KEY is ignored",
+		"DATADOG_SITE is set to a different value than DD_SITE, which takes precedence; DATADOG_SITE is ignored",
+	}
+	if got := env.EnvConflicts(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("conflicts:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSiteAPIURL(t *testing.T) {
+	for _, tc := range []struct {
+		name, value, want string
+	}{
+		{"DD_SITE", "datadoghq.com", "https://api.datadoghq.com/api/v1"},
+		{"DD_SITE", "us5.datadoghq.com", "https://api.us5.datadoghq.com/api/v1"},
+		{"DD_SITE", "app.datadoghq.eu", "https://api.datadoghq.eu/api/v1"},
+		{"DD_SITE", "https://ddog-gov.com/", "https://api.ddog-gov.com/api/v1"},
+		{"DATADOG_SITE", "API.DATADOGHQ.EU", "https://api.datadoghq.eu/api/v1"},
+		{"DATADOG_HOST", "api.datadoghq.eu", "https://api.datadoghq.eu/api/v1"},
+		{"DATADOG_HOST", "https://us3.datadoghq.com", "https://api.us3.datadoghq.com/api/v1"},
+		// Proxies keep their host and path
+		{"DATADOG_HOST", "http://proxy.internal:3128/datadog", "http://proxy.internal:3128/datadog"},
+	} {
+		if got, err := siteAPIURL(tc.name, tc.value); err != nil || got != tc.want {
+			t.Errorf("siteAPIURL(%s=%q) = %q, %v, want %q", tc.name, tc.value, got, err, tc.want)
+		}
+	}
+
+	for _, env := range []map[string]string{
+		{"DD_SITE": "datadoghq.eu/api"},
+		{"DATADOG_SITE": "data dog"},
+		{"DATADOG_HOST": "https://"},
+		// An invalid lower-precedence variable is an error too
+		{"DD_SITE": "datadoghq.eu", "DATADOG_SITE": "a/b"},
+	} {
+		if _, err := ResolveEnv(envLookup(env)); err == nil {
+			t.Errorf("ResolveEnv(%v) succeeded", env)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package datadog
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrMonitorNotFound is returned when the API answers 404 for a monitor ID
+type ErrMonitorNotFound struct {
+	ID int
+}
+
+// Error implements the error interface
+func (e *ErrMonitorNotFound) Error() string {
+	return fmt.Sprintf("monitor %d does not exist (it may have been deleted)", e.ID)
+}
+
+// Is makes errors.Is(err, &ErrMonitorNotFound{}) match any monitor ID
+func (e *ErrMonitorNotFound) Is(target error) bool {
+	_, ok := target.(*ErrMonitorNotFound)
+	return ok
+}
+
+// IsMonitorNotFound reports whether err (or an error it wraps) is ErrMonitorNotFound
+func IsMonitorNotFound(err error) bool {
+	return errors.Is(err, &ErrMonitorNotFound{})
+}
+
+// MonitorExistsError is returned by CreateMonitorStrict when a monitor with the
+// same name already exists
+type MonitorExistsError struct {
+	ID   int
+	Name string
+}
+
+// Error implements the error interface
+func (e *MonitorExistsError) Error() string {
+	return fmt.Sprintf("monitor %q already exists (ID %d), use upsert or --allow-duplicate", e.Name, e.ID)
+}
+
+// IsMonitorExists reports whether err (or an error it wraps) is a MonitorExistsError
+func IsMonitorExists(err error) bool {
+	var exists *MonitorExistsError
+	return errors.As(err, &exists)
+}
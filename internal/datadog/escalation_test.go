@@ -0,0 +1,149 @@
+package datadog
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEscalationMessageRoundTrip(t *testing.T) {
+	data := []byte(`{"id":1,"name":"CPU","type":"metric alert","query":"avg(last_5m):avg:cpu{*} > 90",
+		"options":{"escalation_message":"Still alerting: @pagerduty-secondary","renotify_interval":60,"thresholds":{"critical":90}}}`)
+
+	var monitor Monitor
+	if err := json.Unmarshal(data, &monitor); err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := json.Marshal(monitor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Monitor
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := EscalationMessage(decoded); got != "Still alerting: @pagerduty-secondary" {
+		t.Errorf("escalation message %q after the round-trip", got)
+	}
+	if decoded.Options["renotify_interval"] != float64(60) || decoded.Options["thresholds"] == nil {
+		t.Errorf("other options lost in the round-trip: %v", decoded.Options)
+	}
+	if got := EscalationMessage(Monitor{}); got != "" {
+		t.Errorf("escalation message of a monitor without options = %q", got)
+	}
+}
+
+func TestEscalationEditApply(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		edit    EscalationEdit
+		in      string
+		want    string
+		changed bool
+	}{
+		{"set", EscalationEdit{Set: "@pagerduty-secondary"}, "old", "@pagerduty-secondary", true},
+		{"set same", EscalationEdit{Set: "old"}, "old", "old", false},
+		{"clear", EscalationEdit{Clear: true}, "old", "", true},
+		{"clear empty", EscalationEdit{Clear: true}, "", "", false},
+		{"append", EscalationEdit{MessageEdit: MessageEdit{Append: " @oncall"}}, "Still alerting", "Still alerting @oncall", true},
+		// Append is skipped when the text is already there
+		{"append twice", EscalationEdit{MessageEdit: MessageEdit{Append: " @oncall"}}, "Still alerting @oncall", "Still alerting @oncall", false},
+		{"replace", EscalationEdit{MessageEdit: MessageEdit{Replace: []TextReplacement{{Old: "@pagerduty-old", New: "@pagerduty-new"}}}}, "page @pagerduty-old", "page @pagerduty-new", true},
+		// Edits apply after set
+		{"set and append", EscalationEdit{Set: "Still alerting", MessageEdit: MessageEdit{Append: " @oncall"}}, "", "Still alerting @oncall", true},
+	} {
+		got, changed := tc.edit.Apply(tc.in)
+		if got != tc.want || changed != tc.changed {
+			t.Errorf("%s: Apply(%q) = %q, %v, want %q, %v", tc.name, tc.in, got, changed, tc.want, tc.changed)
+		}
+	}
+}
+
+func TestEditMonitorEscalationKeepsOptions(t *testing.T) {
+	var puts []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		monitor := map[string]interface{}{
+			"id":   1,
+			"name": "CPU",
+			"options": map[string]interface{}{
+				"escalation_message": "page @pagerduty-old",
+				"renotify_interval":  60,
+			},
+		}
+		if r.Method == "PUT" {
+			body, _ := io.ReadAll(r.Body)
+			var sent map[string]interface{}
+			json.Unmarshal(body, &sent)
+			puts = append(puts, sent)
+			monitor = sent
+		}
+		json.NewEncoder(w).Encode(monitor)
+	}))
+	defer srv.Close()
+	client, err := NewClientWithOptions(WithAPIKey("api-key"), WithAppKey("app-key"), WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edit := EscalationEdit{MessageEdit: MessageEdit{Replace: []TextReplacement{{Old: "@pagerduty-old", New: "@pagerduty-new"}}}}
+	updated, changed, err := client.EditMonitorEscalation(1, edit)
+	if err != nil || !changed {
+		t.Fatalf("EditMonitorEscalation = %v, %v", changed, err)
+	}
+	if len(puts) != 1 {
+		t.Fatalf("%d updates, want 1", len(puts))
+	}
+	options, _ := puts[0]["options"].(map[string]interface{})
+	if options["escalation_message"] != "page @pagerduty-new" || options["renotify_interval"] != float64(60) {
+		t.Errorf("update sent options %v", options)
+	}
+	if got := EscalationMessage(*updated); got != "page @pagerduty-new" {
+		t.Errorf("updated monitor has escalation message %q", got)
+	}
+
+	// A cleared escalation message is sent empty rather than left out, which
+	// would keep it
+	puts = nil
+	if _, _, err := client.EditMonitorEscalation(1, EscalationEdit{Clear: true}); err != nil {
+		t.Fatal(err)
+	}
+	options, _ = puts[0]["options"].(map[string]interface{})
+	if escalation, ok := options["escalation_message"]; !ok || escalation != "" {
+		t.Errorf("clearing sent options %v", options)
+	}
+
+	// Nothing is sent when the escalation message doesn't change
+	puts = nil
+	if _, changed, err := client.EditMonitorEscalation(1, EscalationEdit{Set: "page @pagerduty-old"}); err != nil || changed || len(puts) != 0 {
+		t.Errorf("unchanged edit: changed %v, %d updates, error %v", changed, len(puts), err)
+	}
+}
+
+func TestRenderEscalationMessage(t *testing.T) {
+	options := map[string]interface{}{
+		"escalation_message": "{service} in {env} still alerting: @pagerduty-{team}",
+		"renotify_interval":  float64(30),
+	}
+	template := map[string]interface{}{
+		"name":    "{service} CPU",
+		"type":    "metric alert",
+		"query":   "avg(last_5m):avg:cpu{service:{service}} > 90",
+		"options": options,
+	}
+
+	rendered := RenderTemplate(template, RenderOptions{Service: "api", Env: "prd", Vars: map[string]string{"team": "payments"}})
+	renderedOptions, _ := rendered["options"].(map[string]interface{})
+	if got := renderedOptions["escalation_message"]; got != "api in prd still alerting: @pagerduty-payments" {
+		t.Errorf("rendered escalation message %q", got)
+	}
+	if renderedOptions["renotify_interval"] != float64(30) {
+		t.Errorf("rendered options %v", renderedOptions)
+	}
+	// The template is left as is for the next service
+	if options["escalation_message"] != "{service} in {env} still alerting: @pagerduty-{team}" {
+		t.Errorf("template escalation message changed to %q", options["escalation_message"])
+	}
+}
@@ -0,0 +1,153 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// eventsPageSize is the number of events the events API returns per page
+const eventsPageSize = 1000
+
+// eventsWindow is the time range ListEvents requests at once; long ranges are
+// split so no single query pages through weeks of events
+const eventsWindow = 24 * time.Hour
+
+// eventsMaxRetries is how often ListEvents retries a rate-limited request
+const eventsMaxRetries = 3
+
+// Event is an event from the events API (v1)
+type Event struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+	// AlertType is error, warning, success or info; monitors post error or
+	// warning when they trigger and success when they recover
+	AlertType    string   `json:"alert_type"`
+	DateHappened int64    `json:"date_happened"`
+	Tags         []string `json:"tags"`
+	// MonitorID is the monitor that posted the event, 0 for other events
+	MonitorID int `json:"monitor_id"`
+	// MonitorGroups are the groups of a multi-alert monitor the event is for
+	MonitorGroups []string `json:"monitor_groups"`
+}
+
+// Time returns when the event happened
+func (e Event) Time() time.Time {
+	return time.Unix(e.DateHappened, 0)
+}
+
+// EventQuery filters ListEvents
+type EventQuery struct {
+	// Sources are event sources, e.g. alert for monitor events
+	Sources []string
+	// Tags are tags every event must have
+	Tags []string
+}
+
+// eventsResponse is the body of GET /events
+type eventsResponse struct {
+	Events []Event `json:"events"`
+}
+
+// ListEvents lists the events matching query between from and to, oldest
+// first. The range is requested a day at a time, each day page by page, and
+// rate-limited requests are retried once the limit resets.
+func (c *Client) ListEvents(query EventQuery, from, to time.Time) ([]Event, error) {
+	var events []Event
+	seen := make(map[int64]bool)
+	for start := from; start.Before(to); start = start.Add(eventsWindow) {
+		end := start.Add(eventsWindow)
+		if end.After(to) {
+			end = to
+		}
+		for page := 0; ; page++ {
+			if err := c.interrupted(); err != nil {
+				return events, err
+			}
+			batch, err := c.listEventsPage(query, start, end, page)
+			if err != nil {
+				return events, err
+			}
+			for _, event := range batch {
+				// Events on a window boundary come back twice
+				if !seen[event.ID] {
+					seen[event.ID] = true
+					events = append(events, event)
+				}
+			}
+			if len(batch) < eventsPageSize {
+				break
+			}
+		}
+	}
+
+	sortEvents(events)
+	return events, nil
+}
+
+// listEventsPage requests one page of events, waiting out rate limits
+func (c *Client) listEventsPage(query EventQuery, from, to time.Time, page int) ([]Event, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := c.newRequest("GET", "/events", nil)
+		if err != nil {
+			return nil, err
+		}
+		q := req.URL.Query()
+		q.Set("start", strconv.FormatInt(from.Unix(), 10))
+		q.Set("end", strconv.FormatInt(to.Unix(), 10))
+		q.Set("unaggregated", "true")
+		q.Set("page", strconv.Itoa(page))
+		if len(query.Sources) > 0 {
+			q.Set("sources", strings.Join(query.Sources, ","))
+		}
+		if len(query.Tags) > 0 {
+			q.Set("tags", strings.Join(query.Tags, ","))
+		}
+		req.URL.RawQuery = q.Encode()
+
+		resp, err := c.do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < eventsMaxRetries {
+			wait := rateLimitReset(resp)
+			resp.Body.Close()
+			if c.stats != nil {
+				c.stats.recordRetry()
+			}
+			select {
+			case <-time.After(wait):
+				continue
+			case <-c.ctx.Done():
+				return nil, c.ctx.Err()
+			}
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list events: status %d, body: %s", resp.StatusCode, string(body))
+		}
+
+		var result eventsResponse
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		return result.Events, nil
+	}
+}
+
+// rateLimitReset returns how long to wait before retrying a rate-limited
+// request: the X-RateLimit-Reset seconds, or a second when it is missing
+func rateLimitReset(resp *http.Response) time.Duration {
+	if seconds, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Reset")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return time.Second
+}
@@ -0,0 +1,146 @@
+package datadog
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// groupByCallPattern matches the .by(...) call of log, trace, event and
+// service check queries, e.g. .by("service","@http.status_code")
+var groupByCallPattern = regexp.MustCompile(`\.by\(((?:\s*"(?:[^"\\]|\\.)*"\s*,?)*)\s*\)`)
+
+// quotedPattern matches a double-quoted string
+var quotedPattern = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+
+// metricClauses splits the {...} clauses of metric and APM metric queries
+// into scopes and "by {...}" group-by lists. Search filters of event-style
+// queries can contain braces of their own, so they are dropped first.
+func metricClauses(query string) (scopes, groupBys []string) {
+	rest := searchQueryPattern.ReplaceAllString(query, "")
+	for _, match := range metricScopePattern.FindAllStringSubmatch(rest, -1) {
+		if match[1] != "" {
+			groupBys = append(groupBys, match[2])
+		} else {
+			scopes = append(scopes, match[2])
+		}
+	}
+	return scopes, groupBys
+}
+
+// QueryGroupBy returns the tags and facets a monitor query groups by, in
+// order and without duplicates: those of "by {...}" in metric queries and of
+// .by(...) in log, trace, event and service check queries. A simple alert
+// (no group-by, or service checks' .by("*")) has none.
+func QueryGroupBy(query string) []string {
+	var groupBy []string
+	seen := make(map[string]bool)
+	add := func(list string) {
+		for _, item := range strings.Split(list, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" || item == "*" || seen[item] {
+				continue
+			}
+			seen[item] = true
+			groupBy = append(groupBy, item)
+		}
+	}
+
+	_, groupBys := metricClauses(query)
+	for _, list := range groupBys {
+		add(list)
+	}
+	for _, match := range groupByCallPattern.FindAllStringSubmatch(query, -1) {
+		for _, quoted := range quotedPattern.FindAllString(match[1], -1) {
+			if value, err := strconv.Unquote(quoted); err == nil {
+				add(value)
+			}
+		}
+	}
+	return groupBy
+}
+
+// NotifyByError is a monitor whose options.notify_by names values its query
+// doesn't group by, which the API rejects
+type NotifyByError struct {
+	Invalid []string
+	GroupBy []string
+}
+
+// Error implements the error interface
+func (e *NotifyByError) Error() string {
+	groupBy := "the query has no group-by"
+	if len(e.GroupBy) > 0 {
+		groupBy = "the query groups by " + strings.Join(e.GroupBy, ", ")
+	}
+	return fmt.Sprintf("options.notify_by has %s, not in the query's group-by (%s); group the query by it, remove it from notify_by, or drop it with --fix-notify-by",
+		quoteList(e.Invalid), groupBy)
+}
+
+// notifyBy returns the options.notify_by values of a monitor
+func notifyBy(monitor Monitor) []string {
+	raw, _ := monitor.Options["notify_by"].([]interface{})
+	values := make([]string, 0, len(raw))
+	for _, value := range raw {
+		if s, ok := value.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// CheckNotifyBy returns a *NotifyByError when options.notify_by of a monitor
+// names values the query doesn't group by; "*" (notify per whole group) is
+// always valid
+func CheckNotifyBy(monitor Monitor) error {
+	values := notifyBy(monitor)
+	if len(values) == 0 {
+		return nil
+	}
+	groupBy := QueryGroupBy(monitor.Query)
+	grouped := make(map[string]bool, len(groupBy))
+	for _, item := range groupBy {
+		grouped[item] = true
+	}
+
+	var invalid []string
+	for _, value := range values {
+		if value != "*" && !grouped[value] {
+			invalid = append(invalid, value)
+		}
+	}
+	if len(invalid) == 0 {
+		return nil
+	}
+	sort.Strings(invalid)
+	return &NotifyByError{Invalid: invalid, GroupBy: groupBy}
+}
+
+// FixNotifyBy drops the options.notify_by values of a monitor the query
+// doesn't group by, removing notify_by when none is left, and returns the
+// values dropped
+func FixNotifyBy(monitor *Monitor) []string {
+	notifyErr, ok := CheckNotifyBy(*monitor).(*NotifyByError)
+	if !ok {
+		return nil
+	}
+
+	invalid := make(map[string]bool, len(notifyErr.Invalid))
+	for _, value := range notifyErr.Invalid {
+		invalid[value] = true
+	}
+	var kept []interface{}
+	for _, value := range notifyBy(*monitor) {
+		if !invalid[value] {
+			kept = append(kept, value)
+		}
+	}
+	if len(kept) == 0 {
+		delete(monitor.Options, "notify_by")
+	} else {
+		monitor.Options["notify_by"] = kept
+	}
+	return notifyErr.Invalid
+}
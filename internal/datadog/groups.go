@@ -0,0 +1,123 @@
+package datadog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GroupStatesAll is the group_states value requesting the state of every group
+const GroupStatesAll = "all"
+
+// groupStateValues are the values the group_states parameter accepts
+var groupStateValues = []string{GroupStatesAll, "alert", "warn", "no data"}
+
+// GroupState is the state of one group of a multi-alert monitor
+type GroupState struct {
+	Status          string    `json:"status"`
+	LastTriggeredTS Timestamp `json:"last_triggered_ts,omitzero"`
+	LastNotifiedTS  Timestamp `json:"last_notified_ts,omitzero"`
+	LastResolvedTS  Timestamp `json:"last_resolved_ts,omitzero"`
+	LastNodataTS    Timestamp `json:"last_nodata_ts,omitzero"`
+}
+
+// MonitorState holds the per-group states of a monitor. The API only returns
+// it when monitors are fetched with group_states.
+type MonitorState struct {
+	Groups map[string]GroupState `json:"groups,omitempty"`
+}
+
+// NormalizeGroupStates validates group_states values (all, alert, warn,
+// no data) and lowercases them
+func NormalizeGroupStates(states []string) ([]string, error) {
+	normalized := make([]string, 0, len(states))
+	for _, state := range states {
+		value := strings.ToLower(strings.Join(strings.Fields(strings.NewReplacer("_", " ", "-", " ").Replace(state)), " "))
+		valid := false
+		for _, allowed := range groupStateValues {
+			if value == allowed {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("invalid group state %q (must be one of: %s)", state, strings.Join(groupStateValues, ", "))
+		}
+		normalized = append(normalized, value)
+	}
+	return normalized, nil
+}
+
+// GroupStatesFor returns the group_states value that fetches the groups a
+// monitor state filter can match: the state itself for alert, warn and
+// no data, and every group otherwise
+func GroupStatesFor(state string) string {
+	value := strings.ToLower(state)
+	for _, allowed := range groupStateValues {
+		if value == allowed {
+			return value
+		}
+	}
+	return GroupStatesAll
+}
+
+// NamedGroupState is a GroupState with its group name
+type NamedGroupState struct {
+	Name string
+	GroupState
+}
+
+// SortedGroups returns the group states of a monitor sorted by name; nil when
+// the monitor was fetched without group states
+func SortedGroups(monitor Monitor) []NamedGroupState {
+	if monitor.State == nil {
+		return nil
+	}
+	groups := make([]NamedGroupState, 0, len(monitor.State.Groups))
+	for name, state := range monitor.State.Groups {
+		groups = append(groups, NamedGroupState{Name: name, GroupState: state})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+// HasGroupInState reports whether any group of a monitor has the given state,
+// compared case-insensitively
+func HasGroupInState(monitor Monitor, state string) bool {
+	if monitor.State == nil {
+		return false
+	}
+	for _, group := range monitor.State.Groups {
+		if strings.EqualFold(group.Status, state) {
+			return true
+		}
+	}
+	return false
+}
+
+// AlertingGroups returns the groups of a monitor in Alert, sorted by name;
+// nil when the monitor was fetched without group states
+func AlertingGroups(monitor Monitor) []NamedGroupState {
+	var alerting []NamedGroupState
+	for _, group := range SortedGroups(monitor) {
+		if strings.EqualFold(group.Status, "alert") {
+			alerting = append(alerting, group)
+		}
+	}
+	return alerting
+}
+
+// AlertingSince returns when a monitor started alerting: the earliest
+// last_triggered_ts of its groups in Alert. It is zero when no alerting group
+// reports one, e.g. for a monitor fetched without group states.
+func AlertingSince(monitor Monitor) Timestamp {
+	var since Timestamp
+	for _, group := range AlertingGroups(monitor) {
+		if !group.LastTriggeredTS.IsZero() && (since.IsZero() || group.LastTriggeredTS.Before(since)) {
+			since = group.LastTriggeredTS
+		}
+	}
+	return since
+}
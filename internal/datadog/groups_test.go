@@ -0,0 +1,126 @@
+package datadog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// loadGroupStatesFixture decodes the monitors of testdata/group-states-monitors.json,
+// as the API returns them with group_states=all
+func loadGroupStatesFixture(t *testing.T) map[int]Monitor {
+	t.Helper()
+	data, err := os.ReadFile("testdata/group-states-monitors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list []Monitor
+	if err := json.Unmarshal(data, &list); err != nil {
+		t.Fatal(err)
+	}
+	monitors := make(map[int]Monitor)
+	for _, monitor := range list {
+		monitors[monitor.ID] = monitor
+	}
+	return monitors
+}
+
+func TestGroupStateDecoding(t *testing.T) {
+	monitors := loadGroupStatesFixture(t)
+
+	checkout := monitors[1].State
+	if checkout == nil || len(checkout.Groups) != 3 {
+		t.Fatalf("checkout errors decoded with state %+v", checkout)
+	}
+	podB := checkout.Groups["env:prd,pod:b"]
+	if podB.Status != "Alert" || !podB.LastTriggeredTS.Time().Equal(time.Date(2024, 5, 1, 10, 40, 0, 0, time.UTC)) || podB.LastNotifiedTS.IsZero() {
+		t.Errorf("pod:b group (milliseconds) decoded as %+v", podB)
+	}
+	if podA := checkout.Groups["env:prd,pod:a"]; podA.LastTriggeredTS.Int64() != 1714556880 || podA.LastResolvedTS.Int64() != 1714550000 {
+		t.Errorf("pod:a group (seconds) decoded as %+v", podA)
+	}
+	if api := monitors[2].State.Groups["env:prd"]; !api.LastTriggeredTS.Time().Equal(time.Date(2024, 4, 29, 8, 30, 0, 0, time.UTC)) {
+		t.Errorf("api latency group (RFC3339) decoded as %+v", api)
+	}
+	if orders := monitors[3].State.Groups["queue:orders"]; orders.LastTriggeredTS.Int64() != 1714563900 {
+		t.Errorf("worker lag group (numeric string) decoded as %+v", orders)
+	}
+	if disk := monitors[4].State.Groups["host:db-1"]; disk.Status != "Alert" || !disk.LastTriggeredTS.IsZero() {
+		t.Errorf("disk full group (null) decoded as %+v", disk)
+	}
+	if monitors[5].State != nil {
+		t.Errorf("monitor without group states decoded with state %+v", monitors[5].State)
+	}
+
+	// Group states are encoded back as decoded
+	encoded, err := json.Marshal(monitors[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(encoded), `"env:prd,pod:b":{"status":"Alert","last_triggered_ts":1714560000000,"last_notified_ts":1714560000000}`) {
+		t.Errorf("group states encoded as %s", encoded)
+	}
+}
+
+func TestAlertingSince(t *testing.T) {
+	monitors := loadGroupStatesFixture(t)
+	for _, tc := range []struct {
+		id     int
+		groups string
+		since  time.Time
+	}{
+		// The earliest trigger of the alerting groups; the OK group triggered
+		// earlier but isn't alerting
+		{1, "env:prd,pod:a env:prd,pod:b", time.Date(2024, 5, 1, 9, 48, 0, 0, time.UTC)},
+		{2, "env:prd", time.Date(2024, 4, 29, 8, 30, 0, 0, time.UTC)},
+		// Statuses are compared case-insensitively, Warn groups left out
+		{3, "queue:orders", time.Date(2024, 5, 1, 11, 45, 0, 0, time.UTC)},
+		// Alerting without a trigger time
+		{4, "host:db-1", time.Time{}},
+		{5, "", time.Time{}},
+		{6, "", time.Time{}},
+	} {
+		var names []string
+		for _, group := range AlertingGroups(monitors[tc.id]) {
+			names = append(names, group.Name)
+		}
+		if got := strings.Join(names, " "); got != tc.groups {
+			t.Errorf("monitor %d: alerting groups %q, want %q", tc.id, got, tc.groups)
+		}
+		if got := AlertingSince(monitors[tc.id]).Time(); !got.Equal(tc.since) {
+			t.Errorf("monitor %d: alerting since %v, want %v", tc.id, got, tc.since)
+		}
+	}
+}
+
+func TestListMonitorsGroupStates(t *testing.T) {
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("group_states"))
+		data, _ := os.ReadFile("testdata/group-states-monitors.json")
+		w.Write(data)
+	}))
+	defer srv.Close()
+	client, err := NewClientWithOptions(WithAPIKey("api-key"), WithAppKey("app-key"), WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	monitors, err := client.ListMonitorsWithOptions(ListMonitorsOptions{GroupStates: []string{"alert", "no data"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(monitors) != 6 || AlertingSince(monitors[0]).IsZero() {
+		t.Errorf("listed %d monitors, first alerting since %v", len(monitors), AlertingSince(monitors[0]))
+	}
+	if _, err := client.ListMonitors(nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"alert,no data", ""}; strings.Join(queries, "|") != strings.Join(want, "|") {
+		t.Errorf("group_states parameters %q, want %q", queries, want)
+	}
+}
@@ -0,0 +1,94 @@
+package datadog
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// handlePattern matches the notification handles of a monitor message:
+// @slack-, @pagerduty-, @teams- and @webhook- integrations and @user@domain
+// email handles
+var handlePattern = regexp.MustCompile(`@(?:(?:slack|pagerduty|teams|webhook)-[A-Za-z0-9_.\-/|]+|[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)+)`)
+
+// MessageHandles returns the notification handles in a message, in order of
+// first appearance and without duplicates (compared case-insensitively).
+// Punctuation ending a sentence after a handle is not part of it.
+func MessageHandles(message string) []string {
+	var handles []string
+	seen := make(map[string]bool)
+	for _, match := range handlePattern.FindAllString(message, -1) {
+		handle := strings.TrimRight(match, ".-|/")
+		key := strings.ToLower(handle)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		handles = append(handles, handle)
+	}
+	return handles
+}
+
+// MonitorHandles returns the notification handles of a monitor's message and
+// escalation message
+func MonitorHandles(monitor Monitor) []string {
+	text := monitor.Message
+	if escalation, ok := monitor.Options["escalation_message"].(string); ok && escalation != "" {
+		text += "\n" + escalation
+	}
+	return MessageHandles(text)
+}
+
+// HandleMonitor is a monitor referencing a handle
+type HandleMonitor struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
+// HandleUsage is a notification handle with the monitors referencing it
+type HandleUsage struct {
+	Handle   string          `json:"handle"`
+	Count    int             `json:"count"`
+	Monitors []HandleMonitor `json:"monitors,omitempty"`
+}
+
+// BuildHandleReport groups monitors by the handles they notify, most used
+// handle first. Handles are compared case-insensitively.
+func BuildHandleReport(monitors []Monitor) []HandleUsage {
+	byHandle := make(map[string]*HandleUsage)
+	for _, monitor := range monitors {
+		for _, handle := range MonitorHandles(monitor) {
+			key := strings.ToLower(handle)
+			usage, ok := byHandle[key]
+			if !ok {
+				usage = &HandleUsage{Handle: handle}
+				byHandle[key] = usage
+			}
+			usage.Count++
+			usage.Monitors = append(usage.Monitors, HandleMonitor{ID: monitor.ID, Name: monitor.Name, State: monitor.OverallState})
+		}
+	}
+
+	report := make([]HandleUsage, 0, len(byHandle))
+	for _, usage := range byHandle {
+		sort.Slice(usage.Monitors, func(i, j int) bool { return usage.Monitors[i].ID < usage.Monitors[j].ID })
+		report = append(report, *usage)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Count != report[j].Count {
+			return report[i].Count > report[j].Count
+		}
+		return strings.ToLower(report[i].Handle) < strings.ToLower(report[j].Handle)
+	})
+	return report
+}
+
+// NormalizeHandle returns a handle as written in messages, adding the leading @
+func NormalizeHandle(handle string) string {
+	handle = strings.TrimSpace(handle)
+	if handle != "" && !strings.HasPrefix(handle, "@") {
+		handle = "@" + handle
+	}
+	return handle
+}
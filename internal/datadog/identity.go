@@ -0,0 +1,172 @@
+package datadog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TemplateIDTagKey is the tag key of the stable identity of a monitor rendered
+// from a template. Upserts look monitors up by it before falling back to the
+// name, so renaming a monitor in a template updates it in place.
+const TemplateIDTagKey = "template-id"
+
+// maxTemplateIDLength keeps template-id tags well under Datadog's 200
+// character tag limit
+const maxTemplateIDLength = 100
+
+// TemplateIdentity returns the template-id tag value of a template: a slug of
+// the template file and template names, e.g. "kubernetes-monitors-cpu-usage".
+// Template variables are part of the identity, so the monitors rendered for
+// different --var or --for-each-tag values stay apart.
+func TemplateIdentity(templateFile, templateName string, vars map[string]string) string {
+	base := filepath.Base(templateFile)
+	parts := []string{strings.TrimSuffix(base, filepath.Ext(base))}
+	if templateName != "" && templateName != "Single Template" {
+		parts = append(parts, templateName)
+	}
+	id := slugify(strings.Join(parts, " "))
+
+	suffix := ""
+	if len(vars) > 0 {
+		keys := make([]string, 0, len(vars))
+		for key := range vars {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, len(keys))
+		for i, key := range keys {
+			pairs[i] = key + "=" + vars[key]
+		}
+		suffix = "-" + shortHash(strings.Join(pairs, "\n"))
+	}
+	if len(id)+len(suffix) > maxTemplateIDLength {
+		id = strings.TrimRight(id[:maxTemplateIDLength-len(suffix)-9], "-") + "-" + shortHash(id)
+	}
+	return id + suffix
+}
+
+// slugify lowercases a string and joins its runs of letters and digits with '-'
+func slugify(value string) string {
+	var b strings.Builder
+	pending := false
+	for _, r := range strings.ToLower(value) {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			pending = true
+			continue
+		}
+		if pending && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		pending = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// shortHash returns the first 8 hex digits of the SHA-256 of a string
+func shortHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])[:8]
+}
+
+// MonitorTemplateID returns the template-id tag value of a monitor, or ""
+func MonitorTemplateID(monitor Monitor) string {
+	for _, tag := range monitor.Tags {
+		if value, ok := strings.CutPrefix(tag, TemplateIDTagKey+":"); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// addTemplateIDTag tags a rendered monitor with its template identity, unless
+// the template sets a template-id tag itself (e.g. to keep the identity when
+// the template file is renamed)
+func addTemplateIDTag(monitor *Monitor, id string) {
+	if id != "" && MonitorTemplateID(*monitor) == "" {
+		monitor.Tags = append(monitor.Tags, TemplateIDTagKey+":"+id)
+	}
+}
+
+// identityTags returns the tags identifying the monitor a rendered monitor
+// upserts: its template-id and its service, env and namespace tags. nil when
+// the monitor has no template-id.
+func identityTags(monitor Monitor) []string {
+	id := MonitorTemplateID(monitor)
+	if id == "" {
+		return nil
+	}
+	tags := []string{TemplateIDTagKey + ":" + id}
+	for _, tag := range monitor.Tags {
+		for _, key := range []string{"service:", "env:", "namespace:"} {
+			if strings.HasPrefix(tag, key) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// matchIdentity returns the monitor among candidates that a rendered monitor
+// upserts by identity: the only one carrying all its identity tags or, when
+// several do, the one with the same name (lowest ID first). It returns nil
+// when there is no single match, and the caller falls back to the name.
+func matchIdentity(monitor Monitor, candidates []Monitor) *Monitor {
+	tags := identityTags(monitor)
+	if tags == nil {
+		return nil
+	}
+
+	var matches []Monitor
+	for _, candidate := range candidates {
+		if hasAllTags(candidate.Tags, tags) {
+			matches = append(matches, candidate)
+		}
+	}
+	if len(matches) == 1 {
+		return &matches[0]
+	}
+
+	// Duplicate identities: only a monitor with the same name is unambiguous
+	sort.Slice(matches, func(i, j int) bool { return matches[i].ID < matches[j].ID })
+	for i := range matches {
+		if matches[i].Name == monitor.Name {
+			return &matches[i]
+		}
+	}
+	return nil
+}
+
+// hasAllTags reports whether tags contains every wanted tag
+func hasAllTags(tags, wanted []string) bool {
+	have := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		have[tag] = true
+	}
+	for _, tag := range wanted {
+		if !have[tag] {
+			return false
+		}
+	}
+	return true
+}
+
+// findUpsertTarget returns the existing monitor an upsert of a rendered
+// monitor updates: the monitor with its template identity or, failing that,
+// the monitor with its name. A name-matched monitor gets the identity tag with
+// the update, which migrates monitors created before identities existed.
+func (c *Client) findUpsertTarget(monitor Monitor) (*Monitor, error) {
+	if tags := identityTags(monitor); tags != nil {
+		candidates, err := c.ListMonitors(tags, "")
+		if err != nil {
+			return nil, err
+		}
+		if existing := matchIdentity(monitor, candidates); existing != nil {
+			return existing, nil
+		}
+	}
+	return c.FindMonitorByName(monitor.Name)
+}
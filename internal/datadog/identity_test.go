@@ -0,0 +1,67 @@
+package datadog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTemplateIdentity(t *testing.T) {
+	for _, tc := range []struct {
+		file, name string
+		want       string
+	}{
+		{"templates/kubernetes-monitors.json", "CPU Usage", "kubernetes-monitors-cpu-usage"},
+		{"/abs/path/Monitors.JSON", "Error Rate (5xx)", "monitors-error-rate-5xx"},
+		// Single-template files are identified by the file alone
+		{"redis.json", "Single Template", "redis"},
+		{"redis.json", "", "redis"},
+	} {
+		if got := TemplateIdentity(tc.file, tc.name, nil); got != tc.want {
+			t.Errorf("TemplateIdentity(%q, %q) = %q, want %q", tc.file, tc.name, got, tc.want)
+		}
+	}
+
+	// Variables are part of the identity, whatever their order
+	a := TemplateIdentity("monitors.json", "CPU", map[string]string{"queue": "orders", "region": "eu"})
+	b := TemplateIdentity("monitors.json", "CPU", map[string]string{"region": "eu", "queue": "orders"})
+	c := TemplateIdentity("monitors.json", "CPU", map[string]string{"queue": "payments", "region": "eu"})
+	if a != b || a == c || !strings.HasPrefix(a, "monitors-cpu-") {
+		t.Errorf("identities with variables: %q, %q, %q", a, b, c)
+	}
+
+	long := TemplateIdentity(strings.Repeat("very-long-file-name-", 10)+".json", "CPU", map[string]string{"queue": "orders"})
+	if len(long) > maxTemplateIDLength {
+		t.Errorf("identity of %d characters, want at most %d: %q", len(long), maxTemplateIDLength, long)
+	}
+}
+
+func TestMatchIdentity(t *testing.T) {
+	monitor := Monitor{Name: "Monitor checkout - Errors", Tags: []string{"service:checkout", "env:prd", "team:payments", "template-id:monitors-error-rate"}}
+	tagged := func(id int, name string, tags ...string) Monitor {
+		return Monitor{ID: id, Name: name, Tags: tags}
+	}
+	identity := []string{"service:checkout", "env:prd", "template-id:monitors-error-rate"}
+
+	for _, tc := range []struct {
+		name       string
+		candidates []Monitor
+		want       int
+	}{
+		{"renamed", []Monitor{tagged(1, "Monitor checkout - Error Rate", identity...)}, 1},
+		{"other env", []Monitor{tagged(1, "Monitor checkout - Error Rate", "service:checkout", "env:stg", "template-id:monitors-error-rate")}, 0},
+		{"no template-id", []Monitor{tagged(1, monitor.Name, "service:checkout", "env:prd")}, 0},
+		// Duplicate identities only match the monitor with the same name
+		{"duplicates", []Monitor{tagged(1, "Old name", identity...), tagged(2, "Other name", identity...)}, 0},
+		{"duplicates with the name", []Monitor{tagged(1, "Old name", identity...), tagged(2, monitor.Name, identity...)}, 2},
+		{"duplicates with the name twice", []Monitor{tagged(7, monitor.Name, identity...), tagged(3, monitor.Name, identity...)}, 3},
+	} {
+		got := matchIdentity(monitor, tc.candidates)
+		if (got == nil) != (tc.want == 0) || got != nil && got.ID != tc.want {
+			t.Errorf("%s: matched %+v, want ID %d", tc.name, got, tc.want)
+		}
+	}
+
+	if got := matchIdentity(Monitor{Name: "x", Tags: []string{"service:checkout"}}, []Monitor{tagged(1, "x", "service:checkout")}); got != nil {
+		t.Errorf("a monitor without template-id matched %+v", got)
+	}
+}
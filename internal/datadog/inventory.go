@@ -0,0 +1,107 @@
+package datadog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/paths"
+)
+
+// DefaultInventoryPath returns the file the monitor inventory is cached in
+// (--cache)
+func DefaultInventoryPath() string {
+	return filepath.Join(paths.CacheDir(), "monitors.json")
+}
+
+// Inventory is the complete monitor list of an organization, cached on disk
+// so read-only commands run in a row don't refetch it
+type Inventory struct {
+	// Key identifies the API and credentials the monitors were listed with,
+	// so organizations never share an inventory
+	Key       string    `json:"key"`
+	FetchedAt time.Time `json:"fetched_at"`
+	Monitors  []Monitor `json:"monitors"`
+}
+
+// Age returns how long ago the inventory was fetched
+func (i *Inventory) Age(now time.Time) time.Duration {
+	return now.Sub(i.FetchedAt)
+}
+
+// Fresh reports whether the inventory was listed with the credentials key
+// identifies less than ttl before now
+func (i *Inventory) Fresh(key string, ttl time.Duration, now time.Time) bool {
+	age := i.Age(now)
+	return i.Key == key && age >= 0 && age < ttl
+}
+
+// LoadInventory reads the inventory cached in path. A missing file returns an
+// error matching os.ErrNotExist; a file that can't be decoded is reported as
+// corrupt.
+func LoadInventory(path string) (*Inventory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var inventory Inventory
+	if err := json.Unmarshal(data, &inventory); err != nil {
+		return nil, fmt.Errorf("corrupt monitor cache %s: %w", path, err)
+	}
+	if inventory.FetchedAt.IsZero() {
+		return nil, fmt.Errorf("corrupt monitor cache %s: no fetch time", path)
+	}
+	return &inventory, nil
+}
+
+// SaveInventory writes the inventory to path, replacing it atomically so a
+// concurrent reader never sees a partial file
+func SaveInventory(path string, inventory *Inventory) error {
+	if err := paths.EnsureParent(path); err != nil {
+		return err
+	}
+	data, err := json.Marshal(inventory)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// ClearInventory removes the inventory cached in path; a missing file is not
+// an error
+func ClearInventory(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// InventoryKey identifies the API URL and credentials of the client
+func (c *Client) InventoryKey() string {
+	h := sha256.New()
+	io.WriteString(h, c.config.APIURL+" "+c.config.APIKey+" "+c.config.AppKey)
+	return hex.EncodeToString(h.Sum(nil))
+}
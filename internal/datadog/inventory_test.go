@@ -0,0 +1,115 @@
+package datadog
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInventoryFresh(t *testing.T) {
+	fetched := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	inventory := &Inventory{Key: "key", FetchedAt: fetched}
+	for _, tc := range []struct {
+		name string
+		key  string
+		now  time.Time
+		want bool
+	}{
+		{"just fetched", "key", fetched, true},
+		{"within the TTL", "key", fetched.Add(4 * time.Minute), true},
+		{"at the TTL", "key", fetched.Add(5 * time.Minute), false},
+		{"expired", "key", fetched.Add(time.Hour), false},
+		{"other credentials", "other", fetched.Add(time.Minute), false},
+		// A clock set back doesn't keep a file from the future fresh
+		{"fetched in the future", "key", fetched.Add(-time.Minute), false},
+	} {
+		if got := inventory.Fresh(tc.key, 5*time.Minute, tc.now); got != tc.want {
+			t.Errorf("%s: Fresh = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestLoadInventory(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cache", "monitors.json")
+	if _, err := LoadInventory(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LoadInventory of a missing file = %v, want os.ErrNotExist", err)
+	}
+
+	saved := &Inventory{Key: "key", FetchedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), Monitors: []Monitor{{ID: 1, Name: "CPU", Tags: []string{"service:api"}}}}
+	if err := SaveInventory(path, saved); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadInventory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Key != "key" || !loaded.FetchedAt.Equal(saved.FetchedAt) || len(loaded.Monitors) != 1 || loaded.Monitors[0].Name != "CPU" {
+		t.Errorf("loaded %+v, want %+v", loaded, saved)
+	}
+	// No temporary file is left behind
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("%d files in the cache directory, want 1", len(entries))
+	}
+
+	for _, corrupt := range []string{`{"key": "key", "monitors": [`, `{"key": "key", "monitors": []}`, `not json`} {
+		if err := os.WriteFile(path, []byte(corrupt), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadInventory(path); err == nil || !strings.Contains(err.Error(), "corrupt monitor cache") {
+			t.Errorf("LoadInventory(%q) = %v, want a corrupt cache error", corrupt, err)
+		}
+	}
+
+	if err := ClearInventory(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := ClearInventory(path); err != nil {
+		t.Errorf("ClearInventory of a missing file: %v", err)
+	}
+}
+
+func TestInventoryInvalidatedByMutations(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodDelete:
+			w.Write([]byte(`{"deleted_monitor_id": 1}`))
+		default:
+			w.Write([]byte(`{"id": 1, "name": "CPU", "type": "metric alert", "query": "avg(last_5m):avg:cpu{*} > 90"}`))
+		}
+	}))
+	defer srv.Close()
+	path := filepath.Join(t.TempDir(), "monitors.json")
+	client, err := NewClientWithOptions(WithAPIKey("api-key"), WithAppKey("app-key"), WithBaseURL(srv.URL), WithInventoryFile(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	monitor := &Monitor{Name: "CPU", Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90"}
+
+	for _, tc := range []struct {
+		name     string
+		request  func() error
+		mutating bool
+	}{
+		{"get", func() error { _, err := client.GetMonitor(1); return err }, false},
+		{"create", func() error { _, err := client.CreateMonitor(monitor); return err }, true},
+		{"update", func() error { _, err := client.UpdateMonitor(1, monitor); return err }, true},
+		{"delete", func() error { return client.DeleteMonitor(1) }, true},
+	} {
+		if err := SaveInventory(path, &Inventory{Key: client.InventoryKey(), FetchedAt: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+		if err := tc.request(); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		_, err := os.Stat(path)
+		if cleared := errors.Is(err, os.ErrNotExist); cleared != tc.mutating {
+			t.Errorf("%s: inventory cleared %v, want %v", tc.name, cleared, tc.mutating)
+		}
+	}
+}
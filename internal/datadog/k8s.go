@@ -0,0 +1,55 @@
+package datadog
+
+import (
+	"regexp"
+)
+
+// DefaultK8sDelay is the evaluation_delay and new_group_delay, in seconds,
+// K8sDefaults injects unless configured otherwise
+const DefaultK8sDelay = 300
+
+// K8sDefaults are the options injected into metric monitors on Kubernetes and
+// container metrics, which report late and flap right after deploys without them
+type K8sDefaults struct {
+	EvaluationDelay int
+	NewGroupDelay   int
+}
+
+// k8sMetricPattern matches a kubernetes., kubernetes_state. or container.
+// metric name in a query whose scopes have been removed
+var k8sMetricPattern = regexp.MustCompile(`(?:^|[^A-Za-z0-9_.])(?:kubernetes|kubernetes_state|container)\.[A-Za-z0-9_]`)
+
+// scopePattern matches the {...} scopes and group-by lists of a query
+var scopePattern = regexp.MustCompile(`\{[^{}]*\}`)
+
+// UsesK8sMetrics reports whether a metric query references kubernetes.*,
+// kubernetes_state.* or container.* metrics. Tag values in scopes (e.g.
+// {image:container.app}) don't count.
+func UsesK8sMetrics(query string) bool {
+	return k8sMetricPattern.MatchString(scopePattern.ReplaceAllString(query, "{}"))
+}
+
+// ApplyK8sDefaults sets evaluation_delay and new_group_delay on a metric
+// monitor on Kubernetes or container metrics, leaving options the template
+// already sets alone. It returns the names of the options it set.
+func ApplyK8sDefaults(monitor *Monitor, defaults K8sDefaults) []string {
+	if !IsMetricMonitor(monitor.Type) || !UsesK8sMetrics(monitor.Query) {
+		return nil
+	}
+	if monitor.Options == nil {
+		monitor.Options = make(map[string]interface{})
+	}
+
+	var injected []string
+	for _, option := range []struct {
+		name  string
+		value int
+	}{{"evaluation_delay", defaults.EvaluationDelay}, {"new_group_delay", defaults.NewGroupDelay}} {
+		if _, ok := monitor.Options[option.name]; ok || option.value <= 0 {
+			continue
+		}
+		monitor.Options[option.name] = option.value
+		injected = append(injected, option.name)
+	}
+	return injected
+}
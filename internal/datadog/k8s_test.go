@@ -0,0 +1,78 @@
+package datadog
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUsesK8sMetrics(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		want  bool
+	}{
+		{"avg(last_5m):avg:kubernetes.cpu.usage.total{service:api} > 90", true},
+		{"max(last_10m):max:kubernetes_state.deployment.replicas_unavailable{*} by {deployment} > 0", true},
+		{"avg(last_5m):avg:container.memory.usage{kube_namespace:shop} by {pod_name} > 1e9", true},
+		// Inside a formula, after an operator or a function
+		{"avg(last_5m):100 * avg:kubernetes.cpu.usage.total{*} / avg:kubernetes.cpu.limits{*} > 90", true},
+		{"avg(last_5m):abs(avg:container.cpu.throttled{*}) > 1", true},
+		{"avg(last_5m):avg:system.cpu.user{*}/avg:container.cpu.usage{*} > 1", true},
+		// Other metrics
+		{"avg(last_5m):avg:system.cpu.user{*} > 90", false},
+		{"avg(last_5m):avg:docker.cpu.usage{*} > 90", false},
+		// Prefixes are whole metric namespaces
+		{"avg(last_5m):avg:mykubernetes.cpu{*} > 90", false},
+		{"avg(last_5m):avg:app.container.restarts{*} > 1", false},
+		{"avg(last_5m):avg:my_container.restarts{*} > 1", false},
+		{"avg(last_5m):avg:kubernetes_custom.restarts{*} > 1", false},
+		{"avg(last_5m):avg:container{*} > 1", false},
+		// Tag values and group-bys in scopes don't count
+		{"avg(last_5m):avg:system.cpu.user{image:container.app} > 90", false},
+		{"avg(last_5m):avg:system.cpu.user{*} by {kubernetes.pod} > 90", false},
+		{"", false},
+	} {
+		if got := UsesK8sMetrics(tc.query); got != tc.want {
+			t.Errorf("UsesK8sMetrics(%q) = %v, want %v", tc.query, got, tc.want)
+		}
+	}
+}
+
+func TestApplyK8sDefaults(t *testing.T) {
+	k8sQuery := "avg(last_5m):avg:kubernetes.cpu.usage.total{*} > 90"
+	defaults := K8sDefaults{EvaluationDelay: 300, NewGroupDelay: 600}
+	for _, tc := range []struct {
+		name    string
+		monitor Monitor
+		want    string
+		// options are the resulting evaluation_delay and new_group_delay
+		options string
+	}{
+		{"metric monitor", Monitor{Type: "metric alert", Query: k8sQuery}, "evaluation_delay,new_group_delay", "300 600"},
+		{"query alert", Monitor{Type: "query alert", Query: k8sQuery, Options: map[string]interface{}{"notify_no_data": true}}, "evaluation_delay,new_group_delay", "300 600"},
+		// The template's own options win
+		{"template sets one", Monitor{Type: "metric alert", Query: k8sQuery, Options: map[string]interface{}{"evaluation_delay": 60}}, "new_group_delay", "60 600"},
+		{"template sets zero", Monitor{Type: "metric alert", Query: k8sQuery, Options: map[string]interface{}{"evaluation_delay": 0, "new_group_delay": 0}}, "", "0 0"},
+		{"other metrics", Monitor{Type: "metric alert", Query: "avg(last_5m):avg:system.cpu.user{*} > 90"}, "", "<nil> <nil>"},
+		{"not a metric monitor", Monitor{Type: "service check", Query: `"kubernetes.kubelet.check".over("*").last(2).count_by_status()`}, "", "<nil> <nil>"},
+	} {
+		monitor := tc.monitor
+		injected := ApplyK8sDefaults(&monitor, defaults)
+		if got := strings.Join(injected, ","); got != tc.want {
+			t.Errorf("%s: injected %q, want %q", tc.name, got, tc.want)
+		}
+		var options []string
+		for _, option := range []string{"evaluation_delay", "new_group_delay"} {
+			options = append(options, fmt.Sprint(monitor.Options[option]))
+		}
+		if got := strings.Join(options, " "); got != tc.options {
+			t.Errorf("%s: options %s, want %s", tc.name, got, tc.options)
+		}
+	}
+
+	// A zero delay is not injected
+	monitor := Monitor{Type: "metric alert", Query: k8sQuery}
+	if injected := ApplyK8sDefaults(&monitor, K8sDefaults{NewGroupDelay: 120}); strings.Join(injected, ",") != "new_group_delay" {
+		t.Errorf("injected %v with a zero evaluation_delay", injected)
+	}
+}
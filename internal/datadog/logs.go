@@ -0,0 +1,302 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LogMonitorBlock is the structured "log" block of a log alert template, compiled
+// into a logs("...").index("...").rollup(...).by(...).last(...) query
+type LogMonitorBlock struct {
+	Index string `json:"index,omitempty"`
+	// Query is the log search, e.g. "service:{service} status:error"
+	Query   string   `json:"query"`
+	GroupBy []string `json:"group_by,omitempty"`
+	// Rollup is the aggregation (default count); all but count need a Measure
+	Rollup    string            `json:"rollup,omitempty"`
+	Measure   string            `json:"measure,omitempty"`
+	Threshold LogBlockThreshold `json:"threshold"`
+}
+
+// LogBlockThreshold is the alert condition of a log block
+type LogBlockThreshold struct {
+	Critical   float64  `json:"critical"`
+	Warning    *float64 `json:"warning,omitempty"`
+	Window     string   `json:"window"`               // e.g. 5m, 1h
+	Comparator string   `json:"comparator,omitempty"` // default >
+}
+
+// logWindowPattern matches the windows accepted by last(), e.g. 5m, 1h, 2d
+var logWindowPattern = regexp.MustCompile(`^\d+[mhd]$`)
+
+// logQueryPattern matches a compiled log monitor query
+var logQueryPattern = regexp.MustCompile(`^logs\("((?:[^"\\]|\\.)*)"\)` +
+	`(?:\.index\("([^"]*)"\))?` +
+	`\.rollup\("(\w+)"(?:\s*,\s*"([^"]*)")?\)` +
+	`(?:\.by\(((?:\s*"[^"]*"\s*,?)*)\))?` +
+	`\.last\("(\w+)"\)\s*(>=|<=|>|<)\s*(-?[\d.]+)$`)
+
+// logSearchSpecialChars must be escaped (or quoted) in log search values
+const logSearchSpecialChars = `:/[]{}^~="`
+
+// CompileLogQuery builds the log monitor query of a log block
+func CompileLogQuery(block LogMonitorBlock) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "logs(%s)", strconv.Quote(block.Query))
+	if block.Index != "" {
+		fmt.Fprintf(&b, ".index(%s)", strconv.Quote(block.Index))
+	}
+	rollup := block.Rollup
+	if rollup == "" {
+		rollup = "count"
+	}
+	if block.Measure != "" {
+		fmt.Fprintf(&b, ".rollup(%s, %s)", strconv.Quote(rollup), strconv.Quote(block.Measure))
+	} else {
+		fmt.Fprintf(&b, ".rollup(%s)", strconv.Quote(rollup))
+	}
+	if len(block.GroupBy) > 0 {
+		quoted := make([]string, len(block.GroupBy))
+		for i, facet := range block.GroupBy {
+			quoted[i] = strconv.Quote(facet)
+		}
+		fmt.Fprintf(&b, ".by(%s)", strings.Join(quoted, ","))
+	}
+	comparator := block.Threshold.Comparator
+	if comparator == "" {
+		comparator = ">"
+	}
+	fmt.Fprintf(&b, ".last(%s) %s %s", strconv.Quote(block.Threshold.Window), comparator, formatNumber(block.Threshold.Critical))
+	return b.String()
+}
+
+// DecompileLogQuery turns a log monitor query back into a log block. ok is
+// false for queries the block can't express (e.g., several indexes or formulas).
+// The warning threshold is taken from the monitor options, if any.
+func DecompileLogQuery(query string, options map[string]interface{}) (block LogMonitorBlock, ok bool) {
+	match := logQueryPattern.FindStringSubmatch(strings.TrimSpace(query))
+	if match == nil {
+		return block, false
+	}
+	search, err := strconv.Unquote(`"` + match[1] + `"`)
+	if err != nil {
+		return block, false
+	}
+	critical, err := strconv.ParseFloat(match[8], 64)
+	if err != nil {
+		return block, false
+	}
+
+	block = LogMonitorBlock{
+		Index:   match[2],
+		Query:   search,
+		Measure: match[4],
+		Threshold: LogBlockThreshold{
+			Critical: critical,
+			Window:   match[6],
+		},
+	}
+	if match[3] != "count" || match[4] != "" {
+		block.Rollup = match[3]
+	}
+	if match[7] != ">" {
+		block.Threshold.Comparator = match[7]
+	}
+	for _, facet := range strings.Split(match[5], ",") {
+		if facet = strings.Trim(strings.TrimSpace(facet), `"`); facet != "" {
+			block.GroupBy = append(block.GroupBy, facet)
+		}
+	}
+	if thresholds, ok := options["thresholds"].(map[string]interface{}); ok {
+		if warning, ok := thresholds["warning"].(float64); ok {
+			block.Threshold.Warning = &warning
+		}
+	}
+	return block, true
+}
+
+// formatNumber formats a threshold without a trailing .0
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// LintLogSearch returns the problems of a log search: facet attribute paths
+// without @ and values with unescaped special characters
+func LintLogSearch(search string) []string {
+	var problems []string
+	for _, term := range logSearchTerms(search) {
+		term = strings.TrimLeft(term, "-!(")
+		key, value, found := strings.Cut(term, ":")
+		if !found || key == "" {
+			continue
+		}
+		if !strings.HasPrefix(key, "@") && strings.Contains(key, ".") {
+			problems = append(problems, fmt.Sprintf("facet %q is an attribute path and must be written @%s", key, key))
+		}
+		if strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "(") || strings.HasPrefix(value, "[") {
+			continue
+		}
+		value = strings.TrimLeft(strings.TrimRight(value, ")"), "<>=")
+		if char, ok := unescapedSpecialChar(placeholderPattern.ReplaceAllString(value, "x")); ok {
+			problems = append(problems, fmt.Sprintf("value of %s has an unescaped %q (escape it with \\ or quote the value)", key, char))
+		}
+	}
+	return problems
+}
+
+// LintLogFacet returns the problem of a group-by facet, if any
+func LintLogFacet(facet string) string {
+	switch {
+	case strings.TrimSpace(facet) == "":
+		return "group_by facet is empty"
+	case !strings.HasPrefix(facet, "@") && strings.Contains(facet, "."):
+		return fmt.Sprintf("facet %q is an attribute path and must be written @%s", facet, facet)
+	case strings.ContainsAny(facet, ` "`):
+		return fmt.Sprintf("facet %q contains spaces or quotes", facet)
+	}
+	return ""
+}
+
+// logSearchTerms splits a log search on whitespace outside quoted values
+func logSearchTerms(search string) []string {
+	var terms []string
+	var current strings.Builder
+	inQuotes, escaped := false, false
+	for _, r := range search {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			inQuotes = !inQuotes
+		case (r == ' ' || r == '\t' || r == '\n') && !inQuotes:
+			if current.Len() > 0 {
+				terms = append(terms, current.String())
+				current.Reset()
+			}
+			continue
+		}
+		current.WriteRune(r)
+	}
+	if current.Len() > 0 {
+		terms = append(terms, current.String())
+	}
+	return terms
+}
+
+// unescapedSpecialChar returns the first special character of value not preceded by \
+func unescapedSpecialChar(value string) (string, bool) {
+	escaped := false
+	for _, r := range value {
+		if escaped {
+			escaped = false
+			continue
+		}
+		if r == '\\' {
+			escaped = true
+			continue
+		}
+		if strings.ContainsRune(logSearchSpecialChars, r) {
+			return string(r), true
+		}
+	}
+	return "", false
+}
+
+// lintLogTemplate checks the log block of a template beyond the schema: the
+// index is set, facets are written with @, values are escaped, the template is
+// a log alert and doesn't also set a query
+func lintLogTemplate(config map[string]interface{}, pointer string) []SchemaViolation {
+	raw, ok := config["log"]
+	if !ok {
+		_, hasQuery := config["query"]
+		_, hasServiceCheck := config["service_check"]
+		_, hasAPM := config["apm"]
+		if !hasQuery && !hasServiceCheck && !hasAPM {
+			return []SchemaViolation{{Pointer: pointer, Message: `missing required property "query" (or a "log", "service_check" or "apm" block)`}}
+		}
+		return nil
+	}
+	block, err := decodeLogBlock(raw)
+	if err != nil {
+		// Type problems are reported by the schema
+		return nil
+	}
+
+	var violations []SchemaViolation
+	logPointer := pointer + "/log"
+	if _, hasQuery := config["query"]; hasQuery {
+		violations = append(violations, SchemaViolation{Pointer: pointer + "/query", Message: `set either "query" or a "log" block, not both`})
+	}
+	if monitorType, _ := config["type"].(string); monitorType != "log alert" {
+		violations = append(violations, SchemaViolation{Pointer: pointer + "/type", Message: `a "log" block needs type "log alert"`, Value: config["type"]})
+	}
+	if block.Index == "" {
+		violations = append(violations, SchemaViolation{Pointer: logPointer, Message: `missing "index" (use "*" to search every index)`})
+	}
+	for _, problem := range LintLogSearch(block.Query) {
+		violations = append(violations, SchemaViolation{Pointer: logPointer + "/query", Message: problem})
+	}
+	for i, facet := range block.GroupBy {
+		if problem := LintLogFacet(facet); problem != "" {
+			violations = append(violations, SchemaViolation{Pointer: fmt.Sprintf("%s/group_by/%d", logPointer, i), Message: problem})
+		}
+	}
+	if block.Rollup != "" && block.Rollup != "count" && block.Measure == "" {
+		violations = append(violations, SchemaViolation{Pointer: logPointer + "/measure", Message: fmt.Sprintf("rollup %q needs a measure (e.g., @duration)", block.Rollup)})
+	}
+	if !logWindowPattern.MatchString(block.Threshold.Window) {
+		violations = append(violations, SchemaViolation{Pointer: logPointer + "/threshold/window", Message: "must be a duration like 5m, 1h or 1d", Value: block.Threshold.Window})
+	}
+	return violations
+}
+
+// decodeLogBlock converts the "log" value of a template into a LogMonitorBlock
+func decodeLogBlock(raw interface{}) (LogMonitorBlock, error) {
+	var block LogMonitorBlock
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return block, err
+	}
+	err = json.Unmarshal(data, &block)
+	return block, err
+}
+
+// compileLogBlocks replaces the "log" block of each template with the compiled
+// query, and sets the critical/warning thresholds unless the template sets them
+func compileLogBlocks(templateFile string, templates []TemplateData) error {
+	for _, template := range templates {
+		raw, ok := template.Config["log"]
+		if !ok {
+			continue
+		}
+		block, err := decodeLogBlock(raw)
+		if err != nil {
+			return fmt.Errorf("invalid log block in template %s (%s): %v", template.Name, templateFile, err)
+		}
+		delete(template.Config, "log")
+		template.Config["query"] = CompileLogQuery(block)
+
+		options, _ := template.Config["options"].(map[string]interface{})
+		if options == nil {
+			options = make(map[string]interface{})
+			template.Config["options"] = options
+		}
+		thresholds, _ := options["thresholds"].(map[string]interface{})
+		if thresholds == nil {
+			thresholds = make(map[string]interface{})
+			options["thresholds"] = thresholds
+		}
+		if _, ok := thresholds["critical"]; !ok {
+			thresholds["critical"] = block.Threshold.Critical
+		}
+		if _, ok := thresholds["warning"]; !ok && block.Threshold.Warning != nil {
+			thresholds["warning"] = *block.Threshold.Warning
+		}
+	}
+	return nil
+}
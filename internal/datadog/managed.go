@@ -0,0 +1,135 @@
+package datadog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ManagedByTag marks monitors created or updated by datadog-monitor-manager
+const ManagedByTag = "managed-by:ddmm"
+
+// IsManaged reports whether a monitor carries ManagedByTag
+func IsManaged(monitor Monitor) bool {
+	for _, tag := range monitor.Tags {
+		if tag == ManagedByTag {
+			return true
+		}
+	}
+	return false
+}
+
+// addManagedByTag adds ManagedByTag to a rendered monitor
+func addManagedByTag(monitor *Monitor) {
+	if !IsManaged(*monitor) {
+		monitor.Tags = append(monitor.Tags, ManagedByTag)
+	}
+}
+
+// DefaultProtectedTagKey is the tag key that, set to true, keeps a monitor out
+// of bulk changes
+const DefaultProtectedTagKey = "protected"
+
+// IsProtected reports whether a monitor carries the tag key:true (the value
+// case-insensitive)
+func IsProtected(monitor Monitor, key string) bool {
+	for _, tag := range monitor.Tags {
+		if value, ok := strings.CutPrefix(tag, key+":"); ok && strings.EqualFold(value, "true") {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmanagedMonitorError is returned when an upsert matches a monitor by name
+// that is not managed by the tool and unmanaged monitors are protected
+type UnmanagedMonitorError struct {
+	ID      int
+	Name    string
+	Creator *Creator
+}
+
+// Error implements the error interface
+func (e *UnmanagedMonitorError) Error() string {
+	creator := "unknown"
+	if e.Creator != nil {
+		creator = e.Creator.Email
+		if creator == "" {
+			creator = e.Creator.Handle
+		}
+	}
+	return fmt.Sprintf("monitor %d %q exists but is not managed by this tool (no %s tag, created by %s)", e.ID, e.Name, ManagedByTag, creator)
+}
+
+// IsUnmanagedConflict reports whether err (or an error it wraps) is an UnmanagedMonitorError
+func IsUnmanagedConflict(err error) bool {
+	var conflict *UnmanagedMonitorError
+	return errors.As(err, &conflict)
+}
+
+// FingerprintTagKey is the tag key of the fingerprint of a monitor rendered
+// from a template: a hash of its name, query and type. A create whose response
+// was lost (e.g. the connection dropped after Datadog processed the POST) is
+// recognized by it on the next run, instead of creating a duplicate.
+const FingerprintTagKey = "ddmm-fingerprint"
+
+// MonitorFingerprint returns the fingerprint of a monitor's name, query and type
+func MonitorFingerprint(monitor Monitor) string {
+	sum := sha256.Sum256([]byte(monitor.Name + "\n" + monitor.Query + "\n" + monitor.Type))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// fingerprintTag returns the fingerprint tag a monitor carries, or ""
+func fingerprintTag(monitor Monitor) string {
+	for _, tag := range monitor.Tags {
+		if strings.HasPrefix(tag, FingerprintTagKey+":") {
+			return tag
+		}
+	}
+	return ""
+}
+
+// addFingerprintTag tags a rendered monitor with its fingerprint, replacing a
+// fingerprint tag set by the template
+func addFingerprintTag(monitor *Monitor) {
+	tags := make([]string, 0, len(monitor.Tags)+1)
+	for _, tag := range monitor.Tags {
+		if !strings.HasPrefix(tag, FingerprintTagKey+":") {
+			tags = append(tags, tag)
+		}
+	}
+	monitor.Tags = append(tags, FingerprintTagKey+":"+MonitorFingerprint(*monitor))
+}
+
+// matchFingerprint returns the monitor among candidates carrying the
+// fingerprint tag of a rendered monitor (the lowest ID if there are several),
+// or nil
+func matchFingerprint(monitor Monitor, candidates []Monitor) *Monitor {
+	tag := fingerprintTag(monitor)
+	if tag == "" {
+		return nil
+	}
+	var found *Monitor
+	for i := range candidates {
+		if hasAllTags(candidates[i].Tags, []string{tag}) && (found == nil || candidates[i].ID < found.ID) {
+			found = &candidates[i]
+		}
+	}
+	return found
+}
+
+// findCreated returns the monitor an earlier create of a rendered monitor
+// made, found by its fingerprint tag, or nil when there is none
+func (c *Client) findCreated(monitor Monitor) (*Monitor, error) {
+	tag := fingerprintTag(monitor)
+	if tag == "" {
+		return nil, nil
+	}
+	candidates, err := c.ListMonitors([]string{tag}, "")
+	if err != nil {
+		return nil, err
+	}
+	return matchFingerprint(monitor, candidates), nil
+}
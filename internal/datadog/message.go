@@ -0,0 +1,185 @@
+package datadog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// templateBlockPattern matches Datadog message template blocks such as
+// {{#is_alert}}, {{/is_alert}} and {{value}}
+var templateBlockPattern = regexp.MustCompile(`\{\{[^}]*\}\}`)
+
+// RegexEdit is a regular expression replacement applied to a message
+type RegexEdit struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// TextReplacement is a literal old -> new replacement applied to a message
+type TextReplacement struct {
+	Old string
+	New string
+}
+
+// MessageEdit describes the changes to apply to a monitor message
+type MessageEdit struct {
+	Append                string
+	Prepend               string
+	Replace               []TextReplacement
+	Regex                 []RegexEdit
+	IncludeTemplateBlocks bool
+}
+
+// ParseReplacement parses an "old=new" replacement flag value
+func ParseReplacement(value string) (TextReplacement, error) {
+	idx := strings.Index(value, "=")
+	if idx <= 0 {
+		return TextReplacement{}, fmt.Errorf("invalid replacement %q (expected old=new)", value)
+	}
+	return TextReplacement{Old: value[:idx], New: value[idx+1:]}, nil
+}
+
+// ParseRegexEdit parses a "pattern=replacement" regex flag value
+func ParseRegexEdit(value string) (RegexEdit, error) {
+	r, err := ParseReplacement(value)
+	if err != nil {
+		return RegexEdit{}, fmt.Errorf("invalid regex %q (expected pattern=replacement)", value)
+	}
+	re, err := regexp.Compile(r.Old)
+	if err != nil {
+		return RegexEdit{}, fmt.Errorf("invalid regex %q: %v", r.Old, err)
+	}
+	return RegexEdit{Pattern: re, Replacement: r.New}, nil
+}
+
+// IsEmpty reports whether the edit would not change anything
+func (e MessageEdit) IsEmpty() bool {
+	return e.Append == "" && e.Prepend == "" && len(e.Replace) == 0 && len(e.Regex) == 0
+}
+
+// Apply applies the edit to a message and reports whether it changed.
+// Append and prepend are idempotent: they are skipped when the message already
+// contains the text. Regex edits leave {{...}} template blocks untouched unless
+// IncludeTemplateBlocks is set.
+func (e MessageEdit) Apply(message string) (string, bool) {
+	edited := message
+
+	for _, r := range e.Replace {
+		edited = strings.ReplaceAll(edited, r.Old, r.New)
+	}
+
+	for _, re := range e.Regex {
+		if e.IncludeTemplateBlocks {
+			edited = re.Pattern.ReplaceAllString(edited, re.Replacement)
+		} else {
+			edited = replaceOutsideTemplateBlocks(edited, re)
+		}
+	}
+
+	if e.Prepend != "" && !strings.Contains(edited, e.Prepend) {
+		edited = e.Prepend + edited
+	}
+
+	if e.Append != "" && !strings.Contains(edited, e.Append) {
+		edited = edited + e.Append
+	}
+
+	return edited, edited != message
+}
+
+// replaceOutsideTemplateBlocks applies a regex edit only to the text between
+// {{...}} template blocks
+func replaceOutsideTemplateBlocks(message string, re RegexEdit) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range templateBlockPattern.FindAllStringIndex(message, -1) {
+		b.WriteString(re.Pattern.ReplaceAllString(message[last:loc[0]], re.Replacement))
+		b.WriteString(message[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(re.Pattern.ReplaceAllString(message[last:], re.Replacement))
+	return b.String()
+}
+
+// EditMonitorMessage applies a message edit to a monitor. The monitor is only
+// updated when the message actually changes; the returned bool reports that.
+func (c *Client) EditMonitorMessage(monitorID int, edit MessageEdit) (*Monitor, bool, error) {
+	monitor, err := c.GetMonitor(monitorID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	message, changed := edit.Apply(monitor.Message)
+	if !changed {
+		return monitor, false, nil
+	}
+
+	monitor.Message = message
+	updatedMonitor, err := c.UpdateMonitor(monitorID, monitor)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return updatedMonitor, true, nil
+}
+
+// EscalationMessage returns the escalation message a monitor sends with its
+// re-notifications, "" when it has none
+func EscalationMessage(monitor Monitor) string {
+	escalation, _ := monitor.Options["escalation_message"].(string)
+	return escalation
+}
+
+// EscalationEdit describes the changes to apply to a monitor's escalation
+// message: Clear or Set replace it whole, then the message edits apply
+type EscalationEdit struct {
+	Set   string
+	Clear bool
+	MessageEdit
+}
+
+// IsEmpty reports whether the edit would not change anything
+func (e EscalationEdit) IsEmpty() bool {
+	return e.Set == "" && !e.Clear && e.MessageEdit.IsEmpty()
+}
+
+// Apply applies the edit to an escalation message and reports whether it changed
+func (e EscalationEdit) Apply(escalation string) (string, bool) {
+	edited := escalation
+	if e.Clear {
+		edited = ""
+	}
+	if e.Set != "" {
+		edited = e.Set
+	}
+	edited, _ = e.MessageEdit.Apply(edited)
+	return edited, edited != escalation
+}
+
+// EditMonitorEscalation applies an escalation message edit to a monitor. The
+// monitor is only updated when the escalation message actually changes; the
+// returned bool reports that. A cleared escalation message is sent empty, as
+// an option left out of an update keeps its value.
+func (c *Client) EditMonitorEscalation(monitorID int, edit EscalationEdit) (*Monitor, bool, error) {
+	monitor, err := c.GetMonitor(monitorID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	escalation, changed := edit.Apply(EscalationMessage(*monitor))
+	if !changed {
+		return monitor, false, nil
+	}
+
+	if monitor.Options == nil {
+		monitor.Options = make(map[string]interface{})
+	}
+	monitor.Options["escalation_message"] = escalation
+	updatedMonitor, err := c.UpdateMonitor(monitorID, monitor)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return updatedMonitor, true, nil
+}
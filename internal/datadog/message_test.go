@@ -0,0 +1,102 @@
+package datadog
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestMessageEditApply(t *testing.T) {
+	regex := func(pattern, replacement string) []RegexEdit {
+		return []RegexEdit{{Pattern: regexp.MustCompile(pattern), Replacement: replacement}}
+	}
+	for _, tc := range []struct {
+		name    string
+		edit    MessageEdit
+		message string
+		want    string
+		changed bool
+	}{
+		{"append", MessageEdit{Append: "\n@pagerduty"}, "CPU high", "CPU high\n@pagerduty", true},
+		{"append twice", MessageEdit{Append: "\n@pagerduty"}, "CPU high\n@pagerduty", "CPU high\n@pagerduty", false},
+		{"prepend", MessageEdit{Prepend: "[prd] "}, "CPU high", "[prd] CPU high", true},
+		{"prepend twice", MessageEdit{Prepend: "[prd] "}, "[prd] CPU high", "[prd] CPU high", false},
+		{"literal replace", MessageEdit{Replace: []TextReplacement{{Old: "@slack-old", New: "@slack-new"}}}, "@slack-old and @slack-old", "@slack-new and @slack-new", true},
+		{"literal replace inside blocks", MessageEdit{Replace: []TextReplacement{{Old: "is_alert", New: "is_warning"}}}, "{{#is_alert}}page{{/is_alert}}", "{{#is_warning}}page{{/is_warning}}", true},
+		{"nothing to replace", MessageEdit{Replace: []TextReplacement{{Old: "@slack-old", New: "@slack-new"}}}, "page @oncall", "page @oncall", false},
+		{"regex outside blocks", MessageEdit{Regex: regex(`runbook: \S+`, "runbook: https://wiki/cpu")}, "CPU high, runbook: http://old", "CPU high, runbook: https://wiki/cpu", true},
+		{"regex groups", MessageEdit{Regex: regex(`@slack-(\w+)`, "@teams-$1")}, "page @slack-ops", "page @teams-ops", true},
+		{"regex applied in order", MessageEdit{Regex: append(regex(`a`, "b"), regex(`b`, "c")...)}, "a", "c", true},
+		{"replace, regex, prepend and append", MessageEdit{Replace: []TextReplacement{{Old: "old", New: "new"}}, Regex: regex(`new`, "newer"), Prepend: "> ", Append: " <"}, "old", "> newer <", true},
+		{"append already present after replace", MessageEdit{Replace: []TextReplacement{{Old: "@a", New: "@b"}}, Append: "@b"}, "page @a", "page @b", true},
+	} {
+		got, changed := tc.edit.Apply(tc.message)
+		if got != tc.want || changed != tc.changed {
+			t.Errorf("%s: Apply(%q) = %q, %v, want %q, %v", tc.name, tc.message, got, changed, tc.want, tc.changed)
+		}
+	}
+}
+
+func TestMessageEditTemplateBlocks(t *testing.T) {
+	const message = "{{#is_alert}}alert on {{host.name}} @slack-alerts{{/is_alert}} {{#is_warning}}warn @slack-alerts{{/is_warning}} @slack-alerts"
+	for _, tc := range []struct {
+		name    string
+		pattern string
+		include bool
+		want    string
+	}{
+		{
+			"text inside and outside blocks",
+			`@slack-alerts`, false,
+			"{{#is_alert}}alert on {{host.name}} @slack-new{{/is_alert}} {{#is_warning}}warn @slack-new{{/is_warning}} @slack-new",
+		},
+		{
+			"block names left alone",
+			`is_alert`, false,
+			message,
+		},
+		{
+			"template variables left alone",
+			`host\.name`, false,
+			message,
+		},
+		{
+			"a match spanning a block is not replaced",
+			`alerts\{\{/is_alert`, false,
+			message,
+		},
+		{
+			"blocks included",
+			`is_alert`, true,
+			"{{#@slack-new}}alert on {{host.name}} @slack-alerts{{/@slack-new}} {{#is_warning}}warn @slack-alerts{{/is_warning}} @slack-alerts",
+		},
+		{
+			"variables included",
+			`host\.name`, true,
+			"{{#is_alert}}alert on {{@slack-new}} @slack-alerts{{/is_alert}} {{#is_warning}}warn @slack-alerts{{/is_warning}} @slack-alerts",
+		},
+	} {
+		edit := MessageEdit{Regex: []RegexEdit{{Pattern: regexp.MustCompile(tc.pattern), Replacement: "@slack-new"}}, IncludeTemplateBlocks: tc.include}
+		got, changed := edit.Apply(message)
+		if got != tc.want || changed != (tc.want != message) {
+			t.Errorf("%s: Apply = %q, %v, want %q", tc.name, got, changed, tc.want)
+		}
+	}
+}
+
+func TestReplaceOutsideTemplateBlocks(t *testing.T) {
+	re := RegexEdit{Pattern: regexp.MustCompile(`x`), Replacement: "y"}
+	for _, tc := range []struct{ message, want string }{
+		{"", ""},
+		{"xx", "yy"},
+		{"{{x}}", "{{x}}"},
+		{"x{{x}}x", "y{{x}}y"},
+		{"{{#is_alert}}x{{/is_alert}}{{x}}", "{{#is_alert}}y{{/is_alert}}{{x}}"},
+		// An unclosed block is plain text
+		{"{{x x", "{{y y"},
+		{"x}} {{x", "y}} {{y"},
+	} {
+		if got := replaceOutsideTemplateBlocks(tc.message, re); got != tc.want {
+			t.Errorf("replaceOutsideTemplateBlocks(%q) = %q, want %q", tc.message, got, tc.want)
+		}
+	}
+}
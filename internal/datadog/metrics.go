@@ -0,0 +1,167 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// spaceAggregatorPattern matches the space aggregators a metric name follows
+// in a metrics query (avg:system.cpu.user{...}), distribution percentiles
+// included (p99:trace.http.request{...})
+var spaceAggregatorPattern = regexp.MustCompile(`^(?:avg|sum|min|max|count|p\d+(?:\.\d+)?)$`)
+
+// isMetricNameByte reports whether c can be part of a metric name
+func isMetricNameByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.'
+}
+
+// isLetter reports whether c is an ASCII letter, which metric names start with
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// skipDelimited returns the index after the delimited section of query that
+// starts at i: a quoted string ('...' or "...", with backslash escapes) or a
+// {...} scope or group-by list
+func skipDelimited(query string, i int) int {
+	open := query[i]
+	closing := open
+	if open == '{' {
+		closing = '}'
+	}
+	for j := i + 1; j < len(query); j++ {
+		switch {
+		case query[j] == '\\' && open != '{':
+			j++
+		case query[j] == closing:
+			return j + 1
+		}
+	}
+	return len(query)
+}
+
+// QueryMetricNames returns the metric names a metric monitor query reads, in
+// order and without duplicates: every aggregator:metric{scope} term, at any
+// depth of arithmetic (a / b * 100) and functions (anomalies(...),
+// top(...), .rollup(...)). Tag values of scopes and group-by lists and
+// quoted function arguments are not metric names.
+func QueryMetricNames(query string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '{':
+			i = skipDelimited(query, i)
+		case isMetricNameByte(c):
+			start := i
+			for i < len(query) && isMetricNameByte(query[i]) {
+				i++
+			}
+			if i >= len(query) || query[i] != ':' || !spaceAggregatorPattern.MatchString(query[start:i]) {
+				continue
+			}
+			nameStart := i + 1
+			end := nameStart
+			for end < len(query) && isMetricNameByte(query[end]) {
+				end++
+			}
+			next := end
+			for next < len(query) && query[next] == ' ' {
+				next++
+			}
+			name := query[nameStart:end]
+			if name != "" && isLetter(name[0]) && next < len(query) && query[next] == '{' && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+			i = end
+		default:
+			i++
+		}
+	}
+	return names
+}
+
+// activeMetricsResponse is the response of GET /metrics
+type activeMetricsResponse struct {
+	Metrics []string `json:"metrics"`
+}
+
+// ActiveMetrics returns the names of the metrics that reported a value since
+// the given time
+func (c *Client) ActiveMetrics(since time.Time) (map[string]bool, error) {
+	req, err := c.newRequest("GET", "/metrics", nil)
+	if err != nil {
+		return nil, err
+	}
+	q := req.URL.Query()
+	q.Set("from", strconv.FormatInt(since.Unix(), 10))
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to list active metrics: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var result activeMetricsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	active := make(map[string]bool, len(result.Metrics))
+	for _, name := range result.Metrics {
+		active[name] = true
+	}
+	return active, nil
+}
+
+// StaleMetricMonitor is a metric monitor whose query reads metrics that
+// haven't reported recently
+type StaleMetricMonitor struct {
+	Monitor Monitor
+	// Missing are the metrics of the query that are not active
+	Missing []string
+	// Metrics are every metric of the query
+	Metrics []string
+}
+
+// FindStaleMetricMonitors returns the metric monitors reading a metric that
+// is not in active, sorted by ID, and the metric monitors no metric name could
+// be read from. Other monitor types are left out.
+func FindStaleMetricMonitors(monitors []Monitor, active map[string]bool) ([]StaleMetricMonitor, []Monitor) {
+	var stale []StaleMetricMonitor
+	var unparsed []Monitor
+	for _, monitor := range monitors {
+		if !IsMetricMonitor(monitor.Type) {
+			continue
+		}
+		metrics := QueryMetricNames(monitor.Query)
+		if len(metrics) == 0 {
+			unparsed = append(unparsed, monitor)
+			continue
+		}
+		var missing []string
+		for _, metric := range metrics {
+			if !active[metric] {
+				missing = append(missing, metric)
+			}
+		}
+		if len(missing) > 0 {
+			stale = append(stale, StaleMetricMonitor{Monitor: monitor, Missing: missing, Metrics: metrics})
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i].Monitor.ID < stale[j].Monitor.ID })
+	return stale, unparsed
+}
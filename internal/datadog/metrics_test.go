@@ -0,0 +1,95 @@
+package datadog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQueryMetricNames(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		want  []string
+	}{
+		{"avg(last_5m):avg:system.cpu.user{env:prd} > 90", []string{"system.cpu.user"}},
+		{"avg(last_5m):avg:system.cpu.user{*} by {host} > 90", []string{"system.cpu.user"}},
+		// Arithmetic between metrics, at any depth
+		{"sum(last_5m):sum:http.errors{env:prd}.as_count() / sum:http.requests{env:prd}.as_count() * 100 > 5",
+			[]string{"http.errors", "http.requests"}},
+		{"avg(last_10m):(avg:disk.used{host:a} - avg:disk.free{host:a}) / (avg:disk.total{host:a} + 1) > 0.9",
+			[]string{"disk.used", "disk.free", "disk.total"}},
+		// Repeated metrics are listed once
+		{"avg(last_5m):avg:queue.depth{q:a} + avg:queue.depth{q:b} > 10", []string{"queue.depth"}},
+		// Functions, rollups and percentiles
+		{"avg(last_4h):anomalies(avg:http.requests{env:prd}.rollup(sum, 60), 'agile', 2) >= 1", []string{"http.requests"}},
+		{"avg(last_1h):top(avg:kafka.lag{*} by {topic}, 5, 'mean', 'desc') > 100", []string{"kafka.lag"}},
+		{"percentile(last_5m):p99:trace.http.request{service:api} > 2", []string{"trace.http.request"}},
+		{"avg(last_5m):p99.9:latency.ms{service:api} > 2", []string{"latency.ms"}},
+		{"max(last_5m):max: mem.rss {env:prd} > 1e9", nil},
+		{"max(last_5m):max:mem.rss {env:prd} > 1000", []string{"mem.rss"}},
+		// Scope tag values, group-by lists and quoted arguments are not metrics
+		{"avg(last_5m):avg:app.latency{service:avg:fake{x}} by {sum:other} > 1", []string{"app.latency"}},
+		{`avg(last_5m):forecast(avg:cpu.load{env:prd}, 'linear', 1, interval='60m', model='sum:x{y}') >= 90`, []string{"cpu.load"}},
+		{`avg(last_5m):avg:a.b{tag:"quoted \" brace {"} + avg:c.d{*} > 1`, []string{"a.b", "c.d"}},
+		// Not metric queries
+		{`logs("service:api status:error").index("*").rollup("count").last("5m") > 100`, nil},
+		{`"http.can_connect".over("env:prd").by("host").last(3).count_by_status()`, nil},
+		{"", nil},
+		// Metric names start with a letter; aggregators must be known
+		{"avg(last_5m):avg:1metric{*} + median:foo.bar{*} > 1", nil},
+	} {
+		got := QueryMetricNames(tc.query)
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("QueryMetricNames(%q) = %q, want %q", tc.query, got, tc.want)
+		}
+	}
+}
+
+func TestFindStaleMetricMonitors(t *testing.T) {
+	monitors := []Monitor{
+		{ID: 3, Type: "query alert", Query: "avg(last_5m):avg:old.metric{*} / avg:live.metric{*} > 1"},
+		{ID: 1, Type: "metric alert", Query: "avg(last_5m):avg:renamed.metric{*} > 1"},
+		{ID: 2, Type: "query alert", Query: "avg(last_5m):avg:live.metric{*} > 1"},
+		{ID: 4, Type: "query alert", Query: "change(avg(last_5m),last_5m):weird > 1"},
+		{ID: 5, Type: "log alert", Query: `logs("*").index("*").rollup("count").last("5m") > 1`},
+	}
+	stale, unparsed := FindStaleMetricMonitors(monitors, map[string]bool{"live.metric": true})
+	if len(stale) != 2 || stale[0].Monitor.ID != 1 || stale[1].Monitor.ID != 3 {
+		t.Fatalf("stale monitors %+v, want 1 and 3", stale)
+	}
+	if got := strings.Join(stale[1].Missing, ","); got != "old.metric" {
+		t.Errorf("missing metrics of monitor 3: %q", got)
+	}
+	if got := strings.Join(stale[1].Metrics, ","); got != "old.metric,live.metric" {
+		t.Errorf("metrics of monitor 3: %q", got)
+	}
+	if len(unparsed) != 1 || unparsed[0].ID != 4 {
+		t.Errorf("unparsed monitors %+v, want 4", unparsed)
+	}
+}
+
+func TestActiveMetrics(t *testing.T) {
+	var from string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		from = r.URL.Query().Get("from")
+		w.Write([]byte(`{"metrics": ["system.cpu.user", "http.requests"], "from": "1714564800"}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClientWithOptions(WithAPIKey("api-key"), WithAppKey("app-key"), WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	active, err := client.ActiveMetrics(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("ActiveMetrics: %v", err)
+	}
+	if from != "1714564800" {
+		t.Errorf("from=%q, want 1714564800", from)
+	}
+	if len(active) != 2 || !active["system.cpu.user"] || !active["http.requests"] {
+		t.Errorf("active metrics %v", active)
+	}
+}
@@ -0,0 +1,75 @@
+package datadog
+
+import (
+	"sort"
+	"time"
+)
+
+// WholeMonitorScope is the silenced scope of a mute covering every group
+const WholeMonitorScope = "*"
+
+// SilencedScope is one muted scope of a monitor
+type SilencedScope struct {
+	Scope string
+	// End is when the mute expires; zero means it never expires
+	End time.Time
+}
+
+// SilencedScopes returns the muted scopes of a monitor from options.silenced,
+// sorted with the whole-monitor scope first
+func SilencedScopes(monitor Monitor) []SilencedScope {
+	silenced, ok := monitor.Options["silenced"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var scopes []SilencedScope
+	for scope, end := range silenced {
+		s := SilencedScope{Scope: scope}
+		if ts, ok := end.(float64); ok && ts > 0 {
+			s.End = time.Unix(int64(ts), 0)
+		}
+		scopes = append(scopes, s)
+	}
+	sort.Slice(scopes, func(i, j int) bool {
+		if (scopes[i].Scope == WholeMonitorScope) != (scopes[j].Scope == WholeMonitorScope) {
+			return scopes[i].Scope == WholeMonitorScope
+		}
+		return scopes[i].Scope < scopes[j].Scope
+	})
+	return scopes
+}
+
+// IsScopeSilenced reports whether scope (empty for the whole monitor) is muted
+func IsScopeSilenced(monitor Monitor, scope string) bool {
+	if scope == "" {
+		scope = WholeMonitorScope
+	}
+	for _, s := range SilencedScopes(monitor) {
+		if s.Scope == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// SilencedUntil returns the options.silenced value muting a whole monitor
+// until end, or indefinitely (a null end) when end is zero
+func SilencedUntil(end time.Time) map[string]interface{} {
+	if end.IsZero() {
+		return map[string]interface{}{WholeMonitorScope: nil}
+	}
+	return map[string]interface{}{WholeMonitorScope: end.Unix()}
+}
+
+// MuteOnCreate sets options.silenced on a monitor about to be created so it
+// starts muted until end (indefinitely when end is zero). The options map is
+// copied, leaving the caller's monitor alone.
+func MuteOnCreate(monitor *Monitor, end time.Time) {
+	options := make(map[string]interface{}, len(monitor.Options)+1)
+	for key, value := range monitor.Options {
+		options[key] = value
+	}
+	options["silenced"] = SilencedUntil(end)
+	monitor.Options = options
+}
@@ -0,0 +1,100 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSilencedUntil(t *testing.T) {
+	end := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		end  time.Time
+		want string
+	}{
+		{end, `{"*":1714651200}`},
+		// An indefinite mute has a null end
+		{time.Time{}, `{"*":null}`},
+	} {
+		data, err := json.Marshal(SilencedUntil(tc.end))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != tc.want {
+			t.Errorf("SilencedUntil(%v) = %s, want %s", tc.end, data, tc.want)
+		}
+	}
+}
+
+func TestMuteOnCreate(t *testing.T) {
+	end := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+	options := map[string]interface{}{"notify_no_data": true, "silenced": map[string]interface{}{"env:stg": nil}}
+	monitor := Monitor{Name: "CPU", Options: options}
+	muted := monitor
+	MuteOnCreate(&muted, end)
+
+	// The whole monitor is muted, replacing scoped mutes of the template
+	if got := fmt.Sprint(muted.Options["silenced"]); got != "map[*:1714651200]" {
+		t.Errorf("silenced = %s", got)
+	}
+	if muted.Options["notify_no_data"] != true {
+		t.Errorf("other options lost: %v", muted.Options)
+	}
+	// The caller's options are left alone
+	if got := fmt.Sprint(options["silenced"]); got != "map[env:stg:<nil>]" || len(options) != 2 {
+		t.Errorf("caller's options changed: %v", options)
+	}
+
+	// A monitor without options gets some
+	bare := Monitor{Name: "CPU"}
+	MuteOnCreate(&bare, time.Time{})
+	if got := fmt.Sprint(bare.Options); got != "map[silenced:map[*:<nil>]]" {
+		t.Errorf("options = %s", got)
+	}
+}
+
+func TestSilencedScopes(t *testing.T) {
+	// As decoded from the API: ends are float64 timestamps or null
+	var monitor Monitor
+	if err := json.Unmarshal([]byte(`{"options": {"silenced": {"role:db": 1714651200, "*": null, "env:stg": 0}}}`), &monitor); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, scope := range SilencedScopes(monitor) {
+		end := "never"
+		if !scope.End.IsZero() {
+			end = scope.End.UTC().Format(time.RFC3339)
+		}
+		got = append(got, scope.Scope+" "+end)
+	}
+	// The whole-monitor scope first, then by scope
+	if want := "* never, env:stg never, role:db 2024-05-02T12:00:00Z"; strings.Join(got, ", ") != want {
+		t.Errorf("SilencedScopes = %s, want %s", strings.Join(got, ", "), want)
+	}
+
+	for _, tc := range []struct {
+		scope string
+		want  bool
+	}{
+		{"", true},
+		{"*", true},
+		{"role:db", true},
+		{"role:web", false},
+	} {
+		if got := IsScopeSilenced(monitor, tc.scope); got != tc.want {
+			t.Errorf("IsScopeSilenced(%q) = %v, want %v", tc.scope, got, tc.want)
+		}
+	}
+
+	// Round trip through the map MuteOnCreate builds
+	muted := Monitor{}
+	MuteOnCreate(&muted, time.Time{})
+	data, _ := json.Marshal(muted)
+	var decoded Monitor
+	json.Unmarshal(data, &decoded)
+	if !IsScopeSilenced(decoded, "") || IsScopeSilenced(Monitor{}, "") {
+		t.Errorf("IsScopeSilenced after MuteOnCreate: %s", data)
+	}
+}
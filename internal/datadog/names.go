@@ -0,0 +1,121 @@
+package datadog
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxMonitorNameLength is the longest monitor name, in characters, the API
+// accepts
+const MaxMonitorNameLength = 200
+
+// NameOverflowPolicy says what rendering does with a monitor name longer than
+// MaxMonitorNameLength
+type NameOverflowPolicy string
+
+const (
+	// NameOverflowError fails the template with a *NameTooLongError (default)
+	NameOverflowError NameOverflowPolicy = "error"
+	// NameOverflowTruncateHash cuts the name and appends an ellipsis and a
+	// hash of the full name, so names stay unique and stable across runs
+	NameOverflowTruncateHash NameOverflowPolicy = "truncate-hash"
+	// NameOverflowAbbreviate shortens the service in the name to the first
+	// letters of its segments (payments-reconciliation-worker → pay-rec-wor)
+	NameOverflowAbbreviate NameOverflowPolicy = "abbreviate"
+)
+
+// ParseNameOverflowPolicy parses a --name-overflow value; "" is NameOverflowError
+func ParseNameOverflowPolicy(value string) (NameOverflowPolicy, error) {
+	switch policy := NameOverflowPolicy(value); policy {
+	case "":
+		return NameOverflowError, nil
+	case NameOverflowError, NameOverflowTruncateHash, NameOverflowAbbreviate:
+		return policy, nil
+	}
+	return "", fmt.Errorf("invalid name overflow strategy %q (must be %s, %s or %s)", value, NameOverflowError, NameOverflowTruncateHash, NameOverflowAbbreviate)
+}
+
+// NameTooLongError is returned when a rendered monitor name is over
+// MaxMonitorNameLength and the overflow policy can't shorten it
+type NameTooLongError struct {
+	Name   string
+	Length int
+	Policy NameOverflowPolicy
+}
+
+// Error implements the error interface
+func (e *NameTooLongError) Error() string {
+	if e.Policy == NameOverflowAbbreviate {
+		return fmt.Sprintf("monitor name is %d characters, over the limit of %d even with the service abbreviated (use --name-overflow %s): %s",
+			e.Length, MaxMonitorNameLength, NameOverflowTruncateHash, e.Name)
+	}
+	return fmt.Sprintf("monitor name is %d characters, over the limit of %d (use --name-overflow %s or %s): %s",
+		e.Length, MaxMonitorNameLength, NameOverflowTruncateHash, NameOverflowAbbreviate, e.Name)
+}
+
+// IsNameTooLong reports whether err (or an error it wraps) is a NameTooLongError
+func IsNameTooLong(err error) bool {
+	var tooLong *NameTooLongError
+	return errors.As(err, &tooLong)
+}
+
+// FitMonitorName returns a rendered monitor name within MaxMonitorNameLength,
+// shortened with policy when it is longer. The note describes the shortening
+// for verbose output and is empty when the name already fits.
+func FitMonitorName(name, service string, policy NameOverflowPolicy) (fitted, note string, err error) {
+	length := utf8.RuneCountInString(name)
+	if length <= MaxMonitorNameLength {
+		return name, "", nil
+	}
+
+	switch policy {
+	case NameOverflowTruncateHash:
+		fitted = truncateWithHash(name, MaxMonitorNameLength)
+	case NameOverflowAbbreviate:
+		abbreviated := AbbreviateService(service)
+		if service == "" || abbreviated == service || !strings.Contains(name, service) {
+			return "", "", &NameTooLongError{Name: name, Length: length, Policy: policy}
+		}
+		fitted = strings.ReplaceAll(name, service, abbreviated)
+		if utf8.RuneCountInString(fitted) > MaxMonitorNameLength {
+			return "", "", &NameTooLongError{Name: name, Length: length, Policy: policy}
+		}
+	default:
+		return "", "", &NameTooLongError{Name: name, Length: length, Policy: NameOverflowError}
+	}
+	note = fmt.Sprintf("name shortened from %d to %d characters (%s)", length, utf8.RuneCountInString(fitted), policy)
+	return fitted, note, nil
+}
+
+// truncateWithHash cuts name to limit characters, ending it with "… #" and a
+// hash of the whole name. The hash keeps two names sharing a long prefix
+// apart, and the same name always gives the same result, so upserts keep
+// matching it.
+func truncateWithHash(name string, limit int) string {
+	suffix := "… #" + shortHash(name)
+	keep := limit - utf8.RuneCountInString(suffix)
+	runes := []rune(name)
+	return strings.TrimRight(string(runes[:keep]), " -_") + suffix
+}
+
+// AbbreviateService shortens the segments of a service name (separated by
+// '-', '_' or '.') to their first three characters, keeping the separators,
+// e.g. "payments-reconciliation-worker" gives "pay-rec-wor"
+func AbbreviateService(service string) string {
+	var b strings.Builder
+	segment := 0
+	for _, r := range service {
+		if r == '-' || r == '_' || r == '.' {
+			segment = 0
+			b.WriteRune(r)
+			continue
+		}
+		if segment < 3 {
+			b.WriteRune(r)
+		}
+		segment++
+	}
+	return b.String()
+}
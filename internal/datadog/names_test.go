@@ -0,0 +1,94 @@
+package datadog
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestFitMonitorNameTruncateHash(t *testing.T) {
+	name := "[prd] payments-reconciliation-worker " + strings.Repeat("x", 190)
+	fitted, note, err := FitMonitorName(name, "payments-reconciliation-worker", NameOverflowTruncateHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The hash is the first 8 hex digits of the SHA-256 of the full name:
+	// pinned, so a change to it (which would rename every shortened monitor
+	// on the next apply) fails here
+	if !strings.HasSuffix(fitted, "… #b73dda2b") {
+		t.Errorf("fitted name %q lacks the pinned hash", fitted)
+	}
+	if n := utf8.RuneCountInString(fitted); n != MaxMonitorNameLength {
+		t.Errorf("fitted name has %d characters, want %d", n, MaxMonitorNameLength)
+	}
+	if note != "name shortened from 227 to 200 characters (truncate-hash)" {
+		t.Errorf("note %q", note)
+	}
+
+	// The same name gives the same result every time
+	for i := 0; i < 3; i++ {
+		if again, _, _ := FitMonitorName(name, "", NameOverflowTruncateHash); again != fitted {
+			t.Fatalf("run %d fitted %q, want %q", i, again, fitted)
+		}
+	}
+	// Names sharing the kept prefix stay apart
+	other, _, _ := FitMonitorName(name+"y", "", NameOverflowTruncateHash)
+	if other == fitted {
+		t.Errorf("names differing after the cut fitted to the same %q", other)
+	}
+	if prefix, otherPrefix := strings.Split(fitted, "…")[0], strings.Split(other, "…")[0]; prefix != otherPrefix {
+		t.Errorf("kept prefixes differ: %q, %q", prefix, otherPrefix)
+	}
+
+	// Multi-byte characters are counted as one and never cut in half
+	wide := strings.Repeat("é", 250)
+	fitted, _, err = FitMonitorName(wide, "", NameOverflowTruncateHash)
+	if err != nil || utf8.RuneCountInString(fitted) != MaxMonitorNameLength || !utf8.ValidString(fitted) {
+		t.Errorf("FitMonitorName of 250 é = %q, %v", fitted, err)
+	}
+}
+
+func TestFitMonitorName(t *testing.T) {
+	short := "[prd] checkout CPU"
+	for _, policy := range []NameOverflowPolicy{NameOverflowError, NameOverflowTruncateHash, NameOverflowAbbreviate} {
+		if fitted, note, err := FitMonitorName(short, "checkout", policy); fitted != short || note != "" || err != nil {
+			t.Errorf("%s: FitMonitorName of a short name = %q, %q, %v", policy, fitted, note, err)
+		}
+	}
+	exact := strings.Repeat("a", MaxMonitorNameLength)
+	if fitted, _, err := FitMonitorName(exact, "", NameOverflowError); fitted != exact || err != nil {
+		t.Errorf("name of exactly %d characters: %v", MaxMonitorNameLength, err)
+	}
+
+	long := "[prd] payments-reconciliation-worker " + strings.Repeat("x", 170)
+	if _, _, err := FitMonitorName(long, "payments-reconciliation-worker", NameOverflowError); !IsNameTooLong(err) {
+		t.Errorf("error policy = %v, want a NameTooLongError", err)
+	}
+	fitted, _, err := FitMonitorName(long, "payments-reconciliation-worker", NameOverflowAbbreviate)
+	if err != nil || fitted != "[prd] pay-rec-wor "+strings.Repeat("x", 170) {
+		t.Errorf("abbreviate policy = %q, %v", fitted, err)
+	}
+	// Abbreviating can't help without the service in the name, or when still too long
+	for _, service := range []string{"", "billing", "api"} {
+		if _, _, err := FitMonitorName(long, service, NameOverflowAbbreviate); !IsNameTooLong(err) {
+			t.Errorf("abbreviate with service %q = %v, want a NameTooLongError", service, err)
+		}
+	}
+	if _, _, err := FitMonitorName("[prd] payments-reconciliation-worker "+strings.Repeat("x", 190), "payments-reconciliation-worker", NameOverflowAbbreviate); !IsNameTooLong(err) {
+		t.Errorf("abbreviate of a name still too long = %v", err)
+	}
+}
+
+func TestAbbreviateService(t *testing.T) {
+	for service, want := range map[string]string{
+		"payments-reconciliation-worker": "pay-rec-wor",
+		"api":                            "api",
+		"auth_token.service":             "aut_tok.ser",
+		"a--b":                           "a--b",
+		"":                               "",
+	} {
+		if got := AbbreviateService(service); got != want {
+			t.Errorf("AbbreviateService(%q) = %q, want %q", service, got, want)
+		}
+	}
+}
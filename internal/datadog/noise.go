@@ -0,0 +1,118 @@
+package datadog
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// NoiseSummary is how often one monitor alerted over a period
+type NoiseSummary struct {
+	MonitorID int    `json:"monitor_id"`
+	Name      string `json:"name"`
+	// Triggers counts transitions into alert (error or warning events
+	// following a recovery or the start of the period), per group
+	Triggers int `json:"triggers"`
+	// Cycles counts triggers followed by a recovery
+	Cycles int `json:"cycles"`
+	// TimeInAlertSeconds is the total time spent in alert; alerts still open
+	// count until the end of the period
+	TimeInAlertSeconds float64 `json:"time_in_alert_seconds"`
+	// MeanTimeInAlertSeconds is TimeInAlertSeconds per trigger
+	MeanTimeInAlertSeconds float64 `json:"mean_time_in_alert_seconds"`
+	// Alerting is set when a group was still in alert at the end of the period
+	Alerting bool `json:"alerting"`
+}
+
+// sortEvents sorts events oldest first, by ID within a second
+func sortEvents(events []Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].DateHappened != events[j].DateHappened {
+			return events[i].DateHappened < events[j].DateHappened
+		}
+		return events[i].ID < events[j].ID
+	})
+}
+
+// isAlertEvent reports whether a monitor event is a trigger (error or
+// warning); success events are recoveries and the rest is ignored
+func isAlertEvent(event Event) bool {
+	return event.AlertType == "error" || event.AlertType == "warning"
+}
+
+// BuildNoiseReport counts the triggers, recovery cycles and time in alert of
+// monitors from their alert events up to end, ranked noisiest first: by
+// triggers, then cycles, then time in alert. Each group of a multi-alert
+// monitor alerts on its own. Events of other monitors and events after end
+// are ignored; monitors that didn't trigger in the period are left out.
+func BuildNoiseReport(monitors []Monitor, events []Event, end time.Time) []NoiseSummary {
+	names := make(map[int]string, len(monitors))
+	for _, monitor := range monitors {
+		names[monitor.ID] = monitor.Name
+	}
+
+	sorted := append([]Event(nil), events...)
+	sortEvents(sorted)
+
+	type groupKey struct {
+		monitorID int
+		group     string
+	}
+	alertSince := make(map[groupKey]time.Time)
+	summaries := make(map[int]*NoiseSummary)
+	for _, event := range sorted {
+		name, ok := names[event.MonitorID]
+		if !ok || event.Time().After(end) {
+			continue
+		}
+		summary := summaries[event.MonitorID]
+		if summary == nil {
+			summary = &NoiseSummary{MonitorID: event.MonitorID, Name: name}
+			summaries[event.MonitorID] = summary
+		}
+
+		groups := append([]string(nil), event.MonitorGroups...)
+		sort.Strings(groups)
+		key := groupKey{event.MonitorID, strings.Join(groups, ",")}
+		since, alerting := alertSince[key]
+		switch {
+		case isAlertEvent(event) && !alerting:
+			summary.Triggers++
+			alertSince[key] = event.Time()
+		case event.AlertType == "success" && alerting:
+			summary.Cycles++
+			summary.TimeInAlertSeconds += event.Time().Sub(since).Seconds()
+			delete(alertSince, key)
+		}
+	}
+	for key, since := range alertSince {
+		summary := summaries[key.monitorID]
+		summary.Alerting = true
+		if end.After(since) {
+			summary.TimeInAlertSeconds += end.Sub(since).Seconds()
+		}
+	}
+
+	report := make([]NoiseSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		if summary.Triggers == 0 {
+			continue
+		}
+		summary.MeanTimeInAlertSeconds = summary.TimeInAlertSeconds / float64(summary.Triggers)
+		report = append(report, *summary)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		a, b := report[i], report[j]
+		if a.Triggers != b.Triggers {
+			return a.Triggers > b.Triggers
+		}
+		if a.Cycles != b.Cycles {
+			return a.Cycles > b.Cycles
+		}
+		if a.TimeInAlertSeconds != b.TimeInAlertSeconds {
+			return a.TimeInAlertSeconds > b.TimeInAlertSeconds
+		}
+		return a.MonitorID < b.MonitorID
+	})
+	return report
+}
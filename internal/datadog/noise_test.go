@@ -0,0 +1,195 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// noiseStart is the start of the day testdata/noise-events.json covers
+var noiseStart = time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+func loadNoiseEvents(t *testing.T) []Event {
+	t.Helper()
+	data, err := os.ReadFile("testdata/noise-events.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []Event
+	if err := json.Unmarshal(data, &events); err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+func TestBuildNoiseReport(t *testing.T) {
+	var monitors []Monitor
+	for id, name := range map[int]string{1: "flappy", 2: "stuck", 3: "multi", 4: "tie", 5: "quiet", 6: "once", 7: "slow"} {
+		monitors = append(monitors, Monitor{ID: id, Name: name})
+	}
+	end := noiseStart.Add(24 * time.Hour)
+	report := BuildNoiseReport(monitors, loadNoiseEvents(t), end)
+
+	var got []string
+	for _, s := range report {
+		got = append(got, fmt.Sprintf("%d %s: %d triggers, %d cycles, %gs in alert, %gs mean, alerting %v",
+			s.MonitorID, s.Name, s.Triggers, s.Cycles, s.TimeInAlertSeconds, s.MeanTimeInAlertSeconds, s.Alerting))
+	}
+	want := []string{
+		// The trigger after the end of the period is ignored
+		"1 flappy: 3 triggers, 3 cycles, 1800s in alert, 600s mean, alerting false",
+		// Each group alerts on its own; an error following a warning of the
+		// same group is not another trigger. Ties in triggers, cycles and time
+		// in alert are broken by ID.
+		"3 multi: 2 triggers, 2 cycles, 1800s in alert, 900s mean, alerting false",
+		// Groups are the same in any order
+		"4 tie: 2 triggers, 2 cycles, 1800s in alert, 900s mean, alerting false",
+		// Same triggers: more cycles first, then more time in alert
+		"7 slow: 1 triggers, 1 cycles, 3600s in alert, 3600s mean, alerting false",
+		"6 once: 1 triggers, 1 cycles, 60s in alert, 60s mean, alerting false",
+		// The recovery after the end of the period is ignored: still alerting,
+		// counted until the end
+		"2 stuck: 1 triggers, 0 cycles, 82800s in alert, 82800s mean, alerting true",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("report:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestBuildNoiseReportCutoff(t *testing.T) {
+	monitors := []Monitor{{ID: 1, Name: "CPU"}}
+	at := func(id int64, alertType string, offset time.Duration) Event {
+		return Event{ID: id, AlertType: alertType, DateHappened: noiseStart.Add(offset).Unix(), MonitorID: 1}
+	}
+
+	// An alert open at the end counts until the end
+	report := BuildNoiseReport(monitors, []Event{at(1, "error", 23*time.Hour)}, noiseStart.Add(24*time.Hour))
+	if len(report) != 1 || report[0].TimeInAlertSeconds != 3600 || !report[0].Alerting {
+		t.Errorf("open alert: %+v", report)
+	}
+	// Events after the end are left out entirely
+	report = BuildNoiseReport(monitors, []Event{at(1, "error", 25*time.Hour)}, noiseStart.Add(24*time.Hour))
+	if len(report) != 0 {
+		t.Errorf("alert after the end: %+v", report)
+	}
+	// An event at the end is in the period
+	report = BuildNoiseReport(monitors, []Event{at(1, "error", 24*time.Hour)}, noiseStart.Add(24*time.Hour))
+	if len(report) != 1 || report[0].Triggers != 1 || report[0].TimeInAlertSeconds != 0 {
+		t.Errorf("alert at the end: %+v", report)
+	}
+	if report := BuildNoiseReport(monitors, nil, noiseStart); len(report) != 0 {
+		t.Errorf("report without events: %+v", report)
+	}
+}
+
+func TestListEventsPaging(t *testing.T) {
+	type request struct {
+		start, end int64
+		page       int
+	}
+	var (
+		mu          sync.Mutex
+		requests    []request
+		rateLimited bool
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		q := r.URL.Query()
+		if q.Get("sources") != "alert" || q.Get("tags") != "service:api,env:prd" || q.Get("unaggregated") != "true" {
+			t.Errorf("events requested with %s", r.URL.RawQuery)
+		}
+		start, _ := strconv.ParseInt(q.Get("start"), 10, 64)
+		end, _ := strconv.ParseInt(q.Get("end"), 10, 64)
+		page, _ := strconv.Atoi(q.Get("page"))
+
+		// The second day's second page is rate limited once
+		if start == noiseStart.Add(24*time.Hour).Unix() && page == 1 && !rateLimited {
+			rateLimited = true
+			w.Header().Set("X-RateLimit-Reset", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		requests = append(requests, request{start, end, page})
+
+		// The first day has a full page and a partial one, the second day
+		// two full pages then an empty one, the last half day a few events;
+		// event 1 is on the boundary of the first two days
+		var count int
+		switch {
+		case start == noiseStart.Unix():
+			count = map[int]int{0: eventsPageSize, 1: 10}[page]
+		case start == noiseStart.Add(24*time.Hour).Unix():
+			count = map[int]int{0: eventsPageSize, 1: eventsPageSize}[page]
+		default:
+			count = 3
+		}
+		events := make([]Event, 0, count)
+		for i := 0; i < count; i++ {
+			events = append(events, Event{ID: start*10 + int64(page*eventsPageSize+i), AlertType: "error", DateHappened: end - int64(i), MonitorID: 1})
+		}
+		if page == 0 && start < noiseStart.Add(48*time.Hour).Unix() {
+			events[0].ID = 1
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"events": events})
+	}))
+	defer srv.Close()
+	client, err := NewClientWithOptions(WithAPIKey("api-key"), WithAppKey("app-key"), WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from, to := noiseStart, noiseStart.Add(60*time.Hour)
+	events, err := client.ListEvents(EventQuery{Sources: []string{"alert"}, Tags: []string{"service:api", "env:prd"}}, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := int64(24 * 3600)
+	want := []request{
+		{from.Unix(), from.Unix() + day, 0},
+		{from.Unix(), from.Unix() + day, 1},
+		{from.Unix() + day, from.Unix() + 2*day, 0},
+		{from.Unix() + day, from.Unix() + 2*day, 1},
+		{from.Unix() + day, from.Unix() + 2*day, 2},
+		// The last window ends at to
+		{from.Unix() + 2*day, to.Unix(), 0},
+	}
+	if fmt.Sprint(requests) != fmt.Sprint(want) {
+		t.Errorf("requests %v, want %v", requests, want)
+	}
+	if !rateLimited {
+		t.Error("the rate-limited request was not made")
+	}
+	// Event 1 came back on two days and is listed once
+	if wantCount := eventsPageSize + 10 + 2*eventsPageSize - 1 + 3; len(events) != wantCount {
+		t.Errorf("%d events, want %d", len(events), wantCount)
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i].DateHappened < events[i-1].DateHappened {
+			t.Fatalf("events not sorted oldest first at %d", i)
+		}
+	}
+}
+
+func TestListEventsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"errors": ["Forbidden"]}`))
+	}))
+	defer srv.Close()
+	client, err := NewClientWithOptions(WithAPIKey("api-key"), WithAppKey("app-key"), WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ListEvents(EventQuery{}, noiseStart, noiseStart.Add(time.Hour)); err == nil || !strings.Contains(err.Error(), "status 403") {
+		t.Errorf("ListEvents = %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package datadog
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// ServerDefault is an option value Datadog fills in on monitors whose
+// definition leaves the option out, e.g. include_tags: true
+type ServerDefault struct {
+	// Option is the option key, with nested keys joined by dots as in
+	// MonitorDiff (e.g. thresholds.ok)
+	Option string
+	// Value is the default as the API returns it (JSON numbers are float64)
+	Value interface{}
+	// Types are the monitor types Datadog sets it on; none means every type
+	Types []string
+}
+
+// appliesTo reports whether the default is set on monitors of this type
+func (d ServerDefault) appliesTo(monitorType string) bool {
+	if len(d.Types) == 0 {
+		return true
+	}
+	for _, t := range d.Types {
+		if t == monitorType {
+			return true
+		}
+	}
+	return false
+}
+
+// ServerDefaults are the option values Datadog adds to monitors that don't
+// set them. A live monitor option matching one is not drift when the
+// template leaves the option out; append to it when Datadog starts filling
+// in another option.
+var ServerDefaults = []ServerDefault{
+	{Option: "include_tags", Value: true},
+	{Option: "notify_audit", Value: false},
+	{Option: "notify_no_data", Value: false},
+	{Option: "new_host_delay", Value: float64(300)},
+	{Option: "locked", Value: false},
+	{Option: "escalation_message", Value: ""},
+	{Option: "renotify_interval", Value: float64(0)},
+	{Option: "timeout_h", Value: float64(0)},
+	{Option: "notification_preset_name", Value: "show_all"},
+	{Option: "on_missing_data", Value: "default"},
+	{Option: "groupby_simple_monitor", Value: false},
+	{Option: "enable_logs_sample", Value: false, Types: []string{"log alert"}},
+	{Option: "thresholds.ok", Value: float64(1), Types: []string{"service check"}},
+	{Option: "thresholds.warning", Value: float64(1), Types: []string{"service check"}},
+}
+
+// serverDefault returns the server default of an option for a monitor type
+func serverDefault(monitorType, option string) (ServerDefault, bool) {
+	for _, d := range ServerDefaults {
+		if d.Option == option && d.appliesTo(monitorType) {
+			return d, true
+		}
+	}
+	return ServerDefault{}, false
+}
+
+// NormalizeForComparison returns desired (e.g. a rendered template) and live
+// (the monitor in Datadog) in a form where only real differences remain:
+//   - live options equal to a ServerDefaults value the desired monitor
+//     doesn't set (and desired ones set to the default live doesn't
+//     report), and null or empty options on either side, are removed
+//   - numbers are compared as the API returns them (float64)
+//   - tags are sorted
+//   - a live query equal to the desired one but for whitespace takes the
+//     desired spelling
+func NormalizeForComparison(desired, live Monitor) (Monitor, Monitor) {
+	desiredOptions := flattenOptions(canonicalOptions(desired.Options), "", map[string]interface{}{})
+	liveOptions := flattenOptions(canonicalOptions(live.Options), "", map[string]interface{}{})
+	for key, value := range desiredOptions {
+		if isEmptyOption(value) {
+			delete(desiredOptions, key)
+		}
+	}
+	for key, value := range liveOptions {
+		if isEmptyOption(value) {
+			delete(liveOptions, key)
+			continue
+		}
+		if _, set := desiredOptions[key]; set {
+			continue
+		}
+		if d, ok := serverDefault(live.Type, key); ok && reflect.DeepEqual(value, d.Value) {
+			delete(liveOptions, key)
+		}
+	}
+	for key, value := range desiredOptions {
+		if _, set := liveOptions[key]; set {
+			continue
+		}
+		if d, ok := serverDefault(desired.Type, key); ok && reflect.DeepEqual(value, d.Value) {
+			// Setting an option to its default, which Datadog doesn't report
+			delete(desiredOptions, key)
+		}
+	}
+	desired.Options = desiredOptions
+	live.Options = liveOptions
+
+	desired.Tags = sortedTags(desired.Tags)
+	live.Tags = sortedTags(live.Tags)
+	if live.Query != desired.Query && NormalizeQuery(live.Query) == NormalizeQuery(desired.Query) {
+		live.Query = desired.Query
+	}
+	return desired, live
+}
+
+// DiffDrift returns how the live monitor differs from the desired one, after
+// NormalizeForComparison; changes go from live to desired. An empty diff
+// means updating live with desired would change nothing.
+func DiffDrift(desired, live Monitor) MonitorDiff {
+	desired, live = NormalizeForComparison(desired, live)
+	return DiffMonitors(live, desired)
+}
+
+// canonicalOptions returns a copy of options as decoded from JSON, so ints
+// set by the tool compare equal to the float64s of the API
+func canonicalOptions(options map[string]interface{}) map[string]interface{} {
+	data, err := json.Marshal(options)
+	if err != nil {
+		return options
+	}
+	var canonical map[string]interface{}
+	if err := json.Unmarshal(data, &canonical); err != nil {
+		return options
+	}
+	return canonical
+}
+
+// isEmptyOption reports whether a flattened option value is null or an empty
+// object, which Datadog treats as unset
+func isEmptyOption(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	nested, ok := value.(map[string]interface{})
+	return ok && len(nested) == 0
+}
+
+// sortedTags returns a sorted copy of tags
+func sortedTags(tags []string) []string {
+	sorted := append([]string(nil), tags...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+// priorityValue returns a monitor priority for a FieldChange: nil when unset
+func priorityValue(priority *int) interface{} {
+	if priority == nil {
+		return nil
+	}
+	return *priority
+}
+
+// priorityEqual reports whether two monitor priorities are the same
+func priorityEqual(a, b *int) bool {
+	return (a == nil) == (b == nil) && (a == nil || *a == *b)
+}
@@ -0,0 +1,111 @@
+package datadog
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// driftPair is a fixture of testdata/drift-pairs.json: a desired and a live
+// monitor, and the changes DiffDrift must find between them (none for
+// semantically equal pairs)
+type driftPair struct {
+	Name    string   `json:"name"`
+	Desired Monitor  `json:"desired"`
+	Live    Monitor  `json:"live"`
+	Drift   []string `json:"drift"`
+}
+
+func TestDiffDriftFixtures(t *testing.T) {
+	data, err := os.ReadFile("testdata/drift-pairs.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pairs []driftPair
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&pairs); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pair := range pairs {
+		// The desired side is built by the tool, with Go ints
+		desired := pair.Desired
+		desired.Options = intOptions(desired.Options)
+
+		diff := DiffDrift(desired, pair.Live)
+		var got []string
+		for _, change := range diff.Fields {
+			got = append(got, change.Field)
+		}
+		if len(diff.TagsAdded) > 0 || len(diff.TagsRemoved) > 0 {
+			got = append(got, "tags")
+		}
+		for _, change := range diff.Options {
+			got = append(got, "options."+change.Field)
+		}
+		sort.Strings(got)
+		if strings.Join(got, ",") != strings.Join(pair.Drift, ",") {
+			t.Errorf("%s: drift %q, want %q", pair.Name, got, pair.Drift)
+		}
+		if diff.Empty() != (len(pair.Drift) == 0) {
+			t.Errorf("%s: Empty() = %v", pair.Name, diff.Empty())
+		}
+	}
+}
+
+// intOptions returns options with whole float64s turned into ints, as a
+// template rendered by the tool may hold them
+func intOptions(options map[string]interface{}) map[string]interface{} {
+	if options == nil {
+		return nil
+	}
+	converted := make(map[string]interface{}, len(options))
+	for key, value := range options {
+		switch v := value.(type) {
+		case float64:
+			if v == float64(int(v)) {
+				converted[key] = int(v)
+				continue
+			}
+		case map[string]interface{}:
+			converted[key] = intOptions(v)
+			continue
+		}
+		converted[key] = value
+	}
+	return converted
+}
+
+func TestNormalizeForComparisonKeepsInputs(t *testing.T) {
+	desired := Monitor{Type: "query alert", Tags: []string{"b", "a"}, Options: map[string]interface{}{"include_tags": true}}
+	live := Monitor{Type: "query alert", Tags: []string{"d", "c"}, Options: map[string]interface{}{"include_tags": true, "silenced": map[string]interface{}{}}}
+	NormalizeForComparison(desired, live)
+	if desired.Tags[0] != "b" || live.Tags[0] != "d" || len(live.Options) != 2 || len(desired.Options) != 1 {
+		t.Errorf("inputs modified: %+v, %+v", desired, live)
+	}
+}
+
+func TestServerDefaultTypes(t *testing.T) {
+	if _, ok := serverDefault("query alert", "thresholds.ok"); ok {
+		t.Error("thresholds.ok is a server default of metric monitors")
+	}
+	if d, ok := serverDefault("service check", "thresholds.ok"); !ok || d.Value != float64(1) {
+		t.Errorf("thresholds.ok of service checks: %+v, %v", d, ok)
+	}
+	if _, ok := serverDefault("composite", "include_tags"); !ok {
+		t.Error("include_tags is not a server default of every type")
+	}
+
+	// The table is data: an appended default is honored
+	saved := ServerDefaults
+	t.Cleanup(func() { ServerDefaults = saved })
+	ServerDefaults = append(append([]ServerDefault(nil), saved...), ServerDefault{Option: "notify_by", Value: []interface{}{"*"}})
+	live := Monitor{Type: "query alert", Options: map[string]interface{}{"notify_by": []interface{}{"*"}}}
+	if diff := DiffDrift(Monitor{Type: "query alert"}, live); !diff.Empty() {
+		t.Errorf("appended default reported as drift: %+v", diff)
+	}
+}
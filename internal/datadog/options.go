@@ -0,0 +1,195 @@
+package datadog
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/audit"
+	"github.com/tbernacchi/datadog-monitor-manager/internal/version"
+)
+
+// DefaultBaseURL is the Datadog API v1 base URL used when none is configured
+const DefaultBaseURL = "https://api.datadoghq.com/api/v1"
+
+// Option configures a Client created with NewClientWithOptions
+type Option func(*clientOptions)
+
+// clientOptions collects the values set by Options before the Client is built
+type clientOptions struct {
+	apiKey      string
+	appKey      string
+	credentials CredentialsProvider
+	baseURL     string
+	v2BaseURL   string
+	httpClient  *http.Client
+	userAgent   string
+	cacheDir    string
+	ctx         context.Context
+	stats       *Stats
+	audit       *audit.Log
+	inventory   string
+	orgCheck    *OrgCheck
+}
+
+// WithAPIKey sets the Datadog API key
+func WithAPIKey(apiKey string) Option {
+	return func(o *clientOptions) {
+		o.apiKey = apiKey
+	}
+}
+
+// WithAppKey sets the Datadog application key
+func WithAppKey(appKey string) Option {
+	return func(o *clientOptions) {
+		o.appKey = appKey
+	}
+}
+
+// WithCredentials authenticates every request with the headers of provider,
+// consulted per request; the API and application keys are then not needed
+func WithCredentials(provider CredentialsProvider) Option {
+	return func(o *clientOptions) {
+		o.credentials = provider
+	}
+}
+
+// WithBaseURL sets the API base URL (e.g., an httptest server URL in tests)
+func WithBaseURL(baseURL string) Option {
+	return func(o *clientOptions) {
+		o.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithV2BaseURL sets the API v2 base URL. By default it is derived from the
+// base URL, replacing a trailing /v1 with /v2 or appending /v2.
+func WithV2BaseURL(baseURL string) Option {
+	return func(o *clientOptions) {
+		o.v2BaseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *clientOptions) {
+		o.httpClient = httpClient
+	}
+}
+
+// WithUserAgent overrides the User-Agent header sent on every request
+func WithUserAgent(userAgent string) Option {
+	return func(o *clientOptions) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithCacheDir enables the on-disk ETag cache for GET responses in dir.
+// An empty dir disables caching.
+func WithCacheDir(dir string) Option {
+	return func(o *clientOptions) {
+		o.cacheDir = dir
+	}
+}
+
+// WithContext sets the context every request is made with. Cancelling it aborts
+// in-flight requests and stops bulk operations between monitors.
+func WithContext(ctx context.Context) Option {
+	return func(o *clientOptions) {
+		o.ctx = ctx
+	}
+}
+
+// WithStats records every request of the client in stats. Several clients can
+// share the same Stats.
+func WithStats(stats *Stats) Option {
+	return func(o *clientOptions) {
+		o.stats = stats
+	}
+}
+
+// WithAudit records every mutating request of the client (creates, updates,
+// deletes, mutes, ...) in the audit log
+func WithAudit(log *audit.Log) Option {
+	return func(o *clientOptions) {
+		o.audit = log
+	}
+}
+
+// WithInventoryFile sets the monitor inventory file (see Inventory) that
+// every mutating request of the client invalidates
+func WithInventoryFile(path string) Option {
+	return func(o *clientOptions) {
+		o.inventory = path
+	}
+}
+
+// WithOrgCheck checks the organization of the keys before the first mutating
+// request, which fails with an *OrgCheckError when it isn't the expected one
+func WithOrgCheck(check *OrgCheck) Option {
+	return func(o *clientOptions) {
+		o.orgCheck = check
+	}
+}
+
+// DefaultUserAgent returns the User-Agent sent when none is configured
+func DefaultUserAgent() string {
+	return fmt.Sprintf("datadog-monitor-manager/%s", version.Version)
+}
+
+// NewClientWithOptions creates a new Datadog API client from explicit options.
+// Unlike NewClient it never reads the environment.
+func NewClientWithOptions(opts ...Option) (*Client, error) {
+	o := &clientOptions{
+		baseURL:   DefaultBaseURL,
+		userAgent: DefaultUserAgent(),
+		ctx:       context.Background(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	credentials := o.credentials
+	if credentials == nil {
+		if o.apiKey == "" || o.appKey == "" {
+			return nil, fmt.Errorf("API key and application key are required")
+		}
+		credentials = StaticCredentials{APIKey: o.apiKey, AppKey: o.appKey}
+	}
+
+	if o.v2BaseURL == "" {
+		o.v2BaseURL = apiV2URL(o.baseURL)
+	}
+
+	httpClient := o.httpClient
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+
+	config := &Config{
+		APIKey:      o.apiKey,
+		AppKey:      o.appKey,
+		APIURL:      o.baseURL,
+		APIV2URL:    o.v2BaseURL,
+		Credentials: credentials,
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+			"User-Agent":   o.userAgent,
+		},
+	}
+
+	client := &Client{
+		config:    config,
+		client:    httpClient,
+		ctx:       o.ctx,
+		stats:     o.stats,
+		audit:     o.audit,
+		inventory: o.inventory,
+		orgCheck:  o.orgCheck,
+	}
+	if o.cacheDir != "" {
+		client.cache = &responseCache{dir: o.cacheDir}
+	}
+
+	return client, nil
+}
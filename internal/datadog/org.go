@@ -0,0 +1,119 @@
+package datadog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Org is the Datadog organization the API and application keys belong to
+type Org struct {
+	Name     string `json:"name"`
+	PublicID string `json:"public_id"`
+}
+
+// Matches reports whether the org has the given name (case-insensitively) or
+// public ID
+func (o Org) Matches(expected string) bool {
+	return strings.EqualFold(o.Name, expected) || o.PublicID == expected
+}
+
+// GetOrg returns the organization of the client's keys (GET /org). The
+// application key needs the org_management scope or an admin role; a 403 is
+// reported as such.
+func (c *Client) GetOrg() (*Org, error) {
+	resp, err := c.makeRequest("GET", "/org", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("the keys are not allowed to read the organization (status 403): the application key needs the org_management scope")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get organization: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Orgs []Org `json:"orgs"`
+		Org  *Org  `json:"org"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse organization: %v", err)
+	}
+	switch {
+	case len(result.Orgs) > 0:
+		return &result.Orgs[0], nil
+	case result.Org != nil:
+		return result.Org, nil
+	}
+	return nil, fmt.Errorf("failed to get organization: the response lists none")
+}
+
+// OrgCheck pins the organization mutating requests may be sent to: before
+// the first one, the org of the keys is looked up and compared with
+// Expected, a name or public ID. Several clients can share an OrgCheck; the
+// org is looked up once.
+type OrgCheck struct {
+	Expected string
+
+	mu      sync.Mutex
+	checked bool
+	err     error
+}
+
+// OrgCheckError is returned for every mutating request once the org of the
+// keys is not the expected one, or could not be looked up (Err)
+type OrgCheckError struct {
+	Expected string
+	Org      *Org
+	Err      error
+}
+
+func (e *OrgCheckError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("refusing to change anything: could not check that the keys belong to org %q: %v (skip the check with --no-org-check)", e.Expected, e.Err)
+	}
+	return fmt.Sprintf("refusing to change anything: the keys belong to org %q (public ID %s), not %q", e.Org.Name, e.Org.PublicID, e.Expected)
+}
+
+func (e *OrgCheckError) Unwrap() error {
+	return e.Err
+}
+
+// IsOrgCheckFailed reports whether err (or an error it wraps) is an OrgCheckError
+func IsOrgCheckFailed(err error) bool {
+	var orgErr *OrgCheckError
+	return errors.As(err, &orgErr)
+}
+
+// verify looks the org up with client the first time and returns an
+// *OrgCheckError, the same on every call, unless it is the expected one
+func (o *OrgCheck) verify(client *Client) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.checked {
+		org, err := client.GetOrg()
+		if err != nil && (client.ctx.Err() != nil || IsLimitExceeded(err)) {
+			// Stopped before an answer: check again on the next request
+			return err
+		}
+		o.checked = true
+		switch {
+		case err != nil:
+			o.err = &OrgCheckError{Expected: o.Expected, Err: err}
+		case !org.Matches(o.Expected):
+			o.err = &OrgCheckError{Expected: o.Expected, Org: org}
+		}
+	}
+	return o.err
+}
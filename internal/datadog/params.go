@@ -0,0 +1,223 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TemplateParam documents a template variable in the "params" metadata of a
+// template, e.g. "params": {"team": {"description": "Owning team"}}
+type TemplateParam struct {
+	Description string `json:"description,omitempty"`
+	// Default is the value used when no --var sets the variable; a param
+	// without a default is required
+	Default string `json:"default,omitempty"`
+	// Example is shown in the example invocation of template docs
+	Example string `json:"example,omitempty"`
+}
+
+// placeholderFields are the template fields {key} placeholders are replaced
+// in, by their name in template docs
+var placeholderFields = []struct {
+	name string
+	get  func(config map[string]interface{}) string
+}{
+	{"name", configString("name")},
+	{"query", configString("query")},
+	{"message", configString("message")},
+	{"escalation_message", func(config map[string]interface{}) string {
+		options, _ := config["options"].(map[string]interface{})
+		value, _ := options["escalation_message"].(string)
+		return value
+	}},
+}
+
+// configString returns a getter for a string field of a template config
+func configString(field string) func(config map[string]interface{}) string {
+	return func(config map[string]interface{}) string {
+		value, _ := config[field].(string)
+		return value
+	}
+}
+
+// templatePlaceholders returns the {key} placeholders of a template, with
+// the fields each one is used in. Datadog's {{variables}} and group-by
+// clauses such as "by {service}", which rendering preserves, are left out.
+func templatePlaceholders(template TemplateData) map[string][]string {
+	placeholders := make(map[string][]string)
+	for _, field := range placeholderFields {
+		value := field.get(template.Config)
+		for _, match := range variablePattern.FindAllStringSubmatchIndex(value, -1) {
+			// {{variables}} are Datadog's; a placeholder closing a scope,
+			// as in {env:{env}}, ends with }} too
+			if strings.HasPrefix(value[match[0]:match[1]], "{{") {
+				continue
+			}
+			if field.name == "query" && strings.HasSuffix(strings.TrimRight(value[:match[0]], " "), " by") {
+				continue
+			}
+			key := value[match[2]:match[3]]
+			if fields := placeholders[key]; len(fields) == 0 || fields[len(fields)-1] != field.name {
+				placeholders[key] = append(fields, field.name)
+			}
+		}
+	}
+	return placeholders
+}
+
+// MissingVariablesError is returned when a template uses variables that
+// have no value and no default in its params
+type MissingVariablesError struct {
+	TemplateName string
+	Vars         []string
+	// Params are the params metadata of the template, for the descriptions
+	Params map[string]TemplateParam
+}
+
+func (e *MissingVariablesError) Error() string {
+	vars := make([]string, len(e.Vars))
+	flags := make([]string, len(e.Vars))
+	for i, key := range e.Vars {
+		vars[i] = "{" + key + "}"
+		if description := e.Params[key].Description; description != "" {
+			vars[i] += " (" + description + ")"
+		}
+		flags[i] = "--var " + key + "=..."
+	}
+	return fmt.Sprintf("template %s: missing variable(s) %s: set them with %s, or give them a default in the template's params",
+		e.TemplateName, strings.Join(vars, ", "), strings.Join(flags, " "))
+}
+
+// templateVars returns the variables a template is rendered with: vars, plus
+// the defaults of its params for those vars doesn't set. It returns a
+// *MissingVariablesError when a variable the template uses has neither.
+func templateVars(template TemplateData, vars map[string]string) (map[string]string, error) {
+	merged := make(map[string]string, len(vars))
+	for key, value := range vars {
+		merged[key] = value
+	}
+	for key, param := range template.Params {
+		if _, ok := merged[key]; !ok && param.Default != "" {
+			merged[key] = param.Default
+		}
+	}
+
+	var missing []string
+	for key := range templatePlaceholders(template) {
+		if _, ok := merged[key]; !ok && !builtinPlaceholders[key] {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, &MissingVariablesError{TemplateName: templateDocName(template), Vars: missing, Params: template.Params}
+	}
+	return merged, nil
+}
+
+// TemplateParams returns the params metadata of templates by variable; the
+// first template documenting a variable wins
+func TemplateParams(templates []TemplateData) map[string]TemplateParam {
+	params := make(map[string]TemplateParam)
+	for _, template := range templates {
+		for key, param := range template.Params {
+			if _, ok := params[key]; !ok {
+				params[key] = param
+			}
+		}
+	}
+	return params
+}
+
+// TemplateDoc is the parameter reference of a template (template docs)
+type TemplateDoc struct {
+	Name        string
+	Description string
+	Disabled    bool
+	Params      []ParamDoc
+}
+
+// ParamDoc documents one parameter of a template
+type ParamDoc struct {
+	Name        string
+	Description string
+	Default     string
+	Example     string
+	// Required params are used and have no default
+	Required bool
+	// Builtin params are {service}, {env} and {namespace}, set by their flags
+	Builtin bool
+	// Fields are the template fields the placeholder is used in; none for a
+	// param documented in params but not used
+	Fields []string
+}
+
+// builtinParamOrder is the order of the built-in params in a TemplateDoc
+var builtinParamOrder = []string{"service", "env", "namespace"}
+
+// DocumentTemplate returns the parameter reference of a template: the
+// built-in placeholders it uses, then its variables sorted by name, merged
+// with the descriptions and defaults of its params metadata
+func DocumentTemplate(template TemplateData) TemplateDoc {
+	placeholders := templatePlaceholders(template)
+	doc := TemplateDoc{Name: templateDocName(template), Description: template.Description, Disabled: template.Disabled}
+
+	for _, key := range builtinParamOrder {
+		if fields, ok := placeholders[key]; ok {
+			doc.Params = append(doc.Params, ParamDoc{Name: key, Description: template.Params[key].Description, Required: true, Builtin: true, Fields: fields})
+		}
+	}
+
+	var keys []string
+	for key := range placeholders {
+		if !builtinPlaceholders[key] {
+			keys = append(keys, key)
+		}
+	}
+	for key := range template.Params {
+		if _, ok := placeholders[key]; !ok && !builtinPlaceholders[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		param := template.Params[key]
+		doc.Params = append(doc.Params, ParamDoc{
+			Name:        key,
+			Description: param.Description,
+			Default:     param.Default,
+			Example:     param.Example,
+			Required:    param.Default == "" && len(placeholders[key]) > 0,
+			Fields:      placeholders[key],
+		})
+	}
+	return doc
+}
+
+// templateDocName returns the name template docs and errors show: the
+// template's name, or the monitor name of a single template file
+func templateDocName(template TemplateData) string {
+	if template.Name == "Single Template" {
+		if name, _ := template.Config["name"].(string); name != "" {
+			return name
+		}
+	}
+	return templateDisplayName(template)
+}
+
+// takeTemplateMetadata moves the description and params keys of a single
+// template file out of the monitor definition into template
+func takeTemplateMetadata(template *TemplateData) {
+	if description, ok := template.Config["description"].(string); ok {
+		template.Description = description
+		delete(template.Config, "description")
+	}
+	if params, ok := template.Config["params"]; ok {
+		data, _ := json.Marshal(params)
+		if err := json.Unmarshal(data, &template.Params); err == nil {
+			delete(template.Config, "params")
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package datadog
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// placeholderModifiers are the case modifiers a placeholder can take, as in
+// {env:upper}
+var placeholderModifiers = map[string]func(string) string{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"title": titleCase,
+}
+
+// placeholderPattern matches a {key} or {key:modifier} placeholder, which is
+// not part of the log search syntax either. A scope such as {env:prd} doesn't
+// match, as prd is not a modifier.
+var placeholderPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)(?::(upper|lower|title))?\}`)
+
+// ReplacePlaceholders replaces the {key} and {key:modifier} placeholders of s
+// whose key has a value, in one pass: a value containing braces is never
+// replaced again, and adjacent placeholders ({service}{env}) are both
+// replaced. Other braces, such as query scopes and unknown keys, are kept.
+func ReplacePlaceholders(s string, values map[string]string) string {
+	if len(values) == 0 || !strings.Contains(s, "{") {
+		return s
+	}
+	return placeholderPattern.ReplaceAllStringFunc(s, func(match string) string {
+		groups := placeholderPattern.FindStringSubmatch(match)
+		value, ok := values[groups[1]]
+		if !ok {
+			return match
+		}
+		if modify := placeholderModifiers[groups[2]]; modify != nil {
+			value = modify(value)
+		}
+		return value
+	})
+}
+
+// titleCase uppercases the first letter of each word of s, words being runs
+// of letters and digits (my-app gives My-App); the other letters are kept
+func titleCase(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	wordStart := true
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && wordStart {
+			r = unicode.ToTitle(r)
+		}
+		wordStart = !isWord
+		b.WriteRune(r)
+	}
+	return b.String()
+}
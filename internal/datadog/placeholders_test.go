@@ -0,0 +1,77 @@
+package datadog
+
+import "testing"
+
+func TestReplacePlaceholders(t *testing.T) {
+	values := map[string]string{"service": "my-app", "env": "prd", "namespace": "Shop", "braces": "{env}"}
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"{service}", "my-app"},
+		{"[{env:upper}] {service:title} on {namespace:lower}", "[PRD] My-App on shop"},
+		// Adjacent placeholders are all replaced
+		{"{service}{env}", "my-appprd"},
+		{"{service}-{env}{namespace:upper}", "my-app-prdSHOP"},
+		// Values are not replaced again
+		{"{braces}", "{env}"},
+		// Literal braces and Datadog template variables are kept
+		{"{}", "{}"},
+		{"{ env }", "{ env }"},
+		{"{{host.name}}", "{{host.name}}"},
+		{"{{#is_alert}}{service}{{/is_alert}}", "{{#is_alert}}my-app{{/is_alert}}"},
+		{"{{service}}", "{my-app}"},
+		// Unknown keys and modifiers are kept
+		{"{owner}", "{owner}"},
+		{"{env:reverse}", "{env:reverse}"},
+		{"{env:UPPER}", "{env:UPPER}"},
+		{"{env:upper:lower}", "{env:upper:lower}"},
+		// Query scopes look like placeholders with a value, not a modifier
+		{"avg:cpu{env:prd,service:{service}}", "avg:cpu{env:prd,service:my-app}"},
+		{"avg:cpu{env:prd}", "avg:cpu{env:prd}"},
+		{"avg:cpu{env:upper-tier}", "avg:cpu{env:upper-tier}"},
+		{"no braces", "no braces"},
+	} {
+		if got := ReplacePlaceholders(tc.in, values); got != tc.want {
+			t.Errorf("ReplacePlaceholders(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+
+	if got := ReplacePlaceholders("{env}", nil); got != "{env}" {
+		t.Errorf("ReplacePlaceholders without values = %q", got)
+	}
+}
+
+func TestTitleCase(t *testing.T) {
+	for in, want := range map[string]string{
+		"my-app":       "My-App",
+		"checkout api": "Checkout Api",
+		"mySQL":        "MySQL",
+		"3d-render":    "3d-Render",
+		"élan vital":   "Élan Vital",
+		"":             "",
+	} {
+		if got := titleCase(in); got != want {
+			t.Errorf("titleCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRenderTemplateEnvInName(t *testing.T) {
+	template := map[string]interface{}{
+		"name":  "[{env}] {service} CPU",
+		"query": "avg(last_5m):avg:cpu{env:{env},service:{service}} > 90",
+	}
+	for _, tc := range []struct {
+		upper               bool
+		wantName, wantQuery string
+	}{
+		{false, "[prd] api CPU", "avg(last_5m):avg:cpu{env:prd,service:api} > 90"},
+		// The deprecated option only changes names
+		{true, "[PRD] api CPU", "avg(last_5m):avg:cpu{env:prd,service:api} > 90"},
+	} {
+		rendered := RenderTemplate(template, RenderOptions{Service: "api", Env: "prd", UppercaseEnvInName: tc.upper})
+		if rendered["name"] != tc.wantName || rendered["query"] != tc.wantQuery {
+			t.Errorf("UppercaseEnvInName=%v: name %q, query %q", tc.upper, rendered["name"], rendered["query"])
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// MonitorPolicy is a monitor configuration policy from the v2 API. Tag
+// policies require monitors to carry a tag key with one of the valid values.
+type MonitorPolicy struct {
+	ID          string   `json:"id"`
+	PolicyType  string   `json:"policy_type"`
+	TagKey      string   `json:"tag_key,omitempty"`
+	Required    bool     `json:"tag_key_required"`
+	ValidValues []string `json:"valid_tag_values,omitempty"`
+}
+
+// monitorPoliciesResponse is the JSON:API envelope of GET /api/v2/monitor/policy
+type monitorPoliciesResponse struct {
+	Data []struct {
+		ID         string `json:"id"`
+		Attributes struct {
+			PolicyType string `json:"policy_type"`
+			Policy     struct {
+				TagKey         string   `json:"tag_key"`
+				TagKeyRequired bool     `json:"tag_key_required"`
+				ValidTagValues []string `json:"valid_tag_values"`
+			} `json:"policy"`
+		} `json:"attributes"`
+	} `json:"data"`
+}
+
+// ListMonitorPolicies lists the monitor configuration policies of the organization
+func (c *Client) ListMonitorPolicies() ([]MonitorPolicy, error) {
+	resp, err := c.makeV2Request("GET", "/monitor/policy", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to list monitor policies: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var result monitorPoliciesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	policies := make([]MonitorPolicy, 0, len(result.Data))
+	for _, data := range result.Data {
+		policy := data.Attributes.Policy
+		policies = append(policies, MonitorPolicy{
+			ID:          data.ID,
+			PolicyType:  data.Attributes.PolicyType,
+			TagKey:      policy.TagKey,
+			Required:    policy.TagKeyRequired,
+			ValidValues: policy.ValidTagValues,
+		})
+	}
+	return policies, nil
+}
@@ -0,0 +1,91 @@
+package datadog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+const policiesFixture = `{"data": [
+  {"id": "p1", "type": "monitor-config-policy", "attributes": {"policy_type": "tag", "policy": {"tag_key": "team", "tag_key_required": true, "valid_tag_values": ["sre", "payments"]}}},
+  {"id": "p2", "type": "monitor-config-policy", "attributes": {"policy_type": "tag", "policy": {"tag_key": "env", "tag_key_required": false, "valid_tag_values": ["prd", "stg"]}}}
+]}`
+
+func TestAPIV2URL(t *testing.T) {
+	for base, want := range map[string]string{
+		"https://api.datadoghq.com/api/v1": "https://api.datadoghq.com/api/v2",
+		"https://api.datadoghq.eu/api/v1":  "https://api.datadoghq.eu/api/v2",
+		"http://127.0.0.1:8080/api/v1":     "http://127.0.0.1:8080/api/v2",
+		// A base URL without a version gets one appended
+		"https://proxy.internal/datadog": "https://proxy.internal/datadog/v2",
+	} {
+		if got := apiV2URL(base); got != want {
+			t.Errorf("apiV2URL(%q) = %q, want %q", base, got, want)
+		}
+	}
+}
+
+func TestClientAPIVersions(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/api/v1/monitor/1", "/v1/monitor/1":
+			w.Write([]byte(`{"id": 1, "name": "CPU"}`))
+		case "/api/v2/monitor/policy", "/v2/monitor/policy":
+			w.Write([]byte(policiesFixture))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		name      string
+		opts      []Option
+		wantPaths []string
+	}{
+		{"derived v2 URL", []Option{WithBaseURL(srv.URL + "/api/v1")}, []string{"/api/v1/monitor/1", "/api/v2/monitor/policy"}},
+		{"explicit v2 URL", []Option{WithBaseURL(srv.URL + "/v1"), WithV2BaseURL(srv.URL + "/v2/")}, []string{"/v1/monitor/1", "/v2/monitor/policy"}},
+	} {
+		paths = nil
+		client, err := NewClientWithOptions(append([]Option{WithAPIKey("api-key"), WithAppKey("app-key")}, tc.opts...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.GetMonitor(1); err != nil {
+			t.Errorf("%s: GetMonitor: %v", tc.name, err)
+		}
+		policies, err := client.ListMonitorPolicies()
+		if err != nil {
+			t.Errorf("%s: ListMonitorPolicies: %v", tc.name, err)
+		}
+		if !reflect.DeepEqual(paths, tc.wantPaths) {
+			t.Errorf("%s: paths %v, want %v", tc.name, paths, tc.wantPaths)
+		}
+		want := []MonitorPolicy{
+			{ID: "p1", PolicyType: "tag", TagKey: "team", Required: true, ValidValues: []string{"sre", "payments"}},
+			{ID: "p2", PolicyType: "tag", TagKey: "env", ValidValues: []string{"prd", "stg"}},
+		}
+		if !reflect.DeepEqual(policies, want) {
+			t.Errorf("%s: policies %+v, want %+v", tc.name, policies, want)
+		}
+	}
+}
+
+func TestListMonitorPoliciesError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"errors": ["Forbidden"]}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClientWithOptions(WithAPIKey("api-key"), WithAppKey("app-key"), WithBaseURL(srv.URL+"/api/v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ListMonitorPolicies(); err == nil {
+		t.Error("ListMonitorPolicies succeeded on a 403")
+	}
+}
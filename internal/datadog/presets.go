@@ -0,0 +1,128 @@
+package datadog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// OptionPreset is a named bundle of monitor options applied to rendered
+// templates with --preset
+type OptionPreset struct {
+	Name        string
+	Description string
+	Options     map[string]interface{}
+	// Custom is set for presets from the config file
+	Custom bool
+}
+
+// BuiltinPresets are the presets available without configuration; presets of
+// the same name in the config file replace them
+var BuiltinPresets = []OptionPreset{
+	{
+		Name:        "business-hours",
+		Description: "Only evaluate (and so notify) on weekdays from 09:00 to 18:00 UTC",
+		Options: map[string]interface{}{
+			"scheduling_options": map[string]interface{}{
+				"custom_schedule": map[string]interface{}{
+					"recurrences": []interface{}{
+						map[string]interface{}{
+							"rrule":    "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;BYHOUR=9,10,11,12,13,14,15,16,17;BYMINUTE=0",
+							"timezone": "UTC",
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        "low-noise",
+		Description: "No re-notifications and a 30 minute evaluation delay",
+		Options: map[string]interface{}{
+			"renotify_interval": 0,
+			"evaluation_delay":  1800,
+		},
+	},
+	{
+		Name:        "strict",
+		Description: "Notify when data stops arriving for 10 minutes",
+		Options: map[string]interface{}{
+			"notify_no_data":    true,
+			"no_data_timeframe": 10,
+		},
+	},
+}
+
+// OptionPresets returns the built-in presets and the custom ones, by name;
+// a custom preset replaces the built-in preset of the same name
+func OptionPresets(custom map[string]OptionPreset) map[string]OptionPreset {
+	presets := make(map[string]OptionPreset, len(BuiltinPresets)+len(custom))
+	for _, preset := range BuiltinPresets {
+		presets[preset.Name] = preset
+	}
+	for name, preset := range custom {
+		preset.Name = name
+		preset.Custom = true
+		presets[name] = preset
+	}
+	return presets
+}
+
+// SortedPresetNames returns the names of presets, sorted
+func SortedPresetNames(presets map[string]OptionPreset) []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PresetOptions deep-merges the options of the named presets, in order: a
+// later preset wins over an earlier one
+func PresetOptions(names []string, presets map[string]OptionPreset) (map[string]interface{}, error) {
+	merged := make(map[string]interface{})
+	for _, name := range names {
+		preset, ok := presets[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown preset %q (available: %s)", name, strings.Join(SortedPresetNames(presets), ", "))
+		}
+		merged = MergeOptions(merged, preset.Options)
+	}
+	return merged, nil
+}
+
+// MergeOptions deep-merges override into base and returns the result without
+// modifying either: objects are merged key by key, any other value of
+// override (including lists and null) replaces the one of base
+func MergeOptions(base, override map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(override))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range override {
+		baseMap, baseIsMap := merged[key].(map[string]interface{})
+		overrideMap, overrideIsMap := value.(map[string]interface{})
+		if baseIsMap && overrideIsMap {
+			merged[key] = MergeOptions(baseMap, overrideMap)
+		} else {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// applyPresetOptions returns a template config whose options are the preset
+// options overridden by the template's own
+func applyPresetOptions(config map[string]interface{}, presetOptions map[string]interface{}) map[string]interface{} {
+	if len(presetOptions) == 0 {
+		return config
+	}
+	withPresets := make(map[string]interface{}, len(config)+1)
+	for key, value := range config {
+		withPresets[key] = value
+	}
+	options, _ := config["options"].(map[string]interface{})
+	withPresets["options"] = MergeOptions(presetOptions, options)
+	return withPresets
+}
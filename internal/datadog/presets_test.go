@@ -0,0 +1,145 @@
+package datadog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergeOptions(t *testing.T) {
+	base := map[string]interface{}{
+		"renotify_interval": 60,
+		"thresholds":        map[string]interface{}{"critical": 90, "warning": 80},
+		"notify_by":         []interface{}{"host", "pod"},
+	}
+	override := map[string]interface{}{
+		"thresholds": map[string]interface{}{"warning": 70, "critical_recovery": 85},
+		"notify_by":  []interface{}{"cluster"},
+		"timeout_h":  nil,
+	}
+	want := map[string]interface{}{
+		"renotify_interval": 60,
+		"thresholds":        map[string]interface{}{"critical": 90, "warning": 70, "critical_recovery": 85},
+		"notify_by":         []interface{}{"cluster"},
+		"timeout_h":         nil,
+	}
+	if got := MergeOptions(base, override); !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeOptions = %v, want %v", got, want)
+	}
+	// Neither side is modified
+	if thresholds := base["thresholds"].(map[string]interface{}); len(thresholds) != 2 || thresholds["warning"] != 80 {
+		t.Errorf("base modified: %v", base)
+	}
+	if len(override) != 3 {
+		t.Errorf("override modified: %v", override)
+	}
+}
+
+func TestPresetOptions(t *testing.T) {
+	presets := OptionPresets(map[string]OptionPreset{
+		"team":   {Options: map[string]interface{}{"renotify_interval": 120, "evaluation_delay": 60}},
+		"strict": {Description: "Stricter than the built-in", Options: map[string]interface{}{"notify_no_data": true, "no_data_timeframe": 5}},
+	})
+	if !presets["team"].Custom || presets["team"].Name != "team" || presets["low-noise"].Custom {
+		t.Errorf("presets %+v", presets)
+	}
+	// A custom preset replaces the built-in one of its name
+	if presets["strict"].Options["no_data_timeframe"] != 5 {
+		t.Errorf("custom strict preset %v", presets["strict"].Options)
+	}
+	if got := strings.Join(SortedPresetNames(presets), ","); got != "business-hours,low-noise,strict,team" {
+		t.Errorf("preset names %s", got)
+	}
+
+	// Later presets win
+	for _, tc := range []struct {
+		names []string
+		want  map[string]interface{}
+	}{
+		{[]string{"team", "low-noise"}, map[string]interface{}{"renotify_interval": 0, "evaluation_delay": 1800}},
+		{[]string{"low-noise", "team"}, map[string]interface{}{"renotify_interval": 120, "evaluation_delay": 60}},
+		{nil, map[string]interface{}{}},
+	} {
+		got, err := PresetOptions(tc.names, presets)
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("PresetOptions(%v) = %v, %v, want %v", tc.names, got, err, tc.want)
+		}
+	}
+
+	if _, err := PresetOptions([]string{"low-noise", "quiet"}, presets); err == nil || !strings.Contains(err.Error(), `unknown preset "quiet" (available: business-hours, low-noise, strict, team)`) {
+		t.Errorf("unknown preset: %v", err)
+	}
+}
+
+// TestOptionPrecedence pins the order options are layered in, lowest first:
+// defaults (k8s_defaults), presets, the template's own options, and the
+// per-env threshold_scale applied to the rendered monitor
+func TestOptionPrecedence(t *testing.T) {
+	path := writeTemplateFile(t, `{
+  "templates": [
+    {
+      "name": "Pod CPU",
+      "config": {
+        "name": "{service} pod CPU",
+        "type": "query alert",
+        "query": "avg(last_5m):avg:kubernetes.cpu.usage.total{service:{service}} by {pod} > 100",
+        "message": "CPU",
+        "options": {
+          "thresholds": {"critical": 100},
+          "renotify_interval": 15
+        }
+      }
+    },
+    {
+      "name": "Pod memory",
+      "config": {
+        "name": "{service} pod memory",
+        "type": "query alert",
+        "query": "avg(last_5m):avg:kubernetes.memory.usage{service:{service}} by {pod} > 100",
+        "message": "Memory",
+        "options": {"thresholds": {"critical": 100}}
+      }
+    }
+  ]
+}`)
+	presets, err := PresetOptions([]string{"low-noise"}, OptionPresets(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := ApplyOptions{
+		RenderOptions:  RenderOptions{Service: "api", Env: "prd"},
+		K8sDefaults:    &K8sDefaults{EvaluationDelay: 60, NewGroupDelay: 120},
+		PresetOptions:  presets,
+		ThresholdScale: 2,
+	}
+	rendered, _, err := RenderSelectedTemplates(path, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		monitor int
+		option  string
+		want    float64
+		from    string
+	}{
+		{0, "new_group_delay", 120, "defaults"},
+		{0, "evaluation_delay", 1800, "the preset, over the defaults"},
+		{1, "renotify_interval", 0, "the preset"},
+		{0, "renotify_interval", 15, "the template, over the preset"},
+		{0, "thresholds.critical", 200, "threshold_scale, over the template"},
+	} {
+		options := rendered[tc.monitor].Monitor.Options
+		key, nested, isNested := strings.Cut(tc.option, ".")
+		value := options[key]
+		if isNested {
+			value = value.(map[string]interface{})[nested]
+		}
+		if got, ok := toFloat(value); !ok || got != tc.want {
+			t.Errorf("%s of %q = %v, want %v from %s", tc.option, rendered[tc.monitor].Monitor.Name, value, tc.want, tc.from)
+		}
+	}
+	if got := rendered[0].Monitor.Query; !strings.HasSuffix(got, "> 200") {
+		t.Errorf("scaled query %q", got)
+	}
+}
@@ -0,0 +1,250 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metricMonitorQueryPattern splits a metric monitor query such as
+// avg(last_5m):avg:system.cpu.user{service:foo} by {host} > 80
+var metricMonitorQueryPattern = regexp.MustCompile(`^\s*(\w+)\((last_(\d+)([mhdw]))\)\s*:\s*(.+?)\s*(>=|<=|>|<)\s*(-?[0-9]*\.?[0-9]+(?:[eE][-+]?[0-9]+)?)\s*$`)
+
+// windowUnits maps the unit of an evaluation window to its duration
+var windowUnits = map[string]time.Duration{
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// MetricMonitorQuery is a metric monitor query split into the alert wrapper
+// and the underlying metrics query
+type MetricMonitorQuery struct {
+	Aggregation string        // Time aggregation (avg, sum, min, max, last)
+	Window      string        // Evaluation window, e.g. last_5m
+	Duration    time.Duration // Evaluation window as a duration
+	Metric      string        // Metrics query, e.g. avg:system.cpu.user{service:foo} by {host}
+	Comparator  string
+	Threshold   float64
+}
+
+// ParseMetricMonitorQuery strips the alert wrapper from a metric monitor query
+func ParseMetricMonitorQuery(query string) (*MetricMonitorQuery, error) {
+	match := metricMonitorQueryPattern.FindStringSubmatch(query)
+	if match == nil {
+		return nil, fmt.Errorf("not a metric monitor query (expected e.g. avg(last_5m):avg:system.cpu.user{service:foo} > 80): %s", query)
+	}
+
+	aggregation := match[1]
+	if aggregation != "last" && !containsString(QueryAggregations, aggregation) {
+		return nil, fmt.Errorf("unsupported time aggregation %q (must be one of: %s, last)", aggregation, strings.Join(QueryAggregations, ", "))
+	}
+	count, _ := strconv.Atoi(match[3])
+	threshold, err := strconv.ParseFloat(match[7], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid threshold %q: %v", match[7], err)
+	}
+
+	return &MetricMonitorQuery{
+		Aggregation: aggregation,
+		Window:      match[2],
+		Duration:    time.Duration(count) * windowUnits[match[4]],
+		Metric:      match[5],
+		Comparator:  match[6],
+		Threshold:   threshold,
+	}, nil
+}
+
+// Breaches reports whether value crosses the threshold
+func (q MetricMonitorQuery) Breaches(value float64) bool {
+	switch q.Comparator {
+	case ">":
+		return value > q.Threshold
+	case ">=":
+		return value >= q.Threshold
+	case "<":
+		return value < q.Threshold
+	case "<=":
+		return value <= q.Threshold
+	}
+	return false
+}
+
+// Aggregate reduces the values of the evaluation window the way the monitor does
+func (q MetricMonitorQuery) Aggregate(points []MetricPoint) float64 {
+	if len(points) == 0 {
+		return 0
+	}
+	result := points[0].Value
+	switch q.Aggregation {
+	case "last":
+		result = points[len(points)-1].Value
+	case "sum", "avg":
+		result = 0
+		for _, point := range points {
+			result += point.Value
+		}
+		if q.Aggregation == "avg" {
+			result /= float64(len(points))
+		}
+	case "min":
+		for _, point := range points {
+			if point.Value < result {
+				result = point.Value
+			}
+		}
+	case "max":
+		for _, point := range points {
+			if point.Value > result {
+				result = point.Value
+			}
+		}
+	}
+	return result
+}
+
+// MetricPoint is one datapoint of a metric series
+type MetricPoint struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// MetricSeries is one series (group) returned by the metrics query endpoint
+type MetricSeries struct {
+	Metric     string       `json:"metric"`
+	Scope      string       `json:"scope"`
+	Expression string       `json:"expression"`
+	TagSet     []string     `json:"tag_set"`
+	Pointlist  [][]*float64 `json:"pointlist"`
+}
+
+// Points returns the datapoints of the series, skipping null values
+func (s MetricSeries) Points() []MetricPoint {
+	var points []MetricPoint
+	for _, pair := range s.Pointlist {
+		if len(pair) < 2 || pair[0] == nil || pair[1] == nil {
+			continue
+		}
+		points = append(points, MetricPoint{
+			Timestamp: time.UnixMilli(int64(*pair[0])),
+			Value:     *pair[1],
+		})
+	}
+	return points
+}
+
+// metricsQueryResponse is the response of GET /query
+type metricsQueryResponse struct {
+	Status string         `json:"status"`
+	Error  string         `json:"error"`
+	Series []MetricSeries `json:"series"`
+}
+
+// QueryMetrics runs a metrics query (e.g., avg:system.cpu.user{service:foo} by {host})
+// over the given time range
+func (c *Client) QueryMetrics(from, to time.Time, query string) ([]MetricSeries, error) {
+	req, err := c.newRequest("GET", "/query", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	q := req.URL.Query()
+	q.Set("from", strconv.FormatInt(from.Unix(), 10))
+	q.Set("to", strconv.FormatInt(to.Unix(), 10))
+	q.Set("query", query)
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to query metrics: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var result metricsQueryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	if result.Status == "error" {
+		return nil, fmt.Errorf("failed to query metrics: %s", result.Error)
+	}
+
+	return result.Series, nil
+}
+
+// SeriesPreview is the current evaluation of one group of a metric monitor
+type SeriesPreview struct {
+	Scope  string
+	Latest MetricPoint
+	Value  float64 // Window aggregate the monitor compares with the threshold
+	Points int
+	Breach bool
+}
+
+// QueryPreview is what a metric monitor query returns right now
+type QueryPreview struct {
+	Query  MetricMonitorQuery
+	Series []SeriesPreview
+}
+
+// Breaching returns the number of groups that would breach the threshold
+func (p QueryPreview) Breaching() int {
+	count := 0
+	for _, series := range p.Series {
+		if series.Breach {
+			count++
+		}
+	}
+	return count
+}
+
+// PreviewMonitorQuery evaluates a metric monitor query over its window ending at
+// now, returning the latest datapoint and window aggregate per group
+func (c *Client) PreviewMonitorQuery(query string, now time.Time) (*QueryPreview, error) {
+	parsed, err := ParseMetricMonitorQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	series, err := c.QueryMetrics(now.Add(-parsed.Duration), now, parsed.Metric)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &QueryPreview{Query: *parsed}
+	for _, s := range series {
+		points := s.Points()
+		if len(points) == 0 {
+			continue
+		}
+		value := parsed.Aggregate(points)
+		preview.Series = append(preview.Series, SeriesPreview{
+			Scope:  s.Scope,
+			Latest: points[len(points)-1],
+			Value:  value,
+			Points: len(points),
+			Breach: parsed.Breaches(value),
+		})
+	}
+	sort.Slice(preview.Series, func(i, j int) bool {
+		return preview.Series[i].Scope < preview.Series[j].Scope
+	})
+
+	return preview, nil
+}
+
+// IsMetricMonitor reports whether a monitor type is evaluated from a metrics query
+func IsMetricMonitor(monitorType string) bool {
+	return monitorType == "metric alert" || monitorType == "query alert"
+}
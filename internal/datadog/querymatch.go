@@ -0,0 +1,93 @@
+package datadog
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// QuerySignature returns what matching by query (ApplyOptions.MatchByQuery)
+// compares: the monitor type and its query without whitespace or the
+// comparison value it ends with, so a monitor whose threshold was tuned still
+// matches. "metric alert" is the same type as "query alert", as the API
+// reports it. Monitors without a query have no signature.
+func QuerySignature(monitor Monitor) string {
+	query := NormalizeQuery(monitor.Query)
+	if query == "" {
+		return ""
+	}
+	if loc := queryComparisonPattern.FindStringSubmatchIndex(query); loc != nil {
+		// Keep the comparison operator, drop the value
+		query = query[:loc[4]]
+	}
+	monitorType := monitor.Type
+	if monitorType == "metric alert" {
+		monitorType = "query alert"
+	}
+	return monitorType + "|" + query
+}
+
+// QueryMatchError is returned when a rendered monitor matches no monitor by
+// identity or name, and several by query: which one to update is ambiguous
+type QueryMatchError struct {
+	Name string
+	IDs  []int
+}
+
+func (e *QueryMatchError) Error() string {
+	ids := make([]string, len(e.IDs))
+	for i, id := range e.IDs {
+		ids[i] = strconv.Itoa(id)
+	}
+	return fmt.Sprintf("monitor %q has no name or identity match and %d monitors share its query (IDs %s): rename the one to update to the template's name",
+		e.Name, len(e.IDs), strings.Join(ids, ", "))
+}
+
+// matchQuery returns the monitor among candidates with the QuerySignature of
+// a rendered monitor, nil when none has it, or a *QueryMatchError when
+// several do
+func matchQuery(monitor Monitor, candidates []Monitor) (*Monitor, error) {
+	signature := QuerySignature(monitor)
+	if signature == "" {
+		return nil, nil
+	}
+	var matches []Monitor
+	for _, candidate := range candidates {
+		if QuerySignature(candidate) == signature {
+			matches = append(matches, candidate)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &matches[0], nil
+	}
+	ids := make([]int, len(matches))
+	for i, match := range matches {
+		ids[i] = match.ID
+	}
+	sort.Ints(ids)
+	return nil, &QueryMatchError{Name: monitor.Name, IDs: ids}
+}
+
+// queryIndex looks monitors up by query for the upserts of an apply. Every
+// monitor is listed once, on first use, and shared by the templates.
+type queryIndex struct {
+	client   *Client
+	monitors []Monitor
+	loaded   bool
+}
+
+// match returns the monitor with the query of a rendered monitor, see matchQuery
+func (i *queryIndex) match(monitor Monitor) (*Monitor, error) {
+	if !i.loaded {
+		monitors, err := i.client.ListMonitors(nil, "")
+		if err != nil {
+			return nil, err
+		}
+		i.monitors, i.loaded = monitors, true
+	}
+	return matchQuery(monitor, i.monitors)
+}
@@ -0,0 +1,80 @@
+package datadog
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestQuerySignature(t *testing.T) {
+	signature := func(monitorType, query string) string {
+		return QuerySignature(Monitor{Type: monitorType, Query: query})
+	}
+	base := signature("query alert", "avg(last_5m):avg:system.cpu.user{env:prd,service:api} > 90")
+	if base != "query alert|avg(last_5m):avg:system.cpu.user{env:prd,service:api}>" {
+		t.Fatalf("signature %q", base)
+	}
+
+	// Same signature: whitespace, threshold value and the metric alert alias
+	for _, query := range []string{
+		"avg(last_5m):avg:system.cpu.user{env:prd,service:api} > 95",
+		"avg(last_5m):avg:system.cpu.user{env:prd,service:api}>90",
+		"  avg(last_5m) : avg:system.cpu.user{ env:prd, service:api }\n  >  75.5 ",
+		"avg(last_5m):avg:system.cpu.user{env:prd,service:api} > -1",
+	} {
+		if got := signature("query alert", query); got != base {
+			t.Errorf("signature of %q = %q, want %q", query, got, base)
+		}
+	}
+	if got := signature("metric alert", "avg(last_5m):avg:system.cpu.user{env:prd,service:api} > 90"); got != base {
+		t.Errorf("metric alert signature %q, want %q", got, base)
+	}
+
+	// Different signature: the operator, the scope, the window, the type
+	for _, tc := range []struct{ monitorType, query string }{
+		{"query alert", "avg(last_5m):avg:system.cpu.user{env:prd,service:api} >= 90"},
+		{"query alert", "avg(last_5m):avg:system.cpu.user{env:prd,service:api} < 90"},
+		{"query alert", "avg(last_5m):avg:system.cpu.user{env:stg,service:api} > 90"},
+		{"query alert", "avg(last_15m):avg:system.cpu.user{env:prd,service:api} > 90"},
+		{"service check", "avg(last_5m):avg:system.cpu.user{env:prd,service:api} > 90"},
+	} {
+		if got := signature(tc.monitorType, tc.query); got == base {
+			t.Errorf("%s %q has the same signature", tc.monitorType, tc.query)
+		}
+	}
+
+	// Queries without a trailing comparison are compared whole
+	check := `"http.can_connect".over("env:prd").by("host").last(3).count_by_status()`
+	if got := signature("service check", check); got != "service check|"+check {
+		t.Errorf("service check signature %q", got)
+	}
+	if got := signature("composite", ""); got != "" {
+		t.Errorf("signature without a query %q", got)
+	}
+}
+
+func TestMatchQuery(t *testing.T) {
+	rendered := Monitor{Name: "[prd] api CPU", Type: "query alert", Query: "avg(last_5m):avg:system.cpu.user{env:prd,service:api} > 90"}
+	renamed := Monitor{ID: 12, Name: "api CPU (renamed in the UI)", Type: "metric alert", Query: "avg(last_5m):avg:system.cpu.user{env:prd,service:api} > 80"}
+	other := Monitor{ID: 5, Name: "stg CPU", Type: "query alert", Query: "avg(last_5m):avg:system.cpu.user{env:stg,service:api} > 90"}
+
+	match, err := matchQuery(rendered, []Monitor{other, renamed})
+	if err != nil || match == nil || match.ID != 12 {
+		t.Errorf("matchQuery = %+v, %v, want monitor 12", match, err)
+	}
+	if match, err := matchQuery(rendered, []Monitor{other}); match != nil || err != nil {
+		t.Errorf("matchQuery without a match = %+v, %v", match, err)
+	}
+
+	// Several matches abort with every candidate, sorted
+	copy1, copy2 := renamed, renamed
+	copy1.ID, copy2.ID = 30, 3
+	_, err = matchQuery(rendered, []Monitor{copy1, other, renamed, copy2})
+	var queryErr *QueryMatchError
+	if !errors.As(err, &queryErr) {
+		t.Fatalf("matchQuery with duplicates = %v, want a *QueryMatchError", err)
+	}
+	want := `monitor "[prd] api CPU" has no name or identity match and 3 monitors share its query (IDs 3, 12, 30): rename the one to update to the template's name`
+	if err.Error() != want {
+		t.Errorf("error %q\nwant  %q", err, want)
+	}
+}
@@ -0,0 +1,165 @@
+package datadog
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// windowPattern matches a monitor evaluation window such as last_5m or last_1h
+var windowPattern = regexp.MustCompile(`^last_\d+[mhdw]$`)
+
+// QueryAggregations are the time aggregations a metric monitor query accepts
+var QueryAggregations = []string{"avg", "sum", "min", "max"}
+
+// QueryComparators are the comparators a metric monitor query accepts
+var QueryComparators = []string{">", ">=", "<", "<="}
+
+// QuickMonitorOptions describes a metric monitor built from a single metric query
+type QuickMonitorOptions struct {
+	Name            string
+	Metric          string   // Metric query, e.g. avg:kubernetes.cpu.usage.total{service:foo} by {pod}
+	Aggregation     string   // Time aggregation (default avg)
+	Window          string   // Evaluation window (default last_5m)
+	Above           *float64 // Critical threshold when alerting above
+	Below           *float64 // Critical threshold when alerting below
+	Warning         *float64 // Optional warning threshold
+	NoDataTimeframe int      // Minutes without data before notifying (0 disables no-data alerts)
+	Message         string
+	Tags            []string
+}
+
+// formatThreshold formats a threshold without trailing zeros
+func formatThreshold(threshold float64) string {
+	return strconv.FormatFloat(threshold, 'f', -1, 64)
+}
+
+// BuildMetricQuery assembles a metric monitor query, e.g.
+// avg(last_5m):avg:system.cpu.user{service:foo} > 80
+func BuildMetricQuery(metric, aggregation, window, comparator string, threshold float64) (string, error) {
+	metric = strings.TrimSpace(metric)
+	if metric == "" {
+		return "", fmt.Errorf("metric is required")
+	}
+	if !containsString(QueryAggregations, aggregation) {
+		return "", fmt.Errorf("invalid aggregation %q (must be one of: %s)", aggregation, strings.Join(QueryAggregations, ", "))
+	}
+	if !windowPattern.MatchString(window) {
+		return "", fmt.Errorf("invalid window %q (expected e.g. last_5m, last_1h)", window)
+	}
+	if !containsString(QueryComparators, comparator) {
+		return "", fmt.Errorf("invalid comparator %q (must be one of: %s)", comparator, strings.Join(QueryComparators, ", "))
+	}
+	return fmt.Sprintf("%s(%s):%s %s %s", aggregation, window, metric, comparator, formatThreshold(threshold)), nil
+}
+
+// MetricName returns the metric name of a metric query, without the space
+// aggregator, scope and grouping
+func MetricName(metric string) string {
+	name := metric
+	if idx := strings.Index(name, ":"); idx >= 0 && idx < strings.Index(name+"{", "{") {
+		name = name[idx+1:]
+	}
+	if idx := strings.Index(name, "{"); idx >= 0 {
+		name = name[:idx]
+	}
+	return strings.TrimSpace(name)
+}
+
+// ScopeTags returns the key:value tags of a metric query scope, skipping
+// wildcards and negated tags
+func ScopeTags(metric string) []string {
+	start := strings.Index(metric, "{")
+	end := strings.Index(metric, "}")
+	if start < 0 || end < start {
+		return nil
+	}
+
+	var tags []string
+	for _, tag := range strings.Split(metric[start+1:end], ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" || strings.HasPrefix(tag, "!") || strings.ContainsAny(tag, "*?") || !strings.Contains(tag, ":") {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// BuildQuickMonitor builds a metric alert monitor from quick options
+func BuildQuickMonitor(opts QuickMonitorOptions) (*Monitor, error) {
+	if (opts.Above == nil) == (opts.Below == nil) {
+		return nil, fmt.Errorf("exactly one of above or below threshold is required")
+	}
+	if opts.Aggregation == "" {
+		opts.Aggregation = "avg"
+	}
+	if opts.Window == "" {
+		opts.Window = "last_5m"
+	}
+
+	comparator, critical, direction := ">", 0.0, "above"
+	if opts.Above != nil {
+		critical = *opts.Above
+	} else {
+		comparator, critical, direction = "<", *opts.Below, "below"
+	}
+
+	if opts.Warning != nil {
+		if opts.Above != nil && *opts.Warning >= critical {
+			return nil, fmt.Errorf("warning threshold (%s) must be lower than the critical threshold (%s)", formatThreshold(*opts.Warning), formatThreshold(critical))
+		}
+		if opts.Below != nil && *opts.Warning <= critical {
+			return nil, fmt.Errorf("warning threshold (%s) must be higher than the critical threshold (%s)", formatThreshold(*opts.Warning), formatThreshold(critical))
+		}
+	}
+
+	query, err := BuildMetricQuery(opts.Metric, opts.Aggregation, opts.Window, comparator, critical)
+	if err != nil {
+		return nil, err
+	}
+
+	metricName := MetricName(opts.Metric)
+	name := opts.Name
+	if name == "" {
+		name = fmt.Sprintf("%s is %s %s", metricName, direction, formatThreshold(critical))
+	}
+
+	message := opts.Message
+	if message == "" {
+		message = fmt.Sprintf("{{#is_alert}}%s is %s %s over %s (value: {{value}}){{/is_alert}}", metricName, direction, formatThreshold(critical), opts.Window)
+		if opts.Warning != nil {
+			message += fmt.Sprintf("\n{{#is_warning}}%s is %s the warning threshold %s (value: {{value}}){{/is_warning}}", metricName, direction, formatThreshold(*opts.Warning))
+		}
+		message += fmt.Sprintf("\n{{#is_recovery}}%s recovered{{/is_recovery}}", metricName)
+	}
+
+	thresholds := map[string]interface{}{"critical": critical}
+	if opts.Warning != nil {
+		thresholds["warning"] = *opts.Warning
+	}
+	options := map[string]interface{}{"thresholds": thresholds}
+	if opts.NoDataTimeframe > 0 {
+		options["notify_no_data"] = true
+		options["no_data_timeframe"] = opts.NoDataTimeframe
+	}
+
+	tags := ScopeTags(opts.Metric)
+	for _, tag := range opts.Tags {
+		if !containsString(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	monitor := &Monitor{
+		Name:    name,
+		Type:    "metric alert",
+		Query:   query,
+		Message: message,
+		Tags:    tags,
+		Options: options,
+	}
+	addManagedByTag(monitor)
+	return monitor, nil
+}
@@ -0,0 +1,116 @@
+package datadog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildMetricQuery(t *testing.T) {
+	for _, tc := range []struct {
+		metric, aggregation, window, comparator string
+		threshold                               float64
+		want                                    string
+	}{
+		{"avg:kubernetes.cpu.usage.total{service:foo} by {pod}", "avg", "last_5m", ">", 80,
+			"avg(last_5m):avg:kubernetes.cpu.usage.total{service:foo} by {pod} > 80"},
+		{"  sum:http.errors{*}.as_count()  ", "sum", "last_1h", ">=", 0.5,
+			"sum(last_1h):sum:http.errors{*}.as_count() >= 0.5"},
+		{"min:system.disk.free{host:db}", "min", "last_2d", "<", 1e9,
+			"min(last_2d):min:system.disk.free{host:db} < 1000000000"},
+		{"max:queue.depth{q:a}", "max", "last_1w", "<=", -2.25,
+			"max(last_1w):max:queue.depth{q:a} <= -2.25"},
+	} {
+		got, err := BuildMetricQuery(tc.metric, tc.aggregation, tc.window, tc.comparator, tc.threshold)
+		if err != nil || got != tc.want {
+			t.Errorf("BuildMetricQuery(%q, %s, %s, %s, %v) = %q, %v, want %q", tc.metric, tc.aggregation, tc.window, tc.comparator, tc.threshold, got, err, tc.want)
+		}
+	}
+
+	for _, tc := range []struct {
+		metric, aggregation, window, comparator string
+		wantErr                                 string
+	}{
+		{" ", "avg", "last_5m", ">", "metric is required"},
+		{"avg:cpu{*}", "median", "last_5m", ">", `invalid aggregation "median"`},
+		{"avg:cpu{*}", "avg", "5m", ">", `invalid window "5m"`},
+		{"avg:cpu{*}", "avg", "last_5s", ">", `invalid window "last_5s"`},
+		{"avg:cpu{*}", "avg", "last_m", ">", `invalid window "last_m"`},
+		{"avg:cpu{*}", "avg", "last_5m", "=>", `invalid comparator "=>"`},
+		{"avg:cpu{*}", "avg", "last_5m", "==", `invalid comparator "=="`},
+	} {
+		_, err := BuildMetricQuery(tc.metric, tc.aggregation, tc.window, tc.comparator, 1)
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("BuildMetricQuery(%q, %s, %s, %s) error %v, want %q", tc.metric, tc.aggregation, tc.window, tc.comparator, err, tc.wantErr)
+		}
+	}
+}
+
+func TestMetricNameAndScopeTags(t *testing.T) {
+	for _, tc := range []struct {
+		metric, name string
+		tags         []string
+	}{
+		{"avg:kubernetes.cpu.usage.total{service:foo,env:prd} by {pod}", "kubernetes.cpu.usage.total", []string{"service:foo", "env:prd"}},
+		{"system.load.1{host:web-1}", "system.load.1", []string{"host:web-1"}},
+		{"avg:cpu{*}", "cpu", nil},
+		{"avg:cpu{!env:dev, env:prd, service:api-*, team:a?, standalone}", "cpu", []string{"env:prd"}},
+		{"avg:cpu", "cpu", nil},
+	} {
+		if got := MetricName(tc.metric); got != tc.name {
+			t.Errorf("MetricName(%q) = %q, want %q", tc.metric, got, tc.name)
+		}
+		if got := ScopeTags(tc.metric); strings.Join(got, ",") != strings.Join(tc.tags, ",") {
+			t.Errorf("ScopeTags(%q) = %q, want %q", tc.metric, got, tc.tags)
+		}
+	}
+}
+
+func TestBuildQuickMonitor(t *testing.T) {
+	above, warning := 80.0, 70.0
+	monitor, err := BuildQuickMonitor(QuickMonitorOptions{
+		Metric:          "avg:kubernetes.cpu.usage.total{service:foo} by {pod}",
+		Above:           &above,
+		Warning:         &warning,
+		NoDataTimeframe: 10,
+		Tags:            []string{"team:infra", "service:foo"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if monitor.Query != "avg(last_5m):avg:kubernetes.cpu.usage.total{service:foo} by {pod} > 80" {
+		t.Errorf("query %q", monitor.Query)
+	}
+	if monitor.Name != "kubernetes.cpu.usage.total is above 80" {
+		t.Errorf("name %q", monitor.Name)
+	}
+	if !strings.Contains(monitor.Message, "{{#is_warning}}kubernetes.cpu.usage.total is above the warning threshold 70") {
+		t.Errorf("message %q", monitor.Message)
+	}
+	if got := strings.Join(monitor.Tags, ","); got != "service:foo,team:infra,managed-by:ddmm" {
+		t.Errorf("tags %q", got)
+	}
+	thresholds := monitor.Options["thresholds"].(map[string]interface{})
+	if thresholds["critical"] != 80.0 || thresholds["warning"] != 70.0 || monitor.Options["no_data_timeframe"] != 10 {
+		t.Errorf("options %v", monitor.Options)
+	}
+
+	below := 5.0
+	monitor, err = BuildQuickMonitor(QuickMonitorOptions{Metric: "min:disk.free{*}", Below: &below, Window: "last_1h", Aggregation: "min"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if monitor.Query != "min(last_1h):min:disk.free{*} < 5" || monitor.Options["notify_no_data"] != nil {
+		t.Errorf("below monitor: %q, %v", monitor.Query, monitor.Options)
+	}
+
+	for _, opts := range []QuickMonitorOptions{
+		{Metric: "avg:cpu{*}"},
+		{Metric: "avg:cpu{*}", Above: &above, Below: &below},
+		{Metric: "avg:cpu{*}", Above: &warning, Warning: &above},
+		{Metric: "avg:cpu{*}", Below: &above, Warning: &warning},
+	} {
+		if _, err := BuildQuickMonitor(opts); err == nil {
+			t.Errorf("BuildQuickMonitor(%+v) succeeded", opts)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ApplyReceiptVersion is the version of the apply receipt format
+const ApplyReceiptVersion = 1
+
+// ApplyReceipt is the document template --output-file writes for downstream
+// pipeline steps: every monitor the run applied, with its ID and URL, and the
+// errors of the templates that failed. It is written even when the run fails.
+type ApplyReceipt struct {
+	Version     int       `json:"version"`
+	GeneratedAt time.Time `json:"generated_at"`
+	// Success is false when Errors isn't empty
+	Success  bool                  `json:"success"`
+	Monitors []ApplyReceiptMonitor `json:"monitors"`
+	Errors   []ApplyReceiptError   `json:"errors"`
+}
+
+// ApplyReceiptMonitor is the outcome of applying one template
+type ApplyReceiptMonitor struct {
+	TemplateName string `json:"template_name"`
+	// TemplateFile is empty for --atomic applies
+	TemplateFile string       `json:"template_file,omitempty"`
+	Name         string       `json:"name"`
+	ID           int          `json:"id,omitempty"`
+	Status       ResultStatus `json:"status"` // created, existing, updated, adopted, unchanged, conflict or skipped
+	URL          string       `json:"url,omitempty"`
+	Service      string       `json:"service"`
+	Env          string       `json:"env"`
+	Namespace    string       `json:"namespace"`
+	SkipReason   string       `json:"skip_reason,omitempty"`
+}
+
+// ApplyReceiptError is a template file, or a whole apply, that failed
+type ApplyReceiptError struct {
+	TemplateFile string `json:"template_file,omitempty"`
+	Service      string `json:"service"`
+	Env          string `json:"env"`
+	Namespace    string `json:"namespace"`
+	Error        string `json:"error"`
+}
+
+// NewApplyReceipt returns an empty, successful receipt
+func NewApplyReceipt(now time.Time) *ApplyReceipt {
+	return &ApplyReceipt{
+		Version:     ApplyReceiptVersion,
+		GeneratedAt: now.UTC(),
+		Success:     true,
+		Monitors:    []ApplyReceiptMonitor{},
+		Errors:      []ApplyReceiptError{},
+	}
+}
+
+// AddResults records the results of applying templateFile for opts. Monitor
+// URLs are built from appURL when it is set.
+func (r *ApplyReceipt) AddResults(opts RenderOptions, templateFile, appURL string, results []ApplyResult) {
+	for _, result := range results {
+		monitor := ApplyReceiptMonitor{
+			TemplateName: result.TemplateName,
+			TemplateFile: templateFile,
+			Name:         result.Name,
+			ID:           result.ID,
+			Status:       result.Status,
+			Service:      opts.Service,
+			Env:          opts.Env,
+			Namespace:    opts.Namespace,
+			SkipReason:   result.SkipReason,
+		}
+		if appURL != "" && result.ID > 0 {
+			monitor.URL = fmt.Sprintf("%s/monitors/%d", appURL, result.ID)
+		}
+		r.Monitors = append(r.Monitors, monitor)
+		if result.Status == StatusFailed && result.Err != nil {
+			// A failed adoption fails the receipt like a failed file
+			r.AddError(opts, templateFile, result.Err)
+		}
+	}
+}
+
+// AddError records a failed template file (or the whole apply, with an empty
+// templateFile) for opts
+func (r *ApplyReceipt) AddError(opts RenderOptions, templateFile string, err error) {
+	r.Success = false
+	r.Errors = append(r.Errors, ApplyReceiptError{
+		TemplateFile: templateFile,
+		Service:      opts.Service,
+		Env:          opts.Env,
+		Namespace:    opts.Namespace,
+		Error:        err.Error(),
+	})
+}
+
+// Save writes the receipt to path atomically
+func (r *ApplyReceipt) Save(path string) error {
+	return writeJSONFile(path, r, ".ddmm-receipt-*")
+}
+
+// Write writes the receipt as indented JSON to w
+func (r *ApplyReceipt) Write(w io.Writer) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
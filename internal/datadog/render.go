@@ -0,0 +1,231 @@
+package datadog
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RenderOptions holds the values a template is rendered with
+type RenderOptions struct {
+	Service        string
+	Env            string
+	Namespace      string
+	AdditionalTags []string
+	// SingleValuedTagKeys are the tag keys an additional tag replaces the
+	// template's tags of instead of being added next to them (see MergeTags);
+	// nil means DefaultSingleValuedTagKeys
+	SingleValuedTagKeys []string
+	// Vars are extra template variables replacing {key} placeholders in name, query and message
+	Vars map[string]string
+	// EnvAliases maps environment names to the canonical name used in monitors (e.g., production -> prd)
+	EnvAliases map[string]string
+	// NameOverflow shortens names over MaxMonitorNameLength; "" fails them
+	NameOverflow NameOverflowPolicy
+	// UppercaseEnvInName renders {env} in names uppercased, as names were
+	// before {env:upper}; deprecated, to be removed in the next release
+	UppercaseEnvInName bool
+}
+
+// ApplyOptions holds the options for applying templates
+type ApplyOptions struct {
+	RenderOptions
+	Upsert bool
+	// OnNameConflict is what creating a monitor without Upsert does when the
+	// name already exists
+	OnNameConflict NameConflictPolicy
+	// SkipSchemaValidation disables checking templates against the monitor template schema
+	SkipSchemaValidation bool
+	// StrictScope fails a template whose query is scoped to another env or service
+	// instead of only reporting it in ApplyResult.ScopeWarnings
+	StrictScope bool
+	// ProtectUnmanaged refuses to update monitors without the managed-by tag;
+	// they are reported with StatusConflict instead
+	ProtectUnmanaged bool
+	// MatchByQuery makes upserts fall back to the monitor with the same
+	// QuerySignature when neither identity nor name matches (--match-by
+	// query), e.g. a monitor renamed in the UI; it is renamed back
+	MatchByQuery bool
+	// Selection picks the templates to apply (--only, --skip)
+	Selection TemplateSelection
+	// K8sDefaults, when set, is injected into metric monitors on Kubernetes
+	// and container metrics (see ApplyK8sDefaults)
+	K8sDefaults *K8sDefaults
+	// ThresholdScale multiplies the thresholds of the rendered monitors (see
+	// ScaleThresholds); 0 and 1 leave them alone
+	ThresholdScale float64
+	// FixNotifyBy drops the options.notify_by values the query doesn't group
+	// by (see RenderedMonitor.NotifyByDropped) instead of failing the template
+	FixNotifyBy bool
+	// PresetOptions are the options of the --preset presets (see
+	// PresetOptions), under the template's own options: presets override the
+	// defaults such as K8sDefaults, templates override presets
+	PresetOptions map[string]interface{}
+	// CreateMuted mutes the monitors the apply creates, not those it updates,
+	// until CreateMutedUntil, or indefinitely when that is zero
+	CreateMuted      bool
+	CreateMutedUntil time.Time
+}
+
+// forCreate returns the payload that creates a rendered monitor: the monitor
+// itself, or with CreateMuted a copy muted as a whole
+func (o ApplyOptions) forCreate(monitor Monitor) Monitor {
+	if o.CreateMuted {
+		MuteOnCreate(&monitor, o.CreateMutedUntil)
+	}
+	return monitor
+}
+
+// ResolveEnvAlias returns the canonical environment name for env
+func ResolveEnvAlias(env string, aliases map[string]string) string {
+	if canonical, ok := aliases[env]; ok && canonical != "" {
+		return canonical
+	}
+	return env
+}
+
+// ReplaceVars replaces the {key} and {key:modifier} placeholders of each
+// template variable
+func ReplaceVars(s string, vars map[string]string) string {
+	return ReplacePlaceholders(s, vars)
+}
+
+// builtinPlaceholders are the placeholders filled from RenderOptions rather than Vars
+var builtinPlaceholders = map[string]bool{"service": true, "env": true, "namespace": true}
+
+// variablePattern matches a {key} or {key:modifier} placeholder, and
+// Datadog's {{variables}} so they can be told apart; query scopes such as
+// {env:prd} don't match
+var variablePattern = regexp.MustCompile(`\{+([A-Za-z_][A-Za-z0-9_]*)(?::(?:upper|lower|title))?\}+`)
+
+// TemplateVariables returns the template variables used in the name, query,
+// message and escalation message of templates, sorted: the {key}
+// placeholders other than {service}, {env} and {namespace}. Group-by clauses
+// such as "by {host}" are not variables.
+func TemplateVariables(templates []TemplateData) []string {
+	seen := make(map[string]bool)
+	for _, template := range templates {
+		for key := range templatePlaceholders(template) {
+			if !builtinPlaceholders[key] {
+				seen[key] = true
+			}
+		}
+	}
+
+	vars := make([]string, 0, len(seen))
+	for key := range seen {
+		vars = append(vars, key)
+	}
+	sort.Strings(vars)
+	return vars
+}
+
+// RenderTemplate customizes a template with service-specific values
+func RenderTemplate(template map[string]interface{}, opts RenderOptions) map[string]interface{} {
+	customized, _ := renderTemplate(template, opts)
+	return customized
+}
+
+// renderTemplate is RenderTemplate, also returning the template tags the
+// additional tags replaced
+func renderTemplate(template map[string]interface{}, opts RenderOptions) (map[string]interface{}, []TagReplacement) {
+	service := opts.Service
+	env := ResolveEnvAlias(opts.Env, opts.EnvAliases)
+	namespace := opts.Namespace
+
+	customized := make(map[string]interface{})
+	for k, v := range template {
+		customized[k] = v
+	}
+
+	// Replace template variables first so they can't clash with the built-in placeholders
+	for _, field := range []string{"name", "query", "message"} {
+		if value, ok := customized[field].(string); ok {
+			customized[field] = ReplaceVars(value, opts.Vars)
+		}
+	}
+
+	// Replace placeholders in name
+	if name, ok := customized["name"].(string); ok {
+		if opts.UppercaseEnvInName {
+			// Names used to get the environment uppercased without a modifier
+			name = strings.ReplaceAll(name, "{env}", "{env:upper}")
+		}
+		customized["name"] = replaceBuiltinPlaceholders(name, service, env, namespace)
+	}
+
+	// Replace placeholders in query
+	if query, ok := customized["query"].(string); ok {
+		// Preserve "by {service}" literally
+		query = strings.ReplaceAll(query, "by {service}", "by __SERVICE_PRESERVE__")
+		query = replaceBuiltinPlaceholders(query, service, env, namespace)
+		query = strings.ReplaceAll(query, "__SERVICE_PRESERVE__", "{service}")
+		customized["query"] = query
+	}
+
+	// Replace placeholders in message
+	if message, ok := customized["message"].(string); ok {
+		customized["message"] = replaceBuiltinPlaceholders(message, service, env, namespace)
+	}
+
+	// The escalation message (re-notifications) gets the same placeholders as
+	// the message; options are copied so the template isn't modified
+	if options, ok := customized["options"].(map[string]interface{}); ok {
+		if escalation, ok := options["escalation_message"].(string); ok {
+			renderedOptions := make(map[string]interface{}, len(options))
+			for k, v := range options {
+				renderedOptions[k] = v
+			}
+			renderedOptions["escalation_message"] = replaceBuiltinPlaceholders(ReplaceVars(escalation, opts.Vars), service, env, namespace)
+			customized["options"] = renderedOptions
+		}
+	}
+
+	// Add/update tags
+	var tags []string
+	if existingTags, ok := customized["tags"].([]interface{}); ok {
+		for _, tag := range existingTags {
+			if tagStr, ok := tag.(string); ok {
+				// Templates written with another env vocabulary get the canonical env tag
+				if strings.HasPrefix(tagStr, "env:") {
+					tagStr = "env:" + ResolveEnvAlias(strings.TrimPrefix(tagStr, "env:"), opts.EnvAliases)
+				}
+				tags = append(tags, tagStr)
+			}
+		}
+	}
+
+	// Add service-specific tags
+	serviceTags := []string{
+		fmt.Sprintf("service:%s", service),
+		fmt.Sprintf("env:%s", env),
+		fmt.Sprintf("namespace:%s", namespace),
+	}
+
+	for _, tag := range serviceTags {
+		found := false
+		for _, existingTag := range tags {
+			if existingTag == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			tags = append(tags, tag)
+		}
+	}
+
+	// Add additional tags, replacing the tags of single-valued keys
+	tags, replaced := MergeTags(tags, opts.AdditionalTags, opts.SingleValuedTagKeys)
+
+	customized["tags"] = tags
+	return customized, replaced
+}
+
+// replaceBuiltinPlaceholders replaces the {service}, {env} and {namespace}
+// placeholders, with or without a modifier ({env:upper})
+func replaceBuiltinPlaceholders(s, service, env, namespace string) string {
+	return ReplacePlaceholders(s, map[string]string{"service": service, "env": env, "namespace": namespace})
+}
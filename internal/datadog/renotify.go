@@ -0,0 +1,153 @@
+package datadog
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RenotifyStatuses are the monitor states renotify_statuses accepts
+var RenotifyStatuses = []string{"alert", "warn", "no data"}
+
+// NotificationPresets are the values notification_preset_name accepts
+var NotificationPresets = []string{"show_all", "hide_query", "hide_handles", "hide_all"}
+
+// RenotifySettings holds the renotification options of a monitor. Nil or empty
+// fields are left untouched when applied.
+type RenotifySettings struct {
+	Interval           *int     // Minutes between renotifications (0 disables renotify)
+	Occurrences        *int     // Number of renotifications to send
+	Statuses           []string // States that trigger renotifications
+	NotificationPreset string   // Content shown in notifications
+}
+
+// RenotifyFromOptions reads the renotification settings from monitor options
+func RenotifyFromOptions(options map[string]interface{}) RenotifySettings {
+	var s RenotifySettings
+	if v, ok := options["renotify_interval"].(float64); ok {
+		interval := int(v)
+		s.Interval = &interval
+	}
+	if v, ok := options["renotify_occurrences"].(float64); ok {
+		occurrences := int(v)
+		s.Occurrences = &occurrences
+	}
+	if statuses, ok := options["renotify_statuses"].([]interface{}); ok {
+		for _, status := range statuses {
+			if str, ok := status.(string); ok {
+				s.Statuses = append(s.Statuses, str)
+			}
+		}
+	}
+	if v, ok := options["notification_preset_name"].(string); ok {
+		s.NotificationPreset = v
+	}
+	return s
+}
+
+// IsEmpty reports whether the settings would not change anything
+func (s RenotifySettings) IsEmpty() bool {
+	return s.Interval == nil && s.Occurrences == nil && len(s.Statuses) == 0 && s.NotificationPreset == ""
+}
+
+// Validate checks values against what the Datadog API accepts
+func (s RenotifySettings) Validate() error {
+	if s.Interval != nil && *s.Interval < 0 {
+		return fmt.Errorf("renotify interval must be >= 0 (got %d)", *s.Interval)
+	}
+	if s.Occurrences != nil && *s.Occurrences < 0 {
+		return fmt.Errorf("renotify occurrences must be >= 0 (got %d)", *s.Occurrences)
+	}
+	for _, status := range s.Statuses {
+		if !containsString(RenotifyStatuses, status) {
+			return fmt.Errorf("invalid renotify status %q (must be one of: %s)", status, strings.Join(RenotifyStatuses, ", "))
+		}
+	}
+	if s.NotificationPreset != "" && !containsString(NotificationPresets, s.NotificationPreset) {
+		return fmt.Errorf("invalid notification preset %q (must be one of: %s)", s.NotificationPreset, strings.Join(NotificationPresets, ", "))
+	}
+	return nil
+}
+
+// String formats the settings for display
+func (s RenotifySettings) String() string {
+	var parts []string
+	if s.Interval != nil {
+		parts = append(parts, fmt.Sprintf("interval=%dm", *s.Interval))
+	}
+	if s.Occurrences != nil {
+		parts = append(parts, fmt.Sprintf("occurrences=%d", *s.Occurrences))
+	}
+	if len(s.Statuses) > 0 {
+		parts = append(parts, fmt.Sprintf("statuses=%s", strings.Join(s.Statuses, ",")))
+	}
+	if s.NotificationPreset != "" {
+		parts = append(parts, fmt.Sprintf("preset=%s", s.NotificationPreset))
+	}
+	if len(parts) == 0 {
+		return "(none)"
+	}
+	return strings.Join(parts, " ")
+}
+
+// ApplyTo sets the renotification options on a monitor and reports whether
+// they changed
+func (s RenotifySettings) ApplyTo(monitor *Monitor) bool {
+	options := make(map[string]interface{})
+	for k, v := range monitor.Options {
+		options[k] = v
+	}
+
+	if s.Interval != nil {
+		options["renotify_interval"] = float64(*s.Interval)
+	}
+	if s.Occurrences != nil {
+		options["renotify_occurrences"] = float64(*s.Occurrences)
+	}
+	if len(s.Statuses) > 0 {
+		statuses := make([]interface{}, len(s.Statuses))
+		for i, status := range s.Statuses {
+			statuses[i] = status
+		}
+		options["renotify_statuses"] = statuses
+	}
+	if s.NotificationPreset != "" {
+		options["notification_preset_name"] = s.NotificationPreset
+	}
+
+	if reflect.DeepEqual(RenotifyFromOptions(options), RenotifyFromOptions(monitor.Options)) {
+		return false
+	}
+	monitor.Options = options
+	return true
+}
+
+// SetMonitorRenotify applies renotification settings to a monitor. The monitor
+// is only updated when the settings actually change; the returned bool reports that.
+func (c *Client) SetMonitorRenotify(monitorID int, settings RenotifySettings) (*Monitor, bool, error) {
+	monitor, err := c.GetMonitor(monitorID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !settings.ApplyTo(monitor) {
+		return monitor, false, nil
+	}
+
+	updatedMonitor, err := c.UpdateMonitor(monitorID, monitor)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return updatedMonitor, true, nil
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AllGroups is the bulk resolve group that resolves every group of a monitor
+const AllGroups = "ALL_GROUPS"
+
+// ResolveResult is the outcome of resolving one group of a monitor
+type ResolveResult struct {
+	ID     int          `json:"id"`
+	Group  string       `json:"group"`
+	Status ResultStatus `json:"status"` // StatusResolved, StatusNotFound or StatusFailed
+	// State is the state of the group (or of the monitor for AllGroups) reported after resolving
+	State string `json:"state,omitempty"`
+	Err   error  `json:"-"`
+}
+
+// MarshalJSON serializes Err as an "error" message
+func (r ResolveResult) MarshalJSON() ([]byte, error) {
+	type plain ResolveResult
+	return json.Marshal(struct {
+		plain
+		Error string `json:"error,omitempty"`
+	}{plain(r), errorString(r.Err)})
+}
+
+// NormalizeResolveGroup validates a group given as comma-separated tags
+// (e.g., "service:foo,pod:bar") and returns it in the API's form, tags sorted
+func NormalizeResolveGroup(group string) (string, error) {
+	if group == AllGroups {
+		return group, nil
+	}
+	parts := strings.Split(group, ",")
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		key, value, ok := strings.Cut(part, ":")
+		switch {
+		case part == "":
+			return "", fmt.Errorf("invalid group %q: empty tag", group)
+		case !ok || key == "" || value == "":
+			return "", fmt.Errorf("invalid group %q: %q is not a key:value tag", group, part)
+		case strings.ContainsAny(part, " *?"):
+			return "", fmt.Errorf("invalid group %q: %q contains spaces or wildcards", group, part)
+		}
+		parts[i] = part
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ","), nil
+}
+
+// resolvedMonitor is a monitor as returned by the bulk resolve endpoint
+type resolvedMonitor struct {
+	ID           int          `json:"id"`
+	OverallState string       `json:"overall_state"`
+	State        MonitorState `json:"state"`
+}
+
+// ResolveMonitor manually resolves groups of a monitor (all groups when none
+// are given), like the "Resolve" button of the UI, and returns one result per
+// group from the API response. Groups are comma-separated tags, e.g.
+// "service:foo,pod:bar"; use NormalizeResolveGroup to validate them.
+func (c *Client) ResolveMonitor(monitorID int, groups []string) ([]ResolveResult, error) {
+	if len(groups) == 0 {
+		groups = []string{AllGroups}
+	}
+	key := strconv.Itoa(monitorID)
+	var resolve []map[string]string
+	for _, group := range groups {
+		resolve = append(resolve, map[string]string{key: group})
+	}
+
+	resp, err := c.makeRequest("POST", "/monitor/bulk_resolve", map[string]interface{}{"resolve": resolve})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, &ErrMonitorNotFound{ID: monitorID}
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to resolve monitor: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var monitors []resolvedMonitor
+	if err := json.NewDecoder(resp.Body).Decode(&monitors); err != nil {
+		return nil, err
+	}
+
+	var resolved *resolvedMonitor
+	for i := range monitors {
+		if monitors[i].ID == monitorID {
+			resolved = &monitors[i]
+		}
+	}
+
+	results := make([]ResolveResult, 0, len(groups))
+	for _, group := range groups {
+		result := ResolveResult{ID: monitorID, Group: group, Status: StatusResolved}
+		switch {
+		case resolved == nil:
+			result.Status = StatusFailed
+			result.Err = fmt.Errorf("monitor %d missing from the resolve response", monitorID)
+		case group == AllGroups:
+			result.State = resolved.OverallState
+		default:
+			state, ok := resolved.State.Groups[group]
+			if !ok && resolved.State.Groups != nil {
+				result.Status = StatusNotFound
+				result.Err = fmt.Errorf("monitor %d has no group %s", monitorID, group)
+			}
+			result.State = state.Status
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,173 @@
+package datadog
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeResolveGroup(t *testing.T) {
+	for _, tc := range []struct {
+		group string
+		want  string
+		err   string
+	}{
+		{"service:foo", "service:foo", ""},
+		// Tags are sorted and trimmed, values may contain colons
+		{"service:foo, pod:bar", "pod:bar,service:foo", ""},
+		{"url:http://x,env:prd", "env:prd,url:http://x", ""},
+		{AllGroups, AllGroups, ""},
+		{"", "", "empty tag"},
+		{"service:foo,,pod:bar", "", "empty tag"},
+		{"service:foo,", "", "empty tag"},
+		{"service", "", `"service" is not a key:value tag`},
+		{":foo", "", `":foo" is not a key:value tag`},
+		{"service:", "", `"service:" is not a key:value tag`},
+		{"all_groups", "", `"all_groups" is not a key:value tag`},
+		{"service:foo*", "", "contains spaces or wildcards"},
+		{"pod:checkout-?", "", "contains spaces or wildcards"},
+		{"service:my app", "", "contains spaces or wildcards"},
+	} {
+		got, err := NormalizeResolveGroup(tc.group)
+		if tc.err == "" {
+			if err != nil || got != tc.want {
+				t.Errorf("NormalizeResolveGroup(%q) = %q, %v, want %q", tc.group, got, err, tc.want)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("NormalizeResolveGroup(%q) = %q, %v, want an error with %q", tc.group, got, err, tc.err)
+		}
+	}
+}
+
+// resolveServer is a bulk resolve endpoint returning response, recording the
+// request bodies
+func resolveServer(t *testing.T, status int, response string, bodies *[]string) *Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/monitor/bulk_resolve" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		*bodies = append(*bodies, string(body))
+		w.WriteHeader(status)
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(srv.Close)
+	client, err := NewClientWithOptions(WithAPIKey("api-key"), WithAppKey("app-key"), WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestResolveMonitor(t *testing.T) {
+	const response = `[{"id": 1000, "overall_state": "Alert", "state": {"groups": {
+		"pod:a,service:foo": {"status": "OK"},
+		"pod:b,service:foo": {"status": "Alert"}
+	}}}]`
+	for _, tc := range []struct {
+		name     string
+		response string
+		groups   []string
+		request  string
+		want     []ResolveResult
+		errs     []string
+	}{
+		{
+			name:     "all groups",
+			response: `[{"id": 1000, "overall_state": "OK"}]`,
+			request:  `{"resolve":[{"1000":"ALL_GROUPS"}]}`,
+			want:     []ResolveResult{{ID: 1000, Group: AllGroups, Status: StatusResolved, State: "OK"}},
+		},
+		{
+			// The monitor keeps alerting on the groups left
+			name:     "one group",
+			response: response,
+			groups:   []string{"pod:a,service:foo"},
+			request:  `{"resolve":[{"1000":"pod:a,service:foo"}]}`,
+			want:     []ResolveResult{{ID: 1000, Group: "pod:a,service:foo", Status: StatusResolved, State: "OK"}},
+		},
+		{
+			name:     "unknown group",
+			response: response,
+			groups:   []string{"pod:a,service:foo", "pod:c,service:foo"},
+			request:  `{"resolve":[{"1000":"pod:a,service:foo"},{"1000":"pod:c,service:foo"}]}`,
+			want: []ResolveResult{
+				{ID: 1000, Group: "pod:a,service:foo", Status: StatusResolved, State: "OK"},
+				{ID: 1000, Group: "pod:c,service:foo", Status: StatusNotFound},
+			},
+			errs: []string{"", "monitor 1000 has no group pod:c,service:foo"},
+		},
+		{
+			// Without group states in the response a group can't be checked
+			name:     "no group states",
+			response: `[{"id": 1000, "overall_state": "Alert"}]`,
+			groups:   []string{"pod:c,service:foo"},
+			request:  `{"resolve":[{"1000":"pod:c,service:foo"}]}`,
+			want:     []ResolveResult{{ID: 1000, Group: "pod:c,service:foo", Status: StatusResolved}},
+		},
+		{
+			name:     "monitor missing from the response",
+			response: `[{"id": 1001, "overall_state": "OK"}]`,
+			request:  `{"resolve":[{"1000":"ALL_GROUPS"}]}`,
+			want:     []ResolveResult{{ID: 1000, Group: AllGroups, Status: StatusFailed}},
+			errs:     []string{"monitor 1000 missing from the resolve response"},
+		},
+	} {
+		var bodies []string
+		client := resolveServer(t, http.StatusOK, tc.response, &bodies)
+		results, err := client.ResolveMonitor(1000, tc.groups)
+		if err != nil {
+			t.Errorf("%s: ResolveMonitor: %v", tc.name, err)
+			continue
+		}
+		if len(bodies) != 1 || bodies[0] != tc.request {
+			t.Errorf("%s: request bodies %q, want %q", tc.name, bodies, tc.request)
+		}
+		errs := make([]string, len(results))
+		for i := range results {
+			errs[i] = errorString(results[i].Err)
+			results[i].Err = nil
+		}
+		if !reflect.DeepEqual(results, tc.want) {
+			t.Errorf("%s: results %+v, want %+v", tc.name, results, tc.want)
+		}
+		if tc.errs == nil {
+			tc.errs = make([]string, len(tc.want))
+		}
+		if !reflect.DeepEqual(errs, tc.errs) {
+			t.Errorf("%s: result errors %q, want %q", tc.name, errs, tc.errs)
+		}
+	}
+}
+
+func TestResolveMonitorErrors(t *testing.T) {
+	var bodies []string
+	client := resolveServer(t, http.StatusNotFound, `{"errors": ["Monitor not found"]}`, &bodies)
+	var notFound *ErrMonitorNotFound
+	if _, err := client.ResolveMonitor(1000, nil); !errors.As(err, &notFound) || notFound.ID != 1000 {
+		t.Errorf("ResolveMonitor of a missing monitor = %v, want ErrMonitorNotFound", err)
+	}
+
+	client = resolveServer(t, http.StatusBadRequest, `{"errors": ["invalid group"]}`, &bodies)
+	if _, err := client.ResolveMonitor(1000, []string{"pod:a"}); err == nil || !strings.Contains(err.Error(), "status 400") || !strings.Contains(err.Error(), "invalid group") {
+		t.Errorf("ResolveMonitor = %v, want the API error", err)
+	}
+}
+
+func TestResolveResultJSON(t *testing.T) {
+	data, err := json.Marshal(ResolveResult{ID: 1000, Group: "pod:c", Status: StatusNotFound, Err: errors.New("monitor 1000 has no group pod:c")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"id":1000,"group":"pod:c","status":"not_found","error":"monitor 1000 has no group pod:c"}`; string(data) != want {
+		t.Errorf("JSON %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,160 @@
+package datadog
+
+import "encoding/json"
+
+// ResultStatus is the outcome of an operation on one monitor
+type ResultStatus string
+
+// Result statuses
+const (
+	StatusCreated  ResultStatus = "created"
+	StatusUpdated  ResultStatus = "updated"
+	StatusDeleted  ResultStatus = "deleted"
+	StatusNotFound ResultStatus = "not_found"
+	StatusFailed   ResultStatus = "failed"
+	// StatusAdopted is an update of a monitor that had no managed-by tag, or
+	// that a template took over with adopt_monitor_id
+	StatusAdopted ResultStatus = "adopted"
+	// StatusConflict is a name match with an unmanaged monitor that was left alone
+	StatusConflict ResultStatus = "conflict"
+	// StatusResolved is a monitor group manually resolved
+	StatusResolved ResultStatus = "resolved"
+	// StatusSkipped is a template that was not applied (disabled, --only or --skip)
+	StatusSkipped ResultStatus = "skipped"
+	// StatusUnchanged is a monitor already up to date, so it wasn't updated:
+	// the apply state file showed it (and the API wasn't called), the live
+	// monitor matched the template but for server-side defaults (DiffDrift),
+	// or it already had (or lacked) the tags to add (or remove)
+	StatusUnchanged ResultStatus = "unchanged"
+	// StatusExisting is a monitor an earlier apply created (found by its
+	// fingerprint tag), so it wasn't created again
+	StatusExisting ResultStatus = "existing"
+)
+
+// ApplyResult is the outcome of applying one template
+type ApplyResult struct {
+	TemplateName string       `json:"template_name"`
+	ID           int          `json:"id"`
+	Name         string       `json:"name"`
+	Status       ResultStatus `json:"status"` // StatusCreated, StatusExisting, StatusUpdated, StatusUnchanged, StatusAdopted, StatusConflict, StatusSkipped or StatusFailed (adopt_monitor_id, ambiguous query match)
+	// Creator is the creator of the existing monitor, for conflicts
+	Creator *Creator `json:"creator,omitempty"`
+	// SkipReason tells why a template was skipped
+	SkipReason string `json:"skip_reason,omitempty"`
+	// NameOverflow describes how a name over MaxMonitorNameLength was shortened
+	NameOverflow string `json:"name_overflow,omitempty"`
+	// K8sDefaults are the options injected by the Kubernetes defaults, if any
+	K8sDefaults []string `json:"k8s_defaults,omitempty"`
+	// NotifyByDropped are the options.notify_by values --fix-notify-by
+	// dropped because the query doesn't group by them
+	NotifyByDropped []string `json:"notify_by_dropped,omitempty"`
+	// TagsReplaced are the template tags --tag values of single-valued keys
+	// replaced
+	TagsReplaced []TagReplacement `json:"tags_replaced,omitempty"`
+	// ScopeWarnings lists env/service values in the query scope that differ from the applied ones
+	ScopeWarnings []ScopeMismatch `json:"scope_warnings,omitempty"`
+	// Muted is set on monitors created muted (ApplyOptions.CreateMuted)
+	Muted bool `json:"muted,omitempty"`
+	// AdoptMonitorID is the template's adopt_monitor_id, if any
+	AdoptMonitorID int `json:"adopt_monitor_id,omitempty"`
+	// AdoptRedundant is set when adopt_monitor_id points at a monitor the
+	// template already manages, so the key can be removed
+	AdoptRedundant bool `json:"adopt_redundant,omitempty"`
+	// RenamedFrom is the name a monitor matched by query (ApplyOptions.MatchByQuery)
+	// had before the update renamed it back to the template's
+	RenamedFrom string `json:"renamed_from,omitempty"`
+	Err         error  `json:"-"`
+}
+
+// DeleteResult is the outcome of deleting one monitor
+type DeleteResult struct {
+	ID     int          `json:"id"`
+	Name   string       `json:"name"`
+	Status ResultStatus `json:"status"` // StatusDeleted, StatusNotFound or StatusFailed
+	Err    error        `json:"-"`
+}
+
+// TagUpdateResult is the outcome of changing the tags of one monitor
+type TagUpdateResult struct {
+	ID     int          `json:"id"`
+	Name   string       `json:"name"`
+	Status ResultStatus `json:"status"` // StatusUpdated, StatusUnchanged, StatusNotFound or StatusFailed
+	Tags   []string     `json:"tags,omitempty"`
+	// PreviousTags are the tags before the change, for rollback files
+	PreviousTags []string `json:"previous_tags,omitempty"`
+	Err          error    `json:"-"`
+}
+
+// TemplateMonitorCheck tells whether the monitor rendered from a template exists
+type TemplateMonitorCheck struct {
+	TemplateName string `json:"template_name"`
+	MonitorName  string `json:"monitor_name"`
+	MonitorID    int    `json:"monitor_id,omitempty"`
+	Exists       bool   `json:"exists"`
+	State        string `json:"state,omitempty"`
+}
+
+// errorString returns the error message, or an empty string for nil
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// statusForError returns StatusNotFound or StatusFailed for a failed operation
+func statusForError(err error) ResultStatus {
+	if IsMonitorNotFound(err) {
+		return StatusNotFound
+	}
+	return StatusFailed
+}
+
+// MarshalJSON serializes Err as an "error" message
+func (r ApplyResult) MarshalJSON() ([]byte, error) {
+	type plain ApplyResult
+	return json.Marshal(struct {
+		plain
+		Error string `json:"error,omitempty"`
+	}{plain(r), errorString(r.Err)})
+}
+
+// MarshalJSON serializes Err as an "error" message
+func (r DeleteResult) MarshalJSON() ([]byte, error) {
+	type plain DeleteResult
+	return json.Marshal(struct {
+		plain
+		Error string `json:"error,omitempty"`
+	}{plain(r), errorString(r.Err)})
+}
+
+// MarshalJSON serializes Err as an "error" message
+func (r TagUpdateResult) MarshalJSON() ([]byte, error) {
+	type plain TagUpdateResult
+	return json.Marshal(struct {
+		plain
+		Error string `json:"error,omitempty"`
+	}{plain(r), errorString(r.Err)})
+}
+
+// NewDeleteResult builds the result of deleting a monitor
+func NewDeleteResult(monitor Monitor, err error) DeleteResult {
+	result := DeleteResult{ID: monitor.ID, Name: monitor.Name, Status: StatusDeleted}
+	if err != nil {
+		result.Status = statusForError(err)
+		result.Err = err
+	}
+	return result
+}
+
+// NewTagUpdateResult builds the result of changing the tags of a monitor;
+// changed is false when the monitor already had the tags and wasn't updated
+func NewTagUpdateResult(monitor Monitor, updated *Monitor, changed bool, err error) TagUpdateResult {
+	if err != nil {
+		return TagUpdateResult{ID: monitor.ID, Name: monitor.Name, Status: statusForError(err), Err: err}
+	}
+	if !changed {
+		return TagUpdateResult{ID: updated.ID, Name: updated.Name, Status: StatusUnchanged, Tags: updated.Tags}
+	}
+	return TagUpdateResult{ID: updated.ID, Name: updated.Name, Status: StatusUpdated, Tags: updated.Tags, PreviousTags: monitor.Tags}
+}
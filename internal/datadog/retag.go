@@ -0,0 +1,152 @@
+package datadog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Retag renames a key:value tag on monitors, and with IncludeQueries also its
+// occurrences in queries and messages (e.g. avg:...{env:hml} after an
+// hml -> stg rename)
+type Retag struct {
+	From           string
+	To             string
+	IncludeQueries bool
+}
+
+// NewRetag checks that from and to are distinct key:value tags
+func NewRetag(from, to string, includeQueries bool) (Retag, error) {
+	for _, tag := range []string{from, to} {
+		key, value, ok := strings.Cut(tag, ":")
+		if !ok || key == "" || value == "" {
+			return Retag{}, fmt.Errorf("invalid tag %q (expected key:value)", tag)
+		}
+	}
+	if from == to {
+		return Retag{}, fmt.Errorf("--from and --to are the same tag")
+	}
+	return Retag{From: from, To: to, IncludeQueries: includeQueries}, nil
+}
+
+// RetagChange is the change Retag makes to a monitor
+type RetagChange struct {
+	Monitor Monitor // the monitor with the change applied
+	Tags    bool    // the tags changed
+	Query   bool    // the query changed
+	Message bool    // the message changed
+}
+
+// Changed reports whether anything changed
+func (ch RetagChange) Changed() bool {
+	return ch.Tags || ch.Query || ch.Message
+}
+
+// Apply returns the change the retag makes to a monitor. The To tag replaces
+// the From tag in place (once, if the monitor already had To).
+func (r Retag) Apply(monitor Monitor) RetagChange {
+	change := RetagChange{Monitor: monitor}
+
+	var tags []string
+	hasTo := false
+	for _, tag := range monitor.Tags {
+		if tag == r.From {
+			change.Tags = true
+			tag = r.To
+		}
+		if tag == r.To {
+			if hasTo {
+				continue
+			}
+			hasTo = true
+		}
+		tags = append(tags, tag)
+	}
+	if change.Tags {
+		change.Monitor.Tags = tags
+	}
+
+	if r.IncludeQueries {
+		if query := ReplaceTag(monitor.Query, r.From, r.To); query != monitor.Query {
+			change.Monitor.Query = query
+			change.Query = true
+		}
+		if message := ReplaceTag(monitor.Message, r.From, r.To); message != monitor.Message {
+			change.Monitor.Message = message
+			change.Message = true
+		}
+	}
+	return change
+}
+
+// isTagChar reports whether c can be part of a tag; an occurrence of a tag in
+// a query only counts when the characters around it can't be
+func isTagChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '-' || c == '.' || c == ':' || c == '/'
+}
+
+// endsTag reports whether a tag occurrence ending at end in s ends there: the
+// next character can't be part of a tag, or it is a full stop ending a
+// sentence ("... on env:hml.")
+func endsTag(s string, end int) bool {
+	if end == len(s) || !isTagChar(s[end]) {
+		return true
+	}
+	return s[end] == '.' && (end+1 == len(s) || strings.ContainsRune(" \t\r\n", rune(s[end+1])))
+}
+
+// ReplaceTag replaces the whole-tag occurrences of from in s with to: env:hml
+// in {env:hml,service:a}, !env:hml or "on env:hml.", but not in env:hml2,
+// env:hml.eu or myenv:hml
+func ReplaceTag(s, from, to string) string {
+	var b strings.Builder
+	start := 0
+	for {
+		i := strings.Index(s[start:], from)
+		if i < 0 {
+			b.WriteString(s[start:])
+			return b.String()
+		}
+		i += start
+		end := i + len(from)
+		b.WriteString(s[start:i])
+		if (i == 0 || !isTagChar(s[i-1])) && endsTag(s, end) {
+			b.WriteString(to)
+		} else {
+			b.WriteString(from)
+		}
+		start = end
+	}
+}
+
+// RetagMonitor applies a retag to a monitor. A rewritten query is checked with
+// the validate endpoint first, and the monitor is left alone when it fails.
+func (c *Client) RetagMonitor(monitorID int, retag Retag) (*Monitor, RetagChange, error) {
+	monitor, err := c.GetMonitor(monitorID)
+	if err != nil {
+		return nil, RetagChange{}, err
+	}
+
+	change := retag.Apply(*monitor)
+	if !change.Changed() {
+		return monitor, change, nil
+	}
+	if change.Query {
+		if err := c.ValidateMonitor(&change.Monitor); err != nil {
+			return nil, change, err
+		}
+	}
+
+	body := map[string]interface{}{"tags": nonNilTags(change.Monitor.Tags)}
+	if change.Query {
+		body["query"] = change.Monitor.Query
+	}
+	if change.Message {
+		body["message"] = change.Monitor.Message
+	}
+	updated, err := c.putMonitor(monitorID, body)
+	if err != nil {
+		return nil, change, err
+	}
+	return updated, change, nil
+}
@@ -0,0 +1,112 @@
+package datadog
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestReplaceTag(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		s    string
+		want string
+	}{
+		{"scope", "avg(last_5m):avg:cpu{env:hml,service:a} > 1", "avg(last_5m):avg:cpu{env:stg,service:a} > 1"},
+		{"last in scope", "avg:cpu{service:a,env:hml}", "avg:cpu{service:a,env:stg}"},
+		{"negated", "avg:cpu{!env:hml}", "avg:cpu{!env:stg}"},
+		{"every occurrence", "a{env:hml} / b{env:hml}", "a{env:stg} / b{env:stg}"},
+		{"whole string", "env:hml", "env:stg"},
+		{"longer value", "avg:cpu{env:hml2}", "avg:cpu{env:hml2}"},
+		{"longer key", "avg:cpu{myenv:hml}", "avg:cpu{myenv:hml}"},
+		{"value with a suffix", "avg:cpu{env:hml-eu}", "avg:cpu{env:hml-eu}"},
+		{"value with a dotted suffix", "avg:cpu{env:hml.eu}", "avg:cpu{env:hml.eu}"},
+		{"path suffix", "avg:cpu{env:hml/a}", "avg:cpu{env:hml/a}"},
+		{"key suffix", "avg:cpu{env:hml:x}", "avg:cpu{env:hml:x}"},
+		{"dotted prefix", "avg:cpu{kube.env:hml}", "avg:cpu{kube.env:hml}"},
+		{"end of sentence", "Latency is high on env:hml.", "Latency is high on env:stg."},
+		{"full stop mid-message", "Down on env:hml. Check the pods.", "Down on env:stg. Check the pods."},
+		{"full stop before a newline", "Down on env:hml.\n@slack-ops", "Down on env:stg.\n@slack-ops"},
+		{"comma", "Down on env:hml, service:a", "Down on env:stg, service:a"},
+		{"parentheses", "Down (env:hml)", "Down (env:stg)"},
+		{"quoted", `See "env:hml"`, `See "env:stg"`},
+		{"template block", "{{#is_alert}}env:hml is down{{/is_alert}}", "{{#is_alert}}env:stg is down{{/is_alert}}"},
+		{"template variable", "{{env.name}} env:hml {{host.name}}", "{{env.name}} env:stg {{host.name}}"},
+		{"template match", `{{#is_match "env" "hml"}}paged{{/is_match}}`, `{{#is_match "env" "hml"}}paged{{/is_match}}`},
+		{"other value", "avg:cpu{env:prd}", "avg:cpu{env:prd}"},
+		{"empty", "", ""},
+	} {
+		if got := ReplaceTag(tc.s, "env:hml", "env:stg"); got != tc.want {
+			t.Errorf("%s: ReplaceTag(%q) = %q, want %q", tc.name, tc.s, got, tc.want)
+		}
+	}
+}
+
+func TestRetagApply(t *testing.T) {
+	monitor := Monitor{
+		Tags:    []string{"env:hml", "service:a", "env:stg"},
+		Query:   "avg(last_5m):avg:cpu{env:hml} > 1",
+		Message: "CPU high on env:hml.",
+	}
+	change := Retag{From: "env:hml", To: "env:stg"}.Apply(monitor)
+	// To replaces From in place, once
+	if want := []string{"env:stg", "service:a"}; !reflect.DeepEqual(change.Monitor.Tags, want) || !change.Tags {
+		t.Errorf("tags = %v (changed %v), want %v", change.Monitor.Tags, change.Tags, want)
+	}
+	if change.Query || change.Message || change.Monitor.Query != monitor.Query {
+		t.Errorf("queries changed without IncludeQueries: %+v", change)
+	}
+
+	change = Retag{From: "env:hml", To: "env:stg", IncludeQueries: true}.Apply(monitor)
+	if change.Monitor.Query != "avg(last_5m):avg:cpu{env:stg} > 1" || change.Monitor.Message != "CPU high on env:stg." || !change.Query || !change.Message {
+		t.Errorf("change with IncludeQueries = %+v", change)
+	}
+}
+
+func TestRetagMonitorValidationFailure(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/monitor/1000":
+			json.NewEncoder(w).Encode(Monitor{ID: 1000, Name: "CPU", Type: "metric alert", Query: "avg(last_5m):avg:cpu{env:hml} > 1", Tags: []string{"env:hml"}})
+		case r.Method == "POST" && r.URL.Path == "/monitor/validate":
+			body, _ := io.ReadAll(r.Body)
+			if !strings.Contains(string(body), "{env:stg}") {
+				t.Errorf("validated %s, want the rewritten query", body)
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors": ["no data for env:stg"]}`))
+		default:
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+	client, err := NewClientWithOptions(WithAPIKey("api-key"), WithAppKey("app-key"), WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, change, err := client.RetagMonitor(1000, Retag{From: "env:hml", To: "env:stg", IncludeQueries: true})
+	if err == nil || !strings.Contains(err.Error(), "no data for env:stg") {
+		t.Fatalf("RetagMonitor = %v, want the validation error", err)
+	}
+	if !change.Query {
+		t.Errorf("change = %+v, want the query change", change)
+	}
+	// The monitor is left alone: no PUT after the failed validation
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"GET /monitor/1000", "POST /monitor/validate"}; !reflect.DeepEqual(requests, want) {
+		t.Errorf("requests %v, want %v", requests, want)
+	}
+}
@@ -0,0 +1,131 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// TagRollbackVersion is the version of the tag rollback file format
+const TagRollbackVersion = 1
+
+// TagRollback records the tag sets of monitors before and after a bulk tag
+// change, so the change can be reverted
+type TagRollback struct {
+	Version   int               `json:"version"`
+	CreatedAt time.Time         `json:"created_at"`
+	Operation string            `json:"operation"` // e.g. "add-tags env:prd"
+	Monitors  []TagRollbackItem `json:"monitors"`
+}
+
+// TagRollbackItem is the tag set of one monitor before and right after the change
+type TagRollbackItem struct {
+	ID     int      `json:"id"`
+	Name   string   `json:"name"`
+	Before []string `json:"before"`
+	After  []string `json:"after"`
+}
+
+// TagsChangedError is returned when rolling back a monitor whose tags no longer
+// match the post-change snapshot, i.e. they were changed again since
+type TagsChangedError struct {
+	ID       int
+	Expected []string
+	Current  []string
+}
+
+// Error implements the error interface
+func (e *TagsChangedError) Error() string {
+	return fmt.Sprintf("tags of monitor %d changed since the recorded update (use --force to restore anyway)", e.ID)
+}
+
+// NewTagRollback builds a rollback record from the results of a tag update.
+// Only monitors that were updated are recorded.
+func NewTagRollback(operation string, results []TagUpdateResult, now time.Time) *TagRollback {
+	rollback := &TagRollback{Version: TagRollbackVersion, CreatedAt: now, Operation: operation, Monitors: []TagRollbackItem{}}
+	for _, result := range results {
+		if result.Status != StatusUpdated {
+			continue
+		}
+		rollback.Monitors = append(rollback.Monitors, TagRollbackItem{
+			ID:     result.ID,
+			Name:   result.Name,
+			Before: nonNilTags(result.PreviousTags),
+			After:  nonNilTags(result.Tags),
+		})
+	}
+	return rollback
+}
+
+// LoadTagRollback reads a tag rollback file
+func LoadTagRollback(path string) (*TagRollback, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rollback file %s: %v", path, err)
+	}
+
+	var rollback TagRollback
+	if err := json.Unmarshal(data, &rollback); err != nil {
+		return nil, fmt.Errorf("invalid rollback file %s: %v", path, err)
+	}
+	if rollback.Version != TagRollbackVersion {
+		return nil, fmt.Errorf("rollback file %s has version %d, expected %d", path, rollback.Version, TagRollbackVersion)
+	}
+	return &rollback, nil
+}
+
+// Save writes the rollback file atomically
+func (r *TagRollback) Save(path string) error {
+	return writeJSONFile(path, r, ".ddmm-rollback-*")
+}
+
+// RollbackTags restores the tags a monitor had before the recorded change.
+// Unless force, a monitor whose tags differ from the post-change snapshot is
+// left alone and a *TagsChangedError returned. restored is false when the
+// monitor already has its previous tags.
+func (c *Client) RollbackTags(item TagRollbackItem, force bool) (monitor *Monitor, restored bool, err error) {
+	monitor, err = c.GetMonitor(item.ID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if SameTags(monitor.Tags, item.Before) {
+		return monitor, false, nil
+	}
+	if !force && !SameTags(monitor.Tags, item.After) {
+		return monitor, false, &TagsChangedError{ID: item.ID, Expected: item.After, Current: monitor.Tags}
+	}
+
+	updated, err := c.SetMonitorTags(item.ID, item.Before)
+	if err != nil {
+		return nil, false, err
+	}
+	return updated, true, nil
+}
+
+// SameTags reports whether two tag lists hold the same tags, in any order
+func SameTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// nonNilTags returns tags, or an empty list for nil so it serializes as []
+func nonNilTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
@@ -0,0 +1,150 @@
+package datadog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTagRollbackRoundTrip(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	results := []TagUpdateResult{
+		NewTagUpdateResult(Monitor{ID: 1, Name: "cpu", Tags: []string{"env:prd"}}, &Monitor{ID: 1, Name: "cpu", Tags: []string{"env:prd", "team:sre"}}, true, nil),
+		// Only updated monitors are recorded
+		NewTagUpdateResult(Monitor{ID: 2, Name: "disk", Tags: []string{"team:sre"}}, &Monitor{ID: 2, Name: "disk", Tags: []string{"team:sre"}}, false, nil),
+		NewTagUpdateResult(Monitor{ID: 3, Name: "mem"}, nil, true, errors.New("API error 500")),
+		// A monitor without tags is recorded with [] rather than null
+		NewTagUpdateResult(Monitor{ID: 4, Name: "net"}, &Monitor{ID: 4, Name: "net", Tags: []string{"team:sre"}}, true, nil),
+	}
+	rollback := NewTagRollback("add-tags team:sre", results, created)
+
+	path := filepath.Join(t.TempDir(), "rollback.json")
+	if err := rollback.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"before": []`) {
+		t.Errorf("untagged monitor not recorded with []:\n%s", data)
+	}
+
+	loaded, err := LoadTagRollback(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Operation != "add-tags team:sre" || !loaded.CreatedAt.Equal(created) || loaded.Version != TagRollbackVersion {
+		t.Errorf("loaded %+v", loaded)
+	}
+	var got []string
+	for _, item := range loaded.Monitors {
+		got = append(got, fmt.Sprintf("%d %s %v -> %v", item.ID, item.Name, item.Before, item.After))
+	}
+	if want := "1 cpu [env:prd] -> [env:prd team:sre]; 4 net [] -> [team:sre]"; strings.Join(got, "; ") != want {
+		t.Errorf("monitors %q, want %q", strings.Join(got, "; "), want)
+	}
+}
+
+func TestLoadTagRollbackErrors(t *testing.T) {
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		content string
+		want    string
+	}{
+		{`{"version": 2, "monitors": []}`, "has version 2, expected 1"},
+		{`{"monitors": []}`, "has version 0, expected 1"},
+		{`[`, "invalid rollback file"},
+	} {
+		path := filepath.Join(dir, "rollback.json")
+		if err := os.WriteFile(path, []byte(tc.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadTagRollback(path); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("LoadTagRollback(%s) = %v, want %q", tc.content, err, tc.want)
+		}
+	}
+	if _, err := LoadTagRollback(filepath.Join(dir, "missing.json")); err == nil || !strings.Contains(err.Error(), "failed to read rollback file") {
+		t.Errorf("LoadTagRollback of a missing file = %v", err)
+	}
+}
+
+func TestRollbackTags(t *testing.T) {
+	item := TagRollbackItem{ID: 1, Name: "cpu", Before: []string{"env:prd"}, After: []string{"env:prd", "team:sre"}}
+	for _, tc := range []struct {
+		name     string
+		current  []string
+		force    bool
+		restored bool
+		changed  bool
+	}{
+		{"after the change", []string{"team:sre", "env:prd"}, false, true, false},
+		{"already restored", []string{"env:prd"}, false, false, false},
+		{"changed since", []string{"env:prd", "team:web"}, false, false, true},
+		{"changed since, forced", []string{"env:prd", "team:web"}, true, true, false},
+	} {
+		var sent []string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "PUT" {
+				var body struct {
+					Tags []string `json:"tags"`
+				}
+				data, _ := io.ReadAll(r.Body)
+				json.Unmarshal(data, &body)
+				sent = body.Tags
+				json.NewEncoder(w).Encode(Monitor{ID: 1, Name: "cpu", Tags: body.Tags})
+				return
+			}
+			json.NewEncoder(w).Encode(Monitor{ID: 1, Name: "cpu", Tags: tc.current})
+		}))
+		client, err := NewClientWithOptions(WithAPIKey("api-key"), WithAppKey("app-key"), WithBaseURL(srv.URL))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		monitor, restored, err := client.RollbackTags(item, tc.force)
+		srv.Close()
+		var changedErr *TagsChangedError
+		if got := errors.As(err, &changedErr); got != tc.changed {
+			t.Errorf("%s: error %v", tc.name, err)
+			continue
+		}
+		if tc.changed {
+			if strings.Join(changedErr.Current, ",") != "env:prd,team:web" || sent != nil {
+				t.Errorf("%s: %+v, sent %v", tc.name, changedErr, sent)
+			}
+			continue
+		}
+		if restored != tc.restored || !SameTags(monitor.Tags, item.Before) {
+			t.Errorf("%s: restored %v, tags %v", tc.name, restored, monitor.Tags)
+		}
+		if tc.restored != (sent != nil) {
+			t.Errorf("%s: sent %v", tc.name, sent)
+		}
+	}
+}
+
+func TestSameTags(t *testing.T) {
+	for _, tc := range []struct {
+		a, b []string
+		want bool
+	}{
+		{nil, []string{}, true},
+		{[]string{"a", "b"}, []string{"b", "a"}, true},
+		{[]string{"a"}, []string{"a", "a"}, false},
+		{[]string{"a", "a", "b"}, []string{"a", "b", "b"}, false},
+		{[]string{"a"}, []string{"A"}, false},
+	} {
+		if got := SameTags(tc.a, tc.b); got != tc.want {
+			t.Errorf("SameTags(%q, %q) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package datadog
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// CategoryTagKey is the tag key that files a monitor under a runbook category
+const CategoryTagKey = "category"
+
+// OtherCategory is the runbook category of monitors without a category tag
+// or a templated name
+const OtherCategory = "Other"
+
+// runbookExcerptLines and runbookExcerptLength bound the message excerpt of a
+// runbook entry
+const (
+	runbookExcerptLines  = 6
+	runbookExcerptLength = 500
+)
+
+// runbookThresholdKeys are the options.thresholds keys listed in a runbook,
+// most severe first
+var runbookThresholdKeys = []string{"critical", "critical_recovery", "warning", "warning_recovery", "ok", "unknown"}
+
+// Runbook is the document of the monitors of a service handed to the team
+// that runs it: the monitors grouped by category
+type Runbook struct {
+	Title      string
+	Generated  time.Time
+	Total      int
+	Categories []RunbookCategory
+}
+
+// RunbookCategory is a group of monitors of a runbook
+type RunbookCategory struct {
+	Name string
+	// Anchor is the Markdown anchor of the category's heading in the
+	// default layout
+	Anchor   string
+	Monitors []RunbookEntry
+}
+
+// RunbookEntry is a monitor of a runbook, with what the document shows of it
+type RunbookEntry struct {
+	Monitor
+	// URL is the monitor's page in the Datadog app
+	URL string
+	// Anchor is the Markdown anchor of the monitor's heading in the default
+	// layout
+	Anchor     string
+	Thresholds []RunbookThreshold
+	// Excerpt is the start of the message, up to a few lines
+	Excerpt string
+}
+
+// RunbookThreshold is a threshold of a runbook entry
+type RunbookThreshold struct {
+	Name  string
+	Value string
+}
+
+// MonitorCategory returns the runbook category of a monitor: its category
+// tag, else the part of its name after the last " - ", which is how the
+// templates name monitors ("Monitor myapp - CPU throttling" is "CPU
+// throttling"), else OtherCategory
+func MonitorCategory(monitor Monitor) string {
+	for _, tag := range monitor.Tags {
+		if value, ok := strings.CutPrefix(tag, CategoryTagKey+":"); ok && value != "" {
+			return value
+		}
+	}
+	if i := strings.LastIndex(monitor.Name, " - "); i >= 0 {
+		if category := strings.TrimSpace(monitor.Name[i+3:]); category != "" {
+			return category
+		}
+	}
+	return OtherCategory
+}
+
+// BuildRunbook groups monitors by MonitorCategory into a runbook, categories
+// sorted by name (OtherCategory last) and monitors by name. appURL is the
+// Datadog app URL the monitor links are built from.
+func BuildRunbook(title string, monitors []Monitor, appURL string, now time.Time) Runbook {
+	byCategory := make(map[string][]Monitor)
+	for _, monitor := range monitors {
+		category := MonitorCategory(monitor)
+		byCategory[category] = append(byCategory[category], monitor)
+	}
+	names := make([]string, 0, len(byCategory))
+	for name := range byCategory {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if (names[i] == OtherCategory) != (names[j] == OtherCategory) {
+			return names[j] == OtherCategory
+		}
+		return names[i] < names[j]
+	})
+
+	// Anchors are given in the order of the default layout's headings, so
+	// repeated headings get the same -1, -2, ... suffixes GitHub gives them
+	anchors := make(map[string]int)
+	markdownAnchor(title, anchors)
+	markdownAnchor("Contents", anchors)
+
+	runbook := Runbook{Title: title, Generated: now, Total: len(monitors)}
+	for _, name := range names {
+		category := RunbookCategory{Name: name, Anchor: markdownAnchor(name, anchors)}
+		categoryMonitors := byCategory[name]
+		sort.SliceStable(categoryMonitors, func(i, j int) bool {
+			if categoryMonitors[i].Name != categoryMonitors[j].Name {
+				return categoryMonitors[i].Name < categoryMonitors[j].Name
+			}
+			return categoryMonitors[i].ID < categoryMonitors[j].ID
+		})
+		for _, monitor := range categoryMonitors {
+			entry := RunbookEntry{
+				Monitor:    monitor,
+				Anchor:     markdownAnchor(monitor.Name, anchors),
+				Thresholds: runbookThresholds(monitor),
+				Excerpt:    messageExcerpt(monitor.Message),
+			}
+			if appURL != "" {
+				entry.URL = fmt.Sprintf("%s/monitors/%d", appURL, monitor.ID)
+			}
+			category.Monitors = append(category.Monitors, entry)
+		}
+		runbook.Categories = append(runbook.Categories, category)
+	}
+	return runbook
+}
+
+// runbookThresholds returns the thresholds of a monitor, most severe first
+func runbookThresholds(monitor Monitor) []RunbookThreshold {
+	thresholds, _ := monitor.Options["thresholds"].(map[string]interface{})
+	var list []RunbookThreshold
+	for _, key := range runbookThresholdKeys {
+		switch value := thresholds[key].(type) {
+		case float64:
+			list = append(list, RunbookThreshold{Name: key, Value: formatThreshold(value)})
+		case string:
+			list = append(list, RunbookThreshold{Name: key, Value: value})
+		}
+	}
+	return list
+}
+
+// messageExcerpt returns the first lines of a message, without blank lines,
+// cut at runbookExcerptLines lines or runbookExcerptLength characters with "…"
+func messageExcerpt(message string) string {
+	var lines []string
+	length := 0
+	cut := false
+	for _, line := range strings.Split(strings.TrimSpace(message), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if len(lines) == runbookExcerptLines {
+			cut = true
+			break
+		}
+		runes := utf8.RuneCountInString(line)
+		if remaining := runbookExcerptLength - length; runes > remaining {
+			if remaining > 0 {
+				lines = append(lines, string([]rune(line)[:remaining]))
+			}
+			cut = true
+			break
+		}
+		length += runes
+		lines = append(lines, line)
+	}
+	excerpt := strings.Join(lines, "\n")
+	if cut {
+		excerpt += "…"
+	}
+	return excerpt
+}
+
+// anchorStripPattern matches the characters GitHub drops from a heading to
+// make its anchor
+var anchorStripPattern = regexp.MustCompile(`[^\p{L}\p{N}\- _]`)
+
+// markdownAnchor returns the GitHub-style anchor of a heading: lowercase,
+// punctuation dropped, spaces as dashes, and a -1, -2, ... suffix for
+// headings already in used
+func markdownAnchor(heading string, used map[string]int) string {
+	anchor := strings.ReplaceAll(anchorStripPattern.ReplaceAllString(strings.ToLower(heading), ""), " ", "-")
+	count := used[anchor]
+	used[anchor] = count + 1
+	if count > 0 {
+		return fmt.Sprintf("%s-%d", anchor, count)
+	}
+	return anchor
+}
@@ -0,0 +1,54 @@
+package datadog
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// validTagValueRune reports whether a character can be used in a service, env
+// or namespace value. Datadog tags also hold ':' and '/', but those break the
+// key:value scopes of queries and monitor searches the values end up in.
+func validTagValueRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.'
+}
+
+// CheckTagValue returns an error naming the characters of a service, env or
+// namespace value that can't be used in tags and query scopes
+func CheckTagValue(value string) error {
+	var invalid []string
+	seen := make(map[rune]bool)
+	for _, r := range value {
+		if validTagValueRune(r) || seen[r] {
+			continue
+		}
+		seen[r] = true
+		invalid = append(invalid, fmt.Sprintf("%q", r))
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("contains characters that can't be used in tags: %s", strings.Join(invalid, ", "))
+	}
+	return nil
+}
+
+// SanitizeTagValue maps a value to one that CheckTagValue accepts: it is
+// lowercased, every run of invalid characters and underscores becomes a single
+// '_', and leading and trailing '_' are trimmed, e.g. "Payments/API" gives
+// "payments_api". The mapping is deterministic, so the same input always
+// yields the same monitor names and tags.
+func SanitizeTagValue(value string) string {
+	var b strings.Builder
+	pending := false
+	for _, r := range strings.ToLower(value) {
+		if !validTagValueRune(r) || r == '_' {
+			pending = true
+			continue
+		}
+		if pending && b.Len() > 0 {
+			b.WriteByte('_')
+		}
+		pending = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
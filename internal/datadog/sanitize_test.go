@@ -0,0 +1,64 @@
+package datadog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckTagValue(t *testing.T) {
+	for _, value := range []string{"", "checkout", "payments-api", "api_v2", "eu.west.1", "Café", "zürich", "東京", "naïve", "ét́é"} {
+		if err := CheckTagValue(value); err != nil {
+			t.Errorf("CheckTagValue(%q) = %v", value, err)
+		}
+	}
+	for value, want := range map[string]string{
+		"payments/api":    `'/'`,
+		"env:prd":         `':'`,
+		"my service":      `' '`,
+		"a,b,c":           `','`,
+		"a b/c d":         `' ', '/'`,
+		"tab\there":       `'\t'`,
+		"emoji🚀":          `'🚀'`,
+		"{service}":       `'{', '}'`,
+		"zero\u200bwidth": `'\u200b'`,
+	} {
+		err := CheckTagValue(value)
+		if err == nil || !strings.HasSuffix(err.Error(), ": "+want) {
+			t.Errorf("CheckTagValue(%q) = %v, want it to name %s", value, err, want)
+		}
+	}
+}
+
+func TestSanitizeTagValue(t *testing.T) {
+	for value, want := range map[string]string{
+		"checkout":        "checkout",
+		"Payments/API":    "payments_api",
+		"my  service":     "my_service",
+		"a / b // c":      "a_b_c",
+		"__api__":         "api",
+		"a__b":            "a_b",
+		"/leading":        "leading",
+		"trailing/":       "trailing",
+		"eu.west-1":       "eu.west-1",
+		"Café Zürich":     "café_zürich",
+		"ÉTÉ":             "été",
+		"東京/大阪":           "東京_大阪",
+		"rocket🚀launch":   "rocket_launch",
+		"zero\u200bwidth": "zero_width",
+		"":                "",
+		"///":             "",
+		"___":             "",
+	} {
+		got := SanitizeTagValue(value)
+		if got != want {
+			t.Errorf("SanitizeTagValue(%q) = %q, want %q", value, got, want)
+		}
+		if err := CheckTagValue(got); err != nil {
+			t.Errorf("SanitizeTagValue(%q) = %q, which CheckTagValue rejects: %v", value, got, err)
+		}
+		// Sanitizing is idempotent
+		if again := SanitizeTagValue(got); again != got {
+			t.Errorf("SanitizeTagValue(%q) = %q, not %q", got, again, got)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package datadog
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ThresholdScale maps environments to the factor their monitors' thresholds
+// are multiplied by after rendering, e.g. dev: 2 alerts in dev at twice the
+// value the template sets
+type ThresholdScale map[string]float64
+
+// ParseThresholdScale parses a --threshold-scale value such as "dev=2.0,hml=1.5"
+func ParseThresholdScale(value string) (ThresholdScale, error) {
+	scale := make(ThresholdScale)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		env, factor, ok := strings.Cut(item, "=")
+		env = strings.TrimSpace(env)
+		if !ok || env == "" {
+			return nil, fmt.Errorf("invalid threshold scale %q (expected env=factor, e.g. dev=2.0)", item)
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(factor), 64)
+		if err != nil || f <= 0 || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("invalid threshold scale factor %q for %s (must be a positive number)", factor, env)
+		}
+		scale[env] = f
+	}
+	return scale, nil
+}
+
+// ScaledThreshold is a threshold ScaleThresholds changed or left alone
+type ScaledThreshold struct {
+	// Field is the options.thresholds key, or "query" for the query's
+	// comparison value
+	Field string
+	From  float64
+	To    float64
+	// Skipped tells why a threshold was not scaled; To is then the value it
+	// kept, or NaN when it was removed
+	Skipped string
+}
+
+// String describes the change, e.g. "critical 90 → 180"
+func (s ScaledThreshold) String() string {
+	from := strconv.FormatFloat(s.From, 'f', -1, 64)
+	switch {
+	case s.Skipped == "":
+		return fmt.Sprintf("%s %s → %s", s.Field, from, strconv.FormatFloat(s.To, 'f', -1, 64))
+	case math.IsNaN(s.To):
+		return fmt.Sprintf("%s %s removed (%s)", s.Field, from, s.Skipped)
+	default:
+		return fmt.Sprintf("%s left at %s (%s)", s.Field, from, s.Skipped)
+	}
+}
+
+// scaledThresholdKeys are the options.thresholds keys ScaleThresholds
+// multiplies, each with the threshold it must stay on the less severe side of
+var scaledThresholdKeys = []struct{ key, bound string }{
+	{"critical", ""},
+	{"warning", "critical"},
+	{"critical_recovery", "critical"},
+	{"warning_recovery", "warning"},
+}
+
+// queryComparisonPattern matches the comparison a query alert's query ends
+// with, e.g. "> 90" in "avg(last_5m):avg:system.cpu.user{*} > 90"
+var queryComparisonPattern = regexp.MustCompile(`(>=|<=|>|<)\s*(-?[0-9]+(?:\.[0-9]+)?)\s*$`)
+
+// relativeQueryPattern matches the functions whose thresholds are not values
+// of the metric (a fraction of points out of bounds), which scaling would break
+var relativeQueryPattern = regexp.MustCompile(`\b(?:anomalies|forecast|outliers)\(`)
+
+// ScaleThresholds multiplies the thresholds of a monitor whose query ends in
+// a comparison (metric, log and other query alerts) by factor and rewrites
+// the query's comparison value to match. Integral values stay integral.
+// Warning and recovery thresholds that rounding would move past the threshold
+// they depend on are left unscaled, or removed when even that crosses it, so
+// Datadog doesn't reject the monitor. Anomaly, forecast and outlier monitors
+// are left alone. It returns the thresholds it changed or skipped.
+func ScaleThresholds(monitor *Monitor, factor float64) []ScaledThreshold {
+	match := queryComparisonPattern.FindStringSubmatchIndex(monitor.Query)
+	if match == nil || relativeQueryPattern.MatchString(monitor.Query) {
+		return nil
+	}
+	operator := monitor.Query[match[2]:match[3]]
+	// Alerting above the threshold: less severe values are lower
+	above := strings.HasPrefix(operator, ">")
+
+	var scaled []ScaledThreshold
+	thresholds, _ := monitor.Options["thresholds"].(map[string]interface{})
+	originalCritical, hasCritical := thresholds["critical"].(float64)
+	for _, threshold := range scaledThresholdKeys {
+		from, ok := thresholds[threshold.key].(float64)
+		if !ok {
+			continue
+		}
+		to := scaleThreshold(from, factor)
+		if bound, ok := thresholds[threshold.bound].(float64); ok && !lessSevere(to, bound, above) {
+			reason := fmt.Sprintf("scaled value %s would cross %s %s", strconv.FormatFloat(to, 'f', -1, 64), threshold.bound, strconv.FormatFloat(bound, 'f', -1, 64))
+			if lessSevere(from, bound, above) {
+				scaled = append(scaled, ScaledThreshold{Field: threshold.key, From: from, To: from, Skipped: reason})
+			} else {
+				delete(thresholds, threshold.key)
+				scaled = append(scaled, ScaledThreshold{Field: threshold.key, From: from, To: math.NaN(), Skipped: reason})
+			}
+			continue
+		}
+		thresholds[threshold.key] = to
+		scaled = append(scaled, ScaledThreshold{Field: threshold.key, From: from, To: to})
+	}
+
+	// The query's value must equal the critical threshold
+	from, err := strconv.ParseFloat(monitor.Query[match[4]:match[5]], 64)
+	if err != nil {
+		return scaled
+	}
+	to := scaleThreshold(from, factor)
+	if hasCritical && from == originalCritical {
+		to = thresholds["critical"].(float64)
+	}
+	monitor.Query = monitor.Query[:match[4]] + strconv.FormatFloat(to, 'f', -1, 64) + monitor.Query[match[5]:]
+	return append(scaled, ScaledThreshold{Field: "query", From: from, To: to})
+}
+
+// scaleThreshold multiplies a threshold, keeping integral values integral and
+// dropping the float noise of other products (0.1*3 is 0.3, not 0.30000000000000004)
+func scaleThreshold(value, factor float64) float64 {
+	if value == math.Trunc(value) {
+		return math.Round(value * factor)
+	}
+	return math.Round(value*factor*1e6) / 1e6
+}
+
+// lessSevere reports whether value is strictly on the less severe side of
+// bound, for a monitor alerting above (or below) its thresholds
+func lessSevere(value, bound float64, above bool) bool {
+	if above {
+		return value < bound
+	}
+	return value > bound
+}
@@ -0,0 +1,222 @@
+package datadog
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseThresholdScale(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		want  ThresholdScale
+	}{
+		{"dev=2", ThresholdScale{"dev": 2}},
+		{" dev = 2.0 , hml=1.5,", ThresholdScale{"dev": 2, "hml": 1.5}},
+		{"stg=0.5", ThresholdScale{"stg": 0.5}},
+		{"", ThresholdScale{}},
+	} {
+		got, err := ParseThresholdScale(tc.value)
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("ParseThresholdScale(%q) = %v, %v, want %v", tc.value, got, err, tc.want)
+		}
+	}
+
+	for _, tc := range []struct {
+		value, want string
+	}{
+		{"dev=0", "must be a positive number"},
+		{"dev=-2", "must be a positive number"},
+		{"dev=two", "must be a positive number"},
+		{"dev=", "must be a positive number"},
+		{"dev=+Inf", "must be a positive number"},
+		{"dev", "expected env=factor"},
+		{"=2", "expected env=factor"},
+	} {
+		if _, err := ParseThresholdScale(tc.value); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("ParseThresholdScale(%q) = %v, want %q", tc.value, err, tc.want)
+		}
+	}
+}
+
+func TestScaleThreshold(t *testing.T) {
+	for _, tc := range []struct {
+		value, factor, want float64
+	}{
+		// Integral values stay integral
+		{90, 2, 180},
+		{90, 1.5, 135},
+		{5, 1.5, 8},
+		{3, 0.5, 2},
+		// Other values lose the float noise
+		{0.1, 3, 0.3},
+		{0.75, 1.1, 0.825},
+		{2.5, 2, 5},
+	} {
+		if got := scaleThreshold(tc.value, tc.factor); got != tc.want {
+			t.Errorf("scaleThreshold(%v, %v) = %v, want %v", tc.value, tc.factor, got, tc.want)
+		}
+	}
+}
+
+func TestLessSevere(t *testing.T) {
+	for _, tc := range []struct {
+		value, bound float64
+		above, want  bool
+	}{
+		{80, 90, true, true},
+		{90, 90, true, false},
+		{95, 90, true, false},
+		{20, 10, false, true},
+		{10, 10, false, false},
+		{5, 10, false, false},
+	} {
+		if got := lessSevere(tc.value, tc.bound, tc.above); got != tc.want {
+			t.Errorf("lessSevere(%v, %v, above=%v) = %v, want %v", tc.value, tc.bound, tc.above, got, tc.want)
+		}
+	}
+}
+
+func TestScaleThresholds(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		query      string
+		thresholds map[string]interface{}
+		factor     float64
+		wantQuery  string
+		// want are the thresholds left, nil when the monitor has none
+		want    map[string]interface{}
+		changes string
+	}{
+		{
+			name:       "above",
+			query:      "avg(last_5m):avg:system.cpu.user{env:dev} > 80",
+			thresholds: map[string]interface{}{"critical": 80.0, "warning": 60.0},
+			factor:     1.2,
+			wantQuery:  "avg(last_5m):avg:system.cpu.user{env:dev} > 96",
+			want:       map[string]interface{}{"critical": 96.0, "warning": 72.0},
+			changes:    "critical 80 → 96; warning 60 → 72; query 80 → 96",
+		},
+		{
+			name:       "below",
+			query:      "avg(last_10m):avg:app.throughput{env:dev} <= 10",
+			thresholds: map[string]interface{}{"critical": 10.0, "warning": 20.0},
+			factor:     0.5,
+			wantQuery:  "avg(last_10m):avg:app.throughput{env:dev} <= 5",
+			want:       map[string]interface{}{"critical": 5.0, "warning": 10.0},
+			changes:    "critical 10 → 5; warning 20 → 10; query 10 → 5",
+		},
+		{
+			name:       "fractional values",
+			query:      "avg(last_5m):avg:http.latency{env:dev} >= 0.5",
+			thresholds: map[string]interface{}{"critical": 0.5},
+			factor:     3,
+			wantQuery:  "avg(last_5m):avg:http.latency{env:dev} >= 1.5",
+			want:       map[string]interface{}{"critical": 1.5},
+			changes:    "critical 0.5 → 1.5; query 0.5 → 1.5",
+		},
+		{
+			name:       "negative query value",
+			query:      "avg(last_5m):avg:temperature{env:dev} < -5",
+			thresholds: map[string]interface{}{"critical": -5.0},
+			factor:     2,
+			wantQuery:  "avg(last_5m):avg:temperature{env:dev} < -10",
+			want:       map[string]interface{}{"critical": -10.0},
+			changes:    "critical -5 → -10; query -5 → -10",
+		},
+		{
+			// Recovery thresholds are scaled while they stay less severe
+			name:       "recovery thresholds",
+			query:      "avg(last_5m):avg:system.cpu.user{env:dev} > 80",
+			thresholds: map[string]interface{}{"critical": 80.0, "critical_recovery": 70.0, "warning": 60.0, "warning_recovery": 50.0},
+			factor:     1.25,
+			wantQuery:  "avg(last_5m):avg:system.cpu.user{env:dev} > 100",
+			want:       map[string]interface{}{"critical": 100.0, "critical_recovery": 88.0, "warning": 75.0, "warning_recovery": 63.0},
+			changes:    "critical 80 → 100; warning 60 → 75; critical_recovery 70 → 88; warning_recovery 50 → 63; query 80 → 100",
+		},
+		{
+			// Rounding would make the recovery cross the critical threshold: it
+			// is left at its value, still on the less severe side
+			name:       "recovery left unscaled",
+			query:      "avg(last_5m):avg:queue.depth{env:dev} > 2.2",
+			thresholds: map[string]interface{}{"critical": 2.2, "critical_recovery": 2.0},
+			factor:     1.3,
+			wantQuery:  "avg(last_5m):avg:queue.depth{env:dev} > 2.86",
+			want:       map[string]interface{}{"critical": 2.86, "critical_recovery": 2.0},
+			changes:    "critical 2.2 → 2.86; critical_recovery left at 2 (scaled value 3 would cross critical 2.86); query 2.2 → 2.86",
+		},
+		{
+			// Rounding makes the warning cross the critical threshold and its
+			// unscaled value is past it too, so it goes
+			name:       "warning removed",
+			query:      "avg(last_5m):avg:app.throughput{env:dev} < 3.8",
+			thresholds: map[string]interface{}{"critical": 3.8, "warning": 4.0},
+			factor:     1.1,
+			wantQuery:  "avg(last_5m):avg:app.throughput{env:dev} < 4.18",
+			want:       map[string]interface{}{"critical": 4.18},
+			changes:    "critical 3.8 → 4.18; warning 4 removed (scaled value 4 would cross critical 4.18); query 3.8 → 4.18",
+		},
+		{
+			// A query value other than the critical threshold is scaled alone
+			name:      "query without thresholds",
+			query:     "logs(\"status:error\").index(\"*\").rollup(\"count\").last(\"5m\") > 100",
+			factor:    2,
+			wantQuery: "logs(\"status:error\").index(\"*\").rollup(\"count\").last(\"5m\") > 200",
+			changes:   "query 100 → 200",
+		},
+		{
+			name:       "anomalies left alone",
+			query:      "avg(last_4h):anomalies(avg:system.cpu.user{env:dev}, 'basic', 2) >= 1",
+			thresholds: map[string]interface{}{"critical": 1.0},
+			factor:     2,
+			wantQuery:  "avg(last_4h):anomalies(avg:system.cpu.user{env:dev}, 'basic', 2) >= 1",
+			want:       map[string]interface{}{"critical": 1.0},
+		},
+		{
+			name:       "no comparison",
+			query:      "\"http.can_connect\".over(\"env:dev\").by(\"host\").last(2).count_by_status()",
+			thresholds: map[string]interface{}{"critical": 1.0},
+			factor:     2,
+			wantQuery:  "\"http.can_connect\".over(\"env:dev\").by(\"host\").last(2).count_by_status()",
+			want:       map[string]interface{}{"critical": 1.0},
+		},
+	} {
+		monitor := Monitor{Name: tc.name, Type: "query alert", Query: tc.query}
+		if tc.thresholds != nil {
+			monitor.Options = map[string]interface{}{"thresholds": tc.thresholds}
+		}
+		scaled := ScaleThresholds(&monitor, tc.factor)
+
+		var changes []string
+		for _, s := range scaled {
+			changes = append(changes, s.String())
+		}
+		if got := strings.Join(changes, "; "); got != tc.changes {
+			t.Errorf("%s: changes %q, want %q", tc.name, got, tc.changes)
+		}
+		if monitor.Query != tc.wantQuery {
+			t.Errorf("%s: query %q, want %q", tc.name, monitor.Query, tc.wantQuery)
+		}
+		got, _ := monitor.Options["thresholds"].(map[string]interface{})
+		if tc.want != nil && !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: thresholds %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestScaledThresholdString(t *testing.T) {
+	for _, tc := range []struct {
+		scaled ScaledThreshold
+		want   string
+	}{
+		{ScaledThreshold{Field: "critical", From: 90, To: 180}, "critical 90 → 180"},
+		{ScaledThreshold{Field: "query", From: 0.5, To: 0.75}, "query 0.5 → 0.75"},
+		{ScaledThreshold{Field: "warning", From: 70, To: 70, Skipped: "too close"}, "warning left at 70 (too close)"},
+		{ScaledThreshold{Field: "warning", From: 70, To: math.NaN(), Skipped: "too close"}, "warning 70 removed (too close)"},
+	} {
+		if got := tc.scaled.String(); got != tc.want {
+			t.Errorf("String() = %q, want %q", got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package datadog
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TemplateSchema is the JSON Schema for a monitor template. Only the subset of
+// JSON Schema used by the schema itself is implemented by the validator
+// (type, enum, required, properties, additionalProperties, items, minimum, maximum).
+//
+//go:embed schema/monitor-template.schema.json
+var TemplateSchema []byte
+
+// jsonSchema is the subset of JSON Schema understood by the validator
+type jsonSchema struct {
+	Type                 schemaType             `json:"type"`
+	Enum                 []interface{}          `json:"enum"`
+	Required             []string               `json:"required"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	AdditionalProperties *bool                  `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+}
+
+// schemaType holds a JSON Schema "type", which can be a string or a list
+type schemaType []string
+
+// UnmarshalJSON accepts both "type": "x" and "type": ["x", "y"]
+func (t *schemaType) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = schemaType{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*t = list
+	return nil
+}
+
+// SchemaViolation is a single schema validation failure
+type SchemaViolation struct {
+	Pointer string      // JSON pointer to the offending value
+	Message string      // What is wrong
+	Value   interface{} // The offending value (nil for missing properties)
+}
+
+// String formats the violation as "pointer: message (got value)"
+func (v SchemaViolation) String() string {
+	pointer := v.Pointer
+	if pointer == "" {
+		pointer = "/"
+	}
+	if v.Value == nil {
+		return fmt.Sprintf("%s: %s", pointer, v.Message)
+	}
+	value, _ := json.Marshal(v.Value)
+	return fmt.Sprintf("%s: %s (got %s)", pointer, v.Message, string(value))
+}
+
+// SchemaError reports every schema violation found in a template file
+type SchemaError struct {
+	File       string
+	Violations []SchemaViolation
+}
+
+// Error lists all violations, one per line
+func (e *SchemaError) Error() string {
+	lines := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		lines[i] = "  - " + v.String()
+	}
+	return fmt.Sprintf("template file %s does not match the monitor template schema (%d problem(s)):\n%s\n(use --no-schema-validation to bypass)",
+		e.File, len(e.Violations), strings.Join(lines, "\n"))
+}
+
+var parsedTemplateSchema *jsonSchema
+
+// templateSchema returns the parsed embedded schema
+func templateSchema() *jsonSchema {
+	if parsedTemplateSchema == nil {
+		var schema jsonSchema
+		if err := json.Unmarshal(TemplateSchema, &schema); err != nil {
+			panic(fmt.Sprintf("invalid embedded template schema: %v", err))
+		}
+		parsedTemplateSchema = &schema
+	}
+	return parsedTemplateSchema
+}
+
+// ValidateTemplateConfig validates one monitor template against the schema and
+// the log, service check and APM rules. pointer is the JSON pointer of the template within its file.
+func ValidateTemplateConfig(config map[string]interface{}, pointer string) []SchemaViolation {
+	// Round-trip through json.Number so integers and floats can be told apart
+	data, err := json.Marshal(config)
+	if err != nil {
+		return []SchemaViolation{{Pointer: pointer, Message: err.Error()}}
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return []SchemaViolation{{Pointer: pointer, Message: err.Error()}}
+	}
+
+	var violations []SchemaViolation
+	templateSchema().validate(value, pointer, &violations)
+	violations = append(violations, lintLogTemplate(config, pointer)...)
+	violations = append(violations, lintServiceCheckTemplate(config, pointer)...)
+	violations = append(violations, lintAPMTemplate(config, pointer)...)
+	return violations
+}
+
+// validateTemplates validates the templates loaded from a file, except the
+// excluded ones (by index)
+func validateTemplates(templateFile string, templates []TemplateData, single bool, excluded map[int]bool) error {
+	var violations []SchemaViolation
+	for i, template := range templates {
+		if excluded[i] {
+			continue
+		}
+		pointer := fmt.Sprintf("/templates/%d/config", i)
+		if single {
+			pointer = ""
+		}
+		violations = append(violations, ValidateTemplateConfig(template.Config, pointer)...)
+	}
+	if len(violations) > 0 {
+		return &SchemaError{File: templateFile, Violations: violations}
+	}
+	return nil
+}
+
+// escapePointerToken escapes a property name for use in a JSON pointer
+func escapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func (s *jsonSchema) validate(value interface{}, pointer string, violations *[]SchemaViolation) {
+	if len(s.Type) > 0 && !s.matchesType(value) {
+		*violations = append(*violations, SchemaViolation{
+			Pointer: pointer,
+			Message: fmt.Sprintf("expected %s", strings.Join(s.Type, " or ")),
+			Value:   value,
+		})
+		return
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for _, allowed := range s.Enum {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			allowed := make([]string, len(s.Enum))
+			for i, v := range s.Enum {
+				allowed[i] = fmt.Sprintf("%q", fmt.Sprint(v))
+			}
+			*violations = append(*violations, SchemaViolation{
+				Pointer: pointer,
+				Message: fmt.Sprintf("must be one of %s", strings.Join(allowed, ", ")),
+				Value:   value,
+			})
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*violations = append(*violations, SchemaViolation{
+					Pointer: pointer,
+					Message: fmt.Sprintf("missing required property %q", name),
+				})
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := pointer + "/" + escapePointerToken(key)
+			if propSchema, ok := s.Properties[key]; ok {
+				propSchema.validate(v[key], child, violations)
+			} else if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				*violations = append(*violations, SchemaViolation{
+					Pointer: child,
+					Message: "unknown property" + suggestProperty(key, s.Properties),
+					Value:   v[key],
+				})
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(item, pointer+"/"+strconv.Itoa(i), violations)
+			}
+		}
+	case json.Number:
+		f, _ := v.Float64()
+		if s.Minimum != nil && f < *s.Minimum {
+			*violations = append(*violations, SchemaViolation{Pointer: pointer, Message: fmt.Sprintf("must be >= %v", *s.Minimum), Value: v})
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			*violations = append(*violations, SchemaViolation{Pointer: pointer, Message: fmt.Sprintf("must be <= %v", *s.Maximum), Value: v})
+		}
+	}
+}
+
+// matchesType reports whether value has one of the schema's types
+func (s *jsonSchema) matchesType(value interface{}) bool {
+	for _, t := range s.Type {
+		switch t {
+		case "object":
+			if _, ok := value.(map[string]interface{}); ok {
+				return true
+			}
+		case "array":
+			if _, ok := value.([]interface{}); ok {
+				return true
+			}
+		case "string":
+			if _, ok := value.(string); ok {
+				return true
+			}
+		case "boolean":
+			if _, ok := value.(bool); ok {
+				return true
+			}
+		case "null":
+			if value == nil {
+				return true
+			}
+		case "number":
+			if _, ok := value.(json.Number); ok {
+				return true
+			}
+		case "integer":
+			if n, ok := value.(json.Number); ok {
+				if _, err := n.Int64(); err == nil {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// suggestProperty returns a "did you mean" hint for a misspelled property
+func suggestProperty(key string, properties map[string]*jsonSchema) string {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	best := ""
+	bestDistance := 3 // Only suggest close matches
+	for _, name := range names {
+		if d := levenshtein(key, name); d < bestDistance {
+			best = name
+			bestDistance = d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %q?)", best)
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
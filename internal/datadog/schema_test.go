@@ -0,0 +1,91 @@
+package datadog
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTemplateSchemaIsValidJSON(t *testing.T) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal(TemplateSchema, &schema); err != nil {
+		t.Fatalf("embedded schema: %v", err)
+	}
+	if schema["$schema"] == nil {
+		t.Error("embedded schema has no $schema, which editors need")
+	}
+}
+
+func TestSchemaCommonMistakes(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{"valid", `{"name": "CPU", "type": "metric alert", "query": "avg(last_5m):avg:cpu{*} > 90", "message": "m", "tags": ["a:b"], "priority": 2,
+			"options": {"thresholds": {"critical": 90, "warning": 80}, "notify_no_data": true, "renotify_interval": 60}}`, nil},
+		{"string threshold", `{"name": "CPU", "type": "metric alert", "query": "q", "options": {"thresholds": {"critical": "90"}}}`,
+			[]string{`/options/thresholds/critical: expected number (got "90")`}},
+		{"tags as a string", `{"name": "CPU", "type": "metric alert", "query": "q", "tags": "env:prd,service:api"}`,
+			[]string{`/tags: expected array (got "env:prd,service:api")`}},
+		{"unknown monitor type", `{"name": "CPU", "type": "metric", "query": "q"}`,
+			[]string{`/type: must be one of`}},
+		{"misspelled property", `{"name": "CPU", "type": "metric alert", "query": "q", "mesage": "typo"}`,
+			[]string{`/mesage: unknown property`}},
+		{"missing required properties", `{"query": "q"}`,
+			[]string{`/: missing required property "name"`, `/: missing required property "type"`}},
+		{"priority out of range", `{"name": "CPU", "type": "metric alert", "query": "q", "priority": 7}`,
+			[]string{`/priority: must be <= 5 (got 7)`}},
+		{"fractional integer", `{"name": "CPU", "type": "metric alert", "query": "q", "options": {"renotify_interval": 1.5}}`,
+			[]string{`/options/renotify_interval: expected integer`}},
+		{"boolean as a string", `{"name": "CPU", "type": "metric alert", "query": "q", "options": {"notify_no_data": "true"}}`,
+			[]string{`/options/notify_no_data: expected boolean (got "true")`}},
+	} {
+		var config map[string]interface{}
+		if err := json.Unmarshal([]byte(tc.config), &config); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		violations := ValidateTemplateConfig(config, "")
+		if len(violations) != len(tc.want) {
+			t.Errorf("%s: %d violation(s) %v, want %d", tc.name, len(violations), violations, len(tc.want))
+			continue
+		}
+		for i, want := range tc.want {
+			if got := violations[i].String(); !strings.HasPrefix(got, want) {
+				t.Errorf("%s: violation %q, want %q", tc.name, got, want)
+			}
+		}
+	}
+}
+
+func TestLoadTemplatesReportsEveryViolation(t *testing.T) {
+	path := writeTemplateFile(t, `{
+  "templates": [
+    {"name": "ok", "config": {"name": "CPU", "type": "metric alert", "query": "q"}},
+    {"name": "bad", "config": {"name": "Disk", "type": "metric", "query": "q", "tags": "env:prd", "options": {"thresholds": {"critical": "90"}}}},
+    {"name": "typo", "config": {"name": "Mem", "type": "query alert", "query": "q", "mesage": "m"}}
+  ]
+}`)
+	_, err := LoadTemplates(path, true)
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("LoadTemplates = %v, want a *SchemaError", err)
+	}
+	var pointers []string
+	for _, violation := range schemaErr.Violations {
+		pointers = append(pointers, violation.Pointer)
+	}
+	want := []string{"/templates/1/config/options/thresholds/critical", "/templates/1/config/tags", "/templates/1/config/type", "/templates/2/config/mesage"}
+	if strings.Join(pointers, " ") != strings.Join(want, " ") {
+		t.Errorf("violations at %v, want %v", pointers, want)
+	}
+	if !strings.Contains(err.Error(), "(4 problem(s))") || !strings.Contains(err.Error(), "--no-schema-validation") {
+		t.Errorf("error:\n%s", err)
+	}
+
+	// Validation can be bypassed
+	if templates, err := LoadTemplates(path, false); err != nil || len(templates) != 3 {
+		t.Errorf("LoadTemplates without validation = %d template(s), %v", len(templates), err)
+	}
+}
@@ -0,0 +1,110 @@
+package datadog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ScopeCheckKeys are the tag keys compared between a query scope and the values a template is applied with
+var ScopeCheckKeys = []string{"env", "service"}
+
+// searchQueryPattern matches the search filter of log, APM and other event-style
+// queries (e.g., logs("service:web env:prd").index("*")...). Chained calls such as
+// .index("*") or .rollup("count") are not filters and are skipped.
+var searchQueryPattern = regexp.MustCompile(`(?:^|[^.\w-])[a-z][a-z0-9_-]*\(\s*"((?:[^"\\]|\\.)*)"`)
+
+// metricScopePattern matches the {...} scope of metric queries, and the
+// "by {...}" group-by clause so metricClauses can tell them apart
+var metricScopePattern = regexp.MustCompile(`(\bby\s*)?\{([^{}]*)\}`)
+
+// ScopeMismatch is a tag in a query scope whose value differs from the expected one
+type ScopeMismatch struct {
+	Key      string `json:"key"`
+	Expected string `json:"expected"`
+	Found    string `json:"found"`
+}
+
+// String returns a human-readable description of the mismatch
+func (m ScopeMismatch) String() string {
+	return fmt.Sprintf("query scope has %s:%s but %s is %s", m.Key, m.Found, m.Key, m.Expected)
+}
+
+// ScopeMismatchError is returned when a rendered query is scoped to another env or service
+type ScopeMismatchError struct {
+	TemplateName string
+	Mismatches   []ScopeMismatch
+}
+
+// Error implements the error interface
+func (e *ScopeMismatchError) Error() string {
+	messages := make([]string, len(e.Mismatches))
+	for i, mismatch := range e.Mismatches {
+		messages[i] = mismatch.String()
+	}
+	return fmt.Sprintf("template %s: %s", e.TemplateName, strings.Join(messages, "; "))
+}
+
+// ExtractQueryScopes returns the scope filters of a monitor query: the text between
+// {} in metric and APM metric queries (group-by clauses excluded) and the quoted
+// search filter of log, trace and event queries
+func ExtractQueryScopes(query string) []string {
+	var scopes []string
+	for _, match := range searchQueryPattern.FindAllStringSubmatch(query, -1) {
+		scopes = append(scopes, strings.ReplaceAll(match[1], `\"`, `"`))
+	}
+	metricScopes, _ := metricClauses(query)
+	return append(scopes, metricScopes...)
+}
+
+// ScopeTagValues returns the values of key in the query scopes, skipping negated
+// and wildcard values
+func ScopeTagValues(query, key string) []string {
+	var values []string
+	for _, scope := range ExtractQueryScopes(query) {
+		negateNext := false
+		for _, token := range strings.FieldsFunc(scope, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '(' || r == ')'
+		}) {
+			if token == "NOT" {
+				negateNext = true
+				continue
+			}
+			negated := negateNext || strings.HasPrefix(token, "!") || strings.HasPrefix(token, "-")
+			negateNext = false
+			if negated {
+				continue
+			}
+
+			token = strings.TrimPrefix(token, "@")
+			prefix := key + ":"
+			if !strings.HasPrefix(token, prefix) {
+				continue
+			}
+			value := strings.Trim(strings.TrimPrefix(token, prefix), `"`)
+			if value == "" || strings.Contains(value, "*") {
+				continue
+			}
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// CheckQueryScope returns the env/service values in the query scope that differ
+// from the expected ones. Keys with an empty expected value are not checked.
+func CheckQueryScope(query string, expected map[string]string) []ScopeMismatch {
+	var mismatches []ScopeMismatch
+	for _, key := range ScopeCheckKeys {
+		want := expected[key]
+		if want == "" {
+			continue
+		}
+		for _, found := range ScopeTagValues(query, key) {
+			if found != want {
+				mismatches = append(mismatches, ScopeMismatch{Key: key, Expected: want, Found: found})
+			}
+		}
+	}
+	return mismatches
+}
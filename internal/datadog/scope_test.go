@@ -0,0 +1,98 @@
+package datadog
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestExtractQueryScopes(t *testing.T) {
+	for _, tc := range []struct {
+		name, query string
+		want        []string
+	}{
+		{"metric", "avg(last_5m):avg:system.cpu.user{env:prd,service:api} > 90", []string{"env:prd,service:api"}},
+		{"metric group-by", "avg(last_5m):avg:system.cpu.user{env:prd} by {host,pod} > 90", []string{"env:prd"}},
+		{"metric wildcard", "avg(last_5m):avg:system.cpu.user{*} by{host} > 90", []string{"*"}},
+		{"arithmetic", "sum(last_5m):sum:http.errors{env:prd}.as_count() / sum:http.requests{env:stg}.as_count() > 0.1",
+			[]string{"env:prd", "env:stg"}},
+		{"log", `logs("service:web env:prd status:error").index("*").rollup("count").by("host").last("5m") > 100`,
+			[]string{"service:web env:prd status:error"}},
+		{"log escaped quotes", `logs("service:web @msg:\"a {b}\"").index("main").rollup("count").last("5m") > 1`,
+			[]string{`service:web @msg:"a {b}"`}},
+		{"apm trace analytics", `trace-analytics("env:prd service:checkout operation_name:http.request").rollup("count").last("5m") > 50`,
+			[]string{"env:prd service:checkout operation_name:http.request"}},
+		{"apm metric", "avg(last_10m):avg:trace.http.request.duration{env:prd,service:checkout} > 0.5",
+			[]string{"env:prd,service:checkout"}},
+		{"apm percentile", "percentile(last_5m):p99:trace.http.request{env:prd,service:checkout} > 2",
+			[]string{"env:prd,service:checkout"}},
+		{"service check", `"http.can_connect".over("env:prd","service:api").by("host").last(3).count_by_status()`, nil},
+		{"no scope", "avg(last_5m):avg:system.cpu.user > 90", nil},
+	} {
+		got := ExtractQueryScopes(tc.query)
+		if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tc.want) {
+			t.Errorf("%s: ExtractQueryScopes(%q) = %q, want %q", tc.name, tc.query, got, tc.want)
+		}
+	}
+}
+
+func TestScopeTagValues(t *testing.T) {
+	for _, tc := range []struct {
+		query, key string
+		want       []string
+	}{
+		{"avg(last_5m):avg:cpu{env:prd,service:api} > 90", "env", []string{"prd"}},
+		// Negated and wildcard values don't scope the query to an env
+		{"avg(last_5m):avg:cpu{!env:dev,env:prd} > 90", "env", []string{"prd"}},
+		{`logs("-env:dev env:prd").index("*").rollup("count").last("5m") > 1`, "env", []string{"prd"}},
+		{`logs("NOT env:dev AND service:api").index("*").rollup("count").last("5m") > 1`, "env", nil},
+		{"avg(last_5m):avg:cpu{env:prd*} > 90", "env", nil},
+		{`logs("@env:prd (service:a OR service:b)").index("*").rollup("count").last("5m") > 1`, "service", []string{"a", "b"}},
+		{`logs("env:\"prd\"").index("*").rollup("count").last("5m") > 1`, "env", []string{"prd"}},
+		// Other keys sharing the prefix don't count
+		{"avg(last_5m):avg:cpu{environment:prd,service_name:x} > 90", "env", nil},
+	} {
+		got := ScopeTagValues(tc.query, tc.key)
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("ScopeTagValues(%q, %s) = %q, want %q", tc.query, tc.key, got, tc.want)
+		}
+	}
+}
+
+func TestCheckQueryScope(t *testing.T) {
+	expected := map[string]string{"env": "prd", "service": "checkout"}
+	for _, tc := range []struct {
+		name, query string
+		want        []string
+	}{
+		{"metric match", "avg(last_5m):avg:cpu{env:prd,service:checkout} > 90", nil},
+		{"metric hardcoded env", "avg(last_5m):avg:cpu{env:dev,service:checkout} > 90",
+			[]string{"query scope has env:dev but env is prd"}},
+		{"log hardcoded both", `logs("env:dev service:web").index("*").rollup("count").last("5m") > 1`,
+			[]string{"query scope has env:dev but env is prd", "query scope has service:web but service is checkout"}},
+		{"apm trace analytics", `trace-analytics("env:stg service:checkout").rollup("count").last("5m") > 1`,
+			[]string{"query scope has env:stg but env is prd"}},
+		{"apm metric", "avg(last_10m):avg:trace.http.request.duration{env:prd,service:payments} > 0.5",
+			[]string{"query scope has service:payments but service is checkout"}},
+		{"group-by is not a scope", "avg(last_5m):avg:cpu{env:prd} by {env,service} > 90", nil},
+		{"negated", "avg(last_5m):avg:cpu{env:prd,!service:legacy} > 90", nil},
+		{"unscoped", "avg(last_5m):avg:cpu{*} > 90", nil},
+	} {
+		var got []string
+		for _, mismatch := range CheckQueryScope(tc.query, expected) {
+			got = append(got, mismatch.String())
+		}
+		if strings.Join(got, "\n") != strings.Join(tc.want, "\n") {
+			t.Errorf("%s: CheckQueryScope = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	// Empty expected values are not checked
+	if got := CheckQueryScope("avg(last_5m):avg:cpu{env:dev,service:web} > 90", map[string]string{"env": "prd"}); len(got) != 1 {
+		t.Errorf("CheckQueryScope without a service = %v, want only the env mismatch", got)
+	}
+	err := &ScopeMismatchError{TemplateName: "CPU", Mismatches: CheckQueryScope("avg(last_5m):avg:cpu{env:dev,service:web} > 90", expected)}
+	if want := "template CPU: query scope has env:dev but env is prd; query scope has service:web but service is checkout"; err.Error() != want {
+		t.Errorf("error %q, want %q", err, want)
+	}
+}
@@ -0,0 +1,127 @@
+package datadog
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Reasons a template is skipped
+const (
+	SkipReasonDisabled = "disabled"
+	SkipReasonSkip     = "--skip"
+	SkipReasonOnly     = "not in --only"
+)
+
+// TemplateSelection picks the templates to apply by glob patterns matched
+// against template names, rendered-name templates (the config "name") and
+// template file names (with or without .json)
+type TemplateSelection struct {
+	// Only keeps the templates matching any pattern; empty keeps every template
+	Only []string
+	// Skip drops the templates matching any pattern
+	Skip []string
+}
+
+// SkippedTemplate is a template left out of an apply
+type SkippedTemplate struct {
+	TemplateName string
+	Reason       string // SkipReasonDisabled, SkipReasonSkip or SkipReasonOnly
+}
+
+// Validate checks the glob patterns
+func (s TemplateSelection) Validate() error {
+	for flag, patterns := range map[string][]string{"--only": s.Only, "--skip": s.Skip} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid %s pattern %q: %v", flag, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// exclusion returns why a template of a file is excluded, or "" when it is selected
+func (s TemplateSelection) exclusion(templateFile string, template TemplateData) string {
+	candidates := templateMatchNames(templateFile)
+	if template.Name != "" {
+		candidates = append(candidates, template.Name)
+	}
+	if name, ok := template.Config["name"].(string); ok && name != "" {
+		candidates = append(candidates, name)
+	}
+
+	if matchesAnyGlob(s.Skip, candidates) {
+		return SkipReasonSkip
+	}
+	if len(s.Only) > 0 && !matchesAnyGlob(s.Only, candidates) {
+		return SkipReasonOnly
+	}
+	return ""
+}
+
+// skipsFile reports whether --skip matches the file name itself, so none of
+// its templates need to be loaded
+func (s TemplateSelection) skipsFile(templateFile string) bool {
+	return matchesAnyGlob(s.Skip, templateMatchNames(templateFile))
+}
+
+// skippedFile reports every template of a file skipped with --skip, or the
+// file itself when it can't be parsed
+func skippedFile(templateFile string) []SkippedTemplate {
+	data, err := os.ReadFile(templateFile)
+	if err == nil {
+		if templates, _, err := parseTemplates(templateFile, data); err == nil {
+			skipped := make([]SkippedTemplate, 0, len(templates))
+			for _, template := range templates {
+				skipped = append(skipped, SkippedTemplate{TemplateName: templateDisplayName(template), Reason: SkipReasonSkip})
+			}
+			return skipped
+		}
+	}
+	return []SkippedTemplate{{TemplateName: filepath.Base(templateFile), Reason: SkipReasonSkip}}
+}
+
+// templateMatchNames returns the names a template file is matched by
+func templateMatchNames(templateFile string) []string {
+	base := filepath.Base(templateFile)
+	return []string{base, strings.TrimSuffix(base, filepath.Ext(base))}
+}
+
+// matchesAnyGlob reports whether any value matches any pattern; invalid
+// patterns never match (see Validate)
+func matchesAnyGlob(patterns, values []string) bool {
+	for _, pattern := range patterns {
+		for _, value := range values {
+			if matched, _ := path.Match(pattern, value); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// selectTemplates splits templates into those to load and those excluded by
+// the selection
+func selectTemplates(templateFile string, templates []TemplateData, selection TemplateSelection) ([]int, []SkippedTemplate) {
+	var selected []int
+	var skipped []SkippedTemplate
+	for i, template := range templates {
+		if reason := selection.exclusion(templateFile, template); reason != "" {
+			skipped = append(skipped, SkippedTemplate{TemplateName: templateDisplayName(template), Reason: reason})
+			continue
+		}
+		selected = append(selected, i)
+	}
+	return selected, skipped
+}
+
+// templateDisplayName returns the name a template is reported by
+func templateDisplayName(template TemplateData) string {
+	if template.Name == "" {
+		return "Unknown Template"
+	}
+	return template.Name
+}
@@ -0,0 +1,334 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ServiceCheckBlock is the structured "service_check" block of a service
+// check template, compiled into a
+// "check".over(scope).exclude(...).by(group_by).last(n).count_by_status()
+// query and the ok/warning/critical counts of options.thresholds
+type ServiceCheckBlock struct {
+	// Check is the service check name, e.g. kubernetes.kubelet.check
+	Check string `json:"check"`
+	// Scope are the tags the check is evaluated over (default: every source)
+	Scope   []string `json:"scope,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+	GroupBy []string `json:"group_by,omitempty"`
+	// Last is the number of most recent check runs evaluated
+	Last   int                `json:"last"`
+	Counts ServiceCheckCounts `json:"counts"`
+}
+
+// ServiceCheckCounts are how many of the last check runs must have a status
+// for the monitor to take it: critical and warning to alert, ok to recover
+type ServiceCheckCounts struct {
+	Critical int  `json:"critical"`
+	Warning  *int `json:"warning,omitempty"`
+	OK       *int `json:"ok,omitempty"`
+}
+
+// serviceCheckQueryPattern matches a compiled service check query
+var serviceCheckQueryPattern = regexp.MustCompile(`^"((?:[^"\\]|\\.)*)"` +
+	`\.over\(((?:\s*"(?:[^"\\]|\\.)*"\s*,?)*)\)` +
+	`(?:\.exclude\(((?:\s*"(?:[^"\\]|\\.)*"\s*,?)*)\))?` +
+	`(?:\.by\(((?:\s*"(?:[^"\\]|\\.)*"\s*,?)*)\))?` +
+	`\.last\((\d+)\)\.count_by_status\(\)$`)
+
+// serviceCheckLastPattern matches the last(n) of a service check query
+var serviceCheckLastPattern = regexp.MustCompile(`\.last\((\d+)\)`)
+
+// quotedListPattern matches the quoted values of an over(), exclude() or by() list
+var quotedListPattern = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"`)
+
+// CompileServiceCheckQuery builds the query of a service check block
+func CompileServiceCheckQuery(block ServiceCheckBlock) string {
+	scope := block.Scope
+	if len(scope) == 0 {
+		scope = []string{"*"}
+	}
+	groupBy := block.GroupBy
+	if len(groupBy) == 0 {
+		groupBy = []string{"*"}
+	}
+
+	var b strings.Builder
+	b.WriteString(strconv.Quote(block.Check))
+	fmt.Fprintf(&b, ".over(%s)", quoteList(scope))
+	if len(block.Exclude) > 0 {
+		fmt.Fprintf(&b, ".exclude(%s)", quoteList(block.Exclude))
+	}
+	fmt.Fprintf(&b, ".by(%s).last(%d).count_by_status()", quoteList(groupBy), block.Last)
+	return b.String()
+}
+
+// quoteList quotes values and joins them with commas
+func quoteList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = strconv.Quote(value)
+	}
+	return strings.Join(quoted, ",")
+}
+
+// unquoteList returns the values of an over(), exclude() or by() list; "*"
+// alone is no value
+func unquoteList(list string) ([]string, bool) {
+	var values []string
+	for _, match := range quotedListPattern.FindAllStringSubmatch(list, -1) {
+		value, err := strconv.Unquote(`"` + match[1] + `"`)
+		if err != nil {
+			return nil, false
+		}
+		values = append(values, value)
+	}
+	if len(values) == 1 && values[0] == "*" {
+		return nil, true
+	}
+	return values, true
+}
+
+// DecompileServiceCheckQuery turns a service check query back into a service
+// check block, with the counts of the monitor options. ok is false for
+// queries the block can't express and fractional counts.
+func DecompileServiceCheckQuery(query string, options map[string]interface{}) (block ServiceCheckBlock, ok bool) {
+	match := serviceCheckQueryPattern.FindStringSubmatch(strings.TrimSpace(query))
+	if match == nil {
+		return block, false
+	}
+	check, err := strconv.Unquote(`"` + match[1] + `"`)
+	if err != nil {
+		return block, false
+	}
+	last, err := strconv.Atoi(match[5])
+	if err != nil {
+		return block, false
+	}
+	block = ServiceCheckBlock{Check: check, Last: last}
+	for _, list := range []struct {
+		raw    string
+		values *[]string
+	}{
+		{match[2], &block.Scope},
+		{match[3], &block.Exclude},
+		{match[4], &block.GroupBy},
+	} {
+		if *list.values, ok = unquoteList(list.raw); !ok {
+			return ServiceCheckBlock{}, false
+		}
+	}
+
+	thresholds, _ := options["thresholds"].(map[string]interface{})
+	for name, count := range map[string]**int{"warning": &block.Counts.Warning, "ok": &block.Counts.OK} {
+		if value, ok := thresholds[name].(float64); ok {
+			if value != float64(int(value)) {
+				return ServiceCheckBlock{}, false
+			}
+			n := int(value)
+			*count = &n
+		}
+	}
+	if critical, ok := thresholds["critical"].(float64); ok {
+		if critical != float64(int(critical)) {
+			return ServiceCheckBlock{}, false
+		}
+		block.Counts.Critical = int(critical)
+	}
+	return block, true
+}
+
+// serviceCheckCounts returns the counts of a block by threshold name
+func serviceCheckCounts(counts ServiceCheckCounts) map[string]*int {
+	return map[string]*int{"critical": &counts.Critical, "warning": counts.Warning, "ok": counts.OK}
+}
+
+// LintServiceCheckBlock returns the problems of a service check block beyond
+// the schema, by the name of the property they concern: counts must be
+// between 1 and last, and scope, exclude and group_by values non-empty
+func LintServiceCheckBlock(block ServiceCheckBlock) map[string][]string {
+	problems := make(map[string][]string)
+	if strings.TrimSpace(block.Check) == "" || strings.ContainsAny(block.Check, `" `) {
+		problems["check"] = append(problems["check"], "must be a service check name such as kubernetes.kubelet.check")
+	}
+	for _, name := range []string{"critical", "warning", "ok"} {
+		count := serviceCheckCounts(block.Counts)[name]
+		switch {
+		case count == nil:
+		case *count < 1:
+			problems["counts/"+name] = append(problems["counts/"+name], "must be at least 1")
+		case *count > block.Last:
+			problems["counts/"+name] = append(problems["counts/"+name], fmt.Sprintf("%d is more than the %d check runs evaluated (last)", *count, block.Last))
+		}
+	}
+	for _, list := range []struct {
+		name   string
+		values []string
+	}{{"scope", block.Scope}, {"exclude", block.Exclude}, {"group_by", block.GroupBy}} {
+		for i, value := range list.values {
+			if strings.TrimSpace(value) == "" {
+				key := fmt.Sprintf("%s/%d", list.name, i)
+				problems[key] = append(problems[key], "is empty")
+			}
+		}
+	}
+	return problems
+}
+
+// serviceCheckThresholdKeys are the thresholds a service check monitor takes
+var serviceCheckThresholdKeys = map[string]bool{"critical": true, "warning": true, "ok": true}
+
+// lintServiceCheckThresholds returns the metric-style threshold mistakes of
+// a service check monitor: recovery or unknown thresholds, fractional counts,
+// and counts over the last(n) of its query (when it can be parsed)
+func lintServiceCheckThresholds(config map[string]interface{}, last int) map[string][]string {
+	problems := make(map[string][]string)
+	options, _ := config["options"].(map[string]interface{})
+	thresholds, _ := options["thresholds"].(map[string]interface{})
+	for key, raw := range thresholds {
+		if !serviceCheckThresholdKeys[key] {
+			problems[key] = append(problems[key], "service checks only take ok, warning and critical counts (metric-style threshold)")
+			continue
+		}
+		count, ok := toFloat(raw)
+		if !ok {
+			continue
+		}
+		switch {
+		case count != float64(int(count)) || count < 1:
+			problems[key] = append(problems[key], fmt.Sprintf("must be a count of check runs (a whole number of at least 1), not %s (metric-style threshold)", formatNumber(count)))
+		case last > 0 && int(count) > last:
+			problems[key] = append(problems[key], fmt.Sprintf("%s is more than the %d check runs evaluated (last)", formatNumber(count), last))
+		}
+	}
+	return problems
+}
+
+// toFloat returns a JSON number as a float64
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// lintServiceCheckTemplate checks the service check block of a template, or
+// the query and thresholds of a service check template written by hand: the
+// template is a service check, doesn't also set a query, and its counts are
+// consistent with the check runs it evaluates
+func lintServiceCheckTemplate(config map[string]interface{}, pointer string) []SchemaViolation {
+	var violations []SchemaViolation
+	monitorType, _ := config["type"].(string)
+	raw, hasBlock := config["service_check"]
+	thresholdsPointer := pointer + "/options/thresholds"
+
+	if !hasBlock {
+		query, _ := config["query"].(string)
+		if monitorType != "service check" {
+			if strings.HasSuffix(strings.TrimSpace(query), ".count_by_status()") {
+				violations = append(violations, SchemaViolation{Pointer: pointer + "/type", Message: `a service check query needs type "service check"`, Value: config["type"]})
+			}
+			return violations
+		}
+		if queryComparisonPattern.MatchString(NormalizeQuery(query)) {
+			violations = append(violations, SchemaViolation{Pointer: pointer + "/query", Message: "service check queries end with .count_by_status(), not a comparison (metric-style threshold); set the counts in options.thresholds"})
+		}
+		last := 0
+		if match := serviceCheckLastPattern.FindStringSubmatch(query); match != nil {
+			last, _ = strconv.Atoi(match[1])
+		}
+		return append(violations, problemViolations(thresholdsPointer, lintServiceCheckThresholds(config, last))...)
+	}
+
+	block, err := decodeServiceCheckBlock(raw)
+	if err != nil {
+		// Type problems are reported by the schema
+		return nil
+	}
+	blockPointer := pointer + "/service_check"
+	if _, hasQuery := config["query"]; hasQuery {
+		violations = append(violations, SchemaViolation{Pointer: pointer + "/query", Message: `set either "query" or a "service_check" block, not both`})
+	}
+	if _, hasLog := config["log"]; hasLog {
+		violations = append(violations, SchemaViolation{Pointer: blockPointer, Message: `set either a "log" or a "service_check" block, not both`})
+	}
+	if monitorType != "service check" {
+		violations = append(violations, SchemaViolation{Pointer: pointer + "/type", Message: `a "service_check" block needs type "service check"`, Value: config["type"]})
+	}
+	violations = append(violations, problemViolations(blockPointer, LintServiceCheckBlock(block))...)
+	return append(violations, problemViolations(thresholdsPointer, lintServiceCheckThresholds(config, block.Last))...)
+}
+
+// problemViolations returns problems by property as violations under
+// pointer, sorted by property
+func problemViolations(pointer string, problems map[string][]string) []SchemaViolation {
+	keys := make([]string, 0, len(problems))
+	for key := range problems {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var violations []SchemaViolation
+	for _, key := range keys {
+		for _, problem := range problems[key] {
+			violations = append(violations, SchemaViolation{Pointer: pointer + "/" + key, Message: problem})
+		}
+	}
+	return violations
+}
+
+// decodeServiceCheckBlock converts the "service_check" value of a template
+// into a ServiceCheckBlock
+func decodeServiceCheckBlock(raw interface{}) (ServiceCheckBlock, error) {
+	var block ServiceCheckBlock
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return block, err
+	}
+	err = json.Unmarshal(data, &block)
+	return block, err
+}
+
+// compileServiceCheckBlocks replaces the "service_check" block of each
+// template with the compiled query, and sets the ok/warning/critical counts
+// unless the template sets them
+func compileServiceCheckBlocks(templateFile string, templates []TemplateData) error {
+	for _, template := range templates {
+		raw, ok := template.Config["service_check"]
+		if !ok {
+			continue
+		}
+		block, err := decodeServiceCheckBlock(raw)
+		if err != nil {
+			return fmt.Errorf("invalid service_check block in template %s (%s): %v", template.Name, templateFile, err)
+		}
+		delete(template.Config, "service_check")
+		template.Config["query"] = CompileServiceCheckQuery(block)
+
+		options, _ := template.Config["options"].(map[string]interface{})
+		if options == nil {
+			options = make(map[string]interface{})
+			template.Config["options"] = options
+		}
+		thresholds, _ := options["thresholds"].(map[string]interface{})
+		if thresholds == nil {
+			thresholds = make(map[string]interface{})
+			options["thresholds"] = thresholds
+		}
+		for name, count := range serviceCheckCounts(block.Counts) {
+			if _, ok := thresholds[name]; !ok && count != nil {
+				thresholds[name] = *count
+			}
+		}
+	}
+	return nil
+}
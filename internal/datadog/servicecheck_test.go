@@ -0,0 +1,166 @@
+package datadog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func intPtr(n int) *int { return &n }
+
+func TestCompileServiceCheckQuery(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		block ServiceCheckBlock
+		want  string
+	}{
+		{"every source", ServiceCheckBlock{Check: "kubernetes.kubelet.check", Last: 3},
+			`"kubernetes.kubelet.check".over("*").by("*").last(3).count_by_status()`},
+		{"one tag", ServiceCheckBlock{Check: "http.can_connect", Scope: []string{"env:prd"}, GroupBy: []string{"host"}, Last: 4},
+			`"http.can_connect".over("env:prd").by("host").last(4).count_by_status()`},
+		{"several tags", ServiceCheckBlock{
+			Check:   "kubernetes.kubelet.check",
+			Scope:   []string{"env:{env}", "kube_cluster_name:{namespace}", "service:{service}"},
+			Exclude: []string{"node_role:spot", "kube_zone:us-east-1c"},
+			GroupBy: []string{"host", "kube_node"},
+			Last:    5,
+		}, `"kubernetes.kubelet.check".over("env:{env}","kube_cluster_name:{namespace}","service:{service}").exclude("node_role:spot","kube_zone:us-east-1c").by("host","kube_node").last(5).count_by_status()`},
+		{"quotes", ServiceCheckBlock{Check: "custom.check", Scope: []string{`team:"a b"`}, Last: 1},
+			`"custom.check".over("team:\"a b\"").by("*").last(1).count_by_status()`},
+	} {
+		if got := CompileServiceCheckQuery(tc.block); got != tc.want {
+			t.Errorf("%s:\n got %s\nwant %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestServiceCheckRoundTrip(t *testing.T) {
+	for _, block := range []ServiceCheckBlock{
+		{Check: "kubernetes.kubelet.check", Last: 3, Counts: ServiceCheckCounts{Critical: 2}},
+		{Check: "http.can_connect", Scope: []string{"env:prd", "service:api", "team:payments"}, GroupBy: []string{"host", "url"}, Last: 6,
+			Counts: ServiceCheckCounts{Critical: 3, Warning: intPtr(1), OK: intPtr(2)}},
+		{Check: "postgres.can_connect", Scope: []string{"env:prd"}, Exclude: []string{"role:replica", "db:scratch"}, Last: 2,
+			Counts: ServiceCheckCounts{Critical: 1, OK: intPtr(1)}},
+	} {
+		// Thresholds come back from the API as JSON numbers
+		thresholds := map[string]interface{}{"critical": float64(block.Counts.Critical)}
+		if block.Counts.Warning != nil {
+			thresholds["warning"] = float64(*block.Counts.Warning)
+		}
+		if block.Counts.OK != nil {
+			thresholds["ok"] = float64(*block.Counts.OK)
+		}
+		query := CompileServiceCheckQuery(block)
+		got, ok := DecompileServiceCheckQuery(query, map[string]interface{}{"thresholds": thresholds})
+		if !ok || !reflect.DeepEqual(got, block) {
+			t.Errorf("round trip of %s:\n got %+v (%v)\nwant %+v", query, got, ok, block)
+		}
+	}
+}
+
+func TestDecompileServiceCheckQuery(t *testing.T) {
+	// Spacing the API keeps is accepted
+	block, ok := DecompileServiceCheckQuery(` "http.can_connect".over("env:prd", "service:api").by("host").last(3).count_by_status() `, nil)
+	if !ok || !reflect.DeepEqual(block.Scope, []string{"env:prd", "service:api"}) || block.Last != 3 {
+		t.Errorf("decompiled %+v, %v", block, ok)
+	}
+
+	for _, tc := range []struct {
+		query      string
+		thresholds map[string]interface{}
+	}{
+		{`"http.can_connect".over("*").last(3).count_by_status()`, map[string]interface{}{"critical": 1.5}},
+		{`"http.can_connect".over("*").last(3).count_by_status()`, map[string]interface{}{"ok": 0.5}},
+		{`"http.can_connect".over("*").by("host").last(3)`, nil},
+		{`avg(last_5m):avg:system.cpu.user{*} > 90`, nil},
+		{`"http.can_connect".over("*").by("host").last(n).count_by_status()`, nil},
+	} {
+		if block, ok := DecompileServiceCheckQuery(tc.query, map[string]interface{}{"thresholds": tc.thresholds}); ok {
+			t.Errorf("DecompileServiceCheckQuery(%s, %v) = %+v, want not ok", tc.query, tc.thresholds, block)
+		}
+	}
+}
+
+func TestLintServiceCheckBlock(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		block ServiceCheckBlock
+		want  []string
+	}{
+		{"valid", ServiceCheckBlock{Check: "http.can_connect", Last: 3, Counts: ServiceCheckCounts{Critical: 3, Warning: intPtr(1), OK: intPtr(1)}}, nil},
+		{"critical over last", ServiceCheckBlock{Check: "http.can_connect", Last: 3, Counts: ServiceCheckCounts{Critical: 4}},
+			[]string{"counts/critical: 4 is more than the 3 check runs evaluated (last)"}},
+		{"zero counts", ServiceCheckBlock{Check: "http.can_connect", Last: 3, Counts: ServiceCheckCounts{OK: intPtr(0)}},
+			[]string{"counts/critical: must be at least 1", "counts/ok: must be at least 1"}},
+		{"metric name", ServiceCheckBlock{Check: `avg:"x"`, Last: 1, Counts: ServiceCheckCounts{Critical: 1}},
+			[]string{"check: must be a service check name such as kubernetes.kubelet.check"}},
+		{"empty tags", ServiceCheckBlock{Check: "x", Scope: []string{"env:prd", ""}, GroupBy: []string{" "}, Last: 1, Counts: ServiceCheckCounts{Critical: 1}},
+			[]string{"group_by/0: is empty", "scope/1: is empty"}},
+	} {
+		var got []string
+		for _, violation := range problemViolations("", LintServiceCheckBlock(tc.block)) {
+			got = append(got, strings.TrimPrefix(violation.Pointer, "/")+": "+violation.Message)
+		}
+		if strings.Join(got, "\n") != strings.Join(tc.want, "\n") {
+			t.Errorf("%s: problems %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestLintServiceCheckTemplateMetricStyle(t *testing.T) {
+	config := map[string]interface{}{
+		"type":  "service check",
+		"query": `"http.can_connect".over("env:prd").by("host").last(3).count_by_status() > 2`,
+		"options": map[string]interface{}{"thresholds": map[string]interface{}{
+			"critical": 0.9, "critical_recovery": 0.5, "ok": 4.0, "warning": 2.0,
+		}},
+	}
+	var got []string
+	for _, violation := range lintServiceCheckTemplate(config, "/templates/0/config") {
+		got = append(got, violation.Pointer)
+	}
+	want := []string{
+		"/templates/0/config/query",
+		"/templates/0/config/options/thresholds/critical",
+		"/templates/0/config/options/thresholds/critical_recovery",
+		"/templates/0/config/options/thresholds/ok",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("violations at %q, want %q", got, want)
+	}
+
+	// A service check query under another type
+	config = map[string]interface{}{"type": "metric alert", "query": `"x".over("*").by("*").last(1).count_by_status()`}
+	if violations := lintServiceCheckTemplate(config, ""); len(violations) != 1 || violations[0].Pointer != "/type" {
+		t.Errorf("violations %v, want one at /type", violations)
+	}
+}
+
+func TestRenderServiceCheckTemplate(t *testing.T) {
+	path := writeTemplateFile(t, `{
+  "name": "[{env}] {service} kubelet",
+  "type": "service check",
+  "message": "Kubelet down",
+  "service_check": {
+    "check": "kubernetes.kubelet.check",
+    "scope": ["env:{env}", "service:{service}", "kube_cluster_name:{namespace}"],
+    "group_by": ["host"],
+    "last": 4,
+    "counts": {"critical": 3, "ok": 1}
+  }
+}`)
+	rendered, _, err := RenderSelectedTemplates(path, ApplyOptions{RenderOptions: RenderOptions{Service: "kubelet", Env: "prd", Namespace: "main"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	monitor := rendered[0].Monitor
+	if want := `"kubernetes.kubelet.check".over("env:prd","service:kubelet","kube_cluster_name:main").by("host").last(4).count_by_status()`; monitor.Query != want {
+		t.Errorf("query %s\nwant  %s", monitor.Query, want)
+	}
+	thresholds := monitor.Options["thresholds"].(map[string]interface{})
+	critical, _ := toFloat(thresholds["critical"])
+	ok, _ := toFloat(thresholds["ok"])
+	if critical != 3 || ok != 1 || thresholds["warning"] != nil {
+		t.Errorf("thresholds %v", thresholds)
+	}
+}
@@ -0,0 +1,144 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// SLOThreshold represents a target for one SLO timeframe
+type SLOThreshold struct {
+	Timeframe string  `json:"timeframe" yaml:"timeframe"`
+	Target    float64 `json:"target" yaml:"target"`
+	Warning   float64 `json:"warning,omitempty" yaml:"warning,omitempty"`
+}
+
+// SLOQuery represents the numerator/denominator of a metric-based SLO
+type SLOQuery struct {
+	Numerator   string `json:"numerator" yaml:"numerator"`
+	Denominator string `json:"denominator" yaml:"denominator"`
+}
+
+// SLO represents a Datadog service level objective
+type SLO struct {
+	ID          string         `json:"id,omitempty"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Type        string         `json:"type"`
+	MonitorIDs  []int          `json:"monitor_ids,omitempty"`
+	Query       *SLOQuery      `json:"query,omitempty"`
+	Thresholds  []SLOThreshold `json:"thresholds"`
+	Tags        []string       `json:"tags,omitempty"`
+}
+
+// sloResponse is the envelope the SLO endpoints wrap their results in
+type sloResponse struct {
+	Data []SLO `json:"data"`
+}
+
+// decodeSLOResponse reads the first SLO from an SLO API response
+func decodeSLOResponse(resp *http.Response) (*SLO, error) {
+	var result sloResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	if len(result.Data) == 0 {
+		return nil, fmt.Errorf("empty SLO response")
+	}
+	return &result.Data[0], nil
+}
+
+// CreateSLO creates a new SLO
+func (c *Client) CreateSLO(slo *SLO) (*SLO, error) {
+	resp, err := c.makeRequest("POST", "/slo", slo)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to create SLO: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	return decodeSLOResponse(resp)
+}
+
+// UpdateSLO updates an existing SLO
+func (c *Client) UpdateSLO(sloID string, slo *SLO) (*SLO, error) {
+	endpoint := fmt.Sprintf("/slo/%s", url.PathEscape(sloID))
+	resp, err := c.makeRequest("PUT", endpoint, slo)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to update SLO: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	return decodeSLOResponse(resp)
+}
+
+// FindSLOByName finds an SLO by its exact name
+func (c *Client) FindSLOByName(name string) (*SLO, error) {
+	endpoint := fmt.Sprintf("/slo?query=%s", url.QueryEscape(name))
+	resp, err := c.makeRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to search SLOs: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var result sloResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	for _, slo := range result.Data {
+		if slo.Name == name {
+			return &slo, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// UpsertSLO creates or updates an SLO, matching by name
+func (c *Client) UpsertSLO(slo *SLO) (*SLO, bool, error) {
+	existing, err := c.FindSLOByName(slo.Name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if existing != nil {
+		updated, err := c.UpdateSLO(existing.ID, slo)
+		return updated, false, err
+	}
+
+	created, err := c.CreateSLO(slo)
+	return created, true, err
+}
+
+// DeleteSLO deletes an SLO
+func (c *Client) DeleteSLO(sloID string) error {
+	resp, err := c.makeRequest("DELETE", fmt.Sprintf("/slo/%s", url.PathEscape(sloID)), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to delete SLO: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
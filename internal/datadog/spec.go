@@ -0,0 +1,258 @@
+package datadog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/fetch"
+	"gopkg.in/yaml.v3"
+)
+
+// ServiceSpec describes everything observability-related for one service:
+// the monitor templates to apply, SLOs built on top of them and maintenance windows
+type ServiceSpec struct {
+	Service   string            `yaml:"service"`
+	Env       string            `yaml:"env"`
+	Namespace string            `yaml:"namespace"`
+	Tags      []string          `yaml:"tags,omitempty"`
+	Templates []SpecTemplateRef `yaml:"templates"`
+	SLOs      []SpecSLO         `yaml:"slos,omitempty"`
+	Downtimes []SpecDowntime    `yaml:"downtimes,omitempty"`
+	// ThresholdScale multiplies the thresholds of the spec's monitors (see
+	// ScaleThresholds); 0 falls back to threshold_scale in the config file
+	ThresholdScale float64 `yaml:"threshold_scale,omitempty"`
+}
+
+// SpecTemplateRef references a template file with per-template variable overrides
+type SpecTemplateRef struct {
+	File string            `yaml:"file"`
+	Vars map[string]string `yaml:"vars,omitempty"`
+	Tags []string          `yaml:"tags,omitempty"`
+}
+
+// SpecSLO describes an SLO; monitor-based SLOs reference monitors applied by
+// the same spec by template name or rendered monitor name
+type SpecSLO struct {
+	Name        string         `yaml:"name"`
+	Description string         `yaml:"description,omitempty"`
+	Type        string         `yaml:"type,omitempty"`
+	Monitors    []string       `yaml:"monitors,omitempty"`
+	Query       *SLOQuery      `yaml:"query,omitempty"`
+	Thresholds  []SLOThreshold `yaml:"thresholds"`
+	Tags        []string       `yaml:"tags,omitempty"`
+}
+
+// SpecDowntime describes a maintenance window. Start and End are RFC3339 times;
+// the downtime is identified across runs by its name
+type SpecDowntime struct {
+	Name        string              `yaml:"name"`
+	Scope       []string            `yaml:"scope"`
+	MonitorTags []string            `yaml:"monitor_tags,omitempty"`
+	Start       string              `yaml:"start,omitempty"`
+	End         string              `yaml:"end,omitempty"`
+	Timezone    string              `yaml:"timezone,omitempty"`
+	Message     string              `yaml:"message,omitempty"`
+	Recurrence  *DowntimeRecurrence `yaml:"recurrence,omitempty"`
+}
+
+// LoadServiceSpec loads and validates a service spec from a YAML (or JSON) file.
+// Relative template paths are resolved against the spec file's directory;
+// remote sources (https://, git::) are kept as they are
+func LoadServiceSpec(specFile string) (*ServiceSpec, error) {
+	data, err := os.ReadFile(specFile)
+	if err != nil {
+		return nil, fmt.Errorf("spec file not found: %s", specFile)
+	}
+
+	var spec ServiceSpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("invalid YAML in spec file %s: %v", specFile, err)
+	}
+
+	var problems []string
+	if spec.Service == "" {
+		problems = append(problems, "service is required")
+	}
+	if spec.Env == "" {
+		problems = append(problems, "env is required")
+	}
+	if spec.Namespace == "" {
+		problems = append(problems, "namespace is required")
+	}
+	if spec.ThresholdScale < 0 {
+		problems = append(problems, "threshold_scale must be positive")
+	}
+	for i, ref := range spec.Templates {
+		if ref.File == "" {
+			problems = append(problems, fmt.Sprintf("templates[%d]: file is required", i))
+			continue
+		}
+		if !filepath.IsAbs(ref.File) && !fetch.IsRemote(ref.File) {
+			spec.Templates[i].File = filepath.Join(filepath.Dir(specFile), ref.File)
+		}
+	}
+	for i, slo := range spec.SLOs {
+		if slo.Name == "" {
+			problems = append(problems, fmt.Sprintf("slos[%d]: name is required", i))
+		}
+		if len(slo.Thresholds) == 0 {
+			problems = append(problems, fmt.Sprintf("slos[%d]: at least one threshold is required", i))
+		}
+		switch slo.sloType() {
+		case "monitor":
+			if len(slo.Monitors) == 0 {
+				problems = append(problems, fmt.Sprintf("slos[%d]: monitor SLOs need at least one monitor reference", i))
+			}
+		case "metric":
+			if slo.Query == nil {
+				problems = append(problems, fmt.Sprintf("slos[%d]: metric SLOs need a query", i))
+			}
+		default:
+			problems = append(problems, fmt.Sprintf("slos[%d]: unknown type %q (must be monitor or metric)", i, slo.Type))
+		}
+	}
+	for i, downtime := range spec.Downtimes {
+		if downtime.Name == "" {
+			problems = append(problems, fmt.Sprintf("downtimes[%d]: name is required", i))
+		}
+		if len(downtime.Scope) == 0 {
+			problems = append(problems, fmt.Sprintf("downtimes[%d]: scope is required", i))
+		}
+		for _, value := range []string{downtime.Start, downtime.End} {
+			if value == "" {
+				continue
+			}
+			if _, err := time.Parse(time.RFC3339, value); err != nil {
+				problems = append(problems, fmt.Sprintf("downtimes[%d]: invalid time %q (use RFC3339)", i, value))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid spec file %s:\n  - %s", specFile, strings.Join(problems, "\n  - "))
+	}
+
+	return &spec, nil
+}
+
+// sloType returns the SLO type, defaulting to monitor
+func (s SpecSLO) sloType() string {
+	if s.Type == "" {
+		return "monitor"
+	}
+	return s.Type
+}
+
+// replacePlaceholders replaces {service}, {env} and {namespace}, with or
+// without a modifier ({env:upper}), in spec values
+func (spec *ServiceSpec) replacePlaceholders(s string) string {
+	return replaceBuiltinPlaceholders(s, spec.Service, spec.Env, spec.Namespace)
+}
+
+// MonitorRefs maps what SLOs reference monitors by, template and monitor
+// names, to the IDs of the monitors applied under that name. Two template
+// files with a template of the same name, or a file applied twice with other
+// vars, give a name several IDs.
+type MonitorRefs map[string][]int
+
+// Add records that the monitor with this ID was applied under name
+func (r MonitorRefs) Add(name string, id int) {
+	if name == "" || slices.Contains(r[name], id) {
+		return
+	}
+	r[name] = append(r[name], id)
+}
+
+// BuildSLO turns a spec SLO into an API SLO, resolving monitor references to
+// IDs via the given lookup. A reference matching several monitors is an error
+// rather than a guess.
+func (spec *ServiceSpec) BuildSLO(s SpecSLO, monitorRefs MonitorRefs) (*SLO, error) {
+	slo := &SLO{
+		Name:        spec.replacePlaceholders(s.Name),
+		Description: spec.replacePlaceholders(s.Description),
+		Type:        s.sloType(),
+		Thresholds:  s.Thresholds,
+		Tags:        spec.serviceTags(s.Tags),
+	}
+
+	if slo.Type == "metric" {
+		slo.Query = &SLOQuery{
+			Numerator:   spec.replacePlaceholders(s.Query.Numerator),
+			Denominator: spec.replacePlaceholders(s.Query.Denominator),
+		}
+		return slo, nil
+	}
+
+	for _, ref := range s.Monitors {
+		ids, ok := monitorRefs[ref]
+		if !ok {
+			ids, ok = monitorRefs[spec.replacePlaceholders(ref)]
+		}
+		if !ok {
+			return nil, fmt.Errorf("SLO %s references unknown monitor %q", slo.Name, ref)
+		}
+		if len(ids) > 1 {
+			idList := make([]string, len(ids))
+			for i, id := range ids {
+				idList[i] = strconv.Itoa(id)
+			}
+			return nil, fmt.Errorf("SLO %s references %q, which names %d monitors (IDs %s): several templates share the name, reference the monitor by its name instead", slo.Name, ref, len(ids), strings.Join(idList, ", "))
+		}
+		slo.MonitorIDs = append(slo.MonitorIDs, ids[0])
+	}
+	return slo, nil
+}
+
+// BuildDowntime turns a spec downtime into an API downtime. The name is
+// prefixed to the message so the downtime can be found again on reruns
+func (spec *ServiceSpec) BuildDowntime(d SpecDowntime) *Downtime {
+	message := fmt.Sprintf("[%s]", spec.replacePlaceholders(d.Name))
+	if d.Message != "" {
+		message += " " + spec.replacePlaceholders(d.Message)
+	}
+
+	downtime := &Downtime{
+		Message:    message,
+		Timezone:   d.Timezone,
+		Recurrence: d.Recurrence,
+	}
+	for _, scope := range d.Scope {
+		downtime.Scope = append(downtime.Scope, spec.replacePlaceholders(scope))
+	}
+	for _, tag := range d.MonitorTags {
+		downtime.MonitorTags = append(downtime.MonitorTags, spec.replacePlaceholders(tag))
+	}
+	// Times were validated when the spec was loaded
+	if t, err := time.Parse(time.RFC3339, d.Start); err == nil {
+		downtime.Start = t.Unix()
+	}
+	if t, err := time.Parse(time.RFC3339, d.End); err == nil {
+		downtime.End = t.Unix()
+	}
+	return downtime
+}
+
+// serviceTags returns the service/env/namespace tags plus spec-level and extra tags
+func (spec *ServiceSpec) serviceTags(extra []string) []string {
+	tags := []string{
+		fmt.Sprintf("service:%s", spec.Service),
+		fmt.Sprintf("env:%s", spec.Env),
+		fmt.Sprintf("namespace:%s", spec.Namespace),
+	}
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		seen[tag] = true
+	}
+	for _, tag := range append(append([]string{}, spec.Tags...), extra...) {
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,68 @@
+package datadog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildSLOMonitorRefs(t *testing.T) {
+	spec := &ServiceSpec{Service: "checkout", Env: "prd", Namespace: "shop"}
+	refs := make(MonitorRefs)
+	refs.Add("Error Rate", 1000)
+	refs.Add("Monitor checkout - Error Rate", 1000)
+	refs.Add("Latency", 1001)
+	refs.Add("Monitor checkout - Latency", 1001)
+	// A second template file with a template of the same name
+	refs.Add("Latency", 1002)
+	refs.Add("Monitor checkout - Latency (p99)", 1002)
+	// The same monitor added twice is one reference
+	refs.Add("Error Rate", 1000)
+	refs.Add("", 1003)
+
+	for _, tc := range []struct {
+		monitors []string
+		want     []int
+		err      string
+	}{
+		{[]string{"Error Rate"}, []int{1000}, ""},
+		{[]string{"Monitor {service} - Error Rate", "Monitor checkout - Latency (p99)"}, []int{1000, 1002}, ""},
+		{[]string{"Latency"}, nil, `references "Latency", which names 2 monitors (IDs 1001, 1002)`},
+		{[]string{"Disk"}, nil, `references unknown monitor "Disk"`},
+		{[]string{""}, nil, `references unknown monitor ""`},
+	} {
+		slo, err := spec.BuildSLO(SpecSLO{Name: "{service} availability", Monitors: tc.monitors}, refs)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("BuildSLO(%q) = %v, want %q", tc.monitors, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("BuildSLO(%q): %v", tc.monitors, err)
+			continue
+		}
+		if slo.Name != "checkout availability" || !reflect.DeepEqual(slo.MonitorIDs, tc.want) {
+			t.Errorf("BuildSLO(%q) = %s with monitors %v, want %v", tc.monitors, slo.Name, slo.MonitorIDs, tc.want)
+		}
+	}
+}
+
+func TestBuildDowntime(t *testing.T) {
+	spec := &ServiceSpec{Service: "checkout", Env: "prd", Namespace: "shop"}
+	downtime := spec.BuildDowntime(SpecDowntime{
+		Name:    "{service} deploys",
+		Scope:   []string{"env:{env}"},
+		Message: "Weekly deploys of {service}",
+		Start:   "2024-05-01T10:00:00Z",
+	})
+	if downtime.Message != "[checkout deploys] Weekly deploys of checkout" || !reflect.DeepEqual(downtime.Scope, []string{"env:prd"}) || downtime.Start != 1714557600 {
+		t.Errorf("BuildDowntime = %+v", downtime)
+	}
+	if got := downtimeKey(downtime.Message); got != "[checkout deploys]" {
+		t.Errorf("downtime identified by %q", got)
+	}
+	if got := spec.BuildDowntime(SpecDowntime{Name: "freeze", Scope: []string{"*"}}).Message; got != "[freeze]" {
+		t.Errorf("downtime without a message has message %q", got)
+	}
+}
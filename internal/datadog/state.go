@@ -0,0 +1,163 @@
+package datadog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/paths"
+)
+
+// ApplyStateVersion is the version of the apply state document format
+const ApplyStateVersion = 1
+
+// MonitorHash returns the hash of a rendered monitor: name, type, query,
+// message, tags (order-insensitive) and options. Any change to what an apply
+// sends changes it; it is not written to the monitor (see AppliedMonitor for
+// what can be cross-checked against the monitors).
+func MonitorHash(monitor Monitor) string {
+	tags := append([]string(nil), monitor.Tags...)
+	sort.Strings(tags)
+
+	// encoding/json sorts map keys, so options serialize deterministically
+	data, _ := json.Marshal(struct {
+		Name    string                 `json:"name"`
+		Type    string                 `json:"type"`
+		Query   string                 `json:"query"`
+		Message string                 `json:"message"`
+		Tags    []string               `json:"tags"`
+		Options map[string]interface{} `json:"options"`
+	}{monitor.Name, monitor.Type, monitor.Query, monitor.Message, tags, monitor.Options})
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// ApplyState records what the template command last applied, so an identical
+// apply can be skipped without calling the API
+type ApplyState struct {
+	Version int                     `json:"version"`
+	Targets map[string]*ApplyTarget `json:"targets"`
+}
+
+// ApplyTarget is the last apply for one service/env/namespace
+type ApplyTarget struct {
+	AppliedAt time.Time `json:"applied_at"`
+	// Monitors maps monitor names to their ID and rendered hash
+	Monitors map[string]AppliedMonitor `json:"monitors"`
+}
+
+// AppliedMonitor is one monitor of an ApplyTarget. Hash is its MonitorHash;
+// Fingerprint is the MonitorFingerprint the monitor carries in its
+// ddmm-fingerprint tag, so the state file can be cross-checked against the
+// monitors themselves.
+type AppliedMonitor struct {
+	ID          int    `json:"id"`
+	Hash        string `json:"hash"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// ApplyTargetKey identifies the service/env/namespace of an apply in the state file
+func ApplyTargetKey(opts RenderOptions) string {
+	return fmt.Sprintf("%s/%s/%s", opts.Service, opts.Env, opts.Namespace)
+}
+
+// NewApplyState returns an empty apply state
+func NewApplyState() *ApplyState {
+	return &ApplyState{Version: ApplyStateVersion, Targets: make(map[string]*ApplyTarget)}
+}
+
+// LoadApplyState reads an apply state file. A missing file yields an empty
+// state; a corrupt or version-mismatched file is an error.
+func LoadApplyState(path string) (*ApplyState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewApplyState(), nil
+		}
+		return nil, fmt.Errorf("failed to read state file %s: %v", path, err)
+	}
+
+	var state ApplyState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid state file %s: %v", path, err)
+	}
+	if state.Version != ApplyStateVersion {
+		return nil, fmt.Errorf("state file %s has version %d, expected %d", path, state.Version, ApplyStateVersion)
+	}
+	if state.Targets == nil {
+		state.Targets = make(map[string]*ApplyTarget)
+	}
+	return &state, nil
+}
+
+// Save writes the state file atomically
+func (s *ApplyState) Save(path string) error {
+	return writeJSONFile(path, s, ".ddmm-state-*")
+}
+
+// writeJSONFile writes v as indented JSON to path atomically, through a
+// temporary file matching pattern in the same directory
+func writeJSONFile(path string, v interface{}, pattern string) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := paths.EnsureParent(path); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), pattern)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// UpToDate reports whether every rendered monitor was last applied for key
+// with the same hash
+func (s *ApplyState) UpToDate(key string, rendered []RenderedMonitor) bool {
+	target, ok := s.Targets[key]
+	if !ok || len(rendered) == 0 {
+		return false
+	}
+	for _, r := range rendered {
+		applied, ok := target.Monitors[r.Monitor.Name]
+		if !ok || applied.ID == 0 || applied.Hash != MonitorHash(r.Monitor) {
+			return false
+		}
+	}
+	return true
+}
+
+// Record replaces the state of key with the monitors applied now. results are
+// matched to rendered monitors by name; failed templates are left out so the
+// next run applies them again.
+func (s *ApplyState) Record(key string, rendered []RenderedMonitor, results []ApplyResult, now time.Time) {
+	monitors := make(map[string]Monitor, len(rendered))
+	for _, r := range rendered {
+		monitors[r.Monitor.Name] = r.Monitor
+	}
+
+	target := &ApplyTarget{AppliedAt: now.UTC(), Monitors: make(map[string]AppliedMonitor)}
+	for _, result := range results {
+		if monitor, ok := monitors[result.Name]; ok && result.Err == nil {
+			target.Monitors[result.Name] = AppliedMonitor{ID: result.ID, Hash: MonitorHash(monitor), Fingerprint: MonitorFingerprint(monitor)}
+		}
+	}
+	s.Targets[key] = target
+}
@@ -0,0 +1,170 @@
+package datadog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func stateMonitor(name, query string) RenderedMonitor {
+	return RenderedMonitor{TemplateName: name, Monitor: Monitor{
+		Name:    name,
+		Type:    "metric alert",
+		Query:   query,
+		Message: "@slack-ops",
+		Tags:    []string{"service:api", "env:prd"},
+		Options: map[string]interface{}{"thresholds": map[string]interface{}{"critical": 90.0}},
+	}}
+}
+
+func TestMonitorHash(t *testing.T) {
+	base := stateMonitor("CPU", "avg(last_5m):avg:cpu{*} > 90").Monitor
+	hash := MonitorHash(base)
+	if len(hash) != 16 {
+		t.Errorf("hash %q, want 16 hex characters", hash)
+	}
+
+	reordered := base
+	reordered.Tags = []string{"env:prd", "service:api"}
+	if MonitorHash(reordered) != hash {
+		t.Error("the tag order changed the hash")
+	}
+	for name, edit := range map[string]func(m *Monitor){
+		"message": func(m *Monitor) { m.Message = "@slack-other" },
+		"tags":    func(m *Monitor) { m.Tags = []string{"service:api"} },
+		"options": func(m *Monitor) { m.Options = map[string]interface{}{"notify_no_data": true} },
+		"query":   func(m *Monitor) { m.Query = "avg(last_5m):avg:cpu{*} > 95" },
+	} {
+		edited := base
+		edit(&edited)
+		if MonitorHash(edited) == hash {
+			t.Errorf("changing the %s kept the hash", name)
+		}
+	}
+}
+
+func TestApplyStateRecord(t *testing.T) {
+	cpu := stateMonitor("CPU", "avg(last_5m):avg:cpu{*} > 90")
+	disk := stateMonitor("Disk", "avg(last_5m):avg:disk{*} > 90")
+	addFingerprintTag(&cpu.Monitor)
+	rendered := []RenderedMonitor{cpu, disk}
+	results := []ApplyResult{
+		{Name: "CPU", ID: 1000, Status: StatusCreated},
+		{Name: "Disk", ID: 1001, Status: StatusFailed, Err: errors.New("status 500")},
+		// A result without a rendered monitor is ignored
+		{Name: "Memory", ID: 1002, Status: StatusCreated},
+	}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*3600))
+
+	state := NewApplyState()
+	state.Record("api/prd/shop", rendered, results, now)
+	target := state.Targets["api/prd/shop"]
+	if target == nil || !target.AppliedAt.Equal(now) || target.AppliedAt.Location() != time.UTC {
+		t.Fatalf("target %+v", target)
+	}
+	// The failed monitor is left out, so the next run applies it again
+	want := AppliedMonitor{ID: 1000, Hash: MonitorHash(cpu.Monitor), Fingerprint: MonitorFingerprint(cpu.Monitor)}
+	if len(target.Monitors) != 1 || target.Monitors["CPU"] != want {
+		t.Errorf("recorded monitors %+v, want CPU %+v", target.Monitors, want)
+	}
+	// The recorded fingerprint is the one the monitor is tagged with
+	if tag := fingerprintTag(cpu.Monitor); tag != FingerprintTagKey+":"+target.Monitors["CPU"].Fingerprint {
+		t.Errorf("monitor tagged %q, state has fingerprint %q", tag, target.Monitors["CPU"].Fingerprint)
+	}
+	if state.UpToDate("api/prd/shop", rendered) {
+		t.Error("up to date with a failed monitor")
+	}
+	if !state.UpToDate("api/prd/shop", []RenderedMonitor{cpu}) {
+		t.Error("not up to date with the recorded monitor")
+	}
+
+	// Recording again replaces the target
+	results[1] = ApplyResult{Name: "Disk", ID: 1001, Status: StatusCreated}
+	state.Record("api/prd/shop", rendered, results, now)
+	if !state.UpToDate("api/prd/shop", rendered) {
+		t.Error("not up to date once every monitor was applied")
+	}
+}
+
+func TestApplyStateUpToDate(t *testing.T) {
+	cpu := stateMonitor("CPU", "avg(last_5m):avg:cpu{*} > 90")
+	state := NewApplyState()
+	state.Targets["api/prd/shop"] = &ApplyTarget{Monitors: map[string]AppliedMonitor{
+		"CPU": {ID: 1000, Hash: MonitorHash(cpu.Monitor)},
+	}}
+	changed := stateMonitor("CPU", "avg(last_5m):avg:cpu{*} > 95")
+	added := stateMonitor("Disk", "avg(last_5m):avg:disk{*} > 90")
+
+	for _, tc := range []struct {
+		name     string
+		key      string
+		rendered []RenderedMonitor
+		want     bool
+	}{
+		{"same", "api/prd/shop", []RenderedMonitor{cpu}, true},
+		{"changed", "api/prd/shop", []RenderedMonitor{changed}, false},
+		{"new monitor", "api/prd/shop", []RenderedMonitor{cpu, added}, false},
+		{"other target", "api/stg/shop", []RenderedMonitor{cpu}, false},
+		{"nothing rendered", "api/prd/shop", nil, false},
+	} {
+		if got := state.UpToDate(tc.key, tc.rendered); got != tc.want {
+			t.Errorf("%s: UpToDate = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	// An entry without an ID is never up to date
+	state.Targets["api/prd/shop"].Monitors["CPU"] = AppliedMonitor{Hash: MonitorHash(cpu.Monitor)}
+	if state.UpToDate("api/prd/shop", []RenderedMonitor{cpu}) {
+		t.Error("up to date without a monitor ID")
+	}
+}
+
+func TestLoadApplyState(t *testing.T) {
+	dir := t.TempDir()
+
+	state, err := LoadApplyState(filepath.Join(dir, "missing.json"))
+	if err != nil || state.Version != ApplyStateVersion || len(state.Targets) != 0 {
+		t.Fatalf("LoadApplyState of a missing file = %+v, %v", state, err)
+	}
+
+	// Round trip
+	cpu := stateMonitor("CPU", "avg(last_5m):avg:cpu{*} > 90")
+	state.Record("api/prd/shop", []RenderedMonitor{cpu}, []ApplyResult{{Name: "CPU", ID: 1000}}, time.Now())
+	path := filepath.Join(dir, "nested", "state.json")
+	if err := state.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadApplyState(path)
+	if err != nil || !loaded.UpToDate("api/prd/shop", []RenderedMonitor{cpu}) {
+		t.Errorf("loaded state %+v, %v", loaded, err)
+	}
+
+	for _, tc := range []struct {
+		name, content, want string
+	}{
+		{"corrupt", `{"version": 1, "targets": `, "invalid state file"},
+		{"not an object", `[1, 2]`, "invalid state file"},
+		{"newer version", `{"version": 2, "targets": {}}`, "has version 2, expected 1"},
+		{"no version", `{"targets": {}}`, "has version 0, expected 1"},
+	} {
+		path := filepath.Join(dir, tc.name+".json")
+		if err := os.WriteFile(path, []byte(tc.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadApplyState(path); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: LoadApplyState = %v, want %q", tc.name, err, tc.want)
+		}
+	}
+
+	// A state without targets gets an empty map
+	path = filepath.Join(dir, "empty.json")
+	if err := os.WriteFile(path, []byte(`{"version": 1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if state, err := LoadApplyState(path); err != nil || state.Targets == nil {
+		t.Errorf("LoadApplyState without targets = %+v, %v", state, err)
+	}
+}
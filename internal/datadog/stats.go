@@ -0,0 +1,265 @@
+package datadog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Stats collects statistics about the API calls of one or more clients:
+// requests by method, endpoint and status, retries, bytes and timing. It also
+// counts them against Limits. It is safe for concurrent use.
+type Stats struct {
+	mu            sync.Mutex
+	started       time.Time
+	requests      map[requestKey]*EndpointStats
+	retries       int
+	bytesSent     int64
+	bytesReceived int64
+
+	limits   Limits
+	sent     int
+	failures int
+}
+
+// Limits stop bulk operations early. Zero means unlimited.
+type Limits struct {
+	// RequestBudget is the number of requests the clients sharing the Stats
+	// may send; further requests fail with a *LimitError
+	RequestBudget int
+	// FailFast is the number of failed operations after which bulk loops stop
+	FailFast int
+}
+
+// LimitError is returned once a limit is reached: the request budget is
+// spent, or FailFast operations failed
+type LimitError struct {
+	RequestBudget int
+	FailFast      int
+}
+
+func (e *LimitError) Error() string {
+	if e.RequestBudget > 0 {
+		return fmt.Sprintf("request budget of %d API call(s) spent", e.RequestBudget)
+	}
+	return fmt.Sprintf("stopped after %d failure(s)", e.FailFast)
+}
+
+// IsLimitExceeded reports whether err comes from a reached limit
+func IsLimitExceeded(err error) bool {
+	var limitErr *LimitError
+	return errors.As(err, &limitErr)
+}
+
+// requestKey groups requests in the summary
+type requestKey struct {
+	method   string
+	endpoint string
+	status   int
+}
+
+// EndpointStats are the statistics of the requests with the same method,
+// endpoint and status. Status 0 means the request got no response.
+type EndpointStats struct {
+	Method     string  `json:"method"`
+	Endpoint   string  `json:"endpoint"`
+	Status     int     `json:"status"`
+	Count      int     `json:"count"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// StatsSummary is a snapshot of Stats
+type StatsSummary struct {
+	Started       time.Time       `json:"started"`
+	WallTimeMS    float64         `json:"wall_time_ms"`
+	Requests      int             `json:"requests"`
+	Errors        int             `json:"errors"`
+	Retries       int             `json:"retries"`
+	BytesSent     int64           `json:"bytes_sent"`
+	BytesReceived int64           `json:"bytes_received"`
+	APITimeMS     float64         `json:"api_time_ms"`
+	Endpoints     []EndpointStats `json:"endpoints"`
+}
+
+// NewStats starts collecting statistics; the wall time is measured from now
+func NewStats() *Stats {
+	return &Stats{started: time.Now(), requests: make(map[requestKey]*EndpointStats)}
+}
+
+// SetLimits sets the limits the requests and failures are counted against
+func (s *Stats) SetLimits(limits Limits) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limits = limits
+}
+
+// ResetLimits starts counting requests and failures against the limits
+// afresh, as for a new run; the statistics themselves are kept
+func (s *Stats) ResetLimits() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = 0
+	s.failures = 0
+}
+
+// RecordFailure counts a failed operation of a bulk loop against
+// Limits.FailFast
+func (s *Stats) RecordFailure() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures++
+}
+
+// Exceeded returns a *LimitError once the request budget is spent or the
+// failure limit reached, so bulk loops stop before their next operation
+func (s *Stats) Exceeded() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.limits.RequestBudget > 0 && s.sent >= s.limits.RequestBudget {
+		return &LimitError{RequestBudget: s.limits.RequestBudget}
+	}
+	if s.limits.FailFast > 0 && s.failures >= s.limits.FailFast {
+		return &LimitError{FailFast: s.limits.FailFast}
+	}
+	return nil
+}
+
+// reserve counts a request about to be sent against the request budget, or
+// returns a *LimitError when the budget is spent
+func (s *Stats) reserve() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.limits.RequestBudget > 0 && s.sent >= s.limits.RequestBudget {
+		return &LimitError{RequestBudget: s.limits.RequestBudget}
+	}
+	s.sent++
+	return nil
+}
+
+// recordRequest adds a finished request; status is 0 when it got no response
+func (s *Stats) recordRequest(req *http.Request, status int, duration time.Duration) {
+	key := requestKey{method: req.Method, endpoint: statsEndpoint(req.URL.Path), status: status}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.requests[key]
+	if !ok {
+		entry = &EndpointStats{Method: key.method, Endpoint: key.endpoint, Status: status}
+		s.requests[key] = entry
+	}
+	entry.Count++
+	entry.DurationMS += float64(duration) / float64(time.Millisecond)
+	if req.ContentLength > 0 {
+		s.bytesSent += req.ContentLength
+	}
+}
+
+// recordRetry counts a request sent again by the client
+func (s *Stats) recordRetry() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retries++
+}
+
+// addReceived counts response body bytes as they are read
+func (s *Stats) addReceived(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bytesReceived += int64(n)
+}
+
+// countBody makes the response body count the bytes read from it, as sent
+// over the wire (before gzip decompression)
+func (s *Stats) countBody(resp *http.Response) {
+	resp.Body = &countingBody{ReadCloser: resp.Body, stats: s}
+}
+
+// countingBody is a response body that reports the bytes read to Stats
+type countingBody struct {
+	io.ReadCloser
+	stats *Stats
+}
+
+// Read implements io.Reader
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.stats.addReceived(n)
+	}
+	return n, err
+}
+
+// Summary returns a snapshot of the statistics, endpoints sorted by request
+// count and then by endpoint
+func (s *Stats) Summary() StatsSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summary := StatsSummary{
+		Started:       s.started,
+		WallTimeMS:    float64(time.Since(s.started)) / float64(time.Millisecond),
+		Retries:       s.retries,
+		BytesSent:     s.bytesSent,
+		BytesReceived: s.bytesReceived,
+		Endpoints:     []EndpointStats{},
+	}
+	for _, entry := range s.requests {
+		summary.Endpoints = append(summary.Endpoints, *entry)
+		summary.Requests += entry.Count
+		summary.APITimeMS += entry.DurationMS
+		if entry.Status == 0 || entry.Status >= 400 {
+			summary.Errors += entry.Count
+		}
+	}
+	sort.Slice(summary.Endpoints, func(i, j int) bool {
+		a, b := summary.Endpoints[i], summary.Endpoints[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Endpoint != b.Endpoint {
+			return a.Endpoint < b.Endpoint
+		}
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		return a.Status < b.Status
+	})
+	return summary
+}
+
+// statsEndpoint groups request paths by endpoint, replacing IDs with {id}
+// (e.g., /api/v1/monitor/123 becomes /api/v1/monitor/{id})
+func statsEndpoint(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if isPathID(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// isPathID reports whether a path segment is an ID: a number, or a long
+// alphanumeric string with digits such as an SLO ID. API versions (v1, v2) are not.
+func isPathID(segment string) bool {
+	if segment == "" {
+		return false
+	}
+	digits := 0
+	for _, r := range segment {
+		switch {
+		case unicode.IsDigit(r):
+			digits++
+		case unicode.IsLetter(r) || r == '-':
+		default:
+			return false
+		}
+	}
+	return digits == len(segment) || (digits > 0 && len(segment) >= 16)
+}
@@ -0,0 +1,132 @@
+package datadog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStatsResetLimits(t *testing.T) {
+	s := NewStats()
+	s.SetLimits(Limits{RequestBudget: 2, FailFast: 1})
+	req, _ := http.NewRequest(http.MethodGet, "https://api.datadoghq.com/api/v1/monitor/1", nil)
+	for i := 0; i < 2; i++ {
+		if err := s.reserve(); err != nil {
+			t.Fatalf("request %d: %v", i+1, err)
+		}
+		s.recordRequest(req, http.StatusOK, time.Millisecond)
+	}
+	if err := s.reserve(); !IsLimitExceeded(err) {
+		t.Fatalf("third request = %v, want a LimitError", err)
+	}
+	s.RecordFailure()
+	if err := s.Exceeded(); !IsLimitExceeded(err) {
+		t.Fatalf("Exceeded = %v, want a LimitError", err)
+	}
+
+	s.ResetLimits()
+	if err := s.Exceeded(); err != nil {
+		t.Fatalf("Exceeded after ResetLimits = %v", err)
+	}
+	if err := s.reserve(); err != nil {
+		t.Fatalf("request after ResetLimits: %v", err)
+	}
+	// The statistics are kept
+	if summary := s.Summary(); summary.Requests != 2 {
+		t.Errorf("Summary().Requests = %d, want 2", summary.Requests)
+	}
+}
+
+func TestStatsConcurrentClients(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"id": 1, "name": "CPU"}`))
+	}))
+	defer srv.Close()
+
+	stats := NewStats()
+	const clients, perClient = 4, 25
+	var wg sync.WaitGroup
+	for i := 0; i < clients; i++ {
+		client, err := NewClientWithOptions(WithAPIKey("api-key"), WithAppKey("app-key"), WithBaseURL(srv.URL+"/api/v1"), WithStats(stats))
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perClient; j++ {
+				if _, err := client.GetMonitor(1000 + j); err != nil {
+					t.Error(err)
+				}
+				client.DeleteMonitor(1000 + j)
+				stats.Summary()
+			}
+		}()
+	}
+	wg.Wait()
+
+	summary := stats.Summary()
+	if summary.Requests != 2*clients*perClient || summary.Errors != clients*perClient {
+		t.Errorf("%d requests, %d errors, want %d and %d", summary.Requests, summary.Errors, 2*clients*perClient, clients*perClient)
+	}
+	if want := int64(clients * perClient * len(`{"id": 1, "name": "CPU"}`)); summary.BytesReceived != want {
+		t.Errorf("%d bytes received, want %d", summary.BytesReceived, want)
+	}
+	want := []EndpointStats{
+		{Method: "DELETE", Endpoint: "/api/v1/monitor/{id}", Status: 404, Count: clients * perClient},
+		{Method: "GET", Endpoint: "/api/v1/monitor/{id}", Status: 200, Count: clients * perClient},
+	}
+	for i := range summary.Endpoints {
+		summary.Endpoints[i].DurationMS = 0
+	}
+	if !reflect.DeepEqual(summary.Endpoints, want) {
+		t.Errorf("endpoints %+v, want %+v", summary.Endpoints, want)
+	}
+}
+
+func TestStatsConcurrentBudget(t *testing.T) {
+	s := NewStats()
+	s.SetLimits(Limits{RequestBudget: 50})
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	reserved := 0
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if s.reserve() == nil {
+					mu.Lock()
+					reserved++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if reserved != 50 {
+		t.Errorf("%d requests reserved, want the budget of 50", reserved)
+	}
+}
+
+func TestStatsEndpoint(t *testing.T) {
+	for path, want := range map[string]string{
+		"/api/v1/monitor":                              "/api/v1/monitor",
+		"/api/v1/monitor/123":                          "/api/v1/monitor/{id}",
+		"/api/v1/monitor/123/mute":                     "/api/v1/monitor/{id}/mute",
+		"/api/v1/slo/abc123def456abc123def456abc12345": "/api/v1/slo/{id}",
+		"/api/v2/monitor/policy":                       "/api/v2/monitor/policy",
+		"/api/v1/monitor/can_delete":                   "/api/v1/monitor/can_delete",
+	} {
+		if got := statsEndpoint(path); got != want {
+			t.Errorf("statsEndpoint(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package datadog
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NoEnv is the status overview row for monitors without an env tag
+const NoEnv = "(no env)"
+
+// StatusStates are the monitor states counted in their own column of the status
+// overview, most severe first; any other state is counted as OtherState
+var StatusStates = []string{"Alert", "Warn", "No Data", "OK"}
+
+// OtherState is the status overview column for states not in StatusStates
+const OtherState = "Other"
+
+// StatusRow counts the monitors of one env (and priority) by state
+type StatusRow struct {
+	Env string `json:"env"`
+	// Priority is P1 to P5, "-" for monitors without one; empty when the
+	// overview isn't broken down by priority
+	Priority string         `json:"priority,omitempty"`
+	States   map[string]int `json:"states"`
+	Total    int            `json:"total"`
+}
+
+// AlertingMonitor is a monitor in the Alert state
+type AlertingMonitor struct {
+	ID       int      `json:"id"`
+	Name     string   `json:"name"`
+	Envs     []string `json:"envs"`
+	Priority int      `json:"priority,omitempty"`
+	URL      string   `json:"url,omitempty"`
+}
+
+// StatusOverview is the monitor counts by state × env (× priority when any
+// monitor has one) and the monitors currently alerting
+type StatusOverview struct {
+	Generated  time.Time         `json:"generated"`
+	Total      int               `json:"total"`
+	States     map[string]int    `json:"states"`
+	ByPriority bool              `json:"by_priority"`
+	Rows       []StatusRow       `json:"rows"`
+	Alerting   []AlertingMonitor `json:"alerting"`
+}
+
+// MonitorPriority returns the priority of a monitor from 1 (highest) to 5:
+// the priority field, or else a priority:p1 / priority:1 tag. It returns 0
+// when the monitor has none.
+func MonitorPriority(monitor Monitor) int {
+	if monitor.Priority != nil {
+		return *monitor.Priority
+	}
+	for _, value := range MonitorTagValues(monitor, "priority", "") {
+		if p, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(value), "p")); err == nil && p >= 1 && p <= 5 {
+			return p
+		}
+	}
+	return 0
+}
+
+// priorityLabel formats a priority as P1 to P5, or "-" when unset
+func priorityLabel(priority int) string {
+	if priority == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("P%d", priority)
+}
+
+// statusColumn returns the column a monitor state is counted in
+func statusColumn(state string) string {
+	for _, column := range StatusStates {
+		if state == column {
+			return column
+		}
+	}
+	return OtherState
+}
+
+// BuildStatusOverview aggregates monitors by state and env tag, and by priority
+// when any monitor has one. A monitor with several env tags counts towards
+// each env row, but only once in the totals. appURL is the Datadog web app URL
+// alerting monitors are linked to; empty leaves the links out.
+func BuildStatusOverview(monitors []Monitor, appURL string) StatusOverview {
+	overview := StatusOverview{
+		Generated: time.Now(),
+		Total:     len(monitors),
+		States:    make(map[string]int),
+		Rows:      []StatusRow{},
+		Alerting:  []AlertingMonitor{},
+	}
+	for _, monitor := range monitors {
+		if MonitorPriority(monitor) != 0 {
+			overview.ByPriority = true
+			break
+		}
+	}
+
+	rows := make(map[[2]string]*StatusRow)
+	for _, monitor := range monitors {
+		column := statusColumn(monitor.OverallState)
+		overview.States[column]++
+
+		priority := MonitorPriority(monitor)
+		envs := MonitorTagValues(monitor, "env", NoEnv)
+		for _, env := range envs {
+			key := [2]string{env, ""}
+			if overview.ByPriority {
+				key[1] = priorityLabel(priority)
+			}
+			row, ok := rows[key]
+			if !ok {
+				row = &StatusRow{Env: key[0], Priority: key[1], States: make(map[string]int)}
+				rows[key] = row
+			}
+			row.States[column]++
+			row.Total++
+		}
+
+		if monitor.OverallState == "Alert" {
+			alerting := AlertingMonitor{ID: monitor.ID, Name: monitor.Name, Envs: envs, Priority: priority}
+			if appURL != "" {
+				alerting.URL = fmt.Sprintf("%s/monitors/%d", appURL, monitor.ID)
+			}
+			overview.Alerting = append(overview.Alerting, alerting)
+		}
+	}
+
+	for _, row := range rows {
+		overview.Rows = append(overview.Rows, *row)
+	}
+	sort.Slice(overview.Rows, func(i, j int) bool {
+		a, b := overview.Rows[i], overview.Rows[j]
+		if a.Env != b.Env {
+			if (a.Env == NoEnv) != (b.Env == NoEnv) {
+				return b.Env == NoEnv
+			}
+			return a.Env < b.Env
+		}
+		// "-" (no priority) sorts after P1 to P5
+		if a.Priority == "-" || b.Priority == "-" {
+			return b.Priority == "-" && a.Priority != "-"
+		}
+		return a.Priority < b.Priority
+	})
+	// Highest priority first; monitors without a priority last
+	sort.SliceStable(overview.Alerting, func(i, j int) bool {
+		a, b := overview.Alerting[i], overview.Alerting[j]
+		if a.Priority != b.Priority {
+			return a.Priority != 0 && (b.Priority == 0 || a.Priority < b.Priority)
+		}
+		return a.Name < b.Name
+	})
+	return overview
+}
+
+// AppURL returns the Datadog web app URL of the configured site, derived from
+// the API URL (e.g., https://api.datadoghq.eu/api/v1 gives https://app.datadoghq.eu)
+func (c *Client) AppURL() string {
+	u, err := url.Parse(c.config.APIURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	host := u.Host
+	if site, ok := strings.CutPrefix(host, "api."); ok {
+		host = site
+		if strings.Count(site, ".") == 1 {
+			// datadoghq.com and datadoghq.eu are served from app.; other sites
+			// (us3.datadoghq.com, ...) from the site host itself
+			host = "app." + site
+		}
+	}
+	return fmt.Sprintf("%s://%s", u.Scheme, host)
+}
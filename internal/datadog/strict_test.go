@@ -0,0 +1,111 @@
+package datadog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// monitorStore is a minimal monitor API listing and creating monitors
+type monitorStore struct {
+	mu       sync.Mutex
+	monitors []Monitor
+	lists    int
+	posts    []string
+}
+
+func (s *monitorStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.Method {
+	case "GET":
+		s.lists++
+		json.NewEncoder(w).Encode(s.monitors)
+	case "POST":
+		var monitor Monitor
+		json.NewDecoder(r.Body).Decode(&monitor)
+		monitor.ID = 1000 + len(s.monitors)
+		s.monitors = append(s.monitors, monitor)
+		s.posts = append(s.posts, monitor.Name)
+		json.NewEncoder(w).Encode(monitor)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestCreateMonitorStrict(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		existing []string
+		create   string
+		policy   NameConflictPolicy
+		// want is the name created, "" when creation fails
+		want  string
+		lists int
+	}{
+		{"free name", []string{"CPU"}, "Disk", ConflictFail, "Disk", 1},
+		{"taken name", []string{"Disk", "CPU"}, "CPU", ConflictFail, "", 1},
+		// Names are compared exactly
+		{"other case", []string{"cpu"}, "CPU", ConflictFail, "CPU", 1},
+		{"duplicate allowed", []string{"CPU"}, "CPU", ConflictAllowDuplicate, "CPU", 0},
+		{"suffix", []string{"CPU"}, "CPU", ConflictSuffix, "CPU (2)", 1},
+		{"next free suffix", []string{"CPU", "CPU (2)", "CPU (4)"}, "CPU", ConflictSuffix, "CPU (3)", 1},
+		{"suffix of a free name", []string{"CPU (2)"}, "CPU", ConflictSuffix, "CPU", 1},
+	} {
+		store := &monitorStore{}
+		for i, name := range tc.existing {
+			store.monitors = append(store.monitors, Monitor{ID: i + 1, Name: name})
+		}
+		srv := httptest.NewServer(store)
+		client, err := NewClientWithOptions(WithAPIKey("api-key"), WithAppKey("app-key"), WithBaseURL(srv.URL))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		monitor := &Monitor{Name: tc.create, Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90"}
+		created, err := client.CreateMonitorStrict(monitor, tc.policy)
+		srv.Close()
+		if tc.want == "" {
+			if !IsMonitorExists(err) || len(store.posts) != 0 {
+				t.Errorf("%s: error %v, created %q", tc.name, err, store.posts)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if created.Name != tc.want || strings.Join(store.posts, ",") != tc.want {
+			t.Errorf("%s: created %q (posted %q), want %q", tc.name, created.Name, store.posts, tc.want)
+		}
+		if store.lists != tc.lists {
+			t.Errorf("%s: listed monitors %d time(s), want %d", tc.name, store.lists, tc.lists)
+		}
+		// The monitor passed in keeps its name
+		if monitor.Name != tc.create {
+			t.Errorf("%s: monitor renamed to %q", tc.name, monitor.Name)
+		}
+	}
+}
+
+func TestMonitorExistsError(t *testing.T) {
+	store := &monitorStore{monitors: []Monitor{{ID: 123, Name: "CPU"}, {ID: 456, Name: "CPU"}}}
+	srv := httptest.NewServer(store)
+	defer srv.Close()
+	client, err := NewClientWithOptions(WithAPIKey("api-key"), WithAppKey("app-key"), WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.CreateMonitorStrict(&Monitor{Name: "CPU"}, ConflictFail)
+	// The first monitor with the name is reported
+	if want := `monitor "CPU" already exists (ID 123), use upsert or --allow-duplicate`; err == nil || err.Error() != want {
+		t.Errorf("error %v, want %q", err, want)
+	}
+	if IsMonitorExists(nil) || IsMonitorExists(&ErrMonitorNotFound{}) {
+		t.Error("IsMonitorExists matched other errors")
+	}
+}
@@ -0,0 +1,289 @@
+package datadog
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TagIndex maps tag keys to the values seen on monitors and how many monitors
+// carry each value (e.g. index["env"]["prd"] = 42)
+type TagIndex map[string]map[string]int
+
+// BuildTagIndex indexes the key:value tags of the given monitors
+func BuildTagIndex(monitors []Monitor) TagIndex {
+	index := make(TagIndex)
+	for _, monitor := range monitors {
+		for _, tag := range monitor.Tags {
+			key, value, ok := strings.Cut(tag, ":")
+			if !ok {
+				continue
+			}
+			if index[key] == nil {
+				index[key] = make(map[string]int)
+			}
+			index[key][value]++
+		}
+	}
+	return index
+}
+
+// Values returns the sorted distinct values of a tag key
+func (idx TagIndex) Values(key string) []string {
+	values := make([]string, 0, len(idx[key]))
+	for value := range idx[key] {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// Has reports whether any monitor has the tag key:value
+func (idx TagIndex) Has(key, value string) bool {
+	return idx[key][value] > 0
+}
+
+// TagCount is a tag and the number of monitors carrying it
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// CountTags counts the monitors carrying each tag, most common first (ties by
+// tag). With key, only the tags of that key are counted.
+func CountTags(monitors []Monitor, key string) []TagCount {
+	counts := make(map[string]int)
+	for _, monitor := range monitors {
+		seen := make(map[string]bool)
+		for _, tag := range monitor.Tags {
+			if seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			if tagKey, _, _ := strings.Cut(tag, ":"); key != "" && tagKey != key {
+				continue
+			}
+			counts[tag]++
+		}
+	}
+
+	result := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		result = append(result, TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Tag < result[j].Tag
+	})
+	return result
+}
+
+// TagValueIndex lists all monitors in the organization and indexes their tags
+func (c *Client) TagValueIndex() (TagIndex, error) {
+	monitors, err := c.ListMonitors(nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return BuildTagIndex(monitors), nil
+}
+
+// SuggestClosest returns the candidate closest to value, or an empty string
+// when none is close enough. Case-only differences and prefixes match first,
+// then the smallest edit distance within a third of the value's length (at least 2).
+func SuggestClosest(value string, candidates []string) string {
+	lower := strings.ToLower(value)
+	for _, candidate := range candidates {
+		if strings.ToLower(candidate) == lower {
+			return candidate
+		}
+	}
+
+	maxDistance := len([]rune(value)) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	best := ""
+	bestDistance := maxDistance + 1
+	for _, candidate := range candidates {
+		candidateLower := strings.ToLower(candidate)
+		distance := levenshtein(lower, candidateLower)
+		if len(lower) >= 3 && (strings.HasPrefix(candidateLower, lower) || strings.HasPrefix(lower, candidateLower)) {
+			// Treat prefixes ("prod" vs "production") as close matches
+			distance = min(distance, 1)
+		}
+		if distance < bestDistance {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+	return best
+}
+
+// DistinctTagValues returns the sorted distinct values of a tag key on the
+// monitors that carry all of filterTags
+func (c *Client) DistinctTagValues(key string, filterTags []string) ([]string, error) {
+	monitors, err := c.ListMonitors(filterTags, "")
+	if err != nil {
+		return nil, err
+	}
+	return BuildTagIndex(monitors).Values(key), nil
+}
+
+// TagMatcher selects tags by exact value, glob pattern (* matches any run of
+// characters, including : and /, ? a single character) or regular expression
+type TagMatcher struct {
+	exact    map[string]bool
+	patterns []*regexp.Regexp
+}
+
+// NewTagMatcher builds a matcher for tag patterns. Patterns without * or ? match
+// only the exact tag. With regex every pattern is a regular expression that
+// must match the whole tag.
+func NewTagMatcher(patterns []string, regex bool) (*TagMatcher, error) {
+	m := &TagMatcher{exact: make(map[string]bool)}
+	for _, pattern := range patterns {
+		switch {
+		case regex:
+			re, err := regexp.Compile("^(?:" + pattern + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid tag regex %q: %v", pattern, err)
+			}
+			m.patterns = append(m.patterns, re)
+		case strings.ContainsAny(pattern, "*?"):
+			m.patterns = append(m.patterns, globRegexp(pattern))
+		default:
+			m.exact[pattern] = true
+		}
+	}
+	return m, nil
+}
+
+// globRegexp compiles a tag glob into an anchored regular expression
+func globRegexp(glob string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// HasPatterns reports whether the matcher has glob or regex patterns, whose
+// matches depend on each monitor's tags
+func (m *TagMatcher) HasPatterns() bool {
+	return len(m.patterns) > 0
+}
+
+// Match reports whether a tag is selected
+func (m *TagMatcher) Match(tag string) bool {
+	if m.exact[tag] {
+		return true
+	}
+	for _, re := range m.patterns {
+		if re.MatchString(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matching returns the selected tags among tags, in their order
+func (m *TagMatcher) Matching(tags []string) []string {
+	var matched []string
+	for _, tag := range tags {
+		if m.Match(tag) {
+			matched = append(matched, tag)
+		}
+	}
+	return matched
+}
+
+// DefaultSingleValuedTagKeys are the tag keys a monitor carries one value of:
+// an additional tag with one of these keys replaces the template's tag of the
+// same key instead of being added next to it
+var DefaultSingleValuedTagKeys = []string{"team", "env", "service", "namespace", "priority"}
+
+// TagReplacement is a template tag an additional tag of a single-valued key
+// replaced
+type TagReplacement struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func (r TagReplacement) String() string {
+	return r.From + " -> " + r.To
+}
+
+// tagKey returns the key of a key:value tag; "" for a tag without a value
+func tagKey(tag string) string {
+	key, _, found := strings.Cut(tag, ":")
+	if !found {
+		return ""
+	}
+	return key
+}
+
+// MergeTags adds additional to tags: a tag already present is skipped, and a
+// tag whose key is in singleValued (nil: DefaultSingleValuedTagKeys) takes the
+// place of the tags of the same key, which are returned as replacements.
+// Other tags are appended. Replacing a tag an earlier additional tag added is
+// not reported: --tag team:a --tag team:b keeps team:b.
+func MergeTags(tags, additional, singleValued []string) ([]string, []TagReplacement) {
+	if singleValued == nil {
+		singleValued = DefaultSingleValuedTagKeys
+	}
+	single := make(map[string]bool, len(singleValued))
+	for _, key := range singleValued {
+		single[key] = true
+	}
+
+	merged := append([]string(nil), tags...)
+	added := make(map[string]bool, len(additional))
+	var replaced []TagReplacement
+	for _, tag := range additional {
+		if containsString(merged, tag) {
+			continue
+		}
+		key := tagKey(tag)
+		if key == "" || !single[key] {
+			merged = append(merged, tag)
+			added[tag] = true
+			continue
+		}
+
+		// The new tag takes the position of the first tag of its key
+		position := -1
+		kept := merged[:0:0]
+		for _, existing := range merged {
+			if tagKey(existing) != key {
+				kept = append(kept, existing)
+				continue
+			}
+			if position < 0 {
+				position = len(kept)
+				kept = append(kept, tag)
+			}
+			if !added[existing] {
+				replaced = append(replaced, TagReplacement{From: existing, To: tag})
+			}
+		}
+		if position < 0 {
+			kept = append(kept, tag)
+		}
+		merged = kept
+		added[tag] = true
+	}
+	return merged, replaced
+}
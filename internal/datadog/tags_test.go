@@ -0,0 +1,283 @@
+package datadog
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMergeTags(t *testing.T) {
+	template := []string{"team:old", "service:api", "env:prd", "role:web", "role:db", "critical"}
+	for _, tc := range []struct {
+		name         string
+		tags         []string
+		additional   []string
+		singleValued []string
+		want         string
+		replaced     string
+	}{
+		{"single-valued key replaces in place", template, []string{"team:new"}, nil,
+			"team:new service:api env:prd role:web role:db critical", "team:old -> team:new"},
+		{"multi-valued key is added", template, []string{"role:cache"}, nil,
+			"team:old service:api env:prd role:web role:db critical role:cache", ""},
+		{"tag already present", template, []string{"env:prd", "role:db"}, nil,
+			"team:old service:api env:prd role:web role:db critical", ""},
+		{"new single-valued key is appended", []string{"service:api"}, []string{"priority:p1"}, nil,
+			"service:api priority:p1", ""},
+		{"tags without a value are never single-valued", []string{"critical", "team:a"}, []string{"team", "critical"}, nil,
+			"critical team:a team", ""},
+		{"every tag of the key is replaced", []string{"team:a", "x:1", "team:b"}, []string{"team:c"}, nil,
+			"team:c x:1", "team:a -> team:c, team:b -> team:c"},
+		{"later additional tags win, unreported", []string{"team:old"}, []string{"team:a", "team:b"}, nil,
+			"team:b", "team:old -> team:a"},
+		{"keys are case-sensitive", []string{"Team:old"}, []string{"team:new"}, nil,
+			"Team:old team:new", ""},
+		{"values may hold colons", []string{"team:old", "url:http://a"}, []string{"url:http://b", "team:x:y"}, nil,
+			"team:x:y url:http://a url:http://b", "team:old -> team:x:y"},
+		// The classification is overridable
+		{"custom single-valued keys", template, []string{"role:cache", "team:new"}, []string{"role"},
+			"team:old service:api env:prd role:cache critical team:new", "role:web -> role:cache, role:db -> role:cache"},
+		{"no single-valued keys", template, []string{"team:new"}, []string{},
+			"team:old service:api env:prd role:web role:db critical team:new", ""},
+	} {
+		merged, replaced := MergeTags(tc.tags, tc.additional, tc.singleValued)
+		if got := strings.Join(merged, " "); got != tc.want {
+			t.Errorf("%s: tags %q, want %q", tc.name, got, tc.want)
+		}
+		var replacements []string
+		for _, r := range replaced {
+			replacements = append(replacements, r.String())
+		}
+		if got := strings.Join(replacements, ", "); got != tc.replaced {
+			t.Errorf("%s: replaced %q, want %q", tc.name, got, tc.replaced)
+		}
+	}
+
+	// The template's tags are not modified
+	if fmt.Sprint(template) != "[team:old service:api env:prd role:web role:db critical]" {
+		t.Errorf("template tags modified: %v", template)
+	}
+}
+
+func TestDefaultSingleValuedTagKeys(t *testing.T) {
+	if got := strings.Join(DefaultSingleValuedTagKeys, ","); got != "team,env,service,namespace,priority" {
+		t.Errorf("DefaultSingleValuedTagKeys = %s", got)
+	}
+}
+
+func TestRenderTemplateReplacesTags(t *testing.T) {
+	template := map[string]interface{}{"name": "CPU", "tags": []interface{}{"team:old", "role:web"}}
+	rendered, replaced := renderTemplate(template, RenderOptions{Service: "api", Env: "prd", Namespace: "shop", AdditionalTags: []string{"team:new", "role:db", "env:stg"}})
+	want := "team:new role:web service:api env:stg namespace:shop role:db"
+	if got := strings.Join(rendered["tags"].([]string), " "); got != want {
+		t.Errorf("tags %q, want %q", got, want)
+	}
+	if len(replaced) != 2 || replaced[0].String() != "team:old -> team:new" || replaced[1].String() != "env:prd -> env:stg" {
+		t.Errorf("replaced %v", replaced)
+	}
+}
+
+func TestBuildTagIndex(t *testing.T) {
+	index := BuildTagIndex([]Monitor{
+		{Tags: []string{"env:prd", "service:api", "critical"}},
+		{Tags: []string{"env:prd", "service:web", "url:http://host:8080"}},
+		{Tags: []string{"env:stg", "service:api"}},
+	})
+	if got := index.Values("env"); !slices.Equal(got, []string{"prd", "stg"}) {
+		t.Errorf("Values(env) = %v", got)
+	}
+	if got := index.Values("team"); len(got) != 0 {
+		t.Errorf("Values of an unknown key = %v", got)
+	}
+	if index["env"]["prd"] != 2 || index["service"]["api"] != 2 || index["service"]["web"] != 1 {
+		t.Errorf("counts: %v", index)
+	}
+	// Values keep their colons, and tags without a value are not indexed
+	if !index.Has("url", "http://host:8080") || index.Has("critical", "") || len(index["critical"]) != 0 {
+		t.Errorf("index: %v", index)
+	}
+	for _, tc := range []struct {
+		key, value string
+		want       bool
+	}{
+		{"env", "prd", true},
+		{"env", "PRD", false},
+		{"env", "dev", false},
+		{"team", "payments", false},
+	} {
+		if got := index.Has(tc.key, tc.value); got != tc.want {
+			t.Errorf("Has(%q, %q) = %v, want %v", tc.key, tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestCountTags(t *testing.T) {
+	monitors := []Monitor{
+		{Tags: []string{"env:prd", "team:a", "team:a"}},
+		{Tags: []string{"env:prd", "team:b"}},
+		{Tags: []string{"env:stg", "team:b"}},
+	}
+	format := func(counts []TagCount) string {
+		var parts []string
+		for _, count := range counts {
+			parts = append(parts, fmt.Sprintf("%s=%d", count.Tag, count.Count))
+		}
+		return strings.Join(parts, " ")
+	}
+	// Most common first, ties by tag; a tag repeated on a monitor counts once
+	if got := format(CountTags(monitors, "")); got != "env:prd=2 team:b=2 env:stg=1 team:a=1" {
+		t.Errorf("CountTags = %s", got)
+	}
+	if got := format(CountTags(monitors, "team")); got != "team:b=2 team:a=1" {
+		t.Errorf("CountTags(team) = %s", got)
+	}
+	if got := CountTags(nil, ""); len(got) != 0 {
+		t.Errorf("CountTags(nil) = %v", got)
+	}
+}
+
+func TestSuggestClosest(t *testing.T) {
+	envs := []string{"dev", "prd", "stg", "production"}
+	for _, tc := range []struct {
+		value      string
+		candidates []string
+		want       string
+	}{
+		// Case-only differences win over everything else
+		{"PRD", envs, "prd"},
+		{"Production", envs, "production"},
+		// One edit away
+		{"prod", []string{"dev", "prd", "stg"}, "prd"},
+		{"stage", []string{"dev", "prd", "stg"}, "stg"},
+		// Prefixes count as a single edit, the first closest candidate wins
+		{"prod", envs, "prd"},
+		{"produc", envs, "production"},
+		{"check", []string{"checkout-api", "cart"}, "checkout-api"},
+		// Short values don't match by prefix
+		{"pr", []string{"production"}, ""},
+		// At least 2 edits are allowed, then a third of the length
+		{"pdr", []string{"dev", "prd"}, "prd"},
+		{"checkuot-srvice", []string{"checkout-service"}, "checkout-service"},
+		{"payments", []string{"checkout", "search"}, ""},
+		{"prd", nil, ""},
+	} {
+		if got := SuggestClosest(tc.value, tc.candidates); got != tc.want {
+			t.Errorf("SuggestClosest(%q, %v) = %q, want %q", tc.value, tc.candidates, got, tc.want)
+		}
+	}
+}
+
+func TestTagValueIndex(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`[{"id": 1, "tags": ["env:prd", "service:api"]}, {"id": 2, "tags": ["env:stg"]}]`))
+	}))
+	defer srv.Close()
+	client, err := NewClientWithOptions(WithAPIKey("api-key"), WithAppKey("app-key"), WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := client.TagValueIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// All monitors are listed, whatever the filters that found nothing
+	if strings.Contains(query, "monitor_tags") {
+		t.Errorf("tag values listed with filters: %s", query)
+	}
+	if got := index.Values("env"); !slices.Equal(got, []string{"prd", "stg"}) {
+		t.Errorf("Values(env) = %v", got)
+	}
+}
+
+func TestTagMatcher(t *testing.T) {
+	tags := []string{"owner:alice", "owner:bob", "contact:carol", "env:prd", "url:https://a/b", "tier-deprecated", "team:sre", "team:sre2"}
+	for _, tc := range []struct {
+		name      string
+		patterns  []string
+		regex     bool
+		want      string
+		patterned bool
+	}{
+		// Plain tags match only themselves, as before patterns existed
+		{"exact", []string{"team:sre"}, false, "team:sre", false},
+		{"exact key without value", []string{"owner"}, false, "", false},
+		{"exact regex characters", []string{"team.sre"}, false, "", false},
+		{"glob value", []string{"owner:*"}, false, "owner:alice owner:bob", true},
+		// * runs across : and /
+		{"glob across separators", []string{"url:*"}, false, "url:https://a/b", true},
+		{"glob suffix", []string{"*-deprecated"}, false, "tier-deprecated", true},
+		{"glob single character", []string{"team:sre?"}, false, "team:sre2", true},
+		{"glob is anchored", []string{"owner:a*e"}, false, "owner:alice", true},
+		{"exact and glob", []string{"env:prd", "contact:*"}, false, "contact:carol env:prd", true},
+		{"regex", []string{"(owner|contact):.*"}, true, "owner:alice owner:bob contact:carol", true},
+		// Regexes must match the whole tag
+		{"regex is anchored", []string{"team:sre"}, true, "team:sre", true},
+		{"regex partial", []string{"sre"}, true, "", true},
+		// With --regex, * is a regex quantifier, not a glob
+		{"regex glob characters", []string{"owner:*"}, true, "", true},
+	} {
+		m, err := NewTagMatcher(tc.patterns, tc.regex)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if got := strings.Join(m.Matching(tags), " "); got != tc.want {
+			t.Errorf("%s: Matching = %q, want %q", tc.name, got, tc.want)
+		}
+		if got := m.HasPatterns(); got != tc.patterned {
+			t.Errorf("%s: HasPatterns = %v, want %v", tc.name, got, tc.patterned)
+		}
+	}
+
+	if _, err := NewTagMatcher([]string{"owner:("}, true); err == nil || !strings.Contains(err.Error(), `invalid tag regex "owner:("`) {
+		t.Errorf("invalid regex: %v", err)
+	}
+	// Invalid regexes are only an error with regex
+	if _, err := NewTagMatcher([]string{"owner:("}, false); err != nil {
+		t.Errorf("plain tag with a parenthesis: %v", err)
+	}
+}
+
+func TestRemoveMatchingTags(t *testing.T) {
+	tags := []string{"owner:alice", "env:prd"}
+	var puts int
+	var sent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			puts++
+			body, _ := io.ReadAll(r.Body)
+			sent = string(body)
+			w.Write(body)
+			return
+		}
+		fmt.Fprintf(w, `{"id": 1, "name": "cpu", "tags": ["%s"]}`, strings.Join(tags, `", "`))
+	}))
+	defer srv.Close()
+	client, err := NewClientWithOptions(WithAPIKey("api-key"), WithAppKey("app-key"), WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matcher, _ := NewTagMatcher([]string{"owner:*"}, false)
+	if _, changed, err := client.RemoveMatchingTags(1, matcher); err != nil || !changed {
+		t.Fatalf("RemoveMatchingTags = %v, %v", changed, err)
+	}
+	if puts != 1 || !strings.Contains(sent, `"env:prd"`) || strings.Contains(sent, "owner") {
+		t.Errorf("%d PUT(s), sent %s", puts, sent)
+	}
+
+	// Nothing matching, nothing sent
+	matcher, _ = NewTagMatcher([]string{"team:*"}, false)
+	if _, changed, err := client.RemoveMatchingTags(1, matcher); err != nil || changed {
+		t.Errorf("RemoveMatchingTags without a match = %v, %v", changed, err)
+	}
+	if puts != 1 {
+		t.Errorf("%d PUT(s), want 1", puts)
+	}
+}
@@ -0,0 +1,177 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultTeamTagKey is the tag key monitors are grouped by in team reports
+const DefaultTeamTagKey = "team"
+
+// NoTeam is the report entry for monitors without a team tag
+const NoTeam = "(no team)"
+
+// teamsPageSize is the page size used when listing Datadog Teams
+const teamsPageSize = 100
+
+// Team is a Datadog Team from the v2 Teams API
+type Team struct {
+	ID     string `json:"id"`
+	Handle string `json:"handle"`
+	Name   string `json:"name"`
+}
+
+// teamsResponse is the JSON:API envelope of GET /api/v2/team
+type teamsResponse struct {
+	Data []struct {
+		ID         string `json:"id"`
+		Attributes struct {
+			Handle string `json:"handle"`
+			Name   string `json:"name"`
+		} `json:"attributes"`
+	} `json:"data"`
+}
+
+// ListTeams lists all Datadog Teams
+func (c *Client) ListTeams() ([]Team, error) {
+	var teams []Team
+	for page := 0; ; page++ {
+		req, err := c.newV2Request("GET", "/team", nil)
+		if err != nil {
+			return nil, err
+		}
+		q := req.URL.Query()
+		q.Set("page[size]", strconv.Itoa(teamsPageSize))
+		q.Set("page[number]", strconv.Itoa(page))
+		req.URL.RawQuery = q.Encode()
+
+		resp, err := c.do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list teams: status %d, body: %s", resp.StatusCode, string(body))
+		}
+
+		var result teamsResponse
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, data := range result.Data {
+			teams = append(teams, Team{ID: data.ID, Handle: data.Attributes.Handle, Name: data.Attributes.Name})
+		}
+		if len(result.Data) < teamsPageSize {
+			return teams, nil
+		}
+	}
+}
+
+// HasRunbook reports whether a monitor message links or mentions a runbook
+func HasRunbook(monitor Monitor) bool {
+	return strings.Contains(strings.ToLower(monitor.Message), "runbook")
+}
+
+// TeamSummary is the ownership and alert posture of one team's monitors
+type TeamSummary struct {
+	Team           string         `json:"team"`
+	Total          int            `json:"total"`
+	States         map[string]int `json:"states"`
+	MissingRunbook int            `json:"missing_runbook"`
+	// OldestUnmodified is the monitor that was modified the longest time ago
+	OldestUnmodified *Monitor `json:"oldest_unmodified,omitempty"`
+	// KnownTeam tells whether the team tag matches a Datadog Team; nil when not checked
+	KnownTeam *bool     `json:"known_team,omitempty"`
+	Monitors  []Monitor `json:"monitors,omitempty"`
+}
+
+// MonitorTeams returns the values of the team tag key on a monitor, or NoTeam
+func MonitorTeams(monitor Monitor, tagKey string) []string {
+	return MonitorTagValues(monitor, tagKey, NoTeam)
+}
+
+// MonitorTagValues returns the values of a tag key on a monitor, or missing
+// when the monitor has no such tag. Reports group monitors by these values; a
+// monitor with several values counts towards each.
+func MonitorTagValues(monitor Monitor, tagKey, missing string) []string {
+	prefix := tagKey + ":"
+	var values []string
+	for _, tag := range monitor.Tags {
+		if strings.HasPrefix(tag, prefix) && len(tag) > len(prefix) {
+			values = append(values, strings.TrimPrefix(tag, prefix))
+		}
+	}
+	if len(values) == 0 {
+		return []string{missing}
+	}
+	return values
+}
+
+// BuildTeamReport groups monitors by team tag. A monitor with several team tags
+// counts towards each team. Summaries are sorted by team, with NoTeam last.
+func BuildTeamReport(monitors []Monitor, tagKey string) []TeamSummary {
+	byTeam := make(map[string]*TeamSummary)
+	for _, monitor := range monitors {
+		for _, team := range MonitorTeams(monitor, tagKey) {
+			summary, ok := byTeam[team]
+			if !ok {
+				summary = &TeamSummary{Team: team, States: make(map[string]int)}
+				byTeam[team] = summary
+			}
+
+			summary.Total++
+			state := monitor.OverallState
+			if state == "" {
+				state = "Unknown"
+			}
+			summary.States[state]++
+			if !HasRunbook(monitor) {
+				summary.MissingRunbook++
+			}
+			if !monitor.Modified.IsZero() && (summary.OldestUnmodified == nil || monitor.Modified.Before(summary.OldestUnmodified.Modified)) {
+				oldest := monitor
+				summary.OldestUnmodified = &oldest
+			}
+			summary.Monitors = append(summary.Monitors, monitor)
+		}
+	}
+
+	report := make([]TeamSummary, 0, len(byTeam))
+	for _, summary := range byTeam {
+		report = append(report, *summary)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if (report[i].Team == NoTeam) != (report[j].Team == NoTeam) {
+			return report[j].Team == NoTeam
+		}
+		return report[i].Team < report[j].Team
+	})
+	return report
+}
+
+// MarkKnownTeams sets KnownTeam on each summary by matching team tags against
+// the handles and names of Datadog Teams
+func MarkKnownTeams(report []TeamSummary, teams []Team) {
+	known := make(map[string]bool)
+	for _, team := range teams {
+		known[strings.ToLower(team.Handle)] = true
+		known[strings.ToLower(team.Name)] = true
+	}
+	for i := range report {
+		if report[i].Team == NoTeam {
+			continue
+		}
+		isKnown := known[strings.ToLower(report[i].Team)]
+		report[i].KnownTeam = &isKnown
+	}
+}
@@ -0,0 +1,90 @@
+package datadog
+
+import (
+	"sort"
+	"strings"
+)
+
+// ExportTemplates turns monitors, sorted by ID, into templates that recreate
+// them. Log alert and service check queries the structured blocks can express
+// are decompiled into a "log" or "service_check" block, without the
+// thresholds the block carries. The tags this tool manages (managed-by,
+// template-id and the fingerprint) and options with no configuration are
+// dropped.
+func ExportTemplates(monitors []Monitor) TemplateFile {
+	sorted := append([]Monitor(nil), monitors...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	var file TemplateFile
+	for _, monitor := range sorted {
+		file.Templates = append(file.Templates, TemplateData{Name: monitor.Name, Config: exportTemplateConfig(monitor)})
+	}
+	return file
+}
+
+// exportTemplateConfig returns the template config of one monitor
+func exportTemplateConfig(monitor Monitor) map[string]interface{} {
+	config := map[string]interface{}{
+		"name": monitor.Name,
+		"type": monitor.Type,
+	}
+	if monitor.Message != "" {
+		config["message"] = monitor.Message
+	}
+	if monitor.Priority != nil {
+		config["priority"] = *monitor.Priority
+	}
+
+	var tags []string
+	for _, tag := range monitor.Tags {
+		if tag == ManagedByTag || strings.HasPrefix(tag, TemplateIDTagKey+":") || strings.HasPrefix(tag, FingerprintTagKey+":") {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	if len(tags) > 0 {
+		config["tags"] = tags
+	}
+
+	options := make(map[string]interface{}, len(monitor.Options))
+	for key, value := range monitor.Options {
+		if !terraformIgnoredOptions[key] {
+			options[key] = value
+		}
+	}
+
+	// The thresholds a decompiled block sets when it is compiled
+	var blockThresholds []string
+	switch monitor.Type {
+	case "log alert":
+		if block, ok := DecompileLogQuery(monitor.Query, monitor.Options); ok {
+			config["log"] = block
+			blockThresholds = []string{"critical", "warning"}
+		}
+	case "service check":
+		if block, ok := DecompileServiceCheckQuery(monitor.Query, monitor.Options); ok && len(LintServiceCheckBlock(block)) == 0 {
+			config["service_check"] = block
+			blockThresholds = []string{"critical", "warning", "ok"}
+		}
+	}
+	if blockThresholds == nil {
+		config["query"] = monitor.Query
+	} else if thresholds, ok := options["thresholds"].(map[string]interface{}); ok {
+		remaining := make(map[string]interface{}, len(thresholds))
+		for key, value := range thresholds {
+			remaining[key] = value
+		}
+		for _, key := range blockThresholds {
+			delete(remaining, key)
+		}
+		if len(remaining) > 0 {
+			options["thresholds"] = remaining
+		} else {
+			delete(options, "thresholds")
+		}
+	}
+	if len(options) > 0 {
+		config["options"] = options
+	}
+	return config
+}
@@ -0,0 +1,287 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TerraformResourceType is the Terraform resource monitors are exported as
+const TerraformResourceType = "datadog_monitor"
+
+// terraformAttribute maps a monitor option to a datadog_monitor attribute
+type terraformAttribute struct {
+	Option    string
+	Attribute string
+}
+
+// terraformOptionAttributes maps monitor options to top-level datadog_monitor
+// attributes, in the order they are written
+var terraformOptionAttributes = []terraformAttribute{
+	{"notify_no_data", "notify_no_data"},
+	{"no_data_timeframe", "no_data_timeframe"},
+	{"on_missing_data", "on_missing_data"},
+	{"notify_audit", "notify_audit"},
+	{"include_tags", "include_tags"},
+	{"require_full_window", "require_full_window"},
+	{"new_group_delay", "new_group_delay"},
+	{"new_host_delay", "new_host_delay"},
+	{"evaluation_delay", "evaluation_delay"},
+	{"timeout_h", "timeout_h"},
+	{"renotify_interval", "renotify_interval"},
+	{"renotify_occurrences", "renotify_occurrences"},
+	{"renotify_statuses", "renotify_statuses"},
+	{"escalation_message", "escalation_message"},
+	{"notify_by", "notify_by"},
+	{"notification_preset_name", "notification_preset_name"},
+	{"group_retention_duration", "group_retention_duration"},
+	{"groupby_simple_monitor", "groupby_simple_monitor"},
+	{"enable_logs_sample", "enable_logs_sample"},
+	{"enable_samples", "enable_samples"},
+}
+
+// terraformThresholdAttributes are the keys of options.thresholds written in
+// the monitor_thresholds block, in order
+var terraformThresholdAttributes = []string{"critical", "critical_recovery", "warning", "warning_recovery", "ok", "unknown"}
+
+// terraformThresholdWindowAttributes are the keys of options.threshold_windows
+// written in the monitor_threshold_windows block, in order
+var terraformThresholdWindowAttributes = []string{"trigger_window", "recovery_window"}
+
+// terraformIgnoredOptions are options with no datadog_monitor attribute that
+// carry no configuration (deprecated or server-side)
+var terraformIgnoredOptions = map[string]bool{
+	"silenced": true,
+	"locked":   true,
+}
+
+// TerraformExport is monitors rendered as datadog_monitor resources, with the
+// terraform import commands adopting them
+type TerraformExport struct {
+	HCL     string
+	Imports []string
+}
+
+// ExportTerraform renders monitors as datadog_monitor resources, sorted by ID.
+// Resource names are derived from the monitor names (see
+// TerraformResourceName); options without a datadog_monitor attribute are
+// kept as comments so nothing is dropped silently.
+func ExportTerraform(monitors []Monitor) TerraformExport {
+	sorted := append([]Monitor(nil), monitors...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	var export TerraformExport
+	var b strings.Builder
+	used := make(map[string]bool)
+	for i, monitor := range sorted {
+		name := TerraformResourceName(monitor.Name, used)
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		writeTerraformMonitor(&b, name, monitor)
+		export.Imports = append(export.Imports, fmt.Sprintf("terraform import %s.%s %d", TerraformResourceType, name, monitor.ID))
+	}
+	export.HCL = b.String()
+	return export
+}
+
+// TerraformResourceName returns a resource name for a monitor name: lowercase
+// letters, digits and '_', starting with a letter or '_', e.g.
+// "[prd] CPU usage - api" gives "prd_cpu_usage_api". Names already in used
+// get a numeric suffix (_2, _3, ...); the returned name is added to used.
+func TerraformResourceName(monitorName string, used map[string]bool) string {
+	var b strings.Builder
+	pending := false
+	for _, r := range strings.ToLower(monitorName) {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			pending = true
+			continue
+		}
+		if pending && b.Len() > 0 {
+			b.WriteByte('_')
+		}
+		pending = false
+		b.WriteRune(r)
+	}
+
+	base := b.String()
+	if base == "" {
+		base = "monitor"
+	} else if base[0] >= '0' && base[0] <= '9' {
+		base = "monitor_" + base
+	}
+
+	name := base
+	for n := 2; used[name]; n++ {
+		name = fmt.Sprintf("%s_%d", base, n)
+	}
+	used[name] = true
+	return name
+}
+
+// writeTerraformMonitor writes one datadog_monitor resource block
+func writeTerraformMonitor(b *strings.Builder, resourceName string, monitor Monitor) {
+	fmt.Fprintf(b, "# Monitor %d\n", monitor.ID)
+	fmt.Fprintf(b, "resource %q %q {\n", TerraformResourceType, resourceName)
+	writeTerraformAttribute(b, "name", monitor.Name)
+	writeTerraformAttribute(b, "type", monitor.Type)
+	writeTerraformAttribute(b, "query", monitor.Query)
+	writeTerraformAttribute(b, "message", monitor.Message)
+	if monitor.Priority != nil {
+		// The provider takes the priority as a string
+		writeTerraformAttribute(b, "priority", strconv.Itoa(*monitor.Priority))
+	}
+
+	options := monitor.Options
+	mapped := make(map[string]bool)
+	for _, attr := range terraformOptionAttributes {
+		if value, ok := options[attr.Option]; ok && value != nil {
+			writeTerraformAttribute(b, attr.Attribute, value)
+		}
+		mapped[attr.Option] = true
+	}
+	if len(monitor.Tags) > 0 {
+		tags := append([]string(nil), monitor.Tags...)
+		sort.Strings(tags)
+		writeTerraformAttribute(b, "tags", tags)
+	}
+
+	writeTerraformBlock(b, "monitor_thresholds", options["thresholds"], terraformThresholdAttributes)
+	mapped["thresholds"] = true
+	writeTerraformBlock(b, "monitor_threshold_windows", options["threshold_windows"], terraformThresholdWindowAttributes)
+	mapped["threshold_windows"] = true
+
+	var unmapped []string
+	for option, value := range options {
+		if !mapped[option] && !terraformIgnoredOptions[option] && value != nil {
+			unmapped = append(unmapped, option)
+		}
+	}
+	sort.Strings(unmapped)
+	for _, option := range unmapped {
+		value, _ := json.Marshal(options[option])
+		fmt.Fprintf(b, "\n  # Not exported, set it by hand: options.%s = %s\n", option, value)
+	}
+	b.WriteString("}\n")
+}
+
+// writeTerraformBlock writes a nested block with the given keys of an option
+// object; nothing is written when the option is missing or has none of them
+func writeTerraformBlock(b *strings.Builder, block string, option interface{}, keys []string) {
+	values, ok := option.(map[string]interface{})
+	if !ok {
+		return
+	}
+	var body strings.Builder
+	for _, key := range keys {
+		if value, ok := values[key]; ok && value != nil {
+			body.WriteString("  ")
+			writeTerraformAttribute(&body, key, value)
+		}
+	}
+	if body.Len() == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n  %s {\n%s  }\n", block, body.String())
+}
+
+// writeTerraformAttribute writes an attribute assignment
+func writeTerraformAttribute(b *strings.Builder, attribute string, value interface{}) {
+	if s, ok := value.(string); ok && s == "" {
+		return
+	}
+	fmt.Fprintf(b, "  %s = %s\n", attribute, terraformValue(value))
+}
+
+// terraformValue formats a JSON value as an HCL expression. Multi-line
+// strings become heredocs.
+func terraformValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		if strings.Contains(v, "\n") {
+			return terraformHeredoc(v)
+		}
+		return terraformQuote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case []string:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = terraformQuote(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = terraformValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return terraformQuote(fmt.Sprint(v))
+	}
+}
+
+// terraformQuote returns a quoted HCL string. Besides the usual escapes, ${
+// and %{ are doubled so Terraform doesn't read them as interpolation.
+func terraformQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return escapeTemplateSequences(b.String())
+}
+
+// terraformHeredoc returns a multi-line string as a heredoc. A heredoc always
+// ends with a newline, so messages without one are wrapped in chomp() to
+// round-trip exactly. The delimiter is chosen not to clash with any line.
+func terraformHeredoc(s string) string {
+	delimiter := "EOT"
+	for n := 2; heredocHasLine(s, delimiter); n++ {
+		delimiter = fmt.Sprintf("EOT%d", n)
+	}
+
+	body := escapeTemplateSequences(s)
+	if strings.HasSuffix(body, "\n") {
+		return "<<" + delimiter + "\n" + body + delimiter
+	}
+	return "chomp(<<" + delimiter + "\n" + body + "\n" + delimiter + "\n  )"
+}
+
+// heredocHasLine reports whether s has a line that would end a heredoc with
+// the given delimiter
+func heredocHasLine(s, delimiter string) bool {
+	for _, line := range strings.Split(s, "\n") {
+		if strings.TrimSpace(line) == delimiter {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeTemplateSequences doubles the ${ and %{ template sequences of HCL
+// strings, leaving Datadog's {{...}} variables untouched
+func escapeTemplateSequences(s string) string {
+	s = strings.ReplaceAll(s, "${", "$${")
+	return strings.ReplaceAll(s, "%{", "%%{")
+}
@@ -0,0 +1,141 @@
+package datadog
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+// updateGoldenEnv rewrites the golden files of the tests instead of comparing
+// against them, like datadogtest.UpdateSnapshotsEnv
+const updateGoldenEnv = "DDMM_UPDATE_SNAPSHOTS"
+
+// assertGolden compares got with the golden file at path
+func assertGolden(t *testing.T, path string, got []byte) {
+	t.Helper()
+	if os.Getenv(updateGoldenEnv) != "" {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (write it with %s=1)", err, updateGoldenEnv)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output doesn't match %s (update it with %s=1):\n%s", path, updateGoldenEnv, got)
+	}
+}
+
+func TestExportTerraform(t *testing.T) {
+	data, err := os.ReadFile("testdata/terraform-monitors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var monitors []Monitor
+	if err := json.Unmarshal(data, &monitors); err != nil {
+		t.Fatal(err)
+	}
+
+	export := ExportTerraform(monitors)
+	assertGolden(t, "testdata/terraform.golden.tf", []byte(export.HCL))
+
+	wantImports := []string{
+		"terraform import datadog_monitor.checkout_composite 2000",
+		"terraform import datadog_monitor.prd_cpu_usage_api 2001",
+		"terraform import datadog_monitor.errors_in_var_logs 2002",
+		"terraform import datadog_monitor.prd_cpu_usage_api_2 2003",
+		"terraform import datadog_monitor.monitor_3xx_anomalies 2004",
+	}
+	if strings.Join(export.Imports, "\n") != strings.Join(wantImports, "\n") {
+		t.Errorf("imports:\n%s\nwant:\n%s", strings.Join(export.Imports, "\n"), strings.Join(wantImports, "\n"))
+	}
+
+	// Exporting is deterministic, whatever the order of the monitors and
+	// of their options
+	reversed := make([]Monitor, len(monitors))
+	for i, monitor := range monitors {
+		reversed[len(monitors)-1-i] = monitor
+	}
+	for i := 0; i < 5; i++ {
+		if again := ExportTerraform(reversed); again.HCL != export.HCL {
+			t.Fatal("export differs between runs")
+		}
+	}
+}
+
+func TestTerraformResourceName(t *testing.T) {
+	used := make(map[string]bool)
+	for _, tc := range []struct {
+		name, want string
+	}{
+		{"[prd] CPU usage - api", "prd_cpu_usage_api"},
+		{"[PRD] cpu USAGE api", "prd_cpu_usage_api_2"},
+		{"prd_cpu_usage_api", "prd_cpu_usage_api_3"},
+		{"5xx errors", "monitor_5xx_errors"},
+		{"Ünïcode ☃ only-ascii", "n_code_only_ascii"},
+		{"☃", "monitor"},
+		{"", "monitor_2"},
+	} {
+		if got := TerraformResourceName(tc.name, used); got != tc.want {
+			t.Errorf("TerraformResourceName(%q) = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestTerraformStrings(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"plain", `"plain"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`C:\path`, `"C:\\path"`},
+		{"tab\there", `"tab\there"`},
+		{"${var} and %{if}", `"$${var} and %%{if}"`},
+		// Datadog template variables are not HCL templates
+		{"{{host.name}} {{#is_alert}}", `"{{host.name}} {{#is_alert}}"`},
+		{"$ alone, {braces}", `"$ alone, {braces}"`},
+	} {
+		if got := terraformValue(tc.in); got != tc.want {
+			t.Errorf("terraformValue(%q) = %s, want %s", tc.in, got, tc.want)
+		}
+	}
+
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"line 1\nline 2\n", "<<EOT\nline 1\nline 2\nEOT"},
+		// Without a trailing newline, chomp() keeps the message as it was
+		{"line 1\nline 2", "chomp(<<EOT\nline 1\nline 2\nEOT\n  )"},
+		// Lines equal to the delimiter pick another one
+		{"a\nEOT\n", "<<EOT2\na\nEOT\nEOT2"},
+		{"a\n  EOT  \nEOT2\n", "<<EOT3\na\n  EOT  \nEOT2\nEOT3"},
+		{"cost: ${amount}\n", "<<EOT\ncost: $${amount}\nEOT"},
+	} {
+		if got := terraformValue(tc.in); got != tc.want {
+			t.Errorf("terraformValue(%q) =\n%s\nwant:\n%s", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestTerraformValue(t *testing.T) {
+	for _, tc := range []struct {
+		in   interface{}
+		want string
+	}{
+		{true, "true"},
+		{float64(300), "300"},
+		{0.5, "0.5"},
+		{42, "42"},
+		{[]string{"b", "a"}, `["b", "a"]`},
+		{[]interface{}{"alert", float64(1), false}, `["alert", 1, false]`},
+		{[]interface{}{}, "[]"},
+	} {
+		if got := terraformValue(tc.in); got != tc.want {
+			t.Errorf("terraformValue(%#v) = %s, want %s", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+# Monitor 2000
+resource "datadog_monitor" "checkout_composite" {
+  name = "Checkout composite"
+  type = "composite"
+  query = "2001 && 2002"
+  message = "Both fired"
+}
+
+# Monitor 2001
+resource "datadog_monitor" "prd_cpu_usage_api" {
+  name = "[prd] CPU usage - api"
+  type = "metric alert"
+  query = "avg(last_5m):avg:system.cpu.user{env:prd,service:api} by {host} > 90"
+  message = <<EOT
+CPU is high on {{host.name}}
+{{#is_alert}}@slack-ops{{/is_alert}}
+EOT
+  priority = "2"
+  notify_no_data = true
+  no_data_timeframe = 10
+  include_tags = false
+  renotify_interval = 60
+  renotify_statuses = ["alert", "no data"]
+  tags = ["env:prd", "managed-by:ddmm", "service:api"]
+
+  monitor_thresholds {
+    critical = 90
+    critical_recovery = 85
+    warning = 80
+  }
+}
+
+# Monitor 2002
+resource "datadog_monitor" "errors_in_var_logs" {
+  name = "Errors in $${var} logs"
+  type = "log alert"
+  query = "logs(\"service:api status:error\").index(\"*\").rollup(\"count\").last(\"5m\") > 100"
+  message = chomp(<<EOT2
+Shell-style $${HOME} and %%{directive} stay literal
+EOT
+last line without newline
+EOT2
+  )
+  groupby_simple_monitor = false
+  enable_logs_sample = true
+  tags = ["service:api", "team:payments"]
+
+  monitor_thresholds {
+    critical = 100
+  }
+}
+
+# Monitor 2003
+resource "datadog_monitor" "prd_cpu_usage_api_2" {
+  name = "[prd] CPU usage - api"
+  type = "service check"
+  query = "\"http.can_connect\".over(\"env:prd\",\"service:api\").by(\"host\").last(3).count_by_status()"
+  message = "Quoted \"name\" with a tab\tand backslash \\"
+  new_group_delay = 60
+  notify_by = ["host"]
+
+  monitor_thresholds {
+    critical = 2
+    warning = 1
+    ok = 1
+  }
+}
+
+# Monitor 2004
+resource "datadog_monitor" "monitor_3xx_anomalies" {
+  name = "3xx anomalies"
+  type = "query alert"
+  query = "avg(last_4h):anomalies(avg:http.requests{env:prd}, 'agile', 2) >= 1"
+  evaluation_delay = 300
+
+  monitor_thresholds {
+    critical = 1
+  }
+
+  monitor_threshold_windows {
+    trigger_window = "last_15m"
+    recovery_window = "last_15m"
+  }
+
+  # Not exported, set it by hand: options.variables = [{"name":"q"}]
+}
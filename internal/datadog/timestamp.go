@@ -0,0 +1,116 @@
+package datadog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Timestamp is a point in time the API reports as Unix seconds, Unix
+// milliseconds (created_at), a numeric string or an RFC3339 string. Values
+// that can't be parsed decode to the zero Timestamp. It encodes back to the
+// representation it was decoded from; fields are tagged omitzero so unknown
+// timestamps are left out of request bodies (omitzero needs Go 1.24, hence
+// the go directive of go.mod).
+type Timestamp struct {
+	time time.Time
+	raw  json.RawMessage
+}
+
+// millisecondsThreshold separates Unix seconds from Unix milliseconds:
+// larger values are milliseconds
+const millisecondsThreshold = 1e12
+
+// UnmarshalJSON decodes a number, a numeric string or an RFC3339 string
+// (with or without fractional seconds); null, "" and anything else give the
+// zero Timestamp
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	*t = Timestamp{}
+
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		str = strings.TrimSpace(str)
+		if parsed, ok := parseUnix(str); ok {
+			*t = Timestamp{time: parsed, raw: cloneRaw(data)}
+		} else if parsed, err := time.Parse(time.RFC3339Nano, str); err == nil {
+			*t = Timestamp{time: parsed, raw: cloneRaw(data)}
+		}
+		return nil
+	}
+
+	if parsed, ok := parseUnix(string(bytes.TrimSpace(data))); ok {
+		*t = Timestamp{time: parsed, raw: cloneRaw(data)}
+	}
+	return nil
+}
+
+// MarshalJSON encodes the timestamp as it was decoded; a zero Timestamp
+// encodes as null
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	if t.raw != nil {
+		return t.raw, nil
+	}
+	return []byte(strconv.FormatInt(t.time.Unix(), 10)), nil
+}
+
+// parseUnix parses Unix seconds or milliseconds, possibly fractional
+func parseUnix(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	if val, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if val == 0 {
+			return time.Time{}, false
+		}
+		if val > millisecondsThreshold {
+			return time.UnixMilli(val), true
+		}
+		return time.Unix(val, 0), true
+	}
+	val, err := strconv.ParseFloat(value, 64)
+	if err != nil || val == 0 {
+		return time.Time{}, false
+	}
+	if val > millisecondsThreshold {
+		return time.UnixMicro(int64(val * 1e3)), true
+	}
+	return time.UnixMicro(int64(val * 1e6)), true
+}
+
+// cloneRaw copies a JSON value; the decoder reuses its buffer
+func cloneRaw(data []byte) json.RawMessage {
+	return append(json.RawMessage(nil), bytes.TrimSpace(data)...)
+}
+
+// Time returns the timestamp as a time; the zero time when unknown
+func (t Timestamp) Time() time.Time {
+	return t.time
+}
+
+// IsZero reports whether the timestamp is unknown
+func (t Timestamp) IsZero() bool {
+	return t.time.IsZero()
+}
+
+// Int64 returns the timestamp in Unix seconds; 0 when unknown
+func (t Timestamp) Int64() int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.time.Unix()
+}
+
+// Before reports whether the timestamp is before u
+func (t Timestamp) Before(u Timestamp) bool {
+	return t.time.Before(u.time)
+}
+
+// Equal reports whether two timestamps are the same instant
+func (t Timestamp) Equal(u Timestamp) bool {
+	return t.time.Equal(u.time)
+}
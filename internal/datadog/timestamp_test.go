@@ -0,0 +1,74 @@
+package datadog
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimestampUnmarshal(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		json string
+		want time.Time
+	}{
+		{"seconds", `1700000000`, time.Unix(1700000000, 0)},
+		{"milliseconds", `1700000000123`, time.UnixMilli(1700000000123)},
+		{"fractional seconds", `1700000000.5`, time.UnixMilli(1700000000500)},
+		{"numeric string", `"1700000000"`, time.Unix(1700000000, 0)},
+		{"numeric string with spaces", `" 1700000000 "`, time.Unix(1700000000, 0)},
+		{"RFC3339", `"2023-11-14T22:13:20Z"`, time.Unix(1700000000, 0)},
+		{"RFC3339 with fraction and offset", `"2023-11-14T23:13:20.25+01:00"`, time.UnixMilli(1700000000250)},
+		{"null", `null`, time.Time{}},
+		{"empty string", `""`, time.Time{}},
+		{"zero", `0`, time.Time{}},
+		{"garbage string", `"yesterday"`, time.Time{}},
+		{"bool", `true`, time.Time{}},
+		{"object", `{"seconds": 1}`, time.Time{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var ts Timestamp
+			if err := json.Unmarshal([]byte(tc.json), &ts); err != nil {
+				t.Fatalf("Unmarshal(%s): %v", tc.json, err)
+			}
+			if !ts.Time().Equal(tc.want) {
+				t.Errorf("Unmarshal(%s) = %v, want %v", tc.json, ts.Time(), tc.want)
+			}
+			if ts.IsZero() != tc.want.IsZero() {
+				t.Errorf("IsZero() = %v", ts.IsZero())
+			}
+		})
+	}
+}
+
+func TestTimestampInMonitor(t *testing.T) {
+	var monitor Monitor
+	if err := json.Unmarshal([]byte(`{"id": 1, "created_at": 1700000000123, "modified": "2023-11-14T22:15:00Z"}`), &monitor); err != nil {
+		t.Fatal(err)
+	}
+	if got := monitor.CreatedAt.Int64(); got != 1700000000 {
+		t.Errorf("CreatedAt = %d, want 1700000000", got)
+	}
+	if !monitor.CreatedAt.Before(monitor.Modified) {
+		t.Errorf("CreatedAt %v not before Modified %v", monitor.CreatedAt.Time(), monitor.Modified.Time())
+	}
+
+	// Timestamps encode as they were decoded
+	data, _ := json.Marshal(monitor)
+	for _, want := range []string{`"created_at":1700000000123`, `"modified":"2023-11-14T22:15:00Z"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Marshal = %s, lacks %s", data, want)
+		}
+	}
+
+	// Unknown timestamps are left out of request bodies
+	data, _ = json.Marshal(Monitor{ID: 1, Name: "cpu"})
+	if strings.Contains(string(data), "created_at") || strings.Contains(string(data), "modified") {
+		t.Errorf("Marshal of a monitor without timestamps = %s", data)
+	}
+	data, _ = json.Marshal(GroupState{})
+	if strings.Contains(string(data), "_ts") {
+		t.Errorf("Marshal of a group without timestamps = %s", data)
+	}
+}
@@ -0,0 +1,133 @@
+package datadogtest
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+// keyFiles holds key files as a Vault agent renders them
+type keyFiles struct {
+	api, app string
+	modified time.Time
+}
+
+func newKeyFiles(t *testing.T) *keyFiles {
+	t.Helper()
+	dir := t.TempDir()
+	files := &keyFiles{api: filepath.Join(dir, "api-key"), app: filepath.Join(dir, "app-key"), modified: time.Now().Add(-time.Hour)}
+	files.write(t, APIKey, AppKey)
+	return files
+}
+
+// write writes the keys and gives the files the same modification time as
+// before, so a key of the same length isn't noticed until the API rejects it
+func (f *keyFiles) write(t *testing.T, apiKey, appKey string) {
+	t.Helper()
+	for path, key := range map[string]string{f.api: apiKey, f.app: appKey} {
+		if err := os.WriteFile(path, []byte(key+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, f.modified, f.modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// updateAll updates the monitors in turn, calling rotate before the third one
+func updateAll(t *testing.T, client *datadog.Client, monitors []datadog.Monitor, rotate func()) []error {
+	t.Helper()
+	var errs []error
+	for i, monitor := range monitors {
+		if i == 2 {
+			rotate()
+		}
+		monitor.Message = "updated"
+		_, err := client.UpdateMonitor(monitor.ID, &monitor)
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+func TestCredentialsRotatedMidBatch(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		// apiKey and appKey are the rotated keys written to the files
+		apiKey, appKey string
+		// rejected is the number of requests of the third monitor the API
+		// rejects before the rotated keys are sent
+		rejected int
+	}{
+		// A key of another length is picked up before the request is sent
+		{"changed file", "rotated-api-key", "rotated-app-key", 0},
+		// Keys of the same length in files with the same modification time
+		// are only read again after a 403, and the request retried
+		{"unnoticed change", strings.Repeat("a", len(APIKey)), strings.Repeat("b", len(AppKey)), 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := NewServer()
+			defer srv.Close()
+			var monitors []datadog.Monitor
+			for i := 0; i < 4; i++ {
+				monitors = append(monitors, srv.AddMonitor(datadog.Monitor{Name: "CPU " + strconv.Itoa(i), Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90"}))
+			}
+			files := newKeyFiles(t)
+			credentials, err := datadog.NewFileCredentials(files.api, files.app, "", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			stats := datadog.NewStats()
+			client := newClient(t, srv, datadog.WithCredentials(credentials), datadog.WithStats(stats))
+
+			errs := updateAll(t, client, monitors, func() {
+				srv.SetKeys(tc.apiKey, tc.appKey)
+				files.write(t, tc.apiKey, tc.appKey)
+			})
+			for i, err := range errs {
+				if err != nil {
+					t.Errorf("update of monitor %d: %v", monitors[i].ID, err)
+				}
+			}
+			for _, monitor := range monitors {
+				if m, _ := srv.Monitor(monitor.ID); m.Message != "updated" {
+					t.Errorf("monitor %d not updated", monitor.ID)
+				}
+			}
+			srv.AssertRequestCount(t, 1+tc.rejected, "PUT", "/monitor/"+strconv.Itoa(monitors[2].ID))
+			srv.AssertRequestCount(t, 1, "PUT", "/monitor/"+strconv.Itoa(monitors[3].ID))
+			if retries := stats.Summary().Retries; retries != tc.rejected {
+				t.Errorf("%d retries, want %d", retries, tc.rejected)
+			}
+		})
+	}
+}
+
+func TestCredentialsRevokedMidBatch(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	var monitors []datadog.Monitor
+	for i := 0; i < 3; i++ {
+		monitors = append(monitors, srv.AddMonitor(datadog.Monitor{Name: "CPU " + strconv.Itoa(i), Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90"}))
+	}
+	files := newKeyFiles(t)
+	credentials, err := datadog.NewFileCredentials(files.api, files.app, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newClient(t, srv, datadog.WithCredentials(credentials))
+
+	// The files still hold the revoked keys: the 403 stands without a retry
+	errs := updateAll(t, client, monitors, func() { srv.SetKeys("rotated-api-key", "rotated-app-key") })
+	if errs[1] != nil {
+		t.Errorf("update before the rotation: %v", errs[1])
+	}
+	if errs[2] == nil || !strings.Contains(errs[2].Error(), "status 403") {
+		t.Errorf("update after the rotation = %v, want a 403", errs[2])
+	}
+	srv.AssertRequestCount(t, 1, "PUT", "/monitor/"+strconv.Itoa(monitors[2].ID))
+}
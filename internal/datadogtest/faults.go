@@ -0,0 +1,105 @@
+package datadogtest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Fault makes the server answer matching requests with an error instead of
+// serving them, e.g. to exercise retries on 429s or a bulk operation where
+// some monitors fail. Faults are matched in the order they were injected.
+type Fault struct {
+	// Method matches the request method; empty matches any
+	Method string
+	// Path matches the request path without /api/v1 (e.g. /monitor/1000),
+	// or a prefix of it when it ends with "*"; empty matches any
+	Path string
+	// Status is the status of the response
+	Status int
+	// Body is the response body (default: a Datadog error response)
+	Body string
+	// RateLimitReset is the X-RateLimit-Reset header, in seconds, of a 429
+	RateLimitReset int
+	// Times is how many requests the fault answers; 0 answers every one
+	Times int
+
+	hits int
+}
+
+// InjectFault adds a fault and returns it, to check its hits later
+func (s *Server) InjectFault(fault Fault) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f := fault
+	s.faults = append(s.faults, &f)
+	return &f
+}
+
+// ClearFaults removes every fault
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// RateLimited is a fault answering the first times matching requests with a
+// 429 that resets after reset seconds
+func RateLimited(method, path string, times, reset int) Fault {
+	return Fault{Method: method, Path: path, Status: http.StatusTooManyRequests, RateLimitReset: reset, Times: times}
+}
+
+// ServerError is a fault answering the first times matching requests with a
+// 500; 0 times fails every one
+func ServerError(method, path string, times int) Fault {
+	return Fault{Method: method, Path: path, Status: http.StatusInternalServerError, Times: times}
+}
+
+// Hits returns how many requests the fault answered
+func (f *Fault) Hits() int {
+	return f.hits
+}
+
+// matches reports whether the fault answers a request with this method and path
+func (f *Fault) matches(method, path string) bool {
+	if f.Times > 0 && f.hits >= f.Times {
+		return false
+	}
+	if f.Method != "" && !strings.EqualFold(f.Method, method) {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(f.Path, "*"); ok {
+		return strings.HasPrefix(path, prefix)
+	}
+	return f.Path == "" || f.Path == path
+}
+
+// takeFault returns the first fault answering r, counting the hit
+func (s *Server) takeFault(r *http.Request) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := apiPath(r)
+	for _, fault := range s.faults {
+		if fault.matches(r.Method, path) {
+			fault.hits++
+			answered := *fault
+			return &answered
+		}
+	}
+	return nil
+}
+
+// write writes the fault's response
+func (f *Fault) write(w http.ResponseWriter) {
+	if f.Status == http.StatusTooManyRequests {
+		w.Header().Set("X-RateLimit-Limit", "100")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.Itoa(f.RateLimitReset))
+	}
+	if f.Body != "" {
+		w.WriteHeader(f.Status)
+		w.Write([]byte(f.Body))
+		return
+	}
+	writeErrors(w, f.Status, http.StatusText(f.Status))
+}
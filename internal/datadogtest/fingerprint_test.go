@@ -0,0 +1,108 @@
+package datadogtest
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+// lostResponses sends requests but loses the response of the first n
+// matching ones, as when the connection drops after the API processed them
+type lostResponses struct {
+	method string
+	n      int
+
+	mu sync.Mutex
+}
+
+func (l *lostResponses) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err == nil && req.Method == l.method && l.n > 0 {
+		l.n--
+		resp.Body.Close()
+		return nil, errors.New("connection reset by peer")
+	}
+	return resp, err
+}
+
+// writeTemplate writes a template file with a single monitor template
+func writeTemplate(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "cpu.json")
+	template := `{"name": "{service} CPU", "type": "metric alert", "query": "avg(last_5m):avg:system.cpu.user{service:{service}} > 90", "message": "CPU is high"}`
+	if err := os.WriteFile(path, []byte(template), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestApplyRetryAfterFailedCreate(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		// fail makes the first create fail
+		fail func(srv *Server) []datadog.Option
+		// stored is the number of monitors after the failed apply
+		stored int
+		// status is the status of the monitor when applying again
+		status datadog.ResultStatus
+	}{
+		{"server error", func(srv *Server) []datadog.Option {
+			srv.InjectFault(ServerError("POST", "/monitor", 1))
+			return nil
+		}, 0, datadog.StatusCreated},
+		{"lost response", func(srv *Server) []datadog.Option {
+			return []datadog.Option{datadog.WithHTTPClient(&http.Client{Transport: &lostResponses{method: "POST", n: 1}})}
+		}, 1, datadog.StatusExisting},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := NewServer()
+			defer srv.Close()
+			template := writeTemplate(t)
+			opts := datadog.ApplyOptions{RenderOptions: datadog.RenderOptions{Service: "api", Env: "prd"}}
+			client := newClient(t, srv, tc.fail(srv)...)
+
+			if _, err := client.ApplyTemplateWithOptions(template, opts); err == nil {
+				t.Fatal("apply succeeded despite the failed create")
+			}
+			if got := len(srv.Monitors()); got != tc.stored {
+				t.Fatalf("%d monitor(s) stored after the failed create, want %d", got, tc.stored)
+			}
+
+			srv.ResetRequests()
+			results, err := newClient(t, srv).ApplyTemplateWithOptions(template, opts)
+			if err != nil {
+				t.Fatalf("apply again: %v", err)
+			}
+			if len(results) != 1 || results[0].Status != tc.status {
+				t.Fatalf("results %+v, want one %s", results, tc.status)
+			}
+			srv.AssertRequestCount(t, 1-tc.stored, "POST", "/monitor")
+
+			monitors := srv.Monitors()
+			if len(monitors) != 1 {
+				t.Fatalf("%d monitors stored, want 1", len(monitors))
+			}
+			if !datadog.IsManaged(monitors[0]) || !hasTagPrefix(monitors[0].Tags, datadog.FingerprintTagKey+":") {
+				t.Errorf("stored monitor lacks the managed tags: %v", monitors[0].Tags)
+			}
+		})
+	}
+}
+
+// hasTagPrefix reports whether one of tags starts with prefix
+func hasTagPrefix(tags []string, prefix string) bool {
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package datadogtest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+func TestOrgCheck(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		expected string
+		// fault answers the org lookup, if set
+		fault   *Fault
+		wantErr string
+	}{
+		{"name", "datadog test", nil, ""},
+		{"public ID", "abc123", nil, ""},
+		{"mismatch", "Production", nil, `the keys belong to org "Datadog Test" (public ID abc123), not "Production"`},
+		{"missing permission", "Datadog Test", &Fault{Method: "GET", Path: "/org", Status: http.StatusForbidden}, "needs the org_management scope"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := NewServer()
+			defer srv.Close()
+			monitor := srv.AddMonitor(datadog.Monitor{Name: "CPU", Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90"})
+			if tc.fault != nil {
+				srv.InjectFault(*tc.fault)
+			}
+			check := &datadog.OrgCheck{Expected: tc.expected}
+			client := newClient(t, srv, datadog.WithOrgCheck(check))
+
+			// Reads don't need the check
+			if _, err := client.GetMonitor(monitor.ID); err != nil {
+				t.Fatalf("GetMonitor: %v", err)
+			}
+			srv.AssertRequestCount(t, 0, "GET", "/org")
+
+			for i := 0; i < 2; i++ {
+				monitor.Message = "updated"
+				_, err := client.UpdateMonitor(monitor.ID, &monitor)
+				if tc.wantErr == "" {
+					if err != nil {
+						t.Fatalf("UpdateMonitor: %v", err)
+					}
+					continue
+				}
+				if !datadog.IsOrgCheckFailed(err) || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("UpdateMonitor = %v, want an org check error with %q", err, tc.wantErr)
+				}
+			}
+
+			// The org is looked up once, even when the check fails
+			srv.AssertRequestCount(t, 1, "GET", "/org")
+			if tc.wantErr == "" {
+				srv.AssertRequestCount(t, 2, "PUT", "/monitor/"+strconv.Itoa(monitor.ID))
+			} else {
+				srv.AssertNoMutations(t)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package datadogtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// UpdateSnapshotsEnv rewrites the snapshot files of AssertSnapshot instead of
+// comparing with them when set, e.g. DDMM_UPDATE_SNAPSHOTS=1 go test ./...
+const UpdateSnapshotsEnv = "DDMM_UPDATE_SNAPSHOTS"
+
+// Request is a request the server received
+type Request struct {
+	Method string
+	// Path is the request path without /api/v1, e.g. /monitor/1000
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// String returns the method and path of the request, e.g. "PUT /monitor/1000"
+func (r Request) String() string {
+	return r.Method + " " + r.Path
+}
+
+// Decode decodes the JSON body of the request into v
+func (r Request) Decode(v interface{}) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// apiPath returns the path of a request without /api/v1
+func apiPath(r *http.Request) string {
+	return strings.TrimPrefix(r.URL.Path, "/api/v1")
+}
+
+// record adds a request to the requests received
+func (s *Server) record(r *http.Request, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   apiPath(r),
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+}
+
+// Requests returns the requests received, in order
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// RequestsTo returns the requests received with this method (empty for any)
+// and path; a path ending with "*" matches a prefix, as in Fault
+func (s *Server) RequestsTo(method, path string) []Request {
+	matcher := Fault{Method: method, Path: path}
+	var matched []Request
+	for _, r := range s.Requests() {
+		if matcher.matches(r.Method, r.Path) {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+// Mutations returns the requests received that change something: every
+// method but GET and HEAD, except monitor validation
+func (s *Server) Mutations() []Request {
+	var mutations []Request
+	for _, r := range s.Requests() {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead && r.Path != "/monitor/validate" {
+			mutations = append(mutations, r)
+		}
+	}
+	return mutations
+}
+
+// ResetRequests forgets the requests received so far
+func (s *Server) ResetRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+}
+
+// AssertRequestCount fails the test unless n requests with this method and
+// path were received
+func (s *Server) AssertRequestCount(t testing.TB, n int, method, path string) {
+	t.Helper()
+	if got := len(s.RequestsTo(method, path)); got != n {
+		t.Errorf("datadogtest: got %d %s %s request(s), want %d; received:\n%s", got, method, path, n, s.requestList())
+	}
+}
+
+// AssertRequested fails the test unless a request with this method and path
+// was received
+func (s *Server) AssertRequested(t testing.TB, method, path string) {
+	t.Helper()
+	if len(s.RequestsTo(method, path)) == 0 {
+		t.Errorf("datadogtest: no %s %s request; received:\n%s", method, path, s.requestList())
+	}
+}
+
+// AssertNoMutations fails the test if any request changed something, e.g.
+// for a dry run
+func (s *Server) AssertNoMutations(t testing.TB) {
+	t.Helper()
+	if mutations := s.Mutations(); len(mutations) > 0 {
+		var lines []string
+		for _, r := range mutations {
+			lines = append(lines, "  "+r.String())
+		}
+		t.Errorf("datadogtest: got %d mutating request(s), want none:\n%s", len(mutations), strings.Join(lines, "\n"))
+	}
+}
+
+// requestList returns the requests received, one per line
+func (s *Server) requestList() string {
+	var lines []string
+	for _, r := range s.Requests() {
+		lines = append(lines, "  "+r.String())
+	}
+	if len(lines) == 0 {
+		return "  (none)"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Snapshot returns the store as indented JSON: the monitors and downtimes
+// sorted by ID. Set Now to a fixed clock for stable timestamps.
+func (s *Server) Snapshot() []byte {
+	data, _ := json.MarshalIndent(struct {
+		Monitors  interface{} `json:"monitors"`
+		Downtimes interface{} `json:"downtimes"`
+	}{s.Monitors(), s.Downtimes()}, "", "  ")
+	return append(data, '\n')
+}
+
+// AssertSnapshot fails the test unless the store matches the snapshot file
+// at path (see Snapshot). With UpdateSnapshotsEnv set, the file is written
+// instead.
+func (s *Server) AssertSnapshot(t testing.TB, path string) {
+	t.Helper()
+	got := s.Snapshot()
+	if os.Getenv(UpdateSnapshotsEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("datadogtest: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("datadogtest: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("datadogtest: %v (write it with %s=1)", err, UpdateSnapshotsEnv)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("datadogtest: store doesn't match %s (update it with %s=1):\n%s", path, UpdateSnapshotsEnv, lineDiff(string(want), string(got)))
+	}
+}
+
+// lineDiff returns the lines of want and got that differ, prefixed with -
+// and +, line by line
+func lineDiff(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	var b strings.Builder
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			fmt.Fprintf(&b, "line %d:\n  - %s\n  + %s\n", i+1, w, g)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,790 @@
+// Package datadogtest provides a fake Datadog API for tests: an httptest
+// server backed by an in-memory store of monitors and downtimes, with monitor
+// listing (tags, search, pagination, group states), mute/unmute, validation, the org lookup,
+// the active metrics list, injectable faults (429s, 500s, ...) and a record of every request received.
+//
+//	srv := datadogtest.NewServer()
+//	defer srv.Close()
+//	srv.AddMonitor(datadog.Monitor{Name: "CPU", Type: "metric alert", Query: "..."})
+//	client, _ := datadog.NewClientWithOptions(srv.ClientOptions()...)
+//
+// Commands are pointed at it with srv.Setenv(t).
+package datadogtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+const (
+	// APIKey and AppKey are the keys the server accepts until SetKeys
+	APIKey = "datadogtest-api-key"
+	AppKey = "datadogtest-app-key"
+)
+
+// Server is a fake Datadog API. Its methods are safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	// Validate, when set, replaces the default validation of created,
+	// updated and validated monitors (name, type and query are required). It
+	// returns the errors of the 400 response, none for a valid monitor.
+	Validate func(monitor datadog.Monitor) []string
+	// Now is the clock of created and modified timestamps (default time.Now)
+	Now func() time.Time
+	// Received, when set, is called with every request before it is served,
+	// e.g. to interrupt a command in the middle of a batch
+	Received func(r *http.Request)
+
+	mu        sync.Mutex
+	monitors  map[int]datadog.Monitor
+	downtimes map[int]datadog.Downtime
+	metrics   map[string]time.Time
+	nextID    int
+	org       datadog.Org
+	apiKey    string
+	appKey    string
+	faults    []*Fault
+	requests  []Request
+}
+
+// NewServer starts a fake Datadog API with an empty store, for the org
+// "Datadog Test" (public ID abc123). Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		Now:       time.Now,
+		monitors:  make(map[int]datadog.Monitor),
+		downtimes: make(map[int]datadog.Downtime),
+		metrics:   make(map[string]time.Time),
+		nextID:    1000,
+		org:       datadog.Org{Name: "Datadog Test", PublicID: "abc123"},
+		apiKey:    APIKey,
+		appKey:    AppKey,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// APIURL returns the API v1 base URL of the server
+func (s *Server) APIURL() string {
+	return s.URL + "/api/v1"
+}
+
+// ClientOptions returns the options of a client of the server
+func (s *Server) ClientOptions() []datadog.Option {
+	return []datadog.Option{
+		datadog.WithAPIKey(APIKey),
+		datadog.WithAppKey(AppKey),
+		datadog.WithBaseURL(s.APIURL()),
+	}
+}
+
+// Setenv points the clients created from the environment, as commands
+// create them, at the server for the duration of the test: keys, API URL,
+// no response cache and no update check
+func (s *Server) Setenv(t testing.TB) {
+	t.Helper()
+	for key, value := range map[string]string{
+		"DD_API_KEY":           APIKey,
+		"DD_APP_KEY":           AppKey,
+		datadog.APIURLEnv:      s.APIURL(),
+		"DDMM_NO_CACHE":        "1",
+		"DDMM_NO_UPDATE_CHECK": "1",
+	} {
+		if setter, ok := t.(interface{ Setenv(key, value string) }); ok {
+			setter.Setenv(key, value)
+		} else {
+			t.Fatalf("datadogtest: %T can't set environment variables", t)
+		}
+	}
+}
+
+// SetKeys rotates the keys: from now on requests with other keys, the
+// previous ones included, are rejected with a 403
+func (s *Server) SetKeys(apiKey, appKey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apiKey, s.appKey = apiKey, appKey
+}
+
+// SetOrg sets the organization GET /org returns
+func (s *Server) SetOrg(org datadog.Org) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.org = org
+}
+
+// AddMonitor stores a monitor, as if created earlier, and returns it. A
+// monitor without an ID gets the next one.
+func (s *Server) AddMonitor(monitor datadog.Monitor) datadog.Monitor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if monitor.ID == 0 {
+		monitor.ID = s.newID()
+	} else if monitor.ID >= s.nextID {
+		s.nextID = monitor.ID + 1
+	}
+	if monitor.OverallState == "" {
+		monitor.OverallState = "OK"
+	}
+	s.monitors[monitor.ID] = copyMonitor(monitor)
+	return monitor
+}
+
+// Monitor returns the stored monitor with this ID
+func (s *Server) Monitor(id int) (datadog.Monitor, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	monitor, ok := s.monitors[id]
+	return copyMonitor(monitor), ok
+}
+
+// SetMonitorState sets the overall state of a stored monitor, as when it
+// receives data or recovers; it reports whether the monitor exists
+func (s *Server) SetMonitorState(id int, state string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	monitor, ok := s.monitors[id]
+	if ok {
+		monitor.OverallState = state
+		s.monitors[id] = monitor
+	}
+	return ok
+}
+
+// Monitors returns the stored monitors, sorted by ID
+func (s *Server) Monitors() []datadog.Monitor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sortedMonitors()
+}
+
+// AddDowntime stores a downtime and returns it with its ID
+func (s *Server) AddDowntime(downtime datadog.Downtime) datadog.Downtime {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if downtime.ID == 0 {
+		downtime.ID = s.newID()
+	}
+	s.downtimes[downtime.ID] = downtime
+	return downtime
+}
+
+// Downtimes returns the stored downtimes, sorted by ID
+func (s *Server) Downtimes() []datadog.Downtime {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sortedDowntimes()
+}
+
+// AddMetric records that a metric last reported a value at the given time;
+// GET /metrics lists it when that is at or after from
+func (s *Server) AddMetric(name string, lastReported time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metrics[name] = lastReported
+}
+
+// newID returns the next monitor or downtime ID; s.mu is held
+func (s *Server) newID() int {
+	id := s.nextID
+	s.nextID++
+	return id
+}
+
+// sortedMonitors returns copies of the stored monitors sorted by ID; s.mu is held
+func (s *Server) sortedMonitors() []datadog.Monitor {
+	monitors := make([]datadog.Monitor, 0, len(s.monitors))
+	for _, monitor := range s.monitors {
+		monitors = append(monitors, copyMonitor(monitor))
+	}
+	sort.Slice(monitors, func(i, j int) bool { return monitors[i].ID < monitors[j].ID })
+	return monitors
+}
+
+// sortedDowntimes returns the stored downtimes sorted by ID; s.mu is held
+func (s *Server) sortedDowntimes() []datadog.Downtime {
+	downtimes := make([]datadog.Downtime, 0, len(s.downtimes))
+	for _, downtime := range s.downtimes {
+		downtimes = append(downtimes, downtime)
+	}
+	sort.Slice(downtimes, func(i, j int) bool { return downtimes[i].ID < downtimes[j].ID })
+	return downtimes
+}
+
+// copyMonitor deep-copies a monitor through JSON so callers and the store
+// never share tags or options
+func copyMonitor(monitor datadog.Monitor) datadog.Monitor {
+	data, err := json.Marshal(monitor)
+	if err != nil {
+		return monitor
+	}
+	var copied datadog.Monitor
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return monitor
+	}
+	return copied
+}
+
+// serveHTTP records the request, applies the first matching fault, checks
+// the keys and routes the request
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.record(r, body)
+	if s.Received != nil {
+		s.Received(r)
+	}
+
+	if fault := s.takeFault(r); fault != nil {
+		fault.write(w)
+		return
+	}
+	s.mu.Lock()
+	apiKey, appKey := s.apiKey, s.appKey
+	s.mu.Unlock()
+	if r.Header.Get("DD-API-KEY") != apiKey || r.Header.Get("DD-APPLICATION-KEY") != appKey {
+		writeErrors(w, http.StatusForbidden, "Forbidden")
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case path == "/org" && r.Method == http.MethodGet:
+		s.mu.Lock()
+		org := s.org
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, map[string]interface{}{"orgs": []datadog.Org{org}})
+	case segments[0] == "monitor":
+		s.serveMonitor(w, r, segments[1:], body)
+	case segments[0] == "downtime":
+		s.serveDowntime(w, r, segments[1:], body)
+	case path == "/metrics" && r.Method == http.MethodGet:
+		s.serveMetrics(w, r)
+	default:
+		writeErrors(w, http.StatusNotFound, fmt.Sprintf("datadogtest: no route for %s %s", r.Method, r.URL.Path))
+	}
+}
+
+// serveMetrics serves GET /metrics: the metrics that reported since from
+func (s *Server) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	from, err := strconv.ParseInt(r.URL.Query().Get("from"), 10, 64)
+	if err != nil {
+		writeErrors(w, http.StatusBadRequest, "from is required")
+		return
+	}
+	s.mu.Lock()
+	metrics := []string{}
+	for name, lastReported := range s.metrics {
+		if lastReported.Unix() >= from {
+			metrics = append(metrics, name)
+		}
+	}
+	s.mu.Unlock()
+	sort.Strings(metrics)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"from": strconv.FormatInt(from, 10), "metrics": metrics})
+}
+
+// serveMonitor serves the /monitor endpoints
+func (s *Server) serveMonitor(w http.ResponseWriter, r *http.Request, segments []string, body []byte) {
+	switch {
+	case len(segments) == 0 && r.Method == http.MethodGet:
+		s.listMonitors(w, r)
+	case len(segments) == 0 && r.Method == http.MethodPost:
+		s.createMonitor(w, body)
+	case len(segments) == 1 && segments[0] == "validate" && r.Method == http.MethodPost:
+		var monitor datadog.Monitor
+		if err := json.Unmarshal(body, &monitor); err != nil {
+			writeErrors(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errs := s.validate(monitor); len(errs) > 0 {
+			writeErrors(w, http.StatusBadRequest, errs...)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{})
+	case len(segments) == 1 && segments[0] == "bulk_resolve" && r.Method == http.MethodPost:
+		s.bulkResolve(w, body)
+	default:
+		id, err := strconv.Atoi(segments[0])
+		if err != nil {
+			writeErrors(w, http.StatusNotFound, "Monitor not found")
+			return
+		}
+		s.serveMonitorID(w, r, id, segments[1:], body)
+	}
+}
+
+// serveMonitorID serves the /monitor/{id} endpoints
+func (s *Server) serveMonitorID(w http.ResponseWriter, r *http.Request, id int, segments []string, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	monitor, ok := s.monitors[id]
+	if !ok {
+		writeErrors(w, http.StatusNotFound, "Monitor not found")
+		return
+	}
+
+	switch {
+	case len(segments) == 0 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, withGroupStates(monitor, r.URL.Query().Get("group_states")))
+	case len(segments) == 0 && r.Method == http.MethodDelete:
+		delete(s.monitors, id)
+		writeJSON(w, http.StatusOK, map[string]int{"deleted_monitor_id": id})
+	case len(segments) == 0 && r.Method == http.MethodPut:
+		s.updateMonitor(w, monitor, body)
+	case len(segments) == 1 && segments[0] == "mute" && r.Method == http.MethodPost:
+		var req struct {
+			Scope string `json:"scope"`
+			End   *int64 `json:"end"`
+		}
+		json.Unmarshal(body, &req)
+		if req.Scope == "" {
+			req.Scope = datadog.WholeMonitorScope
+		}
+		silenced := silencedOf(&monitor)
+		if req.End != nil {
+			silenced[req.Scope] = *req.End
+		} else {
+			silenced[req.Scope] = nil
+		}
+		s.monitors[id] = monitor
+		writeJSON(w, http.StatusOK, monitor)
+	case len(segments) == 1 && segments[0] == "unmute" && r.Method == http.MethodPost:
+		var req struct {
+			Scope     string `json:"scope"`
+			AllScopes bool   `json:"all_scopes"`
+		}
+		json.Unmarshal(body, &req)
+		silenced := silencedOf(&monitor)
+		if req.AllScopes {
+			for scope := range silenced {
+				delete(silenced, scope)
+			}
+		} else {
+			if req.Scope == "" {
+				req.Scope = datadog.WholeMonitorScope
+			}
+			delete(silenced, req.Scope)
+		}
+		s.monitors[id] = monitor
+		writeJSON(w, http.StatusOK, monitor)
+	default:
+		writeErrors(w, http.StatusNotFound, fmt.Sprintf("datadogtest: no route for %s %s", r.Method, r.URL.Path))
+	}
+}
+
+// silencedOf returns the options.silenced map of a monitor, creating it
+func silencedOf(monitor *datadog.Monitor) map[string]interface{} {
+	if monitor.Options == nil {
+		monitor.Options = make(map[string]interface{})
+	}
+	silenced, ok := monitor.Options["silenced"].(map[string]interface{})
+	if !ok {
+		silenced = make(map[string]interface{})
+		monitor.Options["silenced"] = silenced
+	}
+	return silenced
+}
+
+// listMonitors serves GET /monitor: monitor_tags (comma-separated, "!" to
+// exclude), query (see matchSearch), and page/page_size pagination
+func (s *Server) listMonitors(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	monitors := s.sortedMonitors()
+	s.mu.Unlock()
+
+	q := r.URL.Query()
+	var tags []string
+	if raw := q.Get("monitor_tags"); raw != "" {
+		tags = strings.Split(raw, ",")
+	}
+	search := q.Get("query")
+
+	matched := make([]datadog.Monitor, 0, len(monitors))
+	for _, monitor := range monitors {
+		if matchTags(monitor, tags) && matchSearch(monitor, search) {
+			matched = append(matched, withGroupStates(monitor, q.Get("group_states")))
+		}
+	}
+
+	if raw := q.Get("page"); raw != "" {
+		page, err := strconv.Atoi(raw)
+		size, sizeErr := strconv.Atoi(q.Get("page_size"))
+		if sizeErr != nil || size <= 0 {
+			size = 100
+		}
+		if err != nil || page < 0 {
+			writeErrors(w, http.StatusBadRequest, "invalid page")
+			return
+		}
+		start := page * size
+		if start > len(matched) {
+			start = len(matched)
+		}
+		end := start + size
+		if end > len(matched) {
+			end = len(matched)
+		}
+		matched = matched[start:end]
+	}
+	writeJSON(w, http.StatusOK, matched)
+}
+
+// withGroupStates returns a monitor as the API returns it for a group_states
+// parameter: without State when none is requested, else with the groups in
+// the requested states only (all for every group)
+func withGroupStates(monitor datadog.Monitor, groupStates string) datadog.Monitor {
+	if groupStates == "" || monitor.State == nil {
+		monitor.State = nil
+		return monitor
+	}
+	states := strings.Split(strings.ToLower(groupStates), ",")
+	groups := make(map[string]datadog.GroupState)
+	for name, group := range monitor.State.Groups {
+		for _, state := range states {
+			if state == datadog.GroupStatesAll || strings.EqualFold(group.Status, state) {
+				groups[name] = group
+				break
+			}
+		}
+	}
+	monitor.State = &datadog.MonitorState{Groups: groups}
+	return monitor
+}
+
+// matchTags reports whether a monitor has every tag, and none of the tags
+// prefixed with "!"
+func matchTags(monitor datadog.Monitor, tags []string) bool {
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if excluded := strings.TrimPrefix(tag, "!"); excluded != tag {
+			if hasTag(monitor, excluded) {
+				return false
+			}
+			continue
+		}
+		if tag != "" && !hasTag(monitor, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasTag reports whether a monitor has tag
+func hasTag(monitor datadog.Monitor, tag string) bool {
+	for _, t := range monitor.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSearch reports whether a monitor matches a search query: every term
+// must match. key:value terms match tags (key:(a OR b) any of the values,
+// with type: and status: matching the monitor type and overall state);
+// other terms match the name, case-insensitively. A "-" prefix negates a term.
+func matchSearch(monitor datadog.Monitor, search string) bool {
+	for _, term := range searchTerms(search) {
+		negate := strings.HasPrefix(term, "-")
+		if negate {
+			term = term[1:]
+		}
+		if matchTerm(monitor, term) == negate {
+			return false
+		}
+	}
+	return true
+}
+
+// searchTerms splits a search query on spaces outside parentheses and
+// quotes, dropping AND
+func searchTerms(search string) []string {
+	var terms []string
+	var b strings.Builder
+	depth, quoted := 0, false
+	flush := func() {
+		if term := b.String(); term != "" && term != "AND" {
+			terms = append(terms, term)
+		}
+		b.Reset()
+	}
+	for _, r := range search {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == '(' && !quoted:
+			depth++
+		case r == ')' && !quoted && depth > 0:
+			depth--
+		case r == ' ' && depth == 0 && !quoted:
+			flush()
+			continue
+		}
+		b.WriteRune(r)
+	}
+	flush()
+	return terms
+}
+
+// matchTerm reports whether a monitor matches one search term
+func matchTerm(monitor datadog.Monitor, term string) bool {
+	key, value, hasKey := strings.Cut(term, ":")
+	if !hasKey {
+		return strings.Contains(strings.ToLower(monitor.Name), strings.ToLower(strings.Trim(term, `"`)))
+	}
+	values := []string{value}
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		values = strings.Split(strings.Trim(value, "()"), " OR ")
+	}
+	for _, v := range values {
+		v = strings.Trim(strings.TrimSpace(v), `"`)
+		switch key {
+		case "type":
+			if strings.EqualFold(monitor.Type, v) {
+				return true
+			}
+		case "status":
+			if strings.EqualFold(monitor.OverallState, v) {
+				return true
+			}
+		default:
+			if hasTag(monitor, key+":"+v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// createMonitor serves POST /monitor
+func (s *Server) createMonitor(w http.ResponseWriter, body []byte) {
+	var monitor datadog.Monitor
+	if err := json.Unmarshal(body, &monitor); err != nil {
+		writeErrors(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if errs := s.validate(monitor); len(errs) > 0 {
+		writeErrors(w, http.StatusBadRequest, errs...)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.Now()
+	monitor.ID = s.newID()
+	monitor.OverallState = "No Data"
+	monitor.CreatedAt = timestamp(now)
+	monitor.Modified = timestamp(now)
+	s.monitors[monitor.ID] = copyMonitor(monitor)
+	writeJSON(w, http.StatusOK, monitor)
+}
+
+// updateMonitor serves PUT /monitor/{id}: the fields of the body replace
+// those of the monitor; s.mu is held
+func (s *Server) updateMonitor(w http.ResponseWriter, monitor datadog.Monitor, body []byte) {
+	updated := copyMonitor(monitor)
+	if err := json.Unmarshal(body, &updated); err != nil {
+		writeErrors(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var fields map[string]json.RawMessage
+	json.Unmarshal(body, &fields)
+	if _, ok := fields["options"]; ok {
+		// Options are replaced, not merged
+		updated.Options = nil
+		json.Unmarshal(fields["options"], &updated.Options)
+	}
+	if errs := s.validate(updated); len(errs) > 0 {
+		writeErrors(w, http.StatusBadRequest, errs...)
+		return
+	}
+
+	updated.ID = monitor.ID
+	updated.CreatedAt = monitor.CreatedAt
+	updated.Modified = timestamp(s.Now())
+	s.monitors[monitor.ID] = copyMonitor(updated)
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// timestamp returns t as the API reports it, an RFC3339 string
+func timestamp(t time.Time) datadog.Timestamp {
+	var ts datadog.Timestamp
+	json.Unmarshal([]byte(strconv.Quote(t.UTC().Format(time.RFC3339))), &ts)
+	return ts
+}
+
+// bulkResolve serves POST /monitor/bulk_resolve: it sets the resolved groups
+// (every group for ALL_GROUPS) to OK, and the monitor too once none of its
+// groups is left alerting, and returns the monitors with their group states
+func (s *Server) bulkResolve(w http.ResponseWriter, body []byte) {
+	var req struct {
+		Resolve []map[string]string `json:"resolve"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeErrors(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var order []int
+	seen := make(map[int]bool)
+	for _, entry := range req.Resolve {
+		for rawID := range entry {
+			id, err := strconv.Atoi(rawID)
+			if err != nil {
+				writeErrors(w, http.StatusBadRequest, "invalid monitor ID "+rawID)
+				return
+			}
+			if _, ok := s.monitors[id]; !ok {
+				writeErrors(w, http.StatusNotFound, "Monitor not found")
+				return
+			}
+			s.monitors[id] = resolveGroup(s.monitors[id], entry[rawID])
+			if !seen[id] {
+				seen[id] = true
+				order = append(order, id)
+			}
+		}
+	}
+	resolved := make([]datadog.Monitor, 0, len(order))
+	for _, id := range order {
+		resolved = append(resolved, s.monitors[id])
+	}
+	writeJSON(w, http.StatusOK, resolved)
+}
+
+// resolveGroup returns the monitor with the group (every group for
+// ALL_GROUPS) set to OK; unknown groups are left alone
+func resolveGroup(monitor datadog.Monitor, group string) datadog.Monitor {
+	if group == datadog.AllGroups {
+		monitor.OverallState = "OK"
+	}
+	if monitor.State == nil {
+		return monitor
+	}
+	groups := make(map[string]datadog.GroupState, len(monitor.State.Groups))
+	alerting := false
+	for name, state := range monitor.State.Groups {
+		if group == datadog.AllGroups || name == group {
+			state.Status = "OK"
+		}
+		if state.Status != "OK" {
+			alerting = true
+		}
+		groups[name] = state
+	}
+	monitor.State = &datadog.MonitorState{Groups: groups}
+	if !alerting {
+		monitor.OverallState = "OK"
+	}
+	return monitor
+}
+
+// validate returns the validation errors of a monitor
+func (s *Server) validate(monitor datadog.Monitor) []string {
+	if s.Validate != nil {
+		return s.Validate(monitor)
+	}
+	var errs []string
+	if strings.TrimSpace(monitor.Name) == "" {
+		errs = append(errs, "Name is required")
+	}
+	if monitor.Type == "" {
+		errs = append(errs, "Type is required")
+	}
+	if strings.TrimSpace(monitor.Query) == "" {
+		errs = append(errs, "The value provided for parameter 'query' is invalid")
+	}
+	return errs
+}
+
+// serveDowntime serves the /downtime endpoints
+func (s *Server) serveDowntime(w http.ResponseWriter, r *http.Request, segments []string, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(segments) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			downtimes := s.sortedDowntimes()
+			if r.URL.Query().Get("current_only") == "true" {
+				var current []datadog.Downtime
+				for _, downtime := range downtimes {
+					if downtime.Active && !downtime.Disabled {
+						current = append(current, downtime)
+					}
+				}
+				downtimes = current
+			}
+			if downtimes == nil {
+				downtimes = []datadog.Downtime{}
+			}
+			writeJSON(w, http.StatusOK, downtimes)
+		case http.MethodPost:
+			var downtime datadog.Downtime
+			if err := json.Unmarshal(body, &downtime); err != nil {
+				writeErrors(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			downtime.ID = s.newID()
+			downtime.Active = downtime.Start <= s.Now().Unix()
+			s.downtimes[downtime.ID] = downtime
+			writeJSON(w, http.StatusOK, downtime)
+		default:
+			writeErrors(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	id, err := strconv.Atoi(segments[0])
+	downtime, ok := s.downtimes[id]
+	if err != nil || !ok || len(segments) > 1 {
+		writeErrors(w, http.StatusNotFound, "Downtime not found")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, downtime)
+	case http.MethodPut:
+		if err := json.Unmarshal(body, &downtime); err != nil {
+			writeErrors(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		downtime.ID = id
+		s.downtimes[id] = downtime
+		writeJSON(w, http.StatusOK, downtime)
+	case http.MethodDelete:
+		downtime.Disabled = true
+		downtime.Active = false
+		s.downtimes[id] = downtime
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeErrors(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// writeJSON writes value as a JSON response with status
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+// writeErrors writes a Datadog error response: {"errors": [...]}
+func writeErrors(w http.ResponseWriter, status int, errs ...string) {
+	writeJSON(w, status, map[string][]string{"errors": errs})
+}
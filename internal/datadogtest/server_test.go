@@ -0,0 +1,199 @@
+package datadogtest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+// newClient returns a client of srv with the options given on top
+func newClient(t *testing.T, srv *Server, opts ...datadog.Option) *datadog.Client {
+	t.Helper()
+	t.Setenv("DDMM_NO_CACHE", "1")
+	client, err := datadog.NewClientWithOptions(append(srv.ClientOptions(), opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestServerMonitors(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddMonitor(datadog.Monitor{Name: "CPU", Type: "metric alert", Query: "avg(last_5m):avg:system.cpu.user{*} > 90", Tags: []string{"service:api"}})
+	client := newClient(t, srv)
+
+	created, err := client.CreateMonitor(&datadog.Monitor{Name: "Disk", Type: "metric alert", Query: "avg(last_5m):avg:system.disk.in_use{*} > 0.9", Tags: []string{"service:db"}})
+	if err != nil {
+		t.Fatalf("CreateMonitor: %v", err)
+	}
+	if created.ID != 1001 {
+		t.Errorf("created monitor ID %d, want 1001", created.ID)
+	}
+
+	monitors, err := client.ListMonitors([]string{"service:api"}, "")
+	if err != nil {
+		t.Fatalf("ListMonitors: %v", err)
+	}
+	if len(monitors) != 1 || monitors[0].Name != "CPU" {
+		t.Errorf("ListMonitors(service:api) = %+v, want the CPU monitor", monitors)
+	}
+
+	created.Name = "Disk usage"
+	if _, err := client.UpdateMonitor(created.ID, created); err != nil {
+		t.Fatalf("UpdateMonitor: %v", err)
+	}
+	if m, _ := srv.Monitor(created.ID); m.Name != "Disk usage" {
+		t.Errorf("stored name %q, want Disk usage", m.Name)
+	}
+
+	if err := client.DeleteMonitor(1000); err != nil {
+		t.Fatalf("DeleteMonitor: %v", err)
+	}
+	if _, ok := srv.Monitor(1000); ok {
+		t.Error("monitor 1000 still stored after delete")
+	}
+	if _, err := client.GetMonitor(1000); !datadog.IsMonitorNotFound(err) {
+		t.Errorf("GetMonitor of a deleted monitor = %v, want not found", err)
+	}
+
+	srv.AssertRequestCount(t, 1, "POST", "/monitor")
+	srv.AssertRequestCount(t, 1, "PUT", "/monitor/1001")
+	srv.AssertRequested(t, "DELETE", "/monitor/1000")
+	if got := len(srv.Mutations()); got != 3 {
+		t.Errorf("%d mutations, want 3", got)
+	}
+	srv.ResetRequests()
+	srv.AssertNoMutations(t)
+}
+
+func TestServerGroupStates(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	monitor := srv.AddMonitor(datadog.Monitor{
+		Name:  "CPU",
+		Type:  "metric alert",
+		Query: "avg(last_5m):avg:system.cpu.user{*} by {host} > 90",
+		State: &datadog.MonitorState{Groups: map[string]datadog.GroupState{
+			"host:a": {Status: "Alert"},
+			"host:b": {Status: "OK"},
+			"host:c": {Status: "No Data"},
+		}},
+	})
+	client := newClient(t, srv)
+
+	// Like the API, group states are only returned when requested, for the
+	// requested states
+	for _, tc := range []struct {
+		states []string
+		want   string
+	}{
+		{nil, ""},
+		{[]string{"alert"}, "host:a"},
+		{[]string{"alert", "no data"}, "host:a host:c"},
+		{[]string{datadog.GroupStatesAll}, "host:a host:b host:c"},
+	} {
+		monitors, err := client.ListMonitorsWithOptions(datadog.ListMonitorsOptions{GroupStates: tc.states})
+		if err != nil {
+			t.Fatalf("ListMonitorsWithOptions: %v", err)
+		}
+		fetched, err := client.GetMonitorWithGroupStates(monitor.ID, tc.states)
+		if err != nil {
+			t.Fatalf("GetMonitorWithGroupStates: %v", err)
+		}
+		for _, m := range []datadog.Monitor{monitors[0], *fetched} {
+			if tc.states == nil && m.State != nil {
+				t.Errorf("group states returned without group_states: %+v", m.State)
+			}
+			var names []string
+			for _, group := range datadog.SortedGroups(m) {
+				names = append(names, group.Name)
+			}
+			if got := strings.Join(names, " "); got != tc.want {
+				t.Errorf("group_states %q returned groups %q, want %q", tc.states, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestServerValidation(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := newClient(t, srv)
+
+	if _, err := client.CreateMonitor(&datadog.Monitor{Name: "No query", Type: "metric alert"}); err == nil {
+		t.Error("CreateMonitor without a query succeeded")
+	}
+	srv.Validate = func(monitor datadog.Monitor) []string {
+		return []string{"custom rule"}
+	}
+	if _, err := client.CreateMonitor(&datadog.Monitor{Name: "CPU", Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 1"}); err == nil {
+		t.Error("CreateMonitor succeeded despite Validate")
+	}
+	if got := len(srv.Monitors()); got != 0 {
+		t.Errorf("%d monitor(s) stored, want none", got)
+	}
+}
+
+func TestServerFaults(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	monitor := srv.AddMonitor(datadog.Monitor{Name: "CPU", Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90"})
+	client := newClient(t, srv)
+
+	fault := srv.InjectFault(ServerError("PUT", "/monitor/*", 1))
+	if _, err := client.UpdateMonitor(monitor.ID, &monitor); err == nil {
+		t.Fatal("UpdateMonitor succeeded through a server error")
+	}
+	if _, err := client.UpdateMonitor(monitor.ID, &monitor); err != nil {
+		t.Fatalf("UpdateMonitor after the fault: %v", err)
+	}
+	if fault.Hits() != 1 {
+		t.Errorf("fault hit %d time(s), want 1", fault.Hits())
+	}
+
+	srv.InjectFault(RateLimited("GET", "", 0, 7))
+	resp, err := http.Get(srv.APIURL() + "/monitor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("X-RateLimit-Reset") != "7" {
+		t.Errorf("rate limited response: %d, reset %q", resp.StatusCode, resp.Header.Get("X-RateLimit-Reset"))
+	}
+	srv.ClearFaults()
+	if _, err := client.GetMonitor(monitor.ID); err != nil {
+		t.Errorf("GetMonitor after ClearFaults: %v", err)
+	}
+}
+
+func TestServerKeys(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := newClient(t, srv)
+	srv.SetKeys("rotated-api-key", "rotated-app-key")
+
+	if _, err := client.ListMonitors(nil, ""); err == nil || !strings.Contains(err.Error(), "status 403") {
+		t.Fatalf("ListMonitors with the previous keys = %v, want a 403", err)
+	}
+	rotated := newClient(t, srv, datadog.WithAPIKey("rotated-api-key"), datadog.WithAppKey("rotated-app-key"))
+	if _, err := rotated.ListMonitors(nil, ""); err != nil {
+		t.Errorf("ListMonitors with the rotated keys: %v", err)
+	}
+}
+
+func TestServerSnapshot(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.Now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+	client := newClient(t, srv)
+
+	if _, err := client.CreateMonitor(&datadog.Monitor{Name: "CPU", Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90", Tags: []string{"service:api"}}); err != nil {
+		t.Fatal(err)
+	}
+	srv.AddDowntime(datadog.Downtime{Scope: []string{"env:staging"}, Message: "maintenance"})
+	srv.AssertSnapshot(t, "testdata/snapshot.json")
+}
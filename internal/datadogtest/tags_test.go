@@ -0,0 +1,38 @@
+package datadogtest
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+func TestTagUpdatesSkipUnchanged(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	tagged := srv.AddMonitor(datadog.Monitor{Name: "CPU", Type: "metric alert", Query: "avg(last_5m):avg:cpu{*} > 90", Tags: []string{"service:api", "team:sre"}})
+	untagged := srv.AddMonitor(datadog.Monitor{Name: "Disk", Type: "metric alert", Query: "avg(last_5m):avg:disk{*} > 90", Tags: []string{"service:api"}})
+	client := newClient(t, srv)
+
+	if _, changed, err := client.AddTagsToMonitor(tagged.ID, []string{"team:sre", "team:sre"}); err != nil || changed {
+		t.Errorf("AddTagsToMonitor of a present tag: changed %v, %v", changed, err)
+	}
+	if _, changed, err := client.RemoveTagsFromMonitor(tagged.ID, []string{"owner:alice"}); err != nil || changed {
+		t.Errorf("RemoveTagsFromMonitor of a missing tag: changed %v, %v", changed, err)
+	}
+	srv.AssertNoMutations(t)
+
+	results, err := client.AddTagsToMonitors("api", "", "", nil, []string{"team:sre"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses := make(map[int]datadog.ResultStatus)
+	for _, result := range results {
+		statuses[result.ID] = result.Status
+	}
+	if statuses[tagged.ID] != datadog.StatusUnchanged || statuses[untagged.ID] != datadog.StatusUpdated {
+		t.Errorf("statuses %v, want %d unchanged and %d updated", statuses, tagged.ID, untagged.ID)
+	}
+	srv.AssertRequestCount(t, 0, "PUT", "/monitor/"+strconv.Itoa(tagged.ID))
+	srv.AssertRequestCount(t, 1, "PUT", "/monitor/"+strconv.Itoa(untagged.ID))
+}
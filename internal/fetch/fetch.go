@@ -0,0 +1,368 @@
+// Package fetch downloads remote template sources (HTTP URLs and Git
+// repositories) into a local cache, so they can be used like local files.
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/paths"
+)
+
+// TokenEnv is the environment variable holding the token sent to private
+// template repositories and URLs
+const TokenEnv = "DDMM_TEMPLATES_TOKEN"
+
+// DefaultTTL is how long a fetched source is used before it is fetched again
+const DefaultTTL = time.Hour
+
+// stampFile marks a complete cache entry; its modification time is the fetch time
+const stampFile = ".ddmm-fetched"
+
+// commitPattern matches a full Git commit SHA, a ref that can never move
+var commitPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// gitSchemes are the repository URL schemes Git sources may use; others
+// (file://, ext::) would let a template source read local repositories or
+// run commands
+var gitSchemes = map[string]bool{"https": true, "ssh": true}
+
+// Kind is the type of a remote source
+type Kind string
+
+// Source kinds
+const (
+	KindHTTP Kind = "http"
+	KindGit  Kind = "git"
+)
+
+// Source is a parsed remote template source:
+//
+//	https://example.com/templates/k8s.json?checksum=sha256:<hex>
+//	git::https://github.com/org/repo.git//templates/k8s?ref=v1.2.0
+type Source struct {
+	Kind Kind
+	// URL is the file URL or the repository URL, without the parameters below
+	URL string
+	// Path is the file or directory inside the repository (Git only)
+	Path string
+	// Ref is the branch, tag or commit to check out (Git only; default: HEAD)
+	Ref string
+	// Checksum is the expected SHA-256 of the file, in hex (HTTP only)
+	Checksum string
+}
+
+// IsRemote reports whether a --file/--template-dir value is a remote source
+func IsRemote(source string) bool {
+	return strings.HasPrefix(source, "git::") || strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://")
+}
+
+// ParseSource parses a remote source
+func ParseSource(source string) (Source, error) {
+	if rest, ok := strings.CutPrefix(source, "git::"); ok {
+		return parseGitSource(source, rest)
+	}
+	if !strings.HasPrefix(source, "https://") && !strings.HasPrefix(source, "http://") {
+		return Source{}, fmt.Errorf("unsupported template source %q (use https:// or git::)", source)
+	}
+
+	u, err := url.Parse(source)
+	if err != nil {
+		return Source{}, fmt.Errorf("invalid template source %q: %v", source, err)
+	}
+	s := Source{Kind: KindHTTP}
+	query := u.Query()
+	if checksum := query.Get("checksum"); checksum != "" {
+		algorithm, sum, _ := strings.Cut(checksum, ":")
+		if algorithm != "sha256" || len(sum) != 64 {
+			return Source{}, fmt.Errorf("invalid checksum %q in %s (use checksum=sha256:<64 hex digits>)", checksum, source)
+		}
+		s.Checksum = strings.ToLower(sum)
+		query.Del("checksum")
+		u.RawQuery = query.Encode()
+	}
+	if path.Base(u.Path) == "/" || path.Base(u.Path) == "." {
+		return Source{}, fmt.Errorf("template URL %s does not name a file", source)
+	}
+	s.URL = u.String()
+	return s, nil
+}
+
+// parseGitSource parses the part after git:: of a Git source
+func parseGitSource(source, rest string) (Source, error) {
+	s := Source{Kind: KindGit}
+	repo, query, _ := strings.Cut(rest, "?")
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return Source{}, fmt.Errorf("invalid template source %q: %v", source, err)
+	}
+	s.Ref = values.Get("ref")
+	// git would take a ref starting with - as an option (--upload-pack=...)
+	if strings.HasPrefix(s.Ref, "-") {
+		return Source{}, fmt.Errorf("invalid template source %q: ref must not start with '-'", source)
+	}
+
+	// The path inside the repository follows a // after the scheme's //
+	scheme, address, ok := strings.Cut(repo, "://")
+	if !ok || address == "" {
+		return Source{}, fmt.Errorf("invalid template source %q (expected git::https://host/repo.git//path?ref=...)", source)
+	}
+	if !gitSchemes[scheme] {
+		return Source{}, fmt.Errorf("unsupported repository scheme %q in template source %q (use https:// or ssh://)", scheme, source)
+	}
+	if repoPath, subdir, ok := strings.Cut(address, "//"); ok {
+		address = repoPath
+		s.Path = strings.Trim(subdir, "/")
+	}
+	if strings.Contains(s.Path, "..") {
+		return Source{}, fmt.Errorf("invalid template source %q: path must stay inside the repository", source)
+	}
+	s.URL = scheme + "://" + address
+	return s, nil
+}
+
+// pinned reports whether the source can never change, so its cache entry never expires
+func (s Source) pinned() bool {
+	return s.Checksum != "" || commitPattern.MatchString(s.Ref)
+}
+
+// cacheKey identifies the cache entry of a source; the path inside a repository
+// is not part of it, so several directories of one checkout share the entry
+func (s Source) cacheKey() string {
+	sum := sha256.Sum256([]byte(string(s.Kind) + "\n" + s.URL + "\n" + s.Ref + "\n" + s.Checksum))
+	return hex.EncodeToString(sum[:16])
+}
+
+// AuthError is returned when a source rejects the request for lack of credentials
+type AuthError struct {
+	Source string
+	Detail string
+}
+
+// Error implements the error interface
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("authentication failed for %s (%s); for private repositories set %s to an access token", e.Source, e.Detail, TokenEnv)
+}
+
+// IsAuthError reports whether err (or an error it wraps) is an AuthError
+func IsAuthError(err error) bool {
+	var target *AuthError
+	return errors.As(err, &target)
+}
+
+// Fetcher downloads remote sources into CacheDir and reuses them for TTL
+type Fetcher struct {
+	CacheDir string
+	TTL      time.Duration
+	// Refresh fetches every source again, ignoring the cache
+	Refresh bool
+	// Token is sent to HTTPS sources as a bearer token and to Git over HTTPS
+	// as the password of basic authentication; never over plain HTTP
+	Token      string
+	HTTPClient *http.Client
+}
+
+// DefaultCacheDir returns the directory fetched templates are cached in
+func DefaultCacheDir() string {
+	return filepath.Join(paths.CacheDir(), "templates")
+}
+
+// NewFetcher returns a Fetcher using the default cache directory and TTL, and
+// the token from TokenEnv
+func NewFetcher() *Fetcher {
+	return &Fetcher{
+		CacheDir:   DefaultCacheDir(),
+		TTL:        DefaultTTL,
+		Token:      os.Getenv(TokenEnv),
+		HTTPClient: &http.Client{Timeout: time.Minute},
+	}
+}
+
+// Fetch returns the local path of a remote source, fetching it unless a fresh
+// copy is cached: a file for HTTP sources, the file or directory at the
+// source's path for Git sources
+func (f *Fetcher) Fetch(ctx context.Context, source string) (string, error) {
+	s, err := ParseSource(source)
+	if err != nil {
+		return "", err
+	}
+
+	entry := filepath.Join(f.CacheDir, s.cacheKey())
+	if f.Refresh || !f.fresh(entry, s) {
+		if err := f.fetchEntry(ctx, s, source, entry); err != nil {
+			return "", err
+		}
+	}
+
+	local := entry
+	switch s.Kind {
+	case KindHTTP:
+		u, _ := url.Parse(s.URL)
+		local = filepath.Join(entry, path.Base(u.Path))
+	case KindGit:
+		local = filepath.Join(entry, filepath.FromSlash(s.Path))
+	}
+	if _, err := os.Stat(local); err != nil {
+		return "", fmt.Errorf("%s: %s not found in the fetched source", source, s.Path)
+	}
+	return local, nil
+}
+
+// fresh reports whether a complete cache entry exists and has not expired
+func (f *Fetcher) fresh(entry string, s Source) bool {
+	info, err := os.Stat(filepath.Join(entry, stampFile))
+	if err != nil {
+		return false
+	}
+	return s.pinned() || time.Since(info.ModTime()) < f.TTL
+}
+
+// fetchEntry fetches a source into a temporary directory and then replaces the
+// cache entry with it, so a failed fetch never leaves a partial entry
+func (f *Fetcher) fetchEntry(ctx context.Context, s Source, source, entry string) error {
+	if err := paths.EnsureDir(f.CacheDir); err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp(f.CacheDir, ".fetch-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	switch s.Kind {
+	case KindHTTP:
+		err = f.fetchHTTP(ctx, s, source, tmp)
+	case KindGit:
+		err = f.fetchGit(ctx, s, source, tmp)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Join(tmp, stampFile), []byte(source+"\n"), 0644); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(entry); err != nil {
+		return err
+	}
+	return os.Rename(tmp, entry)
+}
+
+// fetchHTTP downloads a single file, checking its checksum when pinned
+func (f *Fetcher) fetchHTTP(ctx context.Context, s Source, source, dir string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return err
+	}
+	if f.Token != "" && req.URL.Scheme == "https" {
+		req.Header.Set("Authorization", "Bearer "+f.Token)
+	}
+
+	client := f.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", source, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return &AuthError{Source: source, Detail: fmt.Sprintf("status %d", resp.StatusCode)}
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("failed to fetch %s: status %d", source, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", source, err)
+	}
+	if s.Checksum != "" {
+		sum := sha256.Sum256(data)
+		if got := hex.EncodeToString(sum[:]); got != s.Checksum {
+			return fmt.Errorf("checksum mismatch for %s: expected sha256:%s, got sha256:%s", source, s.Checksum, got)
+		}
+	}
+
+	u, _ := url.Parse(s.URL)
+	return os.WriteFile(filepath.Join(dir, path.Base(u.Path)), data, 0644)
+}
+
+// fetchGit checks out the source's ref (default: HEAD) with a shallow fetch.
+// It needs the git binary.
+func (f *Fetcher) fetchGit(ctx context.Context, s Source, source, dir string) error {
+	ref := s.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	var config []string
+	if f.Token != "" && strings.HasPrefix(s.URL, "https://") {
+		credentials := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + f.Token))
+		config = []string{"-c", "http.extraHeader=Authorization: Basic " + credentials}
+	}
+
+	steps := [][]string{
+		{"init", "--quiet"},
+		append(append([]string{}, config...), "fetch", "--quiet", "--depth", "1", "--", s.URL, ref),
+		{"checkout", "--quiet", "--detach", "FETCH_HEAD"},
+	}
+	for _, args := range steps {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = dir
+		// Never prompt for credentials
+		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if errors.Is(err, exec.ErrNotFound) {
+				return fmt.Errorf("git is required to fetch %s: %w", source, err)
+			}
+			return gitError(source, ref, stderr.String(), err)
+		}
+	}
+	return os.RemoveAll(filepath.Join(dir, ".git"))
+}
+
+// gitError turns a failed git command into a clear error, recognizing
+// authentication failures and unknown refs
+func gitError(source, ref, stderr string, err error) error {
+	message := strings.TrimSpace(stderr)
+	lower := strings.ToLower(message)
+	switch {
+	case strings.Contains(lower, "authentication failed"),
+		strings.Contains(lower, "could not read username"),
+		strings.Contains(lower, "terminal prompts disabled"),
+		strings.Contains(lower, "403"),
+		strings.Contains(lower, "401"):
+		return &AuthError{Source: source, Detail: firstLine(message)}
+	case strings.Contains(lower, "couldn't find remote ref"), strings.Contains(lower, "not our ref"):
+		return fmt.Errorf("ref %q not found in %s", ref, source)
+	}
+	if message == "" {
+		message = err.Error()
+	}
+	return fmt.Errorf("failed to fetch %s: %s", source, firstLine(message))
+}
+
+// firstLine returns the first line of a multi-line message
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
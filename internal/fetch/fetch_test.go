@@ -0,0 +1,200 @@
+package fetch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSourceRejectsUnsafeGitSources(t *testing.T) {
+	for _, source := range []string{
+		"git::https://github.com/org/repo.git//k8s?ref=--upload-pack=touch%20/tmp/pwned",
+		"git::https://github.com/org/repo.git//k8s?ref=-c",
+		"git::file:///srv/repo.git//k8s",
+		"git::ext::sh -c touch% /tmp/pwned//k8s",
+		"git::http://github.com/org/repo.git//k8s",
+	} {
+		if _, err := ParseSource(source); err == nil {
+			t.Errorf("ParseSource(%q) = nil error, want an error", source)
+		}
+	}
+
+	for _, source := range []string{
+		"git::https://github.com/org/repo.git//k8s?ref=v1.2.0",
+		"git::ssh://git@github.com/org/repo.git//k8s?ref=main",
+	} {
+		if _, err := ParseSource(source); err != nil {
+			t.Errorf("ParseSource(%q) = %v, want no error", source, err)
+		}
+	}
+}
+
+// tokenServer records the Authorization header of each request and serves a
+// template file
+func tokenServer(t *testing.T, tls bool, body string) (*httptest.Server, *[]string) {
+	t.Helper()
+	var headers []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Get("Authorization"))
+		w.Write([]byte(body))
+	})
+	srv := httptest.NewServer(handler)
+	if tls {
+		srv.Close()
+		srv = httptest.NewTLSServer(handler)
+	}
+	t.Cleanup(srv.Close)
+	return srv, &headers
+}
+
+func TestFetchHTTPSendsTokenOnlyOverHTTPS(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		tls  bool
+		want string
+	}{
+		{"http", false, ""},
+		{"https", true, "Bearer secret"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv, headers := tokenServer(t, tc.tls, `{"name":"x"}`)
+			f := &Fetcher{CacheDir: t.TempDir(), TTL: DefaultTTL, Token: "secret", HTTPClient: srv.Client()}
+
+			local, err := f.Fetch(context.Background(), srv.URL+"/templates/k8s.json")
+			if err != nil {
+				t.Fatalf("Fetch: %v", err)
+			}
+			if data, _ := os.ReadFile(local); string(data) != `{"name":"x"}` {
+				t.Errorf("fetched %q", data)
+			}
+			if len(*headers) != 1 || (*headers)[0] != tc.want {
+				t.Errorf("Authorization headers = %q, want [%q]", *headers, tc.want)
+			}
+		})
+	}
+}
+
+func TestFetchHTTPChecksumAndCache(t *testing.T) {
+	body := `{"name":"pinned"}`
+	sum := sha256.Sum256([]byte(body))
+	srv, headers := tokenServer(t, false, body)
+	f := &Fetcher{CacheDir: t.TempDir(), TTL: DefaultTTL, HTTPClient: srv.Client()}
+
+	if _, err := f.Fetch(context.Background(), srv.URL+"/k8s.json?checksum=sha256:"+strings.Repeat("0", 64)); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("Fetch with a wrong checksum = %v, want a checksum mismatch", err)
+	}
+
+	source := srv.URL + "/k8s.json?checksum=sha256:" + hex.EncodeToString(sum[:])
+	for i := 0; i < 2; i++ {
+		if _, err := f.Fetch(context.Background(), source); err != nil {
+			t.Fatalf("Fetch: %v", err)
+		}
+	}
+	// The failed fetch and the first good one; the second is served from the cache
+	if len(*headers) != 2 {
+		t.Errorf("%d requests, want 2", len(*headers))
+	}
+}
+
+func TestFetchHTTPAuthError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+	f := &Fetcher{CacheDir: t.TempDir(), TTL: DefaultTTL, HTTPClient: srv.Client()}
+	if _, err := f.Fetch(context.Background(), srv.URL+"/k8s.json"); !IsAuthError(err) {
+		t.Errorf("Fetch = %v, want an AuthError", err)
+	}
+}
+
+// git runs a git command in dir, failing the test on error
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "init.defaultBranch=main"}, args...)...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+// bareRepo creates a bare repository holding k8s/cpu.json, tagged v1, and
+// returns its path
+func bareRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	work := t.TempDir()
+	git(t, work, "init", "--quiet")
+	if err := os.MkdirAll(filepath.Join(work, "k8s"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(work, "k8s", "cpu.json"), []byte(`{"name":"cpu"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git(t, work, "add", ".")
+	git(t, work, "commit", "--quiet", "-m", "templates")
+	git(t, work, "tag", "v1")
+
+	bare := filepath.Join(t.TempDir(), "repo.git")
+	git(t, work, "clone", "--quiet", "--bare", work, bare)
+	return bare
+}
+
+// allowFileScheme lets the tests fetch from a local repository
+func allowFileScheme(t *testing.T) {
+	gitSchemes["file"] = true
+	t.Cleanup(func() { delete(gitSchemes, "file") })
+}
+
+func TestFetchGitLocalBareRepo(t *testing.T) {
+	bare := bareRepo(t)
+	allowFileScheme(t)
+	f := &Fetcher{CacheDir: t.TempDir(), TTL: DefaultTTL}
+
+	local, err := f.Fetch(context.Background(), "git::file://"+bare+"//k8s/cpu.json?ref=v1")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if data, _ := os.ReadFile(local); string(data) != `{"name":"cpu"}` {
+		t.Errorf("fetched %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(filepath.Dir(local)), ".git")); !os.IsNotExist(err) {
+		t.Errorf("the checkout kept its .git directory")
+	}
+
+	if _, err := f.Fetch(context.Background(), "git::file://"+bare+"//k8s?ref=missing"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Fetch of a missing ref = %v, want a not found error", err)
+	}
+}
+
+func TestFetchGitRefInjection(t *testing.T) {
+	bare := bareRepo(t)
+	allowFileScheme(t)
+	marker := filepath.Join(t.TempDir(), "pwned")
+	f := &Fetcher{CacheDir: t.TempDir(), TTL: DefaultTTL}
+
+	source := "git::file://" + bare + "//k8s?ref=--upload-pack=touch%20" + marker
+	if _, err := f.Fetch(context.Background(), source); err == nil {
+		t.Fatal("Fetch with a ref starting with - succeeded")
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatal("the ref ran a command")
+	}
+
+	// Past the parser, -- keeps git from reading the ref as an option
+	s := Source{Kind: KindGit, URL: "file://" + bare, Ref: "--upload-pack=touch " + marker}
+	if err := f.fetchGit(context.Background(), s, source, t.TempDir()); err == nil {
+		t.Fatal("fetchGit with an option-like ref succeeded")
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatal("the ref ran a command")
+	}
+}
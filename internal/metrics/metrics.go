@@ -0,0 +1,191 @@
+// Package metrics exposes the syncs of the daemon command and the API calls
+// they made as Prometheus metrics, in the text exposition format, next to a
+// /healthz endpoint.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+// contentType is the content type of the text exposition format
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Sync is the outcome of one sync
+type Sync struct {
+	Started  time.Time
+	Duration time.Duration
+	// Monitors counts the monitors of the sync by result status (created,
+	// updated, unchanged, failed, ...)
+	Monitors map[datadog.ResultStatus]int
+	// Drift is the number of monitors the sync had to create or update
+	Drift int
+	// Err is the error that failed the sync, if any
+	Err error
+}
+
+// Collector keeps the counters of the syncs and reads the API call counters
+// from the stats the clients record into. It is safe for concurrent use.
+type Collector struct {
+	mu    sync.Mutex
+	stats *datadog.Stats
+
+	syncs        int
+	failures     int
+	lastSync     time.Time
+	lastSuccess  time.Time
+	lastDuration time.Duration
+	drift        int
+	monitors     map[datadog.ResultStatus]int
+}
+
+// NewCollector returns a collector reading the API call counters from stats
+// (none when nil)
+func NewCollector(stats *datadog.Stats) *Collector {
+	return &Collector{stats: stats, monitors: make(map[datadog.ResultStatus]int)}
+}
+
+// RecordSync adds a finished sync to the counters
+func (c *Collector) RecordSync(s Sync) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.syncs++
+	c.lastSync = s.Started.Add(s.Duration)
+	c.lastDuration = s.Duration
+	if s.Err != nil {
+		c.failures++
+	} else {
+		c.lastSuccess = c.lastSync
+	}
+	c.drift = s.Drift
+	for status, n := range s.Monitors {
+		c.monitors[status] += n
+	}
+}
+
+// Handler serves the metrics on /metrics and the liveness probe on /healthz
+func (c *Collector) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		c.Write(w)
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, "ok")
+	})
+	return mux
+}
+
+// Write writes every metric to w in the text exposition format
+func (c *Collector) Write(w io.Writer) error {
+	c.mu.Lock()
+	syncs, failures := c.syncs, c.failures
+	lastSync, lastSuccess, lastDuration, drift := c.lastSync, c.lastSuccess, c.lastDuration, c.drift
+	monitors := make(map[datadog.ResultStatus]int, len(c.monitors))
+	for status, n := range c.monitors {
+		monitors[status] = n
+	}
+	c.mu.Unlock()
+
+	b := bufio.NewWriter(w)
+	family(b, "ddmm_syncs_total", "counter", "Syncs run since the daemon started.")
+	sample(b, "ddmm_syncs_total", nil, float64(syncs))
+	family(b, "ddmm_sync_failures_total", "counter", "Syncs that failed since the daemon started.")
+	sample(b, "ddmm_sync_failures_total", nil, float64(failures))
+	family(b, "ddmm_last_sync_timestamp_seconds", "gauge", "Unix time the last sync finished, 0 before the first one.")
+	sample(b, "ddmm_last_sync_timestamp_seconds", nil, unixSeconds(lastSync))
+	family(b, "ddmm_last_successful_sync_timestamp_seconds", "gauge", "Unix time the last successful sync finished, 0 before the first one.")
+	sample(b, "ddmm_last_successful_sync_timestamp_seconds", nil, unixSeconds(lastSuccess))
+	family(b, "ddmm_last_sync_duration_seconds", "gauge", "How long the last sync took.")
+	sample(b, "ddmm_last_sync_duration_seconds", nil, lastDuration.Seconds())
+	family(b, "ddmm_drift_monitors", "gauge", "Monitors the last sync had to create or update.")
+	sample(b, "ddmm_drift_monitors", nil, float64(drift))
+
+	family(b, "ddmm_sync_monitors_total", "counter", "Monitors applied by the syncs, by result.")
+	// The main results are always exposed, so rate() works from the first sync
+	for _, status := range []datadog.ResultStatus{datadog.StatusCreated, datadog.StatusUpdated, datadog.StatusUnchanged, datadog.StatusFailed} {
+		if _, ok := monitors[status]; !ok {
+			monitors[status] = 0
+		}
+	}
+	statuses := make([]string, 0, len(monitors))
+	for status := range monitors {
+		statuses = append(statuses, string(status))
+	}
+	sort.Strings(statuses)
+	for _, status := range statuses {
+		sample(b, "ddmm_sync_monitors_total", []string{"result", status}, float64(monitors[datadog.ResultStatus(status)]))
+	}
+
+	if c.stats != nil {
+		writeAPIStats(b, c.stats.Summary())
+	}
+	return b.Flush()
+}
+
+// writeAPIStats writes the API call counters of the stats layer
+func writeAPIStats(b *bufio.Writer, summary datadog.StatsSummary) {
+	family(b, "ddmm_api_requests_total", "counter", "Datadog API requests, by method, endpoint and status (0: no response).")
+	for _, entry := range summary.Endpoints {
+		sample(b, "ddmm_api_requests_total", endpointLabels(entry), float64(entry.Count))
+	}
+	family(b, "ddmm_api_request_duration_seconds_total", "counter", "Time spent in Datadog API requests, by method, endpoint and status.")
+	for _, entry := range summary.Endpoints {
+		sample(b, "ddmm_api_request_duration_seconds_total", endpointLabels(entry), entry.DurationMS/1000)
+	}
+	family(b, "ddmm_api_retries_total", "counter", "Datadog API requests retried.")
+	sample(b, "ddmm_api_retries_total", nil, float64(summary.Retries))
+	family(b, "ddmm_api_errors_total", "counter", "Datadog API requests that failed.")
+	sample(b, "ddmm_api_errors_total", nil, float64(summary.Errors))
+	family(b, "ddmm_api_sent_bytes_total", "counter", "Bytes sent to the Datadog API.")
+	sample(b, "ddmm_api_sent_bytes_total", nil, float64(summary.BytesSent))
+	family(b, "ddmm_api_received_bytes_total", "counter", "Bytes received from the Datadog API.")
+	sample(b, "ddmm_api_received_bytes_total", nil, float64(summary.BytesReceived))
+}
+
+// endpointLabels returns the labels of the requests of an endpoint
+func endpointLabels(entry datadog.EndpointStats) []string {
+	return []string{"method", entry.Method, "endpoint", entry.Endpoint, "status", strconv.Itoa(entry.Status)}
+}
+
+// family writes the HELP and TYPE lines of a metric
+func family(b *bufio.Writer, name, kind, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// sample writes a sample line; labels are name/value pairs
+func sample(b *bufio.Writer, name string, labels []string, value float64) {
+	b.WriteString(name)
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(b, "%s=\"%s\"", labels[i], labelEscaper.Replace(labels[i+1]))
+		}
+		b.WriteByte('}')
+	}
+	fmt.Fprintf(b, " %s\n", strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// labelEscaper escapes label values as the text exposition format requires
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// unixSeconds returns t as Unix seconds, 0 for the zero time
+func unixSeconds(t time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+	return float64(t.UnixNano()) / 1e9
+}
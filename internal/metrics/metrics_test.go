@@ -0,0 +1,149 @@
+package metrics
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+func TestCollectorBeforeFirstSync(t *testing.T) {
+	var b strings.Builder
+	if err := NewCollector(nil).Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# TYPE ddmm_syncs_total counter\nddmm_syncs_total 0\n",
+		"ddmm_last_sync_timestamp_seconds 0\n",
+		"ddmm_last_successful_sync_timestamp_seconds 0\n",
+		`ddmm_sync_monitors_total{result="created"} 0` + "\n",
+		`ddmm_sync_monitors_total{result="failed"} 0` + "\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("output lacks %q:\n%s", want, b.String())
+		}
+	}
+	if strings.Contains(b.String(), "ddmm_api_") {
+		t.Errorf("API metrics without stats:\n%s", b.String())
+	}
+}
+
+func TestCollectorRecordSync(t *testing.T) {
+	c := NewCollector(nil)
+	started := time.Unix(1700000000, 0)
+	c.RecordSync(Sync{
+		Started:  started,
+		Duration: 1500 * time.Millisecond,
+		Monitors: map[datadog.ResultStatus]int{datadog.StatusCreated: 2, datadog.StatusUnchanged: 1},
+		Drift:    2,
+	})
+	c.RecordSync(Sync{
+		Started:  started.Add(time.Minute),
+		Duration: 500 * time.Millisecond,
+		Monitors: map[datadog.ResultStatus]int{datadog.StatusUnchanged: 2, datadog.StatusFailed: 1},
+		Err:      errors.New("boom"),
+	})
+
+	var b strings.Builder
+	c.Write(&b)
+	for _, want := range []string{
+		"ddmm_syncs_total 2\n",
+		"ddmm_sync_failures_total 1\n",
+		// The counters add up, the gauges are those of the last sync
+		`ddmm_sync_monitors_total{result="created"} 2` + "\n",
+		`ddmm_sync_monitors_total{result="unchanged"} 3` + "\n",
+		`ddmm_sync_monitors_total{result="failed"} 1` + "\n",
+		"ddmm_drift_monitors 0\n",
+		"ddmm_last_sync_duration_seconds 0.5\n",
+		"ddmm_last_sync_timestamp_seconds 1.7000000605e+09\n",
+		"ddmm_last_successful_sync_timestamp_seconds 1.7000000015e+09\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("output lacks %q:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestCollectorAPIStats(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/monitor/42" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		w.Write([]byte("[]"))
+	}))
+	defer api.Close()
+	t.Setenv("DD_API_KEY", "api")
+	t.Setenv("DD_APP_KEY", "app")
+	t.Setenv("DDMM_NO_CACHE", "1")
+
+	stats := datadog.NewStats()
+	client, err := datadog.NewClient(datadog.WithBaseURL(api.URL+"/api/v1"), datadog.WithStats(stats))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.ListMonitors(nil, "")
+	client.GetMonitor(42)
+
+	var b strings.Builder
+	NewCollector(stats).Write(&b)
+	for _, want := range []string{
+		`ddmm_api_requests_total{method="GET",endpoint="/api/v1/monitor",status="200"} 1`,
+		`ddmm_api_requests_total{method="GET",endpoint="/api/v1/monitor/{id}",status="404"} 1`,
+		"ddmm_api_errors_total 1\n",
+		"ddmm_api_received_bytes_total ",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("output lacks %q:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	c := NewCollector(nil)
+	c.RecordSync(Sync{Started: time.Now(), Monitors: map[datadog.ResultStatus]int{}})
+	srv := httptest.NewServer(c.Handler())
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		path        string
+		status      int
+		contentType string
+		body        string
+	}{
+		{"/metrics", http.StatusOK, contentType, "ddmm_syncs_total 1\n"},
+		{"/healthz", http.StatusOK, "text/plain; charset=utf-8", "ok\n"},
+		{"/nope", http.StatusNotFound, "", ""},
+	} {
+		resp, err := http.Get(srv.URL + tc.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != tc.status {
+			t.Errorf("GET %s: status %d, want %d", tc.path, resp.StatusCode, tc.status)
+		}
+		if tc.contentType != "" && resp.Header.Get("Content-Type") != tc.contentType {
+			t.Errorf("GET %s: Content-Type %q, want %q", tc.path, resp.Header.Get("Content-Type"), tc.contentType)
+		}
+		if !strings.Contains(string(body), tc.body) {
+			t.Errorf("GET %s: body lacks %q:\n%s", tc.path, tc.body, body)
+		}
+	}
+}
+
+func TestSampleEscapesLabels(t *testing.T) {
+	var b strings.Builder
+	w := bufio.NewWriter(&b)
+	sample(w, "m", []string{"endpoint", `/a"b\c` + "\n"}, 1)
+	w.Flush()
+	if want := `m{endpoint="/a\"b\\c\n"} 1` + "\n"; b.String() != want {
+		t.Errorf("sample = %q, want %q", b.String(), want)
+	}
+}
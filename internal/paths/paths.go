@@ -0,0 +1,124 @@
+// Package paths resolves where the tool keeps files on disk, portably: the
+// config and cache directories come from os.UserConfigDir and os.UserCacheDir
+// (XDG directories on Linux, ~/Library on macOS, %AppData% and %LocalAppData%
+// on Windows) and the state directory from XDG_STATE_HOME, unless overridden,
+// and still resolve in containers without HOME.
+package paths
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	// ConfigDirEnv overrides the config directory
+	ConfigDirEnv = "DDMM_CONFIG_DIR"
+	// CacheDirEnv overrides the cache directory
+	CacheDirEnv = "DDMM_CACHE_DIR"
+	// StateDirEnv overrides the state directory
+	StateDirEnv = "DDMM_STATE_DIR"
+
+	// appDir is the directory of the tool inside the user directories
+	appDir = "datadog-monitor-manager"
+	// dirPerm is the permission of the directories created on demand
+	dirPerm = 0o700
+)
+
+// ErrNoHome is returned when a path needs the home directory and there is none
+var ErrNoHome = errors.New("no home directory (set HOME, or give an absolute path)")
+
+// ConfigDir returns the config directory: $DDMM_CONFIG_DIR, else
+// datadog-monitor-manager in the user config directory. It returns an error
+// when neither is available, e.g. without HOME and XDG_CONFIG_HOME.
+func ConfigDir() (string, error) {
+	if dir := os.Getenv(ConfigDirEnv); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appDir), nil
+}
+
+// CacheDir returns the cache directory: $DDMM_CACHE_DIR, else
+// datadog-monitor-manager in the user cache directory, else in the temporary
+// directory so caches keep working without HOME
+func CacheDir() string {
+	if dir := os.Getenv(CacheDirEnv); dir != "" {
+		return dir
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), appDir)
+	}
+	return filepath.Join(dir, appDir)
+}
+
+// StateDir returns the state directory, for files worth keeping across runs
+// but not worth backing up: $DDMM_STATE_DIR, else datadog-monitor-manager in
+// $XDG_STATE_HOME or ~/.local/state on Unix, and in the user config directory
+// on macOS and Windows, which have no state directory. It returns an error
+// when none is available, e.g. without HOME and XDG_STATE_HOME.
+func StateDir() (string, error) {
+	if dir := os.Getenv(StateDirEnv); dir != "" {
+		return dir, nil
+	}
+	switch runtime.GOOS {
+	case "darwin", "ios", "windows", "plan9":
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, appDir), nil
+	}
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		// A relative path is an error, as for os.UserConfigDir
+		if !filepath.IsAbs(dir) {
+			return "", errors.New("path in $XDG_STATE_HOME is relative")
+		}
+		return filepath.Join(dir, appDir), nil
+	}
+	home, err := Home()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state", appDir), nil
+}
+
+// Home returns the home directory, or ErrNoHome
+func Home() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return "", ErrNoHome
+	}
+	return home, nil
+}
+
+// Expand replaces a leading ~ in path with the home directory; other paths
+// are returned as they are
+func Expand(path string) (string, error) {
+	rest, ok := strings.CutPrefix(path, "~")
+	if !ok || (rest != "" && rest[0] != '/' && rest[0] != filepath.Separator) {
+		return path, nil
+	}
+	home, err := Home()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, rest), nil
+}
+
+// EnsureDir creates dir and its parents, accessible by the owner only, when
+// they don't exist
+func EnsureDir(dir string) error {
+	return os.MkdirAll(dir, dirPerm)
+}
+
+// EnsureParent creates the directory of the file path, see EnsureDir
+func EnsureParent(path string) error {
+	return EnsureDir(filepath.Dir(path))
+}
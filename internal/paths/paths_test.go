@@ -0,0 +1,119 @@
+package paths
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// unsetHome clears the variables the user directories are resolved from
+func unsetHome(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{"HOME", "XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_STATE_HOME", "AppData", "LocalAppData", "USERPROFILE", ConfigDirEnv, CacheDirEnv, StateDirEnv} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	unsetHome(t)
+	t.Setenv(ConfigDirEnv, "/etc/ddmm")
+	t.Setenv(CacheDirEnv, "/var/cache/ddmm")
+	t.Setenv(StateDirEnv, "/var/lib/ddmm")
+
+	if dir, err := ConfigDir(); err != nil || dir != "/etc/ddmm" {
+		t.Errorf("ConfigDir() = %q, %v", dir, err)
+	}
+	if dir := CacheDir(); dir != "/var/cache/ddmm" {
+		t.Errorf("CacheDir() = %q", dir)
+	}
+	if dir, err := StateDir(); err != nil || dir != "/var/lib/ddmm" {
+		t.Errorf("StateDir() = %q, %v", dir, err)
+	}
+}
+
+func TestMissingHome(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+		t.Skip("the user directories don't come from HOME")
+	}
+	unsetHome(t)
+
+	if dir, err := ConfigDir(); err == nil {
+		t.Errorf("ConfigDir() = %q, want an error", dir)
+	}
+	if dir, want := CacheDir(), filepath.Join(os.TempDir(), appDir); dir != want {
+		t.Errorf("CacheDir() = %q, want %q", dir, want)
+	}
+	if dir, err := StateDir(); err == nil {
+		t.Errorf("StateDir() = %q, want an error", dir)
+	}
+	if _, err := Home(); !errors.Is(err, ErrNoHome) {
+		t.Errorf("Home() = %v, want ErrNoHome", err)
+	}
+	if _, err := Expand("~/audit.log"); !errors.Is(err, ErrNoHome) {
+		t.Errorf("Expand(~/audit.log) = %v, want ErrNoHome", err)
+	}
+	// Paths without ~ don't need HOME
+	for _, path := range []string{"/var/log/audit.log", "audit.log", "~alice/audit.log"} {
+		if got, err := Expand(path); err != nil || got != path {
+			t.Errorf("Expand(%q) = %q, %v", path, got, err)
+		}
+	}
+}
+
+func TestUserDirectories(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG directories are Linux defaults")
+	}
+	unsetHome(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	for _, tc := range []struct {
+		name string
+		dir  func() (string, error)
+		want string
+	}{
+		{"config", ConfigDir, filepath.Join(home, ".config", appDir)},
+		{"cache", func() (string, error) { return CacheDir(), nil }, filepath.Join(home, ".cache", appDir)},
+		{"state", StateDir, filepath.Join(home, ".local", "state", appDir)},
+	} {
+		if dir, err := tc.dir(); err != nil || dir != tc.want {
+			t.Errorf("%s directory = %q, %v, want %q", tc.name, dir, err, tc.want)
+		}
+	}
+
+	t.Setenv("XDG_STATE_HOME", "/srv/state")
+	if dir, err := StateDir(); err != nil || dir != filepath.Join("/srv/state", appDir) {
+		t.Errorf("StateDir() with XDG_STATE_HOME = %q, %v", dir, err)
+	}
+	t.Setenv("XDG_STATE_HOME", "relative/state")
+	if dir, err := StateDir(); err == nil {
+		t.Errorf("StateDir() with a relative XDG_STATE_HOME = %q, want an error", dir)
+	}
+
+	if got, err := Expand("~/audit.log"); err != nil || got != filepath.Join(home, "audit.log") {
+		t.Errorf("Expand(~/audit.log) = %q, %v", got, err)
+	}
+}
+
+func TestEnsureParent(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "a", "b", "state.json")
+	if err := EnsureParent(path); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != dirPerm {
+		t.Errorf("directory permission %v, want %v", info.Mode().Perm(), os.FileMode(dirPerm))
+	}
+	// Existing directories are fine
+	if err := EnsureParent(path); err != nil {
+		t.Errorf("EnsureParent of an existing directory: %v", err)
+	}
+}
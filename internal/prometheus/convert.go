@@ -0,0 +1,378 @@
+package prometheus
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/datadog"
+)
+
+// SourceTag marks monitors converted from Prometheus alerting rules
+const SourceTag = "source:prometheus"
+
+// ConvertedRule is an alerting rule converted into a monitor template
+type ConvertedRule struct {
+	Group    string               `json:"group"`
+	Alert    string               `json:"alert"`
+	Template datadog.TemplateData `json:"template"`
+	// Notes are the approximations made converting the rule
+	Notes []string `json:"notes,omitempty"`
+}
+
+// SkippedRule is a rule that couldn't be converted, with the reason
+type SkippedRule struct {
+	Group  string `json:"group"`
+	Alert  string `json:"alert"`
+	Expr   string `json:"expr"`
+	Reason string `json:"reason"`
+}
+
+// Conversion is the outcome of converting rule groups: every rule is either
+// converted or skipped with a reason
+type Conversion struct {
+	Converted []ConvertedRule `json:"converted"`
+	Skipped   []SkippedRule   `json:"skipped"`
+}
+
+// Convert converts the alerting rules of groups into monitor templates.
+// Recording rules are skipped.
+func Convert(groups []RuleGroup, mapping *Mapping) Conversion {
+	var conversion Conversion
+	for _, group := range groups {
+		for _, rule := range group.Rules {
+			name := rule.Alert
+			if name == "" {
+				conversion.Skipped = append(conversion.Skipped, SkippedRule{Group: group.Name, Alert: rule.Record, Expr: rule.Expr, Reason: "recording rule"})
+				continue
+			}
+			template, notes, err := ConvertRule(rule, mapping)
+			if err != nil {
+				conversion.Skipped = append(conversion.Skipped, SkippedRule{Group: group.Name, Alert: name, Expr: rule.Expr, Reason: err.Error()})
+				continue
+			}
+			conversion.Converted = append(conversion.Converted, ConvertedRule{Group: group.Name, Alert: name, Template: template, Notes: notes})
+		}
+	}
+	return conversion
+}
+
+// ConvertRule converts one alerting rule into a query alert template. The
+// notes list the approximations made; an error means there is no Datadog
+// equivalent.
+func ConvertRule(rule Rule, mapping *Mapping) (datadog.TemplateData, []string, error) {
+	c := &converter{mapping: mapping}
+	tree, err := parsePromQL(rule.Expr)
+	if err != nil {
+		var unsupportedErr *UnsupportedError
+		if errors.As(err, &unsupportedErr) {
+			return datadog.TemplateData{}, nil, err
+		}
+		return datadog.TemplateData{}, nil, fmt.Errorf("invalid PromQL: %v", err)
+	}
+
+	comparison, ok := tree.(*binaryNode)
+	if !ok || !isComparison(comparison.op) {
+		return datadog.TemplateData{}, nil, fmt.Errorf("the expression has no threshold comparison (e.g. > 90)")
+	}
+	op, threshold, vector := comparison.op, comparison.rhs, comparison.lhs
+	if number, ok := comparison.lhs.(*numberNode); ok {
+		// 90 < x is x > 90
+		op, threshold, vector = flipComparison(op), number, comparison.rhs
+	}
+	number, ok := threshold.(*numberNode)
+	if !ok {
+		return datadog.TemplateData{}, nil, unsupported("comparisons between two series (only against a number)")
+	}
+	if op == "==" || op == "!=" {
+		return datadog.TemplateData{}, nil, unsupported("the %s comparison (monitors compare with >, >=, < or <=)", op)
+	}
+	value, _ := strconv.ParseFloat(number.text, 64)
+
+	query, err := c.vector(vector)
+	if err != nil {
+		return datadog.TemplateData{}, nil, err
+	}
+
+	window, err := evaluationWindow(rule.For)
+	if err != nil {
+		return datadog.TemplateData{}, nil, err
+	}
+	// The condition must hold for the whole "for" duration: for > that is the
+	// minimum over the window, for < the maximum
+	timeAggregation := "min"
+	if op == "<" || op == "<=" {
+		timeAggregation = "max"
+	}
+
+	config := map[string]interface{}{
+		"name":    rule.Alert,
+		"type":    "query alert",
+		"query":   fmt.Sprintf("%s(%s):%s %s %s", timeAggregation, window, query, op, number.text),
+		"message": c.message(rule),
+		"tags":    ruleTags(rule),
+		"options": map[string]interface{}{
+			"thresholds":          map[string]interface{}{"critical": value},
+			"notify_no_data":      false,
+			"require_full_window": false,
+			"include_tags":        true,
+		},
+	}
+	return datadog.TemplateData{Name: rule.Alert, Config: config}, c.notes, nil
+}
+
+// converter converts a parsed expression, collecting notes on approximations
+type converter struct {
+	mapping *Mapping
+	notes   []string
+}
+
+func (c *converter) note(format string, args ...interface{}) {
+	note := fmt.Sprintf(format, args...)
+	for _, existing := range c.notes {
+		if existing == note {
+			return
+		}
+	}
+	c.notes = append(c.notes, note)
+}
+
+// vector converts a vector expression into a Datadog metric query
+func (c *converter) vector(n node) (string, error) {
+	switch n := n.(type) {
+	case *numberNode:
+		return n.text, nil
+	case *parenNode:
+		inner, err := c.vector(n.expr)
+		if err != nil {
+			return "", err
+		}
+		return "(" + inner + ")", nil
+	case *binaryNode:
+		if isComparison(n.op) {
+			return "", unsupported("nested comparisons")
+		}
+		lhs, err := c.vector(n.lhs)
+		if err != nil {
+			return "", err
+		}
+		rhs, err := c.vector(n.rhs)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s %s %s", lhs, n.op, rhs), nil
+	case *aggNode:
+		return c.aggregation(n)
+	case *selectorNode, *callNode:
+		c.note("no aggregation: the series are averaged together; Prometheus alerts per series (add sum/avg by (...) for a multi alert)")
+		return c.metricQuery("avg", n, nil)
+	}
+	return "", fmt.Errorf("unexpected expression")
+}
+
+// aggregation converts sum/avg/min/max by (...) of a selector or a rate
+func (c *converter) aggregation(agg *aggNode) (string, error) {
+	inner := agg.expr
+	for {
+		paren, ok := inner.(*parenNode)
+		if !ok {
+			break
+		}
+		inner = paren.expr
+	}
+	switch inner.(type) {
+	case *selectorNode, *callNode:
+	default:
+		return "", unsupported("%s() of an expression (only of a metric selector or rate)", agg.op)
+	}
+	return c.metricQuery(agg.op, inner, agg.by)
+}
+
+// metricQuery builds aggregator:metric{scope} by {tags}, with .as_rate() or
+// .as_count() for rate and increase
+func (c *converter) metricQuery(aggregator string, n node, by []string) (string, error) {
+	selector, suffix := (*selectorNode)(nil), ""
+	switch n := n.(type) {
+	case *selectorNode:
+		selector = n
+		if n.rangeDur != "" {
+			return "", unsupported("range vector %s[%s] outside of rate, irate or increase", n.metric, n.rangeDur)
+		}
+	case *callNode:
+		selector = n.arg
+		switch n.fn {
+		case "rate", "irate":
+			suffix = ".as_rate()"
+		case "increase":
+			suffix = ".as_count()"
+		}
+		c.note("%s(%s[%s]) became %s over the monitor window", n.fn, n.arg.metric, n.arg.rangeDur, strings.TrimPrefix(suffix, "."))
+	}
+
+	metric, ok := c.mapping.metric(selector.metric)
+	if !ok {
+		return "", fmt.Errorf("no Datadog metric mapped for %s (add it to the mapping file or set a metric prefix)", selector.metric)
+	}
+	scope, err := c.scope(selector.matchers)
+	if err != nil {
+		return "", err
+	}
+
+	query := fmt.Sprintf("%s:%s{%s}", aggregator, metric, scope)
+	if len(by) > 0 {
+		tags := make([]string, len(by))
+		for i, label := range by {
+			tags[i] = c.mapping.tag(label)
+		}
+		query += " by {" + strings.Join(tags, ",") + "}"
+	}
+	return query + suffix, nil
+}
+
+// wildcardRegex matches the regex label values with a Datadog wildcard
+// equivalent: literal text ending in .* (or just .*)
+var wildcardRegex = regexp.MustCompile(`^[A-Za-z0-9_\-/]*\.\*$`)
+
+// literalRegex matches regex label values without special characters
+var literalRegex = regexp.MustCompile(`^[A-Za-z0-9_\-/]+$`)
+
+// scope converts label matchers into a Datadog scope
+func (c *converter) scope(matchers []matcher) (string, error) {
+	var tags []string
+	for _, m := range matchers {
+		key := c.mapping.tag(m.label)
+		value := m.value
+		negate := m.op == "!=" || m.op == "!~"
+		if m.op == "=~" || m.op == "!~" {
+			switch {
+			case literalRegex.MatchString(value):
+			case wildcardRegex.MatchString(value):
+				value = strings.TrimSuffix(value, ".*") + "*"
+			default:
+				return "", unsupported("regex matcher %s%s%q (only literal values and prefix.* wildcards)", m.label, m.op, m.value)
+			}
+			if value == "*" {
+				if negate {
+					return "", unsupported("matcher %s!~\".*\" (matches nothing)", m.label)
+				}
+				continue
+			}
+		}
+		if value == "" {
+			return "", unsupported("empty label value matcher on %s", m.label)
+		}
+		tag := key + ":" + value
+		if negate {
+			tag = "!" + tag
+		}
+		tags = append(tags, tag)
+	}
+	if len(tags) == 0 {
+		return "*", nil
+	}
+	return strings.Join(tags, ","), nil
+}
+
+// flipComparison returns the comparison with its operands swapped
+func flipComparison(op string) string {
+	switch op {
+	case ">":
+		return "<"
+	case "<":
+		return ">"
+	case ">=":
+		return "<="
+	case "<=":
+		return ">="
+	}
+	return op
+}
+
+// durationPattern matches the parts of a Prometheus duration, e.g. 1h30m
+var durationPattern = regexp.MustCompile(`(\d+)(ms|s|m|h|d|w|y)`)
+
+// evaluationWindow converts a rule's "for" duration into a Datadog window:
+// last_Xm, or last_Xh / last_Xd for whole hours and days. Seconds round up to
+// the minute; without "for", the window is last_1m.
+func evaluationWindow(forDuration string) (string, error) {
+	if forDuration == "" {
+		return "last_1m", nil
+	}
+	if durationPattern.ReplaceAllString(forDuration, "") != "" {
+		return "", fmt.Errorf("invalid for duration %q", forDuration)
+	}
+
+	seconds := 0
+	unitSeconds := map[string]int{"ms": 0, "s": 1, "m": 60, "h": 3600, "d": 86400, "w": 604800, "y": 31536000}
+	for _, part := range durationPattern.FindAllStringSubmatch(forDuration, -1) {
+		n, _ := strconv.Atoi(part[1])
+		seconds += n * unitSeconds[part[2]]
+	}
+	minutes := (seconds + 59) / 60
+	switch {
+	case minutes < 1:
+		return "last_1m", nil
+	case minutes%1440 == 0:
+		return fmt.Sprintf("last_%dd", minutes/1440), nil
+	case minutes%60 == 0:
+		return fmt.Sprintf("last_%dh", minutes/60), nil
+	}
+	return fmt.Sprintf("last_%dm", minutes), nil
+}
+
+// ruleTags returns the labels of a rule as tags, sorted, with SourceTag and
+// the alert name. Labels holding templates are left out.
+func ruleTags(rule Rule) []string {
+	tags := []string{SourceTag, "alertname:" + rule.Alert}
+	for key, value := range rule.Labels {
+		if strings.Contains(value, "{{") || value == "" {
+			continue
+		}
+		tags = append(tags, key+":"+value)
+	}
+	sort.Strings(tags[2:])
+	return tags
+}
+
+// labelTemplatePattern matches {{ $labels.name }} in annotations
+var labelTemplatePattern = regexp.MustCompile(`\{\{\s*\$labels\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// valueTemplatePattern matches {{ $value }}, optionally piped to a function
+// such as humanize
+var valueTemplatePattern = regexp.MustCompile(`\{\{\s*\$value(?:\s*\|\s*[A-Za-z]+)*\s*\}\}`)
+
+// message builds the monitor message from the summary, description and
+// runbook_url annotations, translating {{ $labels.x }} and {{ $value }} into
+// Datadog template variables
+func (c *converter) message(rule Rule) string {
+	var parts []string
+	for _, key := range []string{"summary", "description"} {
+		if text := strings.TrimSpace(rule.Annotations[key]); text != "" {
+			parts = append(parts, c.translateTemplate(text))
+		}
+	}
+	if runbook := strings.TrimSpace(rule.Annotations["runbook_url"]); runbook != "" {
+		parts = append(parts, "Runbook: "+runbook)
+	}
+	if len(parts) == 0 {
+		parts = append(parts, rule.Alert)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// translateTemplate rewrites the Prometheus template references of an
+// annotation
+func (c *converter) translateTemplate(text string) string {
+	text = labelTemplatePattern.ReplaceAllStringFunc(text, func(match string) string {
+		label := labelTemplatePattern.FindStringSubmatch(match)[1]
+		return "{{" + c.mapping.tag(label) + ".name}}"
+	})
+	text = valueTemplatePattern.ReplaceAllString(text, "{{value}}")
+	if strings.Contains(text, "{{") && strings.Contains(text, "$") {
+		c.note("annotations use Prometheus templating beyond $labels and $value; review the message")
+	}
+	return text
+}
@@ -0,0 +1,296 @@
+package prometheus
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testMapping maps the metrics of the test rules
+func testMapping() *Mapping {
+	mapping := DefaultMapping()
+	mapping.Metrics["http_requests_total"] = "trace.http.request.hits"
+	mapping.Metrics["kube_pod_container_status_restarts_total"] = "kubernetes.containers.restarts"
+	mapping.Labels["service"] = "service"
+	return mapping
+}
+
+func TestConvertRule(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		rule  Rule
+		query string
+		notes []string
+	}{
+		{
+			name:  "rate by label",
+			rule:  Rule{Expr: `sum by (service) (rate(http_requests_total{code="500"}[5m])) > 1`, For: "10m"},
+			query: "min(last_10m):sum:trace.http.request.hits{code:500} by {service}.as_rate() > 1",
+			notes: []string{"rate(http_requests_total[5m]) became as_rate() over the monitor window"},
+		},
+		{
+			name:  "increase with mapped labels",
+			rule:  Rule{Expr: `max by (namespace, pod) (increase(kube_pod_container_status_restarts_total{namespace!="kube-system"}[1h])) >= 3`, For: "1h"},
+			query: "min(last_1h):max:kubernetes.containers.restarts{!kube_namespace:kube-system} by {kube_namespace,pod_name}.as_count() >= 3",
+			notes: []string{"increase(kube_pod_container_status_restarts_total[1h]) became as_count() over the monitor window"},
+		},
+		{
+			name:  "below threshold takes the maximum",
+			rule:  Rule{Expr: `avg(node_filesystem_avail_ratio{mountpoint="/"}) < 0.1`, For: "1d"},
+			query: "max(last_1d):avg:node.node_filesystem_avail_ratio{mountpoint:/} < 0.1",
+		},
+		{
+			name:  "number first is flipped",
+			rule:  Rule{Expr: `0.9 <= avg(node_cpu_ratio)`, For: "90s"},
+			query: "min(last_2m):avg:node.node_cpu_ratio{*} >= 0.9",
+		},
+		{
+			name:  "regex wildcards",
+			rule:  Rule{Expr: `sum(rate(http_requests_total{code=~"5.*",route!~"/health",pod=~".*"}[5m])) > 5`},
+			query: "min(last_1m):sum:trace.http.request.hits{code:5*,!route:/health}.as_rate() > 5",
+			notes: []string{"rate(http_requests_total[5m]) became as_rate() over the monitor window"},
+		},
+		{
+			name:  "ratio of aggregations",
+			rule:  Rule{Expr: `sum(rate(http_requests_total{code="500"}[5m])) / sum(rate(http_requests_total[5m])) * 100 > 5`, For: "15m"},
+			query: "min(last_15m):sum:trace.http.request.hits{code:500}.as_rate() / sum:trace.http.request.hits{*}.as_rate() * 100 > 5",
+			notes: []string{"rate(http_requests_total[5m]) became as_rate() over the monitor window"},
+		},
+		{
+			name:  "no aggregation",
+			rule:  Rule{Expr: `node_load1 > 4`, For: "2h"},
+			query: "min(last_2h):avg:node.node_load1{*} > 4",
+			notes: []string{"no aggregation: the series are averaged together; Prometheus alerts per series (add sum/avg by (...) for a multi alert)"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mapping := testMapping()
+			mapping.MetricPrefix = "node"
+			tc.rule.Alert = "TestAlert"
+			template, notes, err := ConvertRule(tc.rule, mapping)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if template.Name != "TestAlert" || template.Config["type"] != "query alert" {
+				t.Errorf("template = %+v", template)
+			}
+			if query := template.Config["query"]; query != tc.query {
+				t.Errorf("query = %v, want %s", query, tc.query)
+			}
+			if !reflect.DeepEqual(notes, tc.notes) {
+				t.Errorf("notes = %q, want %q", notes, tc.notes)
+			}
+		})
+	}
+}
+
+func TestConvertRuleTemplate(t *testing.T) {
+	rule := Rule{
+		Alert: "HighErrorRate",
+		Expr:  `sum by (namespace) (rate(http_requests_total[5m])) > 2.5`,
+		For:   "10m",
+		Labels: map[string]string{
+			"team":     "payments",
+			"severity": "page",
+			"instance": "{{ $labels.instance }}",
+		},
+		Annotations: map[string]string{
+			"summary":     "Errors in {{ $labels.namespace }}",
+			"description": "{{ $value | humanize }} errors per second",
+			"runbook_url": "https://runbooks.example.com/errors",
+		},
+	}
+	template, _, err := ConvertRule(rule, testMapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := template.Config["options"].(map[string]interface{})
+	thresholds := options["thresholds"].(map[string]interface{})
+	if !reflect.DeepEqual(thresholds, map[string]interface{}{"critical": 2.5}) {
+		t.Errorf("thresholds = %v", thresholds)
+	}
+	if options["notify_no_data"] != false || options["require_full_window"] != false || options["include_tags"] != true {
+		t.Errorf("options = %v", options)
+	}
+
+	// Labels holding templates are left out; the others are sorted after
+	// the source and alert name
+	wantTags := []string{SourceTag, "alertname:HighErrorRate", "severity:page", "team:payments"}
+	if tags := template.Config["tags"]; !reflect.DeepEqual(tags, wantTags) {
+		t.Errorf("tags = %v, want %v", tags, wantTags)
+	}
+
+	wantMessage := "Errors in {{kube_namespace.name}}\n\n{{value}} errors per second\n\nRunbook: https://runbooks.example.com/errors"
+	if message := template.Config["message"]; message != wantMessage {
+		t.Errorf("message = %q, want %q", message, wantMessage)
+	}
+}
+
+func TestConvertRuleMessage(t *testing.T) {
+	mapping := testMapping()
+	mapping.MetricPrefix = "prom"
+
+	// Without annotations, the message is the alert name
+	template, notes, err := ConvertRule(Rule{Alert: "Down", Expr: "avg(up) < 1"}, mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if template.Config["message"] != "Down" || len(notes) != 0 {
+		t.Errorf("message = %q, notes = %q", template.Config["message"], notes)
+	}
+
+	// Templating beyond $labels and $value is noted for review
+	rule := Rule{Alert: "Down", Expr: "avg(up) < 1", Annotations: map[string]string{"summary": "{{ $externalLabels.cluster }} is down"}}
+	template, notes, err = ConvertRule(rule, mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if template.Config["message"] != "{{ $externalLabels.cluster }} is down" || len(notes) != 1 || !strings.Contains(notes[0], "review the message") {
+		t.Errorf("message = %q, notes = %q", template.Config["message"], notes)
+	}
+}
+
+func TestConvertRuleErrors(t *testing.T) {
+	for _, tc := range []struct {
+		expr        string
+		forDuration string
+		err         string
+		unsupported bool
+	}{
+		{`sum(rate(http_requests_total[5m]))`, "", "the expression has no threshold comparison", false},
+		{`(sum(rate(http_requests_total[5m])) > 1)`, "", "the expression has no threshold comparison", false},
+		{`sum(http_requests_total) > sum(http_requests_total)`, "", "comparisons between two series", true},
+		{`sum(http_requests_total) == 0`, "", "the == comparison", true},
+		{`sum(http_requests_total) != 0`, "", "the != comparison", true},
+		{`sum(unmapped_metric) > 1`, "", "no Datadog metric mapped for unmapped_metric", false},
+		{`sum(http_requests_total{code=~"5.."}) > 1`, "", `regex matcher code=~"5.."`, true},
+		{`sum(http_requests_total{code!~".*"}) > 1`, "", `matcher code!~".*" (matches nothing)`, true},
+		{`sum(http_requests_total{code=""}) > 1`, "", "empty label value matcher on code", true},
+		{`sum(http_requests_total[5m]) > 1`, "", "range vector http_requests_total[5m] outside of rate", true},
+		{`sum(http_requests_total + http_requests_total) > 1`, "", "sum() of an expression", true},
+		{`(http_requests_total > 1) > 2`, "", "nested comparisons", true},
+		{`sum(http_requests_total) > 1`, "5 minutes", `invalid for duration "5 minutes"`, false},
+		{`absent(up) > 0`, "", "the absent function", true},
+		{`sum(x{job="api"`, "", "invalid PromQL", false},
+	} {
+		_, _, err := ConvertRule(Rule{Alert: "TestAlert", Expr: tc.expr, For: tc.forDuration}, testMapping())
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("ConvertRule(%q) = %v, want %q", tc.expr, err, tc.err)
+			continue
+		}
+		var unsupportedErr *UnsupportedError
+		if errors.As(err, &unsupportedErr) != tc.unsupported {
+			t.Errorf("ConvertRule(%q) = %v, unsupported %v, want %v", tc.expr, err, !tc.unsupported, tc.unsupported)
+		}
+	}
+}
+
+func TestEvaluationWindow(t *testing.T) {
+	for _, tc := range []struct {
+		forDuration string
+		want        string
+	}{
+		{"", "last_1m"},
+		{"0s", "last_1m"},
+		{"500ms", "last_1m"},
+		{"30s", "last_1m"},
+		{"61s", "last_2m"},
+		{"5m", "last_5m"},
+		{"1h30m", "last_90m"},
+		{"2h", "last_2h"},
+		{"24h", "last_1d"},
+		{"1w", "last_7d"},
+	} {
+		got, err := evaluationWindow(tc.forDuration)
+		if err != nil || got != tc.want {
+			t.Errorf("evaluationWindow(%q) = %s, %v, want %s", tc.forDuration, got, err, tc.want)
+		}
+	}
+	for _, invalid := range []string{"5", "m", "5min", "-5m", "1h 30m"} {
+		if _, err := evaluationWindow(invalid); err == nil {
+			t.Errorf("evaluationWindow(%q) succeeded, want an error", invalid)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	groups, err := LoadRules(filepath.Join("testdata", "rules.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conversion := Convert(groups, testMapping())
+
+	var converted []string
+	for _, rule := range conversion.Converted {
+		converted = append(converted, rule.Group+"/"+rule.Alert+": "+rule.Template.Config["query"].(string))
+	}
+	wantConverted := []string{
+		"api/HighErrorRate: min(last_10m):sum:trace.http.request.hits{code:5*} by {service}.as_rate() > 1",
+		"pods/PodRestarting: min(last_1h):max:kubernetes.containers.restarts{!kube_namespace:kube-system} by {kube_namespace,pod_name}.as_count() >= 3",
+	}
+	if !reflect.DeepEqual(converted, wantConverted) {
+		t.Errorf("converted = %q, want %q", converted, wantConverted)
+	}
+
+	// Every rule that isn't converted is reported with its reason
+	wantSkipped := []SkippedRule{
+		{Group: "api", Alert: "job:http_requests:rate5m", Expr: "sum by (job) (rate(http_requests_total[5m]))", Reason: "recording rule"},
+		{Group: "api", Alert: "SlowApi", Expr: "avg by (service) (api_latency_seconds) > 0.5", Reason: "no Datadog metric mapped for api_latency_seconds (add it to the mapping file or set a metric prefix)"},
+		{Group: "pods", Alert: "TargetDown", Expr: "up == 0 or absent(up)", Reason: "unsupported: the or set operator"},
+	}
+	if !reflect.DeepEqual(conversion.Skipped, wantSkipped) {
+		t.Errorf("skipped = %+v, want %+v", conversion.Skipped, wantSkipped)
+	}
+
+	var rules int
+	for _, group := range groups {
+		rules += len(group.Rules)
+	}
+	if got := len(conversion.Converted) + len(conversion.Skipped); got != rules {
+		t.Errorf("%d rules converted or skipped, want all %d", got, rules)
+	}
+}
+
+func TestLoadMapping(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mapping.yaml")
+	content := "metrics:\n  http_requests_total: trace.http.request.hits\nlabels:\n  pod: pod\n  service: svc\nmetric_prefix: app\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mapping, err := LoadMapping(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct{ name, want string }{
+		{"http_requests_total", "trace.http.request.hits"},
+		{"queue_depth", "app.queue_depth"},
+	} {
+		if got, ok := mapping.metric(tc.name); !ok || got != tc.want {
+			t.Errorf("metric(%s) = %s, %v, want %s", tc.name, got, ok, tc.want)
+		}
+	}
+	// The file overrides the default labels and keeps the others
+	for _, tc := range []struct{ label, want string }{
+		{"pod", "pod"},
+		{"service", "svc"},
+		{"namespace", "kube_namespace"},
+		{"code", "code"},
+	} {
+		if got := mapping.tag(tc.label); got != tc.want {
+			t.Errorf("tag(%s) = %s, want %s", tc.label, got, tc.want)
+		}
+	}
+
+	// Without a file or prefix, metrics are unmapped
+	if _, ok := DefaultMapping().metric("queue_depth"); ok {
+		t.Error("DefaultMapping maps queue_depth, want no metric")
+	}
+	if _, err := LoadMapping(filepath.Join(t.TempDir(), "missing.yaml")); err == nil || !strings.Contains(err.Error(), "failed to read mapping file") {
+		t.Errorf("LoadMapping(missing) = %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package prometheus
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Mapping maps Prometheus metric and label names to Datadog metric and tag names
+type Mapping struct {
+	// Metrics maps Prometheus metric names to Datadog metric names
+	Metrics map[string]string `yaml:"metrics"`
+	// Labels maps Prometheus label names to Datadog tag keys; unmapped labels
+	// keep their name
+	Labels map[string]string `yaml:"labels"`
+	// MetricPrefix, when set, maps every metric missing from Metrics to
+	// MetricPrefix.<name>, the naming of metrics collected by the OpenMetrics
+	// integration with that namespace
+	MetricPrefix string `yaml:"metric_prefix"`
+}
+
+// defaultLabels are the label mappings of Kubernetes metrics (as exported by
+// kube-state-metrics and cAdvisor) to the Datadog Agent's tags
+var defaultLabels = map[string]string{
+	"namespace": "kube_namespace",
+	"pod":       "pod_name",
+	"container": "kube_container_name",
+}
+
+// DefaultMapping returns the built-in mapping: Kubernetes label names only;
+// metric names always need a mapping file or a metric prefix
+func DefaultMapping() *Mapping {
+	mapping := &Mapping{Metrics: map[string]string{}, Labels: map[string]string{}}
+	for label, tag := range defaultLabels {
+		mapping.Labels[label] = tag
+	}
+	return mapping
+}
+
+// LoadMapping reads a mapping file on top of DefaultMapping
+func LoadMapping(path string) (*Mapping, error) {
+	mapping := DefaultMapping()
+	if path == "" {
+		return mapping, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mapping file %s: %v", path, err)
+	}
+	var file Mapping
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid YAML in mapping file %s: %v", path, err)
+	}
+	for metric, ddMetric := range file.Metrics {
+		mapping.Metrics[metric] = ddMetric
+	}
+	for label, tag := range file.Labels {
+		mapping.Labels[label] = tag
+	}
+	if file.MetricPrefix != "" {
+		mapping.MetricPrefix = file.MetricPrefix
+	}
+	return mapping, nil
+}
+
+// metric returns the Datadog metric of a Prometheus metric, if known
+func (m *Mapping) metric(name string) (string, bool) {
+	if ddMetric, ok := m.Metrics[name]; ok {
+		return ddMetric, true
+	}
+	if m.MetricPrefix != "" {
+		return m.MetricPrefix + "." + name, true
+	}
+	return "", false
+}
+
+// tag returns the Datadog tag key of a Prometheus label
+func (m *Mapping) tag(label string) string {
+	if tag, ok := m.Labels[label]; ok {
+		return tag
+	}
+	return label
+}
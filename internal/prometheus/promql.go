@@ -0,0 +1,483 @@
+package prometheus
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// This is a parser for the subset of PromQL alerting rules are usually
+// written in: selectors, rate/irate/increase, sum/avg/min/max with by, the
+// arithmetic operators and comparisons. Anything else is an
+// *UnsupportedError naming the construct.
+
+// UnsupportedError is a PromQL construct the converter has no Datadog
+// equivalent for
+type UnsupportedError struct {
+	Construct string
+}
+
+// Error implements the error interface
+func (e *UnsupportedError) Error() string {
+	return "unsupported: " + e.Construct
+}
+
+func unsupported(format string, args ...interface{}) error {
+	return &UnsupportedError{Construct: fmt.Sprintf(format, args...)}
+}
+
+// node is a PromQL expression
+type node interface{}
+
+// selectorNode is an instant or range vector selector: metric{matchers}[range]
+type selectorNode struct {
+	metric   string
+	matchers []matcher
+	rangeDur string
+}
+
+// matcher is a label matcher of a selector
+type matcher struct {
+	label, op, value string
+}
+
+// callNode is a function call on a range vector
+type callNode struct {
+	fn  string
+	arg *selectorNode
+}
+
+// aggNode is an aggregation: op by (labels) (expr)
+type aggNode struct {
+	op   string
+	by   []string
+	expr node
+}
+
+// binaryNode is an arithmetic operation or a comparison
+type binaryNode struct {
+	op       string
+	lhs, rhs node
+}
+
+// numberNode is a scalar literal, kept as written
+type numberNode struct {
+	text string
+}
+
+// parenNode is a parenthesized expression
+type parenNode struct {
+	expr node
+}
+
+// token kinds
+const (
+	tokEOF = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokDuration
+	tokOp
+)
+
+type token struct {
+	kind int
+	text string
+}
+
+// lex splits a PromQL expression into tokens. Durations are only lexed
+// inside [ ], where they can't be confused with numbers.
+func lex(expr string) ([]token, error) {
+	var tokens []token
+	runes := []rune(expr)
+	inRange := false
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '#':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case inRange && r == ':':
+			i++
+			tokens = append(tokens, token{tokOp, ":"})
+		case inRange && (unicode.IsDigit(r) || unicode.IsLetter(r)):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || unicode.IsLetter(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, token{tokDuration, string(runes[start:i])})
+		case unicode.IsLetter(r) || r == '_' || r == ':':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == ':') {
+				i++
+			}
+			tokens = append(tokens, token{tokIdent, string(runes[start:i])})
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == 'e' || runes[i] == 'E' ||
+				((runes[i] == '+' || runes[i] == '-') && (runes[i-1] == 'e' || runes[i-1] == 'E'))) {
+				i++
+			}
+			tokens = append(tokens, token{tokNumber, string(runes[start:i])})
+		case r == '"' || r == '\'' || r == '`':
+			quote := r
+			i++
+			var b strings.Builder
+			for ; i < len(runes) && runes[i] != quote; i++ {
+				if runes[i] == '\\' && quote != '`' && i+1 < len(runes) {
+					i++
+				}
+				b.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			i++
+			tokens = append(tokens, token{tokString, b.String()})
+		default:
+			op := string(r)
+			if i+1 < len(runes) {
+				switch two := string(runes[i : i+2]); two {
+				case "==", "!=", "=~", "!~", ">=", "<=":
+					op = two
+				}
+			}
+			if !strings.Contains("(){}[],=!~<>+-*/%^@", string(r)) {
+				return nil, fmt.Errorf("unexpected character %q", r)
+			}
+			switch op {
+			case "[":
+				inRange = true
+			case "]":
+				inRange = false
+			}
+			i += len(op)
+			tokens = append(tokens, token{tokOp, op})
+		}
+	}
+	return append(tokens, token{kind: tokEOF}), nil
+}
+
+// parser is a recursive descent parser over the tokens of an expression
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parsePromQL parses an alerting rule expression
+func parsePromQL(expr string) (node, error) {
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	n, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		if tok.kind == tokIdent {
+			switch tok.text {
+			case "and", "or", "unless":
+				return nil, unsupported("the %s set operator", tok.text)
+			case "offset":
+				return nil, unsupported("offset modifiers")
+			}
+		}
+		return nil, fmt.Errorf("unexpected %q", tok.text)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consumes the next token if it is the operator op
+func (p *parser) accept(op string) bool {
+	if tok := p.peek(); tok.kind == tokOp && tok.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if !p.accept(op) {
+		return fmt.Errorf("expected %q, got %q", op, p.peek().text)
+	}
+	return nil
+}
+
+// isComparison reports whether op is a comparison operator
+func isComparison(op string) bool {
+	switch op {
+	case ">", "<", ">=", "<=", "==", "!=":
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseComparison() (node, error) {
+	lhs, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for tok := p.peek(); tok.kind == tokOp && isComparison(tok.text); tok = p.peek() {
+		p.next()
+		if next := p.peek(); next.kind == tokIdent && next.text == "bool" {
+			return nil, unsupported("the bool modifier")
+		}
+		if err := p.rejectVectorMatching(); err != nil {
+			return nil, err
+		}
+		rhs, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		lhs = &binaryNode{op: tok.text, lhs: lhs, rhs: rhs}
+	}
+	return lhs, nil
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	lhs, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for tok := p.peek(); tok.kind == tokOp && (tok.text == "+" || tok.text == "-"); tok = p.peek() {
+		p.next()
+		if err := p.rejectVectorMatching(); err != nil {
+			return nil, err
+		}
+		rhs, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		lhs = &binaryNode{op: tok.text, lhs: lhs, rhs: rhs}
+	}
+	return lhs, nil
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	lhs, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for tok := p.peek(); tok.kind == tokOp && (tok.text == "*" || tok.text == "/" || tok.text == "%" || tok.text == "^"); tok = p.peek() {
+		p.next()
+		if tok.text == "%" || tok.text == "^" {
+			return nil, unsupported("the %s operator", tok.text)
+		}
+		if err := p.rejectVectorMatching(); err != nil {
+			return nil, err
+		}
+		rhs, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		lhs = &binaryNode{op: tok.text, lhs: lhs, rhs: rhs}
+	}
+	return lhs, nil
+}
+
+// rejectVectorMatching fails on on(), ignoring() and group_left/right, which
+// have no Datadog equivalent
+func (p *parser) rejectVectorMatching() error {
+	if tok := p.peek(); tok.kind == tokIdent {
+		switch tok.text {
+		case "on", "ignoring", "group_left", "group_right":
+			return unsupported("%s() vector matching", tok.text)
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.accept("-") {
+		tok := p.next()
+		if tok.kind != tokNumber {
+			return nil, unsupported("negated expressions")
+		}
+		return &numberNode{text: "-" + tok.text}, nil
+	}
+	return p.parsePrimary()
+}
+
+// aggregationOps are the PromQL aggregations with a Datadog space aggregator
+var aggregationOps = map[string]bool{"sum": true, "avg": true, "min": true, "max": true}
+
+// rangeFunctions are the functions on range vectors the converter supports
+var rangeFunctions = map[string]bool{"rate": true, "irate": true, "increase": true}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.peek()
+	switch {
+	case tok.kind == tokNumber:
+		p.next()
+		if _, err := strconv.ParseFloat(tok.text, 64); err != nil {
+			return nil, fmt.Errorf("invalid number %q", tok.text)
+		}
+		return &numberNode{text: tok.text}, nil
+	case tok.kind == tokOp && tok.text == "(":
+		p.next()
+		inner, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return &parenNode{expr: inner}, nil
+	case tok.kind == tokOp && tok.text == "{":
+		return nil, unsupported("selectors without a metric name")
+	case tok.kind == tokIdent:
+		p.next()
+		following := p.peek()
+		isCall := following.kind == tokOp && following.text == "("
+		isGrouping := following.kind == tokIdent && (following.text == "by" || following.text == "without")
+		switch {
+		case aggregationOps[tok.text] && (isCall || isGrouping):
+			return p.parseAggregation(tok.text)
+		case rangeFunctions[tok.text] && isCall:
+			return p.parseRangeCall(tok.text)
+		case isCall || isGrouping:
+			return nil, unsupported("the %s function", tok.text)
+		}
+		return p.parseSelector(tok.text)
+	}
+	return nil, fmt.Errorf("unexpected %q", tok.text)
+}
+
+func (p *parser) parseAggregation(op string) (node, error) {
+	agg := &aggNode{op: op}
+	if err := p.parseGrouping(agg); err != nil {
+		return nil, err
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind == tokNumber || tok.kind == tokString {
+		return nil, unsupported("parameters of %s", op)
+	}
+	inner, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	agg.expr = inner
+	if err := p.parseGrouping(agg); err != nil {
+		return nil, err
+	}
+	return agg, nil
+}
+
+// parseGrouping parses an optional by (...) clause; without is unsupported
+// because Datadog can only group by a list of tags
+func (p *parser) parseGrouping(agg *aggNode) error {
+	tok := p.peek()
+	if tok.kind != tokIdent || (tok.text != "by" && tok.text != "without") {
+		return nil
+	}
+	p.next()
+	if tok.text == "without" {
+		return unsupported("without() grouping")
+	}
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	for !p.accept(")") {
+		label := p.next()
+		if label.kind != tokIdent {
+			return fmt.Errorf("expected a label name in by(), got %q", label.text)
+		}
+		agg.by = append(agg.by, label.text)
+		if !p.accept(",") && p.peek().text != ")" {
+			return fmt.Errorf("expected \",\" or \")\" in by(), got %q", p.peek().text)
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseRangeCall(fn string) (node, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	tok := p.next()
+	if following := p.peek(); tok.kind != tokIdent || (following.kind == tokOp && following.text == "(") {
+		return nil, unsupported("%s() of an expression (only of a metric selector)", fn)
+	}
+	arg, err := p.parseSelector(tok.text)
+	if err != nil {
+		return nil, err
+	}
+	selector := arg.(*selectorNode)
+	if selector.rangeDur == "" {
+		return nil, fmt.Errorf("%s() needs a range vector, e.g. %s[5m]", fn, selector.metric)
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return &callNode{fn: fn, arg: selector}, nil
+}
+
+func (p *parser) parseSelector(metric string) (node, error) {
+	selector := &selectorNode{metric: metric}
+	if p.accept("{") {
+		for !p.accept("}") {
+			label := p.next()
+			if label.kind != tokIdent {
+				return nil, fmt.Errorf("expected a label name, got %q", label.text)
+			}
+			op := p.next()
+			if op.kind != tokOp || (op.text != "=" && op.text != "!=" && op.text != "=~" && op.text != "!~") {
+				return nil, fmt.Errorf("expected a label matcher operator, got %q", op.text)
+			}
+			value := p.next()
+			if value.kind != tokString {
+				return nil, fmt.Errorf("expected a quoted label value, got %q", value.text)
+			}
+			if label.text == "__name__" {
+				return nil, unsupported("__name__ matchers")
+			}
+			selector.matchers = append(selector.matchers, matcher{label: label.text, op: op.text, value: value.text})
+			if !p.accept(",") && p.peek().text != "}" {
+				return nil, fmt.Errorf("expected \",\" or \"}\" in selector, got %q", p.peek().text)
+			}
+		}
+	}
+	if p.accept("[") {
+		dur := p.next()
+		if dur.kind != tokDuration {
+			return nil, fmt.Errorf("expected a duration in [], got %q", dur.text)
+		}
+		if p.accept(":") {
+			return nil, unsupported("subqueries")
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		selector.rangeDur = dur.text
+	}
+	if tok := p.peek(); tok.kind == tokIdent && tok.text == "offset" {
+		return nil, unsupported("offset modifiers")
+	}
+	if tok := p.peek(); tok.kind == tokOp && tok.text == "@" {
+		return nil, unsupported("@ modifiers")
+	}
+	return selector, nil
+}
@@ -0,0 +1,153 @@
+package prometheus
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// formatNode prints a parsed expression back in a canonical form, with every
+// node visible, so that the parse tree can be compared as a string
+func formatNode(n node) string {
+	switch n := n.(type) {
+	case *selectorNode:
+		var matchers []string
+		for _, m := range n.matchers {
+			matchers = append(matchers, fmt.Sprintf("%s%s%q", m.label, m.op, m.value))
+		}
+		s := n.metric + "{" + strings.Join(matchers, ",") + "}"
+		if n.rangeDur != "" {
+			s += "[" + n.rangeDur + "]"
+		}
+		return s
+	case *callNode:
+		return n.fn + "(" + formatNode(n.arg) + ")"
+	case *aggNode:
+		return n.op + " by (" + strings.Join(n.by, ",") + ") (" + formatNode(n.expr) + ")"
+	case *binaryNode:
+		return "<" + formatNode(n.lhs) + " " + n.op + " " + formatNode(n.rhs) + ">"
+	case *numberNode:
+		return n.text
+	case *parenNode:
+		return "(" + formatNode(n.expr) + ")"
+	}
+	return fmt.Sprintf("%T", n)
+}
+
+func TestParsePromQL(t *testing.T) {
+	for _, tc := range []struct {
+		expr string
+		want string
+	}{
+		{`up == 0`, `<up{} == 0>`},
+		{`node_load1 > 4`, `<node_load1{} > 4>`},
+		{`90 < cpu_usage`, `<90 < cpu_usage{}>`},
+		{`up{job="api", env!="dev"} < 1`, `<up{job="api",env!="dev"} < 1>`},
+		{`up{job=~'api.*',instance!~"db-1"}`, `up{job=~"api.*",instance!~"db-1"}`},
+		{`up{job="api",}`, `up{job="api"}`},
+		{"up{job=`a\\b`}", `up{job="a\\b"}`},
+		{`up{job="say \"hi\""}`, `up{job="say \"hi\""}`},
+		{`rate(http_requests_total{code="500"}[5m]) > 0.5`, `<rate(http_requests_total{code="500"}[5m]) > 0.5>`},
+		{`irate(x[1m30s])`, `irate(x{}[1m30s])`},
+		{`increase(errors_total[1h]) >= 10`, `<increase(errors_total{}[1h]) >= 10>`},
+		{`sum by (service) (rate(x[5m]))`, `sum by (service) (rate(x{}[5m]))`},
+		{`avg(rate(x[5m])) by (pod, namespace)`, `avg by (pod,namespace) (rate(x{}[5m]))`},
+		{`max(x)`, `max by () (x{})`},
+		{`sum(rate(a[5m])) / sum(rate(b[5m])) * 100 > 5`, `<<<sum by () (rate(a{}[5m])) / sum by () (rate(b{}[5m]))> * 100> > 5>`},
+		{`a + b * c`, `<a{} + <b{} * c{}>>`},
+		{`(a + b) * c`, `<(<a{} + b{}>) * c{}>`},
+		{`a - b - c`, `<<a{} - b{}> - c{}>`},
+		{`x > -1.5`, `<x{} > -1.5>`},
+		{`x < 1e3`, `<x{} < 1e3>`},
+		{`x < 2.5e-3`, `<x{} < 2.5e-3>`},
+		{`x > .5`, `<x{} > .5>`},
+		{`node:cpu:ratio > 0.9`, `<node:cpu:ratio{} > 0.9>`},
+		{"x > 1 # high\n", `<x{} > 1>`},
+	} {
+		n, err := parsePromQL(tc.expr)
+		if err != nil {
+			t.Errorf("parsePromQL(%q): %v", tc.expr, err)
+			continue
+		}
+		if got := formatNode(n); got != tc.want {
+			t.Errorf("parsePromQL(%q) = %s, want %s", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestParsePromQLUnsupported(t *testing.T) {
+	for _, tc := range []struct {
+		expr      string
+		construct string
+	}{
+		{`up == 0 and on() absent(x)`, "the and set operator"},
+		{`a or b`, "the or set operator"},
+		{`a unless b`, "the unless set operator"},
+		{`x offset 5m > 1`, "offset modifiers"},
+		{`rate(x[5m] offset 1h)`, "offset modifiers"},
+		{`x @ 1609746000`, "@ modifiers"},
+		{`x > bool 1`, "the bool modifier"},
+		{`a / on(pod) b`, "on() vector matching"},
+		{`a * ignoring(code) b`, "ignoring() vector matching"},
+		{`a - group_left b`, "group_left() vector matching"},
+		{`a % 2`, "the % operator"},
+		{`a ^ 2`, "the ^ operator"},
+		{`-a`, "negated expressions"},
+		{`{job="api"}`, "selectors without a metric name"},
+		{`absent(up)`, "the absent function"},
+		{`count by (job) (up)`, "the count function"},
+		{`sum without (pod) (x)`, "without() grouping"},
+		{`topk(3, x)`, "the topk function"},
+		{`quantile(0.9, x)`, "the quantile function"},
+		{`rate(sum(x)[5m])`, "rate() of an expression (only of a metric selector)"},
+		{`rate(x[5m:1m])`, "subqueries"},
+		{`x{__name__="y"}`, "__name__ matchers"},
+	} {
+		_, err := parsePromQL(tc.expr)
+		var unsupportedErr *UnsupportedError
+		if !errors.As(err, &unsupportedErr) {
+			t.Errorf("parsePromQL(%q) = %v, want an UnsupportedError", tc.expr, err)
+			continue
+		}
+		if unsupportedErr.Construct != tc.construct {
+			t.Errorf("parsePromQL(%q) = unsupported %q, want %q", tc.expr, unsupportedErr.Construct, tc.construct)
+		}
+	}
+}
+
+func TestParsePromQLInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		expr string
+		err  string
+	}{
+		{``, `unexpected ""`},
+		{`x >`, `unexpected ""`},
+		{`(x > 1`, `expected ")"`},
+		{`x > 1)`, `unexpected ")"`},
+		{`x{job="api"`, `expected "," or "}" in selector`},
+		{`x{job}`, `expected a label matcher operator`},
+		{`x{job=api}`, `expected a quoted label value`},
+		{`x{job="api}`, `unterminated string`},
+		{`x{"job"="api"}`, `expected a label name`},
+		{`x[]`, `expected a duration in []`},
+		{`x[5m`, `expected "]"`},
+		{`rate(x)`, `rate() needs a range vector, e.g. x[5m]`},
+		{`rate(x[5m]`, `expected ")"`},
+		{`sum by (1) (x)`, `expected a label name in by()`},
+		{`sum by (a b) (x)`, `expected "," or ")" in by()`},
+		{`x > 1.2.3`, `invalid number "1.2.3"`},
+		{`x > 1 $`, `unexpected character '$'`},
+		{`x y`, `unexpected "y"`},
+	} {
+		_, err := parsePromQL(tc.expr)
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("parsePromQL(%q) = %v, want %q", tc.expr, err, tc.err)
+			continue
+		}
+		var unsupportedErr *UnsupportedError
+		if errors.As(err, &unsupportedErr) {
+			t.Errorf("parsePromQL(%q) = %v, want a syntax error, not unsupported", tc.expr, err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Package prometheus converts Prometheus alerting rules into monitor templates
+package prometheus
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RuleGroup is a group of a Prometheus rules file
+type RuleGroup struct {
+	Name  string `yaml:"name"`
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule is an alerting or recording rule
+type Rule struct {
+	Alert       string            `yaml:"alert"`
+	Record      string            `yaml:"record"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for"`
+	Labels      map[string]string `yaml:"labels"`
+	Annotations map[string]string `yaml:"annotations"`
+}
+
+// rulesDocument is one YAML document of a rules file: a plain rules file
+// (groups at the top) or a PrometheusRule resource (groups under spec)
+type rulesDocument struct {
+	Kind   string      `yaml:"kind"`
+	Groups []RuleGroup `yaml:"groups"`
+	Spec   struct {
+		Groups []RuleGroup `yaml:"groups"`
+	} `yaml:"spec"`
+}
+
+// LoadRules reads the rule groups of a Prometheus rules file or of
+// PrometheusRule resources; several YAML documents (---) are read in order
+func LoadRules(path string) ([]RuleGroup, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file %s: %v", path, err)
+	}
+
+	var groups []RuleGroup
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc rulesDocument
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("invalid YAML in rules file %s: %v", path, err)
+		}
+		switch {
+		case doc.Kind == "PrometheusRule":
+			groups = append(groups, doc.Spec.Groups...)
+		case doc.Kind == "":
+			groups = append(groups, doc.Groups...)
+		default:
+			return nil, fmt.Errorf("rules file %s: unsupported kind %q (expected a rules file or a PrometheusRule)", path, doc.Kind)
+		}
+	}
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("rules file %s has no rule groups", path)
+	}
+	return groups, nil
+}
@@ -0,0 +1,62 @@
+package prometheus
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadRules(t *testing.T) {
+	groups, err := LoadRules(filepath.Join("testdata", "rules.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 2 || groups[0].Name != "api" || groups[1].Name != "pods" {
+		t.Fatalf("LoadRules = %+v, want the api and pods groups", groups)
+	}
+
+	api := groups[0].Rules
+	if len(api) != 3 || api[0].Record != "job:http_requests:rate5m" || api[1].Alert != "HighErrorRate" || api[2].Alert != "SlowApi" {
+		t.Fatalf("api rules = %+v", api)
+	}
+	rule := api[1]
+	if rule.For != "10m" || rule.Labels["severity"] != "page" || rule.Annotations["runbook_url"] != "https://runbooks.example.com/high-error-rate" {
+		t.Errorf("HighErrorRate = %+v", rule)
+	}
+	if !strings.HasPrefix(rule.Expr, "sum by (service)") {
+		t.Errorf("HighErrorRate expr = %q", rule.Expr)
+	}
+
+	// The second document is a PrometheusRule: its groups are under spec
+	pods := groups[1].Rules
+	if len(pods) != 2 || pods[0].Alert != "PodRestarting" || pods[0].For != "1h" || pods[1].Alert != "TargetDown" {
+		t.Errorf("pods rules = %+v", pods)
+	}
+}
+
+func TestLoadRulesErrors(t *testing.T) {
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{"empty.yaml", "", "has no rule groups"},
+		{"no-groups.yaml", "groups: []\n", "has no rule groups"},
+		{"invalid.yaml", "groups: [\n", "invalid YAML in rules file"},
+		{"kind.yaml", "kind: ConfigMap\ndata: {}\n", `unsupported kind "ConfigMap"`},
+	} {
+		path := filepath.Join(dir, tc.name)
+		if err := os.WriteFile(path, []byte(tc.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadRules(path); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("LoadRules(%s) = %v, want %q", tc.name, err, tc.err)
+		}
+	}
+
+	if _, err := LoadRules(filepath.Join(dir, "missing.yaml")); err == nil || !strings.Contains(err.Error(), "failed to read rules file") {
+		t.Errorf("LoadRules(missing) = %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package version
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tbernacchi/datadog-monitor-manager/internal/paths"
+)
+
+// Update check settings
+const (
+	// LatestReleaseURL is the GitHub API endpoint for the latest release
+	LatestReleaseURL = "https://api.github.com/repos/tbernacchi/datadog-monitor-manager/releases/latest"
+	// ReleasesPage is where users download new versions
+	ReleasesPage = "https://github.com/tbernacchi/datadog-monitor-manager/releases/latest"
+	// CheckTimeout bounds the GitHub API call
+	CheckTimeout = 2 * time.Second
+	// CheckInterval is how long a cached check result is reused
+	CheckInterval = 24 * time.Hour
+)
+
+// UpdateCheck is the cached result of the last update check. Latest is empty
+// when the check failed, so an unreachable GitHub is retried only once a day.
+type UpdateCheck struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Latest    string    `json:"latest,omitempty"`
+}
+
+// CheckCachePath returns the file the last update check is cached in, in the
+// state directory, else in the cache directory when there is none
+func CheckCachePath() string {
+	dir, err := paths.StateDir()
+	if err != nil {
+		dir = paths.CacheDir()
+	}
+	return filepath.Join(dir, "update-check.json")
+}
+
+// LoadCachedCheck returns the cached update check if it is younger than maxAge
+func LoadCachedCheck(path string, maxAge time.Duration) (*UpdateCheck, bool) {
+	if path == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var check UpdateCheck
+	if err := json.Unmarshal(data, &check); err != nil {
+		return nil, false
+	}
+	if time.Since(check.CheckedAt) > maxAge {
+		return nil, false
+	}
+	return &check, true
+}
+
+// SaveCheck caches an update check result
+func SaveCheck(path string, check UpdateCheck) error {
+	if path == "" {
+		return nil
+	}
+	if err := paths.EnsureParent(path); err != nil {
+		return err
+	}
+	data, err := json.Marshal(check)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// LatestRelease returns the tag of the latest GitHub release. The call gives up
+// after CheckTimeout.
+func LatestRelease(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, CheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, LatestReleaseURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "datadog-monitor-manager/"+Version)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to check latest release: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to check latest release: status %d", resp.StatusCode)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", fmt.Errorf("failed to decode latest release: %w", err)
+	}
+	if release.TagName == "" {
+		return "", fmt.Errorf("latest release has no tag")
+	}
+	return release.TagName, nil
+}
+
+// Check returns the latest release, fetching it from GitHub and caching it
+// unless a cached result younger than CheckInterval exists. With force the
+// cache is not read.
+func Check(ctx context.Context, force bool) (*UpdateCheck, error) {
+	path := CheckCachePath()
+	if !force {
+		if check, ok := LoadCachedCheck(path, CheckInterval); ok {
+			return check, nil
+		}
+	}
+	check := UpdateCheck{CheckedAt: time.Now()}
+	latest, err := LatestRelease(ctx)
+	// A failed cache write only means checking again next time
+	if err != nil {
+		_ = SaveCheck(path, check)
+		return nil, err
+	}
+	check.Latest = latest
+	_ = SaveCheck(path, check)
+	return &check, nil
+}
+
+// IsNewer reports whether latest is a newer version than current. Versions
+// that don't parse (e.g., "dev") are never considered outdated.
+func IsNewer(latest, current string) bool {
+	l, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	c, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	for i := range l {
+		if l[i] != c[i] {
+			return l[i] > c[i]
+		}
+	}
+	return false
+}
+
+// parseVersion parses "v1.2.3" (the "v", minor and patch are optional; any
+// pre-release or build suffix is ignored)
+func parseVersion(v string) ([3]int, bool) {
+	var parts [3]int
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	fields := strings.Split(v, ".")
+	if len(fields) > 3 {
+		return parts, false
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
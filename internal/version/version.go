@@ -0,0 +1,23 @@
+package version
+
+import "runtime/debug"
+
+// Version is the current version of datadog-monitor-manager. Release builds set
+// it with -ldflags "-X github.com/tbernacchi/datadog-monitor-manager/internal/version.Version=v1.2.3";
+// otherwise the module version is used when installed with go install, or "dev".
+var Version = "dev"
+
+func init() {
+	if Version != "dev" {
+		return
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		Version = info.Main.Version
+	}
+}
+
+// IsRelease reports whether Version identifies a released version rather than a local build
+func IsRelease() bool {
+	_, ok := parseVersion(Version)
+	return ok
+}